@@ -0,0 +1,192 @@
+// Package agg 提供对JSON数组按键分组并聚合的功能，
+// 覆盖常见的统计场景，例如按类别求和、计数和求平均值。
+//
+// 示例：
+//
+//	result, err := agg.GroupBy(orders, "$.region").Aggregate(map[string]agg.AggFn{
+//		"total": agg.Sum("$.amount"),
+//		"n":     agg.Count(),
+//	})
+package agg
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// Accumulator 累加一个分组中的值并给出聚合结果
+type Accumulator interface {
+	// Add 加入一个值
+	Add(value types.JSONValue)
+	// Result 返回当前的聚合结果
+	Result() types.JSONValue
+}
+
+// AggFn 描述一个聚合函数
+type AggFn struct {
+	// Path 是以元素为根的JSON Path，累加器接收它选择的值，没有匹配值的元素被跳过
+	// 为空时累加器接收整个元素
+	Path string
+	// New 为每个分组创建新的累加器
+	New func() Accumulator
+}
+
+// Grouping 表示按键分组的数组，调用Aggregate得到聚合结果
+type Grouping struct {
+	arr     *types.JSONArray
+	keyPath string
+}
+
+// GroupBy 按元素中JSON Path所选的值对数组分组，keyPath为空时所有元素属于同一个分组
+func GroupBy(arr *types.JSONArray, keyPath string) *Grouping {
+	return &Grouping{arr: arr, keyPath: keyPath}
+}
+
+// Aggregate 对每个分组执行聚合函数，返回每个分组一条记录的数组
+func (g *Grouping) Aggregate(fns map[string]AggFn) (*types.JSONArray, error) {
+	a, err := NewAggregator(g.keyPath, fns)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range g.arr.Values() {
+		a.Add(value)
+	}
+	return a.Result(), nil
+}
+
+// compiledFn 是解析过路径的聚合函数
+type compiledFn struct {
+	name string
+	path *jsonpath.JSONPath
+	fn   AggFn
+}
+
+// group 记录一个分组的键和累加器
+type group struct {
+	key          types.JSONValue
+	accumulators []Accumulator
+}
+
+// Aggregator 逐个接收元素并维护每个分组的累加器，适用于流式处理
+// 内存占用与分组数量成正比，与元素数量无关
+type Aggregator struct {
+	keyPath *jsonpath.JSONPath
+	keyName string
+	fns     []compiledFn
+	hasher  *utils.Deduper
+	groups  map[[16]byte]*group
+	order   []*group
+}
+
+// NewAggregator 创建一个新的Aggregator
+// 结果记录中分组键的字段名取keyPath的最后一个属性名，无法确定时使用"key"
+func NewAggregator(keyPath string, fns map[string]AggFn) (*Aggregator, error) {
+	a := &Aggregator{keyName: "key", groups: make(map[[16]byte]*group)}
+	a.hasher, _ = utils.NewDeduper("")
+
+	if keyPath != "" {
+		jp, err := jsonpath.ParseJSONPath(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		a.keyPath = jp
+		a.keyName = keyName(jp)
+	}
+
+	names := make([]string, 0, len(fns))
+	for name := range fns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn := fns[name]
+		compiled := compiledFn{name: name, fn: fn}
+		if fn.Path != "" {
+			jp, err := jsonpath.ParseJSONPath(fn.Path)
+			if err != nil {
+				return nil, err
+			}
+			compiled.path = jp
+		}
+		a.fns = append(a.fns, compiled)
+	}
+	return a, nil
+}
+
+// Add 把元素加入它所属的分组，缺少分组键的元素属于键为null的分组
+func (a *Aggregator) Add(value types.JSONValue) {
+	var key types.JSONValue = types.NewJSONNull()
+	if a.keyPath != nil {
+		if v := first(a.keyPath, value); v != nil {
+			key = v
+		}
+	}
+
+	hash, _ := a.hasher.Key(key)
+	g, ok := a.groups[hash]
+	if !ok {
+		g = &group{key: key, accumulators: make([]Accumulator, len(a.fns))}
+		for i, fn := range a.fns {
+			g.accumulators[i] = fn.fn.New()
+		}
+		a.groups[hash] = g
+		a.order = append(a.order, g)
+	}
+
+	for i, fn := range a.fns {
+		v := value
+		if fn.path != nil {
+			if v = first(fn.path, value); v == nil {
+				continue
+			}
+		}
+		g.accumulators[i].Add(v)
+	}
+}
+
+// Result 返回每个分组一条记录的数组，分组按第一次出现的顺序排列
+func (a *Aggregator) Result() *types.JSONArray {
+	result := make([]types.JSONValue, 0, len(a.order))
+	for _, g := range a.order {
+		record := types.NewJSONObject()
+		if a.keyPath != nil {
+			record.Put(a.keyName, g.key)
+		}
+		for i, fn := range a.fns {
+			record.Put(fn.name, g.accumulators[i].Result())
+		}
+		result = append(result, record)
+	}
+	return types.NewJSONArrayFromValuesUnsafe(result)
+}
+
+// first 返回路径匹配的第一个值，没有匹配时返回nil
+func first(jp *jsonpath.JSONPath, value types.JSONValue) types.JSONValue {
+	results, err := jp.Query(value)
+	if err != nil || len(results) == 0 {
+		return nil
+	}
+	if results[0] == nil {
+		return types.NewJSONNull()
+	}
+	return results[0]
+}
+
+// keyName 返回路径最后一个属性名
+func keyName(jp *jsonpath.JSONPath) string {
+	path := jp.String()
+	if !strings.HasSuffix(path, "']") {
+		if i := strings.LastIndexByte(path, '.'); i >= 0 && !strings.ContainsAny(path[i:], "[]") {
+			return path[i+1:]
+		}
+		return "key"
+	}
+	if i := strings.LastIndex(path, "['"); i >= 0 {
+		return path[i+2 : len(path)-2]
+	}
+	return "key"
+}
@@ -0,0 +1,108 @@
+package agg
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func parseArray(t *testing.T, s string) *types.JSONArray {
+	t.Helper()
+	value, err := parser.ParseToValue(s)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	arr, err := value.AsArray()
+	if err != nil {
+		t.Fatalf("AsArray() error = %v", err)
+	}
+	return arr
+}
+
+const orders = `[
+	{"region": "east", "amount": 10, "user": "a"},
+	{"region": "west", "amount": 5, "user": "b"},
+	{"region": "east", "amount": 30, "user": "a"},
+	{"region": "east", "amount": "n/a", "user": "c"},
+	{"amount": 7}
+]`
+
+func TestGroupByAggregate(t *testing.T) {
+	arr := parseArray(t, orders)
+
+	got, err := GroupBy(arr, "$.region").Aggregate(map[string]AggFn{
+		"total": Sum("$.amount"),
+		"n":     Count(),
+		"avg":   Avg("$.amount"),
+		"max":   Max("$.amount"),
+		"users": CountDistinct("$.user"),
+		"first": First("$.user"),
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	want := `[{"avg":20,"first":"a","max":"n/a","n":3,"region":"east","total":40,"users":2},` +
+		`{"avg":5,"first":"b","max":5,"n":1,"region":"west","total":5,"users":1},` +
+		`{"avg":7,"first":null,"max":7,"n":1,"region":null,"total":7,"users":0}]`
+	if got.String() != want {
+		t.Errorf("Aggregate() = %v, want %v", got.String(), want)
+	}
+}
+
+func TestGroupByWithoutKey(t *testing.T) {
+	got, err := GroupBy(parseArray(t, orders), "").Aggregate(map[string]AggFn{
+		"n":     Count(),
+		"min":   Min("$.amount"),
+		"all":   Collect("$.region"),
+		"last":  Last("$.user"),
+		"total": Sum("$.amount"),
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	want := `[{"all":["east","west","east","east"],"last":"c","min":5,"n":5,"total":52}]`
+	if got.String() != want {
+		t.Errorf("Aggregate() = %v, want %v", got.String(), want)
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	tests := map[string]string{
+		"$.region":        "region",
+		"$.user.country":  "country",
+		"$['first name']": "first name",
+		"$.tags[0]":       "key",
+		"$":               "key",
+	}
+	for path, want := range tests {
+		a, err := NewAggregator(path, nil)
+		if err != nil {
+			t.Fatalf("NewAggregator(%q) error = %v", path, err)
+		}
+		if a.keyName != want {
+			t.Errorf("keyName(%q) = %q, want %q", path, a.keyName, want)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	fns, err := ParseSpec("total=sum($.amount), n=count(), u=distinct($['a,b'])")
+	if err != nil {
+		t.Fatalf("ParseSpec() error = %v", err)
+	}
+	if len(fns) != 3 || fns["total"].Path != "$.amount" || fns["n"].Path != "" || fns["u"].Path != "$['a,b']" {
+		t.Errorf("ParseSpec() = %+v", fns)
+	}
+
+	for _, spec := range []string{"total", "total=median($.a)", "total=sum()", "a=count(),a=count()", "x=sum($.a"} {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("ParseSpec(%q) 应该返回错误", spec)
+		}
+	}
+
+	if _, err := GroupBy(parseArray(t, orders), "region").Aggregate(nil); err == nil {
+		t.Error("无效的分组路径应该返回错误")
+	}
+}
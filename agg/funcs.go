@@ -0,0 +1,231 @@
+package agg
+
+import (
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// funcs 是ParseSpec支持的聚合函数，count以外的函数都需要一个路径参数
+var funcs = map[string]func(path string) AggFn{
+	"count":    func(string) AggFn { return Count() },
+	"distinct": CountDistinct,
+	"sum":      Sum,
+	"avg":      Avg,
+	"min":      Min,
+	"max":      Max,
+	"first":    First,
+	"last":     Last,
+	"collect":  Collect,
+}
+
+// ParseSpec 解析逗号分隔的聚合规格，例如"total=sum($.amount),n=count()"
+// 支持的函数有count、distinct、sum、avg、min、max、first、last和collect
+func ParseSpec(spec string) (map[string]AggFn, error) {
+	result := make(map[string]AggFn)
+	for _, item := range splitSpec(spec) {
+		item = strings.TrimSpace(item)
+		name, call, ok := strings.Cut(item, "=")
+		name, call = strings.TrimSpace(name), strings.TrimSpace(call)
+		open := strings.IndexByte(call, '(')
+		if !ok || name == "" || open < 0 || !strings.HasSuffix(call, ")") {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "无效的聚合规格: "+item+"，格式为 名称=函数(路径)")
+		}
+
+		fnName := strings.TrimSpace(call[:open])
+		path := strings.TrimSpace(call[open+1 : len(call)-1])
+		newFn, ok := funcs[fnName]
+		if !ok {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "不支持的聚合函数: "+fnName)
+		}
+		if fnName != "count" && path == "" {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "聚合函数"+fnName+"需要路径参数")
+		}
+		if _, exists := result[name]; exists {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "重复的聚合名称: "+name)
+		}
+		result[name] = newFn(path)
+	}
+	return result, nil
+}
+
+// splitSpec 按不在括号和引号中的逗号分割规格
+func splitSpec(spec string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(spec); i++ {
+		c := spec[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, spec[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(spec[start:]) != "" {
+		parts = append(parts, spec[start:])
+	}
+	return parts
+}
+
+// Count 返回统计分组中元素数量的聚合函数
+func Count() AggFn {
+	return AggFn{New: func() Accumulator { return &countAcc{} }}
+}
+
+// CountDistinct 返回统计路径所选值中不同值数量的聚合函数
+func CountDistinct(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator {
+		d, _ := utils.NewDeduper("")
+		return &distinctAcc{deduper: d}
+	}}
+}
+
+// Sum 返回对路径所选数字求和的聚合函数，非数字值被忽略
+func Sum(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &sumAcc{} }}
+}
+
+// Avg 返回对路径所选数字求平均值的聚合函数，非数字值被忽略，没有数字时结果为null
+func Avg(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &sumAcc{avg: true} }}
+}
+
+// Min 返回路径所选值中最小值的聚合函数，比较规则与utils.CompareValues相同
+func Min(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &extremeAcc{sign: -1} }}
+}
+
+// Max 返回路径所选值中最大值的聚合函数，比较规则与utils.CompareValues相同
+func Max(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &extremeAcc{sign: 1} }}
+}
+
+// First 返回路径所选的第一个值的聚合函数
+func First(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &firstAcc{} }}
+}
+
+// Last 返回路径所选的最后一个值的聚合函数
+func Last(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &lastAcc{} }}
+}
+
+// Collect 返回把路径所选的值收集为数组的聚合函数
+func Collect(path string) AggFn {
+	return AggFn{Path: path, New: func() Accumulator { return &collectAcc{values: types.NewJSONArray()} }}
+}
+
+type countAcc struct {
+	n int
+}
+
+func (a *countAcc) Add(types.JSONValue) { a.n++ }
+
+func (a *countAcc) Result() types.JSONValue { return types.NewJSONNumber(float64(a.n)) }
+
+type distinctAcc struct {
+	deduper *utils.Deduper
+	n       int
+}
+
+func (a *distinctAcc) Add(value types.JSONValue) {
+	if !a.deduper.IsDuplicate(value) {
+		a.n++
+	}
+}
+
+func (a *distinctAcc) Result() types.JSONValue { return types.NewJSONNumber(float64(a.n)) }
+
+type sumAcc struct {
+	avg   bool
+	sum   float64
+	count int
+}
+
+func (a *sumAcc) Add(value types.JSONValue) {
+	if !value.IsNumber() {
+		return
+	}
+	n, _ := value.AsNumber()
+	a.sum += n
+	a.count++
+}
+
+func (a *sumAcc) Result() types.JSONValue {
+	if !a.avg {
+		return types.NewJSONNumber(a.sum)
+	}
+	if a.count == 0 {
+		return types.NewJSONNull()
+	}
+	return types.NewJSONNumber(a.sum / float64(a.count))
+}
+
+type extremeAcc struct {
+	sign  int
+	value types.JSONValue
+}
+
+func (a *extremeAcc) Add(value types.JSONValue) {
+	if a.value == nil || utils.CompareValues(value, a.value) == a.sign {
+		a.value = value
+	}
+}
+
+func (a *extremeAcc) Result() types.JSONValue {
+	if a.value == nil {
+		return types.NewJSONNull()
+	}
+	return a.value
+}
+
+type firstAcc struct {
+	value types.JSONValue
+}
+
+func (a *firstAcc) Add(value types.JSONValue) {
+	if a.value == nil {
+		a.value = value
+	}
+}
+
+func (a *firstAcc) Result() types.JSONValue {
+	if a.value == nil {
+		return types.NewJSONNull()
+	}
+	return a.value
+}
+
+type lastAcc struct {
+	value types.JSONValue
+}
+
+func (a *lastAcc) Add(value types.JSONValue) { a.value = value }
+
+func (a *lastAcc) Result() types.JSONValue {
+	if a.value == nil {
+		return types.NewJSONNull()
+	}
+	return a.value
+}
+
+type collectAcc struct {
+	values *types.JSONArray
+}
+
+func (a *collectAcc) Add(value types.JSONValue) { a.values.Add(value) }
+
+func (a *collectAcc) Result() types.JSONValue { return a.values }
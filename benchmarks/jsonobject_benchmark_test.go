@@ -0,0 +1,76 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// objectSizes 对象基准测试使用的键数量
+var objectSizes = []int{1000, 100000}
+
+// makeKeys 生成指定数量的键
+func makeKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	return keys
+}
+
+// makeObject 生成包含指定键的对象
+func makeObject(keys []string) *types.JSONObject {
+	obj := types.NewJSONObject()
+	for i, key := range keys {
+		obj.PutNumber(key, float64(i))
+	}
+	return obj
+}
+
+// BenchmarkJSONObjectPut 基准测试向对象中插入大量键
+func BenchmarkJSONObjectPut(b *testing.B) {
+	for _, n := range objectSizes {
+		keys := makeKeys(n)
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				makeObject(keys)
+			}
+		})
+	}
+}
+
+// BenchmarkJSONObjectRemove 基准测试从对象中逐个移除所有键
+func BenchmarkJSONObjectRemove(b *testing.B) {
+	for _, n := range objectSizes {
+		keys := makeKeys(n)
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				obj := makeObject(keys)
+				b.StartTimer()
+				for _, key := range keys {
+					obj.Remove(key)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkJSONObjectIterate 基准测试按插入顺序遍历对象
+func BenchmarkJSONObjectIterate(b *testing.B) {
+	for _, n := range objectSizes {
+		obj := makeObject(makeKeys(n))
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count := 0
+				obj.ForEach(func(key string, value types.JSONValue) {
+					count++
+				})
+				if count != n {
+					b.Fatalf("ForEach() visited %d keys, want %d", count, n)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/UserLeeZJ/gojson/jsonpath"
 	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
 )
 
 // 测试数据
@@ -212,3 +213,86 @@ func BenchmarkJSONPathLargeResult(b *testing.B) {
 		})
 	}
 }
+
+// wildcardBenchmarkPaths 是在大数组上测试的通配符查询
+var wildcardBenchmarkPaths = []struct {
+	name string
+	path string
+}{
+	{"Items", "$.items[*]"},
+	{"Ids", "$.items[*].id"},
+	{"Tags", "$.items[*].tags[*]"},
+	{"NestedField", "$.items[*].nested.field"},
+}
+
+// largeWildcardValue 创建包含100000个元素的数组的JSON值
+func largeWildcardValue() types.JSONValue {
+	items := types.NewJSONArray()
+	for i := 0; i < 100000; i++ {
+		item := types.NewJSONObject()
+		item.PutNumber("id", float64(i))
+		item.Put("tags", types.NewJSONArray().AddString("a").AddString("b"))
+		item.Put("nested", types.NewJSONObject().PutNumber("field", float64(i)))
+		items.Add(item)
+	}
+	return types.NewJSONObject().Put("items", items)
+}
+
+// BenchmarkJSONPathWildcardLarge 基准测试在100000个元素的数组上的通配符查询
+func BenchmarkJSONPathWildcardLarge(b *testing.B) {
+	value := largeWildcardValue()
+
+	for _, bm := range wildcardBenchmarkPaths {
+		path, err := jsonpath.ParseJSONPath(bm.path)
+		if err != nil {
+			b.Fatalf("解析失败: %v", err)
+		}
+
+		b.Run(bm.name+"/Query", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := path.Query(value); err != nil {
+					b.Fatalf("查询失败: %v", err)
+				}
+			}
+		})
+
+		// 复用上一次的结果切片
+		b.Run(bm.name+"/QueryAppend", func(b *testing.B) {
+			b.ReportAllocs()
+			var results []types.JSONValue
+			for i := 0; i < b.N; i++ {
+				if results, err = path.QueryAppend(results[:0], value); err != nil {
+					b.Fatalf("查询失败: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkJSONPathParallel 比较在100000个元素的数组上串行和并行的通配符查询，
+// 使用-cpu参数比较不同的GOMAXPROCS
+func BenchmarkJSONPathParallel(b *testing.B) {
+	value := largeWildcardValue()
+
+	for _, bm := range wildcardBenchmarkPaths[1:] {
+		path, err := jsonpath.ParseJSONPath(bm.path)
+		if err != nil {
+			b.Fatalf("解析失败: %v", err)
+		}
+
+		for _, parallel := range []bool{false, true} {
+			name := bm.name + "/Serial"
+			if parallel {
+				name = bm.name + "/Parallel"
+			}
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := path.QueryWithOptions(value, jsonpath.QueryOptions{Parallel: parallel}); err != nil {
+						b.Fatalf("查询失败: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/UserLeeZJ/gojson/fast"
+	"github.com/UserLeeZJ/gojson/types"
 )
 
 // BenchmarkMarshal 基准测试Marshal函数
@@ -128,6 +129,38 @@ func BenchmarkLargeJSONStandardMarshal(b *testing.B) {
 	}
 }
 
+// BenchmarkLargeJSONObjectString 比较大型JSONObject使用流式编码和interface{}树序列化的性能和内存
+func BenchmarkLargeJSONObjectString(b *testing.B) {
+	items := types.NewJSONArray()
+	for i := 0; i < 10000; i++ {
+		items.Add(types.NewJSONObject().
+			PutNumber("id", float64(i)).
+			PutString("name", fmt.Sprintf("Item %d", i)).
+			PutNumber("value", float64(i)*1.5).
+			PutArray("tags", types.NewJSONArray().AddString("tag1").AddString("tag2")))
+	}
+	value := types.NewJSONObject().PutArray("items", items)
+
+	for _, bm := range []struct {
+		name      string
+		threshold int
+	}{
+		{"Streaming", 0},
+		{"Interface", -1},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			old := types.SetStreamingThreshold(bm.threshold)
+			defer types.SetStreamingThreshold(old)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if len(value.String()) == 0 {
+					b.Fatal("String() 返回空字符串")
+				}
+			}
+		})
+	}
+}
+
 // BenchmarkSimpleTypeMarshal 基准测试简单类型的序列化性能
 func BenchmarkSimpleTypeMarshal(b *testing.B) {
 	// 测试不同的简单类型
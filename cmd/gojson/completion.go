@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// completeCommand 是补全脚本调用的隐藏子命令，参数为光标之前的所有单词和当前单词
+const completeCommand = "__complete"
+
+// completionScripts 是各个shell的补全脚本，都通过 gojson __complete 获取候选项
+var completionScripts = map[string]string{
+	"bash": `# gojson bash 补全
+# 使用方法: source <(gojson completion bash)
+_gojson() {
+    local candidate
+    COMPREPLY=()
+    while IFS= read -r candidate; do
+        # JSON Path以$开头，加上单引号避免被shell展开
+        if [[ "$candidate" == \$* ]]; then
+            candidate="'$candidate'"
+        fi
+        COMPREPLY+=("$candidate")
+    done < <(gojson __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+}
+complete -o default -F _gojson gojson
+`,
+	"zsh": `#compdef gojson
+# gojson zsh 补全
+# 使用方法: source <(gojson completion zsh)，或保存为 fpath 中的 _gojson
+_gojson() {
+    local -a candidates
+    candidates=("${(@f)$(gojson __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -n "${candidates[1]}" ]]; then
+        compadd -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+if [[ "${funcstack[1]}" == "_gojson" ]]; then
+    _gojson "$@"
+else
+    compdef _gojson gojson
+fi
+`,
+	"fish": `# gojson fish 补全
+# 使用方法: gojson completion fish | source
+function __gojson_complete
+    set -l tokens (commandline -opc)
+    gojson __complete $tokens[2..-1] (commandline -ct | string collect --allow-empty) 2>/dev/null
+end
+complete -c gojson -a '(__gojson_complete)'
+`,
+}
+
+func init() {
+	cliutil.Register(cliutil.Command{
+		Name:        "completion",
+		Description: "生成shell补全脚本 (bash, zsh, fish)",
+		Run:         runCompletion,
+	})
+}
+
+// runCompletion 输出指定shell的补全脚本
+func runCompletion(args []string) int {
+	if len(args) != 1 || completionScripts[args[0]] == "" {
+		fmt.Fprintf(os.Stderr, "用法: gojson completion <bash|zsh|fish>\n")
+		return 2
+	}
+	fmt.Print(completionScripts[args[0]])
+	return 0
+}
+
+// complete 把候选项逐行写入w
+func complete(w io.Writer, exeDir string, args []string) {
+	for _, candidate := range completions(exeDir, args) {
+		fmt.Fprintln(w, candidate)
+	}
+}
+
+// completions 返回当前单词的候选项
+// args的第一个元素是子命令，最后一个元素是正在输入的单词
+// 返回空切片时由shell回退到文件名补全
+func completions(exeDir string, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current := strings.TrimLeft(args[len(args)-1], `"'`)
+
+	// 补全子命令
+	if len(args) == 1 {
+		if strings.HasPrefix(current, "-") {
+			return filterPrefix([]string{"--help", "--help-json", "--version"}, current)
+		}
+		names := make([]string, 0, len(subcommands))
+		for _, cmd := range subcommands {
+			names = append(names, cmd.name)
+		}
+		for _, cmd := range cliutil.Commands() {
+			names = append(names, cmd.Name)
+		}
+		for _, p := range plugins(exeDir) {
+			names = append(names, p.name)
+		}
+		sort.Strings(names)
+		return filterPrefix(names, current)
+	}
+	if args[0] == "completion" {
+		if len(args) == 2 {
+			return filterPrefix([]string{"bash", "fish", "zsh"}, current)
+		}
+		return nil
+	}
+
+	// 进程内的子命令没有 --help-json，插件不支持时同样回退到文件名补全
+	if _, ok := cliutil.LookupCommand(args[0]); ok {
+		return nil
+	}
+	path, ok := commandPath(exeDir, args[0])
+	if !ok {
+		return nil
+	}
+	flags, err := toolFlags(path)
+	if err != nil {
+		return nil
+	}
+
+	// 补全参数的值，JSON Path参数根据 -i 指定的文件补全
+	if f := lookupFlag(flags, args[len(args)-2]); f != nil && f.Type != "bool" {
+		if cliutil.IsPathFlag(*f) {
+			if input := flagValue(args[1:len(args)-1], "i"); input != "" && !cliutil.IsURL(input) {
+				return filterPrefix(jsonPaths(input), current)
+			}
+		}
+		return nil
+	}
+
+	// 补全参数名
+	if strings.HasPrefix(current, "-") {
+		names := make([]string, 0, len(flags))
+		for _, f := range flags {
+			names = append(names, "-"+f.Name)
+		}
+		return filterPrefix(names, current)
+	}
+	return nil
+}
+
+// toolFlags 通过 --help-json 获取工具支持的参数
+func toolFlags(path string) ([]cliutil.FlagInfo, error) {
+	output, err := exec.Command(path, cliutil.HelpJSONFlag).Output()
+	if err != nil {
+		return nil, err
+	}
+	var help cliutil.Help
+	if err := json.Unmarshal(output, &help); err != nil {
+		return nil, err
+	}
+	return help.Flags, nil
+}
+
+// lookupFlag 查找单词对应的参数，单词不是参数或已经以 = 带上值时返回nil
+func lookupFlag(flags []cliutil.FlagInfo, word string) *cliutil.FlagInfo {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return nil
+	}
+	name := strings.TrimLeft(word, "-")
+	for i := range flags {
+		if flags[i].Name == name {
+			return &flags[i]
+		}
+	}
+	return nil
+}
+
+// flagValue 返回参数在args中的值，支持 -name value 和 -name=value 两种写法
+func flagValue(args []string, name string) string {
+	value := ""
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		key, v, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if key != name {
+			continue
+		}
+		if hasValue {
+			value = v
+		} else if i+1 < len(args) {
+			value = args[i+1]
+		}
+	}
+	return value
+}
+
+// indexPattern 匹配路径中的数组下标
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// jsonPaths 返回文件中所有的JSON Path，并加入把数组下标替换为[*]的通配路径
+func jsonPaths(path string) []string {
+	data, err := parser.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	value, err := parser.ParseBytesToValue(data)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range utils.ExtractPaths(value) {
+		seen[p] = true
+		seen[indexPattern.ReplaceAllString(p, "[*]")] = true
+	}
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// filterPrefix 返回以prefix开头的候选项
+func filterPrefix(candidates []string, prefix string) []string {
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// subcommandInfo 描述 --help-json 输出中的一个子命令，进程内的子命令没有tool
+type subcommandInfo struct {
+	Name        string `json:"name"`
+	Tool        string `json:"tool,omitempty"`
+	Description string `json:"description,omitempty"`
+	Plugin      bool   `json:"plugin,omitempty"`
+}
+
+// writeHelpJSON 以JSON格式输出gojson的子命令列表，包括进程内的子命令和exeDir、PATH中的插件
+func writeHelpJSON(w io.Writer, exeDir string) error {
+	help := struct {
+		Name        string           `json:"name"`
+		Version     string           `json:"version"`
+		Description string           `json:"description"`
+		Subcommands []subcommandInfo `json:"subcommands"`
+	}{Name: "gojson", Version: version, Description: "JSON工具集"}
+	for _, cmd := range subcommands {
+		help.Subcommands = append(help.Subcommands, subcommandInfo{Name: cmd.name, Tool: cmd.tool, Description: cmd.description})
+	}
+	for _, cmd := range cliutil.Commands() {
+		help.Subcommands = append(help.Subcommands, subcommandInfo{Name: cmd.Name, Description: cmd.Description})
+	}
+	for _, p := range plugins(exeDir) {
+		help.Subcommands = append(help.Subcommands, subcommandInfo{Name: p.name, Tool: p.path, Plugin: true})
+	}
+	data, err := json.MarshalIndent(help, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompletionsSubcommands(t *testing.T) {
+	got := completions("", []string{"jo"})
+	if want := []string{"join", "join-on"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions(jo) = %v, 期望 %v", got, want)
+	}
+	got = completions("", []string{"completion", "z"})
+	if want := []string{"zsh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions(completion z) = %v, 期望 %v", got, want)
+	}
+}
+
+func TestJSONPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(`{"items":[{"id":1},{"id":2}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := filterPrefix(jsonPaths(path), "$.items[*]")
+	if want := []string{"$.items[*]", "$.items[*].id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonPaths() = %v, 期望 %v", got, want)
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-i", "a.json", "-p"}, "a.json"},
+		{[]string{"--i=b.json", "-c"}, "b.json"},
+		{[]string{"-o", "out.json"}, ""},
+	}
+	for _, tt := range tests {
+		if got := flagValue(tt.args, "i"); got != tt.want {
+			t.Errorf("flagValue(%v) = %q, 期望 %q", tt.args, got, tt.want)
+		}
+	}
+}
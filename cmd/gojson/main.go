@@ -6,12 +6,69 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
 )
 
 var (
 	version = "1.0.0" // 版本号
 )
 
+// subcommand 描述一个转发给独立工具的子命令
+type subcommand struct {
+	name        string // 子命令名称
+	tool        string // 工具的可执行文件名
+	description string // 简短说明
+}
+
+// subcommands 是所有子命令，按帮助信息中的顺序排列
+var subcommands = []subcommand{
+	{"format", "jsonformat", "格式化JSON (美化或压缩)"},
+	{"path", "jsonpath", "使用JSON Path查询JSON"},
+	{"analyze", "jsonanalyze", "分析JSON结构"},
+	{"stream", "jsonstream", "流式处理大型JSON文件"},
+	{"lint", "jsonlint", "检查JSON风格问题"},
+	{"gen", "jsongen", "根据JSON样本生成Go结构体"},
+	{"example", "jsonexample", "根据JSON Schema生成示例JSON"},
+	{"split", "jsonsplit", "把大型JSON文件拆分为多个小文件"},
+	{"join", "jsonjoin", "把多个JSON文件合并为一个数组"},
+	{"sort", "jsonsort", "按JSON Path选择的键对数组排序"},
+	{"dedup", "jsondedup", "去除数组中的重复元素"},
+	{"join-on", "jsonjoinon", "按键连接两个JSON数据集"},
+	{"agg", "jsonagg", "按键分组并计算统计值"},
+	{"validate", "jsonvalidate", "检查输入是否为合法的JSON"},
+	{"diff", "jsondiff", "比较两个JSON文档"},
+	{"merge", "jsonmerge", "深度合并多个JSON文档"},
+	{"run", "jsonrun", "按菜谱批量转换JSON文档"},
+}
+
+// findSubcommand 按名称查找子命令，不存在时返回nil
+func findSubcommand(name string) *subcommand {
+	for i := range subcommands {
+		if subcommands[i].name == name {
+			return &subcommands[i]
+		}
+	}
+	return nil
+}
+
+// isBuiltin 检查名称是否是内置的子命令或进程内注册的子命令，它们优先于同名的插件
+func isBuiltin(name string) bool {
+	if _, ok := cliutil.LookupCommand(name); ok {
+		return true
+	}
+	return findSubcommand(name) != nil || name == completeCommand
+}
+
+// toolPath 返回工具的路径，优先使用与gojson同目录的可执行文件，否则在PATH中查找
+func toolPath(exeDir, tool string) string {
+	path := filepath.Join(exeDir, tool)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return tool
+	}
+	return path
+}
+
 func main() {
 	// 检查命令行参数
 	if len(os.Args) < 2 {
@@ -20,14 +77,14 @@ func main() {
 	}
 
 	// 获取子命令
-	subcommand := os.Args[1]
+	name := os.Args[1]
 
 	// 处理版本和帮助命令
-	if subcommand == "-v" || subcommand == "--version" {
+	if name == "-v" || name == "--version" {
 		fmt.Printf("gojson version %s\n", version)
 		os.Exit(0)
 	}
-	if subcommand == "-h" || subcommand == "--help" {
+	if name == "-h" || name == "--help" {
 		printUsage()
 		os.Exit(0)
 	}
@@ -35,48 +92,61 @@ func main() {
 	// 获取可执行文件路径
 	exePath, err := os.Executable()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "获取可执行文件路径失败: %v\n", err)
+		cliutil.Error("获取可执行文件路径失败", err)
 		os.Exit(1)
 	}
 	exeDir := filepath.Dir(exePath)
 
-	// 构建子命令路径
-	var cmdPath string
-	switch subcommand {
-	case "format":
-		cmdPath = filepath.Join(exeDir, "jsonformat")
-	case "path":
-		cmdPath = filepath.Join(exeDir, "jsonpath")
-	case "analyze":
-		cmdPath = filepath.Join(exeDir, "jsonanalyze")
-	case "stream":
-		cmdPath = filepath.Join(exeDir, "jsonstream")
-	default:
-		fmt.Fprintf(os.Stderr, "未知的子命令: %s\n", subcommand)
+	if name == cliutil.HelpJSONFlag {
+		if err := writeHelpJSON(os.Stdout, exeDir); err != nil {
+			cliutil.Error("输出帮助信息失败", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// 处理补全脚本调用的隐藏子命令
+	if name == completeCommand {
+		complete(os.Stdout, exeDir, os.Args[2:])
+		return
+	}
+
+	// 依次查找进程内注册的子命令、内置工具和 gojson-<名称> 插件
+	if cmd, ok := cliutil.LookupCommand(name); ok {
+		os.Exit(cmd.Run(os.Args[2:]))
+	}
+	cmdPath, ok := commandPath(exeDir, name)
+	if !ok {
+		cliutil.UsageError("未知的子命令: %s", name)
 		printUsage()
 		os.Exit(1)
 	}
+	os.Exit(runTool(cmdPath, os.Args[2:]))
+}
 
-	// 检查子命令是否存在
-	if _, err := os.Stat(cmdPath); os.IsNotExist(err) {
-		// 尝试在PATH中查找
-		cmdPath = "json" + subcommand
+// commandPath 返回转发给外部可执行文件的子命令的路径，内置工具优先于插件
+func commandPath(exeDir, name string) (string, bool) {
+	if cmd := findSubcommand(name); cmd != nil {
+		return toolPath(exeDir, cmd.tool), true
 	}
+	return findPlugin(exeDir, name)
+}
 
-	// 执行子命令
-	cmd := exec.Command(cmdPath, os.Args[2:]...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// runTool 执行外部工具并返回它的退出码，标准输入输出直接传给工具
+func runTool(path string, args []string) int {
+	child := exec.Command(path, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := child.Run(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
-		} else {
-			fmt.Fprintf(os.Stderr, "执行子命令失败: %v\n", err)
-			os.Exit(1)
+			return exitErr.ExitCode()
 		}
+		cliutil.Error("执行子命令失败", err)
+		return 1
 	}
+	return 0
 }
 
 func printUsage() {
@@ -84,17 +154,44 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "用法:\n")
 	fmt.Fprintf(os.Stderr, "  gojson <子命令> [选项]\n\n")
 	fmt.Fprintf(os.Stderr, "子命令:\n")
-	fmt.Fprintf(os.Stderr, "  format   格式化JSON (美化或压缩)\n")
-	fmt.Fprintf(os.Stderr, "  path     使用JSON Path查询JSON\n")
-	fmt.Fprintf(os.Stderr, "  analyze  分析JSON结构\n")
-	fmt.Fprintf(os.Stderr, "  stream   流式处理大型JSON文件\n\n")
+	for _, cmd := range subcommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	for _, cmd := range cliutil.Commands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.Name, cmd.Description)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+	if exePath, err := os.Executable(); err == nil {
+		if found := plugins(filepath.Dir(exePath)); len(found) > 0 {
+			fmt.Fprintf(os.Stderr, "插件 (gojson-<名称>):\n")
+			for _, p := range found {
+				fmt.Fprintf(os.Stderr, "  %-10s %s\n", p.name, p.path)
+			}
+			fmt.Fprintf(os.Stderr, "\n")
+		}
+	}
 	fmt.Fprintf(os.Stderr, "全局选项:\n")
 	fmt.Fprintf(os.Stderr, "  -v, --version  显示版本信息\n")
-	fmt.Fprintf(os.Stderr, "  -h, --help     显示帮助信息\n\n")
+	fmt.Fprintf(os.Stderr, "  -h, --help     显示帮助信息\n")
+	fmt.Fprintf(os.Stderr, "  --help-json    以JSON格式输出子命令列表，子命令也支持该参数\n\n")
 	fmt.Fprintf(os.Stderr, "示例:\n")
 	fmt.Fprintf(os.Stderr, "  gojson format -i input.json -o output.json -p\n")
 	fmt.Fprintf(os.Stderr, "  gojson path -i input.json -p \"$.store.book[0].title\"\n")
 	fmt.Fprintf(os.Stderr, "  gojson analyze -i input.json -paths\n")
-	fmt.Fprintf(os.Stderr, "  gojson stream -i large.json -f \"$.items[*].name\"\n\n")
+	fmt.Fprintf(os.Stderr, "  gojson stream -i large.json -f \"$.items[*].name\"\n")
+	fmt.Fprintf(os.Stderr, "  gojson lint -i input.json -strict\n")
+	fmt.Fprintf(os.Stderr, "  gojson gen -i sample.json -pkg models\n")
+	fmt.Fprintf(os.Stderr, "  gojson example -schema api.json#/components/schemas/User\n")
+	fmt.Fprintf(os.Stderr, "  gojson split -i big.json -f \"$.items[*]\" -chunk 10000 -o out-%%03d.json\n")
+	fmt.Fprintf(os.Stderr, "  gojson join -o merged.json out-*.json\n")
+	fmt.Fprintf(os.Stderr, "  gojson sort -i data.json -by \"$.createdAt\" -desc\n")
+	fmt.Fprintf(os.Stderr, "  gojson dedup -i events.json -by \"$.id\" -keep last\n")
+	fmt.Fprintf(os.Stderr, "  gojson join-on -left orders.json -right users.json -left-key \"$.userId\" -right-key \"$.id\"\n")
+	fmt.Fprintf(os.Stderr, "  gojson agg -i orders.json -by \"$.region\" -agg \"total=sum($.amount),n=count()\"\n")
+	fmt.Fprintf(os.Stderr, "  gojson validate -q config.json\n")
+	fmt.Fprintf(os.Stderr, "  gojson diff old.json new.json\n")
+	fmt.Fprintf(os.Stderr, "  gojson merge a.json b.json\n")
+	fmt.Fprintf(os.Stderr, "  gojson run recipe.json -i data.json\n")
+	fmt.Fprintf(os.Stderr, "  source <(gojson completion bash)\n\n")
 	fmt.Fprintf(os.Stderr, "使用 'gojson <子命令> --help' 获取子命令的详细帮助信息\n")
 }
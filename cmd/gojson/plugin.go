@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// pluginPrefix 是外部插件可执行文件名的前缀，可执行文件 gojson-<名称> 提供子命令 <名称>
+const pluginPrefix = "gojson-"
+
+// plugin 描述找到的外部插件
+type plugin struct {
+	name string // 子命令名称
+	path string // 可执行文件的路径
+}
+
+// pluginDirs 返回查找插件的目录，先是gojson所在的目录，然后是PATH中的目录
+func pluginDirs(exeDir string) []string {
+	dirs := []string{exeDir}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		// 与exec.LookPath不同，PATH中的空目录不表示当前目录
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// pluginName 根据文件名返回插件的子命令名称，文件不是插件时返回空字符串
+func pluginName(file string) string {
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(file)
+		if !strings.EqualFold(ext, ".exe") {
+			return ""
+		}
+		file = strings.TrimSuffix(file, ext)
+	}
+	if !strings.HasPrefix(file, pluginPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(file, pluginPrefix)
+}
+
+// isExecutable 检查文件是否是可执行的普通文件，Windows上只检查扩展名
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// findPlugin 查找子命令对应的插件，按pluginDirs的顺序使用第一个找到的可执行文件
+func findPlugin(exeDir, name string) (string, bool) {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	file := pluginPrefix + name
+	if runtime.GOOS == "windows" {
+		file += ".exe"
+	}
+	for _, dir := range pluginDirs(exeDir) {
+		path := filepath.Join(dir, file)
+		if isExecutable(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// plugins 返回所有目录中的插件，按名称排序，同名的插件使用先找到的。
+// 与内置子命令或进程内子命令同名的插件不会被执行，因此不包括在结果中
+func plugins(exeDir string) []plugin {
+	seen := make(map[string]bool)
+	var result []plugin
+	for _, dir := range pluginDirs(exeDir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := pluginName(entry.Name())
+			if name == "" || seen[name] || isBuiltin(name) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			result = append(result, plugin{name: name, path: path})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("插件通过可执行权限识别")
+	}
+	exeDir, first, second := t.TempDir(), t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(first, "gojson-hello", 0755)
+	write(second, "gojson-hello", 0755)
+	write(second, "gojson-lint-org", 0755)
+	write(second, "gojson-noexec", 0644)
+	write(second, "gojson-format", 0755) // 与内置子命令同名
+	write(second, "other", 0755)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	var names []string
+	for _, p := range plugins(exeDir) {
+		names = append(names, p.name)
+	}
+	if want := []string{"hello", "lint-org"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("plugins() = %v, 期望 %v", names, want)
+	}
+
+	if path, ok := findPlugin(exeDir, "hello"); !ok || path != filepath.Join(first, "gojson-hello") {
+		t.Errorf("findPlugin(hello) = %s, %v", path, ok)
+	}
+	for _, name := range []string{"noexec", "missing", "../hello", "-h"} {
+		if _, ok := findPlugin(exeDir, name); ok {
+			t.Errorf("findPlugin(%s) 不应该找到插件", name)
+		}
+	}
+
+	// gojson所在目录中的插件优先
+	write(exeDir, "gojson-hello", 0755)
+	if path, _ := findPlugin(exeDir, "hello"); path != filepath.Join(exeDir, "gojson-hello") {
+		t.Errorf("findPlugin(hello) = %s, 期望gojson所在目录中的插件", path)
+	}
+
+	got := completions(exeDir, []string{"l"})
+	if want := []string{"lint", "lint-org"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("completions(l) = %v, 期望 %v", got, want)
+	}
+}
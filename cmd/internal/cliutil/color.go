@@ -0,0 +1,83 @@
+package cliutil
+
+import "strings"
+
+// ANSI颜色代码
+const (
+	colorReset   = "\x1b[0m"
+	colorKey     = "\x1b[34;1m" // 对象的键
+	colorString  = "\x1b[32m"   // 字符串
+	colorNumber  = "\x1b[36m"   // 数字
+	colorLiteral = "\x1b[33m"   // true、false和null
+)
+
+// Colorize 为格式化后的JSON文本加上ANSI颜色，text必须是合法的JSON
+func Colorize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) * 2)
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := stringEnd(text, i)
+			color := colorString
+			if isKey(text, end) {
+				color = colorKey
+			}
+			b.WriteString(color)
+			b.WriteString(text[i:end])
+			b.WriteString(colorReset)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			b.WriteString(colorNumber)
+			b.WriteString(text[i:end])
+			b.WriteString(colorReset)
+			i = end
+		case c == 't' || c == 'f' || c == 'n':
+			end := i + 1
+			for end < len(text) && text[end] >= 'a' && text[end] <= 'z' {
+				end++
+			}
+			b.WriteString(colorLiteral)
+			b.WriteString(text[i:end])
+			b.WriteString(colorReset)
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// stringEnd 返回从start开始的字符串字面量结束后的位置
+func stringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// isKey 检查位置pos之后的第一个非空白字符是否为冒号
+func isKey(text string, pos int) bool {
+	for ; pos < len(text); pos++ {
+		switch text[pos] {
+		case ' ', '\t', '\n', '\r':
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
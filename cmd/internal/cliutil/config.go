@@ -0,0 +1,136 @@
+package cliutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// Config 是命令行工具的配置，命令行参数的优先级高于配置。
+// 目前只有jsonformat、jsonpath、jsonstream和jsonsort调用LoadConfig，新增配置项或读取配置的工具时同时更新cmd/README.md中的说明
+type Config struct {
+	// Indent 是美化输出的缩进字符串
+	Indent string `json:"indent"`
+	// Color 控制是否为输出着色 (auto, always, never)
+	Color string `json:"color"`
+	// SortKeys 表示jsonformat是否默认排序键
+	SortKeys bool `json:"sortKeys"`
+	// SortOrder 是jsonsort的默认排序方向 (asc, desc)
+	SortOrder string `json:"sortOrder"`
+}
+
+// defaultConfig 是没有配置文件和环境变量时使用的配置
+const defaultConfig = `{"indent": "  ", "color": "auto", "sortKeys": false, "sortOrder": "asc"}`
+
+// envOverrides 把环境变量映射到配置项
+var envOverrides = []struct {
+	env  string
+	key  string
+	bool bool
+}{
+	{"GOJSON_INDENT", "indent", false},
+	{"GOJSON_COLOR", "color", false},
+	{"GOJSON_SORT_KEYS", "sortKeys", true},
+	{"GOJSON_SORT_ORDER", "sortOrder", false},
+}
+
+// ConfigPath 返回配置文件的路径
+// 优先使用环境变量GOJSON_CONFIG，否则为用户配置目录下的 gojson/config.json
+func ConfigPath() string {
+	if path := os.Getenv("GOJSON_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gojson", "config.json")
+}
+
+// LoadConfig 加载配置：在默认配置上依次合并配置文件和环境变量
+// 配置文件不存在时忽略
+func LoadConfig() (*Config, error) {
+	merged := defaultConfig
+
+	// 合并配置文件
+	if path := ConfigPath(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if err == nil {
+			if merged, err = utils.MergeJSON(merged, string(data)); err != nil {
+				return nil, fmt.Errorf("配置文件 %s 无效: %w", path, err)
+			}
+		}
+	}
+
+	// 合并环境变量
+	overrides := types.NewJSONObject()
+	for _, o := range envOverrides {
+		value, ok := os.LookupEnv(o.env)
+		if !ok {
+			continue
+		}
+		if o.bool {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("环境变量 %s 的值无效: %s", o.env, value)
+			}
+			overrides.PutBoolean(o.key, b)
+		} else {
+			overrides.PutString(o.key, value)
+		}
+	}
+	if overrides.Size() > 0 {
+		var err error
+		if merged, err = utils.MergeJSON(merged, overrides.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	var config Config
+	if err := parser.Parse(merged, &config); err != nil {
+		return nil, fmt.Errorf("配置无效: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// validate 检查配置项的取值
+func (c *Config) validate() error {
+	switch c.Color {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("配置项 color 只能是 auto、always 或 never: %s", c.Color)
+	}
+	switch c.SortOrder {
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("配置项 sortOrder 只能是 asc 或 desc: %s", c.SortOrder)
+	}
+	return nil
+}
+
+// UseColor 检查输出到f时是否着色
+// color为auto时只在f是终端且没有设置NO_COLOR环境变量时着色
+func (c *Config) UseColor(f *os.File) bool {
+	switch c.Color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
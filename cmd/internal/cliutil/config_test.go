@@ -0,0 +1,65 @@
+package cliutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("GOJSON_CONFIG", path)
+
+	// 没有配置文件时使用默认配置
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Indent != "  " || config.Color != "auto" || config.SortKeys || config.SortOrder != "asc" {
+		t.Errorf("默认配置 = %+v", config)
+	}
+
+	// 配置文件覆盖默认值，未指定的项保持默认
+	if err := os.WriteFile(path, []byte(`{"indent": "\t", "sortKeys": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Indent != "\t" || !config.SortKeys || config.Color != "auto" {
+		t.Errorf("配置文件 = %+v", config)
+	}
+
+	// 环境变量覆盖配置文件
+	t.Setenv("GOJSON_SORT_KEYS", "false")
+	t.Setenv("GOJSON_SORT_ORDER", "desc")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.SortKeys || config.SortOrder != "desc" || config.Indent != "\t" {
+		t.Errorf("环境变量 = %+v", config)
+	}
+
+	// 无效的取值
+	t.Setenv("GOJSON_COLOR", "rainbow")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("无效的color应该返回错误")
+	}
+	t.Setenv("GOJSON_COLOR", "never")
+	t.Setenv("GOJSON_SORT_KEYS", "maybe")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("无效的布尔值应该返回错误")
+	}
+}
+
+func TestColorize(t *testing.T) {
+	got := Colorize(`{"a": "x\"y", "b": [1.5, true, null]}`)
+	want := `{` + colorKey + `"a"` + colorReset + `: ` + colorString + `"x\"y"` + colorReset + `, ` +
+		colorKey + `"b"` + colorReset + `: [` + colorNumber + `1.5` + colorReset + `, ` +
+		colorLiteral + `true` + colorReset + `, ` + colorLiteral + `null` + colorReset + `]}`
+	if got != want {
+		t.Errorf("Colorize() = %q, 期望 %q", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+// Package cliutil 包含命令行工具共用的辅助函数
+package cliutil
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// HelpJSONFlag 是输出机器可读帮助信息的参数
+const HelpJSONFlag = "--help-json"
+
+// FlagInfo 描述一个命令行参数
+type FlagInfo struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage"`
+}
+
+// Help 是工具的机器可读帮助信息
+type Help struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Flags       []FlagInfo `json:"flags"`
+}
+
+// DescribeFlags 返回参数集中所有参数的描述，按名称排序
+func DescribeFlags(fs *flag.FlagSet) []FlagInfo {
+	flags := make([]FlagInfo, 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		typeName, usage := flag.UnquoteUsage(f)
+		if typeName == "" {
+			typeName = "bool"
+		}
+		flags = append(flags, FlagInfo{
+			Name:    f.Name,
+			Type:    typeName,
+			Default: f.DefValue,
+			Usage:   usage,
+		})
+	})
+	return flags
+}
+
+// WriteHelpJSON 把工具的帮助信息以JSON格式写入w
+func WriteHelpJSON(w io.Writer, name, description string, fs *flag.FlagSet) error {
+	data, err := json.MarshalIndent(Help{
+		Name:        name,
+		Description: description,
+		Flags:       DescribeFlags(fs),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// HandleHelpJSON 在命令行参数中包含 --help-json 时输出帮助信息并退出
+// 需要在定义完所有参数之后、调用flag.Parse之前调用
+func HandleHelpJSON(name, description string) {
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			return
+		}
+		if arg == HelpJSONFlag || arg == strings.TrimPrefix(HelpJSONFlag, "-") {
+			if err := WriteHelpJSON(os.Stdout, name, description, flag.CommandLine); err != nil {
+				fmt.Fprintf(os.Stderr, "输出帮助信息失败: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+}
+
+// IsPathFlag 检查参数的值是否为JSON Path表达式
+func IsPathFlag(f FlagInfo) bool {
+	return f.Type == "string" && strings.Contains(f.Usage, "JSON Path")
+}
@@ -0,0 +1,45 @@
+package cliutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestWriteHelpJSON(t *testing.T) {
+	fs := flag.NewFlagSet("jsontest", flag.ContinueOnError)
+	fs.String("i", "", "输入文件路径")
+	fs.String("p", "$", "JSON Path表达式")
+	fs.Bool("q", false, "静默模式")
+	fs.Duration("timeout", 30*time.Second, "超时时间")
+
+	var buf bytes.Buffer
+	if err := WriteHelpJSON(&buf, "jsontest", "测试工具", fs); err != nil {
+		t.Fatalf("WriteHelpJSON() error = %v", err)
+	}
+
+	var help Help
+	if err := json.Unmarshal(buf.Bytes(), &help); err != nil {
+		t.Fatalf("输出不是合法的JSON: %v", err)
+	}
+	if help.Name != "jsontest" || len(help.Flags) != 4 {
+		t.Fatalf("help = %+v", help)
+	}
+
+	types := map[string]string{}
+	for _, f := range help.Flags {
+		types[f.Name] = f.Type
+	}
+	want := map[string]string{"i": "string", "p": "string", "q": "bool", "timeout": "duration"}
+	for name, typeName := range want {
+		if types[name] != typeName {
+			t.Errorf("参数 %s 的类型 = %q, 期望 %q", name, types[name], typeName)
+		}
+	}
+
+	if !IsPathFlag(help.Flags[1]) || IsPathFlag(help.Flags[0]) {
+		t.Errorf("IsPathFlag() 结果错误: %+v", help.Flags[:2])
+	}
+}
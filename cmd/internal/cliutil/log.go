@@ -0,0 +1,160 @@
+package cliutil
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// 诊断信息的输出格式
+const (
+	LogText = "text" // 纯文本，与原有的输出相同
+	LogJSON = "json" // 每条诊断信息为一行JSON
+)
+
+// logFormat 是当前的输出格式
+var logFormat = LogText
+
+// logOutput 是诊断信息的输出位置
+var logOutput io.Writer = os.Stderr
+
+// Diagnostic 是JSON格式的诊断信息，错误相关的字段取自JSONError
+type Diagnostic struct {
+	Level   string `json:"level"`
+	Tool    string `json:"tool"`
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// logFormatValue 实现flag.Value，只接受支持的格式
+type logFormatValue struct{}
+
+func (logFormatValue) String() string { return logFormat }
+
+func (logFormatValue) Set(value string) error {
+	if value != LogText && value != LogJSON {
+		return fmt.Errorf("只能是 %s 或 %s", LogText, LogJSON)
+	}
+	logFormat = value
+	return nil
+}
+
+// RegisterLogFlag 注册 -log-format 参数
+func RegisterLogFlag() {
+	flag.Var(logFormatValue{}, "log-format", "标准错误上诊断信息的格式 (text, json)")
+}
+
+// Error 输出一条错误，prefix说明失败的操作
+// err包含JSONError时，JSON格式的输出带有其中的错误代码和路径
+func Error(prefix string, err error) {
+	ErrorInput(prefix, err, nil)
+}
+
+// ErrorInput 与Error相同，并根据input计算语法错误所在的行和列
+func ErrorInput(prefix string, err error, input []byte) {
+	if logFormat != LogJSON {
+		if prefix == "" {
+			fmt.Fprintf(logOutput, "%v\n", err)
+		} else {
+			fmt.Fprintf(logOutput, "%s: %v\n", prefix, err)
+		}
+		return
+	}
+
+	d := Diagnostic{Level: "error", Message: prefix, Error: err.Error()}
+	if d.Message == "" {
+		d.Message = d.Error
+	}
+	var jsonErr *jsonerrors.JSONError
+	if errors.As(err, &jsonErr) {
+		d.Code = string(jsonErr.Code)
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if je, ok := e.(*jsonerrors.JSONError); ok && je.Path != "" {
+			d.Path = je.Path
+			break
+		}
+	}
+	if offset, ok := errorOffset(err); ok && input != nil {
+		d.Line, d.Column = lineColumn(input, offset)
+	}
+	writeDiagnostic(d)
+}
+
+// Errorf 输出一条没有对应error值的错误
+func Errorf(format string, args ...interface{}) {
+	logf("error", "", format, args...)
+}
+
+// UsageError 输出一条参数错误，文本格式带有"错误: "前缀
+func UsageError(format string, args ...interface{}) {
+	logf("error", "USAGE", format, args...)
+}
+
+// Infof 输出一条提示信息
+func Infof(format string, args ...interface{}) {
+	logf("info", "", format, args...)
+}
+
+// logf 按当前格式输出一条诊断信息
+func logf(level, code, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if logFormat != LogJSON {
+		if code == "USAGE" {
+			message = "错误: " + message
+		}
+		fmt.Fprintln(logOutput, message)
+		return
+	}
+	writeDiagnostic(Diagnostic{Level: level, Message: message, Code: code})
+}
+
+// writeDiagnostic 把诊断信息写为一行JSON
+func writeDiagnostic(d Diagnostic) {
+	d.Tool = filepath.Base(os.Args[0])
+	data, err := json.Marshal(d)
+	if err != nil {
+		fmt.Fprintln(logOutput, d.Message)
+		return
+	}
+	fmt.Fprintf(logOutput, "%s\n", data)
+}
+
+// errorOffset 返回错误链中语法错误的字节偏移量
+func errorOffset(err error) (int64, bool) {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset, true
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset, true
+	}
+	return 0, false
+}
+
+// lineColumn 把字节偏移量转换为从1开始的行号和列号
+// encoding/json报告的偏移量指向出错字符之后，因此列号指向出错的字符
+func lineColumn(input []byte, offset int64) (int, int) {
+	if offset > int64(len(input)) {
+		offset = int64(len(input))
+	}
+	before := string(input[:offset])
+	line := strings.Count(before, "\n") + 1
+	column := len([]rune(before[strings.LastIndex(before, "\n")+1:]))
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
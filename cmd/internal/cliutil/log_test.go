@@ -0,0 +1,59 @@
+package cliutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+// captureLog 在指定格式下执行fn并返回输出的诊断信息
+func captureLog(t *testing.T, format string, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	oldFormat, oldOutput := logFormat, logOutput
+	logFormat, logOutput = format, &buf
+	defer func() { logFormat, logOutput = oldFormat, oldOutput }()
+	fn()
+	return buf.String()
+}
+
+func TestErrorInputJSON(t *testing.T) {
+	input := []byte("{\n  \"名称\": bad}")
+	_, err := parser.ParseBytesToValue(input)
+	if err == nil {
+		t.Fatal("期望解析错误")
+	}
+
+	output := captureLog(t, LogJSON, func() { ErrorInput("解析JSON失败", err, input) })
+	var d Diagnostic
+	if err := json.Unmarshal([]byte(output), &d); err != nil {
+		t.Fatalf("输出不是合法的JSON: %q", output)
+	}
+	if d.Level != "error" || d.Message != "解析JSON失败" || d.Code != "INVALID_JSON" {
+		t.Errorf("诊断信息 = %+v", d)
+	}
+	if d.Line != 2 || d.Column != 9 {
+		t.Errorf("位置 = %d:%d, 期望 2:9", d.Line, d.Column)
+	}
+}
+
+func TestLogText(t *testing.T) {
+	_, err := parser.ReadFile("does-not-exist.json")
+	output := captureLog(t, LogText, func() {
+		Error("读取输入失败", err)
+		UsageError("必须指定 %s", "-by")
+		Infof("已写入 %d 个文件", 2)
+	})
+	want := "读取输入失败: " + err.Error() + "\n错误: 必须指定 -by\n已写入 2 个文件\n"
+	if output != want {
+		t.Errorf("输出 = %q, 期望 %q", output, want)
+	}
+
+	output = captureLog(t, LogJSON, func() { Error("读取输入失败", err) })
+	var d Diagnostic
+	if err := json.Unmarshal([]byte(output), &d); err != nil || d.Path != "does-not-exist.json" {
+		t.Errorf("诊断信息 = %q", output)
+	}
+}
@@ -0,0 +1,54 @@
+package cliutil
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallel 用最多jobs个goroutine对0到n-1的每个索引调用work，
+// 并在当前goroutine中按索引顺序对结果调用emit，因此输出顺序与输入顺序一致。
+// jobs小于1时按1处理。
+func Parallel[T any](jobs, n int, work func(i int) T, emit func(i int, result T)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > n {
+		jobs = n
+	}
+
+	results := make([]chan T, n)
+	for i := range results {
+		results[i] = make(chan T, 1)
+	}
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] <- work(i)
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	for i := 0; i < n; i++ {
+		emit(i, <-results[i])
+	}
+	wg.Wait()
+}
+
+// Jobs 把 -j 参数转换为并发数，0或负数表示使用所有CPU
+func Jobs(n int) int {
+	if n <= 0 {
+		return runtime.NumCPU()
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package cliutil
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var running, maxRunning int32
+	var order []int
+	Parallel(3, 20, func(i int) int {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(20-i) * time.Millisecond / 4)
+		atomic.AddInt32(&running, -1)
+		return i * i
+	}, func(i int, result int) {
+		if result != i*i {
+			t.Errorf("结果 %d = %d", i, result)
+		}
+		order = append(order, i)
+	})
+
+	for i, got := range order {
+		if got != i {
+			t.Fatalf("输出顺序 = %v", order)
+		}
+	}
+	if len(order) != 20 {
+		t.Errorf("输出数量 = %d", len(order))
+	}
+	if maxRunning > 3 {
+		t.Errorf("同时运行的任务数 = %d, 不应超过3", maxRunning)
+	}
+
+	// 没有任务时立即返回
+	Parallel(4, 0, func(int) int { return 0 }, func(int, int) { t.Error("不应该调用emit") })
+}
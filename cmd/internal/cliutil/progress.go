@@ -0,0 +1,142 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+// progressInterval 是进度条的最短刷新间隔
+const progressInterval = 200 * time.Millisecond
+
+// progressWidth 是进度条的字符宽度
+const progressWidth = 30
+
+// ProgressBar 在标准错误上绘制进度条
+type ProgressBar struct {
+	out       io.Writer
+	start     time.Time
+	last      time.Time
+	lineWidth int
+}
+
+// NewProgressBar 创建一个新的进度条
+func NewProgressBar() *ProgressBar {
+	return &ProgressBar{out: os.Stderr, start: time.Now()}
+}
+
+// Update 按进度刷新进度条，可以直接作为stream.ProgressFunc使用
+func (b *ProgressBar) Update(p stream.Progress) {
+	now := time.Now()
+	if !p.Done && now.Sub(b.last) < progressInterval {
+		return
+	}
+	b.last = now
+
+	var line strings.Builder
+	if fraction, ok := p.Fraction(); ok {
+		filled := int(fraction * progressWidth)
+		line.WriteString("[")
+		line.WriteString(strings.Repeat("=", filled))
+		if filled < progressWidth {
+			line.WriteString(">")
+			line.WriteString(strings.Repeat(" ", progressWidth-filled-1))
+		}
+		fmt.Fprintf(&line, "] %5.1f%%  %s / %s", fraction*100, formatBytes(p.BytesRead), formatBytes(p.TotalBytes))
+	} else {
+		line.WriteString(formatBytes(p.BytesRead))
+	}
+	fmt.Fprintf(&line, "  %d 个元素", p.Elements)
+	if elapsed := now.Sub(b.start).Seconds(); elapsed > 0 {
+		fmt.Fprintf(&line, "  %s/s", formatBytes(int64(float64(p.BytesRead)/elapsed)))
+	}
+
+	// 用空格覆盖上一次较长的输出
+	text := line.String()
+	width := len([]rune(text))
+	if width < b.lineWidth {
+		text += strings.Repeat(" ", b.lineWidth-width)
+	}
+	b.lineWidth = width
+	fmt.Fprintf(b.out, "\r%s", text)
+	if p.Done {
+		fmt.Fprintln(b.out)
+	}
+}
+
+// formatBytes 把字节数格式化为便于阅读的形式
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
+
+// CountedInput 是统计了读取字节数的输入
+type CountedInput struct {
+	io.ReadCloser
+	counter *stream.CountingReader
+	total   int64
+	file    *os.File
+}
+
+// OpenCounted 打开输入并统计读取的字节数，path为空时读取标准输入
+// 输入是普通文件时可以显示完成比例；压缩的输入按压缩后的字节统计
+func OpenCounted(path string) (*CountedInput, error) {
+	file := os.Stdin
+	if path != "" {
+		var err error
+		file, err = os.Open(path)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "打开文件失败").WithPath(path).WithCause(err)
+		}
+	}
+
+	input := &CountedInput{counter: stream.NewCountingReader(file)}
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+		input.total = info.Size()
+	}
+	if path != "" {
+		input.file = file
+	}
+
+	reader, err := parser.NewDecompressReader(input.counter)
+	if err != nil {
+		input.Close()
+		return nil, err
+	}
+	input.ReadCloser = reader
+	return input, nil
+}
+
+// WithProgress 包装数据源，在标准错误上显示读取输入的进度条
+// 提前停止读取时应调用返回值的Finish结束进度条
+func (c *CountedInput) WithProgress(source stream.ValueSource) *stream.ProgressSource {
+	return stream.NewProgressSource(source, c.counter, c.total, NewProgressBar().Update)
+}
+
+// Close 关闭输入，不会关闭标准输入
+func (c *CountedInput) Close() error {
+	var err error
+	if c.ReadCloser != nil {
+		err = c.ReadCloser.Close()
+	}
+	if c.file != nil {
+		if closeErr := c.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
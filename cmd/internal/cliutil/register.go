@@ -0,0 +1,56 @@
+package cliutil
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Command 是在gojson进程中执行的子命令，与转发给独立工具的子命令不同，不需要单独的可执行文件
+type Command struct {
+	Name        string // 子命令名称
+	Description string // 简短说明，显示在gojson的帮助信息中
+	// Run 执行子命令，args不包括子命令名称，返回进程的退出码
+	Run func(args []string) int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Command)
+)
+
+// Register 注册一个进程内的子命令，通常在init函数中调用。
+// 名称为空、Run为nil或者名称已经注册过时panic
+func Register(cmd Command) {
+	if cmd.Name == "" || cmd.Run == nil {
+		panic("cliutil: 注册的子命令必须有名称和Run")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[cmd.Name]; ok {
+		panic(fmt.Sprintf("cliutil: 子命令%q重复注册", cmd.Name))
+	}
+	registry[cmd.Name] = cmd
+}
+
+// LookupCommand 按名称查找注册的子命令
+func LookupCommand(name string) (Command, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	cmd, ok := registry[name]
+	return cmd, ok
+}
+
+// Commands 返回所有注册的子命令，按名称排序
+func Commands() []Command {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	commands := make([]Command, 0, len(registry))
+	for _, cmd := range registry {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
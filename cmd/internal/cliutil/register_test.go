@@ -0,0 +1,39 @@
+package cliutil
+
+import "testing"
+
+func TestRegister(t *testing.T) {
+	Register(Command{Name: "test-b", Run: func([]string) int { return 2 }})
+	Register(Command{Name: "test-a", Description: "a", Run: func(args []string) int { return len(args) }})
+
+	cmd, ok := LookupCommand("test-a")
+	if !ok || cmd.Description != "a" || cmd.Run([]string{"x", "y"}) != 2 {
+		t.Errorf("LookupCommand(test-a) = %+v, %v", cmd, ok)
+	}
+	if _, ok := LookupCommand("test-c"); ok {
+		t.Errorf("LookupCommand(test-c) 不应该存在")
+	}
+
+	var names []string
+	for _, cmd := range Commands() {
+		names = append(names, cmd.Name)
+	}
+	if len(names) != 2 || names[0] != "test-a" || names[1] != "test-b" {
+		t.Errorf("Commands() = %v", names)
+	}
+
+	for _, cmd := range []Command{
+		{Name: "test-a", Run: func([]string) int { return 0 }},
+		{Name: "", Run: func([]string) int { return 0 }},
+		{Name: "test-d"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) 应该panic", cmd.Name)
+				}
+			}()
+			Register(cmd)
+		}()
+	}
+}
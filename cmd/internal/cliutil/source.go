@@ -0,0 +1,195 @@
+package cliutil
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+// DefaultMaxSourceSize 是HTTP(S)输入解压后默认允许的最大字节数
+const DefaultMaxSourceSize = 1 << 30
+
+// SourceOptions 是OpenSource的选项
+type SourceOptions struct {
+	// Timeout 是HTTP请求的超时时间，包括读取响应内容，为0时不限制
+	Timeout time.Duration
+	// Header 是HTTP请求附加的请求头，例如Authorization
+	Header http.Header
+	// Client 是发送请求使用的客户端，为nil时使用新建的客户端
+	Client *http.Client
+	// MaxSize 是响应内容解压后允许的最大字节数，为0时使用DefaultMaxSourceSize，小于0时不限制
+	MaxSize int64
+}
+
+// sourceTimeout、sourceHeaders 和 sourceMaxSize 是 -timeout、-H 和 -max-size 参数的值
+var (
+	sourceTimeout time.Duration
+	sourceHeaders headerList
+	sourceMaxSize int64
+)
+
+// headerList 收集重复指定的 -H 请求头
+type headerList []string
+
+func (h *headerList) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerList) Set(value string) error {
+	if _, _, err := ParseHeader(value); err != nil {
+		return err
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// RegisterSourceFlags 定义读取HTTP(S)输入的 -timeout、-H 和 -max-size 参数
+func RegisterSourceFlags() {
+	flag.DurationVar(&sourceTimeout, "timeout", 30*time.Second, "读取HTTP(S)输入的超时时间，0表示不限制")
+	flag.Var(&sourceHeaders, "H", "读取HTTP(S)输入时附加的请求头，格式为 \"名称: 值\"，可重复指定")
+	flag.Int64Var(&sourceMaxSize, "max-size", DefaultMaxSourceSize, "HTTP(S)输入解压后允许的最大字节数，负数表示不限制")
+}
+
+// SourceFlags 根据 -timeout、-H 和 -max-size 参数创建读取输入源的选项
+func SourceFlags() *SourceOptions {
+	header := http.Header{}
+	for _, line := range sourceHeaders {
+		name, value, _ := ParseHeader(line)
+		header.Add(name, value)
+	}
+	return &SourceOptions{Timeout: sourceTimeout, Header: header, MaxSize: sourceMaxSize}
+}
+
+// IsURL 检查输入源是否为HTTP或HTTPS地址
+func IsURL(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// OpenSource 打开输入源并根据内容自动解压。
+// source为HTTP或HTTPS地址时以流的方式读取响应内容，为空或"-"时读取标准输入，否则打开本地文件。
+// 响应状态码不是2xx时返回错误，响应内容解压后超过MaxSize时读取返回错误
+func OpenSource(source string, opts *SourceOptions) (io.ReadCloser, error) {
+	if source == "-" {
+		source = ""
+	}
+	if !IsURL(source) {
+		return parser.OpenFile(source)
+	}
+	if opts == nil {
+		opts = &SourceOptions{}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "无效的URL").WithPath(source).WithCause(err)
+	}
+	for key, values := range opts.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	if opts.Timeout > 0 {
+		copied := *client
+		copied.Timeout = opts.Timeout
+		client = &copied
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "请求失败").WithPath(source).WithCause(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("请求失败: %s", resp.Status)).WithPath(source)
+	}
+
+	reader, err := parser.NewDecompressReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	maxSize := opts.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxSourceSize
+	}
+	return &bodyReader{ReadCloser: reader, body: resp.Body, source: source, remaining: maxSize}, nil
+}
+
+// ReadSource 读取输入源的全部内容，source的含义与OpenSource相同
+func ReadSource(source string, opts *SourceOptions) ([]byte, error) {
+	reader, err := OpenSource(source, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		if _, ok := err.(*jsonerrors.JSONError); ok {
+			return nil, err
+		}
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取输入失败").WithPath(source).WithCause(err)
+	}
+	return data, nil
+}
+
+// ParseHeader 解析"Name: value"形式的请求头
+func ParseHeader(line string) (string, string, error) {
+	name, value, ok := strings.Cut(line, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "无效的请求头: "+line+"，格式为 名称: 值")
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// bodyReader 限制读取的字节数，关闭解压Reader的同时关闭响应内容
+type bodyReader struct {
+	io.ReadCloser
+	body      io.ReadCloser
+	source    string
+	remaining int64 // 还可以读取的字节数，小于0时不限制
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return b.ReadCloser.Read(p)
+	}
+	if b.remaining == 0 {
+		// 多读一个字节，区分内容恰好等于上限和超过上限
+		var one [1]byte
+		if n, err := b.ReadCloser.Read(one[:]); n == 0 {
+			return 0, err
+		}
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrBudgetExceeded, "响应内容超过允许的最大字节数，可以用 -max-size 调整").WithPath(b.source)
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *bodyReader) Close() error {
+	err := b.ReadCloser.Close()
+	if closeErr := b.body.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
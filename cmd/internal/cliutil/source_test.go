@@ -0,0 +1,126 @@
+package cliutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestSourceFlags(t *testing.T) {
+	fs := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+	defer func() { flag.CommandLine = fs }()
+
+	RegisterSourceFlags()
+	if err := flag.CommandLine.Parse([]string{"-H", "X-Token: a", "-H", "x-token:b ", "-timeout", "5s", "-max-size", "100"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	opts := SourceFlags()
+	if opts.Timeout != 5*time.Second || opts.MaxSize != 100 {
+		t.Errorf("Timeout = %v, MaxSize = %d", opts.Timeout, opts.MaxSize)
+	}
+	if got := opts.Header.Values("X-Token"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Header = %v", opts.Header)
+	}
+
+	var h headerList
+	if err := h.Set("no colon"); err == nil || len(h) != 0 {
+		t.Errorf("Set(no colon) 应该返回错误")
+	}
+}
+
+func TestOpenSourceURL(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(`{"compressed":true}`))
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"ok":true}`))
+		case "/data.json.gz":
+			w.Write(compressed.Bytes())
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	data, err := ReadSource(server.URL+"/data.json", &SourceOptions{Header: header})
+	if err != nil {
+		t.Fatalf("ReadSource() error = %v", err)
+	}
+	if string(data) != `{"ok":true}` {
+		t.Errorf("ReadSource() = %s", data)
+	}
+
+	data, err = ReadSource(server.URL+"/data.json.gz", nil)
+	if err != nil || string(data) != `{"compressed":true}` {
+		t.Errorf("ReadSource(gzip) = %s, %v", data, err)
+	}
+
+	if _, err := ReadSource(server.URL+"/data.json", nil); err == nil {
+		t.Error("401响应应该返回错误")
+	}
+	if _, err := ReadSource(server.URL+"/missing", nil); err == nil {
+		t.Error("404响应应该返回错误")
+	}
+	if _, err := ReadSource(server.URL+"/slow", &SourceOptions{Timeout: 50 * time.Millisecond}); err == nil {
+		t.Error("超时应该返回错误")
+	}
+}
+
+func TestOpenSourceMaxSize(t *testing.T) {
+	body := `{"items":[` + strings.Repeat(`1,`, 100) + `1]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	// 上限按解压后的内容计算，恰好等于上限时可以读取
+	data, err := ReadSource(server.URL, &SourceOptions{MaxSize: int64(len(body))})
+	if err != nil || string(data) != body {
+		t.Errorf("ReadSource(MaxSize = len) = %d bytes, %v", len(data), err)
+	}
+	if _, err := ReadSource(server.URL, &SourceOptions{MaxSize: -1}); err != nil {
+		t.Errorf("ReadSource(MaxSize = -1) error = %v", err)
+	}
+
+	_, err = ReadSource(server.URL, &SourceOptions{MaxSize: 16})
+	jsonErr, ok := err.(*jsonerrors.JSONError)
+	if !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded || jsonErr.Path != server.URL {
+		t.Errorf("ReadSource(MaxSize = 16) error = %v, 期望ErrBudgetExceeded", err)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("Authorization: Bearer a:b")
+	if err != nil || name != "Authorization" || value != "Bearer a:b" {
+		t.Errorf("ParseHeader() = %q, %q, %v", name, value, err)
+	}
+	for _, line := range []string{"Authorization", ": x"} {
+		if _, _, err := ParseHeader(line); err == nil {
+			t.Errorf("ParseHeader(%q) 应该返回错误", line)
+		}
+	}
+	if !IsURL("HTTPS://example.com") || IsURL("data.json") || IsURL("") {
+		t.Error("IsURL() 结果错误")
+	}
+}
@@ -0,0 +1,122 @@
+// jsonagg 是一个JSON聚合工具，按键对数组元素分组并计算统计值
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/agg"
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+var (
+	inputFile  string
+	outputFile string
+	filter     string
+	by         string
+	spec       string
+	gzipOutput bool
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.StringVar(&filter, "f", "$[*]", "JSON Path过滤器，用于选择要聚合的元素")
+	flag.StringVar(&by, "by", "", "分组键的JSON Path，以元素为根，例如 $.region；为空时所有元素属于同一个分组")
+	flag.StringVar(&spec, "agg", "", "逗号分隔的聚合规格，格式为 名称=函数(路径)")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonagg - JSON聚合工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonagg -agg <规格> [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n聚合函数:\n")
+	fmt.Fprintf(os.Stderr, "  count(), distinct(路径), sum(路径), avg(路径), min(路径), max(路径),\n")
+	fmt.Fprintf(os.Stderr, "  first(路径), last(路径), collect(路径)\n")
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonagg -i orders.json -by \"$.region\" -agg \"total=sum($.amount),n=count()\"\n")
+	fmt.Fprintf(os.Stderr, "  jsonagg -i export.json -f \"$.events[*]\" -agg \"users=distinct($.userId)\"\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonagg", "JSON聚合工具")
+	flag.Parse()
+
+	// 检查参数
+	if spec == "" {
+		cliutil.UsageError("必须使用 -agg 指定聚合规格")
+		os.Exit(2)
+	}
+	fns, err := agg.ParseSpec(spec)
+	if err != nil {
+		cliutil.Error("", err)
+		os.Exit(2)
+	}
+	aggregator, err := agg.NewAggregator(by, fns)
+	if err != nil {
+		cliutil.Error("", err)
+		os.Exit(2)
+	}
+
+	// 打开输入
+	input, err := parser.OpenFile(inputFile)
+	if err != nil {
+		cliutil.Error("打开输入文件失败", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	// 逐个读取元素并聚合
+	reader, err := stream.NewElementReader(input, filter)
+	if err != nil {
+		cliutil.Error("", err)
+		os.Exit(2)
+	}
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cliutil.Error("读取输入失败", err)
+			os.Exit(1)
+		}
+		aggregator.Add(value)
+	}
+
+	// 打开输出
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
+	}
+
+	// bufio.Writer保留第一个写入错误，由Flush返回
+	writer := bufio.NewWriter(output)
+	writer.WriteString("[")
+	for i, record := range aggregator.Result().Values() {
+		if i > 0 {
+			writer.WriteString(",")
+		}
+		writer.WriteString("\n" + record.String())
+	}
+	writer.WriteString("\n]\n")
+	err = writer.Flush()
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("写入输出失败", err)
+		os.Exit(1)
+	}
+}
@@ -2,15 +2,17 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"sort"
 	"strings"
 
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
 	"github.com/UserLeeZJ/gojson/jsonpath"
 	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
 	"github.com/UserLeeZJ/gojson/utils"
 )
 
@@ -19,13 +21,19 @@ var (
 	outputFile string
 	path       string
 	showPaths  bool
+	streaming  bool
+	stateFile  string
 )
 
 func init() {
-	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&inputFile, "i", "", "输入文件路径或HTTP(S)地址，如果为空则从标准输入读取")
+	cliutil.RegisterSourceFlags()
 	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
 	flag.StringVar(&path, "p", "$", "JSON Path表达式，用于分析特定路径的结构")
 	flag.BoolVar(&showPaths, "paths", false, "显示所有可能的JSON Path")
+	flag.BoolVar(&streaming, "stream", false, "流式统计每个路径的值，适用于大型文件和NDJSON")
+	flag.StringVar(&stateFile, "state", "", "流式统计的检查点文件，存在时先加载，处理完成后保存")
+	cliutil.RegisterLogFlag()
 	flag.Usage = usage
 }
 
@@ -40,28 +48,39 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "  cat input.json | jsonanalyze\n")
 	fmt.Fprintf(os.Stderr, "  jsonanalyze -i input.json -paths\n")
 	fmt.Fprintf(os.Stderr, "  jsonanalyze -i input.json -p \"$.store.book\"\n")
+	fmt.Fprintf(os.Stderr, "  jsonanalyze -i large.json -stream\n")
+	fmt.Fprintf(os.Stderr, "  jsonanalyze -i https://api.example.com/export.ndjson -stream\n")
+	fmt.Fprintf(os.Stderr, "  jsonanalyze -i part-001.json -stream -state stats.json\n")
 }
 
 func main() {
+	cliutil.HandleHelpJSON("jsonanalyze", "JSON结构分析工具")
 	flag.Parse()
 
-	// 读取输入
-	var input []byte
-	var err error
-	if inputFile == "" {
-		input, err = io.ReadAll(os.Stdin)
-	} else {
-		input, err = os.ReadFile(inputFile)
+	if streaming {
+		if path != "$" || showPaths {
+			cliutil.UsageError("流式统计不支持 -p 和 -paths")
+			os.Exit(1)
+		}
+		analyzeStream()
+		return
+	}
+	if stateFile != "" {
+		cliutil.UsageError("-state 只能与 -stream 一起使用")
+		os.Exit(1)
 	}
+
+	// 读取输入
+	input, err := cliutil.ReadSource(inputFile, cliutil.SourceFlags())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "读取输入失败: %v\n", err)
+		cliutil.Error("读取输入失败", err)
 		os.Exit(1)
 	}
 
 	// 解析JSON
 	jsonValue, err := parser.ParseToValue(string(input))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "解析JSON失败: %v\n", err)
+		cliutil.ErrorInput("解析JSON失败", err, input)
 		os.Exit(1)
 	}
 
@@ -69,11 +88,11 @@ func main() {
 	if path != "$" {
 		results, err := jsonpath.QueryJSONPath(jsonValue, path)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "查询失败: %v\n", err)
+			cliutil.Error("查询失败", err)
 			os.Exit(1)
 		}
 		if len(results) == 0 {
-			fmt.Fprintf(os.Stderr, "路径 %s 没有匹配的结果\n", path)
+			cliutil.Errorf("路径 %s 没有匹配的结果", path)
 			os.Exit(1)
 		}
 		// 使用第一个结果
@@ -110,8 +129,80 @@ func main() {
 	} else {
 		err = os.WriteFile(outputFile, []byte(output.String()), 0644)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "写入输出失败: %v\n", err)
+			cliutil.Error("写入输出失败", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// analyzeStream 流式统计输入中每个路径的值
+func analyzeStream() {
+	aggregator := stream.NewAggregator()
+
+	// 加载检查点
+	if stateFile != "" {
+		data, err := os.ReadFile(stateFile)
+		if err == nil {
+			err = json.Unmarshal(data, aggregator)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			cliutil.Error("加载检查点失败", err)
 			os.Exit(1)
 		}
 	}
+
+	// 打开输入
+	input, err := cliutil.OpenSource(inputFile, cliutil.SourceFlags())
+	if err != nil {
+		cliutil.Error("读取输入失败", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+	if err := aggregator.ConsumeReader(input); err != nil {
+		cliutil.Error("解析JSON失败", err)
+		os.Exit(1)
+	}
+
+	// 保存检查点
+	if stateFile != "" {
+		data, err := json.Marshal(aggregator)
+		if err == nil {
+			err = os.WriteFile(stateFile, data, 0644)
+		}
+		if err != nil {
+			cliutil.Error("保存检查点失败", err)
+			os.Exit(1)
+		}
+	}
+
+	// 准备输出
+	var output strings.Builder
+	output.WriteString("JSON流式统计结果\n")
+	output.WriteString("====================\n\n")
+	output.WriteString(fmt.Sprintf("文档数量: %d\n\n", aggregator.Documents()))
+
+	for _, stats := range aggregator.Stats() {
+		types := make([]string, 0, len(stats.Types))
+		for t, n := range stats.Types {
+			types = append(types, fmt.Sprintf("%s=%d", t, n))
+		}
+		sort.Strings(types)
+
+		output.WriteString(fmt.Sprintf("%s\n", stats.Path))
+		output.WriteString(fmt.Sprintf("  出现次数: %d (%s)\n", stats.Count, strings.Join(types, ", ")))
+		if stats.NumberCount > 0 {
+			output.WriteString(fmt.Sprintf("  数值: 最小 %g, 最大 %g, 平均 %g\n", stats.Min, stats.Max, stats.Mean()))
+		}
+		if stats.Strings != nil {
+			output.WriteString(fmt.Sprintf("  不同字符串: 约 %d\n", stats.DistinctStrings()))
+		}
+	}
+
+	// 写入输出
+	if outputFile == "" {
+		fmt.Print(output.String())
+	} else if err := os.WriteFile(outputFile, []byte(output.String()), 0644); err != nil {
+		cliutil.Error("写入输出失败", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,206 @@
+// jsondedup 是一个JSON去重工具，按元素内容或指定的键去除数组中的重复元素
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	inputFile  string
+	outputFile string
+	filter     string
+	by         string
+	keep       string
+	gzipOutput bool
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.StringVar(&filter, "f", "$[*]", "JSON Path过滤器，用于选择要去重的元素")
+	flag.StringVar(&by, "by", "", "去重键的JSON Path，以元素为根，例如 $.id；为空时比较整个元素")
+	flag.StringVar(&keep, "keep", "first", "保留重复元素中的哪一个 (first, last)")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsondedup - JSON去重工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsondedup [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "元素按内容哈希比较，对象键的顺序不影响比较结果；缺少去重键的元素总是保留。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsondedup -i events.json\n")
+	fmt.Fprintf(os.Stderr, "  jsondedup -i export.json -f \"$.events[*]\" -by \"$.id\" -keep last\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsondedup", "JSON去重工具")
+	flag.Parse()
+
+	// 检查参数
+	if keep != "first" && keep != "last" {
+		cliutil.UsageError("-keep 只能是 first 或 last")
+		os.Exit(2)
+	}
+	if _, err := utils.NewDeduper(by); err != nil {
+		cliutil.Error("无效的去重键", err)
+		os.Exit(2)
+	}
+
+	// 打开输出
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
+	}
+	writer := bufio.NewWriter(output)
+	if keep == "first" {
+		err = dedupFirst(inputFile, writer)
+	} else {
+		err = dedupLast(inputFile, writer)
+	}
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("去重失败", err)
+		os.Exit(1)
+	}
+}
+
+// dedupFirst 在一次读取中输出每个键第一次出现的元素
+func dedupFirst(path string, writer *bufio.Writer) error {
+	deduper, _ := utils.NewDeduper(by)
+	out := &arrayWriter{writer: writer}
+	err := forEachElement(path, func(_ int, value types.JSONValue) {
+		if !deduper.IsDuplicate(value) {
+			out.write(value)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// dedupLast 读取两遍输入：第一遍记录每个键最后出现的位置，第二遍输出这些位置的元素
+// 标准输入无法重新读取，会先复制到临时文件
+func dedupLast(path string, writer *bufio.Writer) error {
+	if path == "" {
+		temp, err := copyStdin()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(temp)
+		path = temp
+	}
+
+	deduper, _ := utils.NewDeduper(by)
+	last := make(map[[16]byte]int)
+	err := forEachElement(path, func(i int, value types.JSONValue) {
+		if key, ok := deduper.Key(value); ok {
+			last[key] = i
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	out := &arrayWriter{writer: writer}
+	err = forEachElement(path, func(i int, value types.JSONValue) {
+		if key, ok := deduper.Key(value); !ok || last[key] == i {
+			out.write(value)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// copyStdin 把解压后的标准输入复制到临时文件，返回临时文件的路径
+func copyStdin() (string, error) {
+	input, err := parser.OpenFile("")
+	if err != nil {
+		return "", err
+	}
+	defer input.Close()
+
+	temp, err := os.CreateTemp("", "jsondedup-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer temp.Close()
+	if _, err := io.Copy(temp, input); err != nil {
+		os.Remove(temp.Name())
+		return "", err
+	}
+	return temp.Name(), nil
+}
+
+// forEachElement 对输入文件中每个匹配的元素调用fn，path为空时读取标准输入
+func forEachElement(path string, fn func(index int, value types.JSONValue)) error {
+	input, err := parser.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	reader, err := stream.NewElementReader(input, filter)
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		value, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(i, value)
+	}
+}
+
+// arrayWriter 把元素写为JSON数组
+type arrayWriter struct {
+	writer *bufio.Writer
+	count  int
+}
+
+// write 写入一个元素
+func (a *arrayWriter) write(value types.JSONValue) {
+	if a.count == 0 {
+		a.writer.WriteString("[\n")
+	} else {
+		a.writer.WriteString(",\n")
+	}
+	a.writer.WriteString(value.String())
+	a.count++
+}
+
+// close 结束数组
+func (a *arrayWriter) close() error {
+	if a.count == 0 {
+		a.writer.WriteString("[")
+	}
+	_, err := a.writer.WriteString("\n]\n")
+	return err
+}
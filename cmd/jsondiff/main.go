@@ -0,0 +1,154 @@
+// jsondiff 是一个JSON比较工具，用于输出两个JSON文档之间的差异
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/diff"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	ignoreCase       bool
+	ignoreWhitespace bool
+	ignoreOrder      bool
+	patch            bool
+	quiet            bool
+	outputFile       string
+	textDiff         string
+)
+
+func init() {
+	flag.BoolVar(&ignoreCase, "ignore-case", false, "忽略字符串大小写")
+	flag.BoolVar(&ignoreWhitespace, "ignore-whitespace", false, "忽略字符串中的空白字符")
+	flag.BoolVar(&ignoreOrder, "ignore-order", false, "忽略数组元素的顺序")
+	flag.BoolVar(&patch, "patch", false, "以JSON Patch格式输出差异")
+	flag.StringVar(&textDiff, "text", "none", "为修改了的长字符串显示字符串内部的差异 (none, line, word)")
+	flag.BoolVar(&quiet, "q", false, "不输出任何内容，只通过退出码报告结果")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsondiff - JSON比较工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsondiff [选项] <旧文件> <新文件>\n\n")
+	fmt.Fprintf(os.Stderr, "其中一个文件可以是 -，表示从标准输入读取。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n退出码:\n")
+	fmt.Fprintf(os.Stderr, "  0  两个文档相同\n")
+	fmt.Fprintf(os.Stderr, "  1  两个文档存在差异\n")
+	fmt.Fprintf(os.Stderr, "  2  参数错误、读取或解析输入失败\n")
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsondiff old.json new.json\n")
+	fmt.Fprintf(os.Stderr, "  jsondiff -patch -o changes.json old.json new.json\n")
+	fmt.Fprintf(os.Stderr, "  jsondiff -text line old-config.json new-config.json\n")
+	fmt.Fprintf(os.Stderr, "  jsondiff -q expected.json actual.json || echo changed\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsondiff", "JSON比较工具")
+	flag.Parse()
+
+	// 检查参数
+	if flag.NArg() != 2 {
+		usage()
+		os.Exit(2)
+	}
+	if flag.Arg(0) == "-" && flag.Arg(1) == "-" {
+		cliutil.UsageError("只能有一个输入来自标准输入")
+		os.Exit(2)
+	}
+	textMode, err := diff.ParseTextDiffMode(textDiff)
+	if err != nil {
+		cliutil.UsageError(err.Error())
+		os.Exit(2)
+	}
+
+	// 读取输入
+	oldValue, err := readValue(flag.Arg(0))
+	if err != nil {
+		cliutil.Error("读取旧文件失败", err)
+		os.Exit(2)
+	}
+	newValue, err := readValue(flag.Arg(1))
+	if err != nil {
+		cliutil.Error("读取新文件失败", err)
+		os.Exit(2)
+	}
+
+	// 比较
+	diffs, err := diff.DiffJSON(oldValue, newValue, &diff.DiffOptions{
+		IgnoreCase:       ignoreCase,
+		IgnoreWhitespace: ignoreWhitespace,
+		IgnoreOrder:      ignoreOrder,
+		TextDiff:         textMode,
+	})
+	if err != nil {
+		cliutil.Error("比较失败", err)
+		os.Exit(2)
+	}
+	report(diffs)
+}
+
+// readValue 读取并解析输入，- 表示标准输入
+func readValue(arg string) (types.JSONValue, error) {
+	if arg == "-" {
+		arg = ""
+	}
+	input, err := parser.ReadFile(arg)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseBytesToValue(input)
+}
+
+// report 输出差异并以相应的退出码结束
+func report(diffs []*diff.Diff) {
+	if !quiet {
+		out, err := parser.CreateFile(outputFile, false)
+		if err == nil {
+			err = writeDiffs(out, diffs)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			cliutil.Error("写入输出失败", err)
+			os.Exit(2)
+		}
+	}
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeDiffs 按选择的格式写入差异
+func writeDiffs(w io.Writer, diffs []*diff.Diff) error {
+	if patch {
+		ops, err := diff.GeneratePatchChecked(diffs)
+		if err != nil {
+			return err
+		}
+		output, err := utils.PrettyPrint(ops, utils.DefaultPrettyOptions())
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, output)
+		return err
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintln(w, d.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
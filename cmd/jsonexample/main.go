@@ -0,0 +1,98 @@
+// jsonexample 是一个示例生成工具，根据JSON Schema生成示例JSON
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/schema"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	schemaFile string
+	outputFile string
+	compress   bool
+)
+
+func init() {
+	flag.StringVar(&schemaFile, "schema", "", "Schema文件路径，可以用#后接JSON Pointer指定文档中的Schema")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&compress, "c", false, "输出紧凑格式")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonexample - 根据JSON Schema生成示例JSON\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonexample -schema <文件>[#<JSON Pointer>] [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonexample -schema user.schema.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonexample -schema api.json#/components/schemas/User\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonexample", "根据JSON Schema生成示例JSON")
+	flag.Parse()
+
+	if schemaFile == "" {
+		usage()
+		os.Exit(1)
+	}
+
+	// 读取Schema文档
+	file, pointer, _ := strings.Cut(schemaFile, "#")
+	data, err := parser.ReadFile(file)
+	if err != nil {
+		cliutil.Error("读取Schema失败", err)
+		os.Exit(1)
+	}
+
+	s, err := schema.ParseAt(data, pointer)
+	if err != nil {
+		cliutil.Error("解析Schema失败", err)
+		os.Exit(1)
+	}
+	resolver, err := schema.DocumentResolver(data)
+	if err != nil {
+		cliutil.Error("解析Schema失败", err)
+		os.Exit(1)
+	}
+
+	// 生成示例
+	example, err := schema.GenerateExampleWithOptions(s, &schema.ExampleOptions{Resolver: resolver})
+	if err != nil {
+		cliutil.Error("生成示例失败", err)
+		os.Exit(1)
+	}
+
+	var output string
+	if compress {
+		output, err = utils.CompressJSON(example)
+	} else {
+		output, err = utils.PrettyPrint(example, utils.DefaultPrettyOptions())
+	}
+	if err != nil {
+		cliutil.Error("格式化输出失败", err)
+		os.Exit(1)
+	}
+	output = strings.TrimRight(output, "\n") + "\n"
+
+	// 写入输出
+	if outputFile == "" {
+		fmt.Print(output)
+	} else {
+		err = os.WriteFile(outputFile, []byte(output), 0644)
+		if err != nil {
+			cliutil.Error("写入输出失败", err)
+			os.Exit(1)
+		}
+	}
+}
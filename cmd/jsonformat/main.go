@@ -2,12 +2,15 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
 	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
 	"github.com/UserLeeZJ/gojson/utils"
 )
 
@@ -16,34 +19,63 @@ var (
 	outputFile string
 	pretty     bool
 	compress   bool
+	reindent   bool
 	sortKeys   bool
 	indent     string
 	escapeHTML bool
+	gzipOutput bool
+	write      bool
+	list       bool
+	jobs       int
 )
 
 func init() {
-	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&inputFile, "i", "", "输入文件路径或HTTP(S)地址，如果为空则从标准输入读取")
+	cliutil.RegisterSourceFlags()
 	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
 	flag.BoolVar(&pretty, "p", false, "美化JSON")
 	flag.BoolVar(&compress, "c", false, "压缩JSON")
+	flag.BoolVar(&reindent, "r", false, "只调整缩进，保留键的顺序、字面量的写法和原有的换行，忽略 -s")
 	flag.BoolVar(&sortKeys, "s", false, "排序键")
 	flag.StringVar(&indent, "indent", "  ", "缩进字符串")
 	flag.BoolVar(&escapeHTML, "escape-html", false, "转义HTML字符")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.BoolVar(&write, "w", false, "把格式化的结果写回文件参数指定的文件")
+	flag.BoolVar(&list, "l", false, "只列出格式与结果不一致的文件")
+	flag.IntVar(&jobs, "j", 1, "同时处理的文件数量，0表示使用所有CPU")
+	cliutil.RegisterLogFlag()
 	flag.Usage = usage
 }
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "jsonformat - JSON格式化工具\n\n")
 	fmt.Fprintf(os.Stderr, "用法:\n")
-	fmt.Fprintf(os.Stderr, "  jsonformat [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat [选项]\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat [选项] <文件>...\n\n")
+	fmt.Fprintf(os.Stderr, "指定文件参数时依次输出每个文件格式化的结果；-w 写回原文件，-l 只列出需要格式化的文件。\n")
+	fmt.Fprintf(os.Stderr, "处理多个文件时，任一文件失败退出码为2；使用 -l 且存在需要格式化的文件时退出码为1。\n\n")
 	fmt.Fprintf(os.Stderr, "选项:\n")
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\n示例:\n")
 	fmt.Fprintf(os.Stderr, "  jsonformat -i input.json -o output.json -p\n")
 	fmt.Fprintf(os.Stderr, "  cat input.json | jsonformat -p > output.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat -i input.json.gz -c -z -o output.json.gz\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat -i https://api.example.com/data.json -H \"Authorization: Bearer <token>\"\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat -l -j 8 $(git ls-files '*.json')\n")
+	fmt.Fprintf(os.Stderr, "  jsonformat -r -w config.json\n")
 }
 
 func main() {
+	cliutil.HandleHelpJSON("jsonformat", "JSON格式化工具")
+
+	// 配置文件提供参数的默认值
+	config, err := cliutil.LoadConfig()
+	if err != nil {
+		cliutil.Error("加载配置失败", err)
+		os.Exit(2)
+	}
+	indent = config.Indent
+	sortKeys = config.SortKeys
 	flag.Parse()
 
 	// 检查参数
@@ -51,55 +83,143 @@ func main() {
 		pretty = true // 默认美化
 	}
 	if pretty && compress {
-		fmt.Fprintf(os.Stderr, "错误: 不能同时指定美化和压缩\n")
+		cliutil.UsageError("不能同时指定美化和压缩")
+		os.Exit(1)
+	}
+	if reindent && compress {
+		cliutil.UsageError("-r 不能与 -c 同时使用")
 		os.Exit(1)
 	}
 
+	// 处理多个文件
+	if flag.NArg() > 0 {
+		if inputFile != "" || outputFile != "" || gzipOutput {
+			cliutil.UsageError("指定文件参数时不能使用 -i、-o 和 -z")
+			os.Exit(2)
+		}
+		if write && list {
+			cliutil.UsageError("不能同时指定 -w 和 -l")
+			os.Exit(2)
+		}
+		os.Exit(formatFiles(flag.Args()))
+	}
+	if write || list {
+		cliutil.UsageError("-w 和 -l 需要指定文件参数")
+		os.Exit(2)
+	}
+
 	// 读取输入
-	var input []byte
-	var err error
-	if inputFile == "" {
-		input, err = io.ReadAll(os.Stdin)
-	} else {
-		input, err = os.ReadFile(inputFile)
+	input, err := cliutil.ReadSource(inputFile, cliutil.SourceFlags())
+	if err != nil {
+		cliutil.Error("读取输入失败", err)
+		os.Exit(1)
 	}
+
+	// 格式化JSON
+	output, err := formatInput(input)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "读取输入失败: %v\n", err)
+		cliutil.ErrorInput("格式化JSON失败", err, input)
 		os.Exit(1)
 	}
+	if outputFile == "" && !gzipOutput && config.UseColor(os.Stdout) {
+		output = cliutil.Colorize(output)
+	}
 
-	// 解析JSON
-	jsonValue, err := parser.ParseToValue(string(input))
+	// 写入输出
+	out, err := parser.CreateFile(outputFile, gzipOutput)
+	if err == nil {
+		_, err = io.WriteString(out, output)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "解析JSON失败: %v\n", err)
+		cliutil.Error("写入输出失败", err)
 		os.Exit(1)
 	}
+}
 
-	// 格式化JSON
-	var output string
+// formatInput 按参数格式化输入，-r 时只调整缩进，否则解析后美化或压缩
+func formatInput(input []byte) (string, error) {
+	if reindent {
+		return utils.ReindentJSON(string(input), indent)
+	}
+	value, err := parser.ParseBytesToValue(input)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(value)
+}
+
+// formatValue 按参数美化或压缩JSON值
+func formatValue(value types.JSONValue) (string, error) {
 	if pretty {
 		options := utils.PrettyOptions{
 			Indent:     indent,
 			SortKeys:   sortKeys,
 			EscapeHTML: escapeHTML,
 		}
-		output, err = utils.PrettyPrint(jsonValue, options)
-	} else {
-		output, err = utils.CompressJSON(jsonValue)
+		return utils.PrettyPrint(value, options)
 	}
+	return utils.CompressJSON(value)
+}
+
+// fileResult 是格式化一个文件的结果
+type fileResult struct {
+	output  string // 格式化的结果
+	changed bool   // 结果与文件原有内容是否不同
+	err     error
+}
+
+// formatFiles 并发格式化多个文件，按参数顺序输出结果，返回退出码
+func formatFiles(paths []string) int {
+	exitCode := 0
+	cliutil.Parallel(cliutil.Jobs(jobs), len(paths), func(i int) fileResult {
+		return formatFile(paths[i])
+	}, func(i int, r fileResult) {
+		switch {
+		case r.err != nil:
+			cliutil.Error(paths[i], r.err)
+			exitCode = 2
+		case list:
+			if r.changed {
+				fmt.Println(paths[i])
+				if exitCode == 0 {
+					exitCode = 1
+				}
+			}
+		case !write:
+			fmt.Println(r.output)
+		}
+	})
+	return exitCode
+}
+
+// formatFile 格式化一个文件，指定 -w 时写回格式发生变化的文件
+// 写回的文件以换行符结尾，gzip压缩的文件写回时保持压缩。
+// 结果先写入临时文件再替换原文件，写入失败时原文件不变
+func formatFile(path string) fileResult {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "格式化JSON失败: %v\n", err)
-		os.Exit(1)
+		return fileResult{err: err}
+	}
+	reader, err := parser.NewDecompressReader(bytes.NewReader(raw))
+	if err != nil {
+		return fileResult{err: err}
+	}
+	input, err := io.ReadAll(reader)
+	if err != nil {
+		return fileResult{err: err}
 	}
 
-	// 写入输出
-	if outputFile == "" {
-		fmt.Print(output)
-	} else {
-		err = os.WriteFile(outputFile, []byte(output), 0644)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "写入输出失败: %v\n", err)
-			os.Exit(1)
-		}
+	output, err := formatInput(input)
+	if err != nil {
+		return fileResult{err: err}
+	}
+
+	result := fileResult{output: output, changed: string(input) != output+"\n"}
+	if write && result.changed {
+		result.err = parser.ReplaceFile(path, []byte(output+"\n"), parser.DetectCompression(raw) == parser.CompressionGzip)
 	}
+	return result
 }
@@ -0,0 +1,154 @@
+// jsongen 是一个代码生成工具，根据JSON样本生成Go结构体定义
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/schema"
+)
+
+var (
+	inputFile   string
+	outputFile  string
+	packageName string
+	rootName    string
+	printSchema bool
+	typeName    string
+)
+
+// typeSchemaProgram 是输出Go类型Schema的临时程序模板
+const typeSchemaProgram = `package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/UserLeeZJ/gojson/schema"
+
+	target %q
+)
+
+func main() {
+	s := schema.FromType(reflect.TypeOf((*target.%s)(nil)).Elem())
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = %q
+	fmt.Println(s.String())
+}
+`
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.StringVar(&packageName, "pkg", "main", "生成代码的包名")
+	flag.StringVar(&rootName, "name", "Root", "根类型的名称")
+	flag.BoolVar(&printSchema, "schema", false, "输出推断的JSON Schema而不是Go代码")
+	flag.StringVar(&typeName, "type", "", "输出Go类型的JSON Schema，格式为 导入路径.类型名")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsongen - 根据JSON样本生成Go结构体\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsongen [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsongen -i sample.json -pkg models -o models/sample.go\n")
+	fmt.Fprintf(os.Stderr, "  cat sample.json | jsongen -name User\n")
+	fmt.Fprintf(os.Stderr, "  jsongen -i sample.json -schema\n")
+	fmt.Fprintf(os.Stderr, "  jsongen -type github.com/example/app/models.User\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsongen", "根据JSON样本生成Go结构体")
+	flag.Parse()
+
+	if typeName != "" {
+		if err := generateTypeSchema(typeName); err != nil {
+			cliutil.Error("生成Schema失败", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 读取输入
+	input, err := parser.ReadFile(inputFile)
+	if err != nil {
+		cliutil.Error("读取输入失败", err)
+		os.Exit(1)
+	}
+
+	// 解析JSON
+	jsonValue, err := parser.ParseBytesToValue(input)
+	if err != nil {
+		cliutil.ErrorInput("解析JSON失败", err, input)
+		os.Exit(1)
+	}
+
+	// 推断Schema并生成输出
+	inferred := schema.Infer(jsonValue)
+	var output []byte
+	if printSchema {
+		output = []byte(inferred.String() + "\n")
+	} else {
+		output, err = schema.GenerateGo(inferred, schema.GoOptions{Package: packageName, RootName: rootName})
+		if err != nil {
+			cliutil.Error("生成代码失败", err)
+			os.Exit(1)
+		}
+	}
+
+	// 写入输出
+	if outputFile == "" {
+		os.Stdout.Write(output)
+	} else {
+		err = os.WriteFile(outputFile, output, 0644)
+		if err != nil {
+			cliutil.Error("写入输出失败", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// generateTypeSchema 在当前模块中编译并运行临时程序，通过反射输出Go类型的Schema
+func generateTypeSchema(name string) error {
+	dot := strings.LastIndex(name, ".")
+	if dot <= 0 || dot == len(name)-1 {
+		return fmt.Errorf("无效的类型名称 %q，格式应为 导入路径.类型名", name)
+	}
+	importPath, typ := name[:dot], name[dot+1:]
+
+	// 临时目录必须位于当前模块中，才能导入模块内的包
+	dir, err := os.MkdirTemp(".", ".jsongen-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	source := fmt.Sprintf(typeSchemaProgram, importPath, typ, typ)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644); err != nil {
+		return err
+	}
+
+	var output strings.Builder
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		fmt.Print(output.String())
+		return nil
+	}
+	return os.WriteFile(outputFile, []byte(output.String()), 0644)
+}
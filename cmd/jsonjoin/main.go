@@ -0,0 +1,122 @@
+// jsonjoin 是一个JSON合并工具，用于把多个JSON文件中的元素合并为一个数组
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+var (
+	outputFile string
+	filter     string
+	key        string
+	gzipOutput bool
+)
+
+func init() {
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.StringVar(&filter, "f", "$[*]", "JSON Path过滤器，用于选择每个输入文件中要合并的元素")
+	flag.StringVar(&key, "key", "", "把合并后的数组包装为对象中的该属性，为空时直接输出数组")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonjoin - JSON合并工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonjoin [选项] <文件>...\n\n")
+	fmt.Fprintf(os.Stderr, "按参数顺序读取文件，把匹配的元素依次写入同一个JSON数组。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonjoin -o merged.json out-*.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonjoin -key items out-001.json out-002.json > big.json\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonjoin", "JSON合并工具")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	// 打开输出
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
+	}
+	writer := bufio.NewWriter(output)
+	err = join(flag.Args(), writer)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("合并失败", err)
+		os.Exit(1)
+	}
+}
+
+// join 依次读取每个文件中的元素并写入同一个数组
+func join(paths []string, writer *bufio.Writer) error {
+	if key != "" {
+		writer.WriteString("{" + strconv.Quote(key) + ":")
+	}
+	writer.WriteString("[\n")
+
+	count := 0
+	for _, path := range paths {
+		if err := joinFile(path, writer, &count); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	writer.WriteString("\n]")
+	if key != "" {
+		writer.WriteString("}")
+	}
+	writer.WriteString("\n")
+	return nil
+}
+
+// joinFile 把单个文件中的元素追加到输出中
+func joinFile(path string, writer *bufio.Writer, count *int) error {
+	file, err := parser.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := stream.NewElementReader(file, filter)
+	if err != nil {
+		return err
+	}
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if *count > 0 {
+			writer.WriteString(",\n")
+		}
+		writer.WriteString(value.String())
+		*count++
+	}
+}
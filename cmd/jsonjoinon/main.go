@@ -0,0 +1,178 @@
+// jsonjoinon 是一个JSON连接工具，按键把两个JSON数据集中的记录合并在一起
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	leftFile    string
+	rightFile   string
+	outputFile  string
+	leftFilter  string
+	rightFilter string
+	key         string
+	leftKey     string
+	rightKey    string
+	kind        string
+	gzipOutput  bool
+)
+
+func init() {
+	flag.StringVar(&leftFile, "left", "", "左侧数据集文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&rightFile, "right", "", "右侧数据集文件路径，会被完整读入内存")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.StringVar(&leftFilter, "lf", "$[*]", "JSON Path过滤器，用于选择左侧数据集中的记录")
+	flag.StringVar(&rightFilter, "rf", "$[*]", "JSON Path过滤器，用于选择右侧数据集中的记录")
+	flag.StringVar(&key, "key", "", "两侧共用的连接键JSON Path，以记录为根，例如 $.id")
+	flag.StringVar(&leftKey, "left-key", "", "左侧记录的连接键，默认使用 -key")
+	flag.StringVar(&rightKey, "right-key", "", "右侧记录的连接键，默认使用 -key")
+	flag.StringVar(&kind, "kind", "inner", "连接方式 (inner, left)")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonjoinon - JSON连接工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonjoinon -right <文件> -key <路径> [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "左侧记录逐个流式读取，右侧数据集建立索引后保存在内存中。\n")
+	fmt.Fprintf(os.Stderr, "合并时加入右侧记录中左侧没有的字段，同名字段保留左侧的值。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonjoinon -left orders.json -right users.json -left-key \"$.userId\" -right-key \"$.id\"\n")
+	fmt.Fprintf(os.Stderr, "  cat events.json | jsonjoinon -right devices.json -key \"$.deviceId\" -kind left\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonjoinon", "JSON连接工具")
+	flag.Parse()
+
+	// 检查参数
+	if leftKey == "" {
+		leftKey = key
+	}
+	if rightKey == "" {
+		rightKey = key
+	}
+	if rightFile == "" || leftKey == "" || rightKey == "" {
+		cliutil.UsageError("必须指定 -right 和连接键")
+		os.Exit(2)
+	}
+	var joinKind utils.JoinKind
+	switch kind {
+	case "inner":
+		joinKind = utils.InnerJoin
+	case "left":
+		joinKind = utils.LeftJoin
+	default:
+		cliutil.UsageError("-kind 只能是 inner 或 left")
+		os.Exit(2)
+	}
+
+	// 读取右侧数据集并建立索引
+	right, err := readAll(rightFile, rightFilter)
+	if err != nil {
+		cliutil.Error("读取右侧数据集失败", err)
+		os.Exit(1)
+	}
+	joiner, err := utils.NewJoiner(right, leftKey, rightKey, joinKind)
+	if err != nil {
+		cliutil.Error("无效的连接键", err)
+		os.Exit(2)
+	}
+
+	// 打开左侧输入
+	input, err := parser.OpenFile(leftFile)
+	if err != nil {
+		cliutil.Error("打开左侧数据集失败", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	// 打开输出
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
+	}
+	writer := bufio.NewWriter(output)
+	err = join(input, writer, joiner)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("连接失败", err)
+		os.Exit(1)
+	}
+}
+
+// readAll 读取文件中所有匹配的记录
+func readAll(path, filter string) (*types.JSONArray, error) {
+	file, err := parser.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := stream.NewElementReader(file, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := types.NewJSONArray()
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Add(value)
+	}
+}
+
+// join 逐个读取左侧记录并输出连接结果
+func join(input io.Reader, writer *bufio.Writer, joiner *utils.Joiner) error {
+	reader, err := stream.NewElementReader(input, leftFilter)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	writer.WriteString("[")
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, record := range joiner.Join(value) {
+			if count > 0 {
+				writer.WriteString(",")
+			}
+			writer.WriteString("\n")
+			writer.WriteString(record.String())
+			count++
+		}
+	}
+	writer.WriteString("\n]\n")
+	return nil
+}
@@ -0,0 +1,101 @@
+// jsonlint 是一个JSON风格检查工具，用于发现文档中的风格和正确性问题
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/lint"
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+var (
+	inputFile string
+	strict    bool
+	rules     string
+	maxDepth  int
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.BoolVar(&strict, "strict", false, "使用严格规则集，所有规则都视为错误")
+	flag.StringVar(&rules, "rules", "", "逗号分隔的规则配置，格式为 规则=严重程度 (off, info, warning, error)")
+	flag.IntVar(&maxDepth, "max-depth", 0, "允许的最大嵌套深度，为0时使用规则集的默认值")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonlint - JSON风格检查工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonlint [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n规则:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(lint.Rules(), ", "))
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonlint -i input.json\n")
+	fmt.Fprintf(os.Stderr, "  cat input.json | jsonlint -strict\n")
+	fmt.Fprintf(os.Stderr, "  jsonlint -i input.json -rules \"numeric-string=off,key-casing=error\"\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonlint", "JSON风格检查工具")
+	flag.Parse()
+
+	// 构建配置
+	config := lint.DefaultConfig()
+	if strict {
+		config = lint.StrictConfig()
+	}
+	if maxDepth > 0 {
+		config.MaxDepth = maxDepth
+	}
+	if rules != "" {
+		for _, item := range strings.Split(rules, ",") {
+			parts := strings.SplitN(strings.TrimSpace(item), "=", 2)
+			if len(parts) != 2 {
+				cliutil.Errorf("无效的规则配置: %s", item)
+				os.Exit(2)
+			}
+			severity, err := lint.ParseSeverity(parts[1])
+			if err != nil {
+				cliutil.Error("", err)
+				os.Exit(2)
+			}
+			if err := config.Set(parts[0], severity); err != nil {
+				cliutil.Error("", err)
+				os.Exit(2)
+			}
+		}
+	}
+
+	// 读取输入
+	input, err := parser.ReadFile(inputFile)
+	if err != nil {
+		cliutil.Error("读取输入失败", err)
+		os.Exit(2)
+	}
+
+	// 检查
+	issues, err := lint.Lint(input, config)
+	if err != nil {
+		cliutil.Error("检查失败", err)
+		os.Exit(2)
+	}
+
+	name := inputFile
+	if name == "" {
+		name = "<stdin>"
+	}
+	for _, issue := range issues {
+		fmt.Printf("%s:%s\n", name, issue)
+	}
+
+	if lint.HasErrors(issues) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,171 @@
+// jsonmerge 是一个JSON合并工具，用于深度合并多个JSON文档并报告冲突
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	strategy    string
+	arrays      string
+	nullDeletes bool
+	pretty      bool
+	quiet       bool
+	outputFile  string
+)
+
+// strategies 是-strategy参数可选的值
+var strategies = map[string]utils.MergeStrategy{
+	"source": utils.MergePreferSource,
+	"target": utils.MergePreferTarget,
+	"error":  utils.MergeFailOnConflict,
+}
+
+// arrayModes 是-arrays参数可选的值
+var arrayModes = map[string]utils.ArrayMergeMode{
+	"replace": utils.ArrayReplace,
+	"concat":  utils.ArrayConcat,
+	"union":   utils.ArrayUnion,
+	"index":   utils.ArrayByIndex,
+}
+
+func init() {
+	flag.StringVar(&strategy, "strategy", "source", "冲突时使用的值 (source: 后面的文档, target: 前面的文档, error: 报错)")
+	flag.StringVar(&arrays, "arrays", "replace", "数组的合并方式 (replace, concat, union, index)")
+	flag.BoolVar(&nullDeletes, "null-deletes", false, "后面文档中的null删除前面文档中的键")
+	flag.BoolVar(&pretty, "p", false, "美化输出")
+	flag.BoolVar(&quiet, "q", false, "不在标准错误中输出冲突")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonmerge - JSON合并工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonmerge [选项] <文件1> <文件2> [文件...]\n\n")
+	fmt.Fprintf(os.Stderr, "按顺序把每个文件合并到前面的结果中，其中一个文件可以是 -，表示从标准输入读取。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n退出码:\n")
+	fmt.Fprintf(os.Stderr, "  0  合并成功\n")
+	fmt.Fprintf(os.Stderr, "  1  使用 -strategy error 时发现冲突\n")
+	fmt.Fprintf(os.Stderr, "  2  参数错误、读取或解析输入失败\n")
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonmerge base.json override.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonmerge -arrays union -p -o merged.json a.json b.json c.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonmerge -strategy error defaults.json user.json\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonmerge", "JSON合并工具")
+	flag.Parse()
+
+	// 检查参数
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+	options, err := mergeOptions()
+	if err != nil {
+		cliutil.UsageError(err.Error())
+		os.Exit(2)
+	}
+	stdin := 0
+	for _, arg := range flag.Args() {
+		if arg == "-" {
+			stdin++
+		}
+	}
+	if stdin > 1 {
+		cliutil.UsageError("只能有一个输入来自标准输入")
+		os.Exit(2)
+	}
+
+	// 依次合并
+	result := readValue(flag.Arg(0))
+	failed := false
+	for _, arg := range flag.Args()[1:] {
+		merged, conflicts, err := utils.MergeValuesWithOptions(result, readValue(arg), options)
+		if !quiet || err != nil {
+			for _, c := range conflicts {
+				cliutil.Infof("%s: 冲突 %s", arg, c.String())
+			}
+		}
+		if err != nil {
+			cliutil.Error("合并 "+arg+" 失败", err)
+			failed = true
+			continue
+		}
+		result = merged
+	}
+	if failed {
+		os.Exit(1)
+	}
+
+	if err := writeResult(result); err != nil {
+		cliutil.Error("写入输出失败", err)
+		os.Exit(2)
+	}
+}
+
+// mergeOptions 根据命令行参数创建合并选项
+func mergeOptions() (*utils.MergeOptions, error) {
+	s, ok := strategies[strategy]
+	if !ok {
+		return nil, fmt.Errorf("未知的冲突策略: %s", strategy)
+	}
+	a, ok := arrayModes[arrays]
+	if !ok {
+		return nil, fmt.Errorf("未知的数组合并方式: %s", arrays)
+	}
+	return &utils.MergeOptions{Strategy: s, Arrays: a, NullDeletes: nullDeletes}, nil
+}
+
+// readValue 读取并解析输入，- 表示标准输入，失败时以退出码2结束
+func readValue(arg string) types.JSONValue {
+	name := arg
+	if arg == "-" {
+		name = ""
+	}
+	input, err := parser.ReadFile(name)
+	if err != nil {
+		cliutil.Error("读取 "+arg+" 失败", err)
+		os.Exit(2)
+	}
+	value, err := parser.ParseBytesToValue(input)
+	if err != nil {
+		cliutil.ErrorInput("解析 "+arg+" 失败", err, input)
+		os.Exit(2)
+	}
+	return value
+}
+
+// writeResult 输出合并结果
+func writeResult(result types.JSONValue) error {
+	output := result.String()
+	if pretty {
+		var err error
+		output, err = utils.PrettyPrint(result, utils.DefaultPrettyOptions())
+		if err != nil {
+			return err
+		}
+	}
+
+	out, err := parser.CreateFile(outputFile, false)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, output)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
 	"github.com/UserLeeZJ/gojson/jsonpath"
 	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/types"
@@ -19,14 +20,20 @@ var (
 	compact    bool
 	pretty     bool
 	outputFile string
+	gzipOutput bool
+	quiet      bool
 )
 
 func init() {
-	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&inputFile, "i", "", "输入文件路径或HTTP(S)地址，如果为空则从标准输入读取")
+	cliutil.RegisterSourceFlags()
 	flag.StringVar(&path, "p", "$", "JSON Path表达式")
 	flag.BoolVar(&compact, "c", false, "输出为紧凑格式")
 	flag.BoolVar(&pretty, "pretty", false, "输出为美化格式")
 	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.BoolVar(&quiet, "q", false, "不输出任何内容，只通过退出码报告是否有匹配")
+	cliutil.RegisterLogFlag()
 	flag.Usage = usage
 }
 
@@ -36,48 +43,66 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "  jsonpath [选项]\n\n")
 	fmt.Fprintf(os.Stderr, "选项:\n")
 	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n退出码:\n")
+	fmt.Fprintf(os.Stderr, "  0  至少有一个匹配的结果\n")
+	fmt.Fprintf(os.Stderr, "  1  没有匹配的结果\n")
+	fmt.Fprintf(os.Stderr, "  2  参数错误、读取或解析输入失败\n")
 	fmt.Fprintf(os.Stderr, "\n示例:\n")
 	fmt.Fprintf(os.Stderr, "  jsonpath -i input.json -p \"$.store.book[0].title\"\n")
 	fmt.Fprintf(os.Stderr, "  cat input.json | jsonpath -p \"$.store.book[*].author\"\n")
+	fmt.Fprintf(os.Stderr, "  jsonpath -i https://api.example.com/data.json -p \"$.items[*].id\" -timeout 10s\n")
+	fmt.Fprintf(os.Stderr, "  jsonpath -i report.json -p \"$.errors[0]\" -q && echo 存在错误\n")
 }
 
 func main() {
+	cliutil.HandleHelpJSON("jsonpath", "JSON Path查询工具")
+
+	// 加载配置
+	config, err := cliutil.LoadConfig()
+	if err != nil {
+		cliutil.Error("加载配置失败", err)
+		os.Exit(2)
+	}
 	flag.Parse()
 
 	// 检查参数
 	if compact && pretty {
-		fmt.Fprintf(os.Stderr, "错误: 不能同时指定紧凑格式和美化格式\n")
-		os.Exit(1)
+		cliutil.UsageError("不能同时指定紧凑格式和美化格式")
+		os.Exit(2)
 	}
 
 	// 读取输入
-	var input []byte
-	var err error
-	if inputFile == "" {
-		input, err = io.ReadAll(os.Stdin)
-	} else {
-		input, err = os.ReadFile(inputFile)
-	}
+	input, err := cliutil.ReadSource(inputFile, cliutil.SourceFlags())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "读取输入失败: %v\n", err)
-		os.Exit(1)
+		cliutil.Error("读取输入失败", err)
+		os.Exit(2)
 	}
 
 	// 解析JSON
 	jsonValue, err := parser.ParseToValue(string(input))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "解析JSON失败: %v\n", err)
-		os.Exit(1)
+		cliutil.ErrorInput("解析JSON失败", err, input)
+		os.Exit(2)
 	}
 
 	// 执行JSON Path查询
 	results, err := jsonpath.QueryJSONPath(jsonValue, path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "查询失败: %v\n", err)
-		os.Exit(1)
+		cliutil.Error("查询失败", err)
+		os.Exit(2)
+	}
+
+	// 静默模式只报告是否有匹配
+	if quiet {
+		if len(results) == 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
 	// 处理结果
+	prettyOptions := utils.DefaultPrettyOptions()
+	prettyOptions.Indent = config.Indent
 	var output string
 	if len(results) == 0 {
 		output = "[]" // 空结果
@@ -86,7 +111,7 @@ func main() {
 		if compact {
 			output, err = utils.CompressJSON(results[0])
 		} else if pretty {
-			output, err = utils.PrettyPrint(results[0], utils.DefaultPrettyOptions())
+			output, err = utils.PrettyPrint(results[0], prettyOptions)
 		} else {
 			output = results[0].String()
 		}
@@ -100,25 +125,37 @@ func main() {
 		if compact {
 			output, err = utils.CompressJSON(array)
 		} else if pretty {
-			output, err = utils.PrettyPrint(array, utils.DefaultPrettyOptions())
+			output, err = utils.PrettyPrint(array, prettyOptions)
 		} else {
 			output = array.String()
 		}
 	}
 	
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "格式化结果失败: %v\n", err)
-		os.Exit(1)
+		cliutil.Error("格式化结果失败", err)
+		os.Exit(2)
 	}
 
 	// 写入输出
 	if outputFile == "" {
-		fmt.Println(output)
-	} else {
-		err = os.WriteFile(outputFile, []byte(output), 0644)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "写入输出失败: %v\n", err)
-			os.Exit(1)
+		if !gzipOutput && config.UseColor(os.Stdout) {
+			output = cliutil.Colorize(output)
+		}
+		output += "\n"
+	}
+	out, err := parser.CreateFile(outputFile, gzipOutput)
+	if err == nil {
+		_, err = io.WriteString(out, output)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
 		}
 	}
+	if err != nil {
+		cliutil.Error("写入输出失败", err)
+		os.Exit(2)
+	}
+
+	if len(results) == 0 {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,152 @@
+// jsonrun 是一个JSON批量转换工具，按菜谱对输入中的每个文档依次执行选择、重命名、补丁、排序和投影
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/recipe"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	inputFile  string
+	outputFile string
+	pretty     bool
+	gzipOutput bool
+	keepGoing  bool
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&pretty, "p", false, "美化输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.BoolVar(&keepGoing, "k", false, "某个文档转换失败时跳过它继续处理后面的文档")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonrun - JSON批量转换工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonrun <菜谱文件> [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "输入可以包含多个文档（NDJSON或直接连接的JSON），每个文档的转换结果输出为一行。\n")
+	fmt.Fprintf(os.Stderr, "菜谱的格式:\n")
+	fmt.Fprintf(os.Stderr, "  {\"steps\": [\n")
+	fmt.Fprintf(os.Stderr, "    {\"op\": \"select\", \"path\": \"$.items[*]\"},\n")
+	fmt.Fprintf(os.Stderr, "    {\"op\": \"rename\", \"keys\": {\"qty\": \"quantity\"}},\n")
+	fmt.Fprintf(os.Stderr, "    {\"op\": \"patch\", \"patch\": [{\"op\": \"remove\", \"path\": \"/0\"}]},\n")
+	fmt.Fprintf(os.Stderr, "    {\"op\": \"sort\", \"by\": \"$.price\", \"order\": \"desc\"},\n")
+	fmt.Fprintf(os.Stderr, "    {\"op\": \"project\", \"fields\": {\"id\": \"$.id\", \"name\": \"$.user.name\"}}\n")
+	fmt.Fprintf(os.Stderr, "  ]}\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n退出码:\n")
+	fmt.Fprintf(os.Stderr, "  0  所有文档转换成功\n")
+	fmt.Fprintf(os.Stderr, "  1  有文档转换失败\n")
+	fmt.Fprintf(os.Stderr, "  2  参数错误、菜谱无效、读取或解析输入失败\n")
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonrun recipe.json -i data.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonrun recipe.json -i events.ndjson -k -o out.ndjson\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonrun", "JSON批量转换工具")
+	flag.Parse()
+
+	// 菜谱文件之后也可以有选项
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	recipeFile := flag.Arg(0)
+	flag.CommandLine.Parse(flag.Args()[1:])
+	if flag.NArg() > 0 {
+		cliutil.UsageError("只能指定一个菜谱文件")
+		os.Exit(2)
+	}
+
+	data, err := parser.ReadFile(recipeFile)
+	if err != nil {
+		cliutil.Error("读取菜谱失败", err)
+		os.Exit(2)
+	}
+	pipeline, err := recipe.ParsePipeline(data)
+	if err != nil {
+		cliutil.Error("菜谱无效", err)
+		os.Exit(2)
+	}
+
+	// 打开输入和输出
+	input, err := parser.OpenFile(inputFile)
+	if err != nil {
+		cliutil.Error("打开输入文件失败", err)
+		os.Exit(2)
+	}
+	defer input.Close()
+
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(2)
+	}
+	writer := bufio.NewWriter(output)
+
+	failed, err := run(pipeline, input, writer)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("转换失败", err)
+		os.Exit(2)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// run 逐个读取文档并转换，返回转换失败的文档数量。
+// 没有使用-k时第一个失败的文档结束处理
+func run(pipeline *recipe.Pipeline, input io.Reader, writer *bufio.Writer) (int, error) {
+	source := stream.NewMessageSource(stream.NewMessageReader(input, stream.FramingConcatenated, stream.FramingOptions{}))
+	failed := 0
+	for n := 1; ; n++ {
+		doc, err := source.Next()
+		if err == io.EOF {
+			return failed, nil
+		}
+		if err != nil {
+			return failed, err
+		}
+
+		result, err := pipeline.Apply(doc)
+		if err != nil {
+			cliutil.Error(fmt.Sprintf("转换第%d个文档失败", n), err)
+			failed++
+			if !keepGoing {
+				return failed, nil
+			}
+			continue
+		}
+
+		output := result.String()
+		if pretty {
+			if output, err = utils.PrettyPrint(result, utils.DefaultPrettyOptions()); err != nil {
+				return failed, err
+			}
+		}
+		if _, err := fmt.Fprintln(writer, output); err != nil {
+			return failed, err
+		}
+	}
+}
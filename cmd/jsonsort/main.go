@@ -0,0 +1,408 @@
+// jsonsort 是一个JSON排序工具，按JSON Path选择的键对大型数组排序
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+var (
+	inputFile    string
+	outputFile   string
+	filter       string
+	by           string
+	desc         bool
+	bufferSize   int
+	gzipOutput   bool
+	showProgress bool
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
+	flag.StringVar(&filter, "f", "$[*]", "JSON Path过滤器，用于选择要排序的元素")
+	flag.StringVar(&by, "by", "", "排序键的JSON Path，以元素为根，例如 $.createdAt")
+	flag.BoolVar(&desc, "desc", false, "按降序排序")
+	flag.IntVar(&bufferSize, "buffer", 100000, "内存中最多保留的元素数量，超出时写入临时文件进行外部归并排序")
+	flag.BoolVar(&showProgress, "progress", false, "在标准错误上显示读取输入的进度")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonsort - JSON排序工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonsort -by <路径> [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "缺少排序键的元素排在最后，排序键相同的元素保持原有顺序。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonsort -i data.json -by \"$.createdAt\" -desc\n")
+	fmt.Fprintf(os.Stderr, "  jsonsort -i export.json -f \"$.events[*]\" -by \"$.user.id\" -o sorted.json\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonsort", "JSON排序工具")
+
+	// 配置文件提供参数的默认值
+	config, err := cliutil.LoadConfig()
+	if err != nil {
+		cliutil.Error("加载配置失败", err)
+		os.Exit(2)
+	}
+	desc = config.SortOrder == "desc"
+	flag.Parse()
+
+	// 检查参数
+	if by == "" {
+		cliutil.UsageError("必须使用 -by 指定排序键")
+		os.Exit(2)
+	}
+	if bufferSize <= 0 {
+		cliutil.UsageError("缓冲区大小必须大于0")
+		os.Exit(2)
+	}
+	order := utils.Ascending
+	if desc {
+		order = utils.Descending
+	}
+	less, err := utils.ByPath(by, order)
+	if err != nil {
+		cliutil.Error("无效的排序键", err)
+		os.Exit(2)
+	}
+
+	// 打开输入
+	input, err := cliutil.OpenCounted(inputFile)
+	if err != nil {
+		cliutil.Error("打开输入文件失败", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	// 打开输出
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
+	}
+	writer := bufio.NewWriter(output)
+	err = sortStream(input, writer, less)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("排序失败", err)
+		os.Exit(1)
+	}
+}
+
+// sortStream 读取所有元素并按排序规则输出。
+// 元素数量不超过缓冲区大小时直接在内存中排序，
+// 否则把每个已排序的批次写入临时文件，再进行多路归并。
+func sortStream(input *cliutil.CountedInput, writer *bufio.Writer, less utils.ArrayOrder) error {
+	reader, err := stream.NewElementReader(input, filter)
+	if err != nil {
+		return err
+	}
+	var source stream.ValueSource = reader
+	if showProgress {
+		progress := input.WithProgress(reader)
+		defer progress.Finish()
+		source = progress
+	}
+
+	tempDir := ""
+	var runs []string
+	defer func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	buffer := make([]types.JSONValue, 0)
+	for {
+		value, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buffer = append(buffer, value)
+		if len(buffer) < bufferSize {
+			continue
+		}
+
+		// 缓冲区已满，写出一个已排序的批次
+		if tempDir == "" {
+			if tempDir, err = os.MkdirTemp("", "jsonsort-*"); err != nil {
+				return err
+			}
+		}
+		run := filepath.Join(tempDir, fmt.Sprintf("run-%d.jsonl", len(runs)))
+		if err := writeRun(run, buffer, less); err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		buffer = buffer[:0]
+	}
+
+	out := &arrayWriter{writer: writer}
+	if len(runs) == 0 {
+		sort.SliceStable(buffer, func(i, j int) bool {
+			return less(buffer[i], buffer[j])
+		})
+		for _, value := range buffer {
+			out.write(value)
+		}
+		return out.close()
+	}
+
+	if len(buffer) > 0 {
+		run := filepath.Join(tempDir, fmt.Sprintf("run-%d.jsonl", len(runs)))
+		if err := writeRun(run, buffer, less); err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+	if err := mergeRuns(runs, out, less); err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// writeRun 对批次排序并按每行一个元素写入临时文件
+func writeRun(path string, values []types.JSONValue, less utils.ArrayOrder) error {
+	sort.SliceStable(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	for _, value := range values {
+		w.WriteString(value.String())
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// maxMergeFanIn 是一次归并同时打开的批次文件数上限，
+// 批次更多时先分组归并成较大的批次，直到不超过上限
+const maxMergeFanIn = 64
+
+// runReader 逐行读取临时文件中的元素
+type runReader struct {
+	index   int
+	file    *os.File
+	scanner *bufio.Scanner
+	current types.JSONValue
+}
+
+// openRun 打开一个批次文件
+func openRun(path string, index int) (*runReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	return &runReader{index: index, file: file, scanner: scanner}, nil
+}
+
+// next 读取下一个元素，读完时关闭文件并返回false
+func (r *runReader) next() (bool, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return false, err
+		}
+		return false, r.close()
+	}
+	value, err := parser.ParseBytesToValue(r.scanner.Bytes())
+	if err != nil {
+		return false, err
+	}
+	r.current = value
+	return true, nil
+}
+
+// close 关闭批次文件，可以重复调用
+func (r *runReader) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// runHeap 是按当前元素排序的最小堆，元素相同时较早的批次优先以保持稳定排序
+type runHeap struct {
+	readers []*runReader
+	less    utils.ArrayOrder
+}
+
+func (h *runHeap) Len() int { return len(h.readers) }
+
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.readers[i], h.readers[j]
+	if h.less(a.current, b.current) {
+		return true
+	}
+	if h.less(b.current, a.current) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *runHeap) Swap(i, j int) { h.readers[i], h.readers[j] = h.readers[j], h.readers[i] }
+
+func (h *runHeap) Push(x interface{}) { h.readers = append(h.readers, x.(*runReader)) }
+
+func (h *runHeap) Pop() interface{} {
+	last := h.readers[len(h.readers)-1]
+	h.readers = h.readers[:len(h.readers)-1]
+	return last
+}
+
+// mergeRuns 多路归并所有已排序的批次。
+// 批次数超过maxMergeFanIn时，先把相邻的批次分组归并到新的临时文件中，
+// 分组保持批次原有的顺序，因此多轮归并后排序仍然稳定。
+func mergeRuns(runs []string, out *arrayWriter, less utils.ArrayOrder) error {
+	for pass := 0; len(runs) > maxMergeFanIn; pass++ {
+		merged := make([]string, 0, (len(runs)+maxMergeFanIn-1)/maxMergeFanIn)
+		for start := 0; start < len(runs); start += maxMergeFanIn {
+			end := start + maxMergeFanIn
+			if end > len(runs) {
+				end = len(runs)
+			}
+			group := runs[start:end]
+			path := filepath.Join(filepath.Dir(group[0]), fmt.Sprintf("merge-%d-%d.jsonl", pass, len(merged)))
+			if err := mergeToFile(group, path, less); err != nil {
+				return err
+			}
+			for _, run := range group {
+				os.Remove(run)
+			}
+			merged = append(merged, path)
+		}
+		runs = merged
+	}
+	return mergeGroup(runs, less, func(value types.JSONValue) error {
+		out.write(value)
+		return nil
+	})
+}
+
+// mergeToFile 把一组批次归并为一个新的批次文件
+func mergeToFile(runs []string, path string, less utils.ArrayOrder) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	err = mergeGroup(runs, less, func(value types.JSONValue) error {
+		w.WriteString(value.String())
+		return w.WriteByte('\n')
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// mergeGroup 同时打开一组批次并按顺序把元素交给emit，每个批次读完后立即关闭
+func mergeGroup(runs []string, less utils.ArrayOrder, emit func(types.JSONValue) error) error {
+	h := &runHeap{less: less}
+	var opened []*runReader
+	defer func() {
+		for _, reader := range opened {
+			reader.close()
+		}
+	}()
+
+	for i, run := range runs {
+		reader, err := openRun(run, i)
+		if err != nil {
+			return err
+		}
+		opened = append(opened, reader)
+		ok, err := reader.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.readers = append(h.readers, reader)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		reader := h.readers[0]
+		if err := emit(reader.current); err != nil {
+			return err
+		}
+		ok, err := reader.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// arrayWriter 把元素写为JSON数组
+type arrayWriter struct {
+	writer *bufio.Writer
+	count  int
+}
+
+// write 写入一个元素
+func (a *arrayWriter) write(value types.JSONValue) {
+	if a.count == 0 {
+		a.writer.WriteString("[\n")
+	} else {
+		a.writer.WriteString(",\n")
+	}
+	a.writer.WriteString(value.String())
+	a.count++
+}
+
+// close 结束数组
+func (a *arrayWriter) close() error {
+	if a.count == 0 {
+		a.writer.WriteString("[")
+	}
+	_, err := a.writer.WriteString("\n]\n")
+	return err
+}
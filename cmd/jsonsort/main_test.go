@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+func TestMergeRunsMultiPass(t *testing.T) {
+	less, err := utils.ByPath("$.k", utils.Ascending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 批次数超过两倍的maxMergeFanIn，需要先分组归并
+	dir := t.TempDir()
+	count := maxMergeFanIn*2 + 7
+	var runs []string
+	for i := 0; i < count; i++ {
+		var values []types.JSONValue
+		for k := 0; k < 3; k++ {
+			value, err := parser.ParseBytesToValue([]byte(fmt.Sprintf(`{"k":%d,"run":%d}`, (i+k)%5, i)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, value)
+		}
+		run := filepath.Join(dir, fmt.Sprintf("run-%d.jsonl", i))
+		if err := writeRun(run, values, less); err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, run)
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	out := &arrayWriter{writer: w}
+	if err := mergeRuns(runs, out, less); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.close(); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+
+	result, err := parser.ParseBytesToValue(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := result.(*types.JSONArray).Values()
+	if len(values) != count*3 {
+		t.Fatalf("元素数量 = %d, 期望 %d", len(values), count*3)
+	}
+	for i := 1; i < len(values); i++ {
+		prev := values[i-1].(*types.JSONObject)
+		cur := values[i].(*types.JSONObject)
+		pk, _ := prev.GetInt("k")
+		ck, _ := cur.GetInt("k")
+		if pk > ck {
+			t.Fatalf("第%d个元素顺序错误: %s 在 %s 之后", i, cur, prev)
+		}
+		// 键相同时保持批次原有的顺序
+		pr, _ := prev.GetInt("run")
+		cr, _ := cur.GetInt("run")
+		if pk == ck && pr > cr {
+			t.Fatalf("第%d个元素不稳定: %s 在 %s 之后", i, cur, prev)
+		}
+	}
+
+	// 中间批次在归并后被删除
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) > maxMergeFanIn {
+		t.Errorf("临时目录中剩余 %d 个文件", len(entries))
+	}
+}
@@ -0,0 +1,155 @@
+// jsonsplit 是一个JSON拆分工具，用于把大型JSON文件中的元素拆分为多个小文件
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+var (
+	inputFile     string
+	filter        string
+	chunkSize     int
+	outputPattern string
+	gzipOutput    bool
+	showProgress  bool
+)
+
+func init() {
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
+	flag.StringVar(&filter, "f", "$[*]", "JSON Path过滤器，用于选择要拆分的元素")
+	flag.IntVar(&chunkSize, "chunk", 10000, "每个输出文件包含的元素数量")
+	flag.StringVar(&outputPattern, "o", "chunk-%03d.json", "输出文件名模板，%d会被替换为从1开始的文件序号")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩每个输出文件")
+	flag.BoolVar(&showProgress, "progress", false, "在标准错误上显示读取输入的进度")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonsplit - JSON拆分工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonsplit [选项]\n\n")
+	fmt.Fprintf(os.Stderr, "每个输出文件都是一个包含若干元素的JSON数组，可以用jsonjoin合并回来。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonsplit -i big.json -f \"$.items[*]\" -chunk 10000 -o out-%%03d.json\n")
+	fmt.Fprintf(os.Stderr, "  cat big.json | jsonsplit -chunk 500\n")
+	fmt.Fprintf(os.Stderr, "  jsonsplit -i big.json.gz -z -o out-%%03d.json.gz\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonsplit", "JSON拆分工具")
+	flag.Parse()
+
+	// 检查参数
+	if chunkSize <= 0 {
+		cliutil.UsageError("每个文件的元素数量必须大于0")
+		os.Exit(2)
+	}
+	if !strings.Contains(outputPattern, "%") {
+		cliutil.UsageError("输出文件名模板必须包含序号占位符，例如 out-%%03d.json")
+		os.Exit(2)
+	}
+
+	// 打开输入
+	input, err := cliutil.OpenCounted(inputFile)
+	if err != nil {
+		cliutil.Error("打开输入文件失败", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	files, count, err := split(input)
+	if err != nil {
+		cliutil.Error("拆分失败", err)
+		os.Exit(1)
+	}
+	cliutil.Infof("已写入 %d 个文件，共 %d 个元素", files, count)
+}
+
+// chunkWriter 把元素写入当前的输出文件
+type chunkWriter struct {
+	file   io.WriteCloser
+	writer *bufio.Writer
+	size   int
+}
+
+// close 结束当前文件中的数组并关闭文件
+func (c *chunkWriter) close() error {
+	c.writer.WriteString("\n]\n")
+	if err := c.writer.Flush(); err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+// split 逐个读取元素并写入输出文件，返回文件数量和元素数量
+func split(input *cliutil.CountedInput) (int, int, error) {
+	reader, err := stream.NewElementReader(input, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	var source stream.ValueSource = reader
+	if showProgress {
+		progress := input.WithProgress(reader)
+		defer progress.Finish()
+		source = progress
+	}
+
+	var current *chunkWriter
+	files, count := 0, 0
+	for {
+		value, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if current != nil {
+				current.close()
+			}
+			return files, count, err
+		}
+
+		// 当前文件已满时切换到下一个文件
+		if current != nil && current.size == chunkSize {
+			if err := current.close(); err != nil {
+				return files, count, err
+			}
+			current = nil
+		}
+		if current == nil {
+			files++
+			file, err := parser.CreateFile(fmt.Sprintf(outputPattern, files), gzipOutput)
+			if err != nil {
+				return files, count, err
+			}
+			current = &chunkWriter{file: file, writer: bufio.NewWriter(file)}
+			current.writer.WriteString("[\n")
+		}
+
+		if current.size > 0 {
+			current.writer.WriteString(",\n")
+		}
+		current.writer.WriteString(value.String())
+		current.size++
+		count++
+	}
+
+	if current != nil {
+		if err := current.close(); err != nil {
+			return files, count, err
+		}
+	}
+	return files, count, nil
+}
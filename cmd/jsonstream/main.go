@@ -5,31 +5,49 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/expr"
+	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/stream"
 	"github.com/UserLeeZJ/gojson/types"
 	"github.com/UserLeeZJ/gojson/utils"
 )
 
 var (
-	inputFile  string
-	outputFile string
-	filter     string
-	limit      int
-	pretty     bool
-	compact    bool
+	inputFile    string
+	outputFile   string
+	filter       string
+	limit        int
+	pretty       bool
+	compact      bool
+	sampleRate   float64
+	sampleSize   int
+	seed         int64
+	gzipOutput   bool
+	showProgress bool
+	assignments  assignmentList
+
+	// prettyOptions 是美化输出的选项
+	prettyOptions = utils.DefaultPrettyOptions()
 )
 
 func init() {
 	flag.StringVar(&inputFile, "i", "", "输入文件路径，如果为空则从标准输入读取")
 	flag.StringVar(&outputFile, "o", "", "输出文件路径，如果为空则输出到标准输出")
+	flag.BoolVar(&gzipOutput, "z", false, "使用gzip压缩输出")
 	flag.StringVar(&filter, "f", "$", "JSON Path过滤器，用于选择要处理的元素")
 	flag.IntVar(&limit, "limit", 0, "限制输出的元素数量，0表示不限制")
 	flag.BoolVar(&pretty, "pretty", false, "输出为美化格式")
 	flag.BoolVar(&compact, "c", false, "输出为紧凑格式")
+	flag.Float64Var(&sampleRate, "sample", 0, "按概率抽样匹配的元素，取值范围(0, 1]，0表示不抽样")
+	flag.IntVar(&sampleSize, "sample-size", 0, "用蓄水池抽样保留固定数量的元素，0表示不抽样")
+	flag.Int64Var(&seed, "seed", 1, "抽样使用的随机数种子")
+	flag.BoolVar(&showProgress, "progress", false, "在标准错误上显示读取输入的进度")
+	flag.Var(&assignments, "set", "为每个匹配的对象计算字段，格式为 \"字段 = 表达式\"，可重复指定")
+	cliutil.RegisterLogFlag()
 	flag.Usage = usage
 }
 
@@ -42,192 +60,148 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "\n示例:\n")
 	fmt.Fprintf(os.Stderr, "  jsonstream -i large.json -o output.json -f \"$.items[*].name\"\n")
 	fmt.Fprintf(os.Stderr, "  cat large.json | jsonstream -f \"$.items[*]\" > output.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonstream -i large.json -f \"$.items[*]\" -sample 0.01 -seed 42\n")
+	fmt.Fprintf(os.Stderr, "  jsonstream -i orders.json -f \"$.orders[*]\" --set 'total = $.price * $.qty'\n")
 }
 
 func main() {
+	cliutil.HandleHelpJSON("jsonstream", "JSON流式处理工具")
+
+	// 配置文件提供美化输出的缩进
+	config, err := cliutil.LoadConfig()
+	if err != nil {
+		cliutil.Error("加载配置失败", err)
+		os.Exit(2)
+	}
+	prettyOptions.Indent = config.Indent
 	flag.Parse()
 
 	// 检查参数
 	if pretty && compact {
-		fmt.Fprintf(os.Stderr, "错误: 不能同时指定美化格式和紧凑格式\n")
+		cliutil.UsageError("不能同时指定美化格式和紧凑格式")
 		os.Exit(1)
 	}
 
 	// 打开输入
-	var input io.Reader
-
-	if inputFile == "" {
-		input = os.Stdin
-	} else {
-		file, err := os.Open(inputFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "打开输入文件失败: %v\n", err)
-			os.Exit(1)
-		}
-		defer file.Close()
-		input = file
+	input, err := cliutil.OpenCounted(inputFile)
+	if err != nil {
+		cliutil.Error("打开输入文件失败", err)
+		os.Exit(1)
 	}
+	defer input.Close()
 
 	// 打开输出
-	var output io.Writer
-	if outputFile == "" {
-		output = os.Stdout
-	} else {
-		file, err := os.Create(outputFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
-			os.Exit(1)
-		}
-		defer file.Close()
-		output = file
+	output, err := parser.CreateFile(outputFile, gzipOutput)
+	if err != nil {
+		cliutil.Error("创建输出文件失败", err)
+		os.Exit(1)
 	}
-
-	// 创建流式解析器
-	tokenizer := stream.NewJSONTokenizer(input)
-
 	// 创建输出缓冲区
 	writer := bufio.NewWriter(output)
-	defer writer.Flush()
 
 	// 处理流
-	processStream(tokenizer, writer)
+	err = processStream(input, writer)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cliutil.Error("处理失败", err)
+		os.Exit(1)
+	}
 }
 
-func processStream(tokenizer *stream.JSONTokenizer, writer *bufio.Writer) {
-	// 解析过滤器
-	segments := parseFilter(filter)
-
-	// 当前路径
-	currentPath := make([]string, 0)
-
-	// 当前深度
-	depth := 0
-
-	// 是否匹配
-	matching := false
-
-	// 匹配深度
-	matchDepth := 0
-
-	// 当前匹配的值
-	var currentValue types.JSONValue
-
-	// 计数器
-	count := 0
+func processStream(input *cliutil.CountedInput, writer *bufio.Writer) error {
+	reader, err := stream.NewElementReader(input, filter)
+	if err != nil {
+		return err
+	}
 
-	// 是否是第一个输出
-	first := true
+	// 按需显示进度和包装采样器
+	var source stream.ValueSource = reader
+	if showProgress {
+		progress := input.WithProgress(reader)
+		defer progress.Finish()
+		source = progress
+	}
+	if sampleRate > 0 || sampleSize > 0 {
+		source, err = stream.NewSampler(source, stream.SampleOptions{
+			Rate: sampleRate,
+			Size: sampleSize,
+			Seed: seed,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	// 写入数组开始
 	writer.WriteString("[\n")
 
-	// 处理令牌
-	for {
-		token := tokenizer.Next()
-
-		// 检查是否结束
-		if token.Type == stream.TokenEOF {
-			break
-		}
-
-		// 检查是否有错误
-		if token.Type == stream.TokenError {
-			fmt.Fprintf(os.Stderr, "解析错误: %v\n", token.Error)
-			break
+	count := 0
+	_, err = stream.SubscribeSource(source, func(value types.JSONValue) error {
+		// 输出分隔符
+		if count > 0 {
+			writer.WriteString(",\n")
 		}
+		count++
 
-		// 更新路径
-		switch token.Type {
-		case stream.TokenObjectStart:
-			depth++
-			currentPath = append(currentPath, "{")
-		case stream.TokenObjectEnd:
-			if len(currentPath) > 0 {
-				currentPath = currentPath[:len(currentPath)-1]
-			}
-			depth--
-		case stream.TokenArrayStart:
-			depth++
-			currentPath = append(currentPath, "[")
-		case stream.TokenArrayEnd:
-			if len(currentPath) > 0 {
-				currentPath = currentPath[:len(currentPath)-1]
+		// 计算字段
+		if len(assignments) > 0 {
+			obj, err := value.AsObject()
+			if err != nil {
+				return fmt.Errorf("第%d个元素不是对象，不能计算字段", count)
 			}
-			depth--
-		case stream.TokenPropertyName:
-			if len(currentPath) > 0 && currentPath[len(currentPath)-1] == "{" {
-				currentPath[len(currentPath)-1] = token.Value.(string)
-			} else {
-				currentPath = append(currentPath, token.Value.(string))
+			if err := expr.ApplyAll(obj, assignments); err != nil {
+				return err
 			}
 		}
 
-		// 检查是否匹配过滤器
-		pathStr := buildPath(currentPath)
-		if matchesFilter(pathStr, segments) && !matching {
-			matching = true
-			matchDepth = depth
-			currentValue = nil
+		// 格式化输出
+		var output string
+		var err error
+		if pretty {
+			output, err = utils.PrettyPrint(value, prettyOptions)
+		} else if compact {
+			output, err = utils.CompressJSON(value)
+		} else {
+			output = value.String()
 		}
+		if err != nil {
+			return err
+		}
+		writer.WriteString(output)
 
-		// 如果匹配，收集值
-		if matching {
-			// 如果深度小于匹配深度，结束匹配
-			if depth < matchDepth {
-				matching = false
-
-				// 如果有值，输出
-				if currentValue != nil {
-					// 检查是否达到限制
-					if limit > 0 && count >= limit {
-						break
-					}
-
-					// 输出分隔符
-					if !first {
-						writer.WriteString(",\n")
-					} else {
-						first = false
-					}
-
-					// 格式化输出
-					var output string
-					if pretty {
-						output, _ = utils.PrettyPrint(currentValue, utils.DefaultPrettyOptions())
-					} else if compact {
-						output, _ = utils.CompressJSON(currentValue)
-					} else {
-						output = currentValue.String()
-					}
-
-					writer.WriteString(output)
-					count++
-				}
-			}
+		// 达到数量限制后不再读取输入
+		if limit > 0 && count >= limit {
+			return stream.StopSubscription
 		}
-	}
+		return nil
+	})
 
 	// 写入数组结束
 	writer.WriteString("\n]")
+	return err
 }
 
-// 解析过滤器
-func parseFilter(filter string) []string {
-	// 简单实现，只支持基本路径
-	segments := strings.Split(filter, ".")
-	if segments[0] == "$" {
-		segments = segments[1:]
-	}
-	return segments
-}
+// assignmentList 收集重复指定的 -set 计算字段
+type assignmentList []*expr.Assignment
 
-// 构建路径
-func buildPath(path []string) string {
-	return "$." + strings.Join(path, ".")
+func (a *assignmentList) String() string {
+	specs := make([]string, len(*a))
+	for i, assignment := range *a {
+		specs[i] = assignment.String()
+	}
+	return strings.Join(specs, ", ")
 }
 
-// 检查路径是否匹配过滤器
-func matchesFilter(path string, segments []string) bool {
-	// 简单实现，只支持基本匹配
-	return strings.Contains(path, strings.Join(segments, "."))
+func (a *assignmentList) Set(value string) error {
+	assignment, err := expr.ParseAssignment(value)
+	if err != nil {
+		return err
+	}
+	*a = append(*a, assignment)
+	return nil
 }
@@ -0,0 +1,91 @@
+// jsonvalidate 是一个JSON校验工具，用于检查文件是否为合法的JSON
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/UserLeeZJ/gojson/cmd/internal/cliutil"
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+var (
+	quiet bool
+	jobs  int
+)
+
+func init() {
+	flag.BoolVar(&quiet, "q", false, "不输出任何内容，只通过退出码报告结果")
+	flag.IntVar(&jobs, "j", 1, "同时处理的文件数量，0表示使用所有CPU")
+	cliutil.RegisterLogFlag()
+	flag.Usage = usage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "jsonvalidate - JSON校验工具\n\n")
+	fmt.Fprintf(os.Stderr, "用法:\n")
+	fmt.Fprintf(os.Stderr, "  jsonvalidate [选项] [文件]...\n\n")
+	fmt.Fprintf(os.Stderr, "没有指定文件时从标准输入读取。\n\n")
+	fmt.Fprintf(os.Stderr, "选项:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n退出码:\n")
+	fmt.Fprintf(os.Stderr, "  0  所有输入都是合法的JSON\n")
+	fmt.Fprintf(os.Stderr, "  1  至少一个输入不是合法的JSON\n")
+	fmt.Fprintf(os.Stderr, "  2  参数错误或读取输入失败\n")
+	fmt.Fprintf(os.Stderr, "\n示例:\n")
+	fmt.Fprintf(os.Stderr, "  jsonvalidate config.json data/*.json\n")
+	fmt.Fprintf(os.Stderr, "  jsonvalidate -j 8 -q $(git ls-files '*.json')\n")
+	fmt.Fprintf(os.Stderr, "  curl -s https://api.example.com/data | jsonvalidate -q && echo ok\n")
+}
+
+func main() {
+	cliutil.HandleHelpJSON("jsonvalidate", "JSON校验工具")
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+
+	exitCode := 0
+	cliutil.Parallel(cliutil.Jobs(jobs), len(paths), func(i int) result {
+		return validate(paths[i])
+	}, func(i int, r result) {
+		name := paths[i]
+		if name == "" {
+			name = "<stdin>"
+		}
+		switch {
+		case r.readErr != nil:
+			cliutil.Error("读取输入失败", r.readErr)
+			exitCode = 2
+		case r.err != nil:
+			if !quiet {
+				fmt.Printf("%s: 无效: %v\n", name, r.err)
+			}
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		case !quiet:
+			fmt.Printf("%s: 有效\n", name)
+		}
+	})
+	os.Exit(exitCode)
+}
+
+// result 是校验一个输入的结果
+type result struct {
+	readErr error // 读取失败的原因
+	err     error // 输入不是合法JSON的原因
+}
+
+// validate 读取并校验一个输入
+func validate(path string) result {
+	input, err := parser.ReadFile(path)
+	if err != nil {
+		return result{readErr: err}
+	}
+	_, err = parser.ParseBytesToValue(input)
+	return result{err: err}
+}
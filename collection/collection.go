@@ -0,0 +1,639 @@
+// Package collection 提供一个嵌入式的小型JSON文档集合，
+// 适合命令行工具和测试保存少量结构化数据。
+//
+// 每个文档是一个JSONObject，用IDField字段保存唯一的ID，插入时没有ID的文档会自动分配一个。
+// 集合可以只存在于内存中，也可以通过Open持久化到NDJSON文件：每次写入在文件末尾追加一行，
+// 打开时重放整个文件，追加的记录积累到一定数量后自动压缩为每个文档一行。
+// 文件的第一行是格式的版本头，旧版本的文件在打开时被迁移并重写为当前版本。
+//
+// 写入在记录追加到文件后返回，默认由操作系统决定何时写入磁盘，系统崩溃或断电可能丢失最近的写入；
+// 设置Options.Sync后每次写入都等待数据写入磁盘。进程在写入一行时崩溃留下的不完整的最后一行
+// 在下次打开时被丢弃，这次写入视为没有发生；文件中间的无效记录仍然导致Open失败。
+//
+// 示例：
+//
+//	users, err := collection.Open("users.ndjson", nil)
+//	id, err := users.Insert(parser.MustParse(`{"name": "Alice", "age": 30}`).(*types.JSONObject))
+//	adults, err := users.Find("$.age", types.NewJSONNumber(30))
+//	_, err = users.Update(id, `[{"op": "replace", "path": "/age", "value": 31}]`)
+package collection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/UserLeeZJ/gojson/compat"
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/patch"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// IDField 是保存文档ID的字段，值是字符串
+const IDField = "_id"
+
+// 删除记录和批量记录没有IDField，因此不会和文档混淆
+const (
+	// deleteField 标记文件中的删除记录
+	deleteField = "$delete"
+	// batchField 标记文件中ApplyAll写入的批量记录，一行中保存一次事务更新的所有文档
+	batchField = "$batch"
+	// nextIDField 标记压缩时写入的计数器记录，保存下一个自动ID，使被删除的自动ID在压缩后也不会被重新分配
+	nextIDField = "$nextID"
+)
+
+// fileFormat 是集合文件的格式。版本0是引入版本头之前的文件，记录的格式与版本1相同；
+// 版本2增加了计数器记录，版本1的文件没有这种记录，不需要转换
+var fileFormat = compat.NewFormat("gojson.collection", 2).
+	Register(0, compat.Unchanged).
+	Register(1, compat.Unchanged)
+
+const (
+	// DefaultCompactRatio 是自动压缩时文件记录数与文档数之比的默认阈值
+	DefaultCompactRatio = 2.0
+	// DefaultCompactMinRecords 是自动压缩需要的最少文件记录数的默认值
+	DefaultCompactMinRecords = 1000
+)
+
+// Options 定义持久化集合的选项
+type Options struct {
+	// CompactRatio 是自动压缩的阈值：文件中的记录数超过文档数的CompactRatio倍时压缩。
+	// 为0时使用DefaultCompactRatio，为负数时不自动压缩
+	CompactRatio float64
+	// CompactMinRecords 是自动压缩需要的最少记录数，为0时使用DefaultCompactMinRecords
+	CompactMinRecords int
+	// Perm 是新建文件的权限，为0时使用0644
+	Perm os.FileMode
+	// Sync 为true时每次写入后把文件同步到磁盘再返回，写入更慢，但系统崩溃不会丢失已经返回的写入
+	Sync bool
+}
+
+// Collection 是一组带有ID的JSON文档。
+// 返回给调用方的文档都是副本，修改它们不会影响集合；Collection可以被多个goroutine同时使用
+type Collection struct {
+	mu     sync.RWMutex
+	docs   map[string]*types.JSONObject
+	order  []string
+	nextID int64
+
+	path    string
+	file    *os.File
+	writer  stream.MessageWriter
+	records int
+	version int
+	opts    Options
+}
+
+// New 创建只存在于内存中的集合
+func New() *Collection {
+	return &Collection{docs: make(map[string]*types.JSONObject), nextID: 1}
+}
+
+// Open 打开或创建保存在NDJSON文件中的集合，重放文件中的记录恢复文档。opts为nil时使用默认选项
+func Open(path string, opts *Options) (*Collection, error) {
+	if path == "" {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "文件路径为空")
+	}
+	c := New()
+	c.path = path
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.CompactRatio == 0 {
+		c.opts.CompactRatio = DefaultCompactRatio
+	}
+	if c.opts.CompactMinRecords <= 0 {
+		c.opts.CompactMinRecords = DefaultCompactMinRecords
+	}
+	if c.opts.Perm == 0 {
+		c.opts.Perm = 0644
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, c.opts.Perm)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "打开文件失败").WithPath(path).WithCause(err)
+	}
+	if err := c.repairTail(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "定位文件开头失败").WithPath(path).WithCause(err)
+	}
+	if err := c.replay(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "定位文件末尾失败").WithPath(path).WithCause(err)
+	}
+	c.setFile(file)
+
+	// 新文件和旧版本的文件立即重写，写入当前版本的版本头
+	compact := c.maybeCompact
+	if c.version < fileFormat.Version() {
+		compact = c.compact
+	}
+	if err := compact(); err != nil {
+		c.file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// repairTail 处理文件末尾没有换行符的最后一行。
+// append在整行写入后才返回，因此这样的一行通常是写入时崩溃留下的不完整记录，截断它；
+// 如果它是完整的JSON（例如手工编辑的文件），补上换行符，使之后追加的记录从新的一行开始
+func (c *Collection) repairTail(file *os.File) error {
+	fail := func(message string, err error) error {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, message).WithPath(c.path).WithCause(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return fail("读取文件信息失败", err)
+	}
+
+	// 从文件末尾向前查找最后一个换行符
+	end := info.Size()
+	start := end
+	buf := make([]byte, 4096)
+	for start > 0 {
+		n := int64(len(buf))
+		if n > start {
+			n = start
+		}
+		if _, err := file.ReadAt(buf[:n], start-n); err != nil {
+			return fail("读取文件失败", err)
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			start = start - n + int64(i) + 1
+			break
+		}
+		start -= n
+	}
+	if start == end {
+		return nil
+	}
+
+	tail := make([]byte, end-start)
+	if _, err := file.ReadAt(tail, start); err != nil {
+		return fail("读取文件失败", err)
+	}
+	if json.Valid(tail) {
+		if _, err := file.WriteAt([]byte("\n"), end); err != nil {
+			return fail("写入文件失败", err)
+		}
+		return nil
+	}
+	if err := file.Truncate(start); err != nil {
+		return fail("截断不完整的记录失败", err)
+	}
+	return nil
+}
+
+// replay 按顺序应用文件中的记录，没有版本头的文件是版本0。
+// 写入时不限制记录的大小，因此重放时也不限制，否则写入过大文档的文件就再也无法打开
+func (c *Collection) replay(r io.Reader) error {
+	reader := stream.NewMessageReader(r, stream.FramingNewline, stream.FramingOptions{MaxMessageSize: math.MaxInt})
+	first := true
+	for {
+		data, err := reader.ReadMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "读取集合文件失败").WithPath(c.path).WithCause(err)
+		}
+		if first {
+			first = false
+			if h, ok, err := compat.ReadHeader(data); err == nil && ok {
+				if err := fileFormat.Negotiate(h); err != nil {
+					return jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion, "无法读取集合文件").WithPath(c.path).WithCause(err)
+				}
+				c.version = h.Version
+				continue
+			}
+		}
+		c.records++
+
+		if data, err = fileFormat.Migrate(c.version, data); err != nil {
+			return c.badRecord(err.Error())
+		}
+		value, err := parser.ParseBytesToValue(data)
+		if err != nil {
+			return c.badRecord(err.Error())
+		}
+		record, err := value.AsObject()
+		if err != nil {
+			return c.badRecord("记录不是对象")
+		}
+		if record.Has(IDField) {
+			id, err := documentID(record)
+			if err != nil {
+				return c.badRecord(err.Error())
+			}
+			c.put(id, record)
+			continue
+		}
+		if record.Has(batchField) {
+			if err := c.replayBatch(record.Get(batchField)); err != nil {
+				return err
+			}
+			continue
+		}
+		if record.Has(nextIDField) {
+			next, err := record.Get(nextIDField).AsNumber()
+			if err != nil || next < 1 || next != float64(int64(next)) {
+				return c.badRecord("计数器记录不是正整数")
+			}
+			if int64(next) > c.nextID {
+				c.nextID = int64(next)
+			}
+			continue
+		}
+		deleted := record.Get(deleteField)
+		if !deleted.IsString() {
+			return c.badRecord("记录既不是文档也不是删除记录")
+		}
+		id, _ := deleted.AsString()
+		c.remove(id)
+	}
+}
+
+// badRecord 返回文件中第records条记录无效的错误
+func (c *Collection) badRecord(reason string) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, fmt.Sprintf("第%d条记录无效：%s", c.records, reason)).WithPath(c.path)
+}
+
+// setFile 设置追加记录的文件
+func (c *Collection) setFile(file *os.File) {
+	c.file = file
+	c.writer = stream.NewMessageWriter(file, stream.FramingNewline)
+}
+
+// Insert 插入文档的副本并返回它的ID。
+// 文档没有IDField时自动分配一个递增的数字ID，已有的ID必须是非空字符串并且不能与其他文档重复
+func (c *Collection) Insert(doc *types.JSONObject) (string, error) {
+	if doc == nil {
+		return "", jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "文档为空")
+	}
+	copied, err := utils.DeepCopyChecked(doc)
+	if err != nil {
+		return "", err
+	}
+	stored, _ := copied.AsObject()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var id string
+	if stored.Has(IDField) {
+		if id, err = documentID(stored); err != nil {
+			return "", err
+		}
+		if _, ok := c.docs[id]; ok {
+			return "", jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("ID %q已经存在", id))
+		}
+	} else {
+		id = c.newID()
+		stored.PutString(IDField, id)
+	}
+
+	if err := c.append(stored); err != nil {
+		return "", err
+	}
+	c.put(id, stored)
+	return id, c.maybeCompact()
+}
+
+// newID 返回下一个没有被使用的自动ID
+func (c *Collection) newID() string {
+	for {
+		id := strconv.FormatInt(c.nextID, 10)
+		c.nextID++
+		if _, ok := c.docs[id]; !ok {
+			return id
+		}
+	}
+}
+
+// Get 返回ID对应的文档
+func (c *Collection) Get(id string) (*types.JSONObject, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	doc, ok := c.docs[id]
+	if !ok {
+		return nil, false
+	}
+	return copyDocument(doc), true
+}
+
+// Find 按插入顺序返回JSON Path选择的值中有一个等于value的所有文档，例如Find("$.tags[*]", tag)。
+// 路径以文档为根，在某个文档上没有匹配值或类型不匹配时跳过这个文档
+func (c *Collection) Find(path string, value types.JSONValue) ([]*types.JSONObject, error) {
+	jp, err := jsonpath.CompileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = types.NewJSONNull()
+	}
+	return c.filter(func(doc *types.JSONObject) bool {
+		results, err := jp.Query(doc)
+		if err != nil {
+			return false
+		}
+		for _, result := range results {
+			if types.Equal(result, value) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// FindByExample 按插入顺序返回包含example的所有文档：example中的每个字段都必须出现在文档中，
+// 嵌套对象同样按包含比较，数组和标量必须相等。空的example匹配所有文档
+func (c *Collection) FindByExample(example *types.JSONObject) []*types.JSONObject {
+	return c.filter(func(doc *types.JSONObject) bool {
+		return example == nil || containsExample(doc, example)
+	})
+}
+
+// All 按插入顺序返回所有文档
+func (c *Collection) All() []*types.JSONObject {
+	return c.filter(func(*types.JSONObject) bool { return true })
+}
+
+// filter 按插入顺序返回满足match的文档的副本
+func (c *Collection) filter(match func(*types.JSONObject) bool) []*types.JSONObject {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var result []*types.JSONObject
+	for _, id := range c.order {
+		if doc := c.docs[id]; match(doc) {
+			result = append(result, copyDocument(doc))
+		}
+	}
+	return result
+}
+
+// Update 对ID对应的文档应用JSON Patch并返回更新后的文档。
+// 补丁中任何一个操作失败时文档保持不变；补丁不能删除或修改文档的ID
+func (c *Collection) Update(id, patchJSON string) (*types.JSONObject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	updated, err := c.patchDocument(id, patchJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.append(updated); err != nil {
+		return nil, err
+	}
+	c.docs[id] = updated
+	return copyDocument(updated), c.maybeCompact()
+}
+
+// patchDocument 返回对ID对应的文档应用补丁后的新文档，保存的文档保持不变
+func (c *Collection) patchDocument(id, patchJSON string) (*types.JSONObject, error) {
+	doc, ok := c.docs[id]
+	if !ok {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, fmt.Sprintf("ID %q不存在", id))
+	}
+	patched, err := patch.ApplyPatch(doc, patchJSON)
+	if err != nil {
+		return nil, err
+	}
+	// 保存的文档不能包含循环引用，copyDocument依赖这一点
+	if patched, err = utils.DeepCopyChecked(patched); err != nil {
+		return nil, err
+	}
+	updated, err := patched.AsObject()
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPatchFailed, "更新后的文档不是对象").WithCause(err)
+	}
+	if newID, err := documentID(updated); err != nil || newID != id {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPatchFailed, "补丁不能修改文档的ID")
+	}
+	return updated, nil
+}
+
+// Delete 删除ID对应的文档，返回文档是否存在
+func (c *Collection) Delete(id string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.docs[id]; !ok {
+		return false, nil
+	}
+	if err := c.append(types.NewJSONObject().PutString(deleteField, id)); err != nil {
+		return false, err
+	}
+	c.remove(id)
+	return true, c.maybeCompact()
+}
+
+// Len 返回文档的数量
+func (c *Collection) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.docs)
+}
+
+// Compact 把集合文件重写为每个文档一行，去掉被覆盖的旧版本和删除记录。
+// 新内容先写入临时文件再替换原文件，写入失败时原文件保持不变。内存中的集合不需要压缩
+func (c *Collection) Compact() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.compact()
+}
+
+// Close 关闭集合文件，之后不能再修改集合。内存中的集合不需要关闭
+func (c *Collection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file, c.writer = nil, nil
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "关闭文件失败").WithPath(c.path).WithCause(err)
+	}
+	return nil
+}
+
+// put 保存文档，新文档追加到插入顺序的末尾
+func (c *Collection) put(id string, doc *types.JSONObject) {
+	if _, ok := c.docs[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.docs[id] = doc
+	if n, err := strconv.ParseInt(id, 10, 64); err == nil && n >= c.nextID {
+		c.nextID = n + 1
+	}
+}
+
+// remove 删除文档
+func (c *Collection) remove(id string) {
+	if _, ok := c.docs[id]; !ok {
+		return
+	}
+	delete(c.docs, id)
+	for i, existing := range c.order {
+		if existing == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// append 在集合文件末尾追加一条记录，内存中的集合不做任何事
+func (c *Collection) append(record *types.JSONObject) error {
+	if c.path == "" {
+		return nil
+	}
+	if c.writer == nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "集合已经关闭").WithPath(c.path)
+	}
+	if err := stream.WriteValue(c.writer, record); err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "写入集合文件失败").WithPath(c.path).WithCause(err)
+	}
+	c.records++
+	if c.opts.Sync {
+		if err := c.file.Sync(); err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "同步集合文件失败").WithPath(c.path).WithCause(err)
+		}
+	}
+	return nil
+}
+
+// maybeCompact 在记录数超过阈值时压缩集合文件
+func (c *Collection) maybeCompact() error {
+	if c.path == "" || c.opts.CompactRatio < 0 || c.records < c.opts.CompactMinRecords {
+		return nil
+	}
+	if float64(c.records) <= c.opts.CompactRatio*float64(len(c.docs)) {
+		return nil
+	}
+	return c.compact()
+}
+
+// compact 重写集合文件，调用方需要持有写锁
+func (c *Collection) compact() error {
+	if c.path == "" {
+		return nil
+	}
+	if c.file == nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "集合已经关闭").WithPath(c.path)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "."+filepath.Base(c.path)+".tmp*")
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "创建临时文件失败").WithPath(c.path).WithCause(err)
+	}
+	tmpName := tmp.Name()
+	fail := func(message string, err error) error {
+		tmp.Close()
+		os.Remove(tmpName)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, message).WithPath(c.path).WithCause(err)
+	}
+
+	writer := stream.NewMessageWriter(tmp, stream.FramingNewline)
+	header, err := json.Marshal(fileFormat.Header())
+	if err == nil {
+		err = writer.WriteMessage(header)
+	}
+	if err != nil {
+		return fail("写入文件失败", err)
+	}
+	records := len(c.order)
+	if c.nextID > c.maxNumericID()+1 {
+		// 最大的自动ID已经被删除，保存计数器，否则重新打开时会重新分配它
+		records++
+		if err := stream.WriteValue(writer, types.NewJSONObject().PutNumber(nextIDField, float64(c.nextID))); err != nil {
+			return fail("写入文件失败", err)
+		}
+	}
+	for _, id := range c.order {
+		if err := stream.WriteValue(writer, c.docs[id]); err != nil {
+			return fail("写入文件失败", err)
+		}
+	}
+	if err := tmp.Chmod(c.opts.Perm); err != nil {
+		return fail("设置文件权限失败", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail("写入文件失败", err)
+	}
+	if err := os.Rename(tmpName, c.path); err != nil {
+		return fail("替换文件失败", err)
+	}
+
+	// 临时文件已经成为新的集合文件，继续在它的末尾追加
+	c.file.Close()
+	c.setFile(tmp)
+	c.records = records
+	c.version = fileFormat.Version()
+	return nil
+}
+
+// maxNumericID 返回文档中最大的数字ID，没有数字ID时返回0
+func (c *Collection) maxNumericID() int64 {
+	var max int64
+	for _, id := range c.order {
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil && n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// documentID 返回文档的ID，ID必须是非空字符串
+func documentID(doc *types.JSONObject) (string, error) {
+	value := doc.Get(IDField)
+	id, _ := value.AsString()
+	if !value.IsString() || id == "" {
+		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, IDField+"必须是非空字符串")
+	}
+	return id, nil
+}
+
+// containsExample 检查value是否包含example
+func containsExample(value, example types.JSONValue) bool {
+	exampleObj, ok := example.(*types.JSONObject)
+	if !ok {
+		return types.Equal(value, example)
+	}
+	obj, ok := value.(*types.JSONObject)
+	if !ok {
+		return false
+	}
+	for _, key := range exampleObj.Keys() {
+		if !obj.Has(key) || !containsExample(obj.Get(key), exampleObj.Get(key)) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyDocument 深度复制保存的文档。
+// Insert和patchDocument保存的都是经过DeepCopyChecked检查的副本，重放的文档由解析得到，因此复制不会失败
+func copyDocument(doc *types.JSONObject) *types.JSONObject {
+	copied, _ := utils.DeepCopyChecked(doc)
+	obj, _ := copied.AsObject()
+	return obj
+}
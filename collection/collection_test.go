@@ -0,0 +1,462 @@
+package collection
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// fileHeader 是集合文件第一行的版本头
+const fileHeader = `{"format":"gojson.collection","version":2}` + "\n"
+
+func parseObject(t *testing.T, s string) *types.JSONObject {
+	t.Helper()
+	value, err := parser.ParseToValue(s)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	obj, err := value.AsObject()
+	if err != nil {
+		t.Fatalf("AsObject() error = %v", err)
+	}
+	return obj
+}
+
+func ids(docs []*types.JSONObject) string {
+	var result []string
+	for _, doc := range docs {
+		id, _ := doc.GetString(IDField)
+		result = append(result, id)
+	}
+	return strings.Join(result, ",")
+}
+
+func insertAll(t *testing.T, c *Collection, docs ...string) {
+	t.Helper()
+	for _, doc := range docs {
+		if _, err := c.Insert(parseObject(t, doc)); err != nil {
+			t.Fatalf("Insert(%s) error = %v", doc, err)
+		}
+	}
+}
+
+func TestInsertAndGet(t *testing.T) {
+	c := New()
+	doc := parseObject(t, `{"name": "alice"}`)
+	id, err := c.Insert(doc)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if id != "1" {
+		t.Errorf("Insert() id = %q, want 1", id)
+	}
+	if doc.Has(IDField) {
+		t.Errorf("Insert() modified the caller's document")
+	}
+
+	got, ok := c.Get(id)
+	if !ok || got.String() != `{"_id":"1","name":"alice"}` {
+		t.Fatalf("Get() = %v, %v", got, ok)
+	}
+	got.PutString("name", "mallory")
+	if again, _ := c.Get(id); again.String() != `{"_id":"1","name":"alice"}` {
+		t.Errorf("modifying the returned document changed the collection: %v", again)
+	}
+
+	// 自动ID跳过已经使用的数字ID
+	insertAll(t, c, `{"_id": "2", "name": "bob"}`)
+	if id, _ := c.Insert(parseObject(t, `{}`)); id != "3" {
+		t.Errorf("Insert() id = %q, want 3", id)
+	}
+
+	if _, err := c.Insert(parseObject(t, `{"_id": "2"}`)); err == nil {
+		t.Errorf("Insert() with duplicate id should fail")
+	}
+	if _, err := c.Insert(parseObject(t, `{"_id": 4}`)); err == nil {
+		t.Errorf("Insert() with non-string id should fail")
+	}
+	if c.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", c.Len())
+	}
+}
+
+func TestFind(t *testing.T) {
+	c := New()
+	insertAll(t, c,
+		`{"name": "alice", "age": 30, "tags": ["admin", "dev"], "address": {"city": "Paris", "zip": "75001"}}`,
+		`{"name": "bob", "age": 25, "tags": ["dev"], "address": {"city": "Berlin"}}`,
+		`{"name": "carol", "age": 30, "tags": "ops"}`,
+	)
+
+	tests := []struct {
+		path  string
+		value types.JSONValue
+		want  string
+	}{
+		{"$.age", types.NewJSONNumber(30), "1,3"},
+		{"$.tags[*]", types.NewJSONString("dev"), "1,2"},
+		{"$.address.city", types.NewJSONString("Berlin"), "2"},
+		{"$.missing", types.NewJSONNull(), ""},
+	}
+	for _, tt := range tests {
+		got, err := c.Find(tt.path, tt.value)
+		if err != nil {
+			t.Fatalf("Find(%q) error = %v", tt.path, err)
+		}
+		if ids(got) != tt.want {
+			t.Errorf("Find(%q, %v) = %s, want %s", tt.path, tt.value, ids(got), tt.want)
+		}
+	}
+
+	if _, err := c.Find("age", types.NewJSONNumber(30)); err == nil {
+		t.Errorf("Find() with invalid path should fail")
+	}
+}
+
+func TestFindExactNumbers(t *testing.T) {
+	c := New()
+	big1, _ := types.ParseJSONBigInt("9007199254740993")
+	big2, _ := types.ParseJSONBigInt("9007199254740992")
+	first := types.NewJSONObject()
+	first.Put("n", big1)
+	if _, err := c.Insert(first); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	insertAll(t, c, `{"n": 9007199254740992}`)
+
+	// 超出float64精度的数字不会因为转换为float64而相等
+	tests := []struct {
+		value types.JSONValue
+		want  string
+	}{
+		{big1, "1"},
+		{big2, "2"},
+		{types.NewJSONNumber(9007199254740992), "2"},
+	}
+	for _, tt := range tests {
+		got, err := c.Find("$.n", tt.value)
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		if ids(got) != tt.want {
+			t.Errorf("Find($.n, %v) = %s, want %s", tt.value, ids(got), tt.want)
+		}
+	}
+
+	example := types.NewJSONObject()
+	example.Put("n", big1)
+	if got := c.FindByExample(example); ids(got) != "1" {
+		t.Errorf("FindByExample(%v) = %s, want 1", example, ids(got))
+	}
+}
+
+func TestFindByExample(t *testing.T) {
+	c := New()
+	insertAll(t, c,
+		`{"name": "alice", "age": 30, "tags": ["admin", "dev"], "address": {"city": "Paris", "zip": "75001"}}`,
+		`{"name": "bob", "age": 25, "tags": ["dev"], "address": {"city": "Berlin"}}`,
+		`{"name": "carol", "age": 30, "tags": "ops"}`,
+	)
+
+	tests := []struct {
+		example string
+		want    string
+	}{
+		{`{}`, "1,2,3"},
+		{`{"age": 30}`, "1,3"},
+		{`{"address": {"city": "Paris"}}`, "1"},
+		{`{"tags": ["dev"]}`, "2"},
+		{`{"age": 30, "address": {"city": "Berlin"}}`, ""},
+	}
+	for _, tt := range tests {
+		if got := c.FindByExample(parseObject(t, tt.example)); ids(got) != tt.want {
+			t.Errorf("FindByExample(%s) = %s, want %s", tt.example, ids(got), tt.want)
+		}
+	}
+}
+
+func TestUpdateAndDelete(t *testing.T) {
+	c := New()
+	insertAll(t, c, `{"name": "alice", "age": 30}`, `{"name": "bob"}`)
+
+	got, err := c.Update("1", `[{"op": "replace", "path": "/age", "value": 31}]`)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got.String() != `{"_id":"1","age":31,"name":"alice"}` {
+		t.Errorf("Update() = %v", got)
+	}
+
+	// 失败的补丁不修改文档
+	if _, err := c.Update("1", `[{"op": "replace", "path": "/age", "value": 40}, {"op": "test", "path": "/name", "value": "bob"}]`); err == nil {
+		t.Errorf("Update() with failing test should fail")
+	}
+	if _, err := c.Update("1", `[{"op": "remove", "path": "/_id"}]`); err == nil {
+		t.Errorf("Update() removing the id should fail")
+	}
+	if _, err := c.Update("9", `[]`); err == nil {
+		t.Errorf("Update() of a missing document should fail")
+	}
+	if doc, _ := c.Get("1"); doc.String() != `{"_id":"1","age":31,"name":"alice"}` {
+		t.Errorf("Get() after failed updates = %v", doc)
+	}
+
+	if deleted, err := c.Delete("1"); !deleted || err != nil {
+		t.Fatalf("Delete() = %v, %v", deleted, err)
+	}
+	if deleted, _ := c.Delete("1"); deleted {
+		t.Errorf("Delete() of a missing document = true")
+	}
+	if ids(c.All()) != "2" {
+		t.Errorf("All() = %s, want 2", ids(c.All()))
+	}
+}
+
+func TestOpenPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.ndjson")
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	insertAll(t, c, `{"name": "alice"}`, `{"name": "bob"}`, `{"name": "carol"}`)
+	if _, err := c.Update("2", `[{"op": "add", "path": "/age", "value": 25}]`); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := c.Delete("1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := c.Insert(parseObject(t, `{}`)); err == nil {
+		t.Errorf("Insert() after Close() should fail")
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 6 {
+		t.Errorf("file has %d lines, want a header and 5 records:\n%s", lines, data)
+	}
+
+	c, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	all := c.All()
+	if ids(all) != "2,3" || all[0].String() != `{"_id":"2","age":25,"name":"bob"}` {
+		t.Errorf("All() after reopening = %v", all)
+	}
+	// 删除的ID不会被重新分配
+	if id, _ := c.Insert(parseObject(t, `{}`)); id != "4" {
+		t.Errorf("Insert() id = %q, want 4", id)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.ndjson")
+	c, err := Open(path, &Options{CompactMinRecords: 10})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	insertAll(t, c, `{"_id": "counter", "n": 0}`, `{"_id": "other"}`)
+
+	for i := 0; i < 25; i++ {
+		if _, err := c.Update("counter", `[{"op": "replace", "path": "/n", "value": `+strconv.Itoa(i+1)+`}]`); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+	// 记录数超过10后自动压缩，之后每次更新都追加一条
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines >= 10 {
+		t.Errorf("file has %d records after auto compaction", lines)
+	}
+
+	if err := c.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	want := fileHeader + `{"_id":"counter","n":25}` + "\n" + `{"_id":"other"}` + "\n"
+	if string(data) != want {
+		t.Errorf("file after Compact() = %q, want %q", data, want)
+	}
+
+	// 压缩之后的写入追加到新文件
+	if _, err := c.Delete("other"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	reopened, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer reopened.Close()
+	if ids(reopened.All()) != "counter" {
+		t.Errorf("All() after reopening = %s", ids(reopened.All()))
+	}
+}
+
+func TestOpenInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"not an object":  "[1]\n",
+		"invalid id":     `{"_id": 1}` + "\n",
+		"unknown record": `{"name": "x"}` + "\n",
+		"invalid json":   `{"_id": "1"` + "\n",
+		"torn middle":    `{"_id": "1"` + "\n" + `{"_id": "2"}` + "\n",
+		"invalid nextID": `{"$nextID": 1.5}` + "\n",
+		"newer version":  `{"format":"gojson.collection","version":3}` + "\n" + `{"_id": "1"}` + "\n",
+		"other format":   `{"format":"gojson.aggregator","version":1}` + "\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_"))
+		os.WriteFile(path, []byte(content), 0644)
+		if c, err := Open(path, nil); err == nil {
+			c.Close()
+			t.Errorf("Open(%s) should fail", name)
+		}
+	}
+}
+
+func TestOpenLegacyFile(t *testing.T) {
+	// 引入版本头之前写入的文件在打开时被重写为当前版本
+	path := filepath.Join(t.TempDir(), "legacy.ndjson")
+	os.WriteFile(path, []byte(`{"_id":"1","name":"alice"}`+"\n"+`{"_id":"2"}`+"\n"+`{"$delete":"2"}`+"\n"), 0644)
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	if ids(c.All()) != "1" {
+		t.Errorf("All() = %s, want 1", ids(c.All()))
+	}
+	// 被删除的ID 2保存在计数器记录中
+	data, _ := os.ReadFile(path)
+	if want := fileHeader + `{"$nextID":3}` + "\n" + `{"_id":"1","name":"alice"}` + "\n"; string(data) != want {
+		t.Errorf("file after Open() = %q, want %q", data, want)
+	}
+
+	// 新建的文件同样以版本头开始
+	path = filepath.Join(t.TempDir(), "new.ndjson")
+	c, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	if data, _ := os.ReadFile(path); string(data) != fileHeader {
+		t.Errorf("new file = %q, want %q", data, fileHeader)
+	}
+}
+
+func TestOpenVersion1File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v1.ndjson")
+	os.WriteFile(path, []byte(`{"format":"gojson.collection","version":1}`+"\n"+`{"_id":"1"}`+"\n"), 0644)
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	if data, _ := os.ReadFile(path); string(data) != fileHeader+`{"_id":"1"}`+"\n" {
+		t.Errorf("file after Open() = %q", data)
+	}
+}
+
+func TestOpenTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		ids     string
+	}{
+		// 写入时崩溃留下的不完整记录被丢弃
+		{"torn", fileHeader + `{"_id":"1"}` + "\n" + `{"_id":"2","na`, "1"},
+		{"torn first line", `{"_id":"1"`, ""},
+		// 完整但缺少换行符的记录被保留
+		{"complete", fileHeader + `{"_id":"1"}` + "\n" + `{"_id":"2"}`, "1,2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+			os.WriteFile(path, []byte(tt.content), 0644)
+			c, err := Open(path, nil)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if ids(c.All()) != tt.ids {
+				t.Errorf("All() = %s, want %s", ids(c.All()), tt.ids)
+			}
+			insertAll(t, c, `{"_id": "new"}`)
+			c.Close()
+
+			// 之后的写入从新的一行开始
+			c, err = Open(path, nil)
+			if err != nil {
+				t.Fatalf("Open() after insert error = %v", err)
+			}
+			defer c.Close()
+			if want := strings.TrimPrefix(tt.ids+",new", ","); ids(c.All()) != want {
+				t.Errorf("All() after reopening = %s, want %s", ids(c.All()), want)
+			}
+		})
+	}
+}
+
+func TestAutoIDNotReused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.ndjson")
+	c, err := Open(path, &Options{Sync: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	insertAll(t, c, `{}`, `{}`, `{}`)
+	if _, err := c.Delete("3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	// 压缩去掉了文档3的所有记录
+	if err := c.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	c.Close()
+
+	c, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	if id, _ := c.Insert(parseObject(t, `{}`)); id != "4" {
+		t.Errorf("Insert() id = %q, want 4", id)
+	}
+}
+
+func TestOpenLargeRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.ndjson")
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// 记录超过stream.DefaultMaxMessageSize
+	doc := types.NewJSONObject()
+	doc.PutString("data", strings.Repeat("x", stream.DefaultMaxMessageSize+1))
+	id, err := c.Insert(doc)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	c.Close()
+
+	c, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	got, ok := c.Get(id)
+	if !ok {
+		t.Fatalf("Get(%q) after reopening found nothing", id)
+	}
+	if data, _ := got.GetString("data"); len(data) != stream.DefaultMaxMessageSize+1 {
+		t.Errorf("Get(%q) data length = %d, want %d", id, len(data), stream.DefaultMaxMessageSize+1)
+	}
+}
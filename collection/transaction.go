@@ -0,0 +1,99 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/patch"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ApplyAll 在一个事务中对多个文档应用JSON Patch，返回更新后的文档。
+//
+// 所有补丁都先应用到文档的副本上，只有每个补丁都成功时才一起生效，任何一个失败时所有文档保持不变。
+// 补丁中的test操作因此可以作为整个事务的前置条件，例如先检查余额再修改两个账户：
+//
+//	_, err := accounts.ApplyAll(map[string][]patch.PatchOperation{
+//		"alice": {{Op: "test", Path: "/balance", Value: json.RawMessage(`100`)},
+//			{Op: "replace", Path: "/balance", Value: json.RawMessage(`70`)}},
+//		"bob": {{Op: "replace", Path: "/balance", Value: json.RawMessage(`80`)}},
+//	})
+//
+// 失败时返回的错误说明是哪个文档的补丁失败，并保留原始错误的错误码（例如test失败的ErrTestFailed）。
+// 持久化的集合把所有更新后的文档写成一行，重新打开时事务要么完整生效，要么完全没有生效
+func (c *Collection) ApplyAll(patches map[string][]patch.PatchOperation) (map[string]*types.JSONObject, error) {
+	ids := make([]string, 0, len(patches))
+	for id := range patches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	updated := make([]*types.JSONObject, len(ids))
+	for i, id := range ids {
+		patchJSON, err := json.Marshal(patches[id])
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("序列化文档%q的补丁失败", id)).WithCause(err)
+		}
+		if updated[i], err = c.patchDocument(id, string(patchJSON)); err != nil {
+			return nil, transactionError(id, err)
+		}
+	}
+	if len(ids) == 0 {
+		return map[string]*types.JSONObject{}, nil
+	}
+
+	batch := types.NewJSONArray()
+	for _, doc := range updated {
+		batch.Add(doc)
+	}
+	if err := c.append(types.NewJSONObject().Put(batchField, batch)); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*types.JSONObject, len(ids))
+	for i, id := range ids {
+		c.docs[id] = updated[i]
+		result[id] = copyDocument(updated[i])
+	}
+	return result, c.maybeCompact()
+}
+
+// transactionError 说明事务中哪个文档的补丁失败，保留原始错误的错误码
+func transactionError(id string, err error) error {
+	code := jsonerrors.ErrPatchFailed
+	if jsonErr, ok := err.(*jsonerrors.JSONError); ok {
+		code = jsonErr.Code
+	}
+	return jsonerrors.NewJSONError(code, fmt.Sprintf("文档%q的补丁失败，事务没有生效", id)).WithCause(err)
+}
+
+// replayBatch 应用文件中的一条批量记录，记录中的文档都有效时才一起生效
+func (c *Collection) replayBatch(value types.JSONValue) error {
+	batch, err := value.AsArray()
+	if err != nil {
+		return c.badRecord("批量记录不是数组")
+	}
+	docs := make([]*types.JSONObject, 0, batch.Size())
+	ids := make([]string, 0, batch.Size())
+	for _, element := range batch.Values() {
+		doc, err := element.AsObject()
+		if err != nil {
+			return c.badRecord("批量记录中的文档不是对象")
+		}
+		id, err := documentID(doc)
+		if err != nil {
+			return c.badRecord(err.Error())
+		}
+		docs = append(docs, doc)
+		ids = append(ids, id)
+	}
+	for i, doc := range docs {
+		c.put(ids[i], doc)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package collection
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/patch"
+)
+
+// transfer 返回从from向to转账的补丁，要求from的余额为balance
+func transfer(from, to string, balance, fromAfter, toAfter int) map[string][]patch.PatchOperation {
+	raw := func(n int) json.RawMessage {
+		data, _ := json.Marshal(n)
+		return data
+	}
+	return map[string][]patch.PatchOperation{
+		from: {
+			{Op: "test", Path: "/balance", Value: raw(balance)},
+			{Op: "replace", Path: "/balance", Value: raw(fromAfter)},
+		},
+		to: {
+			{Op: "replace", Path: "/balance", Value: raw(toAfter)},
+		},
+	}
+}
+
+func TestApplyAll(t *testing.T) {
+	c := New()
+	insertAll(t, c, `{"_id": "alice", "balance": 100}`, `{"_id": "bob", "balance": 50}`)
+
+	got, err := c.ApplyAll(transfer("alice", "bob", 100, 70, 80))
+	if err != nil {
+		t.Fatalf("ApplyAll() error = %v", err)
+	}
+	if len(got) != 2 || got["alice"].String() != `{"_id":"alice","balance":70}` || got["bob"].String() != `{"_id":"bob","balance":80}` {
+		t.Errorf("ApplyAll() = %v", got)
+	}
+
+	// 前置条件不再成立，两个文档都不变
+	_, err = c.ApplyAll(transfer("alice", "bob", 100, 40, 110))
+	jsonErr, ok := err.(*jsonerrors.JSONError)
+	if !ok || jsonErr.Code != jsonerrors.ErrTestFailed || !strings.Contains(err.Error(), `"alice"`) {
+		t.Fatalf("ApplyAll() error = %v, want test failure for alice", err)
+	}
+	if all := ids(c.All()); all != "alice,bob" {
+		t.Fatalf("All() = %s", all)
+	}
+	for id, want := range map[string]string{"alice": "70", "bob": "80"} {
+		if doc, _ := c.Get(id); doc.Get("balance").String() != want {
+			t.Errorf("balance of %s = %v, want %s", id, doc.Get("balance"), want)
+		}
+	}
+
+	// 任何一个文档不存在或补丁无效时事务也不生效
+	failing := []map[string][]patch.PatchOperation{
+		{"alice": {{Op: "replace", Path: "/balance", Value: json.RawMessage(`0`)}}, "carol": {}},
+		{"alice": {{Op: "replace", Path: "/balance", Value: json.RawMessage(`0`)}}, "bob": {{Op: "remove", Path: "/missing"}}},
+		{"alice": {{Op: "replace", Path: "/_id", Value: json.RawMessage(`"eve"`)}}},
+	}
+	for _, patches := range failing {
+		if _, err := c.ApplyAll(patches); err == nil {
+			t.Errorf("ApplyAll(%v) should fail", patches)
+		}
+	}
+	if doc, _ := c.Get("alice"); doc.Get("balance").String() != "70" {
+		t.Errorf("balance of alice = %v after failed transactions", doc.Get("balance"))
+	}
+
+	if got, err := c.ApplyAll(nil); err != nil || len(got) != 0 {
+		t.Errorf("ApplyAll(nil) = %v, %v", got, err)
+	}
+}
+
+func TestApplyAllPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.ndjson")
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	insertAll(t, c, `{"_id": "alice", "balance": 100}`, `{"_id": "bob", "balance": 50}`)
+	if _, err := c.ApplyAll(transfer("alice", "bob", 100, 70, 80)); err != nil {
+		t.Fatalf("ApplyAll() error = %v", err)
+	}
+	c.ApplyAll(transfer("alice", "bob", 100, 40, 110))
+	c.Close()
+
+	// 一次事务只写入一行
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 4 {
+		t.Errorf("file has %d lines, want a header and 3 records:\n%s", lines, data)
+	}
+
+	c, err = Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer c.Close()
+	all := c.All()
+	if len(all) != 2 || all[0].String() != `{"_id":"alice","balance":70}` || all[1].String() != `{"_id":"bob","balance":80}` {
+		t.Errorf("All() after reopening = %v", all)
+	}
+
+	if err := c.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if want := fileHeader + `{"_id":"alice","balance":70}` + "\n" + `{"_id":"bob","balance":80}` + "\n"; string(data) != want {
+		t.Errorf("file after Compact() = %q, want %q", data, want)
+	}
+}
+
+func TestOpenInvalidBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.ndjson")
+	os.WriteFile(path, []byte(`{"_id": "a"}`+"\n"+`{"$batch": [{"_id": "a", "n": 1}, {"n": 2}]}`+"\n"), 0644)
+	if c, err := Open(path, nil); err == nil {
+		c.Close()
+		t.Errorf("Open() with an invalid batch should fail")
+	}
+}
@@ -0,0 +1,180 @@
+// Package compat 为gojson保存到磁盘的格式提供版本头和迁移，
+// 使检查点、集合文件等长期保存的数据在升级库之后仍然可以读取。
+//
+// 每种格式用Format描述：名称、当前版本和把旧版本逐步升级的迁移函数。
+// 保存时用Wrap把内容放入带有版本头的信封：
+//
+//	{"format":"gojson.aggregator","version":1,"data":{...}}
+//
+// 读取时Unwrap检查格式名称和版本，并把旧版本的内容依次迁移到当前版本。
+// 没有版本头的内容被视为版本0，即引入版本头之前写入的格式。
+// 比当前版本新的内容会返回ErrUnsupportedVersion错误，而不是按错误的格式解析。
+//
+// 示例：
+//
+//	var format = compat.NewFormat("myapp.cache", 2).
+//		Register(0, compat.Unchanged).
+//		Register(1, renameField)
+//	data, err := format.Wrap(payload)
+//	payload, err = format.Unwrap(data)
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// Header 是格式的版本头
+type Header struct {
+	// Format 是格式的名称，例如"gojson.collection"
+	Format string `json:"format"`
+	// Version 是写入内容时使用的格式版本
+	Version int `json:"version"`
+}
+
+// String 返回"名称@版本"形式的描述
+func (h Header) String() string {
+	return fmt.Sprintf("%s@%d", h.Format, h.Version)
+}
+
+// Migration 把一个版本的内容转换为下一个版本的内容
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// Unchanged 是内容不需要转换的迁移，用于只增加了版本头或只增加了可选字段的版本
+func Unchanged(data json.RawMessage) (json.RawMessage, error) {
+	return data, nil
+}
+
+// Format 描述一种带版本的序列化格式。Register完成后Format可以被多个goroutine同时使用
+type Format struct {
+	name       string
+	version    int
+	migrations map[int]Migration
+}
+
+// NewFormat 创建当前版本为version的格式，version不能为负数
+func NewFormat(name string, version int) *Format {
+	if version < 0 {
+		panic(fmt.Sprintf("compat: 格式%s的版本不能为负数", name))
+	}
+	return &Format{name: name, version: version, migrations: make(map[int]Migration)}
+}
+
+// Register 注册把版本from的内容升级到版本from+1的迁移，返回Format本身以便链式调用
+func (f *Format) Register(from int, migration Migration) *Format {
+	if from < 0 || from >= f.version {
+		panic(fmt.Sprintf("compat: 格式%s不能注册从版本%d开始的迁移", f.name, from))
+	}
+	f.migrations[from] = migration
+	return f
+}
+
+// Name 返回格式的名称
+func (f *Format) Name() string {
+	return f.name
+}
+
+// Version 返回格式的当前版本
+func (f *Format) Version() int {
+	return f.version
+}
+
+// Header 返回当前版本的版本头
+func (f *Format) Header() Header {
+	return Header{Format: f.name, Version: f.version}
+}
+
+// Negotiate 检查版本头描述的内容能否被读取：格式名称必须相同，
+// 版本不能比当前版本新，并且从该版本到当前版本的每一步都有迁移
+func (f *Format) Negotiate(h Header) error {
+	if h.Format != f.name {
+		return jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion, fmt.Sprintf("格式%q不是%q", h.Format, f.name))
+	}
+	if h.Version < 0 {
+		return jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion, fmt.Sprintf("无效的版本%d", h.Version))
+	}
+	if h.Version > f.version {
+		return jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion,
+			fmt.Sprintf("%s比支持的最新版本%d新，需要升级gojson", h, f.version))
+	}
+	for v := h.Version; v < f.version; v++ {
+		if f.migrations[v] == nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion, fmt.Sprintf("不支持从%s@%d迁移", f.name, v))
+		}
+	}
+	return nil
+}
+
+// Migrate 把版本version的内容依次迁移到当前版本
+func (f *Format) Migrate(version int, data json.RawMessage) (json.RawMessage, error) {
+	if err := f.Negotiate(Header{Format: f.name, Version: version}); err != nil {
+		return nil, err
+	}
+	for v := version; v < f.version; v++ {
+		migrated, err := f.migrations[v](data)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion,
+				fmt.Sprintf("从%s@%d迁移失败", f.name, v)).WithCause(err)
+		}
+		data = migrated
+	}
+	return data, nil
+}
+
+// envelope 是Wrap写入的信封
+type envelope struct {
+	Header
+	Data json.RawMessage `json:"data"`
+}
+
+// Wrap 把当前版本的内容放入带有版本头的信封，data必须是合法的JSON
+func (f *Format) Wrap(data []byte) ([]byte, error) {
+	if !json.Valid(data) {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, fmt.Sprintf("%s的内容不是合法的JSON", f.name))
+	}
+	return json.Marshal(envelope{Header: f.Header(), Data: data})
+}
+
+// Unwrap 读取Wrap写入的信封，返回迁移到当前版本的内容。
+// 没有版本头的data被视为版本0的内容
+func (f *Format) Unwrap(data []byte) (json.RawMessage, error) {
+	h, ok, err := ReadHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return f.Migrate(0, data)
+	}
+	if err := f.Negotiate(h); err != nil {
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Data == nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, fmt.Sprintf("%s缺少data", h)).WithCause(err)
+	}
+	return f.Migrate(h.Version, env.Data)
+}
+
+// ReadHeader 读取data开头的版本头。data是同时包含format和version成员的对象时返回true，
+// 其他合法的JSON返回false，表示内容来自引入版本头之前的版本
+func ReadHeader(data []byte) (Header, bool, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		if !json.Valid(data) {
+			return Header{}, false, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "无效的JSON").WithCause(err)
+		}
+		return Header{}, false, nil
+	}
+	format, hasFormat := members["format"]
+	version, hasVersion := members["version"]
+	if !hasFormat || !hasVersion {
+		return Header{}, false, nil
+	}
+	var h Header
+	if json.Unmarshal(format, &h.Format) != nil || json.Unmarshal(version, &h.Version) != nil {
+		return Header{}, false, jsonerrors.NewJSONError(jsonerrors.ErrUnsupportedVersion, "无效的版本头")
+	}
+	return h, true, nil
+}
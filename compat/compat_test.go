@@ -0,0 +1,123 @@
+package compat
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// renameField 把版本1的name字段改名为title
+func renameField(data json.RawMessage) (json.RawMessage, error) {
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	v["title"] = v["name"]
+	delete(v, "name")
+	return json.Marshal(v)
+}
+
+func testFormat() *Format {
+	return NewFormat("test.cache", 2).Register(0, Unchanged).Register(1, renameField)
+}
+
+func isUnsupported(err error) bool {
+	jsonErr, ok := err.(*jsonerrors.JSONError)
+	return ok && jsonErr.Code == jsonerrors.ErrUnsupportedVersion
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	f := testFormat()
+	data, err := f.Wrap([]byte(`{"title":"a"}`))
+	if err != nil {
+		t.Fatalf("Wrap() error = %v", err)
+	}
+	if want := `{"format":"test.cache","version":2,"data":{"title":"a"}}`; string(data) != want {
+		t.Errorf("Wrap() = %s, want %s", data, want)
+	}
+	h, ok, err := ReadHeader(data)
+	if err != nil || !ok || h != f.Header() || h.String() != "test.cache@2" {
+		t.Errorf("ReadHeader() = %v, %v, %v", h, ok, err)
+	}
+
+	tests := map[string]string{
+		"current":   string(data),
+		"version 1": `{"format":"test.cache","version":1,"data":{"name":"a"}}`,
+		"legacy":    `{"name":"a"}`,
+	}
+	for name, input := range tests {
+		got, err := f.Unwrap([]byte(input))
+		if err != nil {
+			t.Errorf("%s: Unwrap() error = %v", name, err)
+			continue
+		}
+		if string(got) != `{"title":"a"}` {
+			t.Errorf("%s: Unwrap() = %s", name, got)
+		}
+	}
+
+	if _, err := f.Wrap([]byte(`{`)); err == nil {
+		t.Errorf("Wrap() of invalid JSON should fail")
+	}
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	f := testFormat()
+	tests := map[string]string{
+		"newer version":  `{"format":"test.cache","version":3,"data":{}}`,
+		"negative":       `{"format":"test.cache","version":-1,"data":{}}`,
+		"other format":   `{"format":"other","version":1,"data":{}}`,
+		"invalid header": `{"format":"test.cache","version":"2","data":{}}`,
+	}
+	for name, input := range tests {
+		if _, err := f.Unwrap([]byte(input)); !isUnsupported(err) {
+			t.Errorf("%s: Unwrap() error = %v, want UNSUPPORTED_VERSION", name, err)
+		}
+	}
+
+	if _, err := f.Unwrap([]byte(`{"format":"test.cache","version":2}`)); err == nil {
+		t.Errorf("Unwrap() without data should fail")
+	}
+	if _, err := f.Unwrap([]byte(`{"format"`)); err == nil {
+		t.Errorf("Unwrap() of invalid JSON should fail")
+	}
+	// 版本1的内容不能迁移时返回迁移错误
+	if _, err := f.Unwrap([]byte(`{"format":"test.cache","version":1,"data":[1]}`)); !isUnsupported(err) {
+		t.Errorf("Unwrap() error = %v, want a migration error", err)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	// 缺少迁移的版本无法读取
+	f := NewFormat("gapped", 3).Register(1, Unchanged).Register(2, Unchanged)
+	if err := f.Negotiate(Header{Format: "gapped", Version: 1}); err != nil {
+		t.Errorf("Negotiate(v1) error = %v", err)
+	}
+	err := f.Negotiate(Header{Format: "gapped", Version: 0})
+	if !isUnsupported(err) || !strings.Contains(err.Error(), "gapped@0") {
+		t.Errorf("Negotiate(v0) error = %v", err)
+	}
+
+	calls := 0
+	failing := NewFormat("failing", 1).Register(0, func(data json.RawMessage) (json.RawMessage, error) {
+		calls++
+		return nil, errors.New("boom")
+	})
+	if _, err := failing.Migrate(0, json.RawMessage(`{}`)); !isUnsupported(err) || calls != 1 {
+		t.Errorf("Migrate() error = %v after %d calls", err, calls)
+	}
+	if got, err := failing.Migrate(1, json.RawMessage(`{}`)); err != nil || string(got) != `{}` || calls != 1 {
+		t.Errorf("Migrate() at the current version = %s, %v", got, err)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	for _, input := range []string{`[1,2]`, `"format"`, `{"format":"x"}`, `{"version":1}`} {
+		if _, ok, err := ReadHeader([]byte(input)); ok || err != nil {
+			t.Errorf("ReadHeader(%s) = %v, %v, want no header", input, ok, err)
+		}
+	}
+}
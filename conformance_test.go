@@ -0,0 +1,154 @@
+package gojson
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/fast"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+// suiteDirEnv 是指向完整的JSONTestSuite中test_parsing目录的环境变量，
+// 设置后除了testdata/JSONTestSuite中收录的用例，还会运行该目录中的全部用例
+const suiteDirEnv = "GOJSON_JSONTESTSUITE_DIR"
+
+// suiteParser 是参与一致性测试的一个解析实现
+type suiteParser struct {
+	name  string
+	parse func(data []byte) error
+	// lexical 为true时实现只做词法分析，不检查数组和对象的结构，
+	// 它接受的n_用例只记录在日志中
+	lexical bool
+}
+
+var suiteParsers = []suiteParser{
+	{name: "parser", parse: func(data []byte) error {
+		_, err := parser.ParseBytesToValue(data)
+		return err
+	}},
+	{name: "fast", parse: func(data []byte) error {
+		var v interface{}
+		return fast.Unmarshal(data, &v)
+	}},
+	{name: "tokenizer", parse: tokenizeAll, lexical: true},
+}
+
+// tokenizeAll 读取所有令牌，返回遇到的第一个错误
+func tokenizeAll(data []byte) error {
+	tokenizer := stream.NewJSONTokenizer(bytes.NewReader(data))
+	for {
+		token := tokenizer.Next()
+		switch token.Type {
+		case stream.TokenEOF:
+			return nil
+		case stream.TokenError:
+			return token.Error
+		}
+	}
+}
+
+// rejectedImplementationDefined 记录各实现拒绝的i_用例，testdata中其他的i_用例都被接受。
+// 结果与记录不一致时测试失败，修改实现后需要同时更新这里和README中的一致性测试一节
+var rejectedImplementationDefined = map[string][]string{
+	"parser": {
+		"i_number_huge_exp.json",
+		"i_number_neg_int_huge_exp.json",
+		"i_number_pos_double_huge_exp.json",
+		"i_number_real_neg_overflow.json",
+		"i_number_real_pos_overflow.json",
+	},
+	"fast": {
+		"i_string_UTF-16LE_with_BOM.json",
+		"i_string_utf16BE_no_BOM.json",
+		"i_string_utf16LE_no_BOM.json",
+		"i_structure_UTF-8_BOM_empty_object.json",
+	},
+	"tokenizer": {
+		"i_number_huge_exp.json",
+		"i_number_neg_int_huge_exp.json",
+		"i_number_pos_double_huge_exp.json",
+		"i_number_real_neg_overflow.json",
+		"i_number_real_pos_overflow.json",
+	},
+}
+
+// TestJSONTestSuite 使用JSONTestSuite的用例检查解析的一致性：
+// y_用例必须被接受，n_用例必须被拒绝，i_用例的结果由实现决定，与rejectedImplementationDefined比较
+func TestJSONTestSuite(t *testing.T) {
+	runJSONTestSuite(t, "testdata/JSONTestSuite", true)
+	if dir := os.Getenv(suiteDirEnv); dir != "" {
+		t.Run("external", func(t *testing.T) {
+			runJSONTestSuite(t, dir, false)
+		})
+	}
+}
+
+// runJSONTestSuite 运行dir中的用例，checkImplementationDefined为false时i_用例的结果只记录在日志中
+func runJSONTestSuite(t *testing.T, dir string, checkImplementationDefined bool) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("在%s中没有找到用例: %v", dir, err)
+	}
+	sort.Strings(files)
+
+	for _, p := range suiteParsers {
+		p := p
+		t.Run(p.name, func(t *testing.T) {
+			rejected := make(map[string]bool)
+			for _, name := range rejectedImplementationDefined[p.name] {
+				rejected[name] = true
+			}
+
+			var lexicalAccepted []string
+			for _, file := range files {
+				name := filepath.Base(file)
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatalf("读取%s失败: %v", name, err)
+				}
+				parseErr := parseSafely(p.parse, data)
+
+				switch {
+				case strings.HasPrefix(name, "y_"):
+					if parseErr != nil {
+						t.Errorf("%s: 应该接受，实际错误 %v", name, parseErr)
+					}
+				case strings.HasPrefix(name, "n_"):
+					if parseErr == nil {
+						if p.lexical {
+							lexicalAccepted = append(lexicalAccepted, name)
+						} else {
+							t.Errorf("%s: 应该拒绝", name)
+						}
+					}
+				case strings.HasPrefix(name, "i_"):
+					accepted := parseErr == nil
+					if !checkImplementationDefined {
+						t.Logf("%s: 接受 = %v", name, accepted)
+					} else if accepted == rejected[name] {
+						t.Errorf("%s: 接受 = %v，与rejectedImplementationDefined的记录不一致", name, accepted)
+					}
+				}
+			}
+			if len(lexicalAccepted) > 0 {
+				t.Logf("只做词法分析，接受了%d个n_用例: %s", len(lexicalAccepted), strings.Join(lexicalAccepted, ", "))
+			}
+		})
+	}
+}
+
+// parseSafely 调用parse，把panic转换为错误，避免一个用例中断整个测试
+func parseSafely(parse func([]byte) error, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return parse(data)
+}
@@ -0,0 +1,468 @@
+// Package datagen 提供gojson库的模拟数据生成功能。
+//
+// 模板是一个JSON文档，其中的字符串可以包含{{函数}}占位符，对象可以使用以下指令：
+//
+//	{"$repeat": 100, "$item": {...}}   生成包含100个元素的数组
+//	{"$oneOf": [a, b, c]}              随机选择其中一个值
+//
+// 例如：
+//
+//	{"users": {"$repeat": 3, "$item": {"id": "{{uuid}}", "name": "{{name}}", "age": "{{int(18,65)}}"}}}
+//
+// 字符串只包含一个占位符时，输出函数返回值的原始类型，因此"{{int(18,65)}}"输出数字。
+// 生成结果直接写入io.Writer，内存占用与数据量无关，适合生成大型测试数据。
+package datagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+// 模板指令
+const (
+	DirectiveRepeat = "$repeat"
+	DirectiveItem   = "$item"
+	DirectiveOneOf  = "$oneOf"
+)
+
+// Options 表示数据生成选项
+type Options struct {
+	// Seed 是随机数种子，相同的种子和模板生成相同的数据
+	Seed int64
+	// Funcs 是自定义的占位符函数，与内置函数同名时覆盖内置函数
+	Funcs map[string]Func
+}
+
+// Context 是占位符函数的调用上下文
+type Context struct {
+	// Rand 是生成器使用的随机数源
+	Rand *rand.Rand
+	// Index 是最内层$repeat中当前元素的索引，从0开始
+	Index int
+	// Seq 是全局序号，每次调用seq函数时递增
+	Seq int64
+}
+
+// Func 是占位符函数，返回string、数字、bool或nil
+type Func func(ctx *Context, args []string) (interface{}, error)
+
+// Generator 是编译后的模板
+type Generator struct {
+	root  node
+	seed  int64
+	funcs map[string]Func
+}
+
+// New 编译模板，opts为nil时使用默认选项
+func New(template []byte, opts *Options) (*Generator, error) {
+	g := &Generator{funcs: make(map[string]Func)}
+	for name, fn := range builtinFuncs {
+		g.funcs[name] = fn
+	}
+	if opts != nil {
+		g.seed = opts.Seed
+		for name, fn := range opts.Funcs {
+			g.funcs[name] = fn
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(template))
+	dec.UseNumber()
+	root, err := g.compile(dec, "$")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "模板包含多余内容")
+	}
+	g.root = root
+	return g, nil
+}
+
+// Generate 根据模板将生成的数据写入w，每次调用都从相同的种子开始
+func (g *Generator) Generate(w io.Writer) error {
+	r := &run{
+		out: stream.NewJSONGenerator(w),
+		ctx: &Context{Rand: rand.New(rand.NewSource(g.seed))},
+	}
+	if err := g.root.write(r); err != nil {
+		return err
+	}
+	return r.out.Flush()
+}
+
+// Generate 编译模板并将生成的数据写入w
+func Generate(w io.Writer, template []byte, opts *Options) error {
+	g, err := New(template, opts)
+	if err != nil {
+		return err
+	}
+	return g.Generate(w)
+}
+
+// run 保存一次生成的状态
+type run struct {
+	out *stream.JSONGenerator
+	ctx *Context
+}
+
+// writeValue 写入函数返回的值
+func (r *run) writeValue(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return r.out.WriteNull()
+	case string:
+		return r.out.WriteString(v)
+	case bool:
+		return r.out.WriteBoolean(v)
+	case int:
+		return r.out.WriteNumber(float64(v))
+	case int64:
+		return r.out.WriteNumber(float64(v))
+	case float64:
+		return r.out.WriteNumber(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrTypeConversion, "无效的数字").WithCause(err)
+		}
+		return r.out.WriteNumber(f)
+	default:
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, fmt.Sprintf("不支持的值类型: %T", value))
+	}
+}
+
+// node 是编译后的模板节点
+type node interface {
+	write(r *run) error
+}
+
+// literalNode 是原样输出的值
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) write(r *run) error {
+	return r.writeValue(n.value)
+}
+
+// objectNode 按模板中的顺序输出属性
+type objectNode struct {
+	keys   []string
+	values []node
+}
+
+func (n *objectNode) write(r *run) error {
+	if err := r.out.BeginObject(); err != nil {
+		return err
+	}
+	for i, key := range n.keys {
+		if err := r.out.WriteProperty(key); err != nil {
+			return err
+		}
+		if err := n.values[i].write(r); err != nil {
+			return err
+		}
+	}
+	return r.out.EndObject()
+}
+
+// arrayNode 输出固定的数组
+type arrayNode struct {
+	items []node
+}
+
+func (n *arrayNode) write(r *run) error {
+	if err := r.out.BeginArray(); err != nil {
+		return err
+	}
+	for _, item := range n.items {
+		if err := item.write(r); err != nil {
+			return err
+		}
+	}
+	return r.out.EndArray()
+}
+
+// repeatNode 将元素模板重复指定次数
+type repeatNode struct {
+	count node
+	item  node
+	path  string
+}
+
+func (n *repeatNode) write(r *run) error {
+	count, err := n.evalCount(r)
+	if err != nil {
+		return err
+	}
+
+	outer := r.ctx.Index
+	defer func() { r.ctx.Index = outer }()
+
+	if err := r.out.BeginArray(); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		r.ctx.Index = i
+		if err := n.item.write(r); err != nil {
+			return err
+		}
+	}
+	return r.out.EndArray()
+}
+
+// evalCount 计算重复次数，次数可以是数字或返回数字的占位符
+func (n *repeatNode) evalCount(r *run) (int, error) {
+	var value interface{}
+	switch c := n.count.(type) {
+	case *literalNode:
+		value = c.value
+	case *templateNode:
+		v, err := c.eval(r)
+		if err != nil {
+			return 0, err
+		}
+		value = v
+	}
+
+	var count int
+	switch v := value.(type) {
+	case json.Number:
+		i, err := strconv.Atoi(v.String())
+		if err != nil {
+			return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "$repeat必须是整数").WithPath(n.path)
+		}
+		count = i
+	case int:
+		count = v
+	case int64:
+		count = int(v)
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "$repeat必须是整数").WithPath(n.path)
+		}
+		count = i
+	default:
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "$repeat必须是整数").WithPath(n.path)
+	}
+	if count < 0 {
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "$repeat不能为负数").WithPath(n.path)
+	}
+	return count, nil
+}
+
+// oneOfNode 随机选择一个候选值
+type oneOfNode struct {
+	choices []node
+}
+
+func (n *oneOfNode) write(r *run) error {
+	return n.choices[r.ctx.Rand.Intn(len(n.choices))].write(r)
+}
+
+// templatePart 是字符串模板的一段，call为nil时是普通文本
+type templatePart struct {
+	text string
+	call *call
+}
+
+// call 是一次占位符函数调用
+type call struct {
+	name string
+	fn   Func
+	args []string
+}
+
+// templateNode 是包含占位符的字符串
+type templateNode struct {
+	parts []templatePart
+}
+
+func (n *templateNode) write(r *run) error {
+	value, err := n.eval(r)
+	if err != nil {
+		return err
+	}
+	return r.writeValue(value)
+}
+
+// eval 计算模板的值，只有一个占位符时保留返回值的类型
+func (n *templateNode) eval(r *run) (interface{}, error) {
+	if len(n.parts) == 1 && n.parts[0].call != nil {
+		return n.parts[0].call.invoke(r.ctx)
+	}
+
+	var sb strings.Builder
+	for _, part := range n.parts {
+		if part.call == nil {
+			sb.WriteString(part.text)
+			continue
+		}
+		value, err := part.call.invoke(r.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			fmt.Fprint(&sb, value)
+		}
+	}
+	return sb.String(), nil
+}
+
+// invoke 调用占位符函数
+func (c *call) invoke(ctx *Context) (interface{}, error) {
+	value, err := c.fn(ctx, c.args)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("调用%s失败", c.name)).WithCause(err)
+	}
+	return value, nil
+}
+
+// compile 从解码器读取一个值并编译为节点
+func (g *Generator) compile(dec *json.Decoder, path string) (node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析模板失败").WithCause(err)
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			items := make([]node, 0)
+			for dec.More() {
+				item, err := g.compile(dec, path+"["+strconv.Itoa(len(items))+"]")
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析模板失败").WithCause(err)
+			}
+			return &arrayNode{items: items}, nil
+		}
+		return g.compileObject(dec, path)
+	case string:
+		return g.compileString(t, path)
+	default:
+		return &literalNode{value: t}, nil
+	}
+}
+
+// compileObject 编译对象，识别$repeat和$oneOf指令
+func (g *Generator) compileObject(dec *json.Decoder, path string) (node, error) {
+	obj := &objectNode{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析模板失败").WithCause(err)
+		}
+		key := tok.(string)
+		value, err := g.compile(dec, path+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		obj.keys = append(obj.keys, key)
+		obj.values = append(obj.values, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析模板失败").WithCause(err)
+	}
+
+	directives := make(map[string]node)
+	for i, key := range obj.keys {
+		if key == DirectiveRepeat || key == DirectiveItem || key == DirectiveOneOf {
+			directives[key] = obj.values[i]
+		}
+	}
+	if len(directives) == 0 {
+		return obj, nil
+	}
+	if len(directives) != len(obj.keys) {
+		return nil, jsonerrors.ErrInvalidPathWithDetails(path, "指令不能与普通属性混用")
+	}
+
+	if choices, ok := directives[DirectiveOneOf]; ok {
+		arr, isArray := choices.(*arrayNode)
+		if len(directives) != 1 || !isArray || len(arr.items) == 0 {
+			return nil, jsonerrors.ErrInvalidPathWithDetails(path, "$oneOf必须是非空数组")
+		}
+		return &oneOfNode{choices: arr.items}, nil
+	}
+
+	count, hasCount := directives[DirectiveRepeat]
+	item, hasItem := directives[DirectiveItem]
+	if !hasCount || !hasItem {
+		return nil, jsonerrors.ErrInvalidPathWithDetails(path, "$repeat和$item必须同时出现")
+	}
+	switch count.(type) {
+	case *literalNode, *templateNode:
+	default:
+		return nil, jsonerrors.ErrInvalidPathWithDetails(path, "$repeat必须是整数")
+	}
+	return &repeatNode{count: count, item: item, path: path}, nil
+}
+
+// compileString 编译字符串，没有占位符时原样输出
+func (g *Generator) compileString(s, path string) (node, error) {
+	if !strings.Contains(s, "{{") {
+		return &literalNode{value: s}, nil
+	}
+
+	var parts []templatePart
+	rest := s
+	for rest != "" {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			parts = append(parts, templatePart{text: rest})
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, jsonerrors.ErrInvalidPathWithDetails(path, "占位符缺少}}")
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{text: rest[:start]})
+		}
+
+		c, err := g.parseCall(strings.TrimSpace(rest[start+2:start+end]), path)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, templatePart{call: c})
+		rest = rest[start+end+2:]
+	}
+	return &templateNode{parts: parts}, nil
+}
+
+// parseCall 解析占位符，格式为 name 或 name(arg1,arg2)
+func (g *Generator) parseCall(expr, path string) (*call, error) {
+	name, args := expr, []string(nil)
+	if open := strings.Index(expr, "("); open >= 0 {
+		if !strings.HasSuffix(expr, ")") {
+			return nil, jsonerrors.ErrInvalidPathWithDetails(path, fmt.Sprintf("占位符%q缺少)", expr))
+		}
+		name = strings.TrimSpace(expr[:open])
+		if inner := strings.TrimSpace(expr[open+1 : len(expr)-1]); inner != "" {
+			for _, arg := range strings.Split(inner, ",") {
+				args = append(args, strings.TrimSpace(arg))
+			}
+		}
+	}
+
+	fn, ok := g.funcs[name]
+	if !ok {
+		return nil, jsonerrors.ErrInvalidPathWithDetails(path, fmt.Sprintf("未知的占位符函数%q", name))
+	}
+	return &call{name: name, fn: fn, args: args}, nil
+}
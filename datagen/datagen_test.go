@@ -0,0 +1,139 @@
+package datagen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestGenerate(t *testing.T) {
+	template := `{
+		"version": 1,
+		"users": {
+			"$repeat": 50,
+			"$item": {
+				"id": "{{uuid}}",
+				"n": "{{index}}",
+				"seq": "{{seq}}",
+				"label": "user-{{index}}",
+				"age": "{{int(18,65)}}",
+				"score": "{{float(0,10)}}",
+				"active": "{{bool}}",
+				"email": "{{email}}",
+				"joined": "{{date}}",
+				"role": {"$oneOf": ["admin", "user", null]},
+				"tags": {"$repeat": "{{int(0,3)}}", "$item": "{{word}}"}
+			}
+		}
+	}`
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, []byte(template), &Options{Seed: 42}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	value, err := parser.ParseToValue(buf.String())
+	if err != nil {
+		t.Fatalf("generated output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	obj, _ := value.AsObject()
+	users, err := obj.GetArray("users")
+	if err != nil || users.Size() != 50 {
+		t.Fatalf("users = %v, %v", users, err)
+	}
+
+	// 键保持模板中的顺序
+	if !strings.HasPrefix(buf.String(), `{"version":1,"users":[{"id":"`) {
+		t.Errorf("output does not keep template key order: %.60s", buf.String())
+	}
+
+	for i := 0; i < users.Size(); i++ {
+		user, _ := users.Get(i).AsObject()
+		if n, _ := user.GetNumber("n"); int(n) != i {
+			t.Errorf("users[%d].n = %v", i, n)
+		}
+		if seq, _ := user.GetNumber("seq"); int(seq) != i+1 {
+			t.Errorf("users[%d].seq = %v", i, seq)
+		}
+		if label, _ := user.GetString("label"); label != fmt.Sprintf("user-%d", i) {
+			t.Errorf("users[%d].label = %q", i, label)
+		}
+		if age, err := user.GetNumber("age"); err != nil || age < 18 || age > 65 {
+			t.Errorf("users[%d].age = %v, %v", i, age, err)
+		}
+		if _, err := user.GetBoolean("active"); err != nil {
+			t.Errorf("users[%d].active is not a boolean", i)
+		}
+		if id, _ := user.GetString("id"); len(id) != 36 {
+			t.Errorf("users[%d].id = %q", i, id)
+		}
+		if tags, err := user.GetArray("tags"); err != nil || tags.Size() > 3 {
+			t.Errorf("users[%d].tags = %v, %v", i, tags, err)
+		}
+	}
+
+	// 相同种子生成相同数据
+	var again bytes.Buffer
+	g, _ := New([]byte(template), &Options{Seed: 42})
+	if err := g.Generate(&again); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if again.String() != buf.String() {
+		t.Errorf("same seed produced different output")
+	}
+}
+
+func TestGenerateCustomFunc(t *testing.T) {
+	opts := &Options{Funcs: map[string]Func{
+		"const": func(ctx *Context, args []string) (interface{}, error) {
+			return strings.Join(args, "+"), nil
+		},
+	}}
+	var buf bytes.Buffer
+	if err := Generate(&buf, []byte(`["{{const(a, b)}}", "x{{const}}y"]`), opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if buf.String() != `["a+b","xy"]` {
+		t.Errorf("Generate() = %s", buf.String())
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	templates := []string{
+		`{"a": "{{unknown}}"}`,
+		`{"a": "{{int(1,2}}"}`,
+		`{"a": "{{int"}`,
+		`{"$repeat": 2}`,
+		`{"$repeat": 2, "$item": 1, "extra": 1}`,
+		`{"$oneOf": []}`,
+		`{"$repeat": "x", "$item": 1}`,
+		`{"$repeat": -1, "$item": 1}`,
+		`{"a": "{{int(5,1)}}"}`,
+		`{"a": 1} 2`,
+	}
+	for _, template := range templates {
+		var buf bytes.Buffer
+		if err := Generate(&buf, []byte(template), nil); err == nil {
+			t.Errorf("Generate(%s) should fail, got %s", template, buf.String())
+		}
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	template := []byte(`{"$repeat": 1000, "$item": {"id": "{{uuid}}", "name": "{{name}}", "age": "{{int(18,65)}}", "email": "{{email}}"}}`)
+	g, err := New(template, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := g.Generate(&buf); err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(buf.Len()))
+	}
+}
@@ -0,0 +1,152 @@
+package datagen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 生成名称和文本使用的词表
+var (
+	firstNames = []string{"James", "Mary", "John", "Linda", "Robert", "Emma", "Michael", "Olivia", "Wei", "Fang", "Hiroshi", "Yuki", "Carlos", "Sofia", "Ahmed", "Fatima"}
+	lastNames  = []string{"Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Wang", "Li", "Zhang", "Tanaka", "Sato", "Silva", "Khan", "Martin", "Muller", "Rossi"}
+	words      = []string{"alpha", "bravo", "cloud", "delta", "echo", "field", "green", "harbor", "iron", "jungle", "kite", "lemon", "maple", "noble", "ocean", "pixel", "quartz", "river", "stone", "tiger"}
+	cities     = []string{"Beijing", "Shanghai", "Tokyo", "London", "Paris", "Berlin", "New York", "Toronto", "Sydney", "Singapore", "Dubai", "Madrid"}
+	domains    = []string{"example.com", "example.org", "example.net"}
+)
+
+// 日期函数生成的时间范围
+var (
+	minTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+)
+
+// builtinFuncs 是内置的占位符函数
+var builtinFuncs = map[string]Func{
+	"uuid": func(ctx *Context, args []string) (interface{}, error) {
+		var b [16]byte
+		ctx.Rand.Read(b[:])
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	},
+	"firstName": func(ctx *Context, args []string) (interface{}, error) {
+		return pick(ctx, firstNames), nil
+	},
+	"lastName": func(ctx *Context, args []string) (interface{}, error) {
+		return pick(ctx, lastNames), nil
+	},
+	"name": func(ctx *Context, args []string) (interface{}, error) {
+		return pick(ctx, firstNames) + " " + pick(ctx, lastNames), nil
+	},
+	"email": func(ctx *Context, args []string) (interface{}, error) {
+		user := strings.ToLower(pick(ctx, firstNames) + "." + pick(ctx, lastNames))
+		return fmt.Sprintf("%s%d@%s", user, ctx.Rand.Intn(100), pick(ctx, domains)), nil
+	},
+	"word": func(ctx *Context, args []string) (interface{}, error) {
+		return pick(ctx, words), nil
+	},
+	"sentence": func(ctx *Context, args []string) (interface{}, error) {
+		n := 4 + ctx.Rand.Intn(6)
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = pick(ctx, words)
+		}
+		sentence := strings.Join(parts, " ")
+		return strings.ToUpper(sentence[:1]) + sentence[1:] + ".", nil
+	},
+	"city": func(ctx *Context, args []string) (interface{}, error) {
+		return pick(ctx, cities), nil
+	},
+	"int": func(ctx *Context, args []string) (interface{}, error) {
+		min, max, err := intRange(args, 0, 1000)
+		if err != nil {
+			return nil, err
+		}
+		return min + ctx.Rand.Int63n(max-min+1), nil
+	},
+	"float": func(ctx *Context, args []string) (interface{}, error) {
+		min, max, err := floatRange(args, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		return min + ctx.Rand.Float64()*(max-min), nil
+	},
+	"bool": func(ctx *Context, args []string) (interface{}, error) {
+		return ctx.Rand.Intn(2) == 1, nil
+	},
+	"date": func(ctx *Context, args []string) (interface{}, error) {
+		return randomTime(ctx).Format("2006-01-02"), nil
+	},
+	"datetime": func(ctx *Context, args []string) (interface{}, error) {
+		return randomTime(ctx).Format(time.RFC3339), nil
+	},
+	"choice": func(ctx *Context, args []string) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("choice至少需要一个参数")
+		}
+		return pick(ctx, args), nil
+	},
+	"index": func(ctx *Context, args []string) (interface{}, error) {
+		return ctx.Index, nil
+	},
+	"seq": func(ctx *Context, args []string) (interface{}, error) {
+		ctx.Seq++
+		return ctx.Seq, nil
+	},
+}
+
+// pick 随机选择一个元素
+func pick(ctx *Context, items []string) string {
+	return items[ctx.Rand.Intn(len(items))]
+}
+
+// randomTime 返回范围内的随机时间
+func randomTime(ctx *Context) time.Time {
+	return time.Unix(minTime+ctx.Rand.Int63n(maxTime-minTime), 0).UTC()
+}
+
+// intRange 解析整数范围参数
+func intRange(args []string, defMin, defMax int64) (int64, int64, error) {
+	if len(args) == 0 {
+		return defMin, defMax, nil
+	}
+	if len(args) != 2 {
+		return 0, 0, fmt.Errorf("需要两个参数(min,max)")
+	}
+	min, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("min不能大于max")
+	}
+	return min, max, nil
+}
+
+// floatRange 解析浮点数范围参数
+func floatRange(args []string, defMin, defMax float64) (float64, float64, error) {
+	if len(args) == 0 {
+		return defMin, defMax, nil
+	}
+	if len(args) != 2 {
+		return 0, 0, fmt.Errorf("需要两个参数(min,max)")
+	}
+	min, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("min不能大于max")
+	}
+	return min, max, nil
+}
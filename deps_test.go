@@ -0,0 +1,55 @@
+package gojson
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// forbiddenDeps 是根包在任何构建标签下都不能链接的标准库包，网络相关的代码只属于命令行工具
+var forbiddenDeps = []string{"net", "net/http", "crypto/tls"}
+
+// listDeps 返回根包在指定构建标签下依赖的所有包及其直接导入的包
+func listDeps(t *testing.T, tags string) map[string][]string {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("找不到go命令")
+	}
+	out, err := exec.Command(goTool, "list", "-tags", tags, "-deps",
+		"-f", "{{.ImportPath}} {{if not .Standard}}{{join .Imports \",\"}}{{end}}", ".").Output()
+	if err != nil {
+		t.Fatalf("go list -tags %q -deps 失败: %v", tags, err)
+	}
+	deps := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		path, imports, _ := strings.Cut(line, " ")
+		deps[path] = nil
+		if imports != "" {
+			deps[path] = strings.Split(imports, ",")
+		}
+	}
+	return deps
+}
+
+func TestMinimalBuildDeps(t *testing.T) {
+	for _, tags := range []string{"", "gojson_nofast,gojson_nostream,gojson_nounsafe"} {
+		deps := listDeps(t, tags)
+		for _, pkg := range forbiddenDeps {
+			if _, ok := deps[pkg]; ok {
+				t.Errorf("构建标签%q下根包依赖了%s", tags, pkg)
+			}
+		}
+		if tags == "" {
+			continue
+		}
+		// 标准库内部使用unsafe，这里只检查本模块的包
+		for pkg, imports := range deps {
+			for _, imp := range imports {
+				if imp == "unsafe" {
+					t.Errorf("构建标签%q下%s导入了unsafe", tags, pkg)
+				}
+			}
+		}
+	}
+}
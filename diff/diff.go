@@ -9,6 +9,7 @@ import (
 
 	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
 )
 
 // DiffType 表示差异的类型
@@ -30,6 +31,21 @@ type DiffOptions struct {
 	IgnoreOrder      bool // 忽略数组顺序
 	IncludeSame      bool // 包含相同的值
 	MaxDepth         int  // 最大递归深度，0表示无限制
+
+	// TextDiff 不为TextDiffNone时，为修改了的长字符串计算字符串内部的差异，保存在Diff.TextEdits中
+	TextDiff TextDiffMode
+	// MinTextLength 是计算字符串内部差异的最小长度，新旧字符串都比它短并且都只有一行时不计算，
+	// 为0时使用DefaultMinTextLength
+	MinTextLength int
+
+	// ArrayKeys 将数组路径模式映射到元素的标识键，例如 "$.users" → "id"
+	// 匹配的对象数组按标识键而不是索引对应元素，模式中的*匹配一个路径段内的任意字符，
+	// 例如 "$.groups[*].members"。多个模式匹配同一个数组时使用最具体的模式，
+	// 即*最少的模式，*的数量相同时使用较长的模式
+	ArrayKeys map[string]string
+
+	// arrayKeys 是DiffJSON按优先顺序编译的ArrayKeys
+	arrayKeys []arrayKeyPattern
 }
 
 // DefaultDiffOptions 返回默认的比较选项
@@ -49,6 +65,19 @@ type Diff struct {
 	Path     string          // 差异路径
 	OldValue types.JSONValue // 旧值
 	NewValue types.JSONValue // 新值
+
+	// PathSegments 是结构化的差异路径，根节点为空切片
+	// 手动构造的Diff可以不设置，此时Pointer会解析Path
+	PathSegments []Segment
+
+	// From 和 FromSegments 是DiffMoved差异中元素移动前的路径
+	From         string
+	FromSegments []Segment
+
+	// TextEdits 是DiffModified差异中新旧字符串内部的差异，只在设置了DiffOptions.TextDiff时计算
+	TextEdits []TextEdit
+	// TextDiff 是TextEdits的粒度
+	TextDiff TextDiffMode
 }
 
 // String 返回差异的字符串表示
@@ -59,11 +88,17 @@ func (d *Diff) String() string {
 	case DiffRemoved:
 		return fmt.Sprintf("移除: %s = %s", d.Path, d.OldValue.String())
 	case DiffModified:
+		if len(d.TextEdits) > 0 {
+			if d.TextDiff == TextDiffWord {
+				return fmt.Sprintf("修改: %s = %s", d.Path, FormatTextEdits(d.TextEdits, d.TextDiff))
+			}
+			return fmt.Sprintf("修改: %s\n%s", d.Path, FormatTextEdits(d.TextEdits, d.TextDiff))
+		}
 		return fmt.Sprintf("修改: %s = %s -> %s", d.Path, d.OldValue.String(), d.NewValue.String())
 	case DiffSame:
 		return fmt.Sprintf("相同: %s = %s", d.Path, d.OldValue.String())
 	case DiffMoved:
-		return fmt.Sprintf("移动: %s", d.Path)
+		return fmt.Sprintf("移动: %s -> %s", d.From, d.Path)
 	case DiffTypeChanged:
 		return fmt.Sprintf("类型改变: %s = %s -> %s", d.Path, d.OldValue.Type(), d.NewValue.Type())
 	default:
@@ -76,9 +111,15 @@ func DiffJSON(oldValue, newValue types.JSONValue, options *DiffOptions) ([]*Diff
 	if options == nil {
 		options = DefaultDiffOptions()
 	}
+	if len(options.ArrayKeys) > 0 {
+		// 复制选项，不修改调用者的DiffOptions
+		compiled := *options
+		compiled.arrayKeys = compileArrayKeys(options.ArrayKeys)
+		options = &compiled
+	}
 
 	diffs := make([]*Diff, 0)
-	diffValues("$", oldValue, newValue, options, &diffs, 0)
+	diffValues(rootLocation(), oldValue, newValue, options, &diffs, 0)
 	return diffs, nil
 }
 
@@ -97,8 +138,53 @@ func DiffJSONStrings(oldJSON, newJSON string, options *DiffOptions) ([]*Diff, er
 	return DiffJSON(oldValue, newValue, options)
 }
 
+// Segment 表示差异路径中的一个段，是对象键或数组索引
+type Segment = types.PathSegment
+
+// location 表示差异在文档中的位置
+// 同时保存JSON Path字符串和结构化的路径段，生成JSON Patch时使用路径段，避免重新解析字符串
+type location struct {
+	path     string
+	segments []Segment
+}
+
+// rootLocation 返回根节点的位置
+func rootLocation() location {
+	return location{path: "$", segments: []Segment{}}
+}
+
+// child 返回追加了一个段的新位置
+func (l location) child(segment Segment) location {
+	segments := make([]Segment, len(l.segments), len(l.segments)+1)
+	copy(segments, l.segments)
+	segments = append(segments, segment)
+
+	return location{path: types.ChildPath(l.path, segment), segments: segments}
+}
+
+// key 返回对象键对应的子位置
+func (l location) key(key string) location {
+	return l.child(types.KeySegment(key))
+}
+
+// index 返回数组索引对应的子位置
+func (l location) index(index int) location {
+	return l.child(types.IndexSegment(index))
+}
+
+// addDiff 在指定位置记录一个差异
+func addDiff(diffs *[]*Diff, diffType DiffType, loc location, oldValue, newValue types.JSONValue) {
+	*diffs = append(*diffs, &Diff{
+		Type:         diffType,
+		Path:         loc.path,
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		PathSegments: loc.segments,
+	})
+}
+
 // 递归比较两个JSON值的差异
-func diffValues(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
+func diffValues(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
 	// 检查最大递归深度
 	if options.MaxDepth > 0 && depth > options.MaxDepth {
 		return
@@ -107,114 +193,63 @@ func diffValues(path string, oldValue, newValue types.JSONValue, options *DiffOp
 	// 处理null值
 	if oldValue.IsNull() && newValue.IsNull() {
 		if options.IncludeSame {
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffSame,
-				Path:     path,
-				OldValue: oldValue,
-				NewValue: newValue,
-			})
+			addDiff(diffs, DiffSame, loc, oldValue, newValue)
 		}
 		return
 	}
 
-	if oldValue.IsNull() {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffAdded,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
-		return
-	}
-
-	if newValue.IsNull() {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffRemoved,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
-		return
-	}
-
-	// 处理类型不同的情况
+	// 处理类型不同的情况，包括null与非null值之间的变化
 	if oldValue.Type() != newValue.Type() {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffTypeChanged,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
+		addDiff(diffs, DiffTypeChanged, loc, oldValue, newValue)
 		return
 	}
 
 	// 根据类型进行比较
 	switch oldValue.Type() {
 	case "boolean":
-		diffBooleans(path, oldValue, newValue, options, diffs)
+		diffBooleans(loc, oldValue, newValue, options, diffs)
 	case "number":
-		diffNumbers(path, oldValue, newValue, options, diffs)
+		diffNumbers(loc, oldValue, newValue, options, diffs)
 	case "string":
-		diffStrings(path, oldValue, newValue, options, diffs)
+		diffStrings(loc, oldValue, newValue, options, diffs)
 	case "array":
-		diffArrays(path, oldValue, newValue, options, diffs, depth)
+		diffArrays(loc, oldValue, newValue, options, diffs, depth)
 	case "object":
-		diffObjects(path, oldValue, newValue, options, diffs, depth)
+		diffObjects(loc, oldValue, newValue, options, diffs, depth)
 	}
 }
 
 // 比较布尔值
-func diffBooleans(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
+func diffBooleans(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
 	oldBool, _ := oldValue.AsBoolean()
 	newBool, _ := newValue.AsBoolean()
 
 	if oldBool == newBool {
 		if options.IncludeSame {
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffSame,
-				Path:     path,
-				OldValue: oldValue,
-				NewValue: newValue,
-			})
+			addDiff(diffs, DiffSame, loc, oldValue, newValue)
 		}
 	} else {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffModified,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
+		addDiff(diffs, DiffModified, loc, oldValue, newValue)
 	}
 }
 
 // 比较数字
-func diffNumbers(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
-	oldNum, _ := oldValue.AsNumber()
-	newNum, _ := newValue.AsNumber()
-
-	if oldNum == newNum {
+func diffNumbers(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
+	// JSONBigInt和JSONDecimal精确比较，能发现超出float64精度的修改
+	if types.NumbersEqual(oldValue, newValue) {
 		if options.IncludeSame {
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffSame,
-				Path:     path,
-				OldValue: oldValue,
-				NewValue: newValue,
-			})
+			addDiff(diffs, DiffSame, loc, oldValue, newValue)
 		}
 	} else {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffModified,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
+		addDiff(diffs, DiffModified, loc, oldValue, newValue)
 	}
 }
 
 // 比较字符串
-func diffStrings(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
+func diffStrings(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff) {
 	oldStr, _ := oldValue.AsString()
 	newStr, _ := newValue.AsString()
+	oldText, newText := oldStr, newStr
 
 	// 应用选项
 	if options.IgnoreCase {
@@ -229,23 +264,27 @@ func diffStrings(path string, oldValue, newValue types.JSONValue, options *DiffO
 
 	if oldStr == newStr {
 		if options.IncludeSame {
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffSame,
-				Path:     path,
-				OldValue: oldValue,
-				NewValue: newValue,
-			})
+			addDiff(diffs, DiffSame, loc, oldValue, newValue)
 		}
 	} else {
-		*diffs = append(*diffs, &Diff{
-			Type:     DiffModified,
-			Path:     path,
-			OldValue: oldValue,
-			NewValue: newValue,
-		})
+		addDiff(diffs, DiffModified, loc, oldValue, newValue)
+		if options.TextDiff != TextDiffNone && isLongText(oldText, newText, options.MinTextLength) {
+			d := (*diffs)[len(*diffs)-1]
+			d.TextEdits = DiffText(oldText, newText, options.TextDiff)
+			d.TextDiff = options.TextDiff
+		}
 	}
 }
 
+// isLongText 检查两个字符串是否需要计算字符串内部的差异
+func isLongText(oldText, newText string, minLength int) bool {
+	if minLength <= 0 {
+		minLength = DefaultMinTextLength
+	}
+	return len(oldText) >= minLength || len(newText) >= minLength ||
+		strings.Contains(oldText, "\n") || strings.Contains(newText, "\n")
+}
+
 // 移除字符串中的空白字符
 func removeWhitespace(s string) string {
 	re := regexp.MustCompile(`\s+`)
@@ -253,62 +292,190 @@ func removeWhitespace(s string) string {
 }
 
 // 比较数组
-func diffArrays(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
+func diffArrays(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
 	oldArr, _ := oldValue.AsArray()
 	newArr, _ := newValue.AsArray()
 
-	if options.IgnoreOrder {
+	if key, ok := arrayKeyFor(loc.path, options.arrayKeys); ok {
+		// 配置了标识键时，按标识键对应元素
+		diffArraysByKey(loc, key, oldArr, newArr, options, diffs, depth)
+	} else if options.IgnoreOrder {
 		// 忽略顺序时，将数组视为集合进行比较
-		diffArraysAsSet(path, oldArr, newArr, options, diffs, depth)
+		diffArraysAsSet(loc, oldArr, newArr, options, diffs, depth)
 	} else {
 		// 保持顺序时，按索引比较
-		diffArraysInOrder(path, oldArr, newArr, options, diffs, depth)
+		diffArraysInOrder(loc, oldArr, newArr, options, diffs, depth)
 	}
 }
 
 // 按顺序比较数组
-func diffArraysInOrder(path string, oldArr, newArr *types.JSONArray, options *DiffOptions, diffs *[]*Diff, depth int) {
-	maxLen := oldArr.Size()
-	if newArr.Size() > maxLen {
-		maxLen = newArr.Size()
+func diffArraysInOrder(loc location, oldArr, newArr *types.JSONArray, options *DiffOptions, diffs *[]*Diff, depth int) {
+	minLen := oldArr.Size()
+	if newArr.Size() < minLen {
+		minLen = newArr.Size()
 	}
 
-	for i := 0; i < maxLen; i++ {
-		itemPath := fmt.Sprintf("%s[%d]", path, i)
+	// 比较相同位置的元素
+	for i := 0; i < minLen; i++ {
+		diffValues(loc.index(i), oldArr.Get(i), newArr.Get(i), options, diffs, depth+1)
+	}
 
-		if i >= oldArr.Size() {
-			// 新数组中添加的元素
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffAdded,
-				Path:     itemPath,
-				OldValue: types.NewJSONNull(),
-				NewValue: newArr.Get(i),
-			})
-		} else if i >= newArr.Size() {
-			// 旧数组中移除的元素
+	// 新数组中添加的元素
+	for i := minLen; i < newArr.Size(); i++ {
+		addDiff(diffs, DiffAdded, loc.index(i), types.NewJSONNull(), newArr.Get(i))
+	}
+
+	// 旧数组中移除的元素，从末尾开始记录，使生成的补丁按顺序应用时索引仍然有效
+	for i := oldArr.Size() - 1; i >= minLen; i-- {
+		addDiff(diffs, DiffRemoved, loc.index(i), oldArr.Get(i), types.NewJSONNull())
+	}
+}
+
+// 按标识键比较对象数组
+// 生成的差异按顺序应用时有效：先从末尾开始移除旧元素，再按新数组的顺序依次添加或移动元素，
+// 最后在元素的新位置比较其内容
+func diffArraysByKey(loc location, key string, oldArr, newArr *types.JSONArray, options *DiffOptions, diffs *[]*Diff, depth int) {
+	// 建立旧数组中标识键到索引的映射，重复的标识键只取第一个
+	oldIndex := make(map[string]int)
+	for i := 0; i < oldArr.Size(); i++ {
+		if id, ok := elementKey(oldArr.Get(i), key); ok {
+			if _, exists := oldIndex[id]; !exists {
+				oldIndex[id] = i
+			}
+		}
+	}
+
+	// 为新数组中的元素找到对应的旧元素
+	matches := make([]int, newArr.Size())
+	matched := make(map[int]bool)
+	for j := 0; j < newArr.Size(); j++ {
+		matches[j] = -1
+		if id, ok := elementKey(newArr.Get(j), key); ok {
+			if i, exists := oldIndex[id]; exists && !matched[i] {
+				matches[j] = i
+				matched[i] = true
+			}
+		}
+	}
+
+	// 旧数组中没有对应元素的视为移除，从末尾开始记录
+	for i := oldArr.Size() - 1; i >= 0; i-- {
+		if !matched[i] {
+			addDiff(diffs, DiffRemoved, loc.index(i), oldArr.Get(i), types.NewJSONNull())
+		}
+	}
+
+	// state 模拟应用差异过程中数组的内容，保存旧元素的索引，新添加的元素为-1
+	state := make([]int, 0, newArr.Size())
+	for i := 0; i < oldArr.Size(); i++ {
+		if matched[i] {
+			state = append(state, i)
+		}
+	}
+
+	for j := 0; j < newArr.Size(); j++ {
+		i := matches[j]
+		if i == -1 {
+			addDiff(diffs, DiffAdded, loc.index(j), types.NewJSONNull(), newArr.Get(j))
+			state = append(state[:j], append([]int{-1}, state[j:]...)...)
+			continue
+		}
+
+		// 元素不在当前位置时，将其移动过来
+		p := j
+		for state[p] != i {
+			p++
+		}
+		if p != j {
+			from := loc.index(p)
+			to := loc.index(j)
 			*diffs = append(*diffs, &Diff{
-				Type:     DiffRemoved,
-				Path:     itemPath,
-				OldValue: oldArr.Get(i),
-				NewValue: types.NewJSONNull(),
+				Type:         DiffMoved,
+				Path:         to.path,
+				OldValue:     oldArr.Get(i),
+				NewValue:     oldArr.Get(i),
+				PathSegments: to.segments,
+				From:         from.path,
+				FromSegments: from.segments,
 			})
-		} else {
-			// 比较相同位置的元素
-			diffValues(itemPath, oldArr.Get(i), newArr.Get(i), options, diffs, depth+1)
+			copy(state[j+1:p+1], state[j:p])
+			state[j] = i
+		}
+
+		diffValues(loc.index(j), oldArr.Get(i), newArr.Get(j), options, diffs, depth+1)
+	}
+}
+
+// elementKey 返回数组元素标识键的值
+func elementKey(value types.JSONValue, key string) (string, bool) {
+	if !value.IsObject() {
+		return "", false
+	}
+	obj, _ := value.AsObject()
+	if !obj.Has(key) {
+		return "", false
+	}
+	return obj.Get(key).String(), true
+}
+
+// arrayKeyPattern 是编译后的ArrayKeys模式
+type arrayKeyPattern struct {
+	pattern string
+	key     string
+	re      *regexp.Regexp // 模式不包含*时为nil
+}
+
+// compileArrayKeys 编译ArrayKeys中的模式，按优先顺序排列：*少的在前，其次是较长的，最后按字典序
+func compileArrayKeys(arrayKeys map[string]string) []arrayKeyPattern {
+	patterns := make([]arrayKeyPattern, 0, len(arrayKeys))
+	for pattern, key := range arrayKeys {
+		patterns = append(patterns, arrayKeyPattern{pattern: pattern, key: key, re: compilePathPattern(pattern)})
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := patterns[i].pattern, patterns[j].pattern
+		if na, nb := strings.Count(a, "*"), strings.Count(b, "*"); na != nb {
+			return na < nb
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return patterns
+}
+
+// arrayKeyFor 查找与数组路径匹配的标识键，使用第一个匹配的模式
+func arrayKeyFor(path string, patterns []arrayKeyPattern) (string, bool) {
+	for _, p := range patterns {
+		if p.re == nil && p.pattern == path || p.re != nil && p.re.MatchString(path) {
+			return p.key, true
 		}
 	}
+	return "", false
+}
+
+// compilePathPattern 把模式编译为正则表达式，*匹配一个路径段内的任意字符，模式不包含*时返回nil
+func compilePathPattern(pattern string) *regexp.Regexp {
+	if !strings.Contains(pattern, "*") {
+		return nil
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, `[^.\[\]]*`) + "$")
 }
 
 // 将数组视为集合进行比较
-func diffArraysAsSet(path string, oldArr, newArr *types.JSONArray, options *DiffOptions, diffs *[]*Diff, depth int) {
+func diffArraysAsSet(loc location, oldArr, newArr *types.JSONArray, options *DiffOptions, diffs *[]*Diff, depth int) {
 	// TODO: 实现将数组视为集合的比较逻辑
 	// 这需要一个复杂的算法来匹配最相似的元素
 	// 简化起见，这里仍然使用按顺序比较
-	diffArraysInOrder(path, oldArr, newArr, options, diffs, depth)
+	diffArraysInOrder(loc, oldArr, newArr, options, diffs, depth)
 }
 
 // 比较对象
-func diffObjects(path string, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
+func diffObjects(loc location, oldValue, newValue types.JSONValue, options *DiffOptions, diffs *[]*Diff, depth int) {
 	oldObj, _ := oldValue.AsObject()
 	newObj, _ := newValue.AsObject()
 
@@ -319,40 +486,20 @@ func diffObjects(path string, oldValue, newValue types.JSONValue, options *DiffO
 
 	// 比较每个键
 	for _, key := range allKeys {
-		propPath := path
-		if path == "$" {
-			propPath = "$." + key
-		} else {
-			// 处理键中的特殊字符
-			if isValidIdentifier(key) {
-				propPath = path + "." + key
-			} else {
-				propPath = path + "['" + key + "']"
-			}
-		}
+		propLoc := loc.key(key)
 
 		oldHas := oldObj.Has(key)
 		newHas := newObj.Has(key)
 
 		if oldHas && newHas {
 			// 两个对象都有该键，比较值
-			diffValues(propPath, oldObj.Get(key), newObj.Get(key), options, diffs, depth+1)
+			diffValues(propLoc, oldObj.Get(key), newObj.Get(key), options, diffs, depth+1)
 		} else if oldHas {
 			// 只有旧对象有该键，表示移除
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffRemoved,
-				Path:     propPath,
-				OldValue: oldObj.Get(key),
-				NewValue: types.NewJSONNull(),
-			})
+			addDiff(diffs, DiffRemoved, propLoc, oldObj.Get(key), types.NewJSONNull())
 		} else {
 			// 只有新对象有该键，表示添加
-			*diffs = append(*diffs, &Diff{
-				Type:     DiffAdded,
-				Path:     propPath,
-				OldValue: types.NewJSONNull(),
-				NewValue: newObj.Get(key),
-			})
+			addDiff(diffs, DiffAdded, propLoc, types.NewJSONNull(), newObj.Get(key))
 		}
 	}
 }
@@ -380,80 +527,90 @@ func mergeKeys(a, b []string) []string {
 	return result
 }
 
-// 检查字符串是否为有效的标识符
-func isValidIdentifier(s string) bool {
-	if len(s) == 0 {
-		return false
-	}
-	if !isLetter(s[0]) && s[0] != '_' {
-		return false
-	}
-	for i := 1; i < len(s); i++ {
-		if !isLetter(s[i]) && !isDigit(s[i]) && s[i] != '_' {
-			return false
-		}
+// GeneratePatch 从差异生成JSON Patch
+// 补丁中的值是差异中新值的副本，修改补丁不会影响参与比较的文档。
+// 新值包含循环引用时会panic，不能确定输入是否安全时使用GeneratePatchChecked
+func GeneratePatch(diffs []*Diff) *types.JSONArray {
+	ops, err := GeneratePatchChecked(diffs)
+	if err != nil {
+		panic(err)
 	}
-	return true
-}
-
-// 检查字符是否为字母
-func isLetter(c byte) bool {
-	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	return ops
 }
 
-// 检查字符是否为数字
-func isDigit(c byte) bool {
-	return c >= '0' && c <= '9'
-}
-
-// GeneratePatch 从差异生成JSON Patch
-func GeneratePatch(diffs []*Diff) *types.JSONArray {
-	patch := types.NewJSONArray()
+// GeneratePatchChecked 从差异生成JSON Patch，新值包含循环引用时返回错误
+func GeneratePatchChecked(diffs []*Diff) (*types.JSONArray, error) {
+	ops := types.NewJSONArray()
 
 	for _, d := range diffs {
 		switch d.Type {
 		case DiffAdded:
+			value, err := utils.DeepCopyChecked(d.NewValue)
+			if err != nil {
+				return nil, err
+			}
 			op := types.NewJSONObject()
 			op.PutString("op", "add")
-			op.PutString("path", jsonPathToPatchPath(d.Path))
-			op.Put("value", d.NewValue)
-			patch.Add(op)
+			op.PutString("path", d.Pointer())
+			op.Put("value", value)
+			ops.Add(op)
 		case DiffRemoved:
 			op := types.NewJSONObject()
 			op.PutString("op", "remove")
-			op.PutString("path", jsonPathToPatchPath(d.Path))
-			patch.Add(op)
-		case DiffModified:
+			op.PutString("path", d.Pointer())
+			ops.Add(op)
+		case DiffMoved:
+			op := types.NewJSONObject()
+			op.PutString("op", "move")
+			op.PutString("from", d.fromPointer())
+			op.PutString("path", d.Pointer())
+			ops.Add(op)
+		case DiffModified, DiffTypeChanged:
+			value, err := utils.DeepCopyChecked(d.NewValue)
+			if err != nil {
+				return nil, err
+			}
 			op := types.NewJSONObject()
 			op.PutString("op", "replace")
-			op.PutString("path", jsonPathToPatchPath(d.Path))
-			op.Put("value", d.NewValue)
-			patch.Add(op)
+			op.PutString("path", d.Pointer())
+			op.Put("value", value)
+			ops.Add(op)
 		}
 	}
 
-	return patch
+	return ops, nil
 }
 
-// 将JSON Path转换为JSON Patch路径
-func jsonPathToPatchPath(path string) string {
-	if path == "$" {
-		return ""
+// Pointer 返回差异位置的JSON Pointer（RFC 6901）
+// 优先使用结构化的路径段，未设置PathSegments时解析Path字符串
+func (d *Diff) Pointer() string {
+	if d.PathSegments == nil {
+		return jsonPathToPatchPath(d.Path)
 	}
 
-	// 移除开头的$
-	path = path[1:]
-
-	// 替换.为/
-	result := strings.ReplaceAll(path, ".", "/")
+	return segmentsToPointer(d.PathSegments)
+}
 
-	// 处理数组索引
-	result = strings.ReplaceAll(result, "[", "/")
-	result = strings.ReplaceAll(result, "]", "")
+// segmentsToPointer 将路径段格式化为JSON Pointer
+func segmentsToPointer(segments []Segment) string {
+	return types.Path(segments).Pointer()
+}
 
-	// 处理转义字符
-	result = strings.ReplaceAll(result, "~", "~0")
-	result = strings.ReplaceAll(result, "/", "~1")
+// fromPointer 返回DiffMoved差异中移动前位置的JSON Pointer
+func (d *Diff) fromPointer() string {
+	if d.FromSegments == nil {
+		return jsonPathToPatchPath(d.From)
+	}
+	return segmentsToPointer(d.FromSegments)
+}
 
-	return "/" + result
+// 将JSON Path转换为JSON Patch路径
+// 路径是diffValues生成的形式，由.key、['key']和[index]段组成
+func jsonPathToPatchPath(path string) string {
+	p, err := types.ParsePath(path)
+	if err != nil {
+		// 无法解析的路径作为一个键处理，保证总能生成补丁
+		return types.Path{types.KeySegment(strings.TrimPrefix(path, "$"))}.Pointer()
+	}
+	return p.Pointer()
 }
@@ -1,9 +1,11 @@
 package diff
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/patch"
 	"github.com/UserLeeZJ/gojson/types"
 )
 
@@ -127,3 +129,320 @@ func TestGeneratePatch(t *testing.T) {
 		t.Errorf("第二个操作类型不匹配: 期望 op=add, 实际 op=%s", opType2)
 	}
 }
+
+func TestGeneratePatchCopiesValues(t *testing.T) {
+	oldValue, _ := parser.ParseToValue(`{}`)
+	newValue, _ := parser.ParseToValue(`{"user":{"name":"张三"}}`)
+
+	diffs, err := DiffJSON(oldValue, newValue, nil)
+	if err != nil {
+		t.Fatalf("比较失败: %v", err)
+	}
+	op, _ := GeneratePatch(diffs).GetObject(0)
+	user, err := op.GetObject("value")
+	if err != nil {
+		t.Fatalf("Patch操作缺少对象值: %v", err)
+	}
+
+	// 修改补丁中的值不应影响新文档
+	user.PutString("name", "李四")
+	if got := newValue.String(); got != `{"user":{"name":"张三"}}` {
+		t.Errorf("修改补丁影响了新文档: %s", got)
+	}
+}
+
+func TestGeneratePatchCycle(t *testing.T) {
+	cyclic := types.NewJSONObject()
+	cyclic.Put("self", cyclic)
+	diffs := []*Diff{{Type: DiffAdded, Path: "$.a", OldValue: types.NewJSONNull(), NewValue: cyclic}}
+
+	if _, err := GeneratePatchChecked(diffs); err == nil {
+		t.Error("新值包含循环引用时GeneratePatchChecked()应该返回错误")
+	}
+	ops, err := GeneratePatchChecked(diffs[:0])
+	if err != nil || ops.Size() != 0 {
+		t.Errorf("GeneratePatchChecked(nil) = %v, %v", ops, err)
+	}
+}
+
+func TestGeneratePatchEscaping(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "$", want: ""},
+		{path: "$.name", want: "/name"},
+		{path: "$.users[0].name", want: "/users/0/name"},
+		{path: "$['a/b']", want: "/a~1b"},
+		{path: "$['m~n'].x", want: "/m~0n/x"},
+		{path: "$['a.b'][2]['c d']", want: "/a.b/2/c d"},
+	}
+
+	for _, tt := range tests {
+		if got := jsonPathToPatchPath(tt.path); got != tt.want {
+			t.Errorf("jsonPathToPatchPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	diffs, _ := DiffJSONStrings(`{"a/b":1}`, `{"a/b":2}`, nil)
+	patchArray := GeneratePatch(diffs)
+	op, _ := patchArray.GetObject(0)
+	if path, _ := op.GetString("path"); path != "/a~1b" {
+		t.Errorf("GeneratePatch() path = %q, want %q", path, "/a~1b")
+	}
+}
+
+// roundTripKeys 包含需要在JSON Pointer中转义或在JSON Path中加引号的键
+var roundTripKeys = []string{"a", "b.c", "d/e", "f~g", "[h]", "i j", "0", "", "~1", "k']"}
+
+// randomValue 生成随机的JSON值
+func randomValue(r *rand.Rand, depth int) types.JSONValue {
+	kind := r.Intn(7)
+	if depth <= 0 && kind >= 5 {
+		kind = r.Intn(5)
+	}
+	switch kind {
+	case 0:
+		return types.NewJSONNull()
+	case 1:
+		return types.NewJSONBool(r.Intn(2) == 0)
+	case 2:
+		return types.NewJSONNumber(float64(r.Intn(100)))
+	case 3, 4:
+		return types.NewJSONString(roundTripKeys[r.Intn(len(roundTripKeys))])
+	case 5:
+		arr := types.NewJSONArray()
+		for i := r.Intn(5); i > 0; i-- {
+			arr.Add(randomValue(r, depth-1))
+		}
+		return arr
+	default:
+		obj := types.NewJSONObject()
+		for i := r.Intn(5); i > 0; i-- {
+			obj.Put(roundTripKeys[r.Intn(len(roundTripKeys))], randomValue(r, depth-1))
+		}
+		return obj
+	}
+}
+
+// mutateValue 返回随机修改后的JSON值副本
+func mutateValue(r *rand.Rand, value types.JSONValue, depth int) types.JSONValue {
+	if r.Intn(6) == 0 {
+		return randomValue(r, depth)
+	}
+	switch {
+	case value.IsObject():
+		obj, _ := value.AsObject()
+		result := types.NewJSONObject()
+		for _, key := range obj.Keys() {
+			if r.Intn(5) == 0 {
+				continue // 删除键
+			}
+			result.Put(key, mutateValue(r, obj.Get(key), depth-1))
+		}
+		if r.Intn(3) == 0 {
+			result.Put(roundTripKeys[r.Intn(len(roundTripKeys))], randomValue(r, depth-1))
+		}
+		return result
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		result := types.NewJSONArray()
+		size := arr.Size()
+		if r.Intn(3) == 0 {
+			size = r.Intn(size + 1)
+		}
+		for i := 0; i < size; i++ {
+			result.Add(mutateValue(r, arr.Get(i), depth-1))
+		}
+		for i := r.Intn(3); i > 0; i-- {
+			result.Add(randomValue(r, depth-1))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func TestGeneratePatchRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 500; i++ {
+		oldValue := randomValue(r, 4)
+		newValue := mutateValue(r, oldValue, 4)
+
+		diffs, err := DiffJSON(oldValue, newValue, nil)
+		if err != nil {
+			t.Fatalf("DiffJSON() error = %v", err)
+		}
+		patchJSON := GeneratePatch(diffs).String()
+
+		got, err := patch.ApplyPatch(oldValue, patchJSON)
+		if err != nil {
+			t.Fatalf("ApplyPatch() error = %v\nold: %s\nnew: %s\npatch: %s", err, oldValue, newValue, patchJSON)
+		}
+		if got.String() != newValue.String() {
+			t.Fatalf("round trip mismatch\nold: %s\nnew: %s\npatch: %s\ngot: %s", oldValue, newValue, patchJSON, got)
+		}
+	}
+}
+
+func TestDiffPathSegments(t *testing.T) {
+	diffs, err := DiffJSONStrings(`{"users":[{"a/b":1}]}`, `{"users":[{"a/b":2}]}`, nil)
+	if err != nil {
+		t.Fatalf("DiffJSONStrings() error = %v", err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("差异数量不匹配: 期望 1, 实际 %d", len(diffs))
+	}
+
+	d := diffs[0]
+	if d.Path != "$.users[0]['a/b']" {
+		t.Errorf("Path = %q, want %q", d.Path, "$.users[0]['a/b']")
+	}
+	want := []Segment{{Key: "users"}, {Index: 0, IsIndex: true}, {Key: "a/b"}}
+	if len(d.PathSegments) != len(want) {
+		t.Fatalf("PathSegments = %v, want %v", d.PathSegments, want)
+	}
+	for i := range want {
+		if d.PathSegments[i] != want[i] {
+			t.Errorf("PathSegments[%d] = %v, want %v", i, d.PathSegments[i], want[i])
+		}
+	}
+	if d.Pointer() != "/users/0/a~1b" {
+		t.Errorf("Pointer() = %q, want %q", d.Pointer(), "/users/0/a~1b")
+	}
+
+	// 手动构造的Diff从Path解析
+	manual := &Diff{Type: DiffModified, Path: "$.x[1]"}
+	if manual.Pointer() != "/x/1" {
+		t.Errorf("Pointer() = %q, want %q", manual.Pointer(), "/x/1")
+	}
+
+	// 根节点
+	diffs, _ = DiffJSONStrings(`1`, `2`, nil)
+	if len(diffs) != 1 || len(diffs[0].PathSegments) != 0 || diffs[0].Pointer() != "" {
+		t.Errorf("root diff = %+v", diffs)
+	}
+}
+
+func TestDiffExactNumbers(t *testing.T) {
+	id, _ := types.ParseJSONBigInt("9007199254740993")
+	nextID, _ := types.ParseJSONBigInt("9007199254740992")
+	oldValue := types.NewJSONObject().Put("id", id).Put("amount", types.NewJSONDecimalFromInt64(150, 2))
+	newValue := types.NewJSONObject().Put("id", nextID).Put("amount", types.NewJSONNumber(1.5))
+
+	diffs, err := DiffJSON(oldValue, newValue, nil)
+	if err != nil {
+		t.Fatalf("DiffJSON() error = %v", err)
+	}
+	// id的差异超出了float64的精度，amount的1.50与1.5相等
+	if len(diffs) != 1 || diffs[0].Path != "$.id" || diffs[0].Type != DiffModified {
+		t.Errorf("DiffJSON() = %v, want one modification at $.id", diffs)
+	}
+}
+
+func TestDiffArrayKeys(t *testing.T) {
+	oldJSON := `{"users":[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3,"name":"c"}]}`
+	newJSON := `{"users":[{"id":1,"name":"a"},{"id":3,"name":"C"}]}`
+
+	options := DefaultDiffOptions()
+	options.ArrayKeys = map[string]string{"$.users": "id"}
+	diffs, err := DiffJSONStrings(oldJSON, newJSON, options)
+	if err != nil {
+		t.Fatalf("DiffJSONStrings() error = %v", err)
+	}
+
+	// 移除一个元素只应产生一个移除和一个修改，而不是一连串的修改
+	if len(diffs) != 2 {
+		t.Fatalf("差异数量不匹配: 期望 2, 实际 %d: %v", len(diffs), diffs)
+	}
+	if diffs[0].Type != DiffRemoved || diffs[0].Path != "$.users[1]" {
+		t.Errorf("diffs[0] = %v, want removal of $.users[1]", diffs[0])
+	}
+	if diffs[1].Type != DiffModified || diffs[1].Path != "$.users[1].name" {
+		t.Errorf("diffs[1] = %v, want modification of $.users[1].name", diffs[1])
+	}
+
+	// 通配符模式
+	options.ArrayKeys = map[string]string{"$.groups[*].members": "id"}
+	diffs, _ = DiffJSONStrings(
+		`{"groups":[{"members":[{"id":"x"},{"id":"y","v":1}]}]}`,
+		`{"groups":[{"members":[{"id":"y","v":2}]}]}`, options)
+	if len(diffs) != 2 || diffs[1].Path != "$.groups[0].members[0].v" {
+		t.Errorf("wildcard ArrayKeys diffs = %v", diffs)
+	}
+}
+
+func TestDiffArrayKeysOverlapping(t *testing.T) {
+	oldJSON := `{"groups":[{"members":[{"id":"x","name":"a"},{"id":"y","name":"b"}]}]}`
+	newJSON := `{"groups":[{"members":[{"id":"y","name":"a"}]}]}`
+
+	tests := []struct {
+		arrayKeys map[string]string
+		want      string // 使用的标识键不同，修改的路径也不同
+	}{
+		// *少的模式优先
+		{map[string]string{"$.groups[*].members": "id", "$.groups[*].*": "name"}, "$.groups[0].members[0].name"},
+		// 不包含*的模式优先
+		{map[string]string{"$.groups[0].members": "name", "$.groups[*].members": "id"}, "$.groups[0].members[0].id"},
+		// *的数量相同时较长的模式优先
+		{map[string]string{"$.groups[*].members": "id", "$.*[0].members": "name"}, "$.groups[0].members[0].name"},
+	}
+	for _, tt := range tests {
+		options := DefaultDiffOptions()
+		options.ArrayKeys = tt.arrayKeys
+		// map的遍历顺序是随机的，多次比较的结果应该相同
+		for i := 0; i < 20; i++ {
+			diffs, err := DiffJSONStrings(oldJSON, newJSON, options)
+			if err != nil {
+				t.Fatalf("DiffJSONStrings() error = %v", err)
+			}
+			var modified []string
+			for _, d := range diffs {
+				if d.Type == DiffModified {
+					modified = append(modified, d.Path)
+				}
+			}
+			if len(modified) != 1 || modified[0] != tt.want {
+				t.Fatalf("ArrayKeys %v: 修改了 %v, 期望 %s", tt.arrayKeys, modified, tt.want)
+			}
+		}
+	}
+}
+
+func TestDiffArrayKeysRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	options := DefaultDiffOptions()
+	options.ArrayKeys = map[string]string{"$": "id"}
+
+	for i := 0; i < 300; i++ {
+		oldArr := types.NewJSONArray()
+		for n := r.Intn(8); n > 0; n-- {
+			item := types.NewJSONObject()
+			item.PutNumber("id", float64(r.Intn(10)))
+			item.PutNumber("v", float64(r.Intn(3)))
+			oldArr.Add(item)
+		}
+		newArr := types.NewJSONArray()
+		for n := r.Intn(8); n > 0; n-- {
+			if r.Intn(4) == 0 {
+				newArr.AddString("no id")
+				continue
+			}
+			item := types.NewJSONObject()
+			item.PutNumber("id", float64(r.Intn(10)))
+			item.PutNumber("v", float64(r.Intn(3)))
+			newArr.Add(item)
+		}
+
+		diffs, _ := DiffJSON(oldArr, newArr, options)
+		patchJSON := GeneratePatch(diffs).String()
+		got, err := patch.ApplyPatch(oldArr, patchJSON)
+		if err != nil {
+			t.Fatalf("ApplyPatch() error = %v\nold: %s\nnew: %s\npatch: %s", err, oldArr, newArr, patchJSON)
+		}
+		if got.String() != newArr.String() {
+			t.Fatalf("round trip mismatch\nold: %s\nnew: %s\npatch: %s\ngot: %s", oldArr, newArr, patchJSON, got)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextDiffMode 表示字符串内部差异的粒度
+type TextDiffMode int
+
+const (
+	// TextDiffNone 不计算字符串内部的差异
+	TextDiffNone TextDiffMode = iota
+	// TextDiffLine 按行计算差异，适合配置文件和多行文档
+	TextDiffLine
+	// TextDiffWord 按单词计算差异，空白和标点符号也作为单独的片段参与比较，适合单行的长文本
+	TextDiffWord
+)
+
+// String 返回粒度的名称
+func (m TextDiffMode) String() string {
+	switch m {
+	case TextDiffNone:
+		return "none"
+	case TextDiffLine:
+		return "line"
+	case TextDiffWord:
+		return "word"
+	default:
+		return fmt.Sprintf("TextDiffMode(%d)", int(m))
+	}
+}
+
+// ParseTextDiffMode 按名称（none、line、word）返回粒度
+func ParseTextDiffMode(name string) (TextDiffMode, error) {
+	for _, mode := range []TextDiffMode{TextDiffNone, TextDiffLine, TextDiffWord} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return TextDiffNone, fmt.Errorf("未知的字符串差异粒度: %s", name)
+}
+
+// DefaultMinTextLength 是计算字符串内部差异的默认最小长度
+const DefaultMinTextLength = 64
+
+// TextOp 表示字符串内部差异中一个片段的操作
+type TextOp string
+
+const (
+	TextEqual  TextOp = "equal"  // 两个字符串共有的片段
+	TextInsert TextOp = "insert" // 新字符串中添加的片段
+	TextDelete TextOp = "delete" // 旧字符串中删除的片段
+)
+
+// TextEdit 是字符串内部差异中的一个片段，按顺序连接所有TextEqual和TextDelete片段得到旧字符串，
+// 连接所有TextEqual和TextInsert片段得到新字符串
+type TextEdit struct {
+	Op   TextOp
+	Text string
+}
+
+// DiffText 用Myers算法按mode的粒度比较两个字符串，相邻的同类片段会被合并
+func DiffText(oldText, newText string, mode TextDiffMode) []TextEdit {
+	var oldTokens, newTokens []string
+	if mode == TextDiffWord {
+		oldTokens, newTokens = splitWords(oldText), splitWords(newText)
+	} else {
+		oldTokens, newTokens = splitLines(oldText), splitLines(newText)
+	}
+
+	var edits []TextEdit
+	for _, edit := range myers(oldTokens, newTokens) {
+		if n := len(edits); n > 0 && edits[n-1].Op == edit.Op {
+			edits[n-1].Text += edit.Text
+			continue
+		}
+		edits = append(edits, edit)
+	}
+	return edits
+}
+
+// splitLines 把文本切分为行，每行保留结尾的换行符
+func splitLines(s string) []string {
+	var lines []string
+	for len(s) > 0 {
+		end := strings.IndexByte(s, '\n') + 1
+		if end == 0 {
+			end = len(s)
+		}
+		lines = append(lines, s[:end])
+		s = s[end:]
+	}
+	return lines
+}
+
+// splitWords 把文本切分为单词（字母、数字和下划线）、连续的空白和单个标点符号
+func splitWords(s string) []string {
+	var words []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		class := runeClass(r)
+		end := size
+		if class != classPunct {
+			for end < len(s) {
+				r, size := utf8.DecodeRuneInString(s[end:])
+				if runeClass(r) != class {
+					break
+				}
+				end += size
+			}
+		}
+		words = append(words, s[:end])
+		s = s[end:]
+	}
+	return words
+}
+
+// 字符的分类，同类的连续字符组成一个单词
+const (
+	classWord = iota
+	classSpace
+	classPunct
+)
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return classWord
+	case unicode.IsSpace(r):
+		return classSpace
+	}
+	return classPunct
+}
+
+// maxTextEditDistance 是Myers算法搜索的最大编辑距离，超过时把整个字符串视为被替换，
+// 使回溯需要的内存不超过编辑距离的平方
+const maxTextEditDistance = 4096
+
+// myers 返回把a变为b的最短编辑序列，每个片段对应一个词法单元
+func myers(a, b []string) []TextEdit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxTextEditDistance {
+		maxD = maxTextEditDistance
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d]保存第d步之前v中对角线-d到d的部分，用于回溯路径
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return []TextEdit{
+			{Op: TextDelete, Text: strings.Join(a, "")},
+			{Op: TextInsert, Text: strings.Join(b, "")},
+		}
+	}
+
+	// 从终点回溯，逆序生成编辑
+	var edits []TextEdit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || k != d && v[k-1+d] < v[k+1+d] {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = v[prevK+d]
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, TextEdit{Op: TextEqual, Text: a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, TextEdit{Op: TextInsert, Text: b[y]})
+		} else {
+			x--
+			edits = append(edits, TextEdit{Op: TextDelete, Text: a[x]})
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// textDiffContext 是按行显示差异时每处修改前后保留的相同行数
+const textDiffContext = 2
+
+// FormatTextEdits 把字符串内部差异格式化为便于阅读的文本。
+// 按行的差异每行以"  "、"- "或"+ "开头，离修改较远的相同行被省略；
+// 按单词的差异显示为一行，删除的片段写作[-文本-]，添加的片段写作{+文本+}
+func FormatTextEdits(edits []TextEdit, mode TextDiffMode) string {
+	var sb strings.Builder
+	if mode == TextDiffWord {
+		for _, edit := range edits {
+			switch edit.Op {
+			case TextDelete:
+				sb.WriteString("[-" + edit.Text + "-]")
+			case TextInsert:
+				sb.WriteString("{+" + edit.Text + "+}")
+			default:
+				sb.WriteString(edit.Text)
+			}
+		}
+		return sb.String()
+	}
+
+	for i, edit := range edits {
+		lines := splitLines(edit.Text)
+		switch edit.Op {
+		case TextDelete:
+			writeLines(&sb, "- ", lines)
+		case TextInsert:
+			writeLines(&sb, "+ ", lines)
+		default:
+			// 只保留靠近修改的相同行
+			head, tail := 0, 0
+			if i > 0 {
+				head = textDiffContext
+			}
+			if i < len(edits)-1 {
+				tail = textDiffContext
+			}
+			if head+tail >= len(lines) {
+				writeLines(&sb, "  ", lines)
+				continue
+			}
+			writeLines(&sb, "  ", lines[:head])
+			fmt.Fprintf(&sb, "  ...（省略%d行）\n", len(lines)-head-tail)
+			writeLines(&sb, "  ", lines[len(lines)-tail:])
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// writeLines 写入带有前缀的行，没有换行符结尾的行补上换行符
+func writeLines(sb *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(prefix)
+		sb.WriteString(strings.TrimSuffix(line, "\n"))
+		sb.WriteByte('\n')
+	}
+}
@@ -0,0 +1,174 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// joinEdits 返回由编辑还原的旧字符串和新字符串
+func joinEdits(edits []TextEdit) (string, string) {
+	var oldText, newText strings.Builder
+	for _, edit := range edits {
+		if edit.Op != TextInsert {
+			oldText.WriteString(edit.Text)
+		}
+		if edit.Op != TextDelete {
+			newText.WriteString(edit.Text)
+		}
+	}
+	return oldText.String(), newText.String()
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		oldText, newText string
+		mode             TextDiffMode
+		want             []TextEdit
+	}{
+		{
+			"a\nb\nc\n", "a\nB\nc\n", TextDiffLine,
+			[]TextEdit{{TextEqual, "a\n"}, {TextDelete, "b\n"}, {TextInsert, "B\n"}, {TextEqual, "c\n"}},
+		},
+		{
+			"a\nc", "a\nb\nc", TextDiffLine,
+			[]TextEdit{{TextEqual, "a\n"}, {TextInsert, "b\n"}, {TextEqual, "c"}},
+		},
+		{
+			"the quick brown fox", "the slow brown dog", TextDiffWord,
+			[]TextEdit{{TextEqual, "the "}, {TextDelete, "quick"}, {TextInsert, "slow"},
+				{TextEqual, " brown "}, {TextDelete, "fox"}, {TextInsert, "dog"}},
+		},
+		{"", "new", TextDiffWord, []TextEdit{{TextInsert, "new"}}},
+		{"same", "same", TextDiffWord, []TextEdit{{TextEqual, "same"}}},
+	}
+	for _, tt := range tests {
+		got := DiffText(tt.oldText, tt.newText, tt.mode)
+		if len(got) != len(tt.want) {
+			t.Errorf("DiffText(%q, %q) = %v, want %v", tt.oldText, tt.newText, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("DiffText(%q, %q) = %v, want %v", tt.oldText, tt.newText, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDiffTextRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", " ", "\n"}
+	random := func() string {
+		var sb strings.Builder
+		for i := rng.Intn(40); i > 0; i-- {
+			sb.WriteString(words[rng.Intn(len(words))])
+		}
+		return sb.String()
+	}
+	for i := 0; i < 500; i++ {
+		oldText, newText := random(), random()
+		for _, mode := range []TextDiffMode{TextDiffLine, TextDiffWord} {
+			gotOld, gotNew := joinEdits(DiffText(oldText, newText, mode))
+			if gotOld != oldText || gotNew != newText {
+				t.Fatalf("DiffText(%q, %q, %v) reconstructs %q, %q", oldText, newText, mode, gotOld, gotNew)
+			}
+		}
+	}
+}
+
+func TestDiffTextTooManyChanges(t *testing.T) {
+	var oldText, newText strings.Builder
+	for i := 0; i < maxTextEditDistance; i++ {
+		oldText.WriteString("old\n")
+		newText.WriteString("new\n")
+	}
+	got := DiffText(oldText.String(), newText.String(), TextDiffLine)
+	if len(got) != 2 || got[0].Op != TextDelete || got[1].Op != TextInsert {
+		t.Fatalf("DiffText() returned %d edits, want a single replacement", len(got))
+	}
+	if gotOld, gotNew := joinEdits(got); gotOld != oldText.String() || gotNew != newText.String() {
+		t.Errorf("DiffText() does not reconstruct the inputs")
+	}
+}
+
+func TestFormatTextEdits(t *testing.T) {
+	oldText := "l1\nl2\nl3\nl4\nl5\nl6\nl7\nl8\n"
+	newText := "l1\nl2\nl3\nl4\nl5\nL6\nl7\nl8\n"
+	got := FormatTextEdits(DiffText(oldText, newText, TextDiffLine), TextDiffLine)
+	want := "  ...（省略3行）\n  l4\n  l5\n- l6\n+ L6\n  l7\n  l8"
+	if got != want {
+		t.Errorf("FormatTextEdits(line) = %q, want %q", got, want)
+	}
+
+	got = FormatTextEdits(DiffText("the quick brown fox", "the slow brown fox", TextDiffWord), TextDiffWord)
+	if want := "the [-quick-]{+slow+} brown fox"; got != want {
+		t.Errorf("FormatTextEdits(word) = %q, want %q", got, want)
+	}
+}
+
+func TestDiffJSONTextDiff(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 10)
+	oldValue := types.NewJSONObject().
+		PutString("config", "host=a\nport=1\n").
+		PutString("text", long+"old").
+		PutString("short", "x")
+	newValue := types.NewJSONObject().
+		PutString("config", "host=a\nport=2\n").
+		PutString("text", long+"new").
+		PutString("short", "y")
+
+	diffs, err := DiffJSON(oldValue, newValue, &DiffOptions{TextDiff: TextDiffLine})
+	if err != nil {
+		t.Fatalf("DiffJSON() error = %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("DiffJSON() = %v", diffs)
+	}
+	byPath := make(map[string]*Diff)
+	for _, d := range diffs {
+		byPath[d.Path] = d
+	}
+
+	// 多行字符串即使很短也计算差异，短的单行字符串不计算
+	if got, want := byPath["$.config"].String(), "修改: $.config\n  host=a\n- port=1\n+ port=2"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if byPath["$.short"].TextEdits != nil {
+		t.Errorf("short strings should not get text edits")
+	}
+	if edits := byPath["$.text"].TextEdits; len(edits) != 2 || edits[0].Op != TextDelete {
+		t.Errorf("TextEdits of a single long line = %v", edits)
+	}
+
+	diffs, _ = DiffJSON(oldValue, newValue, &DiffOptions{TextDiff: TextDiffWord, MinTextLength: 1000})
+	for _, d := range diffs {
+		if d.Path == "$.text" && d.TextEdits != nil {
+			t.Errorf("MinTextLength should skip $.text")
+		}
+		if d.Path == "$.config" {
+			if want := "修改: $.config = host=a\nport=[-1-]{+2+}\n"; d.String() != want {
+				t.Errorf("String() = %q, want %q", d.String(), want)
+			}
+		}
+	}
+
+	// 字符串内部的差异不影响生成的补丁
+	if ops := GeneratePatch(diffs); ops.Size() != 3 {
+		t.Errorf("GeneratePatch() = %v", ops)
+	}
+}
+
+func TestParseTextDiffMode(t *testing.T) {
+	for _, mode := range []TextDiffMode{TextDiffNone, TextDiffLine, TextDiffWord} {
+		if got, err := ParseTextDiffMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseTextDiffMode(%q) = %v, %v", mode.String(), got, err)
+		}
+	}
+	if _, err := ParseTextDiffMode("char"); err == nil {
+		t.Errorf("ParseTextDiffMode(char) should fail")
+	}
+}
@@ -39,6 +39,13 @@ gojson定义了以下基本类型：
 	// 输出格式化的JSON
 	fmt.Println(person.String())
 
+也可以使用Obj和Arr以字面量形式构造：
+
+	person := gojson.Obj{
+		"name":    "张三",
+		"hobbies": gojson.Arr{"阅读", "编程"},
+	}.MustObject()
+
 解析JSON字符串：
 
 	jsonStr := `{"name":"张三","age":28,"address":{"city":"北京"}}`
@@ -73,6 +80,11 @@ gojson支持JSON Path查询，可以从复杂的JSON结构中提取数据：
 		fmt.Println(author)
 	}
 
+只需要第一个匹配值时，可以直接从JSON字符串中获取：
+
+	title, err := gojson.GetString(jsonStr, "$.store.book[0].title")
+	price, err := gojson.GetNumber(jsonStr, "$.store.book[0].price")
+
 支持的JSON Path语法：
 
 - $: 根节点
@@ -81,7 +93,10 @@ gojson支持JSON Path查询，可以从复杂的JSON结构中提取数据：
 - [index]: 数组索引访问
 - [start:end]: 数组切片
 - [*]: 通配符，匹配所有元素
-- [?(@.property == value)]: 过滤器表达式
+- [0,2,5]、['a','b']: 联合，按顺序连接各成员匹配的值
+- ..property: 递归下降，匹配任意深度的属性
+- [?(@.property == value)]: 过滤器表达式，支持==、!=、<、<=、>、>=、&&、||、!和存在性检查[?(@.property)]，$引用根节点
+- .length()、.min()、.max()、.avg()、.sum(): 路径末尾的聚合函数，结果是一个数字
 
 JSON Diff
 
@@ -123,6 +138,26 @@ gojson支持根据差异生成JSON Patch（RFC 6902）：
 	// 输出Patch
 	fmt.Println(patch.String())
 
+遍历JSON值
+
+实现Visitor接口即可遍历任意JSON值，无需编写类型分支。
+嵌入BaseVisitor后只需实现关心的方法：
+
+	type stringCounter struct {
+		gojson.BaseVisitor
+		count int
+	}
+
+	func (c *stringCounter) VisitString(value string) error {
+		c.count++
+		return nil
+	}
+
+	counter := &stringCounter{}
+	err := gojson.Accept(value, counter)
+
+VisitObjectStart或VisitArrayStart返回gojson.SkipChildren时跳过该容器的内容。
+
 性能优化
 
 gojson提供了优化的序列化和反序列化函数：
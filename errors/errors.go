@@ -14,9 +14,13 @@ const (
 	ErrInvalidJSON ErrorCode = "INVALID_JSON"
 	ErrEmptyInput  ErrorCode = "EMPTY_INPUT"
 
+	// 编码错误。
+	ErrInvalidEncoding ErrorCode = "INVALID_ENCODING"
+
 	// 类型错误。
-	ErrInvalidType    ErrorCode = "INVALID_TYPE"
-	ErrTypeConversion ErrorCode = "TYPE_CONVERSION"
+	ErrInvalidType     ErrorCode = "INVALID_TYPE"
+	ErrTypeConversion  ErrorCode = "TYPE_CONVERSION"
+	ErrUnsupportedType ErrorCode = "UNSUPPORTED_TYPE"
 
 	// 路径错误。
 	ErrPathNotFound ErrorCode = "PATH_NOT_FOUND"
@@ -27,13 +31,26 @@ const (
 	ErrInvalidIndex    ErrorCode = "INVALID_INDEX"
 
 	// 操作错误。
-	ErrOperationFailed ErrorCode = "OPERATION_FAILED"
-	ErrNotSupported    ErrorCode = "NOT_SUPPORTED"
+	ErrOperationFailed   ErrorCode = "OPERATION_FAILED"
+	ErrNotSupported      ErrorCode = "NOT_SUPPORTED"
+	ErrCircularReference ErrorCode = "CIRCULAR_REFERENCE"
 
 	// Patch 错误。
 	ErrInvalidPatch ErrorCode = "INVALID_PATCH"
 	ErrPatchFailed  ErrorCode = "PATCH_FAILED"
 	ErrTestFailed   ErrorCode = "TEST_FAILED"
+
+	// 资源限制错误。
+	ErrBudgetExceeded ErrorCode = "BUDGET_EXCEEDED"
+
+	// 表达式错误。
+	ErrInvalidExpression ErrorCode = "INVALID_EXPRESSION"
+
+	// 格式版本错误。
+	ErrUnsupportedVersion ErrorCode = "UNSUPPORTED_VERSION"
+
+	// 转换菜谱错误。
+	ErrInvalidRecipe ErrorCode = "INVALID_RECIPE"
 )
 
 // JSONError 表示JSON操作中的错误。
@@ -0,0 +1,234 @@
+package errors
+
+import (
+	"encoding"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxUnsupportedDepth 是查找不能序列化的值时的最大嵌套深度，防止循环引用导致无限递归。
+const maxUnsupportedDepth = 1000
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ErrUnsupportedTypeWithDetails 创建不能序列化的类型错误详情，path是值在序列化对象中的JSON Path。
+func ErrUnsupportedTypeWithDetails(goType, path string) *JSONError {
+	return NewJSONError(ErrUnsupportedType,
+		fmt.Sprintf("不能序列化为JSON的类型: %s", goType)).WithPath(path)
+}
+
+// UnsupportedTypeError 检查序列化v时得到的错误err是否由通道、函数、复数等不能序列化的Go类型引起。
+// 是则返回统一的ErrUnsupportedType错误，包含该值的Go类型以及它在v中的JSON Path（结构体字段使用
+// JSON中的名称），err作为原始错误；否则返回nil。fast、parser和types的序列化函数都使用这个错误。
+func UnsupportedTypeError(v interface{}, err error) *JSONError {
+	var typeErr *json.UnsupportedTypeError
+	var jsonErr *JSONError
+	isTypeErr := stderrors.As(err, &typeErr)
+	if !isTypeErr && !(stderrors.As(err, &jsonErr) && jsonErr.Code == ErrUnsupportedType) {
+		return nil
+	}
+
+	// 不同版本的encoding/json对omitempty的空字段处理不同：有的不检查这些字段，有的按类型报告错误，
+	// 因此先跳过它们查找，找不到时再包括它们。
+	t, path, ok := findUnsupported(reflect.ValueOf(v), "$", 0, true)
+	if !ok {
+		t, path, ok = findUnsupported(reflect.ValueOf(v), "$", 0, false)
+	}
+	if ok {
+		cause := err
+		if isTypeErr {
+			cause = typeErr
+		}
+		return ErrUnsupportedTypeWithDetails(t.String(), path).WithCause(cause)
+	}
+	if isTypeErr {
+		return NewJSONError(ErrUnsupportedType,
+			fmt.Sprintf("不能序列化为JSON的类型: %s", typeErr.Type)).WithCause(typeErr)
+	}
+	return jsonErr
+}
+
+// findUnsupported 按encoding/json的规则在v中查找第一个不能序列化的值，
+// map按键排序后查找，返回该值的类型和JSON Path。skipEmpty决定是否跳过omitempty的空字段。
+func findUnsupported(v reflect.Value, path string, depth int, skipEmpty bool) (reflect.Type, string, bool) {
+	if !v.IsValid() || depth > maxUnsupportedDepth {
+		return nil, "", false
+	}
+
+	t := v.Type()
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface &&
+		(t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)) {
+		return nil, "", false
+	}
+
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return t, path, true
+	case reflect.Pointer:
+		if v.IsNil() || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return nil, "", false
+		}
+		return findUnsupported(v.Elem(), path, depth+1, skipEmpty)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, "", false
+		}
+		return findUnsupported(v.Elem(), path, depth+1, skipEmpty)
+	case reflect.Struct:
+		return findUnsupportedField(v, path, depth, skipEmpty)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, "", false
+		}
+		return findUnsupportedEntry(v, path, depth, skipEmpty)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, "", false
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if ut, p, ok := findUnsupported(v.Index(i), path+"["+strconv.Itoa(i)+"]", depth+1, skipEmpty); ok {
+				return ut, p, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// findUnsupportedField 在结构体的字段中查找，忽略未导出的字段、标签为"-"的字段
+// 以及skipEmpty为true时标签带omitempty的空字段，匿名结构体字段的字段视为外层结构体的字段。
+func findUnsupportedField(v reflect.Value, path string, depth int, skipEmpty bool) (reflect.Type, string, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				if ut, p, ok := findUnsupportedField(fv, path, depth+1, skipEmpty); ok {
+					return ut, p, true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if skipEmpty && hasOption(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		if ut, p, ok := findUnsupported(fv, childPath(path, name), depth+1, skipEmpty); ok {
+			return ut, p, true
+		}
+	}
+	return nil, "", false
+}
+
+// findUnsupportedEntry 在map的键和值中查找，键的类型不能作为JSON对象的键时返回键的类型。
+func findUnsupportedEntry(v reflect.Value, path string, depth int, skipEmpty bool) (reflect.Type, string, bool) {
+	keyType := v.Type().Key()
+	switch keyType.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !keyType.Implements(textMarshalerType) {
+			return keyType, path, true
+		}
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, entry{key: fmt.Sprint(iter.Key().Interface()), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	for _, e := range entries {
+		if ut, p, ok := findUnsupported(e.value, childPath(path, e.key), depth+1, skipEmpty); ok {
+			return ut, p, true
+		}
+	}
+	return nil, "", false
+}
+
+// childPath 返回在path后加上键key的JSON Path，不是标识符的键使用带引号的方括号。
+func childPath(path, key string) string {
+	if isIdentifier(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// isIdentifier 检查键是否可以在JSON Path中使用点号表示，与types包的规则相同。
+func isIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// hasOption 检查逗号分隔的标签选项中是否有name。
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue 与encoding/json的omitempty使用相同的规则判断值是否为空。
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String, reflect.Chan:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
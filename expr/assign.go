@@ -0,0 +1,103 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// Assignment 是一个计算字段，把表达式的值写入对象的字段，例如"total = $.price * $.qty"
+type Assignment struct {
+	// Field 是写入的字段名，可以用点号分隔多级字段，缺少的中间对象会被创建
+	Field string
+	// Expr 是以对象为$求值的表达式
+	Expr *Expr
+}
+
+// ParseAssignment 解析"field = expression"形式的计算字段
+func ParseAssignment(spec string) (*Assignment, error) {
+	eq := assignmentOperator(spec)
+	if eq < 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("计算字段%q必须形如field = expression", spec))
+	}
+	field := strings.TrimSpace(spec[:eq])
+	if field == "" || strings.ContainsAny(field, " \t") {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("计算字段%q的字段名无效", spec))
+	}
+	for _, name := range strings.Split(field, ".") {
+		if name == "" {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("计算字段%q的字段名无效", spec))
+		}
+	}
+	e, err := Compile(strings.TrimSpace(spec[eq+1:]))
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("计算字段%s的表达式无效", field)).WithCause(err)
+	}
+	return &Assignment{Field: field, Expr: e}, nil
+}
+
+// assignmentOperator 返回spec中第一个不属于==、!=、<=和>=的=的位置
+func assignmentOperator(spec string) int {
+	for i := 0; i < len(spec); i++ {
+		if spec[i] != '=' {
+			continue
+		}
+		if i+1 < len(spec) && spec[i+1] == '=' {
+			i++
+			continue
+		}
+		if i > 0 && strings.IndexByte("=!<>", spec[i-1]) >= 0 {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// String 返回"field = expression"形式的计算字段
+func (a *Assignment) String() string {
+	return a.Field + " = " + a.Expr.String()
+}
+
+// Apply 以obj为$求值表达式，把结果写入obj的字段
+func (a *Assignment) Apply(obj *types.JSONObject) error {
+	value, err := a.Expr.Eval(obj)
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("计算字段%s失败", a.Field)).WithCause(err)
+	}
+	if value.IsObject() || value.IsArray() {
+		// 复制引用的对象和数组，避免例如"self = $"使对象包含它自身
+		if value, err = utils.DeepCopyChecked(value); err != nil {
+			return err
+		}
+	}
+
+	names := strings.Split(a.Field, ".")
+	target := obj
+	for _, name := range names[:len(names)-1] {
+		child := target.Get(name)
+		if !child.IsObject() {
+			if target.Has(name) {
+				return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, fmt.Sprintf("计算字段%s失败: %s不是对象", a.Field, name))
+			}
+			child = types.NewJSONObject()
+			target.Put(name, child)
+		}
+		target, _ = child.AsObject()
+	}
+	target.Put(names[len(names)-1], value)
+	return nil
+}
+
+// ApplyAll 按顺序把计算字段应用到obj，后面的表达式可以引用前面计算出的字段
+func ApplyAll(obj *types.JSONObject, assignments []*Assignment) error {
+	for _, a := range assignments {
+		if err := a.Apply(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
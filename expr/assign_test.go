@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestParseAssignment(t *testing.T) {
+	tests := []struct {
+		spec  string
+		field string
+		expr  string
+	}{
+		{`total = $.price * $.qty`, "total", "$.price * $.qty"},
+		{`  flag=$.a == 1`, "flag", "$.a == 1"},
+		{`meta.label = $.a >= 1 ? 'x=y' : "z"`, "meta.label", `$.a >= 1 ? 'x=y' : "z"`},
+	}
+	for _, tt := range tests {
+		a, err := ParseAssignment(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseAssignment(%q) error = %v", tt.spec, err)
+		}
+		if a.Field != tt.field || a.Expr.String() != tt.expr {
+			t.Errorf("ParseAssignment(%q) = %q, %q", tt.spec, a.Field, a.Expr.String())
+		}
+		if a.String() != tt.field+" = "+tt.expr {
+			t.Errorf("String() = %q", a.String())
+		}
+	}
+
+	for _, spec := range []string{`$.a + 1`, `= 1`, `a b = 1`, `a..b = 1`, `total = `, `total = $.a +`, `a == 1`} {
+		if _, err := ParseAssignment(spec); err == nil {
+			t.Errorf("ParseAssignment(%q) should fail", spec)
+		}
+	}
+}
+
+func TestApplyAll(t *testing.T) {
+	var assignments []*Assignment
+	for _, spec := range []string{
+		`total = $.price * $.qty`,
+		`discounted = $.total > 40 ? $.total * 0.9 : $.total`,
+		`summary.label = $.name + ' x' + $.qty`,
+		`self = $`,
+	} {
+		a, err := ParseAssignment(spec)
+		if err != nil {
+			t.Fatalf("ParseAssignment(%q) error = %v", spec, err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	obj, _ := parser.MustParse(`{"price": 12.5, "qty": 4, "name": "widget"}`).AsObject()
+	if err := ApplyAll(obj, assignments); err != nil {
+		t.Fatalf("ApplyAll() error = %v", err)
+	}
+	want := `{"discounted":45,"name":"widget","price":12.5,"qty":4,` +
+		`"self":{"discounted":45,"name":"widget","price":12.5,"qty":4,"summary":{"label":"widget x4"},"total":50},` +
+		`"summary":{"label":"widget x4"},"total":50}`
+	if obj.String() != want {
+		t.Errorf("ApplyAll() = %v, want %v", obj, want)
+	}
+
+	// 中间字段不是对象或表达式出错时返回错误
+	obj = types.NewJSONObject().PutString("summary", "text").PutNumber("price", 1)
+	if err := assignments[2].Apply(obj); err == nil {
+		t.Errorf("Apply() into a string field should fail")
+	}
+	if err := assignments[0].Apply(obj); err == nil {
+		t.Errorf("Apply() with a missing qty should fail")
+	}
+}
@@ -0,0 +1,318 @@
+// Package expr 提供一个小型的表达式语言，用于根据JSON文档计算派生字段，
+// 使简单的计算不需要编写Go代码。
+//
+// 表达式支持：
+//   - 字面量：数字、单引号或双引号字符串、true、false和null
+//   - JSON Path引用：以$开头，例如$.price、$.items[0].name、$['unit price']、$..sku和$.items.length()，语法与jsonpath包相同
+//   - 算术运算：+、-、*、/、%，+的任意一侧是字符串时把另一侧转换为字符串后拼接
+//   - 比较运算：==、!=、<、<=、>、>=，数字按大小比较，字符串按字典序比较
+//   - 逻辑运算：&&、||、!
+//   - 条件表达式：cond ? a : b
+//
+// 示例：
+//
+//	e, err := expr.Compile(`$.qty > 10 ? $.price * $.qty * 0.9 : $.price * $.qty`)
+//	total, err := e.Eval(order)
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Expr 是编译后的表达式，可以被多个goroutine同时求值
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile 编译表达式
+func Compile(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "表达式为空")
+	}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("表达式应该已经结束")
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustCompile 编译表达式，失败时panic，适用于初始化阶段的常量表达式
+func MustCompile(src string) *Expr {
+	e, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String 返回表达式的源码
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval 以root为$对表达式求值。
+// 路径没有匹配值或者类型不匹配时得到null，匹配多个值时得到由这些值组成的数组
+func (e *Expr) Eval(root types.JSONValue) (types.JSONValue, error) {
+	if root == nil {
+		root = types.NewJSONNull()
+	}
+	return e.root.eval(root)
+}
+
+// Eval 编译并求值表达式
+func Eval(src string, root types.JSONValue) (types.JSONValue, error) {
+	e, err := Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return e.Eval(root)
+}
+
+// node 是语法树的节点
+type node interface {
+	eval(root types.JSONValue) (types.JSONValue, error)
+}
+
+// literalNode 是字面量
+type literalNode struct {
+	value types.JSONValue
+}
+
+func (n *literalNode) eval(types.JSONValue) (types.JSONValue, error) {
+	return n.value, nil
+}
+
+// pathNode 是JSON Path引用
+type pathNode struct {
+	path *jsonpath.JSONPath
+}
+
+func (n *pathNode) eval(root types.JSONValue) (types.JSONValue, error) {
+	results, err := n.path.Query(root)
+	if err != nil {
+		return types.NewJSONNull(), nil
+	}
+	switch len(results) {
+	case 0:
+		return types.NewJSONNull(), nil
+	case 1:
+		return results[0], nil
+	default:
+		return types.NewJSONArrayFromValues(results), nil
+	}
+}
+
+// conditionalNode 是条件表达式，只对选中的分支求值
+type conditionalNode struct {
+	cond, then, otherwise node
+}
+
+func (n *conditionalNode) eval(root types.JSONValue) (types.JSONValue, error) {
+	cond, err := n.cond.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return n.then.eval(root)
+	}
+	return n.otherwise.eval(root)
+}
+
+// unaryNode 是一元运算
+type unaryNode struct {
+	op      string
+	pos     int
+	operand node
+}
+
+func (n *unaryNode) eval(root types.JSONValue) (types.JSONValue, error) {
+	value, err := n.operand.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return types.NewJSONBool(!truthy(value)), nil
+	}
+	if !value.IsNumber() {
+		return nil, operandError(n.pos, n.op, value)
+	}
+	x, _ := value.AsNumber()
+	return types.NewJSONNumber(-x), nil
+}
+
+// binaryNode 是二元运算，&&和||是短路求值的
+type binaryNode struct {
+	op          string
+	pos         int
+	left, right node
+}
+
+func (n *binaryNode) eval(root types.JSONValue) (types.JSONValue, error) {
+	left, err := n.left.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return types.NewJSONBool(false), nil
+		}
+		right, err := n.right.eval(root)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewJSONBool(truthy(right)), nil
+	case "||":
+		if truthy(left) {
+			return types.NewJSONBool(true), nil
+		}
+		right, err := n.right.eval(root)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewJSONBool(truthy(right)), nil
+	}
+
+	right, err := n.right.eval(root)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return types.NewJSONBool(types.Equal(left, right)), nil
+	case "!=":
+		return types.NewJSONBool(!types.Equal(left, right)), nil
+	case "<", "<=", ">", ">=":
+		return n.compare(left, right)
+	case "+":
+		if left.IsString() || right.IsString() {
+			return types.NewJSONString(stringify(left) + stringify(right)), nil
+		}
+	}
+	return n.arithmetic(left, right)
+}
+
+// compare 比较两个数字或两个字符串
+func (n *binaryNode) compare(left, right types.JSONValue) (types.JSONValue, error) {
+	var c int
+	switch {
+	case left.IsNumber() && right.IsNumber():
+		x, _ := left.AsNumber()
+		y, _ := right.AsNumber()
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	case left.IsString() && right.IsString():
+		x, _ := left.AsString()
+		y, _ := right.AsString()
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	default:
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType,
+			fmt.Sprintf("第%d个字符处: 不能用%s比较%s和%s", n.pos+1, n.op, left.Type(), right.Type()))
+	}
+
+	var result bool
+	switch n.op {
+	case "<":
+		result = c < 0
+	case "<=":
+		result = c <= 0
+	case ">":
+		result = c > 0
+	case ">=":
+		result = c >= 0
+	}
+	return types.NewJSONBool(result), nil
+}
+
+// arithmetic 对两个数字进行算术运算
+func (n *binaryNode) arithmetic(left, right types.JSONValue) (types.JSONValue, error) {
+	if !left.IsNumber() {
+		return nil, operandError(n.pos, n.op, left)
+	}
+	if !right.IsNumber() {
+		return nil, operandError(n.pos, n.op, right)
+	}
+	x, _ := left.AsNumber()
+	y, _ := right.AsNumber()
+
+	var result float64
+	switch n.op {
+	case "+":
+		result = x + y
+	case "-":
+		result = x - y
+	case "*":
+		result = x * y
+	case "/", "%":
+		if y == 0 {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("第%d个字符处: 除数为0", n.pos+1))
+		}
+		if n.op == "/" {
+			result = x / y
+		} else {
+			result = math.Mod(x, y)
+		}
+	}
+	return types.NewJSONNumber(result), nil
+}
+
+// operandError 返回运算符不能用于某种类型的值的错误
+func operandError(pos int, op string, value types.JSONValue) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType,
+		fmt.Sprintf("第%d个字符处: %s不能用于%s", pos+1, op, value.Type()))
+}
+
+// truthy 判断值在条件中是否为真：null、false、0和空字符串为假，其他值为真
+func truthy(value types.JSONValue) bool {
+	switch {
+	case value.IsNull():
+		return false
+	case value.IsBoolean():
+		b, _ := value.AsBoolean()
+		return b
+	case value.IsNumber():
+		x, _ := value.AsNumber()
+		return x != 0
+	case value.IsString():
+		s, _ := value.AsString()
+		return s != ""
+	}
+	return true
+}
+
+// stringify 返回拼接字符串时值的文本：字符串不带引号，数字不使用科学计数法，其他值使用JSON文本
+func stringify(value types.JSONValue) string {
+	switch {
+	case value.IsString():
+		s, _ := value.AsString()
+		return s
+	case value.IsNumber():
+		x, _ := value.AsNumber()
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return value.String()
+}
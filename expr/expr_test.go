@@ -0,0 +1,166 @@
+package expr
+
+import (
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+const order = `{
+	"price": 12.5,
+	"qty": 4,
+	"name": "widget",
+	"tags": ["a", "b"],
+	"customer": {"first": "Ada", "last": "Lovelace", "vip": true},
+	"unit price": 3,
+	"empty": ""
+}`
+
+func TestEval(t *testing.T) {
+	root := parser.MustParse(order)
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`$.price * $.qty`, `50`},
+		{`1 + 2 * 3`, `7`},
+		{`(1 + 2) * 3`, `9`},
+		{`10 - 4 - 3`, `3`},
+		{`7 % 4`, `3`},
+		{`-$.qty + 1`, `-3`},
+		{`1.5e2 / 3`, `50`},
+		{`$.customer.first + ' ' + $.customer.last`, `"Ada Lovelace"`},
+		{`"qty: " + $.qty`, `"qty: 4"`},
+		{`$.name + "-" + true`, `"widget-true"`},
+		{`"it's" + ' "quoted"'`, `"it's \"quoted\""`},
+		{`'a\'b\n'`, `"a'b\n"`},
+		{`$['unit price'] * 2`, `6`},
+		{`$.tags[1]`, `"b"`},
+		{`$.tags[*]`, `["a","b"]`},
+		{`$.missing`, `null`},
+		{`$.name.length`, `null`},
+		{`$..first`, `"Ada"`},
+		{`$.tags.length() * 2`, `4`},
+		{`$.customer.length() + 1`, `4`},
+		{`$.tags[?(@ == 'b')]`, `"b"`},
+		{`$..[0] + "!"`, `"a!"`},
+		{`$`, `{"customer":{"first":"Ada","last":"Lovelace","vip":true},"empty":"","name":"widget","price":12.5,"qty":4,"tags":["a","b"],"unit price":3}`},
+		{`$.qty > 3 ? "bulk" : "single"`, `"bulk"`},
+		{`$.qty > 10 ? "large" : $.qty > 3 ? "medium" : "small"`, `"medium"`},
+		{`$.customer.vip && $.qty >= 4`, `true`},
+		{`$.missing || $.empty`, `false`},
+		{`!$.empty`, `true`},
+		{`$.name == "widget" && $.tags == $.tags`, `true`},
+		{`$.name != 'widget'`, `false`},
+		{`"abc" < "abd"`, `true`},
+		{`$.missing == null`, `true`},
+		{`1 == "1"`, `false`},
+		{`$.missing ? 1 / 0 : 2`, `2`},
+		{`false && 1 / 0`, `false`},
+	}
+	for _, tt := range tests {
+		got, err := Eval(tt.src, root)
+		if err != nil {
+			t.Errorf("Eval(%s) error = %v", tt.src, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Eval(%s) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestEvalExactNumbers(t *testing.T) {
+	big1, _ := types.ParseJSONBigInt("9007199254740992")
+	big2, _ := types.ParseJSONBigInt("9007199254740993")
+	root := types.NewJSONObject()
+	root.Put("a", big1)
+	root.Put("b", big2)
+	root.Put("c", types.NewJSONNumber(9007199254740992))
+	root.Put("list", types.NewJSONArray().Add(big2))
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`$.a == $.b`, `false`},
+		{`$.a != $.b`, `true`},
+		{`$.a == $.c`, `true`},
+		{`$.b == $.c`, `false`},
+		{`$.list == $.list`, `true`},
+	}
+	for _, tt := range tests {
+		got, err := Eval(tt.src, root)
+		if err != nil {
+			t.Fatalf("Eval(%s) error = %v", tt.src, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("Eval(%s) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`1 +`,
+		`(1 + 2`,
+		`1 2`,
+		`price * 2`,
+		`"unterminated`,
+		`$.`,
+		`$.tags[0`,
+		`$..`,
+		`$.tags.size()`,
+		`$.a ? 1`,
+		`1 # 2`,
+		`$.a = 1`,
+	}
+	for _, src := range tests {
+		_, err := Compile(src)
+		if err == nil {
+			t.Errorf("Compile(%q) should fail", src)
+			continue
+		}
+		if jsonErr, ok := err.(*jsonerrors.JSONError); !ok ||
+			jsonErr.Code != jsonerrors.ErrInvalidExpression && jsonErr.Code != jsonerrors.ErrEmptyInput {
+			t.Errorf("Compile(%q) error = %v, want an expression error", src, err)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	root := parser.MustParse(order)
+	tests := []string{
+		`$.price / 0`,
+		`$.missing * 2`,
+		`-$.name`,
+		`$.name - 1`,
+		`$.qty < "5"`,
+		`$.tags > 1`,
+	}
+	for _, src := range tests {
+		if got, err := Eval(src, root); err == nil {
+			t.Errorf("Eval(%s) = %v, want an error", src, got)
+		}
+	}
+}
+
+func TestExprString(t *testing.T) {
+	e := MustCompile(`$.price  *  2`)
+	if e.String() != `$.price  *  2` {
+		t.Errorf("String() = %q", e.String())
+	}
+	// 编译后的表达式可以对不同的文档求值
+	for doc, want := range map[string]float64{`{"price": 1}`: 2, `{"price": 2.5}`: 5} {
+		got, err := e.Eval(parser.MustParse(doc))
+		if err != nil {
+			t.Fatalf("Eval() error = %v", err)
+		}
+		if n, _ := got.AsNumber(); n != want {
+			t.Errorf("Eval(%s) = %v, want %v", doc, got, want)
+		}
+	}
+}
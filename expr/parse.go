@@ -0,0 +1,312 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// tokenKind 是词法单元的类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenPath
+	tokenIdent
+	tokenOperator
+)
+
+// token 是表达式中的一个词法单元
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value types.JSONValue // 数字和字符串字面量的值
+}
+
+// operators 是所有运算符，较长的运算符排在前面以便优先匹配
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")"}
+
+// syntaxError 返回表达式在pos处的语法错误
+func syntaxError(pos int, format string, args ...interface{}) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("第%d个字符处: %s", pos+1, fmt.Sprintf(format, args...)))
+}
+
+// tokenize 把表达式切分为词法单元
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(src) {
+		c := src[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c >= '0' && c <= '9' || c == '.' && pos+1 < len(src) && src[pos+1] >= '0' && src[pos+1] <= '9':
+			tok, err := scanNumber(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case c == '"' || c == '\'':
+			tok, err := scanString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case c == '$':
+			end, err := scanPath(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenPath, text: src[pos:end], pos: pos})
+			pos = end
+		case isIdentStart(c):
+			end := pos + 1
+			for end < len(src) && isIdentPart(src[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[pos:end], pos: pos})
+			pos = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[pos:])
+				return nil, syntaxError(pos, "无法识别的字符%q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// scanNumber 读取从pos开始的数字字面量
+func scanNumber(src string, pos int) (token, error) {
+	end := pos
+	for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.') {
+		end++
+	}
+	if end < len(src) && (src[end] == 'e' || src[end] == 'E') {
+		exp := end + 1
+		if exp < len(src) && (src[exp] == '+' || src[exp] == '-') {
+			exp++
+		}
+		if exp < len(src) && src[exp] >= '0' && src[exp] <= '9' {
+			for end = exp; end < len(src) && src[end] >= '0' && src[end] <= '9'; end++ {
+			}
+		}
+	}
+	text := src[pos:end]
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, syntaxError(pos, "无效的数字%q", text)
+	}
+	return token{kind: tokenNumber, text: text, pos: pos, value: types.NewJSONNumber(n)}, nil
+}
+
+// scanString 读取从pos开始的字符串字面量，支持单引号和双引号，转义规则与Go的双引号字符串相同
+func scanString(src string, pos int) (token, error) {
+	quote := src[pos]
+	end := pos + 1
+	for end < len(src) && src[end] != quote {
+		if src[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(src) {
+		return token{}, syntaxError(pos, "字符串没有结束")
+	}
+	text := src[pos : end+1]
+
+	body := text[1 : len(text)-1]
+	if quote == '\'' {
+		// 单引号字符串中的\'表示单引号，双引号不需要转义
+		body = strings.ReplaceAll(body, `\'`, `'`)
+		body = strings.ReplaceAll(body, `"`, `\"`)
+	}
+	s, err := strconv.Unquote(`"` + body + `"`)
+	if err != nil {
+		return token{}, syntaxError(pos, "无效的字符串%s", text)
+	}
+	return token{kind: tokenString, text: text, pos: pos, value: types.NewJSONString(s)}, nil
+}
+
+// scanPath 返回从pos处的$开始的JSON Path的结束位置。
+// 路径的语法由jsonpath包决定，支持..、通配符、过滤器和末尾的聚合函数
+func scanPath(src string, pos int) (int, error) {
+	end, err := jsonpath.ScanJSONPath(src, pos)
+	if err != nil {
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("第%d个字符处: 无效的路径", pos+1)).WithCause(err)
+	}
+	return end, nil
+}
+
+// exprParser 是递归下降的语法分析器
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+// peek 返回当前的词法单元
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// accept 在当前词法单元是运算符op时前进并返回true
+func (p *exprParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect 要求当前词法单元是运算符op
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return p.unexpected("缺少" + op)
+	}
+	return nil
+}
+
+// unexpected 返回当前词法单元处的语法错误
+func (p *exprParser) unexpected(reason string) error {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return syntaxError(tok.pos, "表达式意外结束，%s", reason)
+	}
+	return syntaxError(tok.pos, "意外的%q，%s", tok.text, reason)
+}
+
+// parseExpression 解析条件表达式 cond ? a : b，条件表达式是右结合的
+func (p *exprParser) parseExpression() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// precedence 是二元运算符的优先级，数字越大结合越紧
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// parseBinary 按优先级解析左结合的二元运算，只处理优先级高于minPrec的运算符
+func (p *exprParser) parseBinary(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != tokenOperator || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseBinary(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: tok.text, pos: tok.pos, left: left, right: right}
+	}
+}
+
+// parseUnary 解析取负和逻辑非
+func (p *exprParser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "-" || tok.text == "!") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: tok.text, pos: tok.pos, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析字面量、路径引用和括号中的表达式
+func (p *exprParser) parsePrimary() (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		p.pos++
+		return &literalNode{value: tok.value}, nil
+	case tokenPath:
+		p.pos++
+		path, err := jsonpath.ParseJSONPath(tok.text)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidExpression, fmt.Sprintf("第%d个字符处: 无效的路径%s", tok.pos+1, tok.text)).WithCause(err)
+		}
+		return &pathNode{path: path}, nil
+	case tokenIdent:
+		p.pos++
+		switch tok.text {
+		case "true":
+			return &literalNode{value: types.NewJSONBool(true)}, nil
+		case "false":
+			return &literalNode{value: types.NewJSONBool(false)}, nil
+		case "null":
+			return &literalNode{value: types.NewJSONNull()}, nil
+		}
+		return nil, syntaxError(tok.pos, "未知的名称%q，引用字段请使用$.%s", tok.text, tok.text)
+	case tokenOperator:
+		if tok.text == "(" {
+			p.pos++
+			inner, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.unexpected("需要一个值")
+}
@@ -301,3 +301,77 @@ func BenchmarkSimpleTypeStandardUnmarshal(b *testing.B) {
 		})
 	}
 }
+
+// crossoverSizes 是比较快速路径和encoding/json时使用的元素个数
+var crossoverSizes = []int{1, 2, 4, 8, 16, 32, 64, 128}
+
+// crossoverValues 生成不同类型的元素值
+var crossoverValues = []struct {
+	name  string
+	value func(i int) interface{}
+}{
+	{"Int", func(i int) interface{} { return i * 7 }},
+	{"Float", func(i int) interface{} { return float64(i) + 0.25 }},
+	{"String", func(i int) interface{} { return fmt.Sprintf("value-%d", i) }},
+	{"Nested", func(i int) interface{} { return map[string]interface{}{"id": i, "ok": true} }},
+}
+
+// BenchmarkSmallMapCrossover 比较不同大小和值类型的map使用快速路径与encoding/json的性能，
+// 用于确定DefaultSmallMapThreshold
+func BenchmarkSmallMapCrossover(b *testing.B) {
+	opts := DefaultOptions()
+	for _, vt := range crossoverValues {
+		for _, n := range crossoverSizes {
+			m := make(map[string]interface{}, n)
+			for i := 0; i < n; i++ {
+				m[fmt.Sprintf("key%03d", i)] = vt.value(i)
+			}
+			b.Run(fmt.Sprintf("%s/%d/Fast", vt.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := marshalSmallMap(m, opts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("%s/%d/Std", vt.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := encodeStd(m, false); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSmallArrayCrossover 比较不同大小和值类型的数组使用快速路径与encoding/json的性能，
+// 用于确定DefaultSmallArrayThreshold
+func BenchmarkSmallArrayCrossover(b *testing.B) {
+	opts := DefaultOptions()
+	for _, vt := range crossoverValues {
+		for _, n := range crossoverSizes {
+			arr := make([]interface{}, n)
+			for i := range arr {
+				arr[i] = vt.value(i)
+			}
+			b.Run(fmt.Sprintf("%s/%d/Fast", vt.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := marshalSmallArray(arr, opts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("%s/%d/Std", vt.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := encodeStd(arr, false); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build gojson_nounsafe
+
+package fast
+
+// bytesToString 将[]byte复制为string，是不使用unsafe的实现。
+func bytesToString(b []byte) string {
+	return string(b)
+}
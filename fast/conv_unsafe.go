@@ -0,0 +1,15 @@
+//go:build !gojson_nounsafe
+
+package fast
+
+import "unsafe"
+
+// bytesToString 将[]byte转换为string，不复制内存。
+// 返回的字符串与b共享内存，只能在b不再被修改的期间作为临时值使用（例如传给strconv或比较），
+// 不能保存或返回给调用方。使用gojson_nounsafe构建标签时改为复制。
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
@@ -2,8 +2,11 @@ package fast
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
 )
 
 // TestMarshal 测试Marshal函数
@@ -182,6 +185,215 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+// textKey 是实现了encoding.TextMarshaler的map键
+type textKey struct{ a, b string }
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(k.a + "/" + k.b), nil
+}
+
+// TestMarshalMapKeys 测试键不是字符串的map直接按排序后的键序列化
+func TestMarshalMapKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{"整数键", map[int]interface{}{10: "b", 2: "a", -1: []int{1}}, `{"-1":[1],"10":"b","2":"a"}`},
+		{"无符号整数键", map[uint64]float64{18446744073709551615: 1.5}, `{"18446744073709551615":1.5}`},
+		{"TextMarshaler键", map[textKey]int{{"x", "2"}: 2, {"x", "1"}: 1}, `{"x/1":1,"x/2":2}`},
+		{"字符串键的其他值类型", map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{"嵌套", map[int]map[int]bool{1: {2: true}}, `{"1":{"2":true}}`},
+		{"nil map", map[int]int(nil), `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarshalDeterministic 测试小型map按键排序并且多次序列化的结果相同
+func TestMarshalDeterministic(t *testing.T) {
+	input := map[string]interface{}{
+		"h": 1, "g": 2, "f": 3, "e": 4, "d": 5,
+		"c": map[string]interface{}{"z": true, "y": false, "x": nil},
+		"b": []interface{}{"s"}, "a": "v",
+	}
+	want := `{"a":"v","b":["s"],"c":{"x":null,"y":false,"z":true},"d":5,"e":4,"f":3,"g":2,"h":1}`
+
+	for i := 0; i < 50; i++ {
+		got, err := Marshal(input)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("第%d次 Marshal() = %s, want %s", i+1, got, want)
+		}
+	}
+
+	old := SetSortMapKeys(false)
+	defer SetSortMapKeys(old)
+	if !old {
+		t.Errorf("SetSortMapKeys() 返回 %v, 默认应该按键排序", old)
+	}
+	if SortMapKeys() {
+		t.Errorf("SortMapKeys() = true, want false")
+	}
+	got, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var gotObj, wantObj interface{}
+	_ = json.Unmarshal(got, &gotObj)
+	_ = json.Unmarshal([]byte(want), &wantObj)
+	if !reflect.DeepEqual(gotObj, wantObj) {
+		t.Errorf("不排序时 Marshal() = %s, want %s", got, want)
+	}
+}
+
+// TestMarshalWithOptions 测试MarshalWithOptions的HTML转义、缩进和排序选项
+func TestMarshalWithOptions(t *testing.T) {
+	input := map[string]interface{}{
+		"b":    []interface{}{1, "<x>"},
+		"a&b":  "</script>",
+		"list": []string{"<", ">"},
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "默认",
+			opts: DefaultOptions(),
+			want: `{"a&b":"</script>","b":[1,"<x>"],"list":["<",">"]}`,
+		},
+		{
+			name: "转义HTML",
+			opts: Options{EscapeHTML: true, SortKeys: true},
+			want: `{"a\u0026b":"\u003c/script\u003e","b":[1,"\u003cx\u003e"],"list":["\u003c","\u003e"]}`,
+		},
+		{
+			name: "缩进",
+			opts: Options{Indent: "  ", SortKeys: true},
+			want: "{\n  \"a&b\": \"</script>\",\n  \"b\": [\n    1,\n    \"<x>\"\n  ],\n  \"list\": [\n    \"<\",\n    \">\"\n  ]\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalWithOptions(input, tt.opts)
+			if err != nil {
+				t.Fatalf("MarshalWithOptions() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalWithOptions() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// 转义HTML时与encoding/json的默认输出相同
+	want, _ := json.Marshal(input)
+	got, err := MarshalWithOptions(input, Options{EscapeHTML: true, SortKeys: true})
+	if err != nil || string(got) != string(want) {
+		t.Errorf("MarshalWithOptions() = %s, %v, want %s", got, err, want)
+	}
+}
+
+// TestSmallThresholds 测试调整快速路径阈值不改变输出
+func TestSmallThresholds(t *testing.T) {
+	if SmallMapThreshold() != DefaultSmallMapThreshold || SmallArrayThreshold() != DefaultSmallArrayThreshold {
+		t.Fatalf("默认阈值 = %d, %d", SmallMapThreshold(), SmallArrayThreshold())
+	}
+	input := map[string]interface{}{
+		"list": []interface{}{1, "a&b", 2.5, map[string]interface{}{"y": nil, "x": true}},
+		"name": "<gojson>",
+	}
+	for _, opts := range []Options{DefaultOptions(), {EscapeHTML: true, SortKeys: true}} {
+		want, err := MarshalWithOptions(input, opts)
+		if err != nil {
+			t.Fatalf("MarshalWithOptions() error = %v", err)
+		}
+		for _, n := range []int{0, 1, 3, 1000} {
+			oldMap := SetSmallMapThreshold(n)
+			oldArray := SetSmallArrayThreshold(n)
+			got, err := MarshalWithOptions(input, opts)
+			SetSmallMapThreshold(oldMap)
+			SetSmallArrayThreshold(oldArray)
+			if err != nil {
+				t.Fatalf("阈值%d: MarshalWithOptions() error = %v", n, err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("阈值%d: MarshalWithOptions() = %s, want %s", n, got, want)
+			}
+		}
+	}
+}
+
+// TestMarshalUnsupportedType 测试不能序列化的值在快速路径和encoding/json路径中报告相同的错误
+func TestMarshalUnsupportedType(t *testing.T) {
+	type job struct {
+		ID  int          `json:"id"`
+		Run func() error `json:"run"`
+	}
+	tests := []struct {
+		name  string
+		input interface{}
+		path  string
+	}{
+		{"小型map", map[string]interface{}{"a": 1, "b": complex(1, 2)}, "$.b"},
+		{"小型数组", []interface{}{"x", make(chan int)}, "$[1]"},
+		{"结构体", []job{{ID: 1}}, "$[0].run"},
+		{"整数键的map", map[int]job{7: {}}, `$["7"].run`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, threshold := range []int{0, DefaultSmallMapThreshold} {
+				oldMap := SetSmallMapThreshold(threshold)
+				oldArray := SetSmallArrayThreshold(threshold)
+				_, err := Marshal(tt.input)
+				SetSmallMapThreshold(oldMap)
+				SetSmallArrayThreshold(oldArray)
+
+				var jsonErr *jsonerrors.JSONError
+				if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrUnsupportedType || jsonErr.Path != tt.path {
+					t.Errorf("阈值%d: Marshal() error = %v, want %s (path: %s)", threshold, err, jsonerrors.ErrUnsupportedType, tt.path)
+				}
+			}
+		})
+	}
+}
+
+// TestMarshalResultNotAliased 测试修改Marshal的结果不会影响之后的调用
+func TestMarshalResultNotAliased(t *testing.T) {
+	for _, v := range []interface{}{7, int64(42), 123456, 1.5} {
+		first, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", v, err)
+		}
+		want := string(first)
+		for i := range first {
+			first[i] = 'x'
+		}
+		second, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", v, err)
+		}
+		if string(second) != want {
+			t.Errorf("修改结果后 Marshal(%v) = %s, want %s", v, second, want)
+		}
+	}
+	if digitStrings[7] != "7" {
+		t.Errorf("digitStrings[7] = %q, want \"7\"", digitStrings[7])
+	}
+}
+
 // TestFragmentCache 测试片段缓存功能
 func TestFragmentCache(t *testing.T) {
 	// 清空缓存，确保测试环境干净
@@ -3,10 +3,14 @@ package fast
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"sync"
-	"unsafe"
+	"sync/atomic"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
 )
@@ -58,6 +62,61 @@ func init() {
 	}
 }
 
+// 小型map和数组快速路径的默认阈值，由BenchmarkSmallMapCrossover和BenchmarkSmallArrayCrossover测得，
+// 见benchmarks/RESULTS.md。快速路径在测量的范围内都更快，阈值取分配的内存开始超过encoding/json的位置。
+const (
+	DefaultSmallMapThreshold   = 32
+	DefaultSmallArrayThreshold = 64
+)
+
+// smallMapThreshold和smallArrayThreshold 是当前的快速路径阈值
+var (
+	smallMapThreshold   int64 = DefaultSmallMapThreshold
+	smallArrayThreshold int64 = DefaultSmallArrayThreshold
+)
+
+// SetSmallMapThreshold 设置map[string]interface{}使用快速路径的阈值并返回原来的阈值。
+// 元素个数小于n的map逐个序列化元素，其他map交给encoding/json；n不大于0时不使用快速路径
+func SetSmallMapThreshold(n int) int {
+	return int(atomic.SwapInt64(&smallMapThreshold, int64(n)))
+}
+
+// SmallMapThreshold 返回当前map使用快速路径的阈值
+func SmallMapThreshold() int {
+	return int(atomic.LoadInt64(&smallMapThreshold))
+}
+
+// SetSmallArrayThreshold 设置[]interface{}使用快速路径的阈值并返回原来的阈值。
+// 元素个数小于n的数组逐个序列化元素，其他数组交给encoding/json；n不大于0时不使用快速路径
+func SetSmallArrayThreshold(n int) int {
+	return int(atomic.SwapInt64(&smallArrayThreshold, int64(n)))
+}
+
+// SmallArrayThreshold 返回当前数组使用快速路径的阈值
+func SmallArrayThreshold() int {
+	return int(atomic.LoadInt64(&smallArrayThreshold))
+}
+
+// unsortedMapKeys 为1时marshalSmallMap按Go的迭代顺序输出键，默认为0（按键排序）。
+var unsortedMapKeys int32
+
+// SetSortMapKeys 设置Marshal是否按键排序输出map[string]interface{}并返回原来的设置。
+// 默认按键排序：相同的输入每次得到相同的输出，与encoding/json一致，可以用于计算内容哈希。
+// 关闭后小型map按Go的迭代顺序输出，省去排序的开销，但输出不再确定；
+// 交给encoding/json序列化的值总是按键排序。
+func SetSortMapKeys(sorted bool) bool {
+	var v int32
+	if !sorted {
+		v = 1
+	}
+	return atomic.SwapInt32(&unsortedMapKeys, v) == 0
+}
+
+// SortMapKeys 返回Marshal是否按键排序输出map。
+func SortMapKeys() bool {
+	return atomic.LoadInt32(&unsortedMapKeys) == 0
+}
+
 // fastItoa 快速获取数字的字符串表示。
 func fastItoa(i int) string {
 	if i >= 0 && i < 1000 {
@@ -66,25 +125,52 @@ func fastItoa(i int) string {
 	return strconv.Itoa(i)
 }
 
-// bytesToString 安全地将[]byte转换为string，不需要内存复制。
-// 注意：返回的字符串不应该在输入字节切片被修改后使用。
-func bytesToString(b []byte) string {
-	return *(*string)(unsafe.Pointer(&b))
+// Options 是MarshalWithOptions的选项。
+type Options struct {
+	// EscapeHTML 为true时把字符串中的<、>和&转义为\u003c、\u003e和\u0026，
+	// 输出可以直接嵌入HTML的<script>标签。
+	EscapeHTML bool
+	// Indent 不为空时使用它作为每一级的缩进格式化输出。
+	Indent string
+	// SortKeys 为true时按键排序输出map[string]interface{}，输出是确定的。
+	// 为false时小型map按Go的迭代顺序输出；交给encoding/json序列化的值总是按键排序。
+	SortKeys bool
 }
 
-// stringToBytes 安全地将string转换为[]byte，不需要内存复制。
-// 注意：返回的字节切片不应该被修改。
-func stringToBytes(s string) []byte {
-	return *(*[]byte)(unsafe.Pointer(
-		&struct {
-			string
-			Cap int
-		}{s, len(s)},
-	))
+// DefaultOptions 返回Marshal使用的选项：不转义HTML、不缩进，是否按键排序由SortMapKeys决定。
+func DefaultOptions() Options {
+	return Options{SortKeys: SortMapKeys()}
 }
 
-// Marshal 是一个优化的JSON序列化函数。
+// Marshal 是一个优化的JSON序列化函数，使用DefaultOptions。
 func Marshal(v interface{}) ([]byte, error) {
+	return MarshalWithOptions(v, DefaultOptions())
+}
+
+// MarshalWithOptions 按指定的选项序列化v，需要转义HTML或格式化输出时不必退回encoding/json。
+func MarshalWithOptions(v interface{}, opts Options) ([]byte, error) {
+	data, err := marshalValue(v, opts)
+	if err != nil {
+		// 通道、函数、复数等不能序列化的值统一报告其类型和路径。
+		if uerr := jsonerrors.UnsupportedTypeError(v, err); uerr != nil {
+			return nil, uerr
+		}
+		return nil, err
+	}
+	if opts.Indent == "" {
+		return data, nil
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data) * 2)
+	if err := json.Indent(&out, data, "", opts.Indent); err != nil {
+		return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "格式化失败").WithCause(err)
+	}
+	return out.Bytes(), nil
+}
+
+// marshalValue 按opts紧凑地序列化v，不处理Indent。
+func marshalValue(v interface{}, opts Options) ([]byte, error) {
 	// 对于nil值，直接返回"null"。
 	if v == nil {
 		return []byte("null"), nil
@@ -94,51 +180,66 @@ func Marshal(v interface{}) ([]byte, error) {
 	switch val := v.(type) {
 	case string:
 		// 字符串需要特殊处理，添加引号和转义。
-		return marshalString(val)
+		return marshalString(val, opts.EscapeHTML)
 	case bool:
 		if val {
 			return []byte("true"), nil
 		}
 		return []byte("false"), nil
+	// 返回的切片总是新分配的，调用方可以修改，不会影响digitStrings。
 	case int:
 		if val >= 0 && val < 1000 {
-			return stringToBytes(digitStrings[val]), nil
+			return []byte(digitStrings[val]), nil
 		}
-		return stringToBytes(strconv.FormatInt(int64(val), 10)), nil
+		return strconv.AppendInt(nil, int64(val), 10), nil
 	case int64:
 		if val >= 0 && val < 1000 {
-			return stringToBytes(digitStrings[val]), nil
+			return []byte(digitStrings[val]), nil
 		}
-		return stringToBytes(strconv.FormatInt(val, 10)), nil
+		return strconv.AppendInt(nil, val, 10), nil
 	case float64:
-		return stringToBytes(strconv.FormatFloat(val, 'f', -1, 64)), nil
+		return strconv.AppendFloat(nil, val, 'f', -1, 64), nil
 	case []byte:
 		// 对于[]byte，我们需要base64编码，使用标准库。
 		return json.Marshal(val)
 	case map[string]interface{}:
 		// 对于小型map，使用优化的方法。
-		if len(val) < 10 {
-			return marshalSmallMap(val)
+		if int64(len(val)) < atomic.LoadInt64(&smallMapThreshold) {
+			return marshalSmallMap(val, opts)
 		}
 	case []interface{}:
 		// 对于小型数组，使用优化的方法。
-		if len(val) < 10 {
-			return marshalSmallArray(val)
+		if int64(len(val)) < atomic.LoadInt64(&smallArrayThreshold) {
+			return marshalSmallArray(val, opts)
 		}
+	default:
+		// 键是整数或实现了encoding.TextMarshaler的map直接按排序后的键序列化。
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && isDirectMap(v, rv.Type()) {
+			return marshalMap(rv, opts)
+		}
+	}
+
+	result, err := encodeStd(v, opts.EscapeHTML)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "序列化失败").WithCause(err)
 	}
+	return result, nil
+}
 
+// encodeStd 使用encoding/json序列化v，escapeHTML决定是否转义HTML字符。
+func encodeStd(v interface{}, escapeHTML bool) ([]byte, error) {
 	// 获取缓冲区。
 	buf := getBuffer()
 	defer releaseBuffer(buf)
 
 	// 创建一个新的编码器。
 	enc := json.NewEncoder(buf)
-	enc.SetEscapeHTML(false) // 避免HTML转义，保持JSON原样。
-	enc.SetIndent("", "")    // 默认不缩进。
+	enc.SetEscapeHTML(escapeHTML)
+	enc.SetIndent("", "") // 不缩进，缩进由MarshalWithOptions统一处理。
 
 	// 编码数据。
 	if err := enc.Encode(v); err != nil {
-		return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "序列化失败").WithCause(err)
+		return nil, err
 	}
 
 	// 获取缓冲区内容。
@@ -156,8 +257,8 @@ func Marshal(v interface{}) ([]byte, error) {
 	return result, nil
 }
 
-// marshalString 将字符串转换为JSON字符串。
-func marshalString(s string) ([]byte, error) {
+// marshalString 将字符串转换为JSON字符串，escapeHTML决定是否转义HTML字符。
+func marshalString(s string, escapeHTML bool) ([]byte, error) {
 	// 快速路径：空字符串。
 	if s == "" {
 		return []byte(`""`), nil
@@ -171,6 +272,10 @@ func marshalString(s string) ([]byte, error) {
 			needEscape = true
 			break
 		}
+		if escapeHTML && (s[i] == '<' || s[i] == '>' || s[i] == '&') {
+			needEscape = true
+			break
+		}
 	}
 
 	// 如果不需要转义，直接添加引号。
@@ -183,11 +288,11 @@ func marshalString(s string) ([]byte, error) {
 	}
 
 	// 需要转义，使用标准库。
-	return json.Marshal(s)
+	return encodeStd(s, escapeHTML)
 }
 
-// marshalSmallMap 优化小型map的序列化。
-func marshalSmallMap(m map[string]interface{}) ([]byte, error) {
+// marshalSmallMap 优化小型map的序列化，是否按键排序由opts.SortKeys决定。
+func marshalSmallMap(m map[string]interface{}, opts Options) ([]byte, error) {
 	if len(m) == 0 {
 		return []byte("{}"), nil
 	}
@@ -198,28 +303,147 @@ func marshalSmallMap(m map[string]interface{}) ([]byte, error) {
 	buf.WriteByte('{')
 	first := true
 
-	for k, v := range m {
+	writeEntry := func(k string, v interface{}) error {
 		if !first {
 			buf.WriteByte(',')
 		}
 		first = false
 
 		// 写入键。
-		keyBytes, err := marshalString(k)
+		keyBytes, err := marshalString(k, opts.EscapeHTML)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		buf.Write(keyBytes)
 		buf.WriteByte(':')
 
 		// 写入值。
-		valBytes, err := Marshal(v)
+		valBytes, err := marshalValue(v, opts)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		buf.Write(valBytes)
+		return nil
 	}
 
+	if opts.SortKeys {
+		// 按键排序，保证输出确定。
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := writeEntry(k, m[k]); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for k, v := range m {
+			if err := writeEntry(k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf.WriteByte('}')
+
+	// 复制结果。
+	result := make([]byte, buf.Len())
+	copy(result, buf.Bytes())
+
+	return result, nil
+}
+
+// textMarshalerType 是encoding.TextMarshaler的反射类型。
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// isDirectMap 检查map是否可以由marshalMap序列化：map本身没有自定义的序列化方法，
+// 键是字符串、整数或实现了encoding.TextMarshaler。map[string]interface{}仍然使用原来的路径。
+func isDirectMap(v interface{}, t reflect.Type) bool {
+	switch v.(type) {
+	case json.Marshaler, encoding.TextMarshaler, map[string]interface{}:
+		return false
+	}
+	key := t.Key()
+	switch key.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return key.Implements(textMarshalerType)
+}
+
+// mapKeyString 按encoding/json的规则把map的键转换为字符串：
+// 字符串类型的键直接使用，其次是encoding.TextMarshaler，最后是整数。
+func mapKeyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if key.Kind() == reflect.Pointer && key.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", jsonerrors.NewJSONError(ErrInvalidJSON,
+				fmt.Sprintf("序列化map的键失败: %v", key.Interface())).WithCause(err)
+		}
+		return string(text), nil
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "不支持的map键类型: "+key.Type().String())
+}
+
+// marshalMap 序列化键是字符串、整数或encoding.TextMarshaler的map，键按转换后的字符串排序，
+// 与encoding/json的输出相同。
+func marshalMap(rv reflect.Value, opts Options) ([]byte, error) {
+	if rv.IsNil() {
+		return []byte("null"), nil
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := mapKeyString(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyBytes, err := marshalString(e.key, opts.EscapeHTML)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+
+		valBytes, err := marshalValue(e.value.Interface(), opts)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(valBytes)
+	}
 	buf.WriteByte('}')
 
 	// 复制结果。
@@ -230,7 +454,7 @@ func marshalSmallMap(m map[string]interface{}) ([]byte, error) {
 }
 
 // marshalSmallArray 优化小型数组的序列化。
-func marshalSmallArray(arr []interface{}) ([]byte, error) {
+func marshalSmallArray(arr []interface{}, opts Options) ([]byte, error) {
 	if len(arr) == 0 {
 		return []byte("[]"), nil
 	}
@@ -246,7 +470,7 @@ func marshalSmallArray(arr []interface{}) ([]byte, error) {
 		}
 
 		// 写入值。
-		valBytes, err := Marshal(v)
+		valBytes, err := marshalValue(v, opts)
 		if err != nil {
 			return nil, err
 		}
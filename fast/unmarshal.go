@@ -4,6 +4,7 @@ package fast
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"strconv"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
@@ -56,6 +57,11 @@ func Unmarshal(data []byte, v interface{}) error {
 			// 返回原始错误，它通常更有信息。
 			return jsonerrors.NewJSONError(ErrInvalidJSON, "反序列化失败").WithCause(err)
 		}
+
+		// Decoder只读取第一个值，第一个值之后还有内容时输入不是一个JSON文本。
+		if _, tokErr := dec.Token(); tokErr != io.EOF {
+			return jsonerrors.NewJSONError(ErrInvalidJSON, "反序列化失败").WithCause(err)
+		}
 	}
 
 	return nil
@@ -123,7 +129,7 @@ func unmarshalInt(data []byte, target *int) error {
 	}
 
 	// 解析数字。
-	val, err := strconv.Atoi(string(data[start : end+1]))
+	val, err := strconv.Atoi(bytesToString(data[start : end+1]))
 	if err != nil {
 		return jsonerrors.NewJSONError(ErrInvalidJSON, "无效的整数值").WithCause(err)
 	}
@@ -152,7 +158,7 @@ func unmarshalBool(data []byte, target *bool) error {
 	}
 
 	// 解析布尔值。
-	s := string(data[start : end+1])
+	s := bytesToString(data[start : end+1])
 	if s == "true" {
 		*target = true
 		return nil
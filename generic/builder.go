@@ -0,0 +1,93 @@
+package generic
+
+import (
+	"github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Builder builds a JSONObject with a fluent chain of typed puts.
+// The first conversion error is recorded and all later calls become no-ops,
+// so the error only needs to be checked once at the end of the chain.
+type Builder struct {
+	obj *types.JSONObject
+	err error
+}
+
+// NewBuilder creates a new object builder
+func NewBuilder() *Builder {
+	return &Builder{obj: types.NewJSONObject()}
+}
+
+// Put converts value to a JSONValue and sets it for the specified key
+func (b *Builder) Put(key string, value interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+	jsonValue, err := ToJSONValue(value)
+	if err != nil {
+		b.err = wrapBuilderError(err, key)
+		return b
+	}
+	b.obj.Put(key, jsonValue)
+	return b
+}
+
+// Err returns the first error encountered while building
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Object returns the built object and the first error encountered
+func (b *Builder) Object() (*types.JSONObject, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.obj, nil
+}
+
+// ArrayBuilder builds a JSONArray with a fluent chain of typed adds.
+// Like Builder, it records the first error and ignores later calls.
+type ArrayBuilder struct {
+	arr *types.JSONArray
+	err error
+}
+
+// NewArrayBuilder creates a new array builder
+func NewArrayBuilder() *ArrayBuilder {
+	return &ArrayBuilder{arr: types.NewJSONArray()}
+}
+
+// Add converts value to a JSONValue and appends it to the array
+func (b *ArrayBuilder) Add(value interface{}) *ArrayBuilder {
+	if b.err != nil {
+		return b
+	}
+	jsonValue, err := ToJSONValue(value)
+	if err != nil {
+		b.err = wrapBuilderError(err, "")
+		return b
+	}
+	b.arr.Add(jsonValue)
+	return b
+}
+
+// Err returns the first error encountered while building
+func (b *ArrayBuilder) Err() error {
+	return b.err
+}
+
+// Array returns the built array and the first error encountered
+func (b *ArrayBuilder) Array() (*types.JSONArray, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.arr, nil
+}
+
+// wrapBuilderError attaches the key being built to a conversion error
+func wrapBuilderError(err error, key string) error {
+	if key == "" {
+		return err
+	}
+	return errors.NewJSONError(errors.ErrTypeConversion, "failed to convert value").WithPath(key).WithCause(err)
+}
@@ -0,0 +1,63 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestBuilder(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+
+	b := NewBuilder().
+		Put("name", "John").
+		Put("age", 30).
+		Put("address", Address{City: "New York"})
+	obj, err := b.Object()
+	if err != nil {
+		t.Fatalf("Builder.Object() failed: %v", err)
+	}
+	expected := `{"address":{"city":"New York"},"age":30,"name":"John"}`
+	if obj.String() != expected {
+		t.Errorf("Builder result mismatch: expected %s, got %s", expected, obj.String())
+	}
+
+	// The first error is kept and later puts are ignored
+	b = NewBuilder().
+		Put("a", 1).
+		Put("bad", complex(1, 2)).
+		Put("c", 3)
+	if b.Err() == nil {
+		t.Fatalf("Builder.Err() should return error")
+	}
+	jsonErr, ok := b.Err().(*errors.JSONError)
+	if !ok || jsonErr.Path != "bad" {
+		t.Errorf("Builder.Err() should carry the failing key, got %v", b.Err())
+	}
+	if _, err := b.Object(); err == nil {
+		t.Errorf("Builder.Object() should return error")
+	}
+	if b.obj.Has("c") {
+		t.Errorf("Builder should ignore puts after an error")
+	}
+}
+
+func TestArrayBuilder(t *testing.T) {
+	arr, err := NewArrayBuilder().Add("a").Add(1).Add([]int{2, 3}).Array()
+	if err != nil {
+		t.Fatalf("ArrayBuilder.Array() failed: %v", err)
+	}
+	if arr.String() != `["a",1,[2,3]]` {
+		t.Errorf("ArrayBuilder result mismatch: got %s", arr.String())
+	}
+
+	b := NewArrayBuilder().Add(make(chan int)).Add(1)
+	if b.Err() == nil {
+		t.Errorf("ArrayBuilder.Err() should return error")
+	}
+	if _, err := b.Array(); err == nil {
+		t.Errorf("ArrayBuilder.Array() should return error")
+	}
+}
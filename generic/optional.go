@@ -0,0 +1,126 @@
+package generic
+
+import (
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Optional holds a value that may be absent
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some creates an Optional holding value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, ok: true}
+}
+
+// None creates an empty Optional
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// IsSome reports whether the Optional holds a value
+func (o Optional[T]) IsSome() bool {
+	return o.ok
+}
+
+// IsNone reports whether the Optional is empty
+func (o Optional[T]) IsNone() bool {
+	return !o.ok
+}
+
+// Get returns the value and whether it is present
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// GetOr returns the value, or def if the Optional is empty
+func (o Optional[T]) GetOr(def T) T {
+	if o.ok {
+		return o.value
+	}
+	return def
+}
+
+// Result holds either a value or the error that prevented producing it
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok creates a successful Result
+func Ok[T any](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// Fail creates a failed Result
+func Fail[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// ResultOf wraps a (value, error) pair
+func ResultOf[T any](value T, err error) Result[T] {
+	if err != nil {
+		return Fail[T](err)
+	}
+	return Ok(value)
+}
+
+// IsOk reports whether the Result holds a value
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Err returns the error of a failed Result, or nil
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Unwrap returns the value and panics if the Result failed
+func (r Result[T]) Unwrap() T {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.value
+}
+
+// UnwrapOr returns the value, or def if the Result failed
+func (r Result[T]) UnwrapOr(def T) T {
+	if r.err != nil {
+		return def
+	}
+	return r.value
+}
+
+// Get returns the value and the error as a pair
+func (r Result[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+// Optional converts the Result to an Optional, dropping the error
+func (r Result[T]) Optional() Optional[T] {
+	if r.err != nil {
+		return None[T]()
+	}
+	return Some(r.value)
+}
+
+// TryGet is like GetTyped but returns a Result
+func TryGet[V any](obj *types.JSONObject, key string) Result[V] {
+	return ResultOf(GetTyped[V](obj, key))
+}
+
+// Lookup is like GetTyped but returns an Optional that is empty when the key is
+// missing, null or cannot be converted to V
+func Lookup[V any](obj *types.JSONObject, key string) Optional[V] {
+	if key != "" && obj.Get(key).IsNull() {
+		return None[V]()
+	}
+	return TryGet[V](obj, key).Optional()
+}
+
+// TryQuery is like QueryTyped but returns a Result
+func TryQuery[T any](doc types.JSONValue, path string) Result[[]T] {
+	return ResultOf(QueryTyped[T](doc, path))
+}
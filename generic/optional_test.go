@@ -0,0 +1,88 @@
+package generic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestOptional(t *testing.T) {
+	some := Some(3)
+	if !some.IsSome() || some.IsNone() || some.GetOr(0) != 3 {
+		t.Errorf("Some(3) mismatch: %+v", some)
+	}
+	if v, ok := some.Get(); !ok || v != 3 {
+		t.Errorf("Some(3).Get() = %v, %v", v, ok)
+	}
+
+	none := None[string]()
+	if none.IsSome() || !none.IsNone() || none.GetOr("default") != "default" {
+		t.Errorf("None() mismatch: %+v", none)
+	}
+	if v, ok := none.Get(); ok || v != "" {
+		t.Errorf("None().Get() = %q, %v", v, ok)
+	}
+}
+
+func TestResult(t *testing.T) {
+	ok := Ok("value")
+	if !ok.IsOk() || ok.Err() != nil || ok.Unwrap() != "value" || ok.UnwrapOr("x") != "value" {
+		t.Errorf("Ok mismatch: %+v", ok)
+	}
+	if !ok.Optional().IsSome() {
+		t.Errorf("Ok.Optional() should be Some")
+	}
+
+	failure := errors.New("failure")
+	failed := ResultOf(0, failure)
+	if failed.IsOk() || failed.Err() != failure || failed.UnwrapOr(7) != 7 {
+		t.Errorf("failed Result mismatch: %+v", failed)
+	}
+	if _, err := failed.Get(); err != failure {
+		t.Errorf("failed.Get() error = %v", err)
+	}
+	if failed.Optional().IsSome() {
+		t.Errorf("failed.Optional() should be None")
+	}
+
+	defer func() {
+		if r := recover(); r != failure {
+			t.Errorf("Unwrap on a failed Result should panic with its error, got %v", r)
+		}
+	}()
+	failed.Unwrap()
+}
+
+func TestAccessorVariants(t *testing.T) {
+	obj := types.NewJSONObject()
+	obj.PutString("name", "John")
+	obj.PutNumber("age", 30)
+	obj.PutNull("nickname")
+
+	if age := Lookup[int](obj, "age").GetOr(0); age != 30 {
+		t.Errorf("Lookup[int](age) = %v, expected 30", age)
+	}
+	for _, key := range []string{"missing", "nickname", "name"} {
+		if Lookup[int](obj, key).IsSome() {
+			t.Errorf("Lookup[int](%s) should be None", key)
+		}
+	}
+	if Lookup[*string](obj, "nickname").IsSome() {
+		t.Errorf("Lookup[*string](nickname) should be None for a null value")
+	}
+
+	if name := TryGet[string](obj, "name").Unwrap(); name != "John" {
+		t.Errorf("TryGet[string](name) = %v, expected John", name)
+	}
+	if TryGet[int](obj, "name").Err() == nil {
+		t.Errorf("TryGet[int](name) should fail")
+	}
+
+	if ages := TryQuery[int](obj, "$.age").UnwrapOr(nil); len(ages) != 1 || ages[0] != 30 {
+		t.Errorf("TryQuery[int]($.age) = %v, expected [30]", ages)
+	}
+	if TryQuery[int](obj, "$[").IsOk() {
+		t.Errorf("TryQuery with an invalid path should fail")
+	}
+}
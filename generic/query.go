@@ -0,0 +1,45 @@
+package generic
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// QueryTyped runs a JSON Path query and converts every match to T.
+// Conversion follows the same rules as GetTyped; null matches become nil for
+// pointer and interface targets. The error names the index of the first match
+// that cannot be converted
+//
+//	prices, err := generic.QueryTyped[float64](doc, "$.items[*].price")
+func QueryTyped[T any](doc types.JSONValue, path string) ([]T, error) {
+	matches, err := jsonpath.QueryJSONPath(doc, path)
+	if err != nil {
+		return nil, err
+	}
+
+	targetType := reflect.TypeOf((*T)(nil)).Elem()
+	results := make([]T, len(matches))
+	for i, match := range matches {
+		converted, err := convertValue(match, targetType)
+		if err != nil {
+			return nil, errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("cannot convert match %d", i)).WithPath(path).WithCause(err)
+		}
+		reflect.ValueOf(&results[i]).Elem().Set(converted)
+	}
+	return results, nil
+}
+
+// QueryTypedString is like QueryTyped but parses the document from a JSON string
+func QueryTypedString[T any](jsonStr string, path string) ([]T, error) {
+	doc, err := parser.ParseToValue(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+	return QueryTyped[T](doc, path)
+}
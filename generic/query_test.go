@@ -0,0 +1,71 @@
+package generic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestQueryTyped(t *testing.T) {
+	doc, err := parser.ParseToValue(`{
+		"items": [
+			{"id": "a1", "price": 9.5, "note": null},
+			{"id": "b2", "price": 20, "note": "sale"}
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prices, err := QueryTyped[float64](doc, "$.items[*].price")
+	if err != nil {
+		t.Fatalf("QueryTyped[float64] failed: %v", err)
+	}
+	if len(prices) != 2 || prices[0] != 9.5 || prices[1] != 20 {
+		t.Errorf("prices mismatch: expected [9.5 20], got %v", prices)
+	}
+
+	ids, err := QueryTyped[CustomID](doc, "$.items[*].id")
+	if err != nil {
+		t.Fatalf("QueryTyped[CustomID] failed: %v", err)
+	}
+	if len(ids) != 2 || ids[1] != "b2" {
+		t.Errorf("ids mismatch: expected [a1 b2], got %v", ids)
+	}
+
+	notes, err := QueryTyped[*string](doc, "$.items[*].note")
+	if err != nil {
+		t.Fatalf("QueryTyped[*string] failed: %v", err)
+	}
+	if len(notes) != 2 || notes[0] != nil || notes[1] == nil || *notes[1] != "sale" {
+		t.Errorf("notes mismatch: expected [nil sale], got %v", notes)
+	}
+
+	// No matches give an empty slice
+	none, err := QueryTyped[int](doc, "$.missing[*]")
+	if err != nil || len(none) != 0 {
+		t.Errorf("QueryTyped with no matches = %v, %v, expected empty", none, err)
+	}
+
+	// The error names the match that cannot be converted
+	_, err = QueryTyped[string](doc, "$.items[*].note")
+	if err == nil || !strings.Contains(err.Error(), "match 0") {
+		t.Errorf("QueryTyped with a null match error = %v, expected match 0", err)
+	}
+
+	// Invalid paths are reported
+	if _, err := QueryTyped[int](doc, "$.items["); err == nil {
+		t.Errorf("QueryTyped with an invalid path should fail")
+	}
+}
+
+func TestQueryTypedString(t *testing.T) {
+	counts, err := QueryTypedString[int](`[{"n":1},{"n":2}]`, "$[*].n")
+	if err != nil || len(counts) != 2 || counts[1] != 2 {
+		t.Errorf("QueryTypedString[int] = %v, %v, expected [1 2]", counts, err)
+	}
+	if _, err := QueryTypedString[int](`{`, "$"); err == nil {
+		t.Errorf("QueryTypedString with invalid JSON should fail")
+	}
+}
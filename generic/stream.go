@@ -0,0 +1,150 @@
+//go:build !gojson_nostream && !gojson_nofast
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/fast"
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// streamBuffer is the number of elements buffered between reading, conversion and the consumer
+const streamBuffer = 64
+
+// DecodeArrayStream reads the values matching path (e.g. "$.items[*]") from r and sends
+// them converted to T, in document order. Reading and conversion run in separate
+// goroutines, so only the buffered elements are held in memory.
+//
+// The error channel receives at most one error and both channels are closed when the
+// stream ends. The caller must drain the value channel; use DecodeArrayStreamContext
+// to stop early
+func DecodeArrayStream[T any](r io.Reader, path string) (<-chan T, <-chan error) {
+	return DecodeArrayStreamContext[T](context.Background(), r, path)
+}
+
+// DecodeArrayStreamContext is like DecodeArrayStream but stops when ctx is cancelled,
+// reporting ctx.Err() on the error channel
+func DecodeArrayStreamContext[T any](ctx context.Context, r io.Reader, path string) (<-chan T, <-chan error) {
+	values := make(chan T, streamBuffer)
+	errs := make(chan error, 1)
+
+	reader, err := stream.NewElementReader(r, path)
+	if err != nil {
+		close(values)
+		errs <- err
+		close(errs)
+		return values, errs
+	}
+
+	// Read elements in one goroutine and convert them in another.
+	// The reader is cancelled as well when conversion fails
+	ctx, cancel := context.WithCancel(ctx)
+	raw := make(chan types.JSONValue, streamBuffer)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(raw)
+		for {
+			value, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case raw <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+		defer cancel()
+
+		targetType := reflect.TypeOf((*T)(nil)).Elem()
+		index := 0
+		for value := range raw {
+			converted, err := convertValue(value, targetType)
+			if err != nil {
+				errs <- errors.NewJSONError(errors.ErrTypeConversion,
+					fmt.Sprintf("cannot convert element %d", index)).WithPath(path).WithCause(err)
+				cancel()
+				drain(raw)
+				return
+			}
+			var result T
+			reflect.ValueOf(&result).Elem().Set(converted)
+
+			select {
+			case values <- result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				drain(raw)
+				return
+			}
+			index++
+		}
+
+		select {
+		case err := <-readErr:
+			errs <- err
+		default:
+			if ctx.Err() != nil {
+				errs <- ctx.Err()
+			}
+		}
+	}()
+
+	return values, errs
+}
+
+// EncodeArrayStream writes the values received from values to w as a JSON array,
+// encoding each element as it arrives and flushing every streamBuffer elements.
+// It returns when values is closed or an element cannot be encoded
+func EncodeArrayStream[T any](w io.Writer, values <-chan T) error {
+	generator := stream.NewJSONGenerator(w)
+	if err := generator.BeginArray(); err != nil {
+		return err
+	}
+
+	index := 0
+	for value := range values {
+		data, err := fast.Marshal(value)
+		if err != nil {
+			// Flush what has been written so far but leave the array unterminated
+			generator.Flush()
+			return errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("cannot encode element %d", index)).WithCause(err)
+		}
+		if err := generator.WriteRaw(data); err != nil {
+			return err
+		}
+		index++
+		if index%streamBuffer == 0 {
+			if err := generator.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := generator.EndArray(); err != nil {
+		return err
+	}
+	return generator.Flush()
+}
+
+// drain discards the remaining values so that the producing goroutine can exit
+func drain[T any](ch <-chan T) {
+	for range ch {
+	}
+}
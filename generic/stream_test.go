@@ -0,0 +1,147 @@
+//go:build !gojson_nostream && !gojson_nofast
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type streamItem struct {
+	ID    int     `json:"id"`
+	Price float64 `json:"price"`
+}
+
+// collect reads all values and the error from the channels
+func collect[T any](values <-chan T, errs <-chan error) ([]T, error) {
+	var result []T
+	for v := range values {
+		result = append(result, v)
+	}
+	return result, <-errs
+}
+
+func TestDecodeArrayStream(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"meta":{"count":500},"items":[`)
+	for i := 0; i < 500; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id":%d,"price":%d.5}`, i, i)
+	}
+	b.WriteString(`]}`)
+
+	items, err := collect(DecodeArrayStream[streamItem](strings.NewReader(b.String()), "$.items[*]"))
+	if err != nil {
+		t.Fatalf("DecodeArrayStream failed: %v", err)
+	}
+	if len(items) != 500 {
+		t.Fatalf("items length mismatch: expected 500, got %d", len(items))
+	}
+	for i, item := range items {
+		if item.ID != i || item.Price != float64(i)+0.5 {
+			t.Fatalf("items[%d] mismatch: %+v", i, item)
+		}
+	}
+
+	ids, err := collect(DecodeArrayStream[*int](strings.NewReader(`[1,null,3]`), "$[*]"))
+	if err != nil || len(ids) != 3 || ids[1] != nil || *ids[2] != 3 {
+		t.Errorf("DecodeArrayStream[*int] = %v, %v", ids, err)
+	}
+}
+
+func TestDecodeArrayStreamErrors(t *testing.T) {
+	// Invalid path
+	_, err := collect(DecodeArrayStream[int](strings.NewReader(`[]`), "$..x"))
+	if err == nil {
+		t.Errorf("DecodeArrayStream with an unsupported path should fail")
+	}
+
+	// Conversion error names the element and stops the stream
+	values, err := collect(DecodeArrayStream[int](strings.NewReader(`[1,2,"three",4]`), "$[*]"))
+	if err == nil || !strings.Contains(err.Error(), "element 2") {
+		t.Errorf("conversion error = %v, expected element 2", err)
+	}
+	if len(values) != 2 {
+		t.Errorf("values before the error = %v, expected [1 2]", values)
+	}
+
+	// Truncated input
+	_, err = collect(DecodeArrayStream[int](strings.NewReader(`[1,2`), "$[*]"))
+	if err == nil {
+		t.Errorf("DecodeArrayStream with truncated input should fail")
+	}
+}
+
+func TestDecodeArrayStreamContext(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%d", i)
+	}
+	b.WriteString("]")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	values, errs := DecodeArrayStreamContext[int](ctx, strings.NewReader(b.String()), "$[*]")
+	count := 0
+	for range values {
+		count++
+		if count == 10 {
+			cancel()
+			break
+		}
+	}
+	for range values {
+	}
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("error after cancel = %v, expected context.Canceled", err)
+	}
+}
+
+func TestEncodeArrayStream(t *testing.T) {
+	values := make(chan streamItem)
+	go func() {
+		defer close(values)
+		for i := 0; i < 200; i++ {
+			values <- streamItem{ID: i, Price: float64(i) / 2}
+		}
+	}()
+
+	var b strings.Builder
+	if err := EncodeArrayStream[streamItem](&b, values); err != nil {
+		t.Fatalf("EncodeArrayStream failed: %v", err)
+	}
+
+	// Round trip through DecodeArrayStream
+	items, err := collect(DecodeArrayStream[streamItem](strings.NewReader(b.String()), "$[*]"))
+	if err != nil {
+		t.Fatalf("decoding the encoded stream failed: %v", err)
+	}
+	if len(items) != 200 || items[199].ID != 199 || items[3].Price != 1.5 {
+		t.Errorf("round trip mismatch: %d items, last %+v", len(items), items[len(items)-1])
+	}
+
+	empty := make(chan int)
+	close(empty)
+	b.Reset()
+	if err := EncodeArrayStream[int](&b, empty); err != nil || b.String() != "[]" {
+		t.Errorf("EncodeArrayStream of an empty channel = %q, %v, expected []", b.String(), err)
+	}
+
+	bad := make(chan interface{}, 2)
+	bad <- 1
+	bad <- make(chan int)
+	close(bad)
+	b.Reset()
+	err = EncodeArrayStream[interface{}](&b, bad)
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("EncodeArrayStream with an unencodable value error = %v, expected element 1", err)
+	}
+}
@@ -15,6 +15,8 @@ import (
 // key is the key to get the value for. If empty, the entire object is used
 func GetTyped[V any](obj *types.JSONObject, key string) (V, error) {
 	var zero V
+	// reflect.TypeOf(zero) is nil for interface types, so take the type from a pointer
+	targetType := reflect.TypeOf((*V)(nil)).Elem()
 	var value types.JSONValue
 
 	// If key is empty, use the entire object
@@ -23,192 +25,132 @@ func GetTyped[V any](obj *types.JSONObject, key string) (V, error) {
 	} else {
 		value = obj.Get(key)
 		if value.IsNull() {
+			// Pointer and interface targets are optional: missing and null values become nil
+			if targetType.Kind() == reflect.Pointer || targetType.Kind() == reflect.Interface {
+				return zero, nil
+			}
 			return zero, errors.ErrPathNotFoundWithDetails(key)
 		}
 	}
 
-	// Get the target type using reflection
-	targetType := reflect.TypeOf(zero)
+	converted, err := convertValue(value, targetType)
+	if err != nil {
+		return zero, err
+	}
+
+	// Set through reflection so that nil interface results do not panic
+	var result V
+	reflect.ValueOf(&result).Elem().Set(converted)
+	return result, nil
+}
 
-	// Convert based on target type
+// convertValue converts a JSONValue to a value of the target type.
+// Numbers are converted according to types.CurrentNumericPolicy,
+// named types (e.g. type CustomID string) are converted from their underlying kind,
+// pointers are allocated for non-null values and interfaces receive the plain Go value
+func convertValue(value types.JSONValue, targetType reflect.Type) (reflect.Value, error) {
 	switch targetType.Kind() {
+	case reflect.Pointer:
+		if value.IsNull() {
+			return reflect.Zero(targetType), nil
+		}
+		elem, err := convertValue(value, targetType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(targetType.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Interface:
+		if value.IsNull() {
+			return reflect.Zero(targetType), nil
+		}
+		v := reflect.ValueOf(types.ValueToInterface(value))
+		if !v.Type().AssignableTo(targetType) {
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails(targetType.String(), value.Type())
+		}
+		result := reflect.New(targetType).Elem()
+		result.Set(v)
+		return result, nil
 	case reflect.String:
 		if !value.IsString() {
-			return zero, errors.ErrInvalidTypeWithDetails("string", value.Type())
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("string", value.Type())
 		}
 		str, _ := value.AsString()
-		return any(str).(V), nil
+		return reflect.ValueOf(str).Convert(targetType), nil
 	case reflect.Bool:
 		if !value.IsBoolean() {
-			return zero, errors.ErrInvalidTypeWithDetails("boolean", value.Type())
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("boolean", value.Type())
 		}
 		b, _ := value.AsBoolean()
-		return any(b).(V), nil
+		return reflect.ValueOf(b).Convert(targetType), nil
 	case reflect.Float64, reflect.Float32:
 		if !value.IsNumber() {
-			return zero, errors.ErrInvalidTypeWithDetails("number", value.Type())
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("number", value.Type())
+		}
+		num, err := types.CurrentNumericPolicy().Float64(value)
+		if err != nil {
+			return reflect.Value{}, err
 		}
-		num, _ := value.AsNumber()
-		return any(num).(V), nil
+		return reflect.ValueOf(num).Convert(targetType), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if !value.IsNumber() {
-			return zero, errors.ErrInvalidTypeWithDetails("number", value.Type())
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("number", value.Type())
 		}
-		num, _ := value.AsNumber()
-		return any(int(num)).(V), nil
+		num, err := types.CurrentNumericPolicy().IntBits(value, targetType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(num).Convert(targetType), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if !value.IsNumber() {
-			return zero, errors.ErrInvalidTypeWithDetails("number", value.Type())
-		}
-		num, _ := value.AsNumber()
-		return any(uint(num)).(V), nil
-	case reflect.Slice, reflect.Array:
-		if !value.IsArray() {
-			return zero, errors.ErrInvalidTypeWithDetails("array", value.Type())
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("number", value.Type())
 		}
-		arr, _ := value.AsArray()
-		// Use json package for type conversion
-		data, err := json.Marshal(arr.ToArray())
+		num, err := types.CurrentNumericPolicy().UintBits(value, targetType.Bits())
 		if err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("failed to marshal array: %v", err)).WithCause(err)
+			return reflect.Value{}, err
 		}
-		
-		var result V
-		if err := json.Unmarshal(data, &result); err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("cannot convert array to %T", zero)).WithCause(err)
+		return reflect.ValueOf(num).Convert(targetType), nil
+	case reflect.Slice, reflect.Array:
+		if !value.IsArray() {
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("array", value.Type())
 		}
-		return result, nil
+		return unmarshalValue(value, targetType, "array")
 	case reflect.Map:
 		if !value.IsObject() {
-			return zero, errors.ErrInvalidTypeWithDetails("object", value.Type())
-		}
-		objVal, _ := value.AsObject()
-		// Use json package for type conversion
-		data, err := json.Marshal(types.ValueToInterface(objVal))
-		if err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("failed to marshal object: %v", err)).WithCause(err)
-		}
-		
-		var result V
-		if err := json.Unmarshal(data, &result); err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("cannot convert object to %T", zero)).WithCause(err)
+			return reflect.Value{}, errors.ErrInvalidTypeWithDetails("object", value.Type())
 		}
-		return result, nil
+		return unmarshalValue(value, targetType, "object")
 	case reflect.Struct:
-		// For structs, use json package for conversion
-		data, err := json.Marshal(types.ValueToInterface(value))
-		if err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("failed to marshal JSON: %v", err)).WithCause(err)
-		}
-		
-		var result V
-		if err := json.Unmarshal(data, &result); err != nil {
-			return zero, errors.NewJSONError(errors.ErrTypeConversion,
-				fmt.Sprintf("cannot convert JSON to %T", zero)).WithCause(err)
-		}
-		return result, nil
+		return unmarshalValue(value, targetType, "JSON")
 	default:
-		return zero, errors.NewJSONError(errors.ErrNotSupported,
-			fmt.Sprintf("unsupported type: %T", zero))
+		return reflect.Value{}, errors.NewJSONError(errors.ErrNotSupported,
+			fmt.Sprintf("unsupported type: %s", targetType))
 	}
 }
 
-// ToJSONValue converts a Go value to a JSONValue
-func ToJSONValue(v interface{}) (types.JSONValue, error) {
-	if v == nil {
-		return types.NewJSONNull(), nil
-	}
-
-	// Handle primitive types directly
-	switch val := v.(type) {
-	case string:
-		return types.NewJSONString(val), nil
-	case float64:
-		return types.NewJSONNumber(val), nil
-	case float32:
-		return types.NewJSONNumber(float64(val)), nil
-	case int:
-		return types.NewJSONNumber(float64(val)), nil
-	case int8:
-		return types.NewJSONNumber(float64(val)), nil
-	case int16:
-		return types.NewJSONNumber(float64(val)), nil
-	case int32:
-		return types.NewJSONNumber(float64(val)), nil
-	case int64:
-		return types.NewJSONNumber(float64(val)), nil
-	case uint:
-		return types.NewJSONNumber(float64(val)), nil
-	case uint8:
-		return types.NewJSONNumber(float64(val)), nil
-	case uint16:
-		return types.NewJSONNumber(float64(val)), nil
-	case uint32:
-		return types.NewJSONNumber(float64(val)), nil
-	case uint64:
-		return types.NewJSONNumber(float64(val)), nil
-	case bool:
-		return types.NewJSONBool(val), nil
-	}
-
-	// For complex types, use reflection and json marshaling
-	data, err := json.Marshal(v)
+// unmarshalValue converts composite values using the json package
+func unmarshalValue(value types.JSONValue, targetType reflect.Type, kind string) (reflect.Value, error) {
+	data, err := json.Marshal(types.ValueToInterface(value))
 	if err != nil {
-		return nil, errors.NewJSONError(errors.ErrTypeConversion,
-			fmt.Sprintf("failed to marshal %T to JSON", v)).WithCause(err)
+		return reflect.Value{}, errors.NewJSONError(errors.ErrTypeConversion,
+			fmt.Sprintf("failed to marshal %s: %v", kind, err)).WithCause(err)
 	}
 
-	var raw interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, errors.NewJSONError(errors.ErrTypeConversion,
-			fmt.Sprintf("failed to unmarshal JSON to interface{}: %v", err)).WithCause(err)
+	result := reflect.New(targetType)
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return reflect.Value{}, errors.NewJSONError(errors.ErrTypeConversion,
+			fmt.Sprintf("cannot convert %s to %s", kind, targetType)).WithCause(err)
 	}
+	return result.Elem(), nil
+}
 
-	return convertToJSONValue(raw)
+// ToJSONValue converts a Go value to a JSONValue
+func ToJSONValue(v interface{}) (types.JSONValue, error) {
+	return convertToJSONValue(v)
 }
 
 // convertToJSONValue converts a Go native type to JSONValue
 func convertToJSONValue(v interface{}) (types.JSONValue, error) {
-	if v == nil {
-		return types.NewJSONNull(), nil
-	}
-
-	switch val := v.(type) {
-	case string:
-		return types.NewJSONString(val), nil
-	case float64:
-		return types.NewJSONNumber(val), nil
-	case int:
-		return types.NewJSONNumber(float64(val)), nil
-	case bool:
-		return types.NewJSONBool(val), nil
-	case map[string]interface{}:
-		obj := types.NewJSONObject()
-		for k, v := range val {
-			jsonVal, err := convertToJSONValue(v)
-			if err != nil {
-				return nil, err
-			}
-			obj.Put(k, jsonVal)
-		}
-		return obj, nil
-	case []interface{}:
-		arr := types.NewJSONArray()
-		for _, v := range val {
-			jsonVal, err := convertToJSONValue(v)
-			if err != nil {
-				return nil, err
-			}
-			arr.Add(jsonVal)
-		}
-		return arr, nil
-	default:
-		return nil, errors.NewJSONError(errors.ErrNotSupported,
-			fmt.Sprintf("unsupported type: %T", val))
-	}
+	return types.FromInterface(v)
 }
@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/UserLeeZJ/gojson/types"
@@ -117,8 +118,14 @@ func TestGetTyped(t *testing.T) {
 		t.Errorf("GetTyped with type mismatch should fail")
 	}
 
-	// Skip custom type test for now
-	// CustomID is a string alias, but direct conversion is not supported
+	// Test custom string type
+	id, err := GetTyped[CustomID](obj, "name")
+	if err != nil {
+		t.Errorf("GetTyped[CustomID] failed: %v", err)
+	}
+	if id != CustomID("John") {
+		t.Errorf("id mismatch: expected John, got %s", id)
+	}
 
 	// Test uint
 	_, err = GetTyped[uint](obj, "age")
@@ -127,6 +134,83 @@ func TestGetTyped(t *testing.T) {
 	}
 }
 
+func TestGetTypedNumericPolicy(t *testing.T) {
+	obj := types.NewJSONObject()
+	obj.PutNumber("big", 300)
+	obj.PutNumber("ratio", 2.5)
+
+	if n, err := GetTyped[int8](obj, "big"); err != nil || n != 127 {
+		t.Errorf("GetTyped[int8] = %d, %v, expected clamped 127", n, err)
+	}
+	if n, err := GetTyped[int](obj, "ratio"); err != nil || n != 2 {
+		t.Errorf("GetTyped[int] = %d, %v, expected truncated 2", n, err)
+	}
+
+	old := types.SetNumericPolicy(types.StrictNumericPolicy())
+	defer types.SetNumericPolicy(old)
+	if _, err := GetTyped[int8](obj, "big"); err == nil {
+		t.Errorf("GetTyped[int8] should fail on overflow with the strict policy")
+	}
+	if _, err := GetTyped[uint](obj, "ratio"); err == nil {
+		t.Errorf("GetTyped[uint] should fail on fractions with the strict policy")
+	}
+}
+
+func TestGetTypedOptional(t *testing.T) {
+	obj := types.NewJSONObject()
+	obj.PutString("name", "John")
+	obj.PutNumber("age", 30)
+	obj.PutNull("nickname")
+	ids := types.NewJSONArray()
+	ids.AddString("a").AddString("b")
+	obj.PutArray("ids", ids)
+
+	// Pointer targets
+	age, err := GetTyped[*int](obj, "age")
+	if err != nil || age == nil || *age != 30 {
+		t.Errorf("GetTyped[*int] = %v, %v, expected 30", age, err)
+	}
+	missing, err := GetTyped[*string](obj, "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetTyped[*string] for missing key = %v, %v, expected nil, nil", missing, err)
+	}
+	nickname, err := GetTyped[*string](obj, "nickname")
+	if err != nil || nickname != nil {
+		t.Errorf("GetTyped[*string] for null value = %v, %v, expected nil, nil", nickname, err)
+	}
+	if _, err := GetTyped[*int](obj, "name"); err == nil {
+		t.Errorf("GetTyped[*int] with type mismatch should fail")
+	}
+
+	// Named numeric and slice types
+	type Age int64
+	named, err := GetTyped[Age](obj, "age")
+	if err != nil || named != 30 {
+		t.Errorf("GetTyped[Age] = %v, %v, expected 30", named, err)
+	}
+	customIDs, err := GetTyped[[]CustomID](obj, "ids")
+	if err != nil || len(customIDs) != 2 || customIDs[1] != "b" {
+		t.Errorf("GetTyped[[]CustomID] = %v, %v, expected [a b]", customIDs, err)
+	}
+	ptrIDs, err := GetTyped[*[]CustomID](obj, "ids")
+	if err != nil || ptrIDs == nil || len(*ptrIDs) != 2 {
+		t.Errorf("GetTyped[*[]CustomID] = %v, %v, expected [a b]", ptrIDs, err)
+	}
+
+	// Interface targets
+	anyName, err := GetTyped[interface{}](obj, "name")
+	if err != nil || anyName != "John" {
+		t.Errorf("GetTyped[interface{}] = %v, %v, expected John", anyName, err)
+	}
+	anyMissing, err := GetTyped[any](obj, "missing")
+	if err != nil || anyMissing != nil {
+		t.Errorf("GetTyped[any] for missing key = %v, %v, expected nil, nil", anyMissing, err)
+	}
+	if _, err := GetTyped[fmt.Stringer](obj, "name"); err == nil {
+		t.Errorf("GetTyped[fmt.Stringer] should fail for a string value")
+	}
+}
+
 func TestToJSONValue(t *testing.T) {
 	// Test primitive types
 	strVal, err := ToJSONValue("test")
@@ -153,6 +237,11 @@ func TestToJSONValue(t *testing.T) {
 		t.Errorf("numVal mismatch: expected 42, got %f", num)
 	}
 
+	intMapVal, err := ToJSONValue(map[int]string{2: "b", 1: "a"})
+	if err != nil || intMapVal.String() != `{"1":"a","2":"b"}` {
+		t.Errorf("ToJSONValue(map[int]string) = %v, %v", intMapVal, err)
+	}
+
 	boolVal, err := ToJSONValue(true)
 	if err != nil {
 		t.Errorf("ToJSONValue(bool) failed: %v", err)
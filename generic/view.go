@@ -0,0 +1,148 @@
+package generic
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ObjectView is a typed view of a JSONObject backed by a struct.
+// Reads go through the decoded struct, writes through SetField update both
+// the struct and the underlying JSONObject
+type ObjectView[T any] struct {
+	obj   *types.JSONObject
+	value *T
+}
+
+// View decodes obj into T and keeps a link to obj so that SetField writes back into it.
+// T must be a struct type; fields are mapped to keys using their json tags
+func View[T any](obj *types.JSONObject) (*ObjectView[T], error) {
+	targetType := reflect.TypeOf((*T)(nil)).Elem()
+	if targetType.Kind() != reflect.Struct {
+		return nil, errors.NewJSONError(errors.ErrNotSupported,
+			fmt.Sprintf("view type must be a struct, got %s", targetType))
+	}
+
+	view := &ObjectView[T]{obj: obj}
+	if err := view.Refresh(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// Value returns the decoded struct.
+// Changes made directly to the struct are not written back; use SetField instead
+func (v *ObjectView[T]) Value() *T {
+	return v.value
+}
+
+// Object returns the underlying JSONObject
+func (v *ObjectView[T]) Object() *types.JSONObject {
+	return v.obj
+}
+
+// Refresh decodes the underlying JSONObject again, picking up changes made to it directly
+func (v *ObjectView[T]) Refresh() error {
+	converted, err := unmarshalValue(v.obj, reflect.TypeOf((*T)(nil)).Elem(), "object")
+	if err != nil {
+		return err
+	}
+	// Keep the pointer returned by Value valid across refreshes
+	if v.value == nil {
+		v.value = new(T)
+	}
+	*v.value = converted.Interface().(T)
+	return nil
+}
+
+// SetField sets the struct field with the given Go name and writes the value to the
+// matching key of the underlying JSONObject. Fields tagged omitempty are removed
+// from the object when set to their zero value
+func (v *ObjectView[T]) SetField(fieldName string, value interface{}) error {
+	structValue := reflect.ValueOf(v.value).Elem()
+	field, ok := structValue.Type().FieldByName(fieldName)
+	if !ok || !field.IsExported() || len(field.Index) != 1 {
+		return errors.NewJSONError(errors.ErrPathNotFound,
+			fmt.Sprintf("unknown field: %s", fieldName))
+	}
+	key, omitEmpty, ok := jsonFieldKey(field)
+	if !ok {
+		return errors.NewJSONError(errors.ErrNotSupported,
+			fmt.Sprintf("field %s is not mapped to JSON", fieldName))
+	}
+
+	fieldValue, err := assignableValue(value, field.Type)
+	if err != nil {
+		return err
+	}
+
+	if omitEmpty && fieldValue.IsZero() {
+		v.obj.Remove(key)
+	} else {
+		jsonValue, err := ToJSONValue(fieldValue.Interface())
+		if err != nil {
+			return err
+		}
+		v.obj.Put(key, jsonValue)
+	}
+	structValue.Field(field.Index[0]).Set(fieldValue)
+	return nil
+}
+
+// jsonFieldKey returns the object key of a struct field following encoding/json rules.
+// ok is false for fields tagged "-"
+func jsonFieldKey(field reflect.StructField) (key string, omitEmpty bool, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, true
+}
+
+// assignableValue converts value to the field type.
+// nil is accepted for pointer, interface, map and slice fields; numbers are converted
+// between numeric kinds and named types are converted from their underlying type
+func assignableValue(value interface{}, fieldType reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch fieldType.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			return reflect.Zero(fieldType), nil
+		}
+		return reflect.Value{}, errors.ErrInvalidTypeWithDetails(fieldType.String(), "null")
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(fieldType) {
+		return v, nil
+	}
+	if v.CanConvert(fieldType) && sameKindFamily(v.Kind(), fieldType.Kind()) {
+		return v.Convert(fieldType), nil
+	}
+	return reflect.Value{}, errors.ErrInvalidTypeWithDetails(fieldType.String(), v.Type().String())
+}
+
+// sameKindFamily reports whether converting between the two kinds keeps the meaning
+// of the value, which excludes conversions such as int to string
+func sameKindFamily(a, b reflect.Kind) bool {
+	family := func(k reflect.Kind) int {
+		switch {
+		case k >= reflect.Int && k <= reflect.Float64:
+			return 1
+		default:
+			return int(k) + 2
+		}
+	}
+	return family(a) == family(b)
+}
@@ -0,0 +1,115 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+type viewUser struct {
+	ID       CustomID `json:"id"`
+	Name     string   `json:"name"`
+	Age      int      `json:"age"`
+	Nickname *string  `json:"nickname,omitempty"`
+	Internal string   `json:"-"`
+	Plain    bool
+}
+
+func TestView(t *testing.T) {
+	value, err := parser.ParseToValue(`{"id":"u1","name":"John","age":30,"nickname":"JJ","extra":true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, _ := value.AsObject()
+
+	view, err := View[viewUser](obj)
+	if err != nil {
+		t.Fatalf("View failed: %v", err)
+	}
+	user := view.Value()
+	if user.ID != "u1" || user.Name != "John" || user.Age != 30 || user.Nickname == nil || *user.Nickname != "JJ" {
+		t.Errorf("decoded value mismatch: %+v", user)
+	}
+
+	// Writes go to both the struct and the object
+	if err := view.SetField("Name", "Jane"); err != nil {
+		t.Fatalf("SetField(Name) failed: %v", err)
+	}
+	if err := view.SetField("Age", 31.0); err != nil {
+		t.Fatalf("SetField(Age) failed: %v", err)
+	}
+	if err := view.SetField("ID", "u2"); err != nil {
+		t.Fatalf("SetField(ID) failed: %v", err)
+	}
+	if err := view.SetField("Plain", true); err != nil {
+		t.Fatalf("SetField(Plain) failed: %v", err)
+	}
+	if user.Name != "Jane" || user.Age != 31 || user.ID != "u2" || !user.Plain {
+		t.Errorf("struct not updated: %+v", user)
+	}
+	if name, _ := obj.GetString("name"); name != "Jane" {
+		t.Errorf("object name = %s, expected Jane", name)
+	}
+	if age, _ := obj.GetNumber("age"); age != 31 {
+		t.Errorf("object age = %v, expected 31", age)
+	}
+	if id, _ := obj.GetString("id"); id != "u2" {
+		t.Errorf("object id = %s, expected u2", id)
+	}
+	if plain, _ := obj.GetBoolean("Plain"); !plain {
+		t.Errorf("object Plain should be true")
+	}
+
+	// omitempty fields set to their zero value are removed
+	if err := view.SetField("Nickname", nil); err != nil {
+		t.Fatalf("SetField(Nickname, nil) failed: %v", err)
+	}
+	if user.Nickname != nil || obj.Has("nickname") {
+		t.Errorf("nickname should be removed, got %v in %s", user.Nickname, obj.String())
+	}
+
+	// Keys not mapped to fields are kept
+	if !obj.Has("extra") {
+		t.Errorf("unmapped key extra should be kept")
+	}
+
+	// Invalid writes change nothing
+	for _, tt := range []struct {
+		field string
+		value interface{}
+	}{
+		{"Missing", 1},
+		{"Internal", "x"},
+		{"Age", "31"},
+		{"Name", 1},
+		{"Age", nil},
+	} {
+		if err := view.SetField(tt.field, tt.value); err == nil {
+			t.Errorf("SetField(%s, %v) should fail", tt.field, tt.value)
+		}
+	}
+	if user.Age != 31 || user.Name != "Jane" {
+		t.Errorf("failed writes should not change the struct: %+v", user)
+	}
+
+	// Refresh picks up changes made directly to the object
+	obj.PutString("name", "Joe")
+	if err := view.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if user.Name != "Joe" {
+		t.Errorf("Refresh did not update the struct: %+v", user)
+	}
+}
+
+func TestViewErrors(t *testing.T) {
+	if _, err := View[map[string]string](types.NewJSONObject()); err == nil {
+		t.Errorf("View of a non-struct type should fail")
+	}
+	obj := types.NewJSONObject()
+	obj.PutString("age", "thirty")
+	if _, err := View[viewUser](obj); err == nil {
+		t.Errorf("View with a mismatched field type should fail")
+	}
+}
@@ -5,11 +5,9 @@ package gojson
 import (
 	"github.com/UserLeeZJ/gojson/diff"
 	"github.com/UserLeeZJ/gojson/errors"
-	"github.com/UserLeeZJ/gojson/fast"
 	"github.com/UserLeeZJ/gojson/jsonpath"
 	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/patch"
-	"github.com/UserLeeZJ/gojson/stream"
 	"github.com/UserLeeZJ/gojson/types"
 	"github.com/UserLeeZJ/gojson/utils"
 )
@@ -31,80 +29,191 @@ const (
 
 // 重新导出的类型。
 type (
-	JSONValue   = types.JSONValue
-	JSONObject  = types.JSONObject
-	JSONArray   = types.JSONArray
-	JSONString  = types.JSONString
-	JSONNumber  = types.JSONNumber
-	JSONBool    = types.JSONBool
-	JSONNull    = types.JSONNull
-	JSONError   = errors.JSONError
-	ErrorCode   = errors.ErrorCode
-	DiffType    = diff.DiffType
-	Diff        = diff.Diff
-	DiffOptions = diff.DiffOptions
-
-	// 流式处理相关类型
-	JSONTokenType     = stream.JSONTokenType
-	JSONToken         = stream.JSONToken
-	JSONTokenizer     = stream.JSONTokenizer
-	JSONGenerator     = stream.JSONGenerator
-	IncrementalParser = stream.IncrementalParser
+	JSONValue            = types.JSONValue
+	JSONObject           = types.JSONObject
+	JSONArray            = types.JSONArray
+	JSONString           = types.JSONString
+	JSONNumber           = types.JSONNumber
+	JSONBigInt           = types.JSONBigInt
+	JSONDecimal          = types.JSONDecimal
+	NumericPolicy        = types.NumericPolicy
+	FractionMode         = types.FractionMode
+	OverflowMode         = types.OverflowMode
+	JSONBool             = types.JSONBool
+	JSONNull             = types.JSONNull
+	OrderedMap           = types.OrderedMap
+	KeyValue             = types.KeyValue
+	Obj                  = types.Obj
+	Arr                  = types.Arr
+	InterfaceOptions     = types.InterfaceOptions
+	FromInterfaceOptions = types.FromInterfaceOptions
+	Index                = types.Index
+	Accessor             = types.Accessor
+	Chain                = types.Chain
+	Snapshot             = types.Snapshot
+	MultiValueObject     = types.MultiValueObject
+	Path                 = types.Path
+	PathSegment          = types.PathSegment
+	KeyOrder             = types.KeyOrder
+	InternPool           = types.InternPool
+	InternStats          = types.InternStats
+	PlanOptions          = jsonpath.PlanOptions
+	Plan                 = jsonpath.Plan
+	Strategy             = jsonpath.Strategy
+	PathCacheStats       = jsonpath.CacheStats
+	Parser               = parser.Parser
+	Visitor              = types.Visitor
+	BaseVisitor          = types.BaseVisitor
+	ParserOptions        = parser.ParserOptions
+	Encoding             = parser.Encoding
+	StringMode           = parser.StringMode
+	RepairKind           = parser.RepairKind
+	Repair               = parser.Repair
+	JSONError            = errors.JSONError
+	ErrorCode            = errors.ErrorCode
+	DiffType             = diff.DiffType
+	Diff                 = diff.Diff
+	DiffOptions          = diff.DiffOptions
+	TextDiffMode         = diff.TextDiffMode
+	TextEdit             = diff.TextEdit
+	ApplyPatchOptions    = patch.ApplyPatchOptions
+	PatchProgress        = patch.Progress
+	Patch                = patch.Patch
+	PrettyOptions        = utils.PrettyOptions
+	MergeOptions         = utils.MergeOptions
+	MergeStrategy        = utils.MergeStrategy
+	ArrayMergeMode       = utils.ArrayMergeMode
+	Conflict             = utils.Conflict
+	Fix                  = utils.Fix
+	FixKind              = utils.FixKind
+	QuoteOptions         = utils.QuoteOptions
 )
 
 // 重新导出的错误代码常量。
 const (
-	ErrInvalidJSON     = errors.ErrInvalidJSON
-	ErrEmptyInput      = errors.ErrEmptyInput
-	ErrInvalidType     = errors.ErrInvalidType
-	ErrTypeConversion  = errors.ErrTypeConversion
-	ErrPathNotFound    = errors.ErrPathNotFound
-	ErrInvalidPath     = errors.ErrInvalidPath
-	ErrIndexOutOfRange = errors.ErrIndexOutOfRange
-	ErrInvalidIndex    = errors.ErrInvalidIndex
-	ErrOperationFailed = errors.ErrOperationFailed
-	ErrNotSupported    = errors.ErrNotSupported
-	ErrInvalidPatch    = errors.ErrInvalidPatch
-	ErrPatchFailed     = errors.ErrPatchFailed
-	ErrTestFailed      = errors.ErrTestFailed
-)
-
-// 重新导出的流式处理常量。
+	ErrInvalidJSON        = errors.ErrInvalidJSON
+	ErrEmptyInput         = errors.ErrEmptyInput
+	ErrInvalidEncoding    = errors.ErrInvalidEncoding
+	ErrInvalidType        = errors.ErrInvalidType
+	ErrTypeConversion     = errors.ErrTypeConversion
+	ErrUnsupportedType    = errors.ErrUnsupportedType
+	ErrPathNotFound       = errors.ErrPathNotFound
+	ErrInvalidPath        = errors.ErrInvalidPath
+	ErrIndexOutOfRange    = errors.ErrIndexOutOfRange
+	ErrInvalidIndex       = errors.ErrInvalidIndex
+	ErrOperationFailed    = errors.ErrOperationFailed
+	ErrNotSupported       = errors.ErrNotSupported
+	ErrCircularReference  = errors.ErrCircularReference
+	ErrInvalidPatch       = errors.ErrInvalidPatch
+	ErrPatchFailed        = errors.ErrPatchFailed
+	ErrTestFailed         = errors.ErrTestFailed
+	ErrBudgetExceeded     = errors.ErrBudgetExceeded
+	ErrInvalidExpression  = errors.ErrInvalidExpression
+	ErrUnsupportedVersion = errors.ErrUnsupportedVersion
+	ErrInvalidRecipe      = errors.ErrInvalidRecipe
+)
+
+// 重新导出的查询执行方式常量。
+const (
+	StrategyAuto      = jsonpath.StrategyAuto
+	StrategyDOM       = jsonpath.StrategyDOM
+	StrategyStreaming = jsonpath.StrategyStreaming
+)
+
+// 重新导出的字符串内部差异粒度常量。
+const (
+	TextDiffNone = diff.TextDiffNone
+	TextDiffLine = diff.TextDiffLine
+	TextDiffWord = diff.TextDiffWord
+)
+
+// 重新导出的数字转换策略常量。
+const (
+	FractionTruncate = types.FractionTruncate
+	FractionRound    = types.FractionRound
+	FractionError    = types.FractionError
+	OverflowClamp    = types.OverflowClamp
+	OverflowError    = types.OverflowError
+)
+
+// 重新导出的流式编码阈值常量。
+const (
+	DefaultStreamingThreshold = types.DefaultStreamingThreshold
+)
+
+// 重新导出的字符编码常量。
+const (
+	EncodingUTF8    = parser.EncodingUTF8
+	EncodingUTF16BE = parser.EncodingUTF16BE
+	EncodingUTF16LE = parser.EncodingUTF16LE
+	EncodingUTF32BE = parser.EncodingUTF32BE
+	EncodingUTF32LE = parser.EncodingUTF32LE
+)
+
+// 重新导出的字符串处理模式常量。
+const (
+	StringsDefault      = parser.StringsDefault
+	StringsStrict       = parser.StringsStrict
+	StringsRepair       = parser.StringsRepair
+	RepairControlChar   = parser.RepairControlChar
+	RepairInvalidEscape = parser.RepairInvalidEscape
+	RepairSurrogate     = parser.RepairSurrogate
+	RepairInvalidUTF8   = parser.RepairInvalidUTF8
+)
+
+// 重新导出的JSON修复类型常量。
+const (
+	FixTrailingComma      = utils.FixTrailingComma
+	FixSingleQuotes       = utils.FixSingleQuotes
+	FixUnquotedKey        = utils.FixUnquotedKey
+	FixUnterminatedString = utils.FixUnterminatedString
+	FixMissingClose       = utils.FixMissingClose
+	FixNonFinite          = utils.FixNonFinite
+)
+
+// 重新导出的合并策略常量。
 const (
-	TokenError        = stream.TokenError
-	TokenObjectStart  = stream.TokenObjectStart
-	TokenObjectEnd    = stream.TokenObjectEnd
-	TokenArrayStart   = stream.TokenArrayStart
-	TokenArrayEnd     = stream.TokenArrayEnd
-	TokenPropertyName = stream.TokenPropertyName
-	TokenString       = stream.TokenString
-	TokenNumber       = stream.TokenNumber
-	TokenBoolean      = stream.TokenBoolean
-	TokenNull         = stream.TokenNull
-	TokenEOF          = stream.TokenEOF
+	MergePreferSource   = utils.MergePreferSource
+	MergePreferTarget   = utils.MergePreferTarget
+	MergeFailOnConflict = utils.MergeFailOnConflict
+	ArrayReplace        = utils.ArrayReplace
+	ArrayConcat         = utils.ArrayConcat
+	ArrayUnion          = utils.ArrayUnion
+	ArrayByIndex        = utils.ArrayByIndex
 )
 
 // 重新导出的构造函数。
 var (
-	NewJSONObject          = types.NewJSONObject
-	NewJSONArray           = types.NewJSONArray
-	NewJSONArrayFromValues = types.NewJSONArrayFromValues
-	NewJSONString          = types.NewJSONString
-	NewJSONNumber          = types.NewJSONNumber
-	NewJSONBool            = types.NewJSONBool
-	NewJSONNull            = types.NewJSONNull
-	NewJSONError           = errors.NewJSONError
+	NewJSONObject                = types.NewJSONObject
+	NewMultiValueObject          = types.NewMultiValueObject
+	MultiValueObjectFrom         = types.MultiValueObjectFrom
+	NewJSONArray                 = types.NewJSONArray
+	NewJSONArrayFromValues       = types.NewJSONArrayFromValues
+	NewJSONArrayFromValuesUnsafe = types.NewJSONArrayFromValuesUnsafe
+	NewJSONString                = types.NewJSONString
+	NewJSONNumber                = types.NewJSONNumber
+	NewJSONBool                  = types.NewJSONBool
+	NewJSONNull                  = types.NewJSONNull
+	NewJSONError                 = errors.NewJSONError
 )
 
 // 重新导出的解析函数。
 var (
 	ParseToValue      = parser.ParseToValue
 	ParseBytesToValue = parser.ParseBytesToValue
+	ParseMultiValue   = parser.ParseMultiValue
 	Parse             = parser.Parse
 	ParseBytes        = parser.ParseBytes
 	Stringify         = parser.Stringify
 	StringifyBytes    = parser.StringifyBytes
 	StringifyIndent   = parser.StringifyIndent
+	MustParse         = parser.MustParse
+	ParseFile         = parser.ParseFile
+	WriteFile         = parser.WriteFile
+	NewParser         = parser.NewParser
+	DetectEncoding    = parser.DetectEncoding
+	ToUTF8            = parser.ToUTF8
+	NewUTF8Reader     = parser.NewUTF8Reader
 )
 
 // 重新导出的JSON Path函数。
@@ -112,6 +221,25 @@ var (
 	ParseJSONPath       = jsonpath.ParseJSONPath
 	QueryJSONPath       = jsonpath.QueryJSONPath
 	QueryJSONPathString = jsonpath.QueryJSONPathString
+	// CompileJSONPath 返回表达式编译后的JSONPath，编译结果保存在LRU缓存中。
+	CompileJSONPath = jsonpath.CompileJSONPath
+	// SetJSONPathCacheSize 设置编译后的JSON Path缓存最多保存的表达式数量。
+	SetJSONPathCacheSize = jsonpath.SetJSONPathCacheSize
+	ClearJSONPathCache   = jsonpath.ClearJSONPathCache
+	JSONPathCacheStats   = jsonpath.JSONPathCacheStats
+	// Query 从Reader读取JSON并查询，自动选择流式或DOM执行。
+	Query = jsonpath.QueryReader
+	// PlanQuery 返回查询将使用的执行方式。
+	PlanQuery = jsonpath.PlanQuery
+
+	// GetValueByPath 返回JSON Path在JSON字符串中匹配的第一个值。
+	GetValueByPath = jsonpath.GetValueByPath
+	// GetString 返回JSON Path匹配的第一个字符串值。
+	GetString = jsonpath.GetStringByPath
+	// GetNumber 返回JSON Path匹配的第一个数字值。
+	GetNumber = jsonpath.GetNumberByPath
+	// GetBool 返回JSON Path匹配的第一个布尔值。
+	GetBool = jsonpath.GetBoolByPath
 )
 
 // 重新导出的JSON Diff函数。
@@ -119,41 +247,151 @@ var (
 	DiffJSON           = diff.DiffJSON
 	DiffJSONStrings    = diff.DiffJSONStrings
 	DefaultDiffOptions = diff.DefaultDiffOptions
+	DiffText           = diff.DiffText
+	FormatTextEdits    = diff.FormatTextEdits
 )
 
 // 重新导出的JSON Patch函数。
 var (
-	ApplyPatch    = patch.ApplyPatch
-	GeneratePatch = diff.GeneratePatch
+	ApplyPatch           = patch.ApplyPatch
+	GeneratePatch        = diff.GeneratePatch
+	GeneratePatchChecked = diff.GeneratePatchChecked
+
+	ApplyPatchWithOptions    = patch.ApplyPatchWithOptions
+	ApplyPatchContext        = patch.ApplyPatchContext
+	DefaultApplyPatchOptions = patch.DefaultApplyPatchOptions
+
+	NewPatch   = patch.NewPatch
+	ParsePatch = patch.ParsePatch
 )
 
 // 重新导出的类型转换函数。
 var (
-	ValueToInterface = types.ValueToInterface
+	ValueToInterface     = types.ValueToInterface
+	ValueToInterfaceOpts = types.ValueToInterfaceOpts
+	FromInterface        = types.FromInterface
+	FromInterfaceOpts    = types.FromInterfaceOpts
+	FromGoValue          = types.FromGoValue
+)
+
+// 重新导出的路径访问函数。
+var (
+	// NewAccessor 创建沿着属性和索引访问嵌套值的Accessor。
+	NewAccessor = types.NewAccessor
+	// Maybe 从任意JSON值开始可选链。
+	Maybe = types.Maybe
+	// ErrMissing 是可选链的值不存在时返回的错误。
+	ErrMissing = types.ErrMissing
+	// ParsePath 把JSON Path解析为结构化的Path。
+	ParsePath = types.ParsePath
+	// ParsePointer 把JSON Pointer解析为结构化的Path。
+	ParsePointer = types.ParsePointer
+	// KeySegment 返回对象键的路径段。
+	KeySegment = types.KeySegment
+	// IndexSegment 返回数组索引的路径段。
+	IndexSegment = types.IndexSegment
+	// ChildPath 在JSON Path字符串后追加一个段。
+	ChildPath = types.ChildPath
+)
+
+// 重新导出的键排序规则。
+var (
+	// CaseInsensitiveOrder 忽略大小写比较键。
+	CaseInsensitiveOrder = types.CaseInsensitiveOrder
+	// NaturalOrder 按自然顺序比较键，"item2"排在"item10"之前。
+	NaturalOrder = types.NaturalOrder
+	// Collate 把三路比较函数（例如x/text的collate.Collator.CompareString）转换为KeyOrder。
+	Collate = types.Collate
+	// SortStrings 按KeyOrder原地排序字符串。
+	SortStrings = types.SortStrings
+)
+
+// 重新导出的索引函数。
+var (
+	// NewIndex 在JSON文档上为路径建立哈希索引。
+	NewIndex = types.NewIndex
+)
+
+// 重新导出的复制函数。
+var (
+	// DeepCopyValue 深度复制JSON值，发现循环引用时返回错误。
+	DeepCopyValue = types.DeepCopyValue
+)
+
+// 重新导出的共享存储函数。
+var (
+	// NewInternPool 创建在文档之间共享相同内容的InternPool。
+	NewInternPool = types.NewInternPool
+)
+
+// 重新导出的精确数字函数。
+var (
+	// NewJSONBigInt 从big.Int创建任意精度的整数。
+	NewJSONBigInt = types.NewJSONBigInt
+	// NewJSONBigIntFromInt64 从int64创建任意精度的整数。
+	NewJSONBigIntFromInt64 = types.NewJSONBigIntFromInt64
+	// ParseJSONBigInt 解析十进制整数字符串。
+	ParseJSONBigInt = types.ParseJSONBigInt
+	// NewJSONDecimal 创建值为unscaled × 10^-scale的定点小数。
+	NewJSONDecimal = types.NewJSONDecimal
+	// NewJSONDecimalFromInt64 从int64和小数位数创建定点小数。
+	NewJSONDecimalFromInt64 = types.NewJSONDecimalFromInt64
+	// NewJSONDecimalFromFloat 按float64的最短十进制表示创建定点小数。
+	NewJSONDecimalFromFloat = types.NewJSONDecimalFromFloat
+	// ParseJSONDecimal 解析十进制数字字符串。
+	ParseJSONDecimal = types.ParseJSONDecimal
+	// IsExactNumber 检查值是否为JSONBigInt或JSONDecimal。
+	IsExactNumber = types.IsExactNumber
+	// CompareNumbers 精确比较两个数字值。
+	CompareNumbers = types.CompareNumbers
+	// NumbersEqual 检查两个数字值是否相等。
+	NumbersEqual = types.NumbersEqual
+	// Equal 深度比较两个JSON值，数字精确比较。
+	Equal = types.Equal
+	// ParseNumber 解析数字文本，float64不能精确表示时返回JSONBigInt或JSONDecimal。
+	ParseNumber = types.ParseNumber
+	// ToDecimal 将数字值转换为JSONDecimal。
+	ToDecimal = types.ToDecimal
+	// ToBigInt 将整数值转换为JSONBigInt。
+	ToBigInt = types.ToBigInt
+	// AddNumbers 返回两个数字值的和。
+	AddNumbers = types.AddNumbers
+	// SubNumbers 返回两个数字值的差。
+	SubNumbers = types.SubNumbers
+	// MulNumbers 返回两个数字值的积。
+	MulNumbers = types.MulNumbers
+)
+
+// 重新导出的数字转换策略函数。
+var (
+	// DefaultNumericPolicy 返回截断小数部分、超出范围时取边界值的默认策略。
+	DefaultNumericPolicy = types.DefaultNumericPolicy
+	// StrictNumericPolicy 返回小数部分、超出范围和丢失精度都返回错误的策略。
+	StrictNumericPolicy = types.StrictNumericPolicy
+	// RoundNumericPolicy 返回四舍五入的策略。
+	RoundNumericPolicy = types.RoundNumericPolicy
+	// SetNumericPolicy 设置全局的数字转换策略。
+	SetNumericPolicy = types.SetNumericPolicy
+	// CurrentNumericPolicy 返回当前的全局数字转换策略。
+	CurrentNumericPolicy = types.CurrentNumericPolicy
 )
 
-// 重新导出的性能优化函数。
+// 重新导出的遍历函数。
 var (
-	// FastMarshal 是一个优化的JSON序列化函数。
-	FastMarshal = fast.Marshal
-	// FastUnmarshal 是一个优化的JSON反序列化函数。
-	FastUnmarshal = fast.Unmarshal
-	// CacheFragment 缓存JSON片段。
-	CacheFragment = fast.CacheFragment
-	// GetCachedFragment 获取缓存的JSON片段。
-	GetCachedFragment = fast.GetCachedFragment
-	// ClearFragmentCache 清空片段缓存。
-	ClearFragmentCache = fast.ClearFragmentCache
+	// Accept 使用Visitor遍历JSON值。
+	Accept = types.Accept
+	// SkipChildren 表示跳过容器的内容。
+	SkipChildren = types.SkipChildren
 )
 
-// 重新导出的流式处理函数。
+// 重新导出的流式编码函数。
 var (
-	// NewJSONTokenizer 创建一个新的JSON流式解析器。
-	NewJSONTokenizer = stream.NewJSONTokenizer
-	// NewJSONGenerator 创建一个新的JSON流式生成器。
-	NewJSONGenerator = stream.NewJSONGenerator
-	// NewIncrementalParser 创建一个新的增量JSON解析器。
-	NewIncrementalParser = stream.NewIncrementalParser
+	// WriteJSON 把JSON值直接写入io.Writer。
+	WriteJSON = types.WriteJSON
+	// SetStreamingThreshold 设置String和MarshalJSON改用流式编码的节点数。
+	SetStreamingThreshold = types.SetStreamingThreshold
+	// StreamingThreshold 返回当前的流式编码阈值。
+	StreamingThreshold = types.StreamingThreshold
 )
 
 // 注意：泛型函数不能直接导出，需要在使用时导入generic包并指定类型参数
@@ -164,14 +402,54 @@ var (
 var (
 	// FormatJSON 格式化JSON字符串。
 	FormatJSON = utils.FormatJSON
+	// FormatJSONWithOptions 按美化选项格式化JSON字符串。
+	FormatJSONWithOptions = utils.FormatJSONWithOptions
+	// DefaultPrettyOptions 返回默认的美化选项。
+	DefaultPrettyOptions = utils.DefaultPrettyOptions
 	// CompactJSON 压缩JSON字符串。
 	CompactJSON = utils.CompactJSON
+	// QuoteJSONString 返回字符串的JSON字面量，包括双引号。
+	QuoteJSONString = utils.QuoteJSONString
+	// AppendQuotedJSONString 把字符串的JSON字面量追加到字节切片。
+	AppendQuotedJSONString = utils.AppendQuotedJSONString
+	// UnquoteJSONString 解码包括双引号的JSON字符串字面量。
+	UnquoteJSONString = utils.UnquoteJSONString
+	// ReindentJSON 只调整JSON字符串的缩进，保留其他写法。
+	ReindentJSON = utils.ReindentJSON
+	// RepairJSON 修复几乎合法的JSON并报告每一处修复。
+	RepairJSON = utils.Repair
 	// SortJSONKeys 对JSON对象的键进行排序。
 	SortJSONKeys = utils.SortJSONKeys
 	// ValidateJSON 验证JSON字符串是否有效。
 	ValidateJSON = utils.ValidateJSON
+	// ValidateJSONBytes 验证JSON字节是否有效。
+	ValidateJSONBytes = utils.ValidateJSONBytes
 	// MergeJSON 合并两个JSON对象。
 	MergeJSON = utils.MergeJSON
+	// MergeValues 深度合并两个JSON对象值。
+	MergeValues = utils.MergeValues
+	// MergeJSONWithOptions 按选项深度合并两个JSON字符串并报告冲突。
+	MergeJSONWithOptions = utils.MergeJSONWithOptions
+	// MergeValuesWithOptions 按选项深度合并两个JSON值并报告冲突。
+	MergeValuesWithOptions = utils.MergeValuesWithOptions
+	// DefaultMergeOptions 返回默认的合并选项。
+	DefaultMergeOptions = utils.DefaultMergeOptions
+	// SortKeys 返回所有对象的键都已排序的副本。
+	SortKeys = utils.SortKeys
+	// SortKeysBy 返回所有对象的键都按指定规则排序的副本。
+	SortKeysBy = utils.SortKeysBy
 	// DeepCopy 深度复制JSON值。
 	DeepCopy = utils.DeepCopy
+	// DeepCopyChecked 深度复制JSON值，发现循环引用时返回错误。
+	DeepCopyChecked = utils.DeepCopyChecked
+	// Normalize 返回JSON值的规范化副本。
+	Normalize = utils.Normalize
+	// SortArrayBy 按JSON Path选择的键对数组排序。
+	SortArrayBy = utils.SortArrayBy
+	// DedupArray 去除数组中的重复元素。
+	DedupArray = utils.DedupArray
+	// ToColumnar 把对象数组转换为列式布局。
+	ToColumnar = utils.ToColumnar
+	// FromColumnar 把列式布局转换回对象数组。
+	FromColumnar = utils.FromColumnar
 )
@@ -0,0 +1,36 @@
+//go:build !gojson_nofast
+
+package gojson
+
+import (
+	"github.com/UserLeeZJ/gojson/fast"
+)
+
+// FastMarshalOptions 是FastMarshalWithOptions的选项。
+type FastMarshalOptions = fast.Options
+
+// 重新导出的性能优化函数。
+var (
+	// FastMarshal 是一个优化的JSON序列化函数。
+	FastMarshal = fast.Marshal
+	// FastMarshalWithOptions 按指定的选项序列化，可以转义HTML和缩进。
+	FastMarshalWithOptions = fast.MarshalWithOptions
+	// DefaultFastMarshalOptions 返回FastMarshal使用的选项。
+	DefaultFastMarshalOptions = fast.DefaultOptions
+	// FastUnmarshal 是一个优化的JSON反序列化函数。
+	FastUnmarshal = fast.Unmarshal
+	// CacheFragment 缓存JSON片段。
+	CacheFragment = fast.CacheFragment
+	// GetCachedFragment 获取缓存的JSON片段。
+	GetCachedFragment = fast.GetCachedFragment
+	// ClearFragmentCache 清空片段缓存。
+	ClearFragmentCache = fast.ClearFragmentCache
+	// SetFastSmallMapThreshold 设置FastMarshal对map使用快速路径的阈值。
+	SetFastSmallMapThreshold = fast.SetSmallMapThreshold
+	// SetFastSmallArrayThreshold 设置FastMarshal对数组使用快速路径的阈值。
+	SetFastSmallArrayThreshold = fast.SetSmallArrayThreshold
+	// SetFastSortMapKeys 设置FastMarshal是否按键排序输出map。
+	SetFastSortMapKeys = fast.SetSortMapKeys
+	// FastSortMapKeys 返回FastMarshal是否按键排序输出map。
+	FastSortMapKeys = fast.SortMapKeys
+)
@@ -0,0 +1,90 @@
+//go:build !gojson_nostream
+
+package gojson
+
+import (
+	"github.com/UserLeeZJ/gojson/stream"
+)
+
+// 重新导出的流式处理类型。
+type (
+	JSONTokenType     = stream.JSONTokenType
+	JSONToken         = stream.JSONToken
+	JSONTokenizer     = stream.JSONTokenizer
+	JSONGenerator     = stream.JSONGenerator
+	GeneratorOptions  = stream.GeneratorOptions
+	TokenFilter       = stream.TokenFilter
+	Pipeline          = stream.Pipeline
+	SubscribeStats    = stream.SubscribeStats
+	IncrementalParser = stream.IncrementalParser
+	ElementReader     = stream.ElementReader
+	Sampler           = stream.Sampler
+	SampleOptions     = stream.SampleOptions
+	Framing           = stream.Framing
+	FramingOptions    = stream.FramingOptions
+	MessageReader     = stream.MessageReader
+	MessageWriter     = stream.MessageWriter
+	MessageSource     = stream.MessageSource
+)
+
+// 重新导出的流式处理常量。
+const (
+	TokenError        = stream.TokenError
+	TokenObjectStart  = stream.TokenObjectStart
+	TokenObjectEnd    = stream.TokenObjectEnd
+	TokenArrayStart   = stream.TokenArrayStart
+	TokenArrayEnd     = stream.TokenArrayEnd
+	TokenPropertyName = stream.TokenPropertyName
+	TokenString       = stream.TokenString
+	TokenNumber       = stream.TokenNumber
+	TokenBoolean      = stream.TokenBoolean
+	TokenNull         = stream.TokenNull
+	TokenEOF          = stream.TokenEOF
+
+	FramingConcatenated = stream.FramingConcatenated
+	FramingNewline      = stream.FramingNewline
+	FramingUvarint      = stream.FramingUvarint
+	FramingUint32       = stream.FramingUint32
+)
+
+// 重新导出的流式处理函数。
+var (
+	// NewJSONTokenizer 创建一个新的JSON流式解析器。
+	NewJSONTokenizer = stream.NewJSONTokenizer
+	// NewJSONGenerator 创建一个新的JSON流式生成器。
+	NewJSONGenerator = stream.NewJSONGenerator
+	// NewJSONGeneratorWithOptions 创建按指定策略缓冲和刷新的JSON流式生成器。
+	NewJSONGeneratorWithOptions = stream.NewJSONGeneratorWithOptions
+	// NewIncrementalParser 创建一个新的增量JSON解析器。
+	NewIncrementalParser = stream.NewIncrementalParser
+	// NewElementReader 创建逐个读取路径匹配值的读取器。
+	NewElementReader = stream.NewElementReader
+	// ParseValueAtPath 跳过路径之外的内容，只解析路径处的值。
+	ParseValueAtPath = stream.ParseValueAtPath
+	// NewSampler 创建一个新的采样器。
+	NewSampler = stream.NewSampler
+	// NewMessageReader 创建按分帧方式逐条读取JSON消息的读取器。
+	NewMessageReader = stream.NewMessageReader
+	// NewMessageWriter 创建按分帧方式逐条写入JSON消息的写入器。
+	NewMessageWriter = stream.NewMessageWriter
+	// NewMessageSource 创建把每条消息解析为JSON值的数据源。
+	NewMessageSource = stream.NewMessageSource
+	// ParseFraming 根据名称返回分帧方式。
+	ParseFraming = stream.ParseFraming
+	// NewPipeline 创建把令牌经过过滤器写入生成器的管道。
+	NewPipeline = stream.NewPipeline
+	// ChainFilters 把多个令牌过滤器连接为一个。
+	ChainFilters = stream.ChainFilters
+	// RenameKeys 创建重命名属性名的令牌过滤器。
+	RenameKeys = stream.RenameKeys
+	// RedactPaths 创建替换路径上的值的令牌过滤器。
+	RedactPaths = stream.RedactPaths
+	// PrunePaths 创建删除路径上的值的令牌过滤器。
+	PrunePaths = stream.PrunePaths
+	// Subscribe 在读取输入的同时对每个路径匹配的值调用回调。
+	Subscribe = stream.Subscribe
+	// SubscribeSource 对数据源产生的每个值调用回调。
+	SubscribeSource = stream.SubscribeSource
+	// StopSubscription 由订阅的回调返回，表示停止订阅。
+	StopSubscription = stream.StopSubscription
+)
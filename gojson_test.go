@@ -0,0 +1,102 @@
+package gojson
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// 根包中的类型都是types包的别名，下面的赋值在类型不一致时无法编译
+var (
+	_ *types.JSONObject = NewJSONObject()
+	_ *types.JSONArray  = NewJSONArray()
+	_ *JSONObject       = types.NewJSONObject()
+	_ *JSONArray        = types.NewJSONArray()
+	_ types.JSONValue   = JSONValue(NewJSONNull())
+)
+
+// exportedNames 返回目录中非测试文件声明的导出的顶层标识符
+func exportedNames(t *testing.T, dir string) map[string]bool {
+	t.Helper()
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("解析 %s 失败: %v", file, err)
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Name.IsExported() {
+					names[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if s.Name.IsExported() {
+							names[s.Name.Name] = true
+						}
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							if name.IsExported() {
+								names[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// TestTypesParity 检查types包导出的类型和函数都在根包中重新导出，
+// 新功能只需要在types包中实现一次
+func TestTypesParity(t *testing.T) {
+	if _, err := os.Stat("types"); err != nil {
+		t.Skip("找不到types包的源码")
+	}
+	root := exportedNames(t, ".")
+	for name := range exportedNames(t, "types") {
+		if !root[name] {
+			t.Errorf("types.%s 没有在根包中重新导出", name)
+		}
+	}
+}
+
+// TestAliasMethods 检查通过根包创建的值可以直接使用types包中的方法
+func TestAliasMethods(t *testing.T) {
+	arr := NewJSONArray().AddNumber(1).AddNumber(2).AddNumber(3)
+	if got := arr.Slice(1, 3).String(); got != "[2,3]" {
+		t.Errorf("Slice() = %s, 期望 [2,3]", got)
+	}
+
+	obj := NewJSONObject().PutString("name", "gojson")
+	value, err := ParseToValue(`{"name":"gojson"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := value.AsObject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.String() != obj.String() {
+		t.Errorf("ParseToValue() = %s, 期望 %s", parsed.String(), obj.String())
+	}
+}
@@ -0,0 +1,199 @@
+// Package jsonstr 实现JSON字符串的引号和转义，供stream的解析器和生成器以及utils共用。
+package jsonstr
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// AppendQuote 把s加上双引号和必要的转义后追加到dst，返回追加后的切片。
+// 总是转义双引号、反斜杠、控制字符和U+2028、U+2029，无效的UTF-8字节转义为\ufffd；
+// escapeHTML为true时转义<、>和&，ascii为true时把所有非ASCII字符转义为\uXXXX（必要时使用代理对）
+func AppendQuote(dst []byte, s string, escapeHTML, ascii bool) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || c != '<' && c != '>' && c != '&') {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = appendUnicodeEscape(dst, rune(c))
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029' || ascii:
+			dst = append(dst, s[start:i]...)
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				dst = appendUnicodeEscape(dst, r1)
+				dst = appendUnicodeEscape(dst, r2)
+			} else {
+				dst = appendUnicodeEscape(dst, r)
+			}
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// appendUnicodeEscape 把基本多文种平面中的字符r以\uXXXX的形式追加到dst
+func appendUnicodeEscape(dst []byte, r rune) []byte {
+	return append(dst, '\\', 'u', hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+}
+
+// Unquote 解码带双引号的JSON字符串data，规则与encoding/json相同：未转义的控制字符和双引号、
+// 无效的转义是错误，不成对的代理项和无效的UTF-8字节替换为U+FFFD。
+// 不需要解码转义的字符串只分配一次
+func Unquote(data []byte) (string, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return "", jsonerrors.ErrInvalidJSONWithDetails("字符串必须以双引号开始和结束")
+	}
+	body := data[1 : len(data)-1]
+
+	// 没有转义和无效字节时直接转换
+	i := 0
+	for i < len(body) {
+		c := body[i]
+		if c < utf8.RuneSelf {
+			if c == '\\' || c == '"' || c < 0x20 {
+				break
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		i += size
+	}
+	if i == len(body) {
+		return string(body), nil
+	}
+
+	out := make([]byte, i, len(body))
+	copy(out, body[:i])
+	for i < len(body) {
+		c := body[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(body) {
+				return "", invalidString("字符串以反斜杠结束", i+1)
+			}
+			switch body[i+1] {
+			case '"', '\\', '/':
+				out = append(out, body[i+1])
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'u':
+				r, ok := hexCode(body, i+2)
+				if !ok {
+					return "", invalidString("无效的\\u转义", i+1)
+				}
+				i += 6
+				if utf16.IsSurrogate(r) {
+					// 后面紧跟能组成代理对的\u转义时一起解码，否则替换为U+FFFD
+					r2, ok := rune(0), false
+					if i+1 < len(body) && body[i] == '\\' && body[i+1] == 'u' {
+						r2, ok = hexCode(body, i+2)
+					}
+					if dec := utf16.DecodeRune(r, r2); ok && dec != utf8.RuneError {
+						r = dec
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				out = utf8.AppendRune(out, r)
+				continue
+			default:
+				return "", invalidString(fmt.Sprintf("无效的转义\\%c", body[i+1]), i+1)
+			}
+			i += 2
+		case c == '"':
+			return "", invalidString("字符串中有未转义的双引号", i+1)
+		case c < 0x20:
+			return "", invalidString("字符串中有未转义的控制字符", i+1)
+		case c < utf8.RuneSelf:
+			out = append(out, c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(body[i:])
+			if r == utf8.RuneError && size == 1 {
+				out = append(out, "\ufffd"...)
+			} else {
+				out = append(out, body[i:i+size]...)
+			}
+			i += size
+		}
+	}
+	return string(out), nil
+}
+
+// invalidString 返回字符串在data中的偏移offset处无效的错误
+func invalidString(reason string, offset int) error {
+	return jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("%s (偏移 %d)", reason, offset))
+}
+
+// hexCode 解析从i开始的4个十六进制数字
+func hexCode(data []byte, i int) (rune, bool) {
+	if i+4 > len(data) {
+		return 0, false
+	}
+	var code rune
+	for _, b := range data[i : i+4] {
+		switch {
+		case b >= '0' && b <= '9':
+			code = code<<4 | rune(b-'0')
+		case b >= 'a' && b <= 'f':
+			code = code<<4 | rune(b-'a'+10)
+		case b >= 'A' && b <= 'F':
+			code = code<<4 | rune(b-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return code, true
+}
@@ -0,0 +1,131 @@
+package jsonpath
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheSize 是编译后的JSON Path缓存默认最多保存的表达式数量
+const DefaultCacheSize = 512
+
+// CacheStats 是编译后的JSON Path缓存的统计信息
+type CacheStats struct {
+	// Entries 是缓存中的表达式数量
+	Entries int
+	// MaxEntries 是缓存最多保存的表达式数量，为0表示缓存已关闭
+	MaxEntries int
+	// Hits 是在缓存中找到编译结果的次数
+	Hits uint64
+	// Misses 是需要解析表达式的次数
+	Misses uint64
+}
+
+// pathCache 是按表达式保存编译结果的LRU缓存
+type pathCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*list.Element
+	order   *list.List // 元素是*cacheEntry，最近使用的在前
+	hits    uint64
+	misses  uint64
+}
+
+// cacheEntry 是缓存中的一个表达式
+type cacheEntry struct {
+	expr string
+	path *JSONPath
+}
+
+// cache 是CompileJSONPath和QueryJSONPath使用的包级别缓存
+var cache = &pathCache{
+	max:     DefaultCacheSize,
+	entries: make(map[string]*list.Element),
+	order:   list.New(),
+}
+
+// CompileJSONPath 返回表达式编译后的JSONPath，先在包级别的LRU缓存中查找，没有时解析并放入缓存。
+// 返回的JSONPath可能被其他调用者共享，JSONPath的所有方法都不会修改它，可以在多个goroutine中同时使用。
+// 解析失败的表达式不会被缓存
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	if jp := cache.get(expr); jp != nil {
+		return jp, nil
+	}
+	jp, err := ParseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(expr, jp)
+	return jp, nil
+}
+
+// SetJSONPathCacheSize 设置缓存最多保存的表达式数量并返回原来的值，超出的条目按最久未使用的顺序淘汰。
+// n不大于0时关闭缓存并清空已有的条目
+func SetJSONPathCacheSize(n int) int {
+	if n < 0 {
+		n = 0
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	old := cache.max
+	cache.max = n
+	cache.evict()
+	return old
+}
+
+// ClearJSONPathCache 清空缓存中的表达式和统计信息，不改变缓存的大小
+func ClearJSONPathCache() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries = make(map[string]*list.Element)
+	cache.order.Init()
+	cache.hits, cache.misses = 0, 0
+}
+
+// JSONPathCacheStats 返回缓存的统计信息
+func JSONPathCacheStats() CacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return CacheStats{
+		Entries:    cache.order.Len(),
+		MaxEntries: cache.max,
+		Hits:       cache.hits,
+		Misses:     cache.misses,
+	}
+}
+
+// get 返回缓存的编译结果并把它标记为最近使用，不存在时返回nil
+func (c *pathCache) get(expr string) *JSONPath {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[expr]; ok {
+		c.hits++
+		c.order.MoveToFront(e)
+		return e.Value.(*cacheEntry).path
+	}
+	c.misses++
+	return nil
+}
+
+// put 放入编译结果，其他goroutine已经放入了同一个表达式时保留已有的
+func (c *pathCache) put(expr string, jp *JSONPath) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.max == 0 {
+		return
+	}
+	if e, ok := c.entries[expr]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[expr] = c.order.PushFront(&cacheEntry{expr: expr, path: jp})
+	c.evict()
+}
+
+// evict 淘汰最久未使用的条目，直到条目数量不超过max，调用时需要持有锁
+func (c *pathCache) evict() {
+	for c.order.Len() > c.max {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).expr)
+	}
+}
@@ -0,0 +1,111 @@
+package jsonpath
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestCompileJSONPathCache(t *testing.T) {
+	old := SetJSONPathCacheSize(2)
+	defer SetJSONPathCacheSize(old)
+	ClearJSONPathCache()
+
+	a, err := CompileJSONPath("$.a")
+	if err != nil {
+		t.Fatalf("CompileJSONPath($.a) error = %v", err)
+	}
+	if again, _ := CompileJSONPath("$.a"); again != a {
+		t.Errorf("CompileJSONPath($.a) 应该返回缓存的结果")
+	}
+	CompileJSONPath("$.b")
+	CompileJSONPath("$.a") // $.b 成为最久未使用的
+	CompileJSONPath("$.c")
+
+	stats := JSONPathCacheStats()
+	if stats.Entries != 2 || stats.MaxEntries != 2 || stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("JSONPathCacheStats() = %+v", stats)
+	}
+	if again, _ := CompileJSONPath("$.a"); again != a {
+		t.Errorf("$.a 不应该被淘汰")
+	}
+	if JSONPathCacheStats().Misses != 3 {
+		t.Errorf("$.a 应该命中缓存")
+	}
+	CompileJSONPath("$.b")
+	if JSONPathCacheStats().Misses != 4 {
+		t.Errorf("$.b 应该已经被淘汰")
+	}
+
+	// 解析失败的表达式不缓存
+	if _, err := CompileJSONPath("$["); err == nil {
+		t.Errorf("CompileJSONPath($[) 应该返回错误")
+	}
+	if got := JSONPathCacheStats().Entries; got != 2 {
+		t.Errorf("Entries = %d, want 2", got)
+	}
+
+	// 缩小时淘汰多余的条目，为0时关闭缓存
+	if prev := SetJSONPathCacheSize(1); prev != 2 {
+		t.Errorf("SetJSONPathCacheSize() = %d, want 2", prev)
+	}
+	if got := JSONPathCacheStats().Entries; got != 1 {
+		t.Errorf("Entries = %d, want 1", got)
+	}
+	SetJSONPathCacheSize(0)
+	x, _ := CompileJSONPath("$.x")
+	if y, _ := CompileJSONPath("$.x"); x == y || JSONPathCacheStats().Entries != 0 {
+		t.Errorf("关闭缓存后不应该缓存表达式")
+	}
+
+	ClearJSONPathCache()
+	if stats := JSONPathCacheStats(); stats.Hits != 0 || stats.Misses != 0 || stats.Entries != 0 {
+		t.Errorf("ClearJSONPathCache() 之后 stats = %+v", stats)
+	}
+}
+
+func TestCompileJSONPathConcurrent(t *testing.T) {
+	old := SetJSONPathCacheSize(8)
+	defer SetJSONPathCacheSize(old)
+
+	value := parser.MustParse(`{"items": [1, 2, 3], "n": {"a": 1}}`)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				expr := fmt.Sprintf("$.items[%d]", (g+i)%16)
+				if _, err := QueryJSONPath(value, expr); err != nil {
+					t.Errorf("QueryJSONPath(%s) error = %v", expr, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if stats := JSONPathCacheStats(); stats.Entries > 8 {
+		t.Errorf("Entries = %d, 不应该超过8", stats.Entries)
+	}
+}
+
+func BenchmarkQueryJSONPath(b *testing.B) {
+	value := parser.MustParse(`{"store": {"book": [{"price": 8}, {"price": 12}]}}`)
+	const expr = "$.store.book[?(@.price > 10)].price"
+
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			jp, _ := ParseJSONPath(expr)
+			jp.Query(value)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			QueryJSONPath(value, expr)
+		}
+	})
+}
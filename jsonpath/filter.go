@@ -0,0 +1,514 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// filterSegment 表示过滤表达式 [?(...)]，按顺序返回数组中或对象中使条件为真的值。
+// 条件中的@表示当前被检查的值，$表示查询的根节点
+type filterSegment struct {
+	cond filterExpr
+	src  string
+}
+
+func (s *filterSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
+	if value.IsObject() {
+		obj, _ := value.AsObject()
+		for _, key := range obj.Keys() {
+			if member := obj.Get(key); s.cond.test(member, root) {
+				dst = append(dst, member)
+			}
+		}
+		return dst, nil
+	} else if value.IsArray() {
+		arr, _ := value.AsArray()
+		for _, elem := range arr.Values() {
+			if s.cond.test(elem, root) {
+				dst = append(dst, elem)
+			}
+		}
+		return dst, nil
+	}
+	return nil, jsonerrors.ErrInvalidTypeWithDetails("object or array", value.Type())
+}
+
+func (s *filterSegment) capacity(values []types.JSONValue) int {
+	return len(values)
+}
+
+func (s *filterSegment) String() string {
+	return "[?" + s.src + "]"
+}
+
+// filterExpr 是过滤条件的语法树节点
+type filterExpr interface {
+	// test 返回条件对当前值current是否为真
+	test(current, root types.JSONValue) bool
+}
+
+// orExpr 是 a || b
+type orExpr struct {
+	left, right filterExpr
+}
+
+func (e *orExpr) test(current, root types.JSONValue) bool {
+	return e.left.test(current, root) || e.right.test(current, root)
+}
+
+// andExpr 是 a && b
+type andExpr struct {
+	left, right filterExpr
+}
+
+func (e *andExpr) test(current, root types.JSONValue) bool {
+	return e.left.test(current, root) && e.right.test(current, root)
+}
+
+// notExpr 是 !a
+type notExpr struct {
+	operand filterExpr
+}
+
+func (e *notExpr) test(current, root types.JSONValue) bool {
+	return !e.operand.test(current, root)
+}
+
+// existsExpr 检查路径是否匹配至少一个值，值为null时也算存在
+type existsExpr struct {
+	path *filterPath
+}
+
+func (e *existsExpr) test(current, root types.JSONValue) bool {
+	results, err := e.path.query(current, root)
+	return err == nil && len(results) > 0
+}
+
+// compareExpr 是比较运算
+type compareExpr struct {
+	op          string
+	left, right filterOperand
+}
+
+// test 比较两个操作数。路径不是恰好匹配一个值时视为不存在：两侧都不存在时==为真，
+// 只有一侧不存在时==为假；<、<=、>、>=只比较两个数字或两个字符串，其他情况为假。!=总是与==相反
+func (e *compareExpr) test(current, root types.JSONValue) bool {
+	left, leftOK := e.left.value(current, root)
+	right, rightOK := e.right.value(current, root)
+
+	switch e.op {
+	case "==", "!=":
+		eq := leftOK == rightOK && (!leftOK || types.Equal(left, right))
+		return eq == (e.op == "==")
+	}
+	if !leftOK || !rightOK {
+		return false
+	}
+
+	var c int
+	switch {
+	case left.IsNumber() && right.IsNumber():
+		cmp, err := types.CompareNumbers(left, right)
+		if err != nil {
+			return false
+		}
+		c = cmp
+	case left.IsString() && right.IsString():
+		x, _ := left.AsString()
+		y, _ := right.AsString()
+		c = strings.Compare(x, y)
+	default:
+		return false
+	}
+
+	switch e.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// filterOperand 是比较运算的操作数
+type filterOperand interface {
+	// value 返回操作数的值，值不存在时第二个返回值为false
+	value(current, root types.JSONValue) (types.JSONValue, bool)
+}
+
+// literalOperand 是字面量
+type literalOperand struct {
+	literal types.JSONValue
+}
+
+func (o *literalOperand) value(types.JSONValue, types.JSONValue) (types.JSONValue, bool) {
+	return o.literal, true
+}
+
+// filterPath 是以@或$开头的路径
+type filterPath struct {
+	segments []pathSegment
+	function *pathFunction // 路径末尾的聚合函数，没有时为nil
+	relative bool          // 以@开头
+}
+
+// query 返回路径匹配的所有值
+func (p *filterPath) query(current, root types.JSONValue) ([]types.JSONValue, error) {
+	start := root
+	if p.relative {
+		start = current
+	}
+	results, err := appendSegments(nil, p.segments, []types.JSONValue{start}, root)
+	if err != nil || p.function == nil {
+		return results, err
+	}
+	result, err := p.function.call(results)
+	if err != nil {
+		return nil, err
+	}
+	return []types.JSONValue{result}, nil
+}
+
+// value 返回路径匹配的唯一的值，没有匹配或匹配多个值时视为不存在
+func (p *filterPath) value(current, root types.JSONValue) (types.JSONValue, bool) {
+	results, err := p.query(current, root)
+	if err != nil || len(results) != 1 {
+		return nil, false
+	}
+	return results[0], true
+}
+
+// filterToken 是过滤条件中的一个词法单元
+type filterToken struct {
+	text    string
+	pos     int
+	path    *filterPath     // 路径
+	literal types.JSONValue // 数字、字符串、true、false和null
+}
+
+// filterOperators 是过滤条件中的运算符，较长的运算符排在前面以便优先匹配
+var filterOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"}
+
+// parseFilter 解析[?...]中?之后的过滤条件，条件外的括号可以省略
+func parseFilter(src string) (*filterSegment, error) {
+	tokens, err := tokenizeFilter(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "过滤条件不能为空")
+	}
+	p := &filterParser{src: src, tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+	return &filterSegment{cond: cond, src: src}, nil
+}
+
+// filterError 返回过滤条件在pos处的语法错误
+func filterError(src string, pos int, reason string) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+		fmt.Sprintf("无效的过滤表达式%q: 第%d个字符处%s", src, pos+1, reason))
+}
+
+// tokenizeFilter 把过滤条件切分为词法单元
+func tokenizeFilter(src string) ([]filterToken, error) {
+	var tokens []filterToken
+	pos := 0
+	for pos < len(src) {
+		c := src[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '@' || c == '$':
+			end, err := scanFilterPath(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			jp, err := ParseJSONPath("$" + src[pos+1:end])
+			if err != nil {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+					fmt.Sprintf("无效的过滤表达式%q: 第%d个字符处的路径无效", src, pos+1)).WithCause(err)
+			}
+			path := &filterPath{segments: jp.segments[1:], function: jp.function, relative: c == '@'}
+			tokens = append(tokens, filterToken{text: src[pos:end], pos: pos, path: path})
+			pos = end
+		case c == '\'' || c == '"':
+			end := pos + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, filterError(src, pos, "的字符串没有结束")
+			}
+			end++
+			s, err := unquoteFilterString(src[pos:end])
+			if err != nil {
+				return nil, filterError(src, pos, "的字符串无效")
+			}
+			tokens = append(tokens, filterToken{text: src[pos:end], pos: pos, literal: types.NewJSONString(s)})
+			pos = end
+		case c == '-' || c >= '0' && c <= '9':
+			end := pos + 1
+			for end < len(src) && strings.IndexByte("0123456789.eE+-", src[end]) >= 0 {
+				end++
+			}
+			n, err := filterNumber(src[pos:end])
+			if err != nil {
+				return nil, filterError(src, pos, "的数字无效")
+			}
+			tokens = append(tokens, filterToken{text: src[pos:end], pos: pos, literal: n})
+			pos = end
+		default:
+			if literal, word := filterKeyword(src[pos:]); literal != nil {
+				tokens = append(tokens, filterToken{text: word, pos: pos, literal: literal})
+				pos += len(word)
+				continue
+			}
+			op := ""
+			for _, candidate := range filterOperators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, filterError(src, pos, fmt.Sprintf("无法识别的字符%q", c))
+			}
+			tokens = append(tokens, filterToken{text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// filterKeyword 识别s开头的true、false和null
+func filterKeyword(s string) (types.JSONValue, string) {
+	for _, word := range []string{"true", "false", "null"} {
+		if !strings.HasPrefix(s, word) || len(s) > len(word) && isFilterIdentPart(s[len(word)]) {
+			continue
+		}
+		switch word {
+		case "true":
+			return types.NewJSONBool(true), word
+		case "false":
+			return types.NewJSONBool(false), word
+		default:
+			return types.NewJSONNull(), word
+		}
+	}
+	return nil, ""
+}
+
+func isFilterIdentPart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// filterNumber 解析数字字面量，float64不能精确表示的数字解析为JSONBigInt或JSONDecimal，
+// 使比较不受float64舍入的影响
+func filterNumber(text string) (types.JSONValue, error) {
+	if n, err := types.ParseNumber(text); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewJSONNumber(f), nil
+}
+
+// unquoteFilterString 解码单引号或双引号字符串，转义规则与Go的双引号字符串相同
+func unquoteFilterString(text string) (string, error) {
+	body := text[1 : len(text)-1]
+	if text[0] == '\'' {
+		// 单引号字符串中的\'表示单引号，双引号不需要转义
+		body = strings.ReplaceAll(body, `\'`, `'`)
+		body = strings.ReplaceAll(body, `"`, `\"`)
+	}
+	return strconv.Unquote(`"` + body + `"`)
+}
+
+// scanFilterPath 返回从pos处的@或$开始的路径的结束位置
+func scanFilterPath(src string, pos int) (int, error) {
+	end, errPos, reason := pathEnd(src, pos)
+	if reason != "" {
+		return 0, filterError(src, errPos, reason)
+	}
+	return end, nil
+}
+
+// pathEnd 返回从pos处的@或$开始的路径的结束位置。
+// 路径由.name、..、.*、[...]和末尾的聚合函数组成，方括号中可以有带引号的属性名和嵌套的过滤表达式。
+// 路径不完整时返回出错的位置和原因
+func pathEnd(src string, pos int) (end, errPos int, reason string) {
+	end = pos + 1
+	for end < len(src) {
+		switch src[end] {
+		case '.':
+			next := end + 1
+			if next < len(src) && src[next] == '.' {
+				next++
+			}
+			if next < len(src) && src[next] == '*' {
+				end = next + 1
+				continue
+			}
+			if next < len(src) && src[next] == '[' {
+				end = next
+				continue
+			}
+			start := next
+			for next < len(src) && isFilterIdentPart(src[next]) {
+				next++
+			}
+			if next == start {
+				return 0, end, "的.后面缺少属性名"
+			}
+			if strings.HasPrefix(src[next:], "()") {
+				// 路径末尾的聚合函数，例如@.tags.length()
+				next += 2
+			}
+			end = next
+		case '[':
+			n := bracketEnd(src[end:])
+			if n < 0 {
+				return 0, end, "的[没有对应的]"
+			}
+			end += n
+		default:
+			return end, 0, ""
+		}
+	}
+	return end, 0, ""
+}
+
+// filterParser 是过滤条件的递归下降语法分析器，优先级从低到高为||、&&、!和比较运算
+type filterParser struct {
+	src    string
+	tokens []filterToken
+	pos    int
+}
+
+// accept 在当前词法单元是运算符op时前进并返回true
+func (p *filterParser) accept(op string) bool {
+	if p.pos < len(p.tokens) {
+		if tok := p.tokens[p.pos]; tok.path == nil && tok.literal == nil && tok.text == op {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+// unexpected 返回当前词法单元处的语法错误
+func (p *filterParser) unexpected() error {
+	if p.pos >= len(p.tokens) {
+		return filterError(p.src, len(p.src), "意外结束")
+	}
+	tok := p.tokens[p.pos]
+	return filterError(p.src, tok.pos, fmt.Sprintf("不应该出现%s", tok.text))
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{operand: operand}, nil
+	}
+	if p.accept("(") {
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected()
+		}
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison 解析比较运算，或者只有一个路径时的存在性检查
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, path, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, _, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	if path == nil {
+		p.pos--
+		return nil, filterError(p.src, p.tokens[p.pos].pos, "的字面量缺少比较运算")
+	}
+	return &existsExpr{path: path}, nil
+}
+
+// parseOperand 解析路径或字面量，是路径时同时返回路径
+func (p *filterParser) parseOperand() (filterOperand, *filterPath, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, nil, p.unexpected()
+	}
+	tok := p.tokens[p.pos]
+	switch {
+	case tok.path != nil:
+		p.pos++
+		return tok.path, tok.path, nil
+	case tok.literal != nil:
+		p.pos++
+		return &literalOperand{literal: tok.literal}, nil, nil
+	}
+	return nil, nil, p.unexpected()
+}
@@ -0,0 +1,149 @@
+package jsonpath
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// pathFunction 是路径末尾的聚合函数，例如$.items.length()和$.items[*].price.min()。
+// 路径只由属性和索引组成并且匹配一个数组时函数作用于数组的元素，否则作用于所有匹配的值，
+// 因此$.items[*].length()和$.items[*].tags.length()总是返回匹配的数量，与匹配的是不是数组无关
+type pathFunction struct {
+	name   string
+	apply  func(values []types.JSONValue) (types.JSONValue, error)
+	single bool // 函数之前的段最多匹配一个值，见singleValue
+}
+
+// functionPattern 匹配路径末尾的函数调用
+var functionPattern = regexp.MustCompile(`^\.([a-zA-Z_][a-zA-Z0-9_]*)\(\)`)
+
+// pathFunctions 是支持的聚合函数
+var pathFunctions = map[string]func([]types.JSONValue) (types.JSONValue, error){
+	"length": func(values []types.JSONValue) (types.JSONValue, error) {
+		return types.NewJSONNumber(float64(len(values))), nil
+	},
+	"sum": func(values []types.JSONValue) (types.JSONValue, error) {
+		numbers, err := functionNumbers("sum", values)
+		if err != nil {
+			return nil, err
+		}
+		sum := 0.0
+		for _, n := range numbers {
+			sum += n
+		}
+		return types.NewJSONNumber(sum), nil
+	},
+	"avg": func(values []types.JSONValue) (types.JSONValue, error) {
+		numbers, err := functionNumbers("avg", values)
+		if err != nil {
+			return nil, err
+		}
+		if len(numbers) == 0 {
+			return nil, emptyFunctionError("avg")
+		}
+		sum := 0.0
+		for _, n := range numbers {
+			sum += n
+		}
+		return types.NewJSONNumber(sum / float64(len(numbers))), nil
+	},
+	"min": func(values []types.JSONValue) (types.JSONValue, error) {
+		return extremum("min", values, math.Min)
+	},
+	"max": func(values []types.JSONValue) (types.JSONValue, error) {
+		return extremum("max", values, math.Max)
+	},
+}
+
+// parseFunction 解析路径末尾的函数调用.name()，path不是函数调用时返回nil
+func parseFunction(path string) (*pathFunction, error) {
+	match := functionPattern.FindStringSubmatch(path)
+	if match == nil {
+		return nil, nil
+	}
+	if len(match[0]) != len(path) {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+			fmt.Sprintf("函数%s()只能出现在路径末尾", match[1]))
+	}
+	apply, ok := pathFunctions[match[1]]
+	if !ok {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+			fmt.Sprintf("不支持的函数%s()，可以使用length()、min()、max()、avg()和sum()", match[1]))
+	}
+	return &pathFunction{name: match[1], apply: apply}, nil
+}
+
+// call 对路径匹配的值调用函数，返回函数的结果
+func (f *pathFunction) call(results []types.JSONValue) (types.JSONValue, error) {
+	if f.single && len(results) == 1 {
+		switch {
+		case results[0].IsArray():
+			arr, _ := results[0].AsArray()
+			results = arr.Values()
+		case results[0].IsObject() && f.name == "length":
+			obj, _ := results[0].AsObject()
+			return types.NewJSONNumber(float64(obj.Size())), nil
+		}
+	}
+	return f.apply(results)
+}
+
+// singleValue 检查路径是否最多匹配一个值，即每个段都是根、属性或索引。
+// 通配符、切片、联合、递归下降和过滤器可能匹配多个值，出现在路径的任何位置时匹配数都取决于文档
+func singleValue(segments []pathSegment) bool {
+	for _, segment := range segments {
+		switch segment.(type) {
+		case *rootSegment, *propertySegment, *indexSegment:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回函数调用的字符串表示
+func (f *pathFunction) String() string {
+	return "." + f.name + "()"
+}
+
+// functionNumbers 把值转换为数字，有不是数字的值时返回错误
+func functionNumbers(name string, values []types.JSONValue) ([]float64, error) {
+	numbers := make([]float64, 0, len(values))
+	for _, value := range values {
+		if !value.IsNumber() {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType,
+				fmt.Sprintf("%s()只能用于数字，遇到了%s", name, value.Type()))
+		}
+		n, err := value.AsNumber()
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// extremum 返回数字中的最小值或最大值
+func extremum(name string, values []types.JSONValue, pick func(a, b float64) float64) (types.JSONValue, error) {
+	numbers, err := functionNumbers(name, values)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, emptyFunctionError(name)
+	}
+	result := numbers[0]
+	for _, n := range numbers[1:] {
+		result = pick(result, n)
+	}
+	return types.NewJSONNumber(result), nil
+}
+
+// emptyFunctionError 返回没有值可以计算的错误
+func emptyFunctionError(name string) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("%s()没有可以计算的值", name))
+}
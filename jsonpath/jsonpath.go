@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
 	"github.com/UserLeeZJ/gojson/parser"
@@ -15,13 +16,16 @@ import (
 // JSONPath 表示一个JSON Path表达式
 type JSONPath struct {
 	segments []pathSegment
+	function *pathFunction // 路径末尾的聚合函数，没有时为nil
 	original string
 }
 
 // pathSegment 表示JSON Path的一个段
 type pathSegment interface {
-	// 应用段到JSON值，返回匹配的值
-	apply(value types.JSONValue) ([]types.JSONValue, error)
+	// 应用段到JSON值，把匹配的值追加到dst后返回，root是查询的根节点，过滤表达式中的$引用它
+	appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error)
+	// 估计应用段到values中的所有值后匹配的值的数量，用于预先分配结果
+	capacity(values []types.JSONValue) int
 	// 返回段的字符串表示
 	String() string
 }
@@ -29,8 +33,12 @@ type pathSegment interface {
 // rootSegment 表示根节点 $
 type rootSegment struct{}
 
-func (s *rootSegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
-	return []types.JSONValue{value}, nil
+func (s *rootSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
+	return append(dst, value), nil
+}
+
+func (s *rootSegment) capacity(values []types.JSONValue) int {
+	return len(values)
 }
 
 func (s *rootSegment) String() string {
@@ -42,7 +50,7 @@ type propertySegment struct {
 	name string
 }
 
-func (s *propertySegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
+func (s *propertySegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
 	if !value.IsObject() {
 		return nil, jsonerrors.ErrInvalidTypeWithDetails("object", value.Type())
 	}
@@ -50,16 +58,17 @@ func (s *propertySegment) apply(value types.JSONValue) ([]types.JSONValue, error
 	obj, _ := value.AsObject()
 	prop := obj.Get(s.name)
 	if prop.IsNull() && !obj.Has(s.name) {
-		return []types.JSONValue{}, nil
+		return dst, nil
 	}
-	return []types.JSONValue{prop}, nil
+	return append(dst, prop), nil
+}
+
+func (s *propertySegment) capacity(values []types.JSONValue) int {
+	return len(values)
 }
 
 func (s *propertySegment) String() string {
-	if isValidIdentifier(s.name) {
-		return "." + s.name
-	}
-	return "['" + s.name + "']"
+	return types.ChildPath("", types.KeySegment(s.name))
 }
 
 // indexSegment 表示数组索引访问 [0]
@@ -67,16 +76,20 @@ type indexSegment struct {
 	index int
 }
 
-func (s *indexSegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
+func (s *indexSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
 	if !value.IsArray() {
 		return nil, jsonerrors.ErrInvalidTypeWithDetails("array", value.Type())
 	}
 
 	arr, _ := value.AsArray()
 	if s.index < 0 || s.index >= arr.Size() {
-		return []types.JSONValue{}, nil
+		return dst, nil
 	}
-	return []types.JSONValue{arr.Get(s.index)}, nil
+	return append(dst, arr.Get(s.index)), nil
+}
+
+func (s *indexSegment) capacity(values []types.JSONValue) int {
+	return len(values)
 }
 
 func (s *indexSegment) String() string {
@@ -86,25 +99,34 @@ func (s *indexSegment) String() string {
 // wildcardSegment 表示通配符 .* 或 [*]
 type wildcardSegment struct{}
 
-func (s *wildcardSegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
+func (s *wildcardSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
 	if value.IsObject() {
 		obj, _ := value.AsObject()
-		result := make([]types.JSONValue, 0, obj.Size())
 		for _, key := range obj.Keys() {
-			result = append(result, obj.Get(key))
+			dst = append(dst, obj.Get(key))
 		}
-		return result, nil
+		return dst, nil
 	} else if value.IsArray() {
 		arr, _ := value.AsArray()
-		result := make([]types.JSONValue, 0, arr.Size())
-		for i := 0; i < arr.Size(); i++ {
-			result = append(result, arr.Get(i))
-		}
-		return result, nil
+		return append(dst, arr.Values()...), nil
 	}
 	return nil, jsonerrors.ErrInvalidTypeWithDetails("object or array", value.Type())
 }
 
+func (s *wildcardSegment) capacity(values []types.JSONValue) int {
+	n := 0
+	for _, value := range values {
+		if value.IsObject() {
+			obj, _ := value.AsObject()
+			n += obj.Size()
+		} else if value.IsArray() {
+			arr, _ := value.AsArray()
+			n += arr.Size()
+		}
+	}
+	return n
+}
+
 func (s *wildcardSegment) String() string {
 	return "[*]"
 }
@@ -117,13 +139,30 @@ type sliceSegment struct {
 	hasEnd   bool
 }
 
-func (s *sliceSegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
+func (s *sliceSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
 	if !value.IsArray() {
 		return nil, jsonerrors.ErrInvalidTypeWithDetails("array", value.Type())
 	}
 
 	arr, _ := value.AsArray()
-	size := arr.Size()
+	start, end := s.bounds(arr.Size())
+	return append(dst, arr.Values()[start:end]...), nil
+}
+
+func (s *sliceSegment) capacity(values []types.JSONValue) int {
+	n := 0
+	for _, value := range values {
+		if value.IsArray() {
+			arr, _ := value.AsArray()
+			start, end := s.bounds(arr.Size())
+			n += end - start
+		}
+	}
+	return n
+}
+
+// bounds 返回切片在长度为size的数组中的实际范围
+func (s *sliceSegment) bounds(size int) (int, int) {
 
 	// 计算实际的起始和结束索引
 	start, end := s.start, s.end
@@ -154,18 +193,12 @@ func (s *sliceSegment) apply(value types.JSONValue) ([]types.JSONValue, error) {
 		end = size
 	}
 
-	// 如果起始索引大于等于结束索引或超出数组范围，返回空数组
+	// 如果起始索引大于等于结束索引或超出数组范围，返回空范围
 	if start >= size || start >= end {
-		return []types.JSONValue{}, nil
+		return 0, 0
 	}
 
-	// 创建结果数组
-	result := make([]types.JSONValue, 0, end-start)
-	for i := start; i < end; i++ {
-		result = append(result, arr.Get(i))
-	}
-
-	return result, nil
+	return start, end
 }
 
 func (s *sliceSegment) String() string {
@@ -182,6 +215,40 @@ func (s *sliceSegment) String() string {
 	return fmt.Sprintf("[%s:%s]", startStr, endStr)
 }
 
+// descendantSegment 表示递归下降 ..name、..*、..[0] 或 ..['name']，
+// 把child应用到值本身及其所有后代，按文档顺序（先父后子）返回匹配的值，
+// 跳过child不适用的值，例如..name跳过数组和标量
+type descendantSegment struct {
+	child pathSegment
+}
+
+func (s *descendantSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
+	if next, err := s.child.appendTo(dst, value, root); err == nil {
+		dst = next
+	}
+
+	if value.IsObject() {
+		obj, _ := value.AsObject()
+		for _, key := range obj.Keys() {
+			dst, _ = s.appendTo(dst, obj.Get(key), root)
+		}
+	} else if value.IsArray() {
+		arr, _ := value.AsArray()
+		for _, elem := range arr.Values() {
+			dst, _ = s.appendTo(dst, elem, root)
+		}
+	}
+	return dst, nil
+}
+
+func (s *descendantSegment) capacity(values []types.JSONValue) int {
+	return len(values)
+}
+
+func (s *descendantSegment) String() string {
+	return ".." + strings.TrimPrefix(s.child.String(), ".")
+}
+
 // ParseJSONPath 解析JSON Path表达式
 func ParseJSONPath(path string) (*JSONPath, error) {
 	if path == "" {
@@ -208,6 +275,14 @@ func ParseJSONPath(path string) (*JSONPath, error) {
 		var consumed int
 		var err error
 
+		// 路径末尾的聚合函数 .length()
+		if jp.function, err = parseFunction(path); err != nil {
+			return nil, err
+		} else if jp.function != nil {
+			jp.function.single = singleValue(jp.segments)
+			break
+		}
+
 		// 解析下一个段
 		if segment, consumed, err = parseNextSegment(path); err != nil {
 			return nil, err
@@ -220,8 +295,39 @@ func ParseJSONPath(path string) (*JSONPath, error) {
 	return jp, nil
 }
 
+// ScanJSONPath 返回src中从pos处的$开始的JSON Path的结束位置，用于从更大的表达式中切分出路径，
+// 切分出的路径使用ParseJSONPath解析
+func ScanJSONPath(src string, pos int) (int, error) {
+	end, errPos, reason := pathEnd(src, pos)
+	if reason != "" {
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+			fmt.Sprintf("无效的JSON Path %q: 第%d个字符处%s", src[pos:], errPos-pos+1, reason))
+	}
+	return end, nil
+}
+
 // 解析下一个路径段
+// propertyNamePattern 匹配点号形式的属性名
+var propertyNamePattern = regexp.MustCompile(`^\.([a-zA-Z_][a-zA-Z0-9_]*)`)
+
 func parseNextSegment(path string) (pathSegment, int, error) {
+	// 递归下降 ..name、..*、..[...]
+	if strings.HasPrefix(path, "..") {
+		rest := path[2:]
+		if rest == "" || rest[0] == '.' {
+			return nil, 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "递归下降后缺少属性名或括号表达式")
+		}
+		if rest[0] != '[' {
+			// 与点号形式的属性访问和通配符相同
+			rest = path[1:]
+		}
+		child, consumed, err := parseNextSegment(rest)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &descendantSegment{child: child}, len(path) - len(rest) + consumed, nil
+	}
+
 	// 属性访问 .property
 	if strings.HasPrefix(path, ".") {
 		if len(path) == 1 {
@@ -234,7 +340,7 @@ func parseNextSegment(path string) (pathSegment, int, error) {
 		}
 
 		// 提取属性名
-		match := regexp.MustCompile(`^\.([a-zA-Z_][a-zA-Z0-9_]*)`).FindStringSubmatch(path)
+		match := propertyNamePattern.FindStringSubmatch(path)
 		if match == nil {
 			return nil, 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "无效的属性名")
 		}
@@ -245,18 +351,8 @@ func parseNextSegment(path string) (pathSegment, int, error) {
 	// 括号表达式 [...]
 	if strings.HasPrefix(path, "[") {
 		// 查找匹配的右括号
-		depth := 1
-		end := 1
-		for end < len(path) && depth > 0 {
-			if path[end] == '[' {
-				depth++
-			} else if path[end] == ']' {
-				depth--
-			}
-			end++
-		}
-
-		if depth > 0 {
+		end := bracketEnd(path)
+		if end < 0 {
 			return nil, 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "括号不匹配")
 		}
 
@@ -267,6 +363,24 @@ func parseNextSegment(path string) (pathSegment, int, error) {
 			return &wildcardSegment{}, end, nil
 		}
 
+		// 过滤表达式 [?(...)]
+		if strings.HasPrefix(bracketContent, "?") {
+			segment, err := parseFilter(bracketContent[1:])
+			if err != nil {
+				return nil, 0, err
+			}
+			return segment, end, nil
+		}
+
+		// 联合 [0,2,5] 或 ['a','b']
+		if members := splitUnion(bracketContent); len(members) > 1 {
+			segment, err := parseUnion(members)
+			if err != nil {
+				return nil, 0, err
+			}
+			return segment, end, nil
+		}
+
 		// 数字索引 [0]
 		if index, err := strconv.Atoi(bracketContent); err == nil {
 			return &indexSegment{index: index}, end, nil
@@ -301,10 +415,10 @@ func parseNextSegment(path string) (pathSegment, int, error) {
 		}
 
 		// 字符串属性 ['property'] 或 ["property"]
-		if (strings.HasPrefix(bracketContent, "'") && strings.HasSuffix(bracketContent, "'")) ||
-			(strings.HasPrefix(bracketContent, "\"") && strings.HasSuffix(bracketContent, "\"")) {
-			propName := bracketContent[1 : len(bracketContent)-1]
-			return &propertySegment{name: propName}, end, nil
+		if strings.HasPrefix(bracketContent, "'") || strings.HasPrefix(bracketContent, "\"") {
+			if propName, ok := unquoteName(bracketContent); ok {
+				return &propertySegment{name: propName}, end, nil
+			}
 		}
 
 		return nil, 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "无效的括号表达式")
@@ -313,56 +427,185 @@ func parseNextSegment(path string) (pathSegment, int, error) {
 	return nil, 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "无效的路径段")
 }
 
-// 检查字符串是否为有效的标识符
-func isValidIdentifier(s string) bool {
-	if len(s) == 0 {
-		return false
-	}
-	if !isLetter(s[0]) && s[0] != '_' {
-		return false
-	}
-	for i := 1; i < len(s); i++ {
-		if !isLetter(s[i]) && !isDigit(s[i]) && s[i] != '_' {
-			return false
+// bracketEnd 返回path开头的[与之匹配的]之后的位置，忽略引号中的括号，没有匹配的]时返回-1
+func bracketEnd(path string) int {
+	depth := 0
+	end := -1
+	scanUnquoted(path, func(i int, c byte) bool {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i + 1
+				return false
+			}
 		}
-	}
-	return true
+		return true
+	})
+	return end
 }
 
-// 检查字符是否为字母
-func isLetter(c byte) bool {
-	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+// unquoteName 解析整个s是一个带引号的属性名的情况。
+// 与types.Path的表示相同，反斜杠只转义反斜杠和当前的引号，其他反斜杠保持原样
+func unquoteName(s string) (string, bool) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return sb.String(), i == len(s)-1
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == quote):
+			i++
+			c = s[i]
+		}
+		sb.WriteByte(c)
+	}
+	return "", false
 }
 
-// 检查字符是否为数字
-func isDigit(c byte) bool {
-	return c >= '0' && c <= '9'
+// scanUnquoted 依次把s中引号之外的字节交给fn，fn返回false时停止。
+// 引号中的反斜杠转义下一个字节，因此转义的引号不会结束字符串
+func scanUnquoted(s string, fn func(i int, c byte) bool) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		default:
+			if !fn(i, c) {
+				return
+			}
+		}
+	}
 }
 
 // Query 使用JSON Path查询JSON值
 func (jp *JSONPath) Query(value types.JSONValue) ([]types.JSONValue, error) {
-	current := []types.JSONValue{value}
+	return jp.QueryAppend(nil, value)
+}
 
-	for _, segment := range jp.segments {
-		if len(current) == 0 {
-			break
-		}
+// maxPooledResults 是放回池中的中间结果切片的最大容量，更大的切片交给垃圾回收
+const maxPooledResults = 1 << 20
+
+// resultPool 缓存查询中间段的结果切片
+var resultPool = sync.Pool{
+	New: func() interface{} {
+		results := make([]types.JSONValue, 0, 16)
+		return &results
+	},
+}
+
+// releaseResults 清空切片中的引用后放回池中
+func releaseResults(results *[]types.JSONValue) {
+	if cap(*results) > maxPooledResults {
+		return
+	}
+	all := (*results)[:cap(*results)]
+	for i := range all {
+		all[i] = nil
+	}
+	*results = (*results)[:0]
+	resultPool.Put(results)
+}
 
-		var nextCurrent []types.JSONValue
+// QueryAppend 使用JSON Path查询JSON值，把匹配的值追加到dst后返回。
+// 重复查询时传入上一次的结果（例如results[:0]）可以避免分配结果切片，
+// 中间段的结果使用内部的池，不会随查询次数分配
+func (jp *JSONPath) QueryAppend(dst []types.JSONValue, value types.JSONValue) ([]types.JSONValue, error) {
+	if len(jp.segments) == 0 {
+		return append(dst, value), nil
+	}
+
+	input := resultPool.Get().(*[]types.JSONValue)
+	defer releaseResults(input)
+	*input = append((*input)[:0], value)
+	if jp.function == nil {
+		return appendSegments(dst, jp.segments, *input, value)
+	}
+
+	// 有聚合函数时先收集所有匹配的值，结果只有函数的返回值
+	matched := resultPool.Get().(*[]types.JSONValue)
+	defer releaseResults(matched)
+	var err error
+	if *matched, err = appendSegments((*matched)[:0], jp.segments, *input, value); err != nil {
+		return dst, err
+	}
+	return jp.appendFunction(dst, *matched)
+}
+
+// appendFunction 对匹配的值调用聚合函数，把结果追加到dst后返回
+func (jp *JSONPath) appendFunction(dst, matched []types.JSONValue) ([]types.JSONValue, error) {
+	result, err := jp.function.call(matched)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, result), nil
+}
+
+// appendSegments 依次把segments应用到values，把最后一段匹配的值追加到dst后返回，root是查询的根节点。
+// 出错时返回原来的dst
+func appendSegments(dst []types.JSONValue, segments []pathSegment, values []types.JSONValue, root types.JSONValue) ([]types.JSONValue, error) {
+	if len(segments) == 0 {
+		return append(dst, values...), nil
+	}
+
+	// 中间段的结果在两个池中的切片之间交替，最后一段直接追加到dst
+	buffers := [2]*[]types.JSONValue{
+		resultPool.Get().(*[]types.JSONValue),
+		resultPool.Get().(*[]types.JSONValue),
+	}
+	defer releaseResults(buffers[0])
+	defer releaseResults(buffers[1])
+
+	current := values
+	start := len(dst)
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		next := dst
+		if !last {
+			next = (*buffers[i%2])[:0]
+		}
+		next = growResults(next, segment.capacity(current))
 
 		for _, val := range current {
-			results, err := segment.apply(val)
+			var err error
+			next, err = segment.appendTo(next, val, root)
 			if err != nil {
-				return nil, err
+				return dst[:start], err
 			}
-
-			nextCurrent = append(nextCurrent, results...)
 		}
 
-		current = nextCurrent
+		if last {
+			return next, nil
+		}
+		*buffers[i%2] = next
+		if len(next) == 0 {
+			break
+		}
+		current = next
 	}
 
-	return current, nil
+	return dst, nil
+}
+
+// growResults 保证切片至少还能追加n个值而不重新分配
+func growResults(results []types.JSONValue, n int) []types.JSONValue {
+	if n <= cap(results)-len(results) {
+		return results
+	}
+	grown := make([]types.JSONValue, len(results), len(results)+n)
+	copy(grown, results)
+	return grown
 }
 
 // String 返回JSON Path的字符串表示
@@ -372,13 +615,16 @@ func (jp *JSONPath) String() string {
 	for _, segment := range jp.segments {
 		sb.WriteString(segment.String())
 	}
+	if jp.function != nil {
+		sb.WriteString(jp.function.String())
+	}
 
 	return sb.String()
 }
 
-// QueryJSONPath 使用JSON Path查询JSON值
+// QueryJSONPath 使用JSON Path查询JSON值，表达式的编译结果保存在包级别的缓存中，见CompileJSONPath
 func QueryJSONPath(value types.JSONValue, pathExpr string) ([]types.JSONValue, error) {
-	path, err := ParseJSONPath(pathExpr)
+	path, err := CompileJSONPath(pathExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -395,3 +641,62 @@ func QueryJSONPathString(jsonStr string, pathExpr string) ([]types.JSONValue, er
 
 	return QueryJSONPath(value, pathExpr)
 }
+
+// GetValue 返回JSON Path在JSON值中匹配的第一个值
+// 如果没有匹配的值，返回路径不存在错误
+func GetValue(value types.JSONValue, pathExpr string) (types.JSONValue, error) {
+	results, err := QueryJSONPath(value, pathExpr)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, jsonerrors.ErrPathNotFoundWithDetails(pathExpr)
+	}
+	return results[0], nil
+}
+
+// GetValueByPath 解析JSON字符串并返回JSON Path匹配的第一个值
+func GetValueByPath(jsonStr string, pathExpr string) (types.JSONValue, error) {
+	value, err := parser.ParseToValue(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetValue(value, pathExpr)
+}
+
+// GetStringByPath 返回JSON Path匹配的第一个字符串值
+func GetStringByPath(jsonStr string, pathExpr string) (string, error) {
+	value, err := GetValueByPath(jsonStr, pathExpr)
+	if err != nil {
+		return "", err
+	}
+	if !value.IsString() {
+		return "", jsonerrors.ErrInvalidTypeWithDetails("string", value.Type()).WithPath(pathExpr)
+	}
+	return value.AsString()
+}
+
+// GetNumberByPath 返回JSON Path匹配的第一个数字值
+func GetNumberByPath(jsonStr string, pathExpr string) (float64, error) {
+	value, err := GetValueByPath(jsonStr, pathExpr)
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsNumber() {
+		return 0, jsonerrors.ErrInvalidTypeWithDetails("number", value.Type()).WithPath(pathExpr)
+	}
+	return value.AsNumber()
+}
+
+// GetBoolByPath 返回JSON Path匹配的第一个布尔值
+func GetBoolByPath(jsonStr string, pathExpr string) (bool, error) {
+	value, err := GetValueByPath(jsonStr, pathExpr)
+	if err != nil {
+		return false, err
+	}
+	if !value.IsBoolean() {
+		return false, jsonerrors.ErrInvalidTypeWithDetails("boolean", value.Type()).WithPath(pathExpr)
+	}
+	return value.AsBoolean()
+}
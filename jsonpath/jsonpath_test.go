@@ -1,9 +1,12 @@
 package jsonpath
 
 import (
+	"strings"
 	"testing"
 
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
 	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
 )
 
 // contains 检查字符串切片是否包含指定字符串
@@ -260,3 +263,426 @@ func TestQueryJSONPathString(t *testing.T) {
 		t.Errorf("结果值不匹配: 期望 John, 实际 %s", val)
 	}
 }
+
+func TestGetValueByPath(t *testing.T) {
+	jsonStr := `{"a":{"b":[{"name":"x","n":1.5,"ok":true}]}}`
+
+	value, err := GetValueByPath(jsonStr, "$.a.b[0].name")
+	if err != nil {
+		t.Fatalf("GetValueByPath() error = %v", err)
+	}
+	if value.String() != `"x"` {
+		t.Errorf("GetValueByPath() = %v, want \"x\"", value.String())
+	}
+
+	if s, err := GetStringByPath(jsonStr, "$.a.b[0].name"); err != nil || s != "x" {
+		t.Errorf("GetStringByPath() = %v, %v, want x", s, err)
+	}
+	if n, err := GetNumberByPath(jsonStr, "$.a.b[0].n"); err != nil || n != 1.5 {
+		t.Errorf("GetNumberByPath() = %v, %v, want 1.5", n, err)
+	}
+	if b, err := GetBoolByPath(jsonStr, "$.a.b[0].ok"); err != nil || !b {
+		t.Errorf("GetBoolByPath() = %v, %v, want true", b, err)
+	}
+
+	// 类型不匹配
+	if _, err := GetNumberByPath(jsonStr, "$.a.b[0].name"); err == nil {
+		t.Errorf("GetNumberByPath() on string should return error")
+	}
+
+	// 路径不存在
+	_, err = GetValueByPath(jsonStr, "$.a.missing")
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrPathNotFound {
+		t.Errorf("GetValueByPath() missing path error = %v, want PATH_NOT_FOUND", err)
+	}
+}
+
+func TestQueryAppend(t *testing.T) {
+	value, err := parser.ParseToValue(`{"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]},{"id":3,"tags":["c"]}]}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", `{"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]},{"id":3,"tags":["c"]}]}`},
+		{"$.items[*].id", "1 2 3"},
+		{"$.items[*].tags[*]", `"a" "b" "c"`},
+		{"$.items[1:].id", "2 3"},
+		{"$.items[0].tags[-1:]", `"b"`},
+		{"$.items[*].missing", ""},
+	}
+
+	prefix := types.NewJSONString("prefix")
+	for _, tt := range tests {
+		path, err := ParseJSONPath(tt.path)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%s) error = %v", tt.path, err)
+		}
+
+		results, err := path.QueryAppend([]types.JSONValue{prefix}, value)
+		if err != nil {
+			t.Fatalf("QueryAppend(%s) error = %v", tt.path, err)
+		}
+		if results[0] != prefix {
+			t.Errorf("QueryAppend(%s) 覆盖了dst中已有的值", tt.path)
+		}
+		got := make([]string, 0, len(results)-1)
+		for _, r := range results[1:] {
+			got = append(got, r.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("QueryAppend(%s) = %v, want %s", tt.path, got, tt.want)
+		}
+
+		// 重复查询的结果与Query相同
+		again, _ := path.Query(value)
+		if len(again) != len(results)-1 {
+			t.Errorf("Query(%s) 返回 %d 个值, QueryAppend 返回 %d 个", tt.path, len(again), len(results)-1)
+		}
+	}
+
+	path, _ := ParseJSONPath("$.items.id")
+	dst := []types.JSONValue{prefix}
+	if results, err := path.QueryAppend(dst, value); err == nil || len(results) != 1 {
+		t.Errorf("类型不匹配时 QueryAppend() = %v, %v", results, err)
+	}
+}
+
+func TestJSONPathRecursiveDescent(t *testing.T) {
+	value, err := parser.ParseToValue(`{
+		"store": {
+			"book": [
+				{"author": "Nigel Rees", "price": 8.95},
+				{"author": "Evelyn Waugh", "price": 12.99, "tags": ["a", "b"]}
+			],
+			"bicycle": {"color": "red", "price": 19.95}
+		},
+		"price": 1,
+		"first name": "x"
+	}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$..author", `"Nigel Rees" "Evelyn Waugh"`},
+		{"$..price", "1 19.95 8.95 12.99"},
+		{"$.store..price", "19.95 8.95 12.99"},
+		{"$..book[1].author", `"Evelyn Waugh"`},
+		{"$..[0]", `{"author":"Nigel Rees","price":8.95} "a"`},
+		{"$..['first name']", `"x"`},
+		{"$..tags[*]", `"a" "b"`},
+		{"$.store.bicycle..*", `"red" 19.95`},
+		{"$..missing", ""},
+	}
+
+	for _, tt := range tests {
+		results, err := QueryJSONPath(value, tt.path)
+		if err != nil {
+			t.Fatalf("QueryJSONPath(%s) error = %v", tt.path, err)
+		}
+		got := make([]string, 0, len(results))
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("QueryJSONPath(%s) = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	all, err := QueryJSONPath(value, "$..*")
+	if err != nil || len(all) != 16 {
+		t.Errorf("QueryJSONPath($..*) 返回 %d 个值, %v, want 16", len(all), err)
+	}
+
+	for path, want := range map[string]string{
+		"$..author":      "$..author",
+		"$..*":           "$..[*]",
+		"$..[0]":         "$..[0]",
+		"$..['a b'].c":   "$..['a b'].c",
+		"$.store..price": "$.store..price",
+	} {
+		jp, err := ParseJSONPath(path)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%s) error = %v", path, err)
+		}
+		if jp.String() != want {
+			t.Errorf("ParseJSONPath(%s).String() = %s, want %s", path, jp.String(), want)
+		}
+	}
+
+	for _, path := range []string{"$..", "$...a", "$..1"} {
+		if _, err := ParseJSONPath(path); err == nil {
+			t.Errorf("ParseJSONPath(%s) 应该返回错误", path)
+		}
+	}
+}
+
+func TestJSONPathFilter(t *testing.T) {
+	value, err := parser.ParseToValue(`{
+		"store": {
+			"book": [
+				{"category": "reference", "author": "Nigel Rees", "price": 8.95},
+				{"category": "fiction", "author": "Evelyn Waugh", "price": 12.99},
+				{"category": "fiction", "author": "Herman Melville", "isbn": "0-553-21311-3", "price": 8.99},
+				{"category": "fiction", "author": "J. R. R. Tolkien", "isbn": null, "price": 22.99, "tags": ["a]", "b"]}
+			],
+			"bicycle": {"color": "red", "price": 19.95}
+		},
+		"expensive": 10,
+		"numbers": [1, 5, 10, "10"]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.store.book[?(@.price > 10)].author", `"Evelyn Waugh" "J. R. R. Tolkien"`},
+		{"$.store.book[?(@.price <= 8.99)].price", "8.95 8.99"},
+		{"$.store.book[?(@.isbn)].author", `"Herman Melville" "J. R. R. Tolkien"`},
+		{"$.store.book[?(!@.isbn)].author", `"Nigel Rees" "Evelyn Waugh"`},
+		{"$.store.book[?(@.category == 'fiction' && @.price < 10)].author", `"Herman Melville"`},
+		{`$.store.book[?(@.category == "reference" || @.price > 20)].author`, `"Nigel Rees" "J. R. R. Tolkien"`},
+		{"$.store.book[?(@.price > $.expensive)].price", "12.99 22.99"},
+		{"$.store.book[?(@.isbn == null)].author", `"J. R. R. Tolkien"`},
+		{"$.store.book[?(@.missing == null)].author", ""},
+		{"$.store.book[?(@.category != 'fiction')].author", `"Nigel Rees"`},
+		{"$.store.book[?(@.tags[0] == 'a]')].author", `"J. R. R. Tolkien"`},
+		{"$.store.book[?((@.price < 9 || @.price > 20) && !(@.category == 'reference'))].price", "8.99 22.99"},
+		{"$.store.book[?@.price > 20].price", "22.99"},
+		{"$.numbers[?(@ >= 5)]", "5 10"},
+		{"$.numbers[?(@ == '10')]", `"10"`},
+		{"$.store[?(@.color)].price", "19.95"},
+		{"$..[?(@.price > 19)].price", "19.95 22.99"},
+		{"$.store.book[?(@.price > 'x')]", ""},
+	}
+
+	for _, tt := range tests {
+		results, err := QueryJSONPath(value, tt.path)
+		if err != nil {
+			t.Fatalf("QueryJSONPath(%s) error = %v", tt.path, err)
+		}
+		got := make([]string, 0, len(results))
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("QueryJSONPath(%s) = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	jp, err := ParseJSONPath("$.store.book[?(@.price > 10)].author")
+	if err != nil || jp.String() != "$.store.book[?(@.price > 10)].author" {
+		t.Errorf("ParseJSONPath().String() = %v, %v", jp, err)
+	}
+
+	for _, path := range []string{
+		"$.a[?()]",
+		"$.a[?(@.price >)]",
+		"$.a[?(@.price > 10]",
+		"$.a[?(10)]",
+		"$.a[?(@.name == 'x)]",
+		"$.a[?(@.price # 1)]",
+		"$.a[?(@. > 1)]",
+		"$.a[?(@.a > 1 &&)]",
+	} {
+		_, err := ParseJSONPath(path)
+		if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrInvalidPath {
+			t.Errorf("ParseJSONPath(%s) error = %v, want INVALID_PATH", path, err)
+		}
+	}
+}
+
+func TestJSONPathFilterExactNumbers(t *testing.T) {
+	big1, _ := types.ParseJSONBigInt("9007199254740992")
+	big2, _ := types.ParseJSONBigInt("9007199254740993")
+	dec, _ := types.ParseJSONDecimal("0.10000000000000000001")
+	items := types.NewJSONArray()
+	for i, v := range []types.JSONValue{big1, big2, types.NewJSONNumber(9007199254740992), dec, types.NewJSONNumber(0.1)} {
+		item := types.NewJSONObject()
+		item.PutNumber("id", float64(i))
+		item.Put("v", v)
+		items.Add(item)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$[?(@.v == 9007199254740993)].id", "1"},
+		{"$[?(@.v == 9007199254740992)].id", "0 2"},
+		{"$[?(@.v > 9007199254740992)].id", "1"},
+		{"$[?(@.v == 0.10000000000000000001)].id", "3"},
+		{"$[?(@.v == 0.1)].id", "4"},
+	}
+	for _, tt := range tests {
+		results, err := QueryJSONPath(items, tt.path)
+		if err != nil {
+			t.Fatalf("QueryJSONPath(%s) error = %v", tt.path, err)
+		}
+		got := make([]string, 0, len(results))
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("QueryJSONPath(%s) = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestJSONPathUnion(t *testing.T) {
+	value, err := parser.ParseToValue(`{
+		"items": [10, 11, 12, 13, 14, 15],
+		"user": {"a": 1, "b": 2, "first name": "x", "it's": 3},
+		"books": [{"title": "A", "price": 1}, {"title": "B", "price": 2}]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+		str  string
+	}{
+		{"$.items[0,2,5]", "10 12 15", "$.items[0,2,5]"},
+		{"$.items[5, 0, 0]", "15 10 10", "$.items[5,0,0]"},
+		{"$.items[1,9,-1]", "11", "$.items[1,9,-1]"},
+		{"$.items[0:2,4:]", "10 11 14 15", "$.items[0:2,4:]"},
+		{"$.user['b','a']", "2 1", "$.user['b','a']"},
+		{`$.user["first name", 'missing', "it's"]`, `"x" 3`, `$.user['first name','missing',"it's"]`},
+		{"$.books[*]['title','price']", `"A" 1 "B" 2`, "$.books[*]['title','price']"},
+		{"$.user[0,'a']", "1", "$.user[0,'a']"},
+		{"$..['a','title']", `"A" "B" 1`, "$..['a','title']"},
+		// 引号中转义的引号后面紧跟逗号
+		{`$.user['it\'s','a']`, "3 1", `$.user["it's",'a']`},
+		{`$.user["first name\",",'b']`, "2", `$.user['first name",','b']`},
+	}
+
+	for _, tt := range tests {
+		jp, err := ParseJSONPath(tt.path)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%s) error = %v", tt.path, err)
+		}
+		if jp.String() != tt.str {
+			t.Errorf("ParseJSONPath(%s).String() = %s, want %s", tt.path, jp.String(), tt.str)
+		}
+		results, err := jp.Query(value)
+		if err != nil {
+			t.Fatalf("Query(%s) error = %v", tt.path, err)
+		}
+		got := make([]string, 0, len(results))
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("Query(%s) = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	// 所有成员都不适用时返回错误
+	if _, err := QueryJSONPath(value, "$.items['a','b']"); err == nil {
+		t.Errorf("QueryJSONPath($.items['a','b']) 应该返回错误")
+	}
+
+	for _, path := range []string{"$.items[0,]", "$.items[,1]", "$.items[0,x]", "$.items[0,?(@ > 1)]"} {
+		if _, err := ParseJSONPath(path); err == nil {
+			t.Errorf("ParseJSONPath(%s) 应该返回错误", path)
+		}
+	}
+}
+
+func TestJSONPathFunctions(t *testing.T) {
+	value, err := parser.ParseToValue(`{
+		"store": {
+			"book": [
+				{"title": "A", "price": 8.5, "tags": ["x"]},
+				{"title": "B", "price": 12.25, "tags": ["x", "y", "z"]},
+				{"title": "C", "price": 22.75}
+			],
+			"bicycle": {"color": "red", "price": 19.95}
+		},
+		"empty": [],
+		"matrix": [[1, 2, 3]]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.store.book.length()", "3"},
+		{"$.store.book[*].length()", "3"},
+		{"$.store.length()", "2"},
+		{"$.store.book[0].title.length()", "1"},
+		{"$.store.book[*].price.min()", "8.5"},
+		{"$.store.book[*].price.max()", "22.75"},
+		{"$.store.book[0:2].price.sum()", "20.75"},
+		{"$.store.book[*].price.avg()", "14.5"},
+		{"$..price.max()", "22.75"},
+		{"$.empty.length()", "0"},
+		{"$.empty.sum()", "0"},
+		{"$.store.book[?(@.tags.length() > 2)].title.length()", "1"},
+		// 通配符、过滤器等只匹配到一个值时仍然作用于匹配的值
+		{"$.matrix[*].length()", "1"},
+		{"$.matrix[0].length()", "3"},
+		{"$.store.book[?(@.title == 'C')].length()", "1"},
+		{"$.store.book[2].length()", "2"},
+		// 通配符或过滤器出现在前面的段中时同样如此，结果不取决于匹配了几个数组
+		{"$.store.book[?(@.title == 'B')].tags.length()", "1"},
+		{"$.store.book[*].tags.length()", "2"},
+		{"$.matrix[*][0].length()", "1"},
+	}
+
+	for _, tt := range tests {
+		jp, err := ParseJSONPath(tt.path)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%s) error = %v", tt.path, err)
+		}
+		if jp.String() != tt.path {
+			t.Errorf("ParseJSONPath(%s).String() = %s", tt.path, jp.String())
+		}
+		results, err := jp.Query(value)
+		if err != nil {
+			t.Fatalf("Query(%s) error = %v", tt.path, err)
+		}
+		if len(results) != 1 || !results[0].IsNumber() {
+			t.Fatalf("Query(%s) = %v, want one number", tt.path, results)
+		}
+		if results[0].String() != tt.want {
+			t.Errorf("Query(%s) = %s, want %s", tt.path, results[0].String(), tt.want)
+		}
+	}
+
+	// 不是数字或没有值可以计算时返回错误
+	for _, path := range []string{"$.store.book[*].title.sum()", "$.empty.min()", "$.empty.avg()", "$.matrix[*].sum()"} {
+		if _, err := QueryJSONPath(value, path); err == nil {
+			t.Errorf("QueryJSONPath(%s) 应该返回错误", path)
+		}
+	}
+
+	for _, path := range []string{"$.store.book.count()", "$.store.book.length().price", "$.store.book.length()[0]"} {
+		if _, err := ParseJSONPath(path); err == nil {
+			t.Errorf("ParseJSONPath(%s) 应该返回错误", path)
+		}
+	}
+
+	plan, err := PlanQuery("$.store.book.length()", -1, PlanOptions{})
+	if err != nil {
+		t.Fatalf("PlanQuery() error = %v", err)
+	}
+	if plan.Strategy != StrategyDOM {
+		t.Errorf("PlanQuery() strategy = %v, want StrategyDOM", plan.Strategy)
+	}
+}
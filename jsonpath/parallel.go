@@ -0,0 +1,114 @@
+package jsonpath
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// DefaultMinFanOut 是并行查询需要的最少展开值数量
+const DefaultMinFanOut = 1024
+
+// QueryOptions 定义查询选项
+type QueryOptions struct {
+	// Parallel 为true时，通配符、切片、联合或递归下降展开出足够多的值后，在多个goroutine中分别查询之后的段，
+	// 结果的顺序与串行查询相同。查询期间不能修改被查询的值
+	Parallel bool
+	// Workers 是并行查询的goroutine数量，为0时使用runtime.GOMAXPROCS(0)
+	Workers int
+	// MinFanOut 是开始并行查询需要的最少展开值数量，为0时使用DefaultMinFanOut
+	MinFanOut int
+}
+
+// QueryWithOptions 按选项使用JSON Path查询JSON值
+func (jp *JSONPath) QueryWithOptions(value types.JSONValue, opts QueryOptions) ([]types.JSONValue, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if !opts.Parallel || workers <= 1 || jp.function != nil {
+		return jp.Query(value)
+	}
+	minFanOut := opts.MinFanOut
+	if minFanOut <= 0 {
+		minFanOut = DefaultMinFanOut
+	}
+
+	// 逐段串行查询，直到通配符、切片、联合或递归下降展开出足够多的值
+	current := []types.JSONValue{value}
+	for i, segment := range jp.segments {
+		next, err := appendSegments(nil, jp.segments[i:i+1], current, value)
+		if err != nil {
+			return nil, err
+		}
+		current = next
+
+		rest := jp.segments[i+1:]
+		if len(rest) > 0 && len(current) >= minFanOut && isFanOut(segment) {
+			return appendParallel(rest, current, value, workers)
+		}
+		if len(current) == 0 {
+			break
+		}
+	}
+	return current, nil
+}
+
+// isFanOut 检查段是否可能把一个值展开为多个值
+func isFanOut(segment pathSegment) bool {
+	switch segment.(type) {
+	case *wildcardSegment, *sliceSegment, *descendantSegment, *unionSegment:
+		return true
+	}
+	return false
+}
+
+// appendParallel 把values分成多块，在workers个goroutine中把segments应用到每一块，按块的顺序合并结果，root是查询的根节点。
+// 出错时返回最靠前的块的错误，与串行查询遇到的第一个错误相同
+func appendParallel(segments []pathSegment, values []types.JSONValue, root types.JSONValue, workers int) ([]types.JSONValue, error) {
+	// 块比goroutine多，使各goroutine的工作量大致均衡
+	chunks := workers * 4
+	if chunks > len(values) {
+		chunks = len(values)
+	}
+	size := (len(values) + chunks - 1) / chunks
+	chunks = (len(values) + size - 1) / size
+
+	results := make([][]types.JSONValue, chunks)
+	errs := make([]error, chunks)
+	jobs := make(chan int, chunks)
+	for c := 0; c < chunks; c++ {
+		jobs <- c
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < chunks; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				end := (c + 1) * size
+				if end > len(values) {
+					end = len(values)
+				}
+				results[c], errs[c] = appendSegments(nil, segments, values[c*size:end], root)
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for c := range results {
+		if errs[c] != nil {
+			return nil, errs[c]
+		}
+		total += len(results[c])
+	}
+	merged := make([]types.JSONValue, 0, total)
+	for _, r := range results {
+		merged = append(merged, r...)
+	}
+	return merged, nil
+}
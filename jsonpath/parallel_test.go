@@ -0,0 +1,87 @@
+package jsonpath
+
+import (
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestQueryWithOptionsParallel(t *testing.T) {
+	items := types.NewJSONArray()
+	for i := 0; i < 1000; i++ {
+		item := types.NewJSONObject().PutNumber("id", float64(i))
+		item.Put("tags", types.NewJSONArray().AddNumber(float64(i)).AddNumber(float64(-i)))
+		items.Add(item)
+	}
+	value := types.NewJSONObject().Put("items", items)
+
+	opts := QueryOptions{Parallel: true, Workers: 4, MinFanOut: 10}
+	for _, expr := range []string{"$.items[*].id", "$.items[*].tags[*]", "$.items[10:500].tags[1]", "$.items", "$"} {
+		path, err := ParseJSONPath(expr)
+		if err != nil {
+			t.Fatalf("ParseJSONPath(%s) error = %v", expr, err)
+		}
+		want, err := path.Query(value)
+		if err != nil {
+			t.Fatalf("Query(%s) error = %v", expr, err)
+		}
+		got, err := path.QueryWithOptions(value, opts)
+		if err != nil {
+			t.Fatalf("QueryWithOptions(%s) error = %v", expr, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("QueryWithOptions(%s) 返回 %d 个值, 期望 %d 个", expr, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("QueryWithOptions(%s)[%d] = %v, 期望 %v", expr, i, got[i], want[i])
+			}
+		}
+	}
+
+	// 错误与串行查询相同：第一个不是数组的元素
+	items.Set(700, types.NewJSONString("x"))
+	items.Set(900, types.NewJSONNumber(1))
+	path, _ := ParseJSONPath("$.items[*].tags")
+	_, want := path.Query(value)
+	_, err := path.QueryWithOptions(value, opts)
+	jsonErr, ok := err.(*jsonerrors.JSONError)
+	if !ok || want == nil || err.Error() != want.Error() || jsonErr.Code != jsonerrors.ErrInvalidType {
+		t.Errorf("QueryWithOptions() error = %v, 期望 %v", err, want)
+	}
+}
+
+func TestQueryWithOptionsParallelSharedObjects(t *testing.T) {
+	// 每个对象在数组中出现多次，被不同的goroutine同时读取；移除的键在对象中留下空位
+	objects := make([]*types.JSONObject, 16)
+	for i := range objects {
+		obj := types.NewJSONObject()
+		for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+			obj.PutString(key, key)
+		}
+		objects[i] = obj.Remove("b").Remove("e")
+	}
+	items := types.NewJSONArray()
+	for i := 0; i < 4096; i++ {
+		items.Add(objects[i%len(objects)])
+	}
+	value := types.NewJSONObject().Put("items", items)
+
+	path, err := ParseJSONPath("$.items[*].*")
+	if err != nil {
+		t.Fatalf("ParseJSONPath() error = %v", err)
+	}
+	got, err := path.QueryWithOptions(value, QueryOptions{Parallel: true, Workers: 4, MinFanOut: 2})
+	if err != nil {
+		t.Fatalf("QueryWithOptions() error = %v", err)
+	}
+	if len(got) != items.Size()*4 {
+		t.Fatalf("QueryWithOptions() 返回 %d 个值, 期望 %d 个", len(got), items.Size()*4)
+	}
+	for i, want := range []string{"a", "c", "d", "f"} {
+		if s, _ := got[i].AsString(); s != want {
+			t.Errorf("QueryWithOptions()[%d] = %v, 期望 %s", i, got[i], want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package jsonpath
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Strategy 表示查询的执行方式
+type Strategy int
+
+const (
+	// StrategyAuto 根据表达式和输入大小自动选择执行方式
+	StrategyAuto Strategy = iota
+	// StrategyDOM 先把整个输入解析为JSONValue再查询，支持全部JSON Path语法
+	StrategyDOM
+	// StrategyStreaming 边读取输入边匹配，只构建匹配的值，
+	// 只支持$、.name、['name']、非负整数索引、.*和[*]
+	StrategyStreaming
+)
+
+// String 返回执行方式的名称
+func (s Strategy) String() string {
+	switch s {
+	case StrategyAuto:
+		return "auto"
+	case StrategyDOM:
+		return "dom"
+	case StrategyStreaming:
+		return "streaming"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// DefaultStreamingThreshold 是自动选择流式执行的最小输入字节数
+const DefaultStreamingThreshold = 1 << 20
+
+// PlanOptions 定义选择执行方式的选项
+type PlanOptions struct {
+	// Strategy 指定执行方式，为StrategyAuto时自动选择
+	Strategy Strategy
+	// StreamingThreshold 是自动选择流式执行的最小输入字节数，为0时使用DefaultStreamingThreshold
+	StreamingThreshold int64
+	// Size 是输入的字节数，为0时从输入推断（例如*os.File和bytes.Reader），推断不出时视为很大
+	Size int64
+}
+
+// Plan 是查询的执行计划
+type Plan struct {
+	// Strategy 是选择的执行方式，不会是StrategyAuto
+	Strategy Strategy
+	// Reason 说明选择这种执行方式的原因
+	Reason string
+}
+
+// PlanQuery 根据表达式和输入的字节数选择执行方式，size为负数表示大小未知。
+// 表达式在流式处理支持的子集中并且输入足够大（或大小未知）时流式执行，否则解析整个输入后执行
+func PlanQuery(expr string, size int64, opts PlanOptions) (Plan, error) {
+	jp, err := CompileJSONPath(expr)
+	if err != nil {
+		return Plan{}, err
+	}
+	streamErr := validateStreamPath(expr)
+	if streamErr == nil && jp.function != nil {
+		// 流式处理会把.length()当作属性名，聚合函数只能在DOM上计算
+		streamErr = jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "流式处理不支持聚合函数"+jp.function.String())
+	}
+
+	switch opts.Strategy {
+	case StrategyDOM:
+		return Plan{Strategy: StrategyDOM, Reason: "指定了DOM执行"}, nil
+	case StrategyStreaming:
+		if streamErr != nil {
+			return Plan{}, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "表达式不能流式执行").WithCause(streamErr)
+		}
+		return Plan{Strategy: StrategyStreaming, Reason: "指定了流式执行"}, nil
+	}
+
+	threshold := opts.StreamingThreshold
+	if threshold <= 0 {
+		threshold = DefaultStreamingThreshold
+	}
+	switch {
+	case streamErr != nil:
+		return Plan{Strategy: StrategyDOM, Reason: "表达式使用了流式处理不支持的语法"}, nil
+	case size < 0:
+		return Plan{Strategy: StrategyStreaming, Reason: "输入大小未知"}, nil
+	case size >= threshold:
+		return Plan{Strategy: StrategyStreaming, Reason: fmt.Sprintf("输入有%d字节，不小于%d字节", size, threshold)}, nil
+	default:
+		return Plan{Strategy: StrategyDOM, Reason: fmt.Sprintf("输入只有%d字节", size)}, nil
+	}
+}
+
+// QueryReader 从r读取JSON并查询，按PlanQuery选择的方式执行，返回匹配的值和使用的执行计划。
+// 类型不匹配（例如对数组访问属性）时两种执行方式都返回ErrInvalidType错误。
+// 对象没有重复的键时两种执行方式的结果相同；有重复的键时DOM执行与解析器一样只保留最后一个值，
+// 流式执行不缓存对象，会返回每个重复的键中匹配的值，例如{"a":1,"a":2}中的$.a得到[1 2]。
+// 自动选择时结果因此可能随输入大小变化，需要确定的结果时用Strategy指定执行方式
+func QueryReader(r io.Reader, expr string, opts PlanOptions) ([]types.JSONValue, Plan, error) {
+	size := opts.Size
+	if size <= 0 {
+		size = inputSize(r)
+	}
+	plan, err := PlanQuery(expr, size, opts)
+	if err != nil {
+		return nil, plan, err
+	}
+
+	if plan.Strategy == StrategyStreaming {
+		results, err := queryStreaming(r, expr)
+		return results, plan, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, plan, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取输入失败").WithCause(err)
+	}
+	value, err := parser.ParseBytesToValue(data)
+	if err != nil {
+		return nil, plan, err
+	}
+	results, err := QueryJSONPath(value, expr)
+	return results, plan, err
+}
+
+// inputSize 推断还能从r读取的字节数，推断不出时返回-1
+func inputSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
@@ -0,0 +1,67 @@
+//go:build !gojson_nostream
+
+package jsonpath
+
+import (
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// TestStrategiesAgree 用两种执行方式运行同一个查询，结果和错误必须相同
+func TestStrategiesAgree(t *testing.T) {
+	tests := []struct {
+		input string
+		expr  string
+	}{
+		{`{"items":[{"id":1},{"id":2},{"name":"x"}]}`, "$.items[*].id"},
+		{`{"items":[{"id":1},{"id":null}]}`, "$.items[*].id"},
+		{`{"items":[{"id":1},{"id":2}]}`, "$.items[5]"},
+		{`{"items":[{"id":1},{"id":2}]}`, "$.items[1]"},
+		{`{"items":[{"id":1},{"id":2}]}`, "$.missing"},
+		{`{"a":{"b":{"c":[true,false]}}}`, "$.a.b.c[*]"},
+		{`{"a":{"b":1,"c":"x"}}`, "$.a.*"},
+		{`{"a b":{"c":1}}`, "$['a b'].c"},
+		{`[1,2,3]`, "$[*]"},
+		{`[1,2,3]`, "$"},
+		{`"text"`, "$"},
+		{`{"n":12345678901234567890,"f":1.5e300}`, "$.*"},
+		{`{"b":{"y":1,"x":2},"a":{"z":3}}`, "$.*.*"},
+		{`{"b":[{"y":1,"x":2}],"a":[{"z":3},{"c":4}]}`, "$.*[*].*"},
+		// 类型不匹配
+		{`{"items":[1,2]}`, "$.items.id"},
+		{`{"items":{"id":1}}`, "$.items[0]"},
+		{`{"items":"text"}`, "$.items[*]"},
+		{`{"items":[{"id":1},2]}`, "$.items[*].id"},
+		{`{"items":[{"id":1},null]}`, "$.items[*].id"},
+		{`{"items":[[1],{"a":1}]}`, "$.items[*][0]"},
+		{`[1,2]`, "$.a"},
+		{`3`, "$[0]"},
+		{`null`, "$.*"},
+	}
+	for _, tt := range tests {
+		dom, _, domErr := QueryReader(strings.NewReader(tt.input), tt.expr, PlanOptions{Strategy: StrategyDOM})
+		streamed, _, streamErr := QueryReader(strings.NewReader(tt.input), tt.expr, PlanOptions{Strategy: StrategyStreaming})
+
+		if (domErr == nil) != (streamErr == nil) || domErr != nil && domErr.Error() != streamErr.Error() {
+			t.Errorf("%s on %s: DOM error = %v, streaming error = %v", tt.expr, tt.input, domErr, streamErr)
+			continue
+		}
+		if domErr != nil {
+			if jsonErr, ok := domErr.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrInvalidType {
+				t.Errorf("%s on %s: error = %v, want INVALID_TYPE", tt.expr, tt.input, domErr)
+			}
+			continue
+		}
+		if len(dom) != len(streamed) {
+			t.Errorf("%s on %s: DOM = %v, streaming = %v", tt.expr, tt.input, dom, streamed)
+			continue
+		}
+		for i := range dom {
+			if dom[i].String() != streamed[i].String() {
+				t.Errorf("%s on %s: result %d DOM = %s, streaming = %s", tt.expr, tt.input, i, dom[i], streamed[i])
+			}
+		}
+	}
+}
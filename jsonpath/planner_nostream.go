@@ -0,0 +1,20 @@
+//go:build gojson_nostream
+
+package jsonpath
+
+import (
+	"io"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// validateStreamPath 在构建时指定了gojson_nostream时总是返回错误，查询都按DOM执行
+func validateStreamPath(expr string) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "构建时排除了流式处理（gojson_nostream）")
+}
+
+// queryStreaming 不会被调用，PlanQuery不会选择流式执行
+func queryStreaming(r io.Reader, expr string) ([]types.JSONValue, error) {
+	return nil, validateStreamPath(expr)
+}
@@ -0,0 +1,23 @@
+//go:build gojson_nostream
+
+package jsonpath
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanQueryWithoutStreaming(t *testing.T) {
+	plan, err := PlanQuery("$.items[*].id", -1, PlanOptions{})
+	if err != nil || plan.Strategy != StrategyDOM {
+		t.Errorf("PlanQuery() = %+v, %v, want dom", plan, err)
+	}
+	if _, err := PlanQuery("$.items", -1, PlanOptions{Strategy: StrategyStreaming}); err == nil {
+		t.Error("排除了流式处理时指定流式执行应该返回错误")
+	}
+
+	results, plan, err := QueryReader(strings.NewReader(`{"items":[{"id":1},{"id":2}]}`), "$.items[*].id", PlanOptions{})
+	if err != nil || plan.Strategy != StrategyDOM || len(results) != 2 {
+		t.Errorf("QueryReader() = %v, %+v, %v", results, plan, err)
+	}
+}
@@ -0,0 +1,67 @@
+//go:build !gojson_nostream
+
+package jsonpath
+
+import (
+	"io"
+	"sort"
+
+	"github.com/UserLeeZJ/gojson/stream"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// validateStreamPath 检查表达式是否在流式处理支持的子集中
+func validateStreamPath(expr string) error {
+	return stream.ValidateStreamPath(expr)
+}
+
+// queryStreaming 边读取边收集与路径匹配的值，类型不匹配时与DOM执行一样返回ErrInvalidType错误。
+// 解析得到的对象按键排序，因此通配符匹配的对象成员也按键排序，使结果的顺序与DOM执行相同
+func queryStreaming(r io.Reader, expr string) ([]types.JSONValue, error) {
+	reader, err := stream.NewStrictElementReader(r, expr)
+	if err != nil {
+		return nil, err
+	}
+	var results []types.JSONValue
+	var positions [][]types.PathSegment
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, value)
+		positions = append(positions, reader.Segments())
+	}
+
+	sort.Stable(byPosition{results, positions})
+	return results, nil
+}
+
+// byPosition 按DOM执行的顺序排列流式匹配的值：数组元素按索引，对象成员按键
+type byPosition struct {
+	values    []types.JSONValue
+	positions [][]types.PathSegment
+}
+
+func (b byPosition) Len() int { return len(b.values) }
+
+func (b byPosition) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.positions[i], b.positions[j] = b.positions[j], b.positions[i]
+}
+
+func (b byPosition) Less(i, j int) bool {
+	x, y := b.positions[i], b.positions[j]
+	for k := 0; k < len(x) && k < len(y); k++ {
+		switch {
+		case x[k].IsIndex && y[k].IsIndex && x[k].Index != y[k].Index:
+			return x[k].Index < y[k].Index
+		case !x[k].IsIndex && !y[k].IsIndex && x[k].Key != y[k].Key:
+			return x[k].Key < y[k].Key
+		}
+	}
+	return false
+}
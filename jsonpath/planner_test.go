@@ -0,0 +1,101 @@
+//go:build !gojson_nostream
+
+package jsonpath
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPlanQuery(t *testing.T) {
+	tests := []struct {
+		expr string
+		size int64
+		opts PlanOptions
+		want Strategy
+	}{
+		{"$.items[*].id", 10, PlanOptions{}, StrategyDOM},
+		{"$.items[*].id", 2 << 20, PlanOptions{}, StrategyStreaming},
+		{"$.items[*].id", -1, PlanOptions{}, StrategyStreaming},
+		{"$.items[*].id", 100, PlanOptions{StreamingThreshold: 50}, StrategyStreaming},
+		{"$.items[1:3]", 2 << 20, PlanOptions{}, StrategyDOM},
+		{"$.items[-1]", -1, PlanOptions{}, StrategyDOM},
+		{"$.items[*].id", 2 << 20, PlanOptions{Strategy: StrategyDOM}, StrategyDOM},
+		{"$.items[0]", 10, PlanOptions{Strategy: StrategyStreaming}, StrategyStreaming},
+	}
+	for _, tt := range tests {
+		plan, err := PlanQuery(tt.expr, tt.size, tt.opts)
+		if err != nil {
+			t.Fatalf("PlanQuery(%s) error = %v", tt.expr, err)
+		}
+		if plan.Strategy != tt.want || plan.Reason == "" {
+			t.Errorf("PlanQuery(%s, %d) = %v (%s), want %v", tt.expr, tt.size, plan.Strategy, plan.Reason, tt.want)
+		}
+	}
+
+	if _, err := PlanQuery("$.items[1:3]", 10, PlanOptions{Strategy: StrategyStreaming}); err == nil {
+		t.Error("不能流式执行的表达式指定流式执行时应该返回错误")
+	}
+	if _, err := PlanQuery("items", 10, PlanOptions{}); err == nil {
+		t.Error("无效的表达式应该返回错误")
+	}
+}
+
+func TestQueryReader(t *testing.T) {
+	input := `{"items":[{"id":1},{"id":2},{"id":3}]}`
+
+	for _, opts := range []PlanOptions{{}, {StreamingThreshold: 1}, {Strategy: StrategyStreaming}} {
+		results, plan, err := QueryReader(strings.NewReader(input), "$.items[*].id", opts)
+		if err != nil {
+			t.Fatalf("QueryReader(%+v) error = %v", opts, err)
+		}
+		if len(results) != 3 || results[2].String() != "3" {
+			t.Errorf("QueryReader(%+v) = %v (%v)", opts, results, plan.Strategy)
+		}
+	}
+
+	// 大小未知的输入流式执行
+	results, plan, err := QueryReader(io.MultiReader(strings.NewReader(input)), "$.items[0]", PlanOptions{})
+	if err != nil || plan.Strategy != StrategyStreaming || len(results) != 1 {
+		t.Errorf("QueryReader() = %v, %v, %v", results, plan, err)
+	}
+
+	results, plan, err = QueryReader(bytes.NewReader([]byte(input)), "$.items[1:]", PlanOptions{})
+	if err != nil || plan.Strategy != StrategyDOM || len(results) != 2 {
+		t.Errorf("QueryReader() = %v, %v, %v", results, plan, err)
+	}
+}
+
+// 重复的键：DOM执行保留最后一个值，流式执行返回每个重复的键中匹配的值
+func TestQueryReaderDuplicateKeys(t *testing.T) {
+	input := `{"a":1,"a":2}`
+	tests := []struct {
+		expr      string
+		dom       string
+		streaming string
+	}{
+		{"$.a", "2", "1 2"},
+		{"$['a']", "2", "1 2"},
+		{"$.*", "2", "1 2"},
+	}
+	for _, tt := range tests {
+		for _, c := range []struct {
+			strategy Strategy
+			want     string
+		}{{StrategyDOM, tt.dom}, {StrategyStreaming, tt.streaming}} {
+			results, _, err := QueryReader(strings.NewReader(input), tt.expr, PlanOptions{Strategy: c.strategy})
+			if err != nil {
+				t.Fatalf("QueryReader(%s, %v) error = %v", tt.expr, c.strategy, err)
+			}
+			got := make([]string, len(results))
+			for i, r := range results {
+				got[i] = r.String()
+			}
+			if strings.Join(got, " ") != c.want {
+				t.Errorf("QueryReader(%s, %v) = %v, want %s", tt.expr, c.strategy, got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// unionSegment 表示联合 [0,2,5] 或 ['a','b']，按成员的顺序依次应用并连接结果。
+// 成员可以是索引、带引号的属性名、切片和*，不适用于当前值的成员（例如对对象使用索引）被跳过，
+// 所有成员都不适用时返回第一个成员的错误
+type unionSegment struct {
+	members []pathSegment
+}
+
+func (s *unionSegment) appendTo(dst []types.JSONValue, value, root types.JSONValue) ([]types.JSONValue, error) {
+	var firstErr error
+	applied := false
+	for _, member := range s.members {
+		next, err := member.appendTo(dst, value, root)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		dst = next
+		applied = true
+	}
+	if !applied {
+		return nil, firstErr
+	}
+	return dst, nil
+}
+
+func (s *unionSegment) capacity(values []types.JSONValue) int {
+	n := 0
+	for _, member := range s.members {
+		n += member.capacity(values)
+	}
+	return n
+}
+
+func (s *unionSegment) String() string {
+	parts := make([]string, len(s.members))
+	for i, member := range s.members {
+		switch m := member.(type) {
+		case *propertySegment:
+			// 与单独的属性段使用相同的引号和转义
+			str := m.String()
+			if strings.HasPrefix(str, ".") {
+				parts[i] = "'" + m.name + "'"
+			} else {
+				parts[i] = str[1 : len(str)-1]
+			}
+		default:
+			// 索引、切片和通配符的表示形式是[...]
+			str := member.String()
+			parts[i] = str[1 : len(str)-1]
+		}
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// splitUnion 按引号之外的逗号拆分括号中的内容，没有这样的逗号时返回nil。
+// 引号的处理与bracketEnd相同
+func splitUnion(content string) []string {
+	var members []string
+	start := 0
+	scanUnquoted(content, func(i int, c byte) bool {
+		if c == ',' {
+			members = append(members, content[start:i])
+			start = i + 1
+		}
+		return true
+	})
+	if members == nil {
+		return nil
+	}
+	return append(members, content[start:])
+}
+
+// parseUnion 解析联合的成员，每个成员按单独的括号表达式解析
+func parseUnion(members []string) (*unionSegment, error) {
+	union := &unionSegment{members: make([]pathSegment, 0, len(members))}
+	for i, member := range members {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath,
+				"联合的第"+strconv.Itoa(i+1)+"个成员为空")
+		}
+		if strings.HasPrefix(member, "?") {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "联合中不能使用过滤表达式")
+		}
+		segment, consumed, err := parseNextSegment("[" + member + "]")
+		if err != nil {
+			return nil, err
+		}
+		if consumed != len(member)+2 {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPath, "无效的联合成员"+member)
+		}
+		union.members = append(union.members, segment)
+	}
+	return union, nil
+}
@@ -0,0 +1,437 @@
+// Package lint 提供gojson库的JSON风格检查功能。
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// 规则名称
+const (
+	RuleDuplicateKey       = "duplicate-key"
+	RuleKeyCasing          = "key-casing"
+	RuleMixedArrayTypes    = "mixed-array-types"
+	RuleMaxDepth           = "max-depth"
+	RuleNumericString      = "numeric-string"
+	RuleTrailingWhitespace = "trailing-whitespace"
+)
+
+// Rules 返回所有规则的名称
+func Rules() []string {
+	return []string{
+		RuleDuplicateKey,
+		RuleKeyCasing,
+		RuleMixedArrayTypes,
+		RuleMaxDepth,
+		RuleNumericString,
+		RuleTrailingWhitespace,
+	}
+}
+
+// Severity 表示问题的严重程度
+type Severity int
+
+const (
+	// SeverityOff 表示规则被禁用
+	SeverityOff Severity = iota
+	// SeverityInfo 表示提示
+	SeverityInfo
+	// SeverityWarning 表示警告
+	SeverityWarning
+	// SeverityError 表示错误
+	SeverityError
+)
+
+// String 返回严重程度的名称
+func (s Severity) String() string {
+	switch s {
+	case SeverityOff:
+		return "off"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity 将名称解析为严重程度
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "off":
+		return SeverityOff, nil
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	default:
+		return SeverityOff, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, fmt.Sprintf("未知的严重程度: %s", name))
+	}
+}
+
+// Config 表示检查配置
+type Config struct {
+	// Rules 是规则名称到严重程度的映射，未列出的规则被禁用
+	Rules map[string]Severity
+	// MaxDepth 是max-depth规则允许的最大嵌套深度
+	MaxDepth int
+}
+
+// DefaultConfig 返回推荐的检查配置
+func DefaultConfig() *Config {
+	return &Config{
+		Rules: map[string]Severity{
+			RuleDuplicateKey:       SeverityError,
+			RuleKeyCasing:          SeverityWarning,
+			RuleMixedArrayTypes:    SeverityWarning,
+			RuleMaxDepth:           SeverityWarning,
+			RuleNumericString:      SeverityInfo,
+			RuleTrailingWhitespace: SeverityInfo,
+		},
+		MaxDepth: 20,
+	}
+}
+
+// StrictConfig 返回严格的检查配置，所有规则都视为错误
+func StrictConfig() *Config {
+	config := DefaultConfig()
+	for rule := range config.Rules {
+		config.Rules[rule] = SeverityError
+	}
+	config.MaxDepth = 10
+	return config
+}
+
+// Set 设置规则的严重程度
+func (c *Config) Set(rule string, severity Severity) error {
+	for _, name := range Rules() {
+		if name == rule {
+			if c.Rules == nil {
+				c.Rules = make(map[string]Severity)
+			}
+			c.Rules[rule] = severity
+			return nil
+		}
+	}
+	return jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, fmt.Sprintf("未知的规则: %s", rule))
+}
+
+// Issue 表示检查发现的问题
+type Issue struct {
+	Rule     string
+	Severity Severity
+	Path     string // 问题所在的JSON Path
+	Line     int    // 问题所在的行号，从1开始，未知时为0
+	Message  string
+}
+
+// String 返回问题的字符串表示
+func (i Issue) String() string {
+	location := i.Path
+	if i.Line > 0 {
+		location = strings.TrimSuffix(fmt.Sprintf("%d:%s", i.Line, i.Path), ":")
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", location, i.Severity, i.Message, i.Rule)
+}
+
+// HasErrors 检查问题列表中是否包含错误
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// linter 保存一次检查的状态
+type linter struct {
+	config *Config
+	data   []byte
+	issues []Issue
+}
+
+// Lint 按配置检查JSON文档，config为nil时使用DefaultConfig
+func Lint(data []byte, config *Config) ([]Issue, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	value, err := parser.ParseBytesToValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{config: config, data: data}
+	if l.enabled(RuleDuplicateKey) {
+		if err := l.checkDuplicateKeys(); err != nil {
+			return nil, err
+		}
+	}
+	if l.enabled(RuleTrailingWhitespace) {
+		l.checkTrailingWhitespace()
+	}
+	l.checkValue(value, "$", 0)
+	if l.enabled(RuleKeyCasing) {
+		l.checkKeyCasing(value)
+	}
+
+	return l.issues, nil
+}
+
+// LintString 按配置检查JSON字符串
+func LintString(jsonStr string, config *Config) ([]Issue, error) {
+	return Lint([]byte(jsonStr), config)
+}
+
+// enabled 检查规则是否启用
+func (l *linter) enabled(rule string) bool {
+	return l.config.Rules[rule] != SeverityOff
+}
+
+// report 记录一个问题
+func (l *linter) report(rule, path string, line int, message string) {
+	l.issues = append(l.issues, Issue{
+		Rule:     rule,
+		Severity: l.config.Rules[rule],
+		Path:     path,
+		Line:     line,
+		Message:  message,
+	})
+}
+
+// lineAt 返回字节偏移所在的行号
+func (l *linter) lineAt(offset int64) int {
+	if offset > int64(len(l.data)) {
+		offset = int64(len(l.data))
+	}
+	return bytes.Count(l.data[:offset], []byte("\n")) + 1
+}
+
+// checkDuplicateKeys 在原始文本中查找重复的键，解析后的值无法保留重复键
+func (l *linter) checkDuplicateKeys() error {
+	dec := json.NewDecoder(bytes.NewReader(l.data))
+	return l.walkTokens(dec, "$")
+}
+
+// walkTokens 读取一个值并检查其中的重复键
+func (l *linter) walkTokens(dec *json.Decoder, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+			}
+			key := tok.(string)
+			childPath := types.ChildPath(path, types.KeySegment(key))
+			if seen[key] {
+				l.report(RuleDuplicateKey, childPath, l.lineAt(dec.InputOffset()), fmt.Sprintf("重复的键 %q", key))
+			}
+			seen[key] = true
+			if err := l.walkTokens(dec, childPath); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := l.walkTokens(dec, types.ChildPath(path, types.IndexSegment(i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 读取结束分隔符
+	if _, err := dec.Token(); err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	return nil
+}
+
+// checkTrailingWhitespace 查找以空白字符结尾的行
+func (l *linter) checkTrailingWhitespace() {
+	lines := bytes.Split(l.data, []byte("\n"))
+	for i, line := range lines {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) > 0 && (line[len(line)-1] == ' ' || line[len(line)-1] == '\t') {
+			l.report(RuleTrailingWhitespace, "", i+1, "行尾存在空白字符")
+		}
+	}
+}
+
+// checkValue 递归检查值的结构规则
+func (l *linter) checkValue(value types.JSONValue, path string, depth int) {
+	if value == nil {
+		return
+	}
+
+	if value.IsObject() || value.IsArray() {
+		if l.enabled(RuleMaxDepth) && depth >= l.config.MaxDepth {
+			l.report(RuleMaxDepth, path, 0, fmt.Sprintf("嵌套深度超过 %d", l.config.MaxDepth))
+			return
+		}
+	}
+
+	switch {
+	case value.IsObject():
+		obj, _ := value.AsObject()
+		for _, key := range obj.Keys() {
+			l.checkValue(obj.Get(key), types.ChildPath(path, types.KeySegment(key)), depth+1)
+		}
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		if l.enabled(RuleMixedArrayTypes) {
+			l.checkArrayTypes(arr, path)
+		}
+		for i, element := range arr.Values() {
+			l.checkValue(element, types.ChildPath(path, types.IndexSegment(i)), depth+1)
+		}
+	case value.IsString():
+		if l.enabled(RuleNumericString) {
+			s, _ := value.AsString()
+			if isNumericString(s) {
+				l.report(RuleNumericString, path, 0, fmt.Sprintf("字符串 %q 是数字，可以使用数字类型", s))
+			}
+		}
+	}
+}
+
+// checkArrayTypes 检查数组元素的类型是否一致，null不参与比较
+func (l *linter) checkArrayTypes(arr *types.JSONArray, path string) {
+	seen := make(map[string]bool)
+	for _, element := range arr.Values() {
+		if element == nil || element.IsNull() {
+			continue
+		}
+		seen[element.Type()] = true
+	}
+	if len(seen) <= 1 {
+		return
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	l.report(RuleMixedArrayTypes, path, 0, fmt.Sprintf("数组包含多种类型: %s", strings.Join(names, ", ")))
+}
+
+// keyOccurrence 记录键及其路径
+type keyOccurrence struct {
+	path  string
+	style string
+}
+
+// checkKeyCasing 检查文档中的键是否使用一致的命名风格
+func (l *linter) checkKeyCasing(value types.JSONValue) {
+	var occurrences []keyOccurrence
+	counts := make(map[string]int)
+
+	var collect func(value types.JSONValue, path string)
+	collect = func(value types.JSONValue, path string) {
+		if value == nil {
+			return
+		}
+		if obj, err := value.AsObject(); err == nil {
+			for _, key := range obj.Keys() {
+				childPath := types.ChildPath(path, types.KeySegment(key))
+				if style := keyStyle(key); style != "" {
+					occurrences = append(occurrences, keyOccurrence{path: childPath, style: style})
+					counts[style]++
+				}
+				collect(obj.Get(key), childPath)
+			}
+		} else if arr, err := value.AsArray(); err == nil {
+			for i, element := range arr.Values() {
+				collect(element, types.ChildPath(path, types.IndexSegment(i)))
+			}
+		}
+	}
+	collect(value, "$")
+
+	if len(counts) <= 1 {
+		return
+	}
+
+	// 出现次数最多的风格为主风格，次数相同时按名称选择
+	dominant := ""
+	for style, count := range counts {
+		if dominant == "" || count > counts[dominant] || (count == counts[dominant] && style < dominant) {
+			dominant = style
+		}
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.style != dominant {
+			l.report(RuleKeyCasing, occurrence.path, 0,
+				fmt.Sprintf("键使用%s风格，文档主要使用%s风格", occurrence.style, dominant))
+		}
+	}
+}
+
+// keyStyle 返回键的命名风格，全小写的单词与多种风格兼容，返回空字符串
+func keyStyle(key string) string {
+	hasUpper, hasLower := false, false
+	for _, c := range key {
+		if c >= 'A' && c <= 'Z' {
+			hasUpper = true
+		} else if c >= 'a' && c <= 'z' {
+			hasLower = true
+		}
+	}
+
+	switch {
+	case strings.Contains(key, "_"):
+		if hasUpper && !hasLower {
+			return "SCREAMING_SNAKE_CASE"
+		}
+		if hasUpper {
+			return "mixed"
+		}
+		return "snake_case"
+	case strings.Contains(key, "-"):
+		return "kebab-case"
+	case key != "" && key[0] >= 'A' && key[0] <= 'Z':
+		return "PascalCase"
+	case hasUpper:
+		return "camelCase"
+	default:
+		return ""
+	}
+}
+
+// isNumericString 检查字符串是否为JSON数字
+func isNumericString(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
@@ -0,0 +1,118 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		rule string
+		want []string // 期望问题的路径
+	}{
+		{
+			name: "重复的键",
+			json: `{"a":1,"b":{"c":1,"c":2},"a":3}`,
+			rule: RuleDuplicateKey,
+			want: []string{"$.b.c", "$.a"},
+		},
+		{
+			name: "键风格不一致",
+			json: `{"firstName":"a","lastName":"b","user_id":1}`,
+			rule: RuleKeyCasing,
+			want: []string{"$.user_id"},
+		},
+		{
+			name: "全小写键不参与风格检查",
+			json: `{"name":"a","user_id":1}`,
+			rule: RuleKeyCasing,
+			want: nil,
+		},
+		{
+			name: "数组类型混合",
+			json: `{"ok":[1,2,null],"bad":[1,"2",{"x":[true,false]}]}`,
+			rule: RuleMixedArrayTypes,
+			want: []string{"$.bad"},
+		},
+		{
+			name: "数字字符串",
+			json: `{"a":"42","b":"1e3","c":"abc","d":" 1","e":"0x10"}`,
+			rule: RuleNumericString,
+			want: []string{"$.a", "$.b"},
+		},
+		{
+			name: "嵌套过深",
+			json: `{"a":{"b":{"c":{"d":1}}},"x":[[1]]}`,
+			rule: RuleMaxDepth,
+			want: []string{"$.a.b", "$.x[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Rules: map[string]Severity{tt.rule: SeverityWarning}, MaxDepth: 2}
+			issues, err := LintString(tt.json, config)
+			if err != nil {
+				t.Fatalf("LintString() error = %v", err)
+			}
+			var got []string
+			for _, issue := range issues {
+				if issue.Rule != tt.rule {
+					t.Errorf("unexpected rule %s in %v", issue.Rule, issue)
+				}
+				got = append(got, issue.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("LintString() paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintTrailingWhitespace(t *testing.T) {
+	jsonStr := "{\n  \"a\": 1, \n  \"b\": 2\t\r\n}"
+	config := &Config{Rules: map[string]Severity{RuleTrailingWhitespace: SeverityInfo}}
+	issues, err := LintString(jsonStr, config)
+	if err != nil {
+		t.Fatalf("LintString() error = %v", err)
+	}
+	if len(issues) != 2 || issues[0].Line != 2 || issues[1].Line != 3 {
+		t.Errorf("LintString() = %v, want issues on lines 2 and 3", issues)
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	jsonStr := `{"a":1,"a":2}`
+
+	issues, err := LintString(jsonStr, nil)
+	if err != nil {
+		t.Fatalf("LintString() error = %v", err)
+	}
+	if !HasErrors(issues) {
+		t.Errorf("duplicate keys should be errors by default: %v", issues)
+	}
+	if got := issues[0].String(); got != `1:$.a: error: 重复的键 "a" [duplicate-key]` {
+		t.Errorf("Issue.String() = %s", got)
+	}
+
+	config := DefaultConfig()
+	if err := config.Set(RuleDuplicateKey, SeverityOff); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	issues, _ = LintString(jsonStr, config)
+	if len(issues) != 0 {
+		t.Errorf("disabled rule still reported: %v", issues)
+	}
+
+	if err := config.Set("no-such-rule", SeverityError); err == nil {
+		t.Errorf("Set() with unknown rule should fail")
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("ParseSeverity() with unknown name should fail")
+	}
+	if _, err := LintString(`{invalid}`, nil); err == nil {
+		t.Errorf("LintString() with invalid JSON should fail")
+	}
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// TruncatedMarker 标记因超出预算而被截断的位置。
+// 未解析完的数组以该字符串作为最后一个元素，
+// 未解析完的对象以该字符串为键、以该字符串为值。
+const TruncatedMarker = "…"
+
+// budgetDecoder 在预算范围内逐个令牌构建JSONValue
+type budgetDecoder struct {
+	p     *Parser
+	dec   *json.Decoder
+	nodes int
+}
+
+// parseWithBudget 解析JSON，超出预算时返回部分结果和ErrBudgetExceeded。
+// 与不限预算的解析不同，对象键保持文档中的顺序。
+func (p *Parser) parseWithBudget(jsonBytes []byte) (types.JSONValue, error) {
+	b := &budgetDecoder{p: p, dec: json.NewDecoder(bytes.NewReader(jsonBytes))}
+
+	value, err := b.decode("$")
+	if err != nil {
+		if !isBudgetError(err) {
+			return nil, err
+		}
+		if value == nil {
+			value = types.NewJSONString(TruncatedMarker)
+		}
+		return value, err
+	}
+
+	if _, err := b.dec.Token(); err != io.EOF {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败: 存在多余内容")
+	}
+	return value, nil
+}
+
+// exceeded 检查是否已超出预算
+func (b *budgetDecoder) exceeded() bool {
+	if b.p.opts.MaxNodes > 0 && b.nodes >= b.p.opts.MaxNodes {
+		return true
+	}
+	return b.p.opts.MaxBytes > 0 && b.dec.InputOffset() >= b.p.opts.MaxBytes
+}
+
+// budgetError 创建超出预算错误
+func budgetError(path string) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrBudgetExceeded, "超出解析预算，结果已截断").WithPath(path)
+}
+
+// isBudgetError 检查是否为超出预算错误
+func isBudgetError(err error) bool {
+	jsonErr, ok := err.(*jsonerrors.JSONError)
+	return ok && jsonErr.Code == jsonerrors.ErrBudgetExceeded
+}
+
+// decode 解析下一个值
+func (b *budgetDecoder) decode(path string) (types.JSONValue, error) {
+	if b.exceeded() {
+		return nil, budgetError(path)
+	}
+
+	tok, err := b.dec.Token()
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	b.nodes++
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return b.decodeObject(path)
+		}
+		if t == '[' {
+			return b.decodeArray(path)
+		}
+		return nil, jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("意外的分隔符 %q", t.String())).WithPath(path)
+	case string:
+		return types.NewJSONString(t), nil
+	case float64:
+		return types.NewJSONNumber(t), nil
+	case bool:
+		return types.NewJSONBool(t), nil
+	case nil:
+		return types.NewJSONNull(), nil
+	default:
+		return nil, jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("未知的令牌 %v", t)).WithPath(path)
+	}
+}
+
+// decodeObject 解析对象的剩余部分
+func (b *budgetDecoder) decodeObject(path string) (types.JSONValue, error) {
+	obj := types.NewJSONObject()
+	for b.dec.More() {
+		tok, err := b.dec.Token()
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+		}
+		key := b.p.intern(tok.(string))
+
+		child, err := b.decode(types.ChildPath(path, types.KeySegment(key)))
+		if err != nil {
+			if !isBudgetError(err) {
+				return nil, err
+			}
+			if child != nil {
+				obj.Put(key, child)
+			}
+			obj.Put(TruncatedMarker, types.NewJSONString(TruncatedMarker))
+			return obj, err
+		}
+		obj.Put(key, child)
+	}
+
+	if _, err := b.dec.Token(); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	return obj, nil
+}
+
+// decodeArray 解析数组的剩余部分
+func (b *budgetDecoder) decodeArray(path string) (types.JSONValue, error) {
+	values := make([]types.JSONValue, 0)
+	for b.dec.More() {
+		child, err := b.decode(types.ChildPath(path, types.IndexSegment(len(values))))
+		if err != nil {
+			if !isBudgetError(err) {
+				return nil, err
+			}
+			if child != nil {
+				values = append(values, child)
+			}
+			values = append(values, types.NewJSONString(TruncatedMarker))
+			return types.NewJSONArrayFromValuesUnsafe(values), err
+		}
+		values = append(values, child)
+	}
+
+	if _, err := b.dec.Token(); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	return types.NewJSONArrayFromValuesUnsafe(values), nil
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestParserBudget(t *testing.T) {
+	jsonStr := `{"a":1,"b":[1,2,3,4],"c":{"d":true}}`
+
+	tests := []struct {
+		name     string
+		opts     ParserOptions
+		want     string
+		wantPath string
+	}{
+		{
+			name: "预算充足",
+			opts: ParserOptions{MaxNodes: 100, MaxBytes: 1000},
+			want: `{"a":1,"b":[1,2,3,4],"c":{"d":true}}`,
+		},
+		{
+			name:     "节点预算截断数组",
+			opts:     ParserOptions{MaxNodes: 5},
+			want:     `{"a":1,"b":[1,2,"…"],"…":"…"}`,
+			wantPath: "$.b[2]",
+		},
+		{
+			name:     "节点预算截断根对象",
+			opts:     ParserOptions{MaxNodes: 1},
+			want:     `{"…":"…"}`,
+			wantPath: "$.a",
+		},
+		{
+			name:     "字节预算",
+			opts:     ParserOptions{MaxBytes: 15},
+			want:     `{"a":1,"b":[1,2,"…"],"…":"…"}`,
+			wantPath: "$.b[2]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := NewParser(tt.opts).Parse(jsonStr)
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+			} else {
+				jsonErr, ok := err.(*jsonerrors.JSONError)
+				if !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded {
+					t.Fatalf("Parse() error = %v, want %s", err, jsonerrors.ErrBudgetExceeded)
+				}
+				if jsonErr.Path != tt.wantPath {
+					t.Errorf("error path = %q, want %q", jsonErr.Path, tt.wantPath)
+				}
+			}
+			if value == nil {
+				t.Fatalf("Parse() returned nil value")
+			}
+			if value.String() != tt.want {
+				t.Errorf("Parse() = %s, want %s", value.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParserBudgetInvalidJSON(t *testing.T) {
+	p := NewParser(ParserOptions{MaxNodes: 100})
+	for _, jsonStr := range []string{`{"a":}`, `[1,2`, `1 2`} {
+		if _, err := p.Parse(jsonStr); err == nil {
+			t.Errorf("Parse(%q) should fail", jsonStr)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !gojson_nofast
+
+package parser
+
+import (
+	"github.com/UserLeeZJ/gojson/fast"
+)
+
+// unmarshalBytes 使用fast包解析JSON。
+func unmarshalBytes(data []byte, v interface{}) error {
+	return fast.Unmarshal(data, v)
+}
+
+// marshalBytes 使用fast包序列化Go对象。
+func marshalBytes(v interface{}) ([]byte, error) {
+	return fast.Marshal(v)
+}
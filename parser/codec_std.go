@@ -0,0 +1,25 @@
+//go:build gojson_nofast
+
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// unmarshalBytes 使用encoding/json解析JSON，构建时指定了gojson_nofast。
+func unmarshalBytes(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// marshalBytes 使用encoding/json序列化Go对象，与fast.Marshal一样不转义HTML字符。
+func marshalBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode总是添加一个换行符
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
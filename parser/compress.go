@@ -0,0 +1,197 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// Compression 表示数据的压缩格式。
+type Compression int
+
+const (
+	// CompressionNone 表示未压缩。
+	CompressionNone Compression = iota
+	// CompressionGzip 表示gzip压缩。
+	CompressionGzip
+	// CompressionZstd 表示zstd压缩。
+	// 为保持无外部依赖，zstd数据只能被识别，NewDecompressReader不解压它。
+	CompressionZstd
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompression 根据数据开头的魔数检测压缩格式，不依赖文件扩展名。
+func DetectCompression(header []byte) Compression {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// NewDecompressReader 返回自动解压的Reader。
+// gzip数据被透明解压，未压缩的数据原样返回；
+// 为保持无外部依赖，zstd数据只被识别而不解压，此时返回ErrNotSupported错误。
+func NewDecompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取输入失败").WithCause(err)
+	}
+
+	switch DetectCompression(header) {
+	case CompressionGzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "解压gzip数据失败").WithCause(err)
+		}
+		return zr, nil
+	case CompressionZstd:
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "不支持zstd压缩的数据，请先使用 zstd -dc 解压后通过管道传入")
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// OpenFile 打开文件并根据内容自动解压，path为空时读取标准输入。
+// 关闭返回的ReadCloser会同时关闭文件。
+func OpenFile(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return NewDecompressReader(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "打开文件失败").WithPath(path).WithCause(err)
+	}
+	reader, err := NewDecompressReader(file)
+	if err != nil {
+		file.Close()
+		if jsonErr, ok := err.(*jsonerrors.JSONError); ok {
+			return nil, jsonErr.WithPath(path)
+		}
+		return nil, err
+	}
+	return &fileReader{ReadCloser: reader, file: file}, nil
+}
+
+// ReadFile 读取文件的全部内容并根据内容自动解压，path为空时读取标准输入。
+func ReadFile(path string) ([]byte, error) {
+	reader, err := OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取文件失败").WithPath(path).WithCause(err)
+	}
+	return data, nil
+}
+
+// CreateFile 创建用于写入的文件，compress为true时写入的内容会被gzip压缩。
+// path为空时写入标准输出，关闭返回的WriteCloser不会关闭标准输出。
+// 为保持无外部依赖，不能写入zstd压缩的文件，path以.zst结尾时返回ErrNotSupported错误。
+// 调用者必须检查Close的错误，gzip流在Close时才写完。
+func CreateFile(path string, compress bool) (io.WriteCloser, error) {
+	var out io.WriteCloser = nopWriteCloser{os.Stdout}
+	if strings.HasSuffix(strings.ToLower(path), ".zst") {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "不支持写入zstd压缩的文件，请输出到标准输出后通过管道传给 zstd").WithPath(path)
+	}
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "创建文件失败").WithPath(path).WithCause(err)
+		}
+		out = file
+	}
+	if !compress {
+		return out, nil
+	}
+	return &gzipWriter{Writer: gzip.NewWriter(out), out: out}, nil
+}
+
+// ReplaceFile 用data替换文件的内容，compress为true时写入gzip压缩的数据。
+// 内容先写入同一目录下的临时文件，成功后再重命名为path，失败时原文件保持不变；
+// 已存在的文件保留原有的权限。
+func ReplaceFile(path string, data []byte, compress bool) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "创建临时文件失败").WithPath(path).WithCause(err)
+	}
+	tempPath := temp.Name()
+
+	var out io.WriteCloser = temp
+	if compress {
+		out = &gzipWriter{Writer: gzip.NewWriter(temp), out: temp}
+	}
+	_, err = out.Write(data)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "写入文件失败").WithPath(path).WithCause(err)
+	}
+	return nil
+}
+
+// fileReader 在关闭解压Reader的同时关闭底层文件。
+type fileReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (f *fileReader) Close() error {
+	err := f.ReadCloser.Close()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// gzipWriter 在关闭时先结束gzip流再关闭底层输出。
+type gzipWriter struct {
+	*gzip.Writer
+	out io.WriteCloser
+}
+
+func (g *gzipWriter) Close() error {
+	err := g.Writer.Close()
+	if closeErr := g.out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// nopWriteCloser 是Close不做任何事的WriteCloser。
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
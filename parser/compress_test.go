@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		header []byte
+		want   Compression
+	}{
+		{[]byte{0x1f, 0x8b, 0x08}, CompressionGzip},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, CompressionZstd},
+		{[]byte(`{"a":1}`), CompressionNone},
+		{nil, CompressionNone},
+	}
+	for _, tt := range tests {
+		if got := DetectCompression(tt.header); got != tt.want {
+			t.Errorf("DetectCompression(%x) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParseGzipFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// 扩展名不影响检测
+	path := filepath.Join(dir, "data.json")
+	out, err := CreateFile(path, true)
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	io.WriteString(out, `{"name":"John"}`)
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	if DetectCompression(raw) != CompressionGzip {
+		t.Fatalf("CreateFile(compress=true) 应该写入gzip数据")
+	}
+
+	got, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if got.String() != `{"name":"John"}` {
+		t.Errorf("ParseFile() = %v", got.String())
+	}
+}
+
+func TestNewDecompressReader(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("[1,2]"))
+	zw.Close()
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"gzip", buf.Bytes(), "[1,2]"},
+		{"未压缩", []byte("[1,2]"), "[1,2]"},
+		{"短于魔数", []byte("1"), "1"},
+	}
+	for _, tt := range tests {
+		r, err := NewDecompressReader(bytes.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("%s: NewDecompressReader() error = %v", tt.name, err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s: ReadAll() error = %v", tt.name, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: data = %q, want %q", tt.name, data, tt.want)
+		}
+	}
+
+	_, err := NewDecompressReader(bytes.NewReader([]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}))
+	var jsonErr *jsonerrors.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrNotSupported {
+		t.Errorf("zstd数据应该返回ErrNotSupported, got %v", err)
+	}
+}
+
+func TestReadZstdFile(t *testing.T) {
+	// zstd -c --no-check 压缩的 {"a":1}
+	data := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 0x58, 0x39, 0x00, 0x00, 0x7b, 0x22, 0x61, 0x22, 0x3a, 0x31, 0x7d}
+	path := filepath.Join(t.TempDir(), "data.json.zst")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// zstd数据被识别但不解压，错误中包含文件路径
+	_, err := ReadFile(path)
+	var jsonErr *jsonerrors.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrNotSupported || jsonErr.Path != path {
+		t.Errorf("ReadFile() error = %v, 期望包含路径的ErrNotSupported", err)
+	}
+}
+
+func TestCreateZstdFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json.zst")
+	_, err := CreateFile(path, false)
+	var jsonErr *jsonerrors.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrNotSupported || jsonErr.Path != path {
+		t.Errorf("CreateFile() error = %v, 期望包含路径的ErrNotSupported", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("CreateFile() 不应该创建.zst文件")
+	}
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceFile(path, []byte(`{"a":1}`), true); err != nil {
+		t.Fatalf("ReplaceFile() error = %v", err)
+	}
+	got, err := ReadFile(path)
+	if err != nil || string(got) != `{"a":1}` {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("ReplaceFile() 权限 = %v, 期望 0600", info.Mode().Perm())
+	}
+
+	// 不留下临时文件
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("目录中有 %d 个文件，期望 1 个", len(entries))
+	}
+
+	// 目录不存在时原文件不受影响
+	if err := ReplaceFile(filepath.Join(dir, "missing", "data.json"), []byte("{}"), false); err == nil {
+		t.Errorf("ReplaceFile() 目录不存在时应该出错")
+	}
+}
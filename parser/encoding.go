@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// Encoding 表示JSON文本的字符编码。
+type Encoding int
+
+const (
+	// EncodingUTF8 表示UTF-8编码。
+	EncodingUTF8 Encoding = iota
+	// EncodingUTF16BE 表示大端序的UTF-16编码。
+	EncodingUTF16BE
+	// EncodingUTF16LE 表示小端序的UTF-16编码。
+	EncodingUTF16LE
+	// EncodingUTF32BE 表示大端序的UTF-32编码。
+	EncodingUTF32BE
+	// EncodingUTF32LE 表示小端序的UTF-32编码。
+	EncodingUTF32LE
+)
+
+// String 返回编码的名称。
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF32BE:
+		return "UTF-32BE"
+	case EncodingUTF32LE:
+		return "UTF-32LE"
+	default:
+		return "UTF-8"
+	}
+}
+
+// unitSize 返回编码中一个代码单元的字节数。
+func (e Encoding) unitSize() int {
+	switch e {
+	case EncodingUTF16BE, EncodingUTF16LE:
+		return 2
+	case EncodingUTF32BE, EncodingUTF32LE:
+		return 4
+	default:
+		return 1
+	}
+}
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16BE = []byte{0xfe, 0xff}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF32BE = []byte{0x00, 0x00, 0xfe, 0xff}
+	bomUTF32LE = []byte{0xff, 0xfe, 0x00, 0x00}
+)
+
+// DetectEncoding 根据数据开头的字节检测编码，返回编码和字节顺序标记（BOM）的长度。
+// 有BOM时按BOM判断；没有BOM时按RFC 4627第3节的方法，根据前4个字节中0x00的位置判断，
+// 因为JSON文本的前两个字符一定是ASCII字符。
+func DetectEncoding(header []byte) (Encoding, int) {
+	switch {
+	case bytes.HasPrefix(header, bomUTF8):
+		return EncodingUTF8, len(bomUTF8)
+	case bytes.HasPrefix(header, bomUTF32BE):
+		return EncodingUTF32BE, len(bomUTF32BE)
+	case bytes.HasPrefix(header, bomUTF32LE):
+		return EncodingUTF32LE, len(bomUTF32LE)
+	case bytes.HasPrefix(header, bomUTF16BE):
+		return EncodingUTF16BE, len(bomUTF16BE)
+	case bytes.HasPrefix(header, bomUTF16LE):
+		return EncodingUTF16LE, len(bomUTF16LE)
+	}
+
+	if len(header) >= 4 {
+		switch {
+		case header[0] == 0 && header[1] == 0 && header[2] == 0 && header[3] != 0:
+			return EncodingUTF32BE, 0
+		case header[0] != 0 && header[1] == 0 && header[2] == 0 && header[3] == 0:
+			return EncodingUTF32LE, 0
+		}
+	}
+	if len(header) >= 2 {
+		switch {
+		case header[0] == 0 && header[1] != 0:
+			return EncodingUTF16BE, 0
+		case header[0] != 0 && header[1] == 0:
+			return EncodingUTF16LE, 0
+		}
+	}
+	return EncodingUTF8, 0
+}
+
+// ToUTF8 去掉数据开头的BOM，并把UTF-16和UTF-32编码的数据转换为UTF-8。
+// 已经是UTF-8且没有BOM的数据原样返回，不会复制。
+// UTF-16中不成对的代理项和UTF-32中无效的码点被替换为U+FFFD。
+func ToUTF8(data []byte) ([]byte, error) {
+	encoding, bomLen := DetectEncoding(data)
+	data = data[bomLen:]
+	if encoding == EncodingUTF8 {
+		return data, nil
+	}
+
+	size := encoding.unitSize()
+	if len(data)%size != 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidEncoding,
+			"输入的长度不是"+encoding.String()+"代码单元的整数倍")
+	}
+	out := make([]byte, 0, len(data)/size*3)
+	var pending rune = -1
+	for i := 0; i < len(data); i += size {
+		out, pending = appendUnit(out, encoding, data[i:i+size], pending)
+	}
+	if pending >= 0 {
+		out = utf8.AppendRune(out, utf8.RuneError)
+	}
+	return out, nil
+}
+
+// appendUnit 把一个代码单元转换为UTF-8追加到out中。
+// pending是尚未配对的UTF-16高代理项，没有时为-1。
+func appendUnit(out []byte, encoding Encoding, unit []byte, pending rune) ([]byte, rune) {
+	var r rune
+	switch encoding {
+	case EncodingUTF16BE:
+		r = rune(binary.BigEndian.Uint16(unit))
+	case EncodingUTF16LE:
+		r = rune(binary.LittleEndian.Uint16(unit))
+	case EncodingUTF32BE:
+		return utf8.AppendRune(out, validRune(binary.BigEndian.Uint32(unit))), -1
+	case EncodingUTF32LE:
+		return utf8.AppendRune(out, validRune(binary.LittleEndian.Uint32(unit))), -1
+	}
+
+	if pending >= 0 {
+		if decoded := utf16.DecodeRune(pending, r); decoded != utf8.RuneError {
+			return utf8.AppendRune(out, decoded), -1
+		}
+		out = utf8.AppendRune(out, utf8.RuneError)
+	}
+	if utf16.IsSurrogate(r) {
+		if r < 0xdc00 {
+			return out, r
+		}
+		return utf8.AppendRune(out, utf8.RuneError), -1
+	}
+	return utf8.AppendRune(out, r), -1
+}
+
+// validRune 把UTF-32代码单元转换为rune，无效的码点转换为U+FFFD。
+func validRune(u uint32) rune {
+	if u > utf8.MaxRune || !utf8.ValidRune(rune(u)) {
+		return utf8.RuneError
+	}
+	return rune(u)
+}
+
+// checkUTF8 检查数据是否是没有BOM的合法UTF-8，用于严格模式。
+func checkUTF8(data []byte) error {
+	if encoding, bomLen := DetectEncoding(data); encoding != EncodingUTF8 || bomLen > 0 {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidEncoding,
+			"输入不是UTF-8编码或带有BOM，检测到的编码为"+encoding.String())
+	}
+	if !utf8.Valid(data) {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidEncoding, "输入包含无效的UTF-8字节")
+	}
+	return nil
+}
+
+// NewUTF8Reader 返回输出UTF-8的Reader，用于流式读取。
+// 第一次读取时检测编码，开头的BOM被丢弃，UTF-16和UTF-32编码的数据边读边转换为UTF-8。
+func NewUTF8Reader(r io.Reader) io.Reader {
+	return &utf8Reader{reader: bufio.NewReader(r)}
+}
+
+// utf8Reader 在第一次读取时检测编码。
+type utf8Reader struct {
+	reader  *bufio.Reader
+	decoded io.Reader // 检测编码后实际读取的Reader
+}
+
+func (u *utf8Reader) Read(p []byte) (int, error) {
+	if u.decoded == nil {
+		header, err := u.reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		// 只有开头是0x00或BOM时才需要4个字节，普通的UTF-8输入最多等待2个字节，
+		// 避免在网络输入上等待还没有发送的数据
+		if header[0] != 0 && header[0] < 0x80 {
+			header, _ = u.reader.Peek(2)
+		}
+		if header[0] == 0 || header[0] >= 0x80 || len(header) > 1 && header[1] == 0 {
+			header, _ = u.reader.Peek(4)
+		}
+		encoding, bomLen := DetectEncoding(header)
+		u.reader.Discard(bomLen)
+		u.decoded = u.reader
+		if encoding != EncodingUTF8 {
+			u.decoded = &transcodeReader{reader: u.reader, encoding: encoding, pending: -1}
+		}
+	}
+	return u.decoded.Read(p)
+}
+
+// transcodeReader 把UTF-16或UTF-32编码的输入转换为UTF-8。
+type transcodeReader struct {
+	reader   *bufio.Reader
+	encoding Encoding
+	unit     [4]byte
+	pending  rune   // 尚未配对的高代理项，没有时为-1
+	out      []byte // 已转换的UTF-8数据
+	off      int    // out中已被读取的字节数
+	err      error
+}
+
+func (t *transcodeReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for t.off == len(t.out) && t.err == nil {
+		t.out, t.off = t.out[:0], 0
+		t.fill(len(p))
+	}
+
+	n := copy(p, t.out[t.off:])
+	t.off += n
+	if t.off == len(t.out) && t.err != nil {
+		return n, t.err
+	}
+	return n, nil
+}
+
+// fill 转换至少一个代码单元，在转换出n个字节或已缓冲的输入用完时返回，避免阻塞在网络输入上。
+func (t *transcodeReader) fill(n int) {
+	unit := t.unit[:t.encoding.unitSize()]
+	for len(t.out) < n {
+		if _, err := io.ReadFull(t.reader, unit); err != nil {
+			switch err {
+			case io.EOF:
+				if t.pending >= 0 {
+					t.out = utf8.AppendRune(t.out, utf8.RuneError)
+					t.pending = -1
+				}
+				t.err = io.EOF
+			case io.ErrUnexpectedEOF:
+				t.err = jsonerrors.NewJSONError(jsonerrors.ErrInvalidEncoding,
+					"输入的长度不是"+t.encoding.String()+"代码单元的整数倍")
+			default:
+				t.err = err
+			}
+			return
+		}
+		t.out, t.pending = appendUnit(t.out, t.encoding, unit, t.pending)
+		if t.reader.Buffered() == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// encode 把字符串编码为指定的编码，bom为true时在开头加上BOM
+func encode(s string, encoding Encoding, bom bool) []byte {
+	if bom {
+		s = "\ufeff" + s
+	}
+	var out []byte
+	switch encoding {
+	case EncodingUTF16BE, EncodingUTF16LE:
+		for _, u := range utf16.Encode([]rune(s)) {
+			if encoding == EncodingUTF16BE {
+				out = binary.BigEndian.AppendUint16(out, u)
+			} else {
+				out = binary.LittleEndian.AppendUint16(out, u)
+			}
+		}
+	case EncodingUTF32BE, EncodingUTF32LE:
+		for _, r := range s {
+			if encoding == EncodingUTF32BE {
+				out = binary.BigEndian.AppendUint32(out, uint32(r))
+			} else {
+				out = binary.LittleEndian.AppendUint32(out, uint32(r))
+			}
+		}
+	default:
+		out = []byte(s)
+	}
+	return out
+}
+
+var allEncodings = []Encoding{EncodingUTF8, EncodingUTF16BE, EncodingUTF16LE, EncodingUTF32BE, EncodingUTF32LE}
+
+func TestDetectEncoding(t *testing.T) {
+	for _, encoding := range allEncodings {
+		for _, bom := range []bool{false, true} {
+			data := encode(`{"a":1}`, encoding, bom)
+			got, bomLen := DetectEncoding(data)
+			if got != encoding {
+				t.Errorf("DetectEncoding(%s, bom=%v) = %s", encoding, bom, got)
+			}
+			if (bomLen > 0) != bom {
+				t.Errorf("DetectEncoding(%s, bom=%v) BOM长度 = %d", encoding, bom, bomLen)
+			}
+		}
+	}
+
+	if got, _ := DetectEncoding([]byte("1")); got != EncodingUTF8 {
+		t.Errorf("DetectEncoding(\"1\") = %s, 期望 UTF-8", got)
+	}
+}
+
+func TestParseBytesToValueEncodings(t *testing.T) {
+	const input = `{"name":"café 😀","n":[1,2]}`
+	for _, encoding := range allEncodings {
+		for _, bom := range []bool{false, true} {
+			value, err := ParseBytesToValue(encode(input, encoding, bom))
+			if err != nil {
+				t.Errorf("ParseBytesToValue(%s, bom=%v) 错误 = %v", encoding, bom, err)
+				continue
+			}
+			obj, _ := value.AsObject()
+			if got, _ := obj.GetString("name"); got != "café 😀" {
+				t.Errorf("ParseBytesToValue(%s, bom=%v) name = %q", encoding, bom, got)
+			}
+		}
+	}
+
+	_, err := ParseBytesToValue(encode(`"a"`, EncodingUTF16LE, false)[:5])
+	var jsonErr *jsonerrors.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrInvalidEncoding {
+		t.Errorf("ParseBytesToValue() 截断的UTF-16输入错误 = %v, 期望 %s", err, jsonerrors.ErrInvalidEncoding)
+	}
+}
+
+func TestToUTF8UnpairedSurrogate(t *testing.T) {
+	data := binary.BigEndian.AppendUint16(nil, '"')
+	data = binary.BigEndian.AppendUint16(data, 0xd83d)
+	data = binary.BigEndian.AppendUint16(data, '"')
+	got, err := ToUTF8(data)
+	if err != nil || string(got) != "\"\ufffd\"" {
+		t.Errorf("ToUTF8() = %q, %v", got, err)
+	}
+
+	input := []byte(`{}`)
+	if got, _ := ToUTF8(input); &got[0] != &input[0] {
+		t.Errorf("ToUTF8() 复制了UTF-8输入")
+	}
+}
+
+func TestStrictEncoding(t *testing.T) {
+	p := NewParser(ParserOptions{StrictEncoding: true})
+	inputs := map[string][]byte{
+		"BOM":     encode(`{}`, EncodingUTF8, true),
+		"UTF-16":  encode(`{}`, EncodingUTF16LE, false),
+		"无效UTF-8": []byte("\"\xff\""),
+	}
+	for name, input := range inputs {
+		_, err := p.ParseBytes(input)
+		var jsonErr *jsonerrors.JSONError
+		if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrInvalidEncoding {
+			t.Errorf("%s: ParseBytes() 错误 = %v, 期望 %s", name, err, jsonerrors.ErrInvalidEncoding)
+		}
+	}
+	if _, err := p.ParseBytes([]byte(`{"a":"é"}`)); err != nil {
+		t.Errorf("ParseBytes() 错误 = %v", err)
+	}
+
+	lenient := NewParser(ParserOptions{})
+	if _, err := lenient.ParseBytes(inputs["UTF-16"]); err != nil {
+		t.Errorf("非严格模式 ParseBytes() 错误 = %v", err)
+	}
+}
+
+func TestNewUTF8Reader(t *testing.T) {
+	const input = `["café", "😀"]`
+	for _, encoding := range allEncodings {
+		for _, bom := range []bool{false, true} {
+			// 每次只读一个字节，检查跨越读取边界的代码单元和代理对
+			r := NewUTF8Reader(iotest.OneByteReader(&sliceReader{data: encode(input, encoding, bom)}))
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != input {
+				t.Errorf("NewUTF8Reader(%s, bom=%v) = %q, %v", encoding, bom, got, err)
+			}
+		}
+	}
+
+	r := NewUTF8Reader(&sliceReader{data: []byte{0xff, 0xfe, '1', 0, '2'}})
+	if _, err := io.ReadAll(r); err == nil {
+		t.Errorf("NewUTF8Reader() 截断的UTF-16输入应该返回错误")
+	}
+}
+
+// sliceReader 是最简单的Reader，不实现io.WriterTo等接口
+type sliceReader struct {
+	data []byte
+}
+
+func (s *sliceReader) Read(p []byte) (int, error) {
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
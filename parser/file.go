@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// WriteOptions 表示WriteFile的写入选项。
+type WriteOptions struct {
+	// Indent 是缩进字符串，为空时输出紧凑格式。
+	Indent string
+	// Perm 是新建文件的权限，为0时使用0644。
+	Perm os.FileMode
+}
+
+// MustParse 将JSON字符串解析为JSONValue，解析失败时panic。
+// 适用于初始化阶段的常量文档。
+func MustParse(jsonStr string) types.JSONValue {
+	value, err := ParseToValue(jsonStr)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ParseFile 读取文件并解析为JSONValue。
+// gzip压缩的文件会根据内容自动解压。
+func ParseFile(path string) (types.JSONValue, error) {
+	if path == "" {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "文件路径为空")
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytesToValue(data)
+}
+
+// WriteFile 将JSONValue写入文件。
+// 内容先写入同目录下的临时文件，再通过重命名替换目标文件，保证写入是原子的。
+func WriteFile(path string, value types.JSONValue, opts *WriteOptions) error {
+	if value == nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON值为空")
+	}
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "序列化JSON失败").WithCause(err)
+	}
+	if opts.Indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", opts.Indent); err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "格式化JSON失败").WithCause(err)
+		}
+		data = buf.Bytes()
+	}
+
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "创建临时文件失败").WithPath(path).WithCause(err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "写入文件失败").WithPath(path).WithCause(err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "设置文件权限失败").WithPath(path).WithCause(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "写入文件失败").WithPath(path).WithCause(err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "替换文件失败").WithPath(path).WithCause(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMustParse(t *testing.T) {
+	value := MustParse(`{"name":"John"}`)
+	if !value.IsObject() {
+		t.Errorf("MustParse() type = %v, want object", value.Type())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustParse() should panic on invalid JSON")
+		}
+	}()
+	MustParse(`{invalid}`)
+}
+
+func TestParseFileAndWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	value := MustParse(`{"name":"John","tags":["a","b"]}`)
+
+	// 写入格式化文件
+	if err := WriteFile(path, value, &WriteOptions{Indent: "  "}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	want := "{\n  \"name\": \"John\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}"
+	if string(data) != want {
+		t.Errorf("WriteFile() content = %q, want %q", data, want)
+	}
+
+	// 覆盖为紧凑格式
+	if err := WriteFile(path, value, nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if got.String() != value.String() {
+		t.Errorf("ParseFile() = %v, want %v", got.String(), value.String())
+	}
+
+	// 不应留下临时文件
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("WriteFile() left %d files in directory, want 1", len(entries))
+	}
+
+	// 文件不存在
+	if _, err := ParseFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("ParseFile() should return error for missing file")
+	}
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"sort"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ParserOptions 表示Parser的解析选项。
+type ParserOptions struct {
+	// InternKeys 为true时，相同的对象键共享同一个字符串。
+	// 对包含大量重复记录的文档可以显著减少内存占用。
+	InternKeys bool
+
+	// MaxBytes 是可以物化的输入字节数上限，为0时不限制。
+	MaxBytes int64
+	// MaxNodes 是可以物化的节点数上限，为0时不限制。
+	MaxNodes int
+
+	// StrictEncoding 为true时，带有BOM、UTF-16或UTF-32编码以及包含无效UTF-8字节的输入返回
+	// ErrInvalidEncoding错误；为false时忽略BOM并把UTF-16和UTF-32编码的输入转换为UTF-8。
+	StrictEncoding bool
+
+	// Strings 决定如何处理字符串中的控制字符、无效的转义、不成对的代理项和无效的UTF-8字节。
+	Strings StringMode
+
+	// CaseInsensitiveKeys 为true时，解析出的所有对象在按键访问时忽略大小写，
+	// 只有大小写不同的重复键被合并为一个，输出保留原来的写法（见JSONObject.SetCaseInsensitive）。
+	CaseInsensitiveKeys bool
+}
+
+// Parser 是可复用的JSON解析器。
+// 启用键驻留后，同一Parser解析的所有文档共享键字符串池。
+// Parser不是并发安全的。
+type Parser struct {
+	opts    ParserOptions
+	keys    map[string]string
+	repairs []Repair
+}
+
+// NewParser 创建一个新的Parser。
+func NewParser(opts ParserOptions) *Parser {
+	p := &Parser{opts: opts}
+	if opts.InternKeys {
+		p.keys = make(map[string]string)
+	}
+	return p
+}
+
+// Parse 将JSON字符串解析为JSONValue。
+func (p *Parser) Parse(jsonStr string) (types.JSONValue, error) {
+	if jsonStr == "" {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字符串为空")
+	}
+	return p.ParseBytes([]byte(jsonStr))
+}
+
+// ParseBytes 将JSON字节数组解析为JSONValue。
+func (p *Parser) ParseBytes(jsonBytes []byte) (types.JSONValue, error) {
+	value, err := p.parseBytes(jsonBytes)
+	if err != nil || !p.opts.CaseInsensitiveKeys {
+		return value, err
+	}
+	foldKeys(value)
+	return value, nil
+}
+
+// parseBytes 按选项解析JSON字节数组，不处理CaseInsensitiveKeys
+func (p *Parser) parseBytes(jsonBytes []byte) (types.JSONValue, error) {
+	p.repairs = nil
+	if len(jsonBytes) == 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字节数组为空")
+	}
+	jsonBytes, err := p.decode(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, p.repairs, err = checkStrings(jsonBytes, p.opts.Strings)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.MaxBytes > 0 || p.opts.MaxNodes > 0 {
+		return p.parseWithBudget(jsonBytes)
+	}
+
+	if p.opts.InternKeys {
+		return p.parseInterned(jsonBytes)
+	}
+
+	var raw interface{}
+	err = unmarshalBytes(jsonBytes, &raw)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	return convertToJSONValue(raw)
+}
+
+// decode 按StrictEncoding选项检查输入的编码或把输入转换为UTF-8
+func (p *Parser) decode(jsonBytes []byte) ([]byte, error) {
+	if p.opts.StrictEncoding {
+		return jsonBytes, checkUTF8(jsonBytes)
+	}
+	return ToUTF8(jsonBytes)
+}
+
+// Repairs 返回最近一次解析时对字符串所做的修复，只有Strings为StringsRepair时才会修复。
+func (p *Parser) Repairs() []Repair {
+	return p.repairs
+}
+
+// InternedKeys 返回键字符串池中的键数量。
+func (p *Parser) InternedKeys() int {
+	return len(p.keys)
+}
+
+// Reset 清空键字符串池。
+// 池随不同键的数量增长，长期复用的Parser可以定期调用Reset。
+func (p *Parser) Reset() {
+	if p.opts.InternKeys {
+		p.keys = make(map[string]string)
+	}
+}
+
+// intern 返回池中与key相等的字符串
+func (p *Parser) intern(key string) string {
+	if !p.opts.InternKeys {
+		return key
+	}
+	if s, ok := p.keys[key]; ok {
+		return s
+	}
+	p.keys[key] = key
+	return key
+}
+
+// foldKeys 让value中的所有对象忽略键的大小写
+func foldKeys(value types.JSONValue) {
+	switch val := value.(type) {
+	case *types.JSONObject:
+		val.SetCaseInsensitive(true)
+		val.ForEach(func(key string, child types.JSONValue) {
+			foldKeys(child)
+		})
+	case *types.JSONArray:
+		for _, element := range val.Values() {
+			foldKeys(element)
+		}
+	}
+}
+
+// convert 将Go原生类型转换为JSONValue，对象键经过驻留
+func (p *Parser) convert(v interface{}) (types.JSONValue, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		obj := types.NewJSONObject()
+		for _, k := range keys {
+			child, err := p.convert(val[k])
+			if err != nil {
+				return nil, err
+			}
+			obj.Put(p.intern(k), child)
+		}
+		return obj, nil
+	case []interface{}:
+		values := make([]types.JSONValue, len(val))
+		for i, item := range val {
+			child, err := p.convert(item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = child
+		}
+		return types.NewJSONArrayFromValuesUnsafe(values), nil
+	default:
+		return convertToJSONValue(val)
+	}
+}
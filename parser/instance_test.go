@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestParserInternKeys(t *testing.T) {
+	jsonStr := `[{"name":"a","id":1},{"name":"b","id":2},{"name":"c","id":3}]`
+
+	p := NewParser(ParserOptions{InternKeys: true})
+	value, err := p.Parse(jsonStr)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.InternedKeys() != 2 {
+		t.Errorf("InternedKeys() = %d, want 2", p.InternedKeys())
+	}
+
+	arr, _ := value.AsArray()
+	first, _ := arr.Get(0).AsObject()
+	for i := 1; i < arr.Size(); i++ {
+		obj, _ := arr.Get(i).AsObject()
+		for j, key := range obj.Keys() {
+			if unsafe.StringData(key) != unsafe.StringData(first.Keys()[j]) {
+				t.Errorf("key %q of element %d is not shared", key, i)
+			}
+		}
+	}
+
+	// 池在多次解析之间共享
+	if _, err := p.Parse(`{"name":"d","extra":true}`); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.InternedKeys() != 3 {
+		t.Errorf("InternedKeys() = %d, want 3", p.InternedKeys())
+	}
+
+	p.Reset()
+	if p.InternedKeys() != 0 {
+		t.Errorf("InternedKeys() after Reset = %d, want 0", p.InternedKeys())
+	}
+
+	// 结果与包级解析函数一致
+	want, _ := ParseToValue(jsonStr)
+	if value.String() != want.String() {
+		t.Errorf("Parse() = %s, want %s", value.String(), want.String())
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	for _, opts := range []ParserOptions{{}, {InternKeys: true}} {
+		p := NewParser(opts)
+		if _, err := p.Parse(""); err == nil {
+			t.Errorf("Parse(\"\") with %+v should fail", opts)
+		}
+		if _, err := p.Parse("{invalid}"); err == nil {
+			t.Errorf("Parse(invalid) with %+v should fail", opts)
+		}
+	}
+}
+
+func TestParserCaseInsensitiveKeys(t *testing.T) {
+	for _, opts := range []ParserOptions{{CaseInsensitiveKeys: true}, {CaseInsensitiveKeys: true, MaxNodes: 100}} {
+		p := NewParser(opts)
+		value, err := p.Parse(`{"ID":1,"User":{"Name":"a"},"items":[{"Sku":"x"}]}`)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		obj, _ := value.AsObject()
+		if n, err := obj.GetNumber("id"); err != nil || n != 1 {
+			t.Errorf("GetNumber(id) = %v, %v", n, err)
+		}
+		user, _ := obj.GetObject("user")
+		if name, _ := user.GetString("NAME"); name != "a" {
+			t.Errorf("user.NAME = %q", name)
+		}
+		items, _ := obj.GetArray("Items")
+		item, _ := items.GetObject(0)
+		if !item.Has("sku") {
+			t.Errorf("items[0] should have sku")
+		}
+		if value.String() != `{"ID":1,"User":{"Name":"a"},"items":[{"Sku":"x"}]}` {
+			t.Errorf("String() = %s", value.String())
+		}
+	}
+
+	// 只有大小写不同的重复键被合并
+	value, _ := NewParser(ParserOptions{CaseInsensitiveKeys: true}).Parse(`{"id":1,"ID":2}`)
+	if obj, _ := value.AsObject(); obj.Size() != 1 {
+		t.Errorf("Parse() = %s, want one key", value.String())
+	}
+	// 默认区分大小写
+	value, _ = NewParser(ParserOptions{}).Parse(`{"ID":1}`)
+	if obj, _ := value.AsObject(); obj.Has("id") {
+		t.Errorf("keys should be case-sensitive by default")
+	}
+}
+
+func TestParserInternKeysMatchesParse(t *testing.T) {
+	inputs := []string{
+		`{"b":1,"a":[true,false,null],"c":{"z":"x","y":-1.5e3}}`,
+		`{"a":1,"a":2}`,
+		`{"esc\u0061ped":"tab\there","\u4e2d":"\ud83d\ude00"}`,
+		"{\"bad\":\"\xff\"}",
+		` [ 1 , 2 , { } , [ ] , "" ] `,
+		`"text"`,
+		`0`,
+		`1e400`,
+		`{"a":1}x`,
+	}
+	for _, input := range inputs {
+		want, wantErr := ParseBytesToValue([]byte(input))
+		got, err := NewParser(ParserOptions{InternKeys: true}).ParseBytes([]byte(input))
+		if (err == nil) != (wantErr == nil) {
+			t.Errorf("ParseBytes(%q) error = %v, want %v", input, err, wantErr)
+			continue
+		}
+		if err == nil && got.String() != want.String() {
+			t.Errorf("ParseBytes(%q) = %s, want %s", input, got.String(), want.String())
+		}
+	}
+}
+
+// BenchmarkParserInternKeys 比较解析大量重复记录时是否驻留键的内存分配，
+// 使用 go test -run xxx -bench ParserInternKeys -benchmem ./parser 运行
+func BenchmarkParserInternKeys(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id":%d,"name":"user%d","email":"user%d@example.com","active":true,"score":%d.5}`, i, i, i, i)
+	}
+	sb.WriteString("]")
+	data := []byte(sb.String())
+
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("InternKeys=%v", intern), func(b *testing.B) {
+			p := NewParser(ParserOptions{InternKeys: intern})
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := p.ParseBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package parser
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// internDecoder 在解析的同时驻留对象键。
+// 没有转义的键直接用输入中的字节查找键池，池中已有的键不再分配新的字符串。
+// 输入必须是合法的JSON，解析结果与convertToJSONValue相同：对象的键按字典序排列，重复的键使用最后一个值
+type internDecoder struct {
+	p    *Parser
+	data []byte
+	pos  int
+}
+
+// member 是解析中的对象成员
+type member struct {
+	key   string
+	value types.JSONValue
+}
+
+// parseInterned 解析合法的JSON并驻留对象键，不合法的输入返回与unmarshalBytes相同的错误
+func (p *Parser) parseInterned(jsonBytes []byte) (types.JSONValue, error) {
+	if !json.Valid(jsonBytes) {
+		var raw interface{}
+		if err := unmarshalBytes(jsonBytes, &raw); err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+		}
+		// unmarshalBytes接受的输入（例如fast包的回退解析）仍按原来的方式转换
+		return p.convert(raw)
+	}
+	d := &internDecoder{p: p, data: jsonBytes}
+	return d.value()
+}
+
+// skipSpace 跳过空白
+func (d *internDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\r', '\n':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value 解析下一个值
+func (d *internDecoder) value() (types.JSONValue, error) {
+	d.skipSpace()
+	switch c := d.data[d.pos]; {
+	case c == '{':
+		return d.object()
+	case c == '[':
+		return d.array()
+	case c == '"':
+		s, err := d.string(false)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewJSONString(s), nil
+	case c == 't':
+		d.pos += len("true")
+		return types.NewJSONBool(true), nil
+	case c == 'f':
+		d.pos += len("false")
+		return types.NewJSONBool(false), nil
+	case c == 'n':
+		d.pos += len("null")
+		return types.NewJSONNull(), nil
+	default:
+		return d.number()
+	}
+}
+
+// object 解析对象
+func (d *internDecoder) object() (types.JSONValue, error) {
+	d.pos++ // {
+	var members []member
+	for {
+		d.skipSpace()
+		if d.data[d.pos] == '}' {
+			d.pos++
+			break
+		}
+		if d.data[d.pos] == ',' {
+			d.pos++
+			d.skipSpace()
+		}
+		key, err := d.string(true)
+		if err != nil {
+			return nil, err
+		}
+		d.skipSpace()
+		d.pos++ // :
+		child, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member{key, child})
+		d.skipSpace()
+	}
+
+	// 稳定排序后相同的键保持文档中的顺序，Put使后面的值覆盖前面的值
+	sort.SliceStable(members, func(i, j int) bool { return members[i].key < members[j].key })
+	obj := types.NewJSONObject()
+	for _, m := range members {
+		obj.Put(m.key, m.value)
+	}
+	return obj, nil
+}
+
+// array 解析数组
+func (d *internDecoder) array() (types.JSONValue, error) {
+	d.pos++ // [
+	values := make([]types.JSONValue, 0)
+	for {
+		d.skipSpace()
+		if d.data[d.pos] == ']' {
+			d.pos++
+			break
+		}
+		if d.data[d.pos] == ',' {
+			d.pos++
+		}
+		child, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, child)
+	}
+	return types.NewJSONArrayFromValuesUnsafe(values), nil
+}
+
+// string 解析字符串，key为true时驻留结果
+func (d *internDecoder) string(key bool) (string, error) {
+	start := d.pos
+	d.pos++ // "
+	escaped := false
+	for d.data[d.pos] != '"' {
+		if d.data[d.pos] == '\\' {
+			escaped = true
+			d.pos++
+		}
+		d.pos++
+	}
+	d.pos++
+	raw := d.data[start+1 : d.pos-1]
+
+	// 没有转义的合法UTF-8与解码结果相同，查找键池时不分配新的字符串
+	if !escaped && utf8.Valid(raw) {
+		if key && d.p.keys != nil {
+			if s, ok := d.p.keys[string(raw)]; ok {
+				return s, nil
+			}
+		}
+		s := string(raw)
+		if key {
+			return d.p.intern(s), nil
+		}
+		return s, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(d.data[start:d.pos], &s); err != nil {
+		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
+	}
+	if key {
+		return d.p.intern(s), nil
+	}
+	return s, nil
+}
+
+// number 解析数字，超出float64范围的数字与convertToJSONValue一样返回错误
+func (d *internDecoder) number() (types.JSONValue, error) {
+	start := d.pos
+	for d.pos < len(d.data) && isNumberByte(d.data[d.pos]) {
+		d.pos++
+	}
+	literal := d.data[start:d.pos]
+	f, err := strconv.ParseFloat(string(literal), 64)
+	if err != nil {
+		return convertToJSONValue(json.Number(literal))
+	}
+	return types.NewJSONNumber(f), nil
+}
+
+// isNumberByte 检查c是否可以出现在数字中
+func isNumberByte(c byte) bool {
+	return c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
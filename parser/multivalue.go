@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ParseMultiValue 将JSON字符串解析为JSONValue，所有对象解析为types.MultiValueObject，
+// 保留重复的键和键在文档中的顺序。
+func ParseMultiValue(jsonStr string) (types.JSONValue, error) {
+	if jsonStr == "" {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字符串为空")
+	}
+	return ParseMultiValueBytes([]byte(jsonStr))
+}
+
+// ParseMultiValueBytes 将JSON字节数组解析为JSONValue，所有对象解析为types.MultiValueObject。
+// 开头的BOM被忽略，UTF-16和UTF-32编码的输入先转换为UTF-8。
+func ParseMultiValueBytes(jsonBytes []byte) (types.JSONValue, error) {
+	if len(jsonBytes) == 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字节数组为空")
+	}
+	jsonBytes, err := ToUTF8(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	value, err := decodeMultiValue(dec, "$")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败: 存在多余内容")
+	}
+	return value, nil
+}
+
+// decodeMultiValue 解析下一个值，对象解析为MultiValueObject
+func decodeMultiValue(dec *json.Decoder, path string) (types.JSONValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithPath(path).WithCause(err)
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := types.NewMultiValueObject()
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithPath(path).WithCause(err)
+				}
+				key := tok.(string)
+				child, err := decodeMultiValue(dec, types.ChildPath(path, types.KeySegment(key)))
+				if err != nil {
+					return nil, err
+				}
+				obj.Add(key, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithPath(path).WithCause(err)
+			}
+			return obj, nil
+		case '[':
+			values := make([]types.JSONValue, 0)
+			for dec.More() {
+				child, err := decodeMultiValue(dec, types.ChildPath(path, types.IndexSegment(len(values))))
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithPath(path).WithCause(err)
+			}
+			return types.NewJSONArrayFromValuesUnsafe(values), nil
+		}
+		return nil, jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("意外的分隔符 %q", t.String())).WithPath(path)
+	case string:
+		return types.NewJSONString(t), nil
+	case float64:
+		return types.NewJSONNumber(t), nil
+	case bool:
+		return types.NewJSONBool(t), nil
+	case nil:
+		return types.NewJSONNull(), nil
+	default:
+		return nil, jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("未知的令牌 %v", t)).WithPath(path)
+	}
+}
@@ -3,10 +3,8 @@ package parser
 
 import (
 	"encoding/json"
-	"strconv"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
-	"github.com/UserLeeZJ/gojson/fast"
 	"github.com/UserLeeZJ/gojson/types"
 )
 
@@ -16,28 +14,27 @@ func ParseToValue(jsonStr string) (types.JSONValue, error) {
 		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字符串为空")
 	}
 
-	var raw interface{}
-	err := fast.Unmarshal([]byte(jsonStr), &raw)
-	if err != nil {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
-	}
-
-	return convertToJSONValue(raw), nil
+	return ParseBytesToValue([]byte(jsonStr))
 }
 
 // ParseBytesToValue 将JSON字节数组解析为JSONValue。
+// 开头的BOM被忽略，UTF-16和UTF-32编码的输入先转换为UTF-8。
 func ParseBytesToValue(jsonBytes []byte) (types.JSONValue, error) {
 	if len(jsonBytes) == 0 {
 		return nil, jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字节数组为空")
 	}
+	jsonBytes, err := ToUTF8(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
 
 	var raw interface{}
-	err := fast.Unmarshal(jsonBytes, &raw)
+	err = unmarshalBytes(jsonBytes, &raw)
 	if err != nil {
 		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
 	}
 
-	return convertToJSONValue(raw), nil
+	return convertToJSONValue(raw)
 }
 
 // Parse 将JSON字符串解析为Go对象。
@@ -46,21 +43,20 @@ func Parse(jsonStr string, v interface{}) error {
 		return jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字符串为空")
 	}
 
-	err := fast.Unmarshal([]byte(jsonStr), v)
-	if err != nil {
-		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
-	}
-
-	return nil
+	return ParseBytes([]byte(jsonStr), v)
 }
 
-// ParseBytes 将JSON字节数组解析为Go对象。
+// ParseBytes 将JSON字节数组解析为Go对象，对编码的处理与ParseBytesToValue相同。
 func ParseBytes(jsonBytes []byte, v interface{}) error {
 	if len(jsonBytes) == 0 {
 		return jsonerrors.NewJSONError(jsonerrors.ErrEmptyInput, "输入的JSON字节数组为空")
 	}
+	jsonBytes, err := ToUTF8(jsonBytes)
+	if err != nil {
+		return err
+	}
 
-	err := fast.Unmarshal(jsonBytes, v)
+	err = unmarshalBytes(jsonBytes, v)
 	if err != nil {
 		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
 	}
@@ -74,8 +70,11 @@ func Stringify(v interface{}) (string, error) {
 		return "null", nil
 	}
 
-	jsonBytes, err := fast.Marshal(v)
+	jsonBytes, err := marshalValue(v)
 	if err != nil {
+		if uerr := jsonerrors.UnsupportedTypeError(v, err); uerr != nil {
+			return "", uerr
+		}
 		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "序列化JSON失败").WithCause(err)
 	}
 
@@ -88,14 +87,33 @@ func StringifyBytes(v interface{}) ([]byte, error) {
 		return []byte("null"), nil
 	}
 
-	jsonBytes, err := fast.Marshal(v)
+	jsonBytes, err := marshalValue(v)
 	if err != nil {
+		if uerr := jsonerrors.UnsupportedTypeError(v, err); uerr != nil {
+			return nil, uerr
+		}
 		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "序列化JSON失败").WithCause(err)
 	}
 
 	return jsonBytes, nil
 }
 
+// marshalValue 序列化Go对象。JSONObject和JSONArray直接调用MarshalJSON，
+// 大型文档因此使用流式编码，也避免encoding/json再次检查和复制MarshalJSON的结果
+func marshalValue(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case *types.JSONObject:
+		if val != nil {
+			return val.MarshalJSON()
+		}
+	case *types.JSONArray:
+		if val != nil {
+			return val.MarshalJSON()
+		}
+	}
+	return marshalBytes(v)
+}
+
 // StringifyIndent 将Go对象转换为格式化的JSON字符串。
 func StringifyIndent(v interface{}, prefix, indent string) (string, error) {
 	if v == nil {
@@ -105,60 +123,21 @@ func StringifyIndent(v interface{}, prefix, indent string) (string, error) {
 	// 由于fast.Marshal不支持缩进，这里仍使用标准库
 	jsonBytes, err := json.MarshalIndent(v, prefix, indent)
 	if err != nil {
+		if uerr := jsonerrors.UnsupportedTypeError(v, err); uerr != nil {
+			return "", uerr
+		}
 		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "序列化JSON失败").WithCause(err)
 	}
 
 	return string(jsonBytes), nil
 }
 
-// convertToJSONValue 将Go原生类型转换为JSONValue。
-func convertToJSONValue(v interface{}) types.JSONValue {
-	if v == nil {
-		return types.NewJSONNull()
-	}
-
-	switch val := v.(type) {
-	case bool:
-		return types.NewJSONBool(val)
-	case float64:
-		return types.NewJSONNumber(val)
-	case json.Number:
-		// 尝试转换为float64
-		f, err := val.Float64()
-		if err == nil {
-			return types.NewJSONNumber(f)
-		}
-		// 如果转换失败，尝试转换为字符串
-		s := val.String()
-		num, _ := strconv.ParseFloat(s, 64)
-		return types.NewJSONNumber(num)
-	case string:
-		return types.NewJSONString(val)
-	case []interface{}:
-		arr := types.NewJSONArray()
-		for _, item := range val {
-			arr.Add(convertToJSONValue(item))
-		}
-		return arr
-	case map[string]interface{}:
-		obj := types.NewJSONObject()
-		for k, v := range val {
-			obj.Put(k, convertToJSONValue(v))
-		}
-		return obj
-	default:
-		// 尝试将其他类型转换为JSON
-		data, err := json.Marshal(val)
-		if err != nil {
-			return types.NewJSONNull()
-		}
-
-		var raw interface{}
-		err = json.Unmarshal(data, &raw)
-		if err != nil {
-			return types.NewJSONNull()
-		}
-
-		return convertToJSONValue(raw)
+// convertToJSONValue 将解码得到的Go原生类型转换为JSONValue。
+// fast.Unmarshal把数字解码为json.Number，这里与encoding/json一样转换为float64
+func convertToJSONValue(v interface{}) (types.JSONValue, error) {
+	value, err := types.FromInterfaceOpts(v, types.FromInterfaceOptions{Float64Numbers: true})
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "转换JSON值失败").WithCause(err)
 	}
+	return value, nil
 }
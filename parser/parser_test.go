@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
 )
 
 func TestParseToValue(t *testing.T) {
@@ -263,6 +267,25 @@ func TestStringify(t *testing.T) {
 	}
 }
 
+func TestStringifyUnsupportedType(t *testing.T) {
+	value := map[string]interface{}{
+		"ok":   []interface{}{1, 2},
+		"task": struct{ Done chan struct{} }{},
+	}
+	check := func(name string, err error) {
+		var jsonErr *jsonerrors.JSONError
+		if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrUnsupportedType || jsonErr.Path != "$.task.Done" {
+			t.Errorf("%s() error = %v, want %s (path: $.task.Done)", name, err, jsonerrors.ErrUnsupportedType)
+		}
+	}
+	_, err := Stringify(value)
+	check("Stringify", err)
+	_, err = StringifyBytes(value)
+	check("StringifyBytes", err)
+	_, err = StringifyIndent(value, "", "  ")
+	check("StringifyIndent", err)
+}
+
 func TestStringifyIndent(t *testing.T) {
 	obj := map[string]interface{}{
 		"name": "John",
@@ -335,10 +358,37 @@ func TestConvertToJSONValue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := convertToJSONValue(tt.value)
+			got, err := convertToJSONValue(tt.value)
+			if err != nil {
+				t.Fatalf("convertToJSONValue() error = %v", err)
+			}
 			if got.Type() != tt.want {
 				t.Errorf("convertToJSONValue() got type = %v, want %v", got.Type(), tt.want)
 			}
 		})
 	}
 }
+
+func TestParseMultiValue(t *testing.T) {
+	input := `{"a":1,"a":{"x":[1,{"y":2,"y":3}],"x":null},"b":"s"}`
+	value, err := ParseMultiValue(input)
+	if err != nil {
+		t.Fatalf("ParseMultiValue() error = %v", err)
+	}
+	if got := value.String(); got != input {
+		t.Errorf("String() = %s, want %s", got, input)
+	}
+	obj, ok := value.(*types.MultiValueObject)
+	if !ok || obj.Count("a") != 2 {
+		t.Fatalf("ParseMultiValue() = %T", value)
+	}
+	if got := obj.ToObject().String(); got != `{"a":{"x":null},"b":"s"}` {
+		t.Errorf("ToObject() = %s", got)
+	}
+
+	for _, bad := range []string{"", `{"a":}`, `{"a":1} 2`, `[1,`} {
+		if _, err := ParseMultiValue(bad); err == nil {
+			t.Errorf("ParseMultiValue(%q) should fail", bad)
+		}
+	}
+}
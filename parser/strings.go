@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// StringMode 决定解析时如何处理字符串中不符合RFC 8259的内容：
+// 未转义的控制字符、无效的转义、不成对的UTF-16代理项以及无效的UTF-8字节（包括超长编码）。
+type StringMode int
+
+const (
+	// StringsDefault 与encoding/json相同：控制字符和无效的转义是语法错误，
+	// 不成对的代理项和无效的UTF-8字节被静默替换为U+FFFD。
+	StringsDefault StringMode = iota
+	// StringsStrict 把所有这些问题都视为错误。
+	StringsStrict
+	// StringsRepair 修复所有这些问题，所做的修复可以通过Parser.Repairs获取。
+	StringsRepair
+)
+
+// RepairKind 表示修复的问题类型。
+type RepairKind int
+
+const (
+	// RepairControlChar 表示未转义的控制字符，修复时替换为转义序列。
+	RepairControlChar RepairKind = iota
+	// RepairInvalidEscape 表示无效的转义序列，修复时转义其中的反斜杠。
+	RepairInvalidEscape
+	// RepairSurrogate 表示不成对的UTF-16代理项转义，修复时替换为\ufffd。
+	RepairSurrogate
+	// RepairInvalidUTF8 表示无效的UTF-8字节，修复时替换为U+FFFD。
+	RepairInvalidUTF8
+)
+
+// String 返回问题类型的描述。
+func (k RepairKind) String() string {
+	switch k {
+	case RepairControlChar:
+		return "未转义的控制字符"
+	case RepairInvalidEscape:
+		return "无效的转义"
+	case RepairSurrogate:
+		return "不成对的代理项"
+	default:
+		return "无效的UTF-8字节"
+	}
+}
+
+// Repair 描述对字符串所做的一处修复。
+type Repair struct {
+	Kind     RepairKind // 问题类型
+	Offset   int        // 问题在输入中的字节偏移，输入经过编码转换时相对于转换后的UTF-8数据
+	Original string     // 被替换的原始内容
+}
+
+// String 返回修复的描述。
+func (r Repair) String() string {
+	return fmt.Sprintf("偏移 %d: %s %q", r.Offset, r.Kind, r.Original)
+}
+
+// stringChecker 按StringMode检查输入中的字符串。
+type stringChecker struct {
+	data    []byte
+	mode    StringMode
+	out     []byte // 修复后的输入，第一次修复时才分配
+	last    int    // data中尚未复制到out的起点
+	repairs []Repair
+}
+
+// checkStrings 按mode检查输入中的所有字符串，返回修复后的输入和所做的修复。
+// 字符串之外的语法错误留给解析器报告。
+func checkStrings(data []byte, mode StringMode) ([]byte, []Repair, error) {
+	if mode == StringsDefault {
+		return data, nil, nil
+	}
+
+	c := &stringChecker{data: data, mode: mode}
+	inString := false
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case !inString:
+			inString = b == '"'
+			i++
+		case b == '"':
+			inString = false
+			i++
+		case b == '\\':
+			n, err := c.checkEscape(i)
+			if err != nil {
+				return nil, nil, err
+			}
+			i += n
+		case b < 0x20:
+			if err := c.fix(RepairControlChar, i, i+1, controlEscape(b)); err != nil {
+				return nil, nil, err
+			}
+			i++
+		case b < utf8.RuneSelf:
+			i++
+		default:
+			// DecodeRune对超长编码和编码后的代理项同样返回(RuneError, 1)
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				if err := c.fix(RepairInvalidUTF8, i, i+1, "\ufffd"); err != nil {
+					return nil, nil, err
+				}
+			}
+			i += size
+		}
+	}
+
+	if c.out == nil {
+		return data, nil, nil
+	}
+	return append(c.out, data[c.last:]...), c.repairs, nil
+}
+
+// checkEscape 检查从i开始的转义序列，返回检查过的字节数。
+func (c *stringChecker) checkEscape(i int) (int, error) {
+	data := c.data
+	if i+1 >= len(data) {
+		return 1, nil
+	}
+	switch data[i+1] {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		return 2, nil
+	case 'u':
+	default:
+		// 只转义反斜杠，后面的字符按普通字符继续检查
+		return 1, c.replace(RepairInvalidEscape, i, i+1, invalidEscape(data, i), `\\`)
+	}
+
+	code, ok := hexCode(data, i+2)
+	if !ok {
+		return 1, c.replace(RepairInvalidEscape, i, i+1, invalidEscape(data, i), `\\`)
+	}
+	switch {
+	case code >= 0xd800 && code < 0xdc00:
+		if i+8 <= len(data) && data[i+6] == '\\' && data[i+7] == 'u' {
+			if low, ok := hexCode(data, i+8); ok && low >= 0xdc00 && low < 0xe000 {
+				return 12, nil
+			}
+		}
+		return 6, c.fix(RepairSurrogate, i, i+6, `\ufffd`)
+	case code >= 0xdc00 && code < 0xe000:
+		return 6, c.fix(RepairSurrogate, i, i+6, `\ufffd`)
+	default:
+		return 6, nil
+	}
+}
+
+// fix 在严格模式下返回错误，在修复模式下把data[start:end]替换为replacement。
+func (c *stringChecker) fix(kind RepairKind, start, end int, replacement string) error {
+	return c.replace(kind, start, end, string(c.data[start:end]), replacement)
+}
+
+// replace 与fix相同，但错误和修复记录中的原始内容使用original，
+// 用于被替换的字节只是问题的一部分的情况，例如无效转义中的反斜杠。
+func (c *stringChecker) replace(kind RepairKind, start, end int, original, replacement string) error {
+	if c.mode == StringsStrict {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON,
+			fmt.Sprintf("字符串中有%s %q (偏移 %d)", kind, original, start))
+	}
+
+	if c.out == nil {
+		c.out = make([]byte, 0, len(c.data)+16)
+	}
+	c.out = append(c.out, c.data[c.last:start]...)
+	c.out = append(c.out, replacement...)
+	c.last = end
+	c.repairs = append(c.repairs, Repair{Kind: kind, Offset: start, Original: original})
+	return nil
+}
+
+// invalidEscape 返回从i开始的无效转义序列：反斜杠和后面的一个字符，
+// \u之后还包括其后最多4个十六进制数字。
+func invalidEscape(data []byte, i int) string {
+	if data[i+1] != 'u' {
+		_, size := utf8.DecodeRune(data[i+1:])
+		return string(data[i : i+1+size])
+	}
+	end := i + 2
+	for end < len(data) && end < i+6 && isHexDigit(data[end]) {
+		end++
+	}
+	return string(data[i:end])
+}
+
+// isHexDigit 判断b是否为十六进制数字。
+func isHexDigit(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+// hexCode 解析从i开始的4个十六进制数字。
+func hexCode(data []byte, i int) (rune, bool) {
+	if i+4 > len(data) {
+		return 0, false
+	}
+	var code rune
+	for _, b := range data[i : i+4] {
+		switch {
+		case b >= '0' && b <= '9':
+			code = code<<4 | rune(b-'0')
+		case b >= 'a' && b <= 'f':
+			code = code<<4 | rune(b-'a'+10)
+		case b >= 'A' && b <= 'F':
+			code = code<<4 | rune(b-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return code, true
+}
+
+// controlEscape 返回控制字符的转义序列。
+func controlEscape(b byte) string {
+	switch b {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	default:
+		return fmt.Sprintf(`\u%04x`, b)
+	}
+}
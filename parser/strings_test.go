@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestStringModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // 修复模式下name字段的值
+		kinds []RepairKind
+	}{
+		{"控制字符", "{\"name\":\"a\tb\x01\"}", "a\tb\x01", []RepairKind{RepairControlChar, RepairControlChar}},
+		{"无效的转义", `{"name":"C:\path"}`, `C:\path`, []RepairKind{RepairInvalidEscape}},
+		{"不完整的\\u转义", `{"name":"\u12"}`, `\u12`, []RepairKind{RepairInvalidEscape}},
+		{"不成对的高代理项", `{"name":"a\ud83db"}`, "a\ufffdb", []RepairKind{RepairSurrogate}},
+		{"不成对的低代理项", `{"name":"\ude00\ud83d\ude00"}`, "\ufffd😀", []RepairKind{RepairSurrogate}},
+		{"超长编码", "{\"name\":\"a\xc0\xafb\"}", "a\ufffd\ufffdb", []RepairKind{RepairInvalidUTF8, RepairInvalidUTF8}},
+		{"合法的字符串", `{"name":"é\n\u00e9\ud83d\ude00\/"}`, "é\né😀/", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(ParserOptions{Strings: StringsRepair})
+			value, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("修复模式 Parse() 错误 = %v", err)
+			}
+			obj, _ := value.AsObject()
+			if got, _ := obj.GetString("name"); got != tt.want {
+				t.Errorf("修复模式 name = %q, 期望 %q", got, tt.want)
+			}
+			var kinds []RepairKind
+			for _, r := range p.Repairs() {
+				kinds = append(kinds, r.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("Repairs() = %v, 期望 %v", p.Repairs(), tt.kinds)
+			}
+
+			_, err = NewParser(ParserOptions{Strings: StringsStrict}).Parse(tt.input)
+			if tt.kinds == nil {
+				if err != nil {
+					t.Errorf("严格模式 Parse() 错误 = %v", err)
+				}
+				return
+			}
+			var jsonErr *jsonerrors.JSONError
+			if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrInvalidJSON {
+				t.Errorf("严格模式 Parse() 错误 = %v, 期望 %s", err, jsonerrors.ErrInvalidJSON)
+			}
+		})
+	}
+}
+
+func TestRepairOffsets(t *testing.T) {
+	p := NewParser(ParserOptions{Strings: StringsRepair})
+	if _, err := p.Parse("[\"ok\", \"a\nb\"]"); err != nil {
+		t.Fatal(err)
+	}
+	want := []Repair{{Kind: RepairControlChar, Offset: 9, Original: "\n"}}
+	if !reflect.DeepEqual(p.Repairs(), want) {
+		t.Errorf("Repairs() = %v, 期望 %v", p.Repairs(), want)
+	}
+
+	if _, err := p.Parse(`["ok"]`); err != nil || p.Repairs() != nil {
+		t.Errorf("Repairs() 没有在下一次解析时清空: %v, %v", p.Repairs(), err)
+	}
+}
+
+func TestRepairInvalidEscapeOriginal(t *testing.T) {
+	// Original记录完整的转义序列，而不只是被转义的反斜杠
+	tests := []struct {
+		input  string
+		offset int
+		want   string
+	}{
+		{`"C:\path"`, 3, `\p`},
+		{`"\q"`, 1, `\q`},
+		{`"\u00"`, 1, `\u00`},
+		{`"\u00zz"`, 1, `\u00`},
+		{`"\uzz"`, 1, `\u`},
+		{`"\é"`, 1, `\é`},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(ParserOptions{Strings: StringsRepair})
+		if _, err := p.Parse(tt.input); err != nil {
+			t.Fatalf("Parse(%s) 错误 = %v", tt.input, err)
+		}
+		want := []Repair{{Kind: RepairInvalidEscape, Offset: tt.offset, Original: tt.want}}
+		if !reflect.DeepEqual(p.Repairs(), want) {
+			t.Errorf("Parse(%s) Repairs() = %v, 期望 %v", tt.input, p.Repairs(), want)
+		}
+
+		_, err := NewParser(ParserOptions{Strings: StringsStrict}).Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%q", tt.want)) {
+			t.Errorf("严格模式 Parse(%s) 错误 = %v, 应包含 %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestStringsDefault(t *testing.T) {
+	// 默认模式与encoding/json相同，无效的代理项被静默替换
+	value, err := NewParser(ParserOptions{}).Parse(`"\ud83d"`)
+	if err != nil || value.String() != "\"\ufffd\"" {
+		t.Errorf("Parse() = %v, %v", value, err)
+	}
+	if _, err := NewParser(ParserOptions{}).Parse("\"a\tb\""); err == nil {
+		t.Errorf("Parse() 默认模式应该拒绝控制字符")
+	}
+}
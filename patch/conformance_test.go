@@ -0,0 +1,119 @@
+package patch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// conformanceTest 是一个补丁用例，格式与json-patch-tests相同。
+// 给出expected时期望结果与它相等，给出error时期望出错，两者都没有时只要求补丁应用成功。
+// error是用例作者对错误的描述，各实现的错误信息不同，只检查是否出错
+type conformanceTest struct {
+	Comment  string          `json:"comment"`
+	Doc      json.RawMessage `json:"doc"`
+	Patch    json.RawMessage `json:"patch"`
+	Expected json.RawMessage `json:"expected"`
+	Error    string          `json:"error"`
+	Disabled bool            `json:"disabled"`
+}
+
+// knownDeviations 按用例的comment记录有意与RFC 6902或json-patch-tests不一致的用例及原因，
+// 这些用例失败时只输出日志
+var knownDeviations = map[string]string{
+	// JSON对象中重复的键按最后一个处理，与parser的行为一致
+	"duplicate ops": "重复的op成员使用最后一个",
+}
+
+// conformanceFiles 是一致性测试的用例文件：tests.json和spec_tests.json收录自json-patch-tests
+// （https://github.com/json-patch/json-patch-tests，Apache-2.0许可，见testdata/LICENSE），
+// gojson_tests.json是本项目编写的用例
+var conformanceFiles = []string{"tests.json", "spec_tests.json", "gojson_tests.json"}
+
+// patchImplementation 是参与一致性测试的一个补丁实现
+type patchImplementation struct {
+	name  string
+	apply func(doc types.JSONValue, patchJSON string) (types.JSONValue, error)
+}
+
+var patchImplementations = []patchImplementation{
+	{"ApplyPatch", ApplyPatch},
+	{"Patch", func(doc types.JSONValue, patchJSON string) (types.JSONValue, error) {
+		p, err := ParsePatch(patchJSON)
+		if err != nil {
+			return nil, err
+		}
+		return p.ApplyTo(doc)
+	}},
+}
+
+// TestConformance 使用testdata中的用例检查每个补丁实现是否符合RFC 6902
+func TestConformance(t *testing.T) {
+	for _, name := range conformanceFiles {
+		file := filepath.Join("testdata", name)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("读取用例失败: %v", err)
+		}
+		var tests []conformanceTest
+		if err := json.Unmarshal(data, &tests); err != nil {
+			t.Fatalf("解析%s失败: %v", file, err)
+		}
+
+		for _, impl := range patchImplementations {
+			t.Run(impl.name+"/"+name, func(t *testing.T) {
+				for _, tt := range tests {
+					if tt.Disabled {
+						continue
+					}
+					t.Run(tt.Comment, func(t *testing.T) {
+						failure := runConformanceTest(impl, tt)
+						if failure == "" {
+							return
+						}
+						if reason, ok := knownDeviations[tt.Comment]; ok {
+							t.Logf("已知的偏差（%s）: %s", reason, failure)
+							return
+						}
+						t.Error(failure)
+					})
+				}
+			})
+		}
+	}
+}
+
+// runConformanceTest 用补丁实现执行一个用例，返回失败的原因，通过时返回空字符串
+func runConformanceTest(impl patchImplementation, tt conformanceTest) string {
+	doc, err := parser.ParseBytesToValue(tt.Doc)
+	if err != nil {
+		return "解析文档失败: " + err.Error()
+	}
+
+	result, err := impl.apply(doc, string(tt.Patch))
+	if tt.Error != "" {
+		if err == nil {
+			return "期望出错（" + tt.Error + "），实际结果 " + result.String()
+		}
+		return ""
+	}
+	if err != nil {
+		return impl.name + " 错误 = " + err.Error()
+	}
+	if tt.Expected == nil {
+		return ""
+	}
+
+	expected, err := parser.ParseBytesToValue(tt.Expected)
+	if err != nil {
+		return "解析期望结果失败: " + err.Error()
+	}
+	if !compareValues(result, expected, DefaultApplyPatchOptions()) {
+		return impl.name + " = " + result.String() + "，期望 " + string(tt.Expected)
+	}
+	return ""
+}
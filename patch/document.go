@@ -0,0 +1,214 @@
+package patch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Patch 是按顺序保存操作的JSON Patch文档，用于在代码中构建补丁而不需要拼接JSON字符串。
+// 构建方法返回Patch本身，可以链式调用：
+//
+//	p := patch.NewPatch().
+//		Test("/version", types.NewJSONNumber(3)).
+//		Replace("/name", types.NewJSONString("new")).
+//		Remove("/legacy")
+//	result, err := p.ApplyTo(doc)
+//
+// 路径是JSON Pointer，可以用PathBuilder构建。序列化值失败等构建错误会被记住，
+// 由Validate、MarshalJSON和ApplyTo返回
+type Patch struct {
+	ops []PatchOperation
+	err error
+}
+
+// NewPatch 创建一个空的补丁
+func NewPatch() *Patch {
+	return &Patch{ops: make([]PatchOperation, 0)}
+}
+
+// ParsePatch 解析并校验JSON Patch字符串
+func ParsePatch(patchJSON string) (*Patch, error) {
+	p := NewPatch()
+	if err := p.UnmarshalJSON([]byte(patchJSON)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Append 追加一个操作，不会立即校验，需要时调用Validate
+func (p *Patch) Append(op PatchOperation) *Patch {
+	p.ops = append(p.ops, op)
+	return p
+}
+
+// Add 追加add操作
+func (p *Patch) Add(path string, value types.JSONValue) *Patch {
+	return p.appendValue("add", path, value)
+}
+
+// Remove 追加remove操作
+func (p *Patch) Remove(path string) *Patch {
+	return p.Append(PatchOperation{Op: "remove", Path: path})
+}
+
+// Replace 追加replace操作
+func (p *Patch) Replace(path string, value types.JSONValue) *Patch {
+	return p.appendValue("replace", path, value)
+}
+
+// Move 追加把from处的值移动到path的move操作
+func (p *Patch) Move(from, path string) *Patch {
+	return p.Append(PatchOperation{Op: "move", From: from, Path: path})
+}
+
+// Copy 追加把from处的值复制到path的copy操作
+func (p *Patch) Copy(from, path string) *Patch {
+	return p.Append(PatchOperation{Op: "copy", From: from, Path: path})
+}
+
+// Test 追加test操作，path处的值与value不相等时整个补丁失败
+func (p *Patch) Test(path string, value types.JSONValue) *Patch {
+	return p.appendValue("test", path, value)
+}
+
+// appendValue 追加带有值的操作，nil表示JSON null
+func (p *Patch) appendValue(op, path string, value types.JSONValue) *Patch {
+	if value == nil {
+		value = types.NewJSONNull()
+	}
+	data, err := value.MarshalJSON()
+	if err != nil {
+		if p.err == nil {
+			p.err = jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("序列化第%d个操作的值失败", len(p.ops)+1)).WithCause(err)
+		}
+		return p
+	}
+	return p.Append(PatchOperation{Op: op, Path: path, Value: json.RawMessage(data)})
+}
+
+// Len 返回操作的数量
+func (p *Patch) Len() int {
+	return len(p.ops)
+}
+
+// Operations 返回操作的副本
+func (p *Patch) Operations() []PatchOperation {
+	return append([]PatchOperation(nil), p.ops...)
+}
+
+// Validate 检查补丁的结构：操作类型已知，路径是合法的JSON Pointer，
+// add、replace和test有合法的值，move不会把值移动到它自己的子节点中。
+// 不检查路径在文档中是否存在
+func (p *Patch) Validate() error {
+	if p.err != nil {
+		return p.err
+	}
+	for i, op := range p.ops {
+		if err := validateOperation(op); err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作无效", i+1)).WithCause(err)
+		}
+	}
+	return nil
+}
+
+// validateOperation 检查单个操作的结构
+func validateOperation(op PatchOperation) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, op.Op+"操作缺少value")
+		}
+		if !json.Valid(op.Value) {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, op.Op+"操作的value不是合法的JSON")
+		}
+	case "remove":
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" && len(from) < len(path) && isPathPrefix(from, path) {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "不能移动到源路径的子路径")
+		}
+	default:
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("未知的操作类型: %s", op.Op))
+	}
+	return nil
+}
+
+// MarshalJSON 把补丁序列化为JSON Patch数组
+func (p *Patch) MarshalJSON() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	ops := make([]interface{}, len(p.ops))
+	for i, op := range p.ops {
+		ops[i] = op
+		if op.Op == "move" || op.Op == "copy" {
+			// PatchOperation的from带有omitempty，指向根节点的空from需要显式写出
+			ops[i] = struct {
+				Op   string `json:"op"`
+				Path string `json:"path"`
+				From string `json:"from"`
+			}{op.Op, op.Path, op.From}
+		}
+	}
+	return json.Marshal(ops)
+}
+
+// UnmarshalJSON 解析并校验JSON Patch数组，替换补丁中原有的操作
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	ops, err := decodeOperations(data)
+	if err != nil {
+		return err
+	}
+
+	parsed := &Patch{ops: ops}
+	if parsed.ops == nil {
+		parsed.ops = make([]PatchOperation, 0)
+	}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	*p = *parsed
+	return nil
+}
+
+// String 返回补丁的JSON表示，补丁无效时返回空数组
+func (p *Patch) String() string {
+	data, err := p.MarshalJSON()
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// ApplyTo 把补丁应用到value的副本上并返回结果，value保持不变
+func (p *Patch) ApplyTo(value types.JSONValue) (types.JSONValue, error) {
+	return p.ApplyToWithOptions(value, nil)
+}
+
+// ApplyToWithOptions 使用指定的选项应用补丁，options为nil时使用默认选项
+func (p *Patch) ApplyToWithOptions(value types.JSONValue, options *ApplyPatchOptions) (types.JSONValue, error) {
+	return p.ApplyToContext(context.Background(), value, options)
+}
+
+// ApplyToContext 与ApplyToWithOptions相同，ctx被取消时停止应用补丁
+func (p *Patch) ApplyToContext(ctx context.Context, value types.JSONValue, options *ApplyPatchOptions) (types.JSONValue, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = DefaultApplyPatchOptions()
+	}
+	return applyOperations(ctx, value, p.ops, options)
+}
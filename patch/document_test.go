@@ -0,0 +1,130 @@
+package patch
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestPatchBuilder(t *testing.T) {
+	p := NewPatch().
+		Test("/version", types.NewJSONNumber(3)).
+		Replace("/name", types.NewJSONString("new")).
+		Add(NewPathBuilder().Append("tags").End().String(), types.NewJSONString("b")).
+		Copy("/name", "/alias").
+		Move("/legacy", "/archived").
+		Remove("/tmp").
+		Add("/meta", nil)
+
+	want := `[{"op":"test","path":"/version","value":3},` +
+		`{"op":"replace","path":"/name","value":"new"},` +
+		`{"op":"add","path":"/tags/-","value":"b"},` +
+		`{"op":"copy","path":"/alias","from":"/name"},` +
+		`{"op":"move","path":"/archived","from":"/legacy"},` +
+		`{"op":"remove","path":"/tmp"},` +
+		`{"op":"add","path":"/meta","value":null}]`
+	if p.String() != want {
+		t.Errorf("String() = %s, want %s", p.String(), want)
+	}
+	if p.Len() != 7 {
+		t.Errorf("Len() = %d, want 7", p.Len())
+	}
+
+	doc := parser.MustParse(`{"version": 3, "name": "old", "tags": ["a"], "legacy": 1, "tmp": true}`)
+	got, err := p.ApplyTo(doc)
+	if err != nil {
+		t.Fatalf("ApplyTo() error = %v", err)
+	}
+	if want := `{"alias":"new","archived":1,"meta":null,"name":"new","tags":["a","b"],"version":3}`; got.String() != want {
+		t.Errorf("ApplyTo() = %v, want %v", got, want)
+	}
+	if want := `{"legacy":1,"name":"old","tags":["a"],"tmp":true,"version":3}`; doc.String() != want {
+		t.Errorf("ApplyTo() modified the input: %v", doc)
+	}
+
+	// test失败时整个补丁失败
+	_, err = NewPatch().Test("/version", types.NewJSONNumber(4)).Remove("/name").ApplyTo(doc)
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrTestFailed {
+		t.Errorf("ApplyTo() error = %v, want a test failure", err)
+	}
+}
+
+func TestPatchJSONRoundTrip(t *testing.T) {
+	patchJSON := `[{"op":"add","path":"/a~1b","value":{"x":[1,2]}},{"op":"copy","path":"/c","from":""}]`
+	p, err := ParsePatch(patchJSON)
+	if err != nil {
+		t.Fatalf("ParsePatch() error = %v", err)
+	}
+	if ops := p.Operations(); len(ops) != 2 || ops[0].Path != "/a~1b" || ops[1].From != "" {
+		t.Errorf("Operations() = %+v", ops)
+	}
+
+	data, err := json.Marshal(struct {
+		Patch *Patch `json:"patch"`
+	}{p})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		Patch *Patch `json:"patch"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Patch.String() != patchJSON {
+		t.Errorf("round trip = %s, want %s", decoded.Patch.String(), patchJSON)
+	}
+
+	if NewPatch().String() != "[]" {
+		t.Errorf("empty patch = %s", NewPatch().String())
+	}
+}
+
+func TestPatchValidate(t *testing.T) {
+	invalid := map[string]*Patch{
+		"unknown op":        NewPatch().Append(PatchOperation{Op: "merge", Path: "/a"}),
+		"bad pointer":       NewPatch().Remove("a"),
+		"bad from":          NewPatch().Copy("a", "/b"),
+		"missing value":     NewPatch().Append(PatchOperation{Op: "add", Path: "/a"}),
+		"invalid value":     NewPatch().Append(PatchOperation{Op: "test", Path: "/a", Value: json.RawMessage(`{`)}),
+		"move into child":   NewPatch().Move("/a", "/a/b"),
+		"unencodable value": NewPatch().Add("/a", types.NewJSONNumber(math.NaN())),
+	}
+	for name, p := range invalid {
+		err := p.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate() should fail", name)
+			continue
+		}
+		if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrInvalidPatch {
+			t.Errorf("%s: Validate() error = %v, want INVALID_PATCH", name, err)
+		}
+		if _, err := p.MarshalJSON(); err == nil {
+			t.Errorf("%s: MarshalJSON() should fail", name)
+		}
+		if _, err := p.ApplyTo(parser.MustParse(`{"a": {}}`)); err == nil {
+			t.Errorf("%s: ApplyTo() should fail", name)
+		}
+	}
+
+	for _, patchJSON := range []string{
+		`{"op":"remove","path":"/a"}`,
+		`[{"op":"remove"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"replace","path":"/a"}]`,
+	} {
+		if _, err := ParsePatch(patchJSON); err == nil {
+			t.Errorf("ParsePatch(%s) should fail", patchJSON)
+		}
+	}
+
+	// 解析失败时保留原有的操作
+	p := NewPatch().Remove("/a")
+	if err := p.UnmarshalJSON([]byte(`[{"op":"bogus","path":"/b"}]`)); err == nil || p.Len() != 1 {
+		t.Errorf("UnmarshalJSON() = %v, Len() = %d", err, p.Len())
+	}
+}
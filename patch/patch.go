@@ -2,14 +2,17 @@
 package patch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
-	"github.com/UserLeeZJ/gojson/jsonpath"
 	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
 )
 
 // PatchOperation 表示JSON Patch操作
@@ -31,269 +34,385 @@ func (e *PatchError) Error() string {
 	return fmt.Sprintf("JSON Patch 错误: %s, 操作: %+v", e.Message, e.Operation)
 }
 
+// ApplyPatchOptions 表示应用补丁的选项，包括test操作的比较方式和进度回调
+type ApplyPatchOptions struct {
+	NumberEpsilon float64 // 比较数字时允许的最大误差，0表示严格相等
+	IgnoreCase    bool    // 比较字符串时忽略大小写
+	MissingAsNull bool    // 不存在的值等同于null，包括test的目标路径和对象中缺少的键
+
+	// OnProgress 不为nil时在应用操作之后调用，返回错误时停止应用补丁，
+	// ApplyPatch返回的错误包装了该错误
+	OnProgress func(progress Progress) error
+	// ProgressInterval 是两次调用OnProgress之间应用的操作数，0和1表示每个操作之后都调用，
+	// 最后一个操作之后总是调用
+	ProgressInterval int
+}
+
+// Progress 是应用补丁的进度
+type Progress struct {
+	Applied int    // 已经应用的操作数
+	Total   int    // 补丁中的操作总数
+	Op      string // 最近应用的操作
+	Path    string // 最近应用的操作的目标路径
+}
+
+// DefaultApplyPatchOptions 返回默认的应用补丁选项，即RFC 6902规定的严格比较
+func DefaultApplyPatchOptions() *ApplyPatchOptions {
+	return &ApplyPatchOptions{
+		NumberEpsilon: 0,
+		IgnoreCase:    false,
+		MissingAsNull: false,
+	}
+}
+
 // ApplyPatch 将JSON Patch应用到JSON值
+// 原始值不会被修改，补丁应用在它的深度副本上
 func ApplyPatch(value types.JSONValue, patchJSON string) (types.JSONValue, error) {
+	return ApplyPatchWithOptions(value, patchJSON, nil)
+}
+
+// ApplyPatchWithOptions 使用指定的选项将JSON Patch应用到JSON值，options为nil时使用默认选项
+func ApplyPatchWithOptions(value types.JSONValue, patchJSON string, options *ApplyPatchOptions) (types.JSONValue, error) {
+	return ApplyPatchContext(context.Background(), value, patchJSON, options)
+}
+
+// ApplyPatchContext 与ApplyPatchWithOptions相同，但在应用每个操作之前检查ctx，
+// ctx被取消时停止应用补丁，返回的错误包装了ctx.Err()
+func ApplyPatchContext(ctx context.Context, value types.JSONValue, patchJSON string, options *ApplyPatchOptions) (types.JSONValue, error) {
+	if options == nil {
+		options = DefaultApplyPatchOptions()
+	}
+
 	// 解析补丁
-	var patchOps []PatchOperation
-	err := json.Unmarshal([]byte(patchJSON), &patchOps)
+	patchOps, err := decodeOperations([]byte(patchJSON))
 	if err != nil {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的JSON Patch").WithCause(err)
+		return nil, err
 	}
 
+	return applyOperations(ctx, value, patchOps, options)
+}
+
+// applyOperations 在value的副本上按顺序应用操作，value保持不变
+func applyOperations(ctx context.Context, value types.JSONValue, ops []PatchOperation, options *ApplyPatchOptions) (types.JSONValue, error) {
 	// 克隆原始值
-	var result types.JSONValue
-	switch value.Type() {
-	case "object":
-		obj, _ := value.AsObject()
-		result = obj.Clone()
-	case "array":
-		arr, _ := value.AsArray()
-		// 创建新数组并复制元素
-		newArr := types.NewJSONArray()
-		for i := 0; i < arr.Size(); i++ {
-			newArr.Add(arr.Get(i))
-		}
-		result = newArr
-	default:
-		// 对于基本类型，直接使用原值
-		result = value
+	result, err := utils.DeepCopyChecked(value)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := options.ProgressInterval
+	if interval < 1 {
+		interval = 1
 	}
 
 	// 应用每个操作
-	for _, op := range patchOps {
-		var err error
-		result, err = applyOperation(result, op)
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed,
+				fmt.Sprintf("应用补丁在第%d个操作之前被取消", i+1)).WithCause(err)
+		}
+		result, err = applyOperation(result, op, options)
 		if err != nil {
 			return nil, err
 		}
+		if applied := i + 1; options.OnProgress != nil && (applied%interval == 0 || applied == len(ops)) {
+			progress := Progress{Applied: applied, Total: len(ops), Op: op.Op, Path: op.Path}
+			if err := options.OnProgress(progress); err != nil {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed,
+					fmt.Sprintf("应用补丁在第%d个操作之后被停止", applied)).WithCause(err)
+			}
+		}
 	}
 
 	return result, nil
 }
 
+// decodeOperations 解析JSON Patch数组并检查每个操作是否包含必需的成员。
+// 缺少的path或from在PatchOperation中与表示根节点的空字符串无法区分，因此先把操作解析为成员
+func decodeOperations(data []byte) ([]PatchOperation, error) {
+	var members []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的JSON Patch").WithCause(err)
+	}
+	ops := make([]PatchOperation, len(members))
+	for i, member := range members {
+		op := &ops[i]
+		hasOp, err := memberString(member, "op", i, &op.Op)
+		if err != nil {
+			return nil, err
+		}
+		if !hasOp {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作缺少op", i+1))
+		}
+		hasPath, err := memberString(member, "path", i, &op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !hasPath {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作缺少path", i+1))
+		}
+		hasFrom, err := memberString(member, "from", i, &op.From)
+		if err != nil {
+			return nil, err
+		}
+		if !hasFrom && (op.Op == "move" || op.Op == "copy") {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作缺少from", i+1))
+		}
+		op.Value = member["value"]
+	}
+	return ops, nil
+}
+
+// memberString 把操作中名为name的成员解析为字符串，成员不存在时返回false，不是字符串时返回错误
+func memberString(member map[string]json.RawMessage, name string, i int, dst *string) (bool, error) {
+	raw, ok := member[name]
+	if !ok {
+		return false, nil
+	}
+	// null可以解析为空字符串，需要单独排除
+	if len(raw) == 0 || raw[0] != '"' {
+		return true, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作的%s不是字符串", i+1, name))
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return true, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("第%d个操作的%s不是字符串", i+1, name)).WithCause(err)
+	}
+	return true, nil
+}
+
 // 应用单个补丁操作
-func applyOperation(value types.JSONValue, op PatchOperation) (types.JSONValue, error) {
-	// 标准化路径
-	path := normalizePath(op.Path)
-	from := normalizePath(op.From)
+func applyOperation(value types.JSONValue, op PatchOperation, options *ApplyPatchOptions) (types.JSONValue, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
 
 	switch op.Op {
 	case "add":
-		return applyAddOperation(value, path, op.Value)
+		newValue, err := parseOperationValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return applyAddOperation(value, path, newValue)
 	case "remove":
 		return applyRemoveOperation(value, path)
 	case "replace":
-		return applyReplaceOperation(value, path, op.Value)
+		newValue, err := parseOperationValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return applyReplaceOperation(value, path, newValue)
 	case "move":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
 		return applyMoveOperation(value, from, path)
 	case "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
 		return applyCopyOperation(value, from, path)
 	case "test":
-		return applyTestOperation(value, path, op.Value)
+		testValue, err := parseOperationValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return applyTestOperation(value, path, testValue, options)
 	default:
 		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, fmt.Sprintf("未知的操作类型: %s", op.Op))
 	}
 }
 
-// 标准化JSON Patch路径
-func normalizePath(path string) string {
-	if path == "" {
-		return ""
-	}
-	// 将JSON Patch路径转换为JSON Path格式
-	// 例如: /foo/bar -> $.foo.bar
-	parts := strings.Split(path, "/")
-	if len(parts) > 0 && parts[0] == "" {
-		parts = parts[1:]
-	}
-
-	result := "$"
-	for _, part := range parts {
-		// 处理转义字符
-		part = strings.ReplaceAll(part, "~1", "/")
-		part = strings.ReplaceAll(part, "~0", "~")
-
-		// 检查是否为数组索引
-		if isArrayIndex(part) {
-			result += "[" + part + "]"
-		} else {
-			result += "." + part
-		}
+// 解析操作中的值
+func parseOperationValue(rawValue json.RawMessage) (types.JSONValue, error) {
+	newValue, err := parser.ParseBytesToValue(rawValue)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的值").WithCause(err)
 	}
-	return result
+	return newValue, nil
 }
 
-// 检查字符串是否为数组索引
-func isArrayIndex(s string) bool {
-	// 检查是否为非负整数
-	if s == "0" {
-		return true
+// 将JSON Pointer解析为未转义的路径段，根节点对应空切片
+func parsePointer(pointer string) ([]string, error) {
+	p, err := types.ParsePointer(pointer)
+	if err != nil {
+		return nil, err
 	}
-	if len(s) > 0 && s[0] != '0' {
-		for _, c := range s {
-			if c < '0' || c > '9' {
-				return false
+	return p.Segments(), nil
+}
+
+// 将路径段格式化为JSON Pointer，用于错误信息
+func formatPointer(path []string) string {
+	p := make(types.Path, len(path))
+	for i, segment := range path {
+		p[i] = types.KeySegment(segment)
+	}
+	return p.Pointer()
+}
+
+// 获取路径段指向的值
+func resolvePath(value types.JSONValue, path []string) (types.JSONValue, error) {
+	current := value
+	for i, segment := range path {
+		switch {
+		case current.IsObject():
+			obj, _ := current.AsObject()
+			if !obj.Has(segment) {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(formatPointer(path[:i+1]))
 			}
+			current = obj.Get(segment)
+		case current.IsArray():
+			arr, _ := current.AsArray()
+			index, err := parseArrayIndex(segment, arr.Size())
+			if err != nil {
+				return nil, err
+			}
+			if index >= arr.Size() {
+				return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(formatPointer(path[:i+1]))
+			}
+			current = arr.Get(index)
+		default:
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(formatPointer(path[:i+1]))
 		}
-		return true
 	}
-	return false
+	return current, nil
 }
 
-// 应用add操作
-func applyAddOperation(value types.JSONValue, path string, rawValue json.RawMessage) (types.JSONValue, error) {
-	// 解析要添加的值
-	newValue, err := parser.ParseBytesToValue(rawValue)
+// 获取路径的父节点和最后一个段
+func resolveParent(value types.JSONValue, path []string) (types.JSONValue, string, error) {
+	parentPath := path[:len(path)-1]
+	parent, err := resolvePath(value, parentPath)
 	if err != nil {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的值").WithCause(err)
+		return nil, "", jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "父路径不存在").WithPath(formatPointer(parentPath)).WithCause(err)
+	}
+	if !parent.IsObject() && !parent.IsArray() {
+		return nil, "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "父路径必须是对象或数组").WithPath(formatPointer(parentPath))
 	}
+	return parent, path[len(path)-1], nil
+}
 
+// 应用add操作
+func applyAddOperation(value types.JSONValue, path []string, newValue types.JSONValue) (types.JSONValue, error) {
 	// 处理根路径
-	if path == "$" {
+	if len(path) == 0 {
 		return newValue, nil
 	}
 
-	// 获取父路径和最后一个段
-	parentPath, lastSegment := splitPath(path)
-
-	// 获取父对象或数组
-	results, err := jsonpath.QueryJSONPath(value, parentPath)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "父路径不存在").WithPath(parentPath)
+	parent, lastSegment, err := resolveParent(value, path)
+	if err != nil {
+		return nil, err
 	}
-	parent := results[0]
 
 	// 根据父对象类型处理
 	if parent.IsObject() {
 		obj, _ := parent.AsObject()
 		obj.Put(lastSegment, newValue)
-	} else if parent.IsArray() {
-		arr, _ := parent.AsArray()
-		index, err := parseArrayIndex(lastSegment, arr.Size())
-		if err != nil {
-			return nil, err
-		}
-		// 在数组中插入元素
-		if index == arr.Size() {
-			arr.Add(newValue)
-		} else {
-			// 创建新数组并重新排列元素
-			newArr := types.NewJSONArray()
-			for i := 0; i < arr.Size()+1; i++ {
-				if i < index {
-					newArr.Add(arr.Get(i))
-				} else if i == index {
-					newArr.Add(newValue)
-				} else {
-					newArr.Add(arr.Get(i - 1))
-				}
-			}
-			// 替换原数组
-			*arr = *newArr
-		}
-	} else {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "父路径必须是对象或数组").WithPath(parentPath)
+		return value, nil
 	}
 
+	arr, _ := parent.AsArray()
+	index, err := parseArrayIndex(lastSegment, arr.Size())
+	if err != nil {
+		return nil, err
+	}
+	if index > arr.Size() {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(formatPointer(path))
+	}
+
+	// 在数组中插入元素
+	elements := arr.Values()
+	inserted := make([]types.JSONValue, 0, len(elements)+1)
+	inserted = append(inserted, elements[:index]...)
+	inserted = append(inserted, newValue)
+	inserted = append(inserted, elements[index:]...)
+	*arr = *types.NewJSONArrayFromValuesUnsafe(inserted)
+
 	return value, nil
 }
 
 // 应用remove操作
-func applyRemoveOperation(value types.JSONValue, path string) (types.JSONValue, error) {
+func applyRemoveOperation(value types.JSONValue, path []string) (types.JSONValue, error) {
 	// 处理根路径
-	if path == "$" {
+	if len(path) == 0 {
 		return types.NewJSONNull(), nil
 	}
 
-	// 获取父路径和最后一个段
-	parentPath, lastSegment := splitPath(path)
-
-	// 获取父对象或数组
-	results, err := jsonpath.QueryJSONPath(value, parentPath)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "父路径不存在").WithPath(parentPath)
+	parent, lastSegment, err := resolveParent(value, path)
+	if err != nil {
+		return nil, err
 	}
-	parent := results[0]
 
 	// 根据父对象类型处理
 	if parent.IsObject() {
 		obj, _ := parent.AsObject()
 		if !obj.Has(lastSegment) {
-			return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(path)
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(formatPointer(path))
 		}
 		obj.Remove(lastSegment)
-	} else if parent.IsArray() {
-		arr, _ := parent.AsArray()
-		index, err := parseArrayIndex(lastSegment, arr.Size())
-		if err != nil {
-			return nil, err
-		}
-		if index >= arr.Size() {
-			return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(path)
-		}
-		arr.Remove(index)
-	} else {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "父路径必须是对象或数组").WithPath(parentPath)
+		return value, nil
 	}
 
+	arr, _ := parent.AsArray()
+	index, err := parseArrayIndex(lastSegment, arr.Size())
+	if err != nil {
+		return nil, err
+	}
+	if index >= arr.Size() {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(formatPointer(path))
+	}
+	arr.Remove(index)
+
 	return value, nil
 }
 
 // 应用replace操作
-func applyReplaceOperation(value types.JSONValue, path string, rawValue json.RawMessage) (types.JSONValue, error) {
-	// 解析要替换的值
-	newValue, err := parser.ParseBytesToValue(rawValue)
-	if err != nil {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的值").WithCause(err)
-	}
-
+func applyReplaceOperation(value types.JSONValue, path []string, newValue types.JSONValue) (types.JSONValue, error) {
 	// 处理根路径
-	if path == "$" {
+	if len(path) == 0 {
 		return newValue, nil
 	}
 
-	// 获取父路径和最后一个段
-	parentPath, lastSegment := splitPath(path)
-
-	// 获取父对象或数组
-	results, err := jsonpath.QueryJSONPath(value, parentPath)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "父路径不存在").WithPath(parentPath)
+	parent, lastSegment, err := resolveParent(value, path)
+	if err != nil {
+		return nil, err
 	}
-	parent := results[0]
 
 	// 根据父对象类型处理
 	if parent.IsObject() {
 		obj, _ := parent.AsObject()
 		if !obj.Has(lastSegment) {
-			return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(path)
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(formatPointer(path))
 		}
 		obj.Put(lastSegment, newValue)
-	} else if parent.IsArray() {
-		arr, _ := parent.AsArray()
-		index, err := parseArrayIndex(lastSegment, arr.Size())
-		if err != nil {
-			return nil, err
-		}
-		if index >= arr.Size() {
-			return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(path)
-		}
-		arr.Set(index, newValue)
-	} else {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "父路径必须是对象或数组").WithPath(parentPath)
+		return value, nil
+	}
+
+	arr, _ := parent.AsArray()
+	index, err := parseArrayIndex(lastSegment, arr.Size())
+	if err != nil {
+		return nil, err
 	}
+	if index >= arr.Size() {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrIndexOutOfRange, "索引超出范围").WithPath(formatPointer(path))
+	}
+	arr.Set(index, newValue)
 
 	return value, nil
 }
 
 // 应用move操作
-func applyMoveOperation(value types.JSONValue, from, path string) (types.JSONValue, error) {
+func applyMoveOperation(value types.JSONValue, from, path []string) (types.JSONValue, error) {
+	// 不能将值移动到它自己的子节点中
+	if len(from) < len(path) && isPathPrefix(from, path) {
+		return nil, jsonerrors.ErrPatchFailedWithDetails("move", formatPointer(path), "不能移动到源路径的子路径")
+	}
+
 	// 获取源值
-	results, err := jsonpath.QueryJSONPath(value, from)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "源路径不存在").WithPath(from)
+	sourceValue, err := resolvePath(value, from)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "源路径不存在").WithPath(formatPointer(from)).WithCause(err)
 	}
-	sourceValue := results[0]
 
 	// 先移除源值
 	value, err = applyRemoveOperation(value, from)
@@ -302,49 +421,59 @@ func applyMoveOperation(value types.JSONValue, from, path string) (types.JSONVal
 	}
 
 	// 将源值添加到目标路径
-	valueBytes, _ := json.Marshal(types.ValueToInterface(sourceValue))
-	return applyAddOperation(value, path, valueBytes)
+	return applyAddOperation(value, path, sourceValue)
 }
 
 // 应用copy操作
-func applyCopyOperation(value types.JSONValue, from, path string) (types.JSONValue, error) {
+func applyCopyOperation(value types.JSONValue, from, path []string) (types.JSONValue, error) {
 	// 获取源值
-	results, err := jsonpath.QueryJSONPath(value, from)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "源路径不存在").WithPath(from)
+	sourceValue, err := resolvePath(value, from)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "源路径不存在").WithPath(formatPointer(from)).WithCause(err)
 	}
-	sourceValue := results[0]
 
-	// 将源值添加到目标路径
-	valueBytes, _ := json.Marshal(types.ValueToInterface(sourceValue))
-	return applyAddOperation(value, path, valueBytes)
-}
-
-// 应用test操作
-func applyTestOperation(value types.JSONValue, path string, rawValue json.RawMessage) (types.JSONValue, error) {
-	// 解析要测试的值
-	testValue, err := parser.ParseBytesToValue(rawValue)
+	// 将源值的副本添加到目标路径
+	copied, err := utils.DeepCopyChecked(sourceValue)
 	if err != nil {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidPatch, "无效的值").WithCause(err)
+		return nil, err
 	}
+	return applyAddOperation(value, path, copied)
+}
 
+// 应用test操作
+func applyTestOperation(value types.JSONValue, path []string, testValue types.JSONValue, options *ApplyPatchOptions) (types.JSONValue, error) {
 	// 获取目标值
-	results, err := jsonpath.QueryJSONPath(value, path)
-	if err != nil || len(results) == 0 {
-		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(path)
+	targetValue, err := resolvePath(value, path)
+	if err != nil {
+		if options.MissingAsNull && testValue.IsNull() {
+			return value, nil
+		}
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "路径不存在").WithPath(formatPointer(path)).WithCause(err)
 	}
-	targetValue := results[0]
 
 	// 比较值
-	if !compareValues(targetValue, testValue) {
-		return nil, jsonerrors.ErrTestFailedWithDetails(path, testValue, targetValue)
+	if !compareValues(targetValue, testValue, options) {
+		return nil, jsonerrors.ErrTestFailedWithDetails(formatPointer(path), testValue, targetValue)
 	}
 
 	return value, nil
 }
 
-// 比较两个JSON值是否相等
-func compareValues(a, b types.JSONValue) bool {
+// 检查prefix是否为path的前缀
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// 按选项比较两个JSON值是否相等
+func compareValues(a, b types.JSONValue, options *ApplyPatchOptions) bool {
 	if a.Type() != b.Type() {
 		return false
 	}
@@ -357,12 +486,27 @@ func compareValues(a, b types.JSONValue) bool {
 		bBool, _ := b.AsBoolean()
 		return aBool == bBool
 	case "number":
+		if types.IsExactNumber(a) || types.IsExactNumber(b) {
+			if options.NumberEpsilon == 0 {
+				return types.NumbersEqual(a, b)
+			}
+			// 精确计算差值，避免超出float64精度的差异在转换时丢失
+			diff, err := types.SubNumbers(a, b)
+			if err != nil {
+				return false
+			}
+			d, _ := diff.AsNumber()
+			return math.Abs(d) <= options.NumberEpsilon
+		}
 		aNum, _ := a.AsNumber()
 		bNum, _ := b.AsNumber()
-		return aNum == bNum
+		return aNum == bNum || math.Abs(aNum-bNum) <= options.NumberEpsilon
 	case "string":
 		aStr, _ := a.AsString()
 		bStr, _ := b.AsString()
+		if options.IgnoreCase {
+			return strings.EqualFold(aStr, bStr)
+		}
 		return aStr == bStr
 	case "array":
 		aArr, _ := a.AsArray()
@@ -371,7 +515,7 @@ func compareValues(a, b types.JSONValue) bool {
 			return false
 		}
 		for i := 0; i < aArr.Size(); i++ {
-			if !compareValues(aArr.Get(i), bArr.Get(i)) {
+			if !compareValues(aArr.Get(i), bArr.Get(i), options) {
 				return false
 			}
 		}
@@ -379,11 +523,14 @@ func compareValues(a, b types.JSONValue) bool {
 	case "object":
 		aObj, _ := a.AsObject()
 		bObj, _ := b.AsObject()
+		if options.MissingAsNull {
+			return compareObjectsMissingAsNull(aObj, bObj, options)
+		}
 		if aObj.Size() != bObj.Size() {
 			return false
 		}
 		for _, key := range aObj.Keys() {
-			if !bObj.Has(key) || !compareValues(aObj.Get(key), bObj.Get(key)) {
+			if !bObj.Has(key) || !compareValues(aObj.Get(key), bObj.Get(key), options) {
 				return false
 			}
 		}
@@ -393,38 +540,57 @@ func compareValues(a, b types.JSONValue) bool {
 	}
 }
 
-// 分割路径为父路径和最后一个段
-func splitPath(path string) (string, string) {
-	// 处理数组索引
-	if strings.HasSuffix(path, "]") {
-		lastOpenBracket := strings.LastIndex(path, "[")
-		if lastOpenBracket != -1 {
-			return path[:lastOpenBracket], path[lastOpenBracket+1 : len(path)-1]
+// 比较两个对象，一侧缺少的键等同于null
+func compareObjectsMissingAsNull(a, b *types.JSONObject, options *ApplyPatchOptions) bool {
+	for _, key := range a.Keys() {
+		if !b.Has(key) {
+			if !a.Get(key).IsNull() {
+				return false
+			}
+		} else if !compareValues(a.Get(key), b.Get(key), options) {
+			return false
 		}
 	}
-
-	// 处理对象属性
-	lastDot := strings.LastIndex(path, ".")
-	if lastDot != -1 {
-		return path[:lastDot], path[lastDot+1:]
+	for _, key := range b.Keys() {
+		if !a.Has(key) && !b.Get(key).IsNull() {
+			return false
+		}
 	}
-
-	return "$", path[1:]
+	return true
 }
 
 // 解析数组索引
+// 索引必须是没有前导零的非负整数，"-"表示数组末尾
 func parseArrayIndex(indexStr string, arraySize int) (int, error) {
 	// 处理"-"表示数组末尾
 	if indexStr == "-" {
 		return arraySize, nil
 	}
 
-	// 解析数字索引
-	var index int
-	_, err := fmt.Sscanf(indexStr, "%d", &index)
-	if err != nil || index < 0 {
+	if !isArrayIndex(indexStr) {
 		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidIndex, "无效的数组索引")
 	}
+	index, err := strconv.Atoi(indexStr)
+	if err != nil {
+		return 0, jsonerrors.NewJSONError(jsonerrors.ErrInvalidIndex, "无效的数组索引").WithCause(err)
+	}
 
 	return index, nil
 }
+
+// 检查字符串是否为数组索引
+func isArrayIndex(s string) bool {
+	// 检查是否为非负整数
+	if s == "0" {
+		return true
+	}
+	if len(s) > 0 && s[0] != '0' {
+		for _, c := range s {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -1,9 +1,13 @@
 package patch
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/UserLeeZJ/gojson/parser"
 	"github.com/UserLeeZJ/gojson/types"
 )
 
@@ -172,3 +176,166 @@ func TestApplyPatch(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyPatchWithOptions(t *testing.T) {
+	doc := types.NewJSONObject()
+	doc.PutNumber("price", 0.30000000000000004)
+	doc.PutString("status", "Active")
+	doc.PutNull("note")
+	item := types.NewJSONObject()
+	item.PutNumber("qty", 1)
+	doc.PutObject("item", item)
+	doc.Put("amount", types.NewJSONDecimalFromInt64(30, 2))
+
+	tests := []struct {
+		name      string
+		patchJSON string
+		options   *ApplyPatchOptions
+		wantErr   bool
+	}{
+		{"严格比较数字", `[{"op":"test","path":"/price","value":0.3}]`, nil, true},
+		{"数字误差", `[{"op":"test","path":"/price","value":0.3}]`, &ApplyPatchOptions{NumberEpsilon: 1e-9}, false},
+		{"1和1.0相等", `[{"op":"test","path":"/item/qty","value":1.0}]`, nil, false},
+		{"精确小数", `[{"op":"test","path":"/amount","value":0.3}]`, nil, false},
+		{"精确小数不等于近似值", `[{"op":"test","path":"/amount","value":0.30000000000000004}]`, nil, true},
+		{"精确小数的误差", `[{"op":"test","path":"/amount","value":0.30000000000000004}]`, &ApplyPatchOptions{NumberEpsilon: 1e-9}, false},
+		{"严格比较字符串", `[{"op":"test","path":"/status","value":"active"}]`, nil, true},
+		{"忽略大小写", `[{"op":"test","path":"/status","value":"ACTIVE"}]`, &ApplyPatchOptions{IgnoreCase: true}, false},
+		{"严格处理不存在的路径", `[{"op":"test","path":"/missing","value":null}]`, nil, true},
+		{"不存在的路径等同于null", `[{"op":"test","path":"/missing","value":null}]`, &ApplyPatchOptions{MissingAsNull: true}, false},
+		{"不存在的路径不等于其他值", `[{"op":"test","path":"/missing","value":0}]`, &ApplyPatchOptions{MissingAsNull: true}, true},
+		{"对象中缺少的键等同于null", `[{"op":"test","path":"/item","value":{"qty":1,"unit":null}}]`, &ApplyPatchOptions{MissingAsNull: true}, false},
+		{"严格比较对象中缺少的键", `[{"op":"test","path":"/item","value":{"qty":1,"unit":null}}]`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyPatchWithOptions(doc, tt.patchJSON, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyPatchWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 补丁应用在副本上，副本中的精确小数保留原来的表示
+	result, err := ApplyPatch(doc, `[{"op":"remove","path":"/note"}]`)
+	if err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	if amount := result.(*types.JSONObject).Get("amount"); amount.String() != "0.30" {
+		t.Errorf("amount = %v, want 0.30", amount)
+	}
+}
+
+func TestApplyPatchProgress(t *testing.T) {
+	p := NewPatch()
+	for i := 0; i < 10; i++ {
+		p.Add(fmt.Sprintf("/k%d", i), types.NewJSONNumber(float64(i)))
+	}
+	doc := types.NewJSONObject()
+
+	var events []Progress
+	options := &ApplyPatchOptions{
+		ProgressInterval: 4,
+		OnProgress: func(progress Progress) error {
+			events = append(events, progress)
+			return nil
+		},
+	}
+	if _, err := p.ApplyToWithOptions(doc, options); err != nil {
+		t.Fatalf("ApplyToWithOptions() error = %v", err)
+	}
+	// 每4个操作报告一次，最后一个操作总是报告
+	if len(events) != 3 || events[0].Applied != 4 || events[1].Applied != 8 || events[2].Applied != 10 {
+		t.Fatalf("events = %+v", events)
+	}
+	if last := events[2]; last.Total != 10 || last.Op != "add" || last.Path != "/k9" {
+		t.Errorf("last event = %+v", last)
+	}
+
+	// 回调返回错误时停止，原文档不受影响
+	stop := stderrors.New("stop")
+	options = &ApplyPatchOptions{OnProgress: func(progress Progress) error {
+		if progress.Applied == 3 {
+			return stop
+		}
+		return nil
+	}}
+	if _, err := ApplyPatchWithOptions(doc, p.String(), options); !stderrors.Is(err, stop) {
+		t.Errorf("ApplyPatchWithOptions() error = %v, want stop", err)
+	}
+	if doc.Size() != 0 {
+		t.Errorf("原文档被修改: %v", doc)
+	}
+
+	// ctx被取消时停止
+	ctx, cancel := context.WithCancel(context.Background())
+	options = &ApplyPatchOptions{OnProgress: func(progress Progress) error {
+		if progress.Applied == 5 {
+			cancel()
+		}
+		return nil
+	}}
+	if _, err := ApplyPatchContext(ctx, doc, p.String(), options); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("ApplyPatchContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := p.ApplyToContext(ctx, doc, nil); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("ApplyToContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestApplyPatchRoots(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		patchJSON string
+		want      string // 期望的结果，为空表示期望出错
+	}{
+		{"向根数组追加", `[1,2]`, `[{"op":"add","path":"/-","value":3}]`, `[1,2,3]`},
+		{"向空的根数组追加", `[]`, `[{"op":"add","path":"/-","value":{"a":1}}]`, `[{"a":1}]`},
+		{"连续向根数组追加", `[]`, `[{"op":"add","path":"/-","value":1},{"op":"add","path":"/-","value":2}]`, `[1,2]`},
+		{"在根数组开头插入", `[1,2]`, `[{"op":"add","path":"/0","value":0}]`, `[0,1,2]`},
+		{"根数组插入越界", `[1,2]`, `[{"op":"add","path":"/3","value":0}]`, ""},
+		{"移除根数组的元素", `[1,2,3]`, `[{"op":"remove","path":"/1"}]`, `[1,3]`},
+		{"移除根数组越界", `[1]`, `[{"op":"remove","path":"/1"}]`, ""},
+		{"替换根数组的元素", `[1,2]`, `[{"op":"replace","path":"/0","value":"a"}]`, `["a",2]`},
+		{"修改根数组中的对象", `[{"a":1}]`, `[{"op":"add","path":"/0/b","value":2}]`, `[{"a":1,"b":2}]`},
+		{"在根数组内移动", `[1,2,3]`, `[{"op":"move","from":"/0","path":"/-"}]`, `[2,3,1]`},
+		{"在根数组内复制", `[1,2]`, `[{"op":"copy","from":"/1","path":"/0"}]`, `[2,1,2]`},
+		{"根数组使用对象键", `[1]`, `[{"op":"add","path":"/a","value":1}]`, ""},
+		{"替换标量根", `1`, `[{"op":"replace","path":"","value":"x"}]`, `"x"`},
+		{"在标量根添加会替换它", `true`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"在标量根之后继续修改", `null`, `[{"op":"add","path":"","value":[]},{"op":"add","path":"/-","value":1}]`, `[1]`},
+		{"测试标量根", `"a"`, `[{"op":"test","path":"","value":"a"}]`, `"a"`},
+		{"向标量根追加", `1`, `[{"op":"add","path":"/-","value":2}]`, ""},
+		{"移除标量根的子路径", `"a"`, `[{"op":"remove","path":"/0"}]`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.ParseToValue(tt.doc)
+			if err != nil {
+				t.Fatalf("解析文档失败: %v", err)
+			}
+
+			result, err := ApplyPatch(doc, tt.patchJSON)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("期望出错，实际结果 %s", result.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch() 错误 = %v", err)
+			}
+			want, _ := parser.ParseToValue(tt.want)
+			if !compareValues(result, want, DefaultApplyPatchOptions()) {
+				t.Errorf("ApplyPatch() = %s, 期望 %s", result.String(), tt.want)
+			}
+			original, _ := parser.ParseToValue(tt.doc)
+			if !compareValues(doc, original, DefaultApplyPatchOptions()) {
+				t.Errorf("原始文档被修改: %s", doc.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package patch
+
+import (
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// EscapeSegment 按RFC 6901转义JSON Pointer的单个段
+// "~"转义为"~0"，"/"转义为"~1"
+func EscapeSegment(segment string) string {
+	if !strings.ContainsAny(segment, "~/") {
+		return segment
+	}
+	segment = strings.ReplaceAll(segment, "~", "~0")
+	return strings.ReplaceAll(segment, "/", "~1")
+}
+
+// UnescapeSegment 按RFC 6901还原JSON Pointer的单个段
+// 先还原"~1"再还原"~0"，保证"~01"被还原为"~1"
+func UnescapeSegment(segment string) string {
+	if !strings.Contains(segment, "~") {
+		return segment
+	}
+	segment = strings.ReplaceAll(segment, "~1", "/")
+	return strings.ReplaceAll(segment, "~0", "~")
+}
+
+// PathBuilder 用于构建JSON Patch使用的JSON Pointer路径
+// 段以未转义的形式保存，在String时统一转义
+type PathBuilder struct {
+	path types.Path
+}
+
+// NewPathBuilder 创建一个指向根节点的路径构建器
+func NewPathBuilder() *PathBuilder {
+	return &PathBuilder{
+		path: types.Path{},
+	}
+}
+
+// Append 追加一个对象键段
+func (b *PathBuilder) Append(key string) *PathBuilder {
+	b.path = append(b.path, types.KeySegment(key))
+	return b
+}
+
+// Index 追加一个数组索引段
+func (b *PathBuilder) Index(i int) *PathBuilder {
+	b.path = append(b.path, types.IndexSegment(i))
+	return b
+}
+
+// End 追加表示数组末尾的"-"段，用于add操作
+func (b *PathBuilder) End() *PathBuilder {
+	return b.Append("-")
+}
+
+// Path 返回已构建的结构化路径的副本
+func (b *PathBuilder) Path() types.Path {
+	return b.path.Join()
+}
+
+// Segments 返回未转义的路径段副本
+func (b *PathBuilder) Segments() []string {
+	return b.path.Segments()
+}
+
+// String 返回转义后的JSON Pointer，根节点为空字符串
+func (b *PathBuilder) String() string {
+	return b.path.Pointer()
+}
@@ -0,0 +1,45 @@
+package patch
+
+import (
+	"testing"
+)
+
+func TestEscapeSegment(t *testing.T) {
+	tests := []struct {
+		raw     string
+		escaped string
+	}{
+		{raw: "name", escaped: "name"},
+		{raw: "a/b", escaped: "a~1b"},
+		{raw: "m~n", escaped: "m~0n"},
+		{raw: "~/", escaped: "~0~1"},
+		{raw: "~1", escaped: "~01"},
+		{raw: "", escaped: ""},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeSegment(tt.raw); got != tt.escaped {
+			t.Errorf("EscapeSegment(%q) = %q, want %q", tt.raw, got, tt.escaped)
+		}
+		if got := UnescapeSegment(tt.escaped); got != tt.raw {
+			t.Errorf("UnescapeSegment(%q) = %q, want %q", tt.escaped, got, tt.raw)
+		}
+	}
+}
+
+func TestPathBuilder(t *testing.T) {
+	if got := NewPathBuilder().String(); got != "" {
+		t.Errorf("empty PathBuilder.String() = %q, want \"\"", got)
+	}
+
+	b := NewPathBuilder().Append("a/b").Index(0).Append("m~n").Append("").End()
+	want := "/a~1b/0/m~0n//-"
+	if got := b.String(); got != want {
+		t.Errorf("PathBuilder.String() = %q, want %q", got, want)
+	}
+
+	segments := b.Segments()
+	if len(segments) != 5 || segments[0] != "a/b" || segments[2] != "m~n" {
+		t.Errorf("PathBuilder.Segments() = %v", segments)
+	}
+}
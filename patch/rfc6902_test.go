@@ -0,0 +1,141 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+// TestRFC6902Examples 使用RFC 6902附录A中的示例检查补丁的应用结果
+func TestRFC6902Examples(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		patchJSON string
+		want      string // 期望的结果，为空表示期望出错
+	}{
+		{
+			name:      "A.1 添加对象成员",
+			doc:       `{"foo":"bar"}`,
+			patchJSON: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:      `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:      "A.2 添加数组元素",
+			doc:       `{"foo":["bar","baz"]}`,
+			patchJSON: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:      `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:      "A.3 移除对象成员",
+			doc:       `{"baz":"qux","foo":"bar"}`,
+			patchJSON: `[{"op":"remove","path":"/baz"}]`,
+			want:      `{"foo":"bar"}`,
+		},
+		{
+			name:      "A.4 移除数组元素",
+			doc:       `{"foo":["bar","qux","baz"]}`,
+			patchJSON: `[{"op":"remove","path":"/foo/1"}]`,
+			want:      `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:      "A.5 替换值",
+			doc:       `{"baz":"qux","foo":"bar"}`,
+			patchJSON: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:      `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:      "A.6 移动值",
+			doc:       `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patchJSON: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:      `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:      "A.7 移动数组元素",
+			doc:       `{"foo":["all","grass","cows","eat"]}`,
+			patchJSON: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:      `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name: "A.8 测试值成功",
+			doc:  `{"baz":"qux","foo":["a",2,"c"]}`,
+			patchJSON: `[
+				{"op":"test","path":"/baz","value":"qux"},
+				{"op":"test","path":"/foo/1","value":2}
+			]`,
+			want: `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:      "A.9 测试值失败",
+			doc:       `{"baz":"qux"}`,
+			patchJSON: `[{"op":"test","path":"/baz","value":"bar"}]`,
+		},
+		{
+			name:      "A.10 添加嵌套对象",
+			doc:       `{"foo":"bar"}`,
+			patchJSON: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:      `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:      "A.11 忽略无法识别的元素",
+			doc:       `{"foo":"bar"}`,
+			patchJSON: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:      `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:      "A.12 添加到不存在的目标",
+			doc:       `{"foo":"bar"}`,
+			patchJSON: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		},
+		{
+			name:      "A.13 无效的补丁文档",
+			doc:       `{"foo":"bar"}`,
+			patchJSON: `[{"op":"add","path":"/baz","value":"qux","op":"remove"}]`,
+		},
+		{
+			name:      "A.14 转义字符的顺序",
+			doc:       `{"/":9,"~1":10}`,
+			patchJSON: `[{"op":"test","path":"/~01","value":10}]`,
+			want:      `{"/":9,"~1":10}`,
+		},
+		{
+			name:      "A.15 比较字符串和数字",
+			doc:       `{"/":9,"~1":10}`,
+			patchJSON: `[{"op":"test","path":"/~01","value":"10"}]`,
+		},
+		{
+			name:      "A.16 添加数组值",
+			doc:       `{"foo":["bar"]}`,
+			patchJSON: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:      `{"foo":["bar",["abc","def"]]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.ParseToValue(tt.doc)
+			if err != nil {
+				t.Fatalf("解析文档失败: %v", err)
+			}
+
+			result, err := ApplyPatch(doc, tt.patchJSON)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("期望出错，实际结果 %s", result.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch() 错误 = %v", err)
+			}
+
+			want, err := parser.ParseToValue(tt.want)
+			if err != nil {
+				t.Fatalf("解析期望结果失败: %v", err)
+			}
+			if !compareValues(result, want, DefaultApplyPatchOptions()) {
+				t.Errorf("ApplyPatch() = %s, 期望 %s", result.String(), tt.want)
+			}
+		})
+	}
+}
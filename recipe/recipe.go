@@ -0,0 +1,397 @@
+// Package recipe 按JSON格式的菜谱依次转换JSON文档，适合对一批文档执行同样的处理。
+//
+// 菜谱是一个对象，steps数组中的每一步用op指定操作，按顺序执行，每一步的结果是下一步的输入：
+//
+//	{"steps": [
+//		{"op": "select", "path": "$.items[*]"},
+//		{"op": "rename", "keys": {"qty": "quantity"}},
+//		{"op": "patch", "patch": [{"op": "add", "path": "/0/first", "value": true}]},
+//		{"op": "sort", "by": "$.price", "order": "desc"},
+//		{"op": "project", "fields": {"id": "$.id", "name": "$.user.name"}}
+//	]}
+//
+// 示例：
+//
+//	p, err := recipe.ParsePipeline(data)
+//	result, err := p.Apply(doc)
+//
+//	p := recipe.NewPipeline().Select("$.items[*]").Sort("$.price", utils.Descending)
+package recipe
+
+import (
+	"fmt"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/patch"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+// Field 是投影结果中的一个字段
+type Field struct {
+	// Name 是结果中的属性名
+	Name string
+	// Path 是以被投影的值为根的JSON Path
+	Path string
+}
+
+// step 是流水线中的一步
+type step struct {
+	op    string
+	apply func(value types.JSONValue) (types.JSONValue, error)
+}
+
+// Pipeline 是按顺序执行的转换步骤，可以用ParsePipeline从菜谱创建，也可以用方法链构建。
+// 构建时的错误（例如无效的路径）被记录下来，由Err和Apply返回。
+// 转换不会修改输入的文档，Pipeline创建后可以在多个goroutine中同时使用
+type Pipeline struct {
+	steps []step
+	err   error
+}
+
+// NewPipeline 创建一个没有步骤的Pipeline，它原样返回输入
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Len 返回步骤的数量
+func (p *Pipeline) Len() int {
+	return len(p.steps)
+}
+
+// Err 返回构建时遇到的第一个错误
+func (p *Pipeline) Err() error {
+	return p.err
+}
+
+// add 追加一步，已经有错误时忽略
+func (p *Pipeline) add(op string, apply func(types.JSONValue) (types.JSONValue, error), err error) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+	if err != nil {
+		p.err = jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, fmt.Sprintf("第%d步%s无效", len(p.steps)+1, op)).WithCause(err)
+		return p
+	}
+	p.steps = append(p.steps, step{op: op, apply: apply})
+	return p
+}
+
+// Select 把值替换为path匹配的所有值组成的数组
+func (p *Pipeline) Select(path string) *Pipeline {
+	jp, err := jsonpath.ParseJSONPath(path)
+	return p.add("select", func(value types.JSONValue) (types.JSONValue, error) {
+		results, err := jp.Query(value)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewJSONArrayFromValuesUnsafe(results), nil
+	}, err)
+}
+
+// SelectFirst 把值替换为path匹配的第一个值，没有匹配的值时返回ErrPathNotFound错误
+func (p *Pipeline) SelectFirst(path string) *Pipeline {
+	jp, err := jsonpath.ParseJSONPath(path)
+	return p.add("select", func(value types.JSONValue) (types.JSONValue, error) {
+		results, err := jp.Query(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) == 0 {
+			return nil, jsonerrors.ErrPathNotFoundWithDetails(path)
+		}
+		return results[0], nil
+	}, err)
+}
+
+// Rename 按names重命名任意深度的属性，names的键是原来的属性名，值是新的属性名。
+// 属性保持原来的位置，重命名后与其他属性同名时后出现的值覆盖前面的
+func (p *Pipeline) Rename(names map[string]string) *Pipeline {
+	return p.add("rename", func(value types.JSONValue) (types.JSONValue, error) {
+		return renameKeys(value, names), nil
+	}, nil)
+}
+
+// Patch 应用JSON Patch，补丁中的路径以当前的值为根
+func (p *Pipeline) Patch(ops *patch.Patch) *Pipeline {
+	return p.add("patch", func(value types.JSONValue) (types.JSONValue, error) {
+		return ops.ApplyTo(value)
+	}, ops.Validate())
+}
+
+// Sort 按元素中JSON Path所选的值稳定排序数组，by为空时按元素本身排序。
+// 缺少排序键的元素排在最后，值不是数组时返回ErrInvalidType错误
+func (p *Pipeline) Sort(by string, order utils.SortOrder) *Pipeline {
+	if by == "" {
+		by = "$"
+	}
+	_, err := jsonpath.ParseJSONPath(by)
+	return p.add("sort", func(value types.JSONValue) (types.JSONValue, error) {
+		arr, err := value.AsArray()
+		if err != nil {
+			return nil, jsonerrors.ErrInvalidTypeWithDetails("array", value.Type())
+		}
+		return utils.SortArrayBy(arr, by, order)
+	}, err)
+}
+
+// Project 把对象替换为只包含fields的新对象，值是数组时投影其中的每个元素。
+// 字段的路径匹配一个值时取这个值，匹配多个值时取它们组成的数组，没有匹配的值时省略这个字段
+func (p *Pipeline) Project(fields ...Field) *Pipeline {
+	paths := make([]*jsonpath.JSONPath, len(fields))
+	var err error
+	for i, field := range fields {
+		if paths[i], err = jsonpath.ParseJSONPath(field.Path); err != nil {
+			break
+		}
+	}
+	return p.add("project", func(value types.JSONValue) (types.JSONValue, error) {
+		if value.IsObject() {
+			return project(value, fields, paths), nil
+		}
+		arr, err := value.AsArray()
+		if err != nil {
+			return nil, jsonerrors.ErrInvalidTypeWithDetails("object or array", value.Type())
+		}
+		elements := arr.Values()
+		result := make([]types.JSONValue, len(elements))
+		for i, element := range elements {
+			result[i] = project(element, fields, paths)
+		}
+		return types.NewJSONArrayFromValuesUnsafe(result), nil
+	}, err)
+}
+
+// Apply 依次执行所有步骤，返回最后一步的结果。
+// 某一步失败时返回的错误指出是第几步
+func (p *Pipeline) Apply(value types.JSONValue) (types.JSONValue, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	for i, s := range p.steps {
+		result, err := s.apply(value)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("第%d步%s失败", i+1, s.op)).WithCause(err)
+		}
+		value = result
+	}
+	return value, nil
+}
+
+// ApplyAll 对每个文档执行Apply，返回与输入顺序相同的结果。
+// 有文档失败时返回nil和第一个错误，错误指出是第几个文档
+func (p *Pipeline) ApplyAll(docs []types.JSONValue) ([]types.JSONValue, error) {
+	results := make([]types.JSONValue, len(docs))
+	for i, doc := range docs {
+		result, err := p.Apply(doc)
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, fmt.Sprintf("转换第%d个文档失败", i+1)).WithCause(err)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// renameKeys 返回重命名属性后的副本，没有对象的值原样返回
+func renameKeys(value types.JSONValue, names map[string]string) types.JSONValue {
+	switch {
+	case value.IsObject():
+		obj, _ := value.AsObject()
+		result := types.NewJSONObject()
+		obj.ForEach(func(key string, child types.JSONValue) {
+			if renamed, ok := names[key]; ok {
+				key = renamed
+			}
+			result.Put(key, renameKeys(child, names))
+		})
+		return result
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		elements := arr.Values()
+		result := make([]types.JSONValue, len(elements))
+		for i, element := range elements {
+			result[i] = renameKeys(element, names)
+		}
+		return types.NewJSONArrayFromValuesUnsafe(result)
+	default:
+		return value
+	}
+}
+
+// project 返回只包含fields的新对象
+func project(value types.JSONValue, fields []Field, paths []*jsonpath.JSONPath) *types.JSONObject {
+	result := types.NewJSONObject()
+	for i, field := range fields {
+		matches, err := paths[i].Query(value)
+		switch {
+		case err != nil || len(matches) == 0:
+		case len(matches) == 1:
+			result.Put(field.Name, matches[0])
+		default:
+			result.Put(field.Name, types.NewJSONArrayFromValuesUnsafe(matches))
+		}
+	}
+	return result
+}
+
+// ParsePipeline 解析JSON格式的菜谱，格式见包文档。
+// 菜谱的结构无效、操作未知或者步骤中有未知的成员时返回ErrInvalidRecipe错误
+func ParsePipeline(data []byte) (*Pipeline, error) {
+	value, err := parser.ParseBytesToValue(data)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "菜谱不是合法的JSON").WithCause(err)
+	}
+	root, err := value.AsObject()
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "菜谱必须是对象")
+	}
+	if err := checkMembers(root, "steps"); err != nil {
+		return nil, err
+	}
+	steps, err := root.GetArray("steps")
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "菜谱的steps必须是数组")
+	}
+
+	p := NewPipeline()
+	for i, value := range steps.Values() {
+		if err := p.parseStep(value); err != nil {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, fmt.Sprintf("第%d步无效", i+1)).WithCause(err)
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+	return p, nil
+}
+
+// parseStep 解析菜谱中的一步并追加到流水线
+func (p *Pipeline) parseStep(value types.JSONValue) error {
+	obj, err := value.AsObject()
+	if err != nil {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "步骤必须是对象")
+	}
+	op, ok := stringMember(obj, "op")
+	if !ok {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "步骤缺少字符串op")
+	}
+
+	switch op {
+	case "select":
+		if err := checkMembers(obj, "op", "path", "first"); err != nil {
+			return err
+		}
+		path, ok := stringMember(obj, "path")
+		if !ok {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "select缺少字符串path")
+		}
+		first := obj.Get("first")
+		if obj.Has("first") && !first.IsBoolean() {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "select的first必须是布尔值")
+		}
+		if b, _ := first.AsBoolean(); b {
+			p.SelectFirst(path)
+		} else {
+			p.Select(path)
+		}
+	case "rename":
+		if err := checkMembers(obj, "op", "keys"); err != nil {
+			return err
+		}
+		keys, err := obj.GetObject("keys")
+		if err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "rename的keys必须是对象")
+		}
+		names := make(map[string]string, keys.Size())
+		for _, key := range keys.Keys() {
+			name, ok := stringMember(keys, key)
+			if !ok {
+				return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, fmt.Sprintf("rename的新属性名%q必须是字符串", key))
+			}
+			names[key] = name
+		}
+		p.Rename(names)
+	case "patch":
+		if err := checkMembers(obj, "op", "patch"); err != nil {
+			return err
+		}
+		ops, err := obj.GetArray("patch")
+		if err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "patch的patch必须是数组")
+		}
+		parsed, err := patch.ParsePatch(ops.String())
+		if err != nil {
+			return err
+		}
+		p.Patch(parsed)
+	case "sort":
+		if err := checkMembers(obj, "op", "by", "order"); err != nil {
+			return err
+		}
+		order := utils.Ascending
+		if obj.Has("order") {
+			switch s, _ := stringMember(obj, "order"); s {
+			case "asc":
+			case "desc":
+				order = utils.Descending
+			default:
+				return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "sort的order必须是asc或desc")
+			}
+		}
+		by, ok := stringMember(obj, "by")
+		if obj.Has("by") && !ok {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "sort的by必须是字符串")
+		}
+		p.Sort(by, order)
+	case "project":
+		if err := checkMembers(obj, "op", "fields"); err != nil {
+			return err
+		}
+		spec, err := obj.GetObject("fields")
+		if err != nil {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, "project的fields必须是对象")
+		}
+		fields := make([]Field, 0, spec.Size())
+		for _, name := range spec.Keys() {
+			path, ok := stringMember(spec, name)
+			if !ok {
+				return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, fmt.Sprintf("project的字段%q的路径必须是字符串", name))
+			}
+			fields = append(fields, Field{Name: name, Path: path})
+		}
+		p.Project(fields...)
+	default:
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe,
+			fmt.Sprintf("未知的操作%q，可以使用select、rename、patch、sort和project", op))
+	}
+	return nil
+}
+
+// stringMember 返回对象中的字符串成员，成员不存在或不是字符串时第二个返回值为false
+func stringMember(obj *types.JSONObject, key string) (string, bool) {
+	value := obj.Get(key)
+	if !value.IsString() {
+		return "", false
+	}
+	s, _ := value.AsString()
+	return s, true
+}
+
+// checkMembers 检查对象只包含allowed中的成员，避免拼错的成员被忽略
+func checkMembers(obj *types.JSONObject, allowed ...string) error {
+	for _, key := range obj.Keys() {
+		known := false
+		for _, name := range allowed {
+			if key == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidRecipe, fmt.Sprintf("未知的成员%q", key))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package recipe
+
+import (
+	"errors"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+	"github.com/UserLeeZJ/gojson/utils"
+)
+
+const order = `{
+	"id": 7,
+	"items": [
+		{"sku": "b", "qty": 1, "price": 5, "meta": {"qty": 0}},
+		{"sku": "a", "qty": 2, "price": 12},
+		{"sku": "c", "qty": 3}
+	]
+}`
+
+func TestParsePipeline(t *testing.T) {
+	p, err := ParsePipeline([]byte(`{"steps": [
+		{"op": "select", "path": "$.items[*]"},
+		{"op": "rename", "keys": {"qty": "quantity"}},
+		{"op": "sort", "by": "$.price", "order": "desc"},
+		{"op": "patch", "patch": [{"op": "remove", "path": "/1/meta"}]},
+		{"op": "project", "fields": {"sku": "$.sku", "n": "$..quantity", "price": "$.price"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParsePipeline() error = %v", err)
+	}
+	if p.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", p.Len())
+	}
+
+	doc := parser.MustParse(order)
+	before := doc.String()
+	got, err := p.Apply(doc)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := `[{"n":2,"price":12,"sku":"a"},{"n":1,"price":5,"sku":"b"},{"n":3,"sku":"c"}]`
+	if got.String() != want {
+		t.Errorf("Apply() = %s, want %s", got.String(), want)
+	}
+	if doc.String() != before {
+		t.Errorf("Apply() 修改了输入: %s", doc.String())
+	}
+}
+
+func TestPipelineBuilder(t *testing.T) {
+	p := NewPipeline().
+		SelectFirst("$.items").
+		Sort("$.qty", utils.Descending).
+		Project(Field{Name: "id", Path: "$.sku"}, Field{Name: "missing", Path: "$.nope"})
+	if err := p.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	docs := []types.JSONValue{parser.MustParse(order), parser.MustParse(`{"items": []}`)}
+	got, err := p.ApplyAll(docs)
+	if err != nil {
+		t.Fatalf("ApplyAll() error = %v", err)
+	}
+	if got[0].String() != `[{"id":"c"},{"id":"a"},{"id":"b"}]` || got[1].String() != `[]` {
+		t.Errorf("ApplyAll() = %v", got)
+	}
+
+	// 第二个文档没有items
+	_, err = p.ApplyAll([]types.JSONValue{parser.MustParse(order), parser.MustParse(`{}`)})
+	var jsonErr *jsonerrors.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Message != "转换第2个文档失败" {
+		t.Errorf("ApplyAll() error = %v", err)
+	}
+
+	// 对对象排序
+	if _, err := NewPipeline().Sort("", utils.Ascending).Apply(parser.MustParse(order)); err == nil {
+		t.Errorf("Sort() 对对象应该返回错误")
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	recipes := []string{
+		`[]`,
+		`{"steps": {}}`,
+		`{"steps": [], "extra": 1}`,
+		`{"steps": [{"path": "$"}]}`,
+		`{"steps": [{"op": "unknown"}]}`,
+		`{"steps": [{"op": "select"}]}`,
+		`{"steps": [{"op": "select", "path": "$[", "first": true}]}`,
+		`{"steps": [{"op": "select", "path": "$", "frist": true}]}`,
+		`{"steps": [{"op": "rename", "keys": {"a": 1}}]}`,
+		`{"steps": [{"op": "patch", "patch": [{"op": "nope", "path": "/a"}]}]}`,
+		`{"steps": [{"op": "sort", "order": "up"}]}`,
+		`{"steps": [{"op": "sort", "by": 1}]}`,
+		`{"steps": [{"op": "select", "path": "$", "first": "yes"}]}`,
+		`{"steps": [{"op": "project", "fields": {"a": "$.b["}}]}`,
+	}
+	for _, recipe := range recipes {
+		_, err := ParsePipeline([]byte(recipe))
+		var jsonErr *jsonerrors.JSONError
+		if !errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrInvalidRecipe {
+			t.Errorf("ParsePipeline(%s) error = %v, want ErrInvalidRecipe", recipe, err)
+		}
+	}
+}
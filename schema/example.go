@@ -0,0 +1,301 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/patch"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Resolver 根据$ref返回被引用的Schema
+type Resolver func(ref string) (*Schema, error)
+
+// ExampleOptions 表示示例生成选项
+type ExampleOptions struct {
+	// Resolver 用于解析$defs之外的引用，为nil时只能解析根Schema的$defs
+	Resolver Resolver
+}
+
+// formatExamples 是常见字符串格式的示例值
+var formatExamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "password",
+}
+
+// exampleGenerator 保存示例生成的状态
+type exampleGenerator struct {
+	root     *Schema
+	resolver Resolver
+	visiting map[string]bool
+}
+
+// GenerateExample 根据Schema生成一个合理的示例值。
+// 优先使用example、examples、default和enum中给出的值，
+// 否则根据类型、格式和取值范围构造。对象包含所有属性，数组包含满足minItems的元素。
+func GenerateExample(s *Schema) (types.JSONValue, error) {
+	return GenerateExampleWithOptions(s, nil)
+}
+
+// GenerateExampleWithOptions 按选项根据Schema生成示例值
+func GenerateExampleWithOptions(s *Schema, opts *ExampleOptions) (types.JSONValue, error) {
+	if s == nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "Schema不能为nil")
+	}
+	g := &exampleGenerator{root: s, visiting: make(map[string]bool)}
+	if opts != nil {
+		g.resolver = opts.Resolver
+	}
+	return g.generate(s)
+}
+
+// generate 生成Schema的示例值
+func (g *exampleGenerator) generate(s *Schema) (types.JSONValue, error) {
+	if s.Ref != "" {
+		// 递归引用在第二次出现时以null结束
+		if g.visiting[s.Ref] {
+			return types.NewJSONNull(), nil
+		}
+		target, err := g.resolve(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		g.visiting[s.Ref] = true
+		defer delete(g.visiting, s.Ref)
+		return g.generate(target)
+	}
+
+	switch {
+	case s.Example != nil:
+		return types.FromInterface(s.Example)
+	case len(s.Examples) > 0:
+		return types.FromInterface(s.Examples[0])
+	case s.Default != nil:
+		return types.FromInterface(s.Default)
+	case len(s.Enum) > 0:
+		return types.FromInterface(s.Enum[0])
+	case len(s.AllOf) > 0:
+		return g.generateAllOf(s)
+	case len(s.OneOf) > 0:
+		return g.generate(s.OneOf[0])
+	case len(s.AnyOf) > 0:
+		return g.generate(s.AnyOf[0])
+	}
+
+	switch exampleType(s) {
+	case TypeObject:
+		return g.generateObject(s)
+	case TypeArray:
+		return g.generateArray(s)
+	case TypeString:
+		return types.NewJSONString(exampleString(s)), nil
+	case TypeInteger:
+		return types.NewJSONNumber(exampleNumber(s, true)), nil
+	case TypeNumber:
+		return types.NewJSONNumber(exampleNumber(s, false)), nil
+	case TypeBoolean:
+		return types.NewJSONBool(true), nil
+	default:
+		return types.NewJSONNull(), nil
+	}
+}
+
+// exampleType 返回生成示例时使用的类型，未声明类型时根据关键字推断
+func exampleType(s *Schema) string {
+	if nonNull := s.Type.NonNull(); len(nonNull) > 0 {
+		return nonNull[0]
+	}
+	switch {
+	case len(s.Properties) > 0 || s.AdditionalProperties != nil:
+		return TypeObject
+	case s.Items != nil:
+		return TypeArray
+	case s.Format != "" || s.MinLength != nil || s.MaxLength != nil:
+		return TypeString
+	case s.Minimum != nil || s.Maximum != nil:
+		return TypeNumber
+	default:
+		return TypeNull
+	}
+}
+
+// generateObject 生成对象示例
+func (g *exampleGenerator) generateObject(s *Schema) (types.JSONValue, error) {
+	obj := types.NewJSONObject()
+	for _, name := range s.PropertyNames() {
+		value, err := g.generate(s.Properties[name])
+		if err != nil {
+			return nil, err
+		}
+		obj.Put(name, value)
+	}
+
+	if len(s.Properties) == 0 && s.AdditionalProperties != nil && !s.AdditionalProperties.deny {
+		value, err := g.generate(s.AdditionalProperties)
+		if err != nil {
+			return nil, err
+		}
+		obj.Put("additionalProp1", value)
+	}
+	return obj, nil
+}
+
+// generateArray 生成数组示例
+func (g *exampleGenerator) generateArray(s *Schema) (types.JSONValue, error) {
+	count := 1
+	if s.MinItems != nil && *s.MinItems > count {
+		count = *s.MinItems
+	}
+	if s.MaxItems != nil && *s.MaxItems < count {
+		count = *s.MaxItems
+	}
+	if s.Items == nil {
+		count = 0
+	}
+
+	values := make([]types.JSONValue, 0, count)
+	for i := 0; i < count; i++ {
+		value, err := g.generate(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return types.NewJSONArrayFromValuesUnsafe(values), nil
+}
+
+// generateAllOf 合并allOf中各个对象示例的属性
+func (g *exampleGenerator) generateAllOf(s *Schema) (types.JSONValue, error) {
+	result := types.NewJSONObject()
+	for _, sub := range s.AllOf {
+		value, err := g.generate(sub)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := value.AsObject()
+		if err != nil {
+			// allOf中包含非对象时，使用最后一个值
+			return value, nil
+		}
+		result.Merge(obj)
+	}
+	return result, nil
+}
+
+// exampleString 生成满足格式和长度限制的字符串
+func exampleString(s *Schema) string {
+	value, ok := formatExamples[s.Format]
+	if !ok {
+		value = "string"
+	}
+	if s.MinLength != nil && len(value) < *s.MinLength {
+		value += strings.Repeat("x", *s.MinLength-len(value))
+	}
+	if s.MaxLength != nil && len(value) > *s.MaxLength {
+		value = value[:*s.MaxLength]
+	}
+	return value
+}
+
+// exampleNumber 生成位于取值范围内的数字
+func exampleNumber(s *Schema, integer bool) float64 {
+	value := 0.0
+	if s.Minimum != nil && value < *s.Minimum {
+		value = *s.Minimum
+		if integer {
+			value = math.Ceil(value)
+		}
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		value = *s.Maximum
+		if integer {
+			value = math.Floor(value)
+		}
+	}
+	return value
+}
+
+// resolve 解析引用，先查找根Schema的$defs，再使用Resolver
+func (g *exampleGenerator) resolve(ref string) (*Schema, error) {
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		if def, ok := g.root.Definitions[patch.UnescapeSegment(name)]; ok {
+			return def, nil
+		}
+	}
+	if g.resolver != nil {
+		return g.resolver(ref)
+	}
+	return nil, jsonerrors.NewJSONError(jsonerrors.ErrPathNotFound, "无法解析引用").WithPath(ref)
+}
+
+// ParseAt 解析JSON文档中JSON Pointer指向的Schema，pointer可以带有"#"前缀
+func ParseAt(data []byte, pointer string) (*Schema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析Schema失败").WithCause(err)
+	}
+	return schemaAt(doc, pointer)
+}
+
+// DocumentResolver 返回在JSON文档中解析本地引用（如"#/components/schemas/User"）的Resolver
+func DocumentResolver(data []byte) (Resolver, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析Schema失败").WithCause(err)
+	}
+	return func(ref string) (*Schema, error) {
+		if !strings.HasPrefix(ref, "#") {
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "只支持文档内的引用").WithPath(ref)
+		}
+		return schemaAt(doc, ref)
+	}, nil
+}
+
+// schemaAt 返回文档中JSON Pointer指向的Schema
+func schemaAt(doc interface{}, pointer string) (*Schema, error) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	current := doc
+	if pointer != "" {
+		if !strings.HasPrefix(pointer, "/") {
+			return nil, jsonerrors.ErrInvalidPathWithDetails(pointer, "JSON Pointer必须以/开头")
+		}
+		for _, segment := range strings.Split(pointer[1:], "/") {
+			segment = patch.UnescapeSegment(segment)
+			switch node := current.(type) {
+			case map[string]interface{}:
+				next, ok := node[segment]
+				if !ok {
+					return nil, jsonerrors.ErrPathNotFoundWithDetails(pointer)
+				}
+				current = next
+			case []interface{}:
+				index, err := strconv.Atoi(segment)
+				if err != nil || index < 0 || index >= len(node) {
+					return nil, jsonerrors.ErrPathNotFoundWithDetails(pointer)
+				}
+				current = node[index]
+			default:
+				return nil, jsonerrors.ErrPathNotFoundWithDetails(pointer)
+			}
+		}
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析Schema失败").WithCause(err)
+	}
+	return Parse(data)
+}
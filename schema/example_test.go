@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestGenerateExample(t *testing.T) {
+	s, err := Parse([]byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1.5},
+			"score": {"type": "number", "maximum": -2},
+			"status": {"type": "string", "enum": ["active", "disabled"]},
+			"email": {"type": "string", "format": "email"},
+			"code": {"type": "string", "minLength": 8, "maxLength": 10},
+			"short": {"type": "string", "maxLength": 3},
+			"name": {"type": "string", "example": "Alice"},
+			"retries": {"type": "integer", "default": 3},
+			"tags": {"type": "array", "items": {"type": "string"}, "minItems": 2},
+			"none": {"type": "array", "items": {"type": "string"}, "maxItems": 0},
+			"labels": {"type": "object", "additionalProperties": {"type": "boolean"}},
+			"closed": {"type": "object", "additionalProperties": false},
+			"nullable": {"type": ["null", "integer"]},
+			"owner": {"$ref": "#/$defs/Person"}
+		},
+		"$defs": {
+			"Person": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"manager": {"$ref": "#/$defs/Person"}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	value, err := GenerateExample(s)
+	if err != nil {
+		t.Fatalf("GenerateExample() error = %v", err)
+	}
+
+	want := `{"closed":{},"code":"stringxx","email":"user@example.com","id":2,"labels":{"additionalProp1":true},` +
+		`"name":"Alice","none":[],"nullable":0,"owner":{"manager":null,"name":"string"},"retries":3,` +
+		`"score":-2,"short":"str","status":"active","tags":["string","string"]}`
+	if value.String() != want {
+		t.Errorf("GenerateExample() = %s, want %s", value.String(), want)
+	}
+}
+
+func TestGenerateExampleOpenAPI(t *testing.T) {
+	doc := []byte(`{
+		"components": {
+			"schemas": {
+				"Base": {"type": "object", "properties": {"id": {"type": "string", "format": "uuid"}}},
+				"User": {
+					"allOf": [
+						{"$ref": "#/components/schemas/Base"},
+						{"properties": {"createdAt": {"type": "string", "format": "date-time"}}}
+					]
+				},
+				"Pet": {"oneOf": [{"type": "boolean"}, {"type": "string"}]}
+			}
+		}
+	}`)
+
+	resolver, err := DocumentResolver(doc)
+	if err != nil {
+		t.Fatalf("DocumentResolver() error = %v", err)
+	}
+
+	tests := map[string]string{
+		"#/components/schemas/User": `{"createdAt":"2024-01-01T00:00:00Z","id":"3fa85f64-5717-4562-b3fc-2c963f66afa6"}`,
+		"#/components/schemas/Pet":  `true`,
+	}
+	for pointer, want := range tests {
+		s, err := ParseAt(doc, pointer)
+		if err != nil {
+			t.Fatalf("ParseAt(%s) error = %v", pointer, err)
+		}
+		value, err := GenerateExampleWithOptions(s, &ExampleOptions{Resolver: resolver})
+		if err != nil {
+			t.Fatalf("GenerateExampleWithOptions(%s) error = %v", pointer, err)
+		}
+		if value.String() != want {
+			t.Errorf("GenerateExampleWithOptions(%s) = %s, want %s", pointer, value.String(), want)
+		}
+	}
+
+	if _, err := ParseAt(doc, "#/components/schemas/Missing"); err == nil {
+		t.Errorf("ParseAt() with missing pointer should fail")
+	}
+	s, _ := ParseAt(doc, "#/components/schemas/User")
+	if _, err := GenerateExample(s); err == nil {
+		t.Errorf("GenerateExample() without resolver should fail on external reference")
+	}
+}
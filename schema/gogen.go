@@ -0,0 +1,265 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// GoOptions 表示Go代码生成选项
+type GoOptions struct {
+	// Package 是生成代码的包名，为空时使用main
+	Package string
+	// RootName 是根类型的名称，为空时使用Root
+	RootName string
+}
+
+// commonInitialisms 是生成字段名时全部大写的缩写
+var commonInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true,
+	"TTL": true, "UDP": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goDef 表示一个生成的类型定义
+type goDef struct {
+	name string
+	body string
+}
+
+// goGenerator 保存代码生成的状态
+type goGenerator struct {
+	root    string
+	defs    []goDef
+	byName  map[string]string
+	imports map[string]bool
+}
+
+// GenerateGo 根据Schema生成Go类型定义。
+// 对象生成结构体，可选属性添加omitempty，允许null的标量和结构体使用指针。
+func GenerateGo(s *Schema, opts GoOptions) ([]byte, error) {
+	if s == nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "Schema不能为nil")
+	}
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	if opts.RootName == "" {
+		opts.RootName = "Root"
+	}
+
+	rootName := goName(opts.RootName)
+	g := &goGenerator{root: rootName, byName: make(map[string]string), imports: make(map[string]bool)}
+	if isStruct(s) {
+		g.defineStruct(s, rootName, "")
+	} else {
+		g.define(rootName, g.typeExpr(s, rootName+"Item", rootName))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gojson gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	if g.imports["time"] {
+		buf.WriteString("import \"time\"\n\n")
+	}
+
+	// 根类型在前，其余类型按定义顺序输出
+	for _, def := range g.defs {
+		if def.name == rootName {
+			fmt.Fprintf(&buf, "type %s %s\n\n", def.name, def.body)
+		}
+	}
+	for _, def := range g.defs {
+		if def.name != rootName {
+			fmt.Fprintf(&buf, "type %s %s\n\n", def.name, def.body)
+		}
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "格式化生成的代码失败").WithCause(err)
+	}
+	return source, nil
+}
+
+// isStruct 检查Schema是否生成结构体
+func isStruct(s *Schema) bool {
+	nonNull := s.Type.NonNull()
+	return len(nonNull) == 1 && nonNull[0] == TypeObject && len(s.Properties) > 0
+}
+
+// typeExpr 返回Schema对应的Go类型表达式，name是需要定义新类型时使用的名称
+func (g *goGenerator) typeExpr(s *Schema, name, parent string) string {
+	if s == nil {
+		return "interface{}"
+	}
+
+	nonNull := s.Type.NonNull()
+	if len(nonNull) != 1 {
+		return "interface{}"
+	}
+
+	var expr string
+	switch nonNull[0] {
+	case TypeObject:
+		if len(s.Properties) == 0 {
+			return "map[string]interface{}"
+		}
+		expr = g.defineStruct(s, name, parent)
+	case TypeArray:
+		return "[]" + g.typeExpr(s.Items, singular(name), parent)
+	case TypeString:
+		expr = "string"
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			expr = "time.Time"
+		}
+	case TypeInteger:
+		expr = "int64"
+	case TypeNumber:
+		expr = "float64"
+	case TypeBoolean:
+		expr = "bool"
+	default:
+		return "interface{}"
+	}
+
+	if s.Nullable() {
+		return "*" + expr
+	}
+	return expr
+}
+
+// defineStruct 定义Schema对应的结构体并返回类型名称
+func (g *goGenerator) defineStruct(s *Schema, name, parent string) string {
+	var body strings.Builder
+	body.WriteString("struct {\n")
+
+	used := make(map[string]bool)
+	for _, key := range s.PropertyNames() {
+		field := uniqueName(goName(key), used)
+		used[field] = true
+
+		typ := g.typeExpr(s.Properties[key], field, name)
+		tag := key
+		if !s.IsRequired(key) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&body, "\t%s %s `json:%s`\n", field, typ, strconv.Quote(tag))
+	}
+	body.WriteString("}")
+
+	return g.define(g.resolveName(name, parent, body.String()), body.String())
+}
+
+// resolveName 为类型定义选择不冲突的名称，定义相同时复用已有名称。
+// 根类型的名称只留给根类型使用。
+func (g *goGenerator) resolveName(name, parent, body string) string {
+	available := func(candidate string) bool {
+		if candidate == g.root && parent != "" {
+			return false
+		}
+		existing, ok := g.byName[candidate]
+		return !ok || existing == body
+	}
+
+	if available(name) {
+		return name
+	}
+	if parent != "" && available(parent+name) {
+		return parent + name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); available(candidate) {
+			return candidate
+		}
+	}
+}
+
+// define 记录类型定义
+func (g *goGenerator) define(name, body string) string {
+	if _, ok := g.byName[name]; !ok {
+		g.byName[name] = body
+		g.defs = append(g.defs, goDef{name: name, body: body})
+	}
+	return name
+}
+
+// uniqueName 返回在used中不冲突的名称
+func uniqueName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// goName 将JSON键转换为导出的Go标识符
+func goName(key string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var name strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if commonInitialisms[upper] {
+			name.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		name.WriteRune(unicode.ToUpper(r[0]))
+		name.WriteString(string(r[1:]))
+	}
+
+	result := name.String()
+	if result == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		return "X" + result
+	}
+	return result
+}
+
+// singular 返回名称的单数形式，用于数组元素的类型名称
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	default:
+		return name
+	}
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Infer 根据样本值推断Schema。
+// 数组的元素Schema由所有元素合并得到，对象的必需属性是所有样本中都出现的属性。
+func Infer(value types.JSONValue) *Schema {
+	if value == nil || value.IsNull() {
+		return &Schema{Type: Types{TypeNull}}
+	}
+
+	switch {
+	case value.IsBoolean():
+		return &Schema{Type: Types{TypeBoolean}}
+	case value.IsNumber():
+		n, _ := value.AsNumber()
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return &Schema{Type: Types{TypeInteger}}
+		}
+		return &Schema{Type: Types{TypeNumber}}
+	case value.IsString():
+		s, _ := value.AsString()
+		return &Schema{Type: Types{TypeString}, Format: inferFormat(s)}
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		schema := &Schema{Type: Types{TypeArray}}
+		for _, element := range arr.Values() {
+			schema.Items = Merge(schema.Items, Infer(element))
+		}
+		return schema
+	default:
+		obj, _ := value.AsObject()
+		schema := &Schema{
+			Type:       Types{TypeObject},
+			Properties: make(map[string]*Schema),
+			Required:   obj.SortedKeys(),
+		}
+		for _, key := range obj.Keys() {
+			schema.Properties[key] = Infer(obj.Get(key))
+		}
+		return schema
+	}
+}
+
+// InferSamples 根据多个样本推断Schema
+func InferSamples(values ...types.JSONValue) *Schema {
+	var schema *Schema
+	for _, value := range values {
+		schema = Merge(schema, Infer(value))
+	}
+	return schema
+}
+
+// Merge 合并两个推断得到的Schema，返回同时接受两者样本的Schema
+func Merge(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	result := &Schema{Type: a.Type.union(b.Type)}
+
+	// 字符串格式只有一致时才保留
+	aString, bString := a.Type.Has(TypeString), b.Type.Has(TypeString)
+	switch {
+	case aString && bString:
+		if a.Format == b.Format {
+			result.Format = a.Format
+		}
+	case aString:
+		result.Format = a.Format
+	case bString:
+		result.Format = b.Format
+	}
+
+	if a.Items != nil || b.Items != nil {
+		result.Items = Merge(a.Items, b.Items)
+	}
+
+	aObject, bObject := a.Type.Has(TypeObject), b.Type.Has(TypeObject)
+	if aObject || bObject {
+		result.Properties = make(map[string]*Schema)
+		for name, prop := range a.Properties {
+			result.Properties[name] = prop
+		}
+		for name, prop := range b.Properties {
+			result.Properties[name] = Merge(result.Properties[name], prop)
+		}
+
+		switch {
+		case aObject && bObject:
+			for _, name := range a.Required {
+				if b.IsRequired(name) {
+					result.Required = append(result.Required, name)
+				}
+			}
+		case aObject:
+			result.Required = append(result.Required, a.Required...)
+		default:
+			result.Required = append(result.Required, b.Required...)
+		}
+		sort.Strings(result.Required)
+	}
+
+	return result
+}
+
+// inferFormat 推断字符串的格式
+func inferFormat(s string) string {
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return "date-time"
+	}
+	return ""
+}
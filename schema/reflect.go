@@ -0,0 +1,256 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	numberType        = reflect.TypeOf(json.Number(""))
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// fieldInfo 表示结构体字段序列化后的JSON属性
+type fieldInfo struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+	asString  bool
+	depth     int
+	tagged    bool
+}
+
+// reflector 保存从Go类型生成Schema的状态
+type reflector struct {
+	inProgress map[reflect.Type]bool
+	recursive  map[reflect.Type]bool
+	defs       map[string]*Schema
+}
+
+// FromType 根据Go类型生成Schema。
+// 字段的名称、省略和字符串选项遵循encoding/json的规则，与gojson的序列化结果一致。
+// 递归类型通过$defs中的定义引用。
+func FromType(t reflect.Type) *Schema {
+	r := &reflector{
+		inProgress: make(map[reflect.Type]bool),
+		recursive:  make(map[reflect.Type]bool),
+		defs:       make(map[string]*Schema),
+	}
+	s := r.schemaFor(t)
+	if len(r.defs) > 0 {
+		s.Definitions = r.defs
+	}
+	return s
+}
+
+// FromValue 根据Go值的类型生成Schema
+func FromValue(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{Type: Types{TypeNull}}
+	}
+	return FromType(reflect.TypeOf(v))
+}
+
+// schemaFor 返回类型对应的Schema
+func (r *reflector) schemaFor(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := r.schemaFor(t.Elem())
+		if s.Ref == "" && len(s.Type) > 0 && !s.Nullable() {
+			s.Type = s.Type.union(Types{TypeNull})
+		}
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{TypeString}, Format: "date-time"}
+	case t == numberType:
+		return &Schema{Type: Types{TypeNumber}}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// 自定义序列化的结果无法从类型推断
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Types{TypeString}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{TypeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// []byte序列化为base64字符串
+			return &Schema{Type: Types{TypeString}, Format: "byte"}
+		}
+		s := &Schema{Type: Types{TypeArray}, Items: r.schemaFor(t.Elem())}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+		return s
+	case reflect.Map:
+		return &Schema{Type: Types{TypeObject}, AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return r.structSchema(t)
+	default:
+		// interface{}等类型可以是任意值
+		return &Schema{}
+	}
+}
+
+// structSchema 返回结构体对应的Schema，递归引用时返回$ref
+func (r *reflector) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" && r.inProgress[t] {
+		r.recursive[t] = true
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	if name != "" {
+		r.inProgress[t] = true
+		defer delete(r.inProgress, t)
+	}
+
+	s := &Schema{Type: Types{TypeObject}, Properties: make(map[string]*Schema)}
+	for _, field := range structFields(t) {
+		prop := r.schemaFor(field.typ)
+		if field.asString && isScalar(prop) {
+			prop = &Schema{Type: Types{TypeString}}
+		}
+		s.Properties[field.name] = prop
+		if !field.omitEmpty {
+			s.Required = append(s.Required, field.name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	if r.recursive[t] {
+		r.defs[name] = s
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	return s
+}
+
+// isScalar 检查Schema是否为可以使用string选项的标量类型
+func isScalar(s *Schema) bool {
+	nonNull := s.Type.NonNull()
+	if len(nonNull) != 1 {
+		return false
+	}
+	switch nonNull[0] {
+	case TypeString, TypeInteger, TypeNumber, TypeBoolean:
+		return true
+	}
+	return false
+}
+
+// structFields 按encoding/json的规则返回结构体序列化的字段，包括嵌入结构体提升的字段
+func structFields(t reflect.Type) []fieldInfo {
+	var fields []fieldInfo
+	var walk func(t reflect.Type, index []int, depth int, visited map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, depth int, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+
+			ft := sf.Type
+			if sf.Anonymous {
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if !sf.IsExported() && ft.Kind() != reflect.Struct {
+					continue
+				}
+				if name == "" && ft.Kind() == reflect.Struct {
+					walk(ft, append(append([]int{}, index...), i), depth+1, visited)
+					continue
+				}
+			} else if !sf.IsExported() {
+				continue
+			}
+
+			info := fieldInfo{
+				name:      name,
+				index:     append(append([]int{}, index...), i),
+				typ:       sf.Type,
+				omitEmpty: hasOption(opts, "omitempty"),
+				asString:  hasOption(opts, "string"),
+				depth:     depth,
+				tagged:    name != "",
+			}
+			if info.name == "" {
+				info.name = sf.Name
+			}
+			fields = append(fields, info)
+		}
+	}
+	walk(t, nil, 0, make(map[reflect.Type]bool))
+
+	// 同名字段中深度最小的优先，深度相同时带标签的优先，仍然冲突则都忽略
+	byName := make(map[string][]fieldInfo)
+	var order []string
+	for _, f := range fields {
+		if _, ok := byName[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var result []fieldInfo
+	for _, name := range order {
+		if f, ok := dominantField(byName[name]); ok {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// dominantField 从同名字段中选出生效的字段
+func dominantField(fields []fieldInfo) (fieldInfo, bool) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].depth != fields[j].depth {
+			return fields[i].depth < fields[j].depth
+		}
+		return fields[i].tagged && !fields[j].tagged
+	})
+	if len(fields) > 1 && fields[0].depth == fields[1].depth && fields[0].tagged == fields[1].tagged {
+		return fieldInfo{}, false
+	}
+	return fields[0], true
+}
+
+// hasOption 检查标签选项中是否包含指定选项
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectBase struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type reflectNode struct {
+	Value    string         `json:"value"`
+	Children []*reflectNode `json:"children,omitempty"`
+}
+
+type reflectUser struct {
+	reflectBase
+	Name     string          `json:"name"`
+	Nickname *string         `json:"nickname,omitempty"`
+	Age      int             `json:"age,string"`
+	Tags     []string        `json:"tags"`
+	Attrs    map[string]int  `json:"attrs"`
+	Raw      json.RawMessage `json:"raw"`
+	Avatar   []byte          `json:"avatar"`
+	Point    [2]float64      `json:"point"`
+	Tree     reflectNode     `json:"tree"`
+	Ignored  string          `json:"-"`
+	Untagged bool
+	hidden   string
+	Extra    map[string]string `json:",omitempty"`
+}
+
+func TestFromType(t *testing.T) {
+	s := FromValue(reflectUser{})
+
+	wantRequired := []string{"Untagged", "age", "attrs", "avatar", "created", "id", "name", "point", "raw", "tags", "tree"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("Required = %v, want %v", s.Required, wantRequired)
+	}
+	if _, ok := s.Properties["Ignored"]; ok {
+		t.Errorf("field with json:\"-\" should be skipped")
+	}
+	if _, ok := s.Properties["hidden"]; ok {
+		t.Errorf("unexported field should be skipped")
+	}
+
+	tests := map[string]string{
+		"id":       `{"type":"integer"}`,
+		"created":  `{"type":"string","format":"date-time"}`,
+		"nickname": `{"type":["string","null"]}`,
+		"age":      `{"type":"string"}`,
+		"tags":     `{"type":"array","items":{"type":"string"}}`,
+		"attrs":    `{"type":"object","additionalProperties":{"type":"integer"}}`,
+		"raw":      `{}`,
+		"avatar":   `{"type":"string","format":"byte"}`,
+		"point":    `{"type":"array","items":{"type":"number"},"minItems":2,"maxItems":2}`,
+		"tree":     `{"$ref":"#/$defs/reflectNode"}`,
+		"Untagged": `{"type":"boolean"}`,
+		"Extra":    `{"type":"object","additionalProperties":{"type":"string"}}`,
+	}
+	for name, want := range tests {
+		data, _ := json.Marshal(s.Properties[name])
+		if string(data) != want {
+			t.Errorf("property %s = %s, want %s", name, data, want)
+		}
+	}
+
+	def, _ := json.Marshal(s.Definitions["reflectNode"])
+	want := `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/reflectNode"}},"value":{"type":"string"}},"required":["value"]}`
+	if string(def) != want {
+		t.Errorf("$defs.reflectNode = %s, want %s", def, want)
+	}
+}
+
+func TestFromTypeConflictingFields(t *testing.T) {
+	type a struct{ Name string }
+	type b struct{ Name string }
+	type c struct {
+		Name string `json:"Name"`
+	}
+	type conflict struct {
+		a
+		b
+	}
+	type tagged struct {
+		a
+		c
+	}
+
+	if s := FromValue(conflict{}); len(s.Properties) != 0 {
+		t.Errorf("ambiguous embedded fields should be dropped: %v", s.PropertyNames())
+	}
+	if s := FromValue(tagged{}); len(s.Properties) != 1 {
+		t.Errorf("tagged embedded field should win: %v", s.PropertyNames())
+	}
+	if s := FromValue(nil); !reflect.DeepEqual(s.Type, Types{TypeNull}) {
+		t.Errorf("FromValue(nil) = %v", s.Type)
+	}
+}
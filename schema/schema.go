@@ -0,0 +1,200 @@
+// Package schema 提供gojson库的JSON Schema功能，包括从样本推断Schema和代码生成。
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// JSON Schema中的类型名称
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// typeOrder 是类型名称的规范顺序
+var typeOrder = map[string]int{
+	TypeObject:  0,
+	TypeArray:   1,
+	TypeString:  2,
+	TypeInteger: 3,
+	TypeNumber:  4,
+	TypeBoolean: 5,
+	TypeNull:    6,
+}
+
+// Types 表示Schema允许的类型集合。
+// 只有一个类型时序列化为字符串，否则序列化为数组。
+type Types []string
+
+// Has 检查集合是否包含指定类型
+func (t Types) Has(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NonNull 返回除null之外的类型
+func (t Types) NonNull() Types {
+	var result Types
+	for _, n := range t {
+		if n != TypeNull {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// MarshalJSON 实现json.Marshaler接口
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+// union 返回两个类型集合的并集，integer与number合并为number
+func (t Types) union(other Types) Types {
+	seen := make(map[string]bool)
+	for _, n := range t {
+		seen[n] = true
+	}
+	for _, n := range other {
+		seen[n] = true
+	}
+	if seen[TypeInteger] && seen[TypeNumber] {
+		delete(seen, TypeInteger)
+	}
+
+	result := make(Types, 0, len(seen))
+	for n := range seen {
+		result = append(result, n)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return typeOrder[result[i]] < typeOrder[result[j]]
+	})
+	return result
+}
+
+// Schema 表示JSON Schema中的一个节点，只包含常用的关键字
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*Schema `json:"$defs,omitempty"`
+
+	deny bool // 由布尔值false表示的Schema，不接受任何值
+}
+
+// schemaFields 用于在序列化时避免递归调用MarshalJSON
+type schemaFields Schema
+
+// MarshalJSON 实现json.Marshaler接口
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.deny {
+		return []byte("false"), nil
+	}
+	return json.Marshal((*schemaFields)(s))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，布尔值true和false也是合法的Schema
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{deny: true}
+		return nil
+	}
+	return json.Unmarshal(data, (*schemaFields)(s))
+}
+
+// Parse 将JSON解析为Schema
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "解析Schema失败").WithCause(err)
+	}
+	return &s, nil
+}
+
+// String 返回Schema的JSON表示
+func (s *Schema) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// IsRequired 检查属性是否为必需
+func (s *Schema) IsRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Nullable 检查Schema是否允许null
+func (s *Schema) Nullable() bool {
+	return s.Type.Has(TypeNull)
+}
+
+// PropertyNames 返回排序后的属性名称
+func (s *Schema) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
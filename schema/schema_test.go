@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestInfer(t *testing.T) {
+	value := parser.MustParse(`{
+		"id": 1,
+		"price": 9.5,
+		"name": "x",
+		"createdAt": "2024-01-02T03:04:05Z",
+		"tags": ["a", "b"],
+		"items": [{"id": 1, "note": null}, {"id": 2.5, "note": "n", "extra": true}]
+	}`)
+
+	s := Infer(value)
+	if !reflect.DeepEqual(s.Type, Types{TypeObject}) {
+		t.Errorf("Type = %v, want object", s.Type)
+	}
+	if got := s.Properties["id"].Type; !reflect.DeepEqual(got, Types{TypeInteger}) {
+		t.Errorf("id type = %v, want integer", got)
+	}
+	if got := s.Properties["price"].Type; !reflect.DeepEqual(got, Types{TypeNumber}) {
+		t.Errorf("price type = %v, want number", got)
+	}
+	if got := s.Properties["createdAt"].Format; got != "date-time" {
+		t.Errorf("createdAt format = %q, want date-time", got)
+	}
+	if got := s.Properties["tags"].Items.Type; !reflect.DeepEqual(got, Types{TypeString}) {
+		t.Errorf("tags items type = %v, want string", got)
+	}
+
+	items := s.Properties["items"].Items
+	if !reflect.DeepEqual(items.Required, []string{"id", "note"}) {
+		t.Errorf("items required = %v, want [id note]", items.Required)
+	}
+	if got := items.Properties["id"].Type; !reflect.DeepEqual(got, Types{TypeNumber}) {
+		t.Errorf("items.id type = %v, want number", got)
+	}
+	if got := items.Properties["note"].Type; !reflect.DeepEqual(got, Types{TypeString, TypeNull}) {
+		t.Errorf("items.note type = %v, want [string null]", got)
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	s, err := Parse([]byte(`{"type":["string","null"],"properties":{"a":{"type":"integer","minimum":1}}}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !s.Nullable() || *s.Properties["a"].Minimum != 1 {
+		t.Errorf("Parse() = %+v", s)
+	}
+
+	data, err := json.Marshal(s.Properties["a"])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"type":"integer","minimum":1}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	if _, err := Parse([]byte(`{"type":1}`)); err == nil {
+		t.Errorf("Parse() with invalid type should fail")
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	value := parser.MustParse(`{
+		"user_id": 1,
+		"userId": 2,
+		"created_at": "2024-01-02T03:04:05Z",
+		"address": {"city": "x", "zip": null},
+		"billing": {"address": {"street": "s"}},
+		"items": [{"id": 1, "price": 1.5}, {"id": 2}],
+		"mixed": [1, "a"],
+		"meta": {},
+		"9lives": true
+	}`)
+
+	source, err := GenerateGo(Infer(value), GoOptions{Package: "models"})
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+	code := string(source)
+
+	for _, want := range []string{
+		"package models",
+		`import "time"`,
+		"type Root struct",
+		"UserID2   int64                  `json:\"user_id\"`",
+		"CreatedAt time.Time",
+		"Address   Address",
+		"Zip  interface{} `json:\"zip\"`",
+		"type BillingAddress struct",
+		"Items     []Item",
+		"Price float64 `json:\"price,omitempty\"`",
+		"Mixed     []interface{}",
+		"Meta      map[string]interface{}",
+		"X9lives",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateGoRootArray(t *testing.T) {
+	source, err := GenerateGo(Infer(parser.MustParse(`[{"name":"a","score":null},{"name":"b","score":1}]`)), GoOptions{RootName: "users"})
+	if err != nil {
+		t.Fatalf("GenerateGo() error = %v", err)
+	}
+	code := string(source)
+	for _, want := range []string{"type Users []UsersItem", "type UsersItem struct", "Score *int64"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":    "UserID",
+		"firstName":  "FirstName",
+		"html-url":   "HTMLURL",
+		"2fa":        "X2fa",
+		"":           "Field",
+		"ÜberCool":   "ÜberCool",
+		"api_v2_key": "APIV2Key",
+	}
+	for key, want := range tests {
+		if got := goName(key); got != want {
+			t.Errorf("goName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package stream
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/UserLeeZJ/gojson/compat"
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// PathStats 是一个路径上所有值的统计信息
+// 数组元素的路径使用[*]，例如"$.items[*].price"，因此同一数组中的元素汇总在一起
+type PathStats struct {
+	// Path 是值的路径
+	Path string `json:"path"`
+	// Count 是该路径上出现的值的数量
+	Count int64 `json:"count"`
+	// Types 是各类型值的数量，键为object、array、string、number、boolean和null
+	Types map[string]int64 `json:"types"`
+	// NumberCount 是数字值的数量
+	NumberCount int64 `json:"numberCount,omitempty"`
+	// Sum 是数字值的和
+	Sum float64 `json:"sum,omitempty"`
+	// Min 是最小的数字值
+	Min float64 `json:"min,omitempty"`
+	// Max 是最大的数字值
+	Max float64 `json:"max,omitempty"`
+	// Strings 估计不同字符串值的数量，没有字符串值时为nil
+	Strings *HyperLogLog `json:"strings,omitempty"`
+}
+
+// Mean 返回数字值的平均值，没有数字值时返回0
+func (s *PathStats) Mean() float64 {
+	if s.NumberCount == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.NumberCount)
+}
+
+// DistinctStrings 返回不同字符串值数量的估计值
+func (s *PathStats) DistinctStrings() uint64 {
+	if s.Strings == nil {
+		return 0
+	}
+	return s.Strings.Count()
+}
+
+// addNumber 记录一个数字值
+func (s *PathStats) addNumber(n float64) {
+	if s.NumberCount == 0 || n < s.Min {
+		s.Min = n
+	}
+	if s.NumberCount == 0 || n > s.Max {
+		s.Max = n
+	}
+	s.NumberCount++
+	s.Sum += n
+}
+
+// merge 合并另一个路径的统计信息
+func (s *PathStats) merge(other *PathStats) error {
+	s.Count += other.Count
+	for t, n := range other.Types {
+		s.Types[t] += n
+	}
+	if other.NumberCount > 0 {
+		if s.NumberCount == 0 || other.Min < s.Min {
+			s.Min = other.Min
+		}
+		if s.NumberCount == 0 || other.Max > s.Max {
+			s.Max = other.Max
+		}
+		s.NumberCount += other.NumberCount
+		s.Sum += other.Sum
+	}
+	if other.Strings != nil {
+		if s.Strings == nil {
+			s.Strings, _ = NewHyperLogLog(other.Strings.precision)
+		}
+		return s.Strings.Merge(other.Strings)
+	}
+	return nil
+}
+
+// aggFrame 记录一层容器的路径
+type aggFrame struct {
+	path    string
+	isArray bool
+	key     string
+}
+
+// Aggregator 逐个消费JSON令牌并维护每个路径的统计信息。
+// 内存占用与不同路径的数量成正比，与输入大小无关；
+// 输入可以包含多个连续的JSON文档，例如NDJSON。
+// 在文档之间可以用json.Marshal保存检查点，之后用json.Unmarshal恢复并继续消费，
+// 也可以用Merge合并分别处理的多个输入的统计信息。
+type Aggregator struct {
+	stats     map[string]*PathStats
+	order     []*PathStats
+	stack     []aggFrame
+	documents int64
+}
+
+// NewAggregator 创建一个新的Aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{stats: make(map[string]*PathStats)}
+}
+
+// Consume 消费一个令牌
+func (a *Aggregator) Consume(token JSONToken) error {
+	switch token.Type {
+	case TokenError:
+		return token.Error
+	case TokenEOF:
+		if len(a.stack) > 0 {
+			return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+		}
+		return nil
+	case TokenPropertyName:
+		if len(a.stack) == 0 || a.stack[len(a.stack)-1].isArray {
+			return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的属性名")
+		}
+		a.stack[len(a.stack)-1].key = token.Value.(string)
+		return nil
+	case TokenObjectEnd, TokenArrayEnd:
+		if len(a.stack) == 0 {
+			return jsonerrors.NewJSONError(ErrInvalidJSON, "多余的结束符")
+		}
+		a.stack = a.stack[:len(a.stack)-1]
+		if len(a.stack) == 0 {
+			a.documents++
+		}
+		return nil
+	}
+
+	// 值的开始
+	path := "$"
+	if len(a.stack) > 0 {
+		top := a.stack[len(a.stack)-1]
+		if top.isArray {
+			path = top.path + "[*]"
+		} else {
+			path = types.ChildPath(top.path, types.KeySegment(top.key))
+		}
+	}
+	stats := a.pathStats(path)
+	stats.Count++
+
+	switch token.Type {
+	case TokenObjectStart:
+		stats.Types["object"]++
+		a.stack = append(a.stack, aggFrame{path: path})
+		return nil
+	case TokenArrayStart:
+		stats.Types["array"]++
+		a.stack = append(a.stack, aggFrame{path: path, isArray: true})
+		return nil
+	case TokenString:
+		stats.Types["string"]++
+		if stats.Strings == nil {
+			stats.Strings, _ = NewHyperLogLog(DefaultHLLPrecision)
+		}
+		stats.Strings.Add(token.Value.(string))
+	case TokenNumber:
+		stats.Types["number"]++
+		if n, err := token.Value.(json.Number).Float64(); err == nil {
+			stats.addNumber(n)
+		}
+	case TokenBoolean:
+		stats.Types["boolean"]++
+	case TokenNull:
+		stats.Types["null"]++
+	}
+	if len(a.stack) == 0 {
+		a.documents++
+	}
+	return nil
+}
+
+// ConsumeReader 消费输入中的所有令牌
+func (a *Aggregator) ConsumeReader(r io.Reader) error {
+	tokenizer := NewJSONTokenizer(r)
+	for {
+		token := tokenizer.Next()
+		if err := a.Consume(token); err != nil {
+			return err
+		}
+		if token.Type == TokenEOF {
+			return nil
+		}
+	}
+}
+
+// Documents 返回已经完整消费的文档数量
+func (a *Aggregator) Documents() int64 {
+	return a.documents
+}
+
+// Stats 返回所有路径的统计信息，按路径第一次出现的顺序排列
+func (a *Aggregator) Stats() []*PathStats {
+	result := make([]*PathStats, len(a.order))
+	copy(result, a.order)
+	return result
+}
+
+// Merge 合并另一个Aggregator的统计信息
+func (a *Aggregator) Merge(other *Aggregator) error {
+	for _, stats := range other.order {
+		if err := a.pathStats(stats.Path).merge(stats); err != nil {
+			return err
+		}
+	}
+	a.documents += other.documents
+	return nil
+}
+
+// aggregatorFormat 是检查点的格式，版本0是引入版本头之前直接保存的aggregatorState
+var aggregatorFormat = compat.NewFormat("gojson.aggregator", 1).Register(0, compat.Unchanged)
+
+// aggregatorState 是检查点的序列化格式
+type aggregatorState struct {
+	Documents int64        `json:"documents"`
+	Paths     []*PathStats `json:"paths"`
+}
+
+// MarshalJSON 实现json.Marshaler接口，用于保存带有版本头的检查点
+// 只能在文档之间保存检查点，文档处理到一半时返回错误
+func (a *Aggregator) MarshalJSON() ([]byte, error) {
+	if len(a.stack) > 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "只能在文档之间保存检查点")
+	}
+	paths := a.order
+	if paths == nil {
+		paths = []*PathStats{}
+	}
+	data, err := json.Marshal(aggregatorState{Documents: a.documents, Paths: paths})
+	if err != nil {
+		return nil, err
+	}
+	return aggregatorFormat.Wrap(data)
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，用于从检查点恢复
+// 旧版本的检查点会被迁移，比当前版本新的检查点返回ErrUnsupportedVersion错误
+func (a *Aggregator) UnmarshalJSON(data []byte) error {
+	data, err := aggregatorFormat.Unwrap(data)
+	if err != nil {
+		return err
+	}
+	var state aggregatorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	restored := NewAggregator()
+	restored.documents = state.Documents
+	for _, stats := range state.Paths {
+		if stats == nil {
+			continue
+		}
+		if stats.Types == nil {
+			stats.Types = make(map[string]int64)
+		}
+		restored.stats[stats.Path] = stats
+		restored.order = append(restored.order, stats)
+	}
+	*a = *restored
+	return nil
+}
+
+// pathStats 返回路径的统计信息，不存在时创建
+func (a *Aggregator) pathStats(path string) *PathStats {
+	stats, ok := a.stats[path]
+	if !ok {
+		stats = &PathStats{Path: path, Types: make(map[string]int64)}
+		a.stats[path] = stats
+		a.order = append(a.order, stats)
+	}
+	return stats
+}
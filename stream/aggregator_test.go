@@ -0,0 +1,159 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestAggregator(t *testing.T) {
+	input := `{"items":[{"price":10,"tag":"a"},{"price":30,"tag":"b"},{"price":"n/a","tag":"a"}],"first name":null}
+{"items":[]}`
+
+	a := NewAggregator()
+	if err := a.ConsumeReader(strings.NewReader(input)); err != nil {
+		t.Fatalf("ConsumeReader() error = %v", err)
+	}
+	if a.Documents() != 2 {
+		t.Errorf("Documents() = %d, want 2", a.Documents())
+	}
+
+	stats := make(map[string]*PathStats)
+	var paths []string
+	for _, s := range a.Stats() {
+		stats[s.Path] = s
+		paths = append(paths, s.Path)
+	}
+	wantPaths := "[$ $.items $.items[*] $.items[*].price $.items[*].tag $['first name']]"
+	if fmt.Sprint(paths) != wantPaths {
+		t.Errorf("paths = %v, want %v", paths, wantPaths)
+	}
+
+	price := stats["$.items[*].price"]
+	if price.Count != 3 || price.Types["number"] != 2 || price.Types["string"] != 1 {
+		t.Errorf("price stats = %+v", price)
+	}
+	if price.Min != 10 || price.Max != 30 || price.Mean() != 20 {
+		t.Errorf("price min/max/mean = %v/%v/%v", price.Min, price.Max, price.Mean())
+	}
+	if got := stats["$.items[*].tag"].DistinctStrings(); got != 2 {
+		t.Errorf("tag DistinctStrings() = %d, want 2", got)
+	}
+	if stats["$.items"].Count != 2 || stats["$['first name']"].Types["null"] != 1 {
+		t.Errorf("stats = %+v %+v", stats["$.items"], stats["$['first name']"])
+	}
+}
+
+func TestAggregatorCheckpoint(t *testing.T) {
+	whole := NewAggregator()
+	if err := whole.ConsumeReader(strings.NewReader(`{"n":1,"s":"x"} {"n":5,"s":"y"} {"n":-2,"s":"x"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 处理第一部分后保存检查点，恢复后继续处理剩余部分
+	first := NewAggregator()
+	if err := first.ConsumeReader(strings.NewReader(`{"n":1,"s":"x"} {"n":5,"s":"y"}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	resumed := NewAggregator()
+	if err := json.Unmarshal(data, resumed); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if err := resumed.ConsumeReader(strings.NewReader(`{"n":-2,"s":"x"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 分别处理后合并
+	merged := NewAggregator()
+	second := NewAggregator()
+	second.ConsumeReader(strings.NewReader(`{"n":-2,"s":"x"}`))
+	if err := merged.Merge(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Merge(second); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := json.Marshal(whole)
+	for name, a := range map[string]*Aggregator{"resumed": resumed, "merged": merged} {
+		got, _ := json.Marshal(a)
+		if string(got) != string(want) {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	// 文档处理到一半时不能保存检查点
+	partial := NewAggregator()
+	tokenizer := NewJSONTokenizer(strings.NewReader(`{"a":1}`))
+	partial.Consume(tokenizer.Next())
+	if _, err := json.Marshal(partial); err == nil {
+		t.Error("文档处理到一半时保存检查点应该返回错误")
+	}
+}
+
+func TestAggregatorCheckpointVersion(t *testing.T) {
+	a := NewAggregator()
+	if err := a.ConsumeReader(strings.NewReader(`{"n":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(a)
+	if !strings.HasPrefix(string(data), `{"format":"gojson.aggregator","version":1,"data":{"documents":1,`) {
+		t.Errorf("Marshal() = %s, want a version header", data)
+	}
+
+	// 引入版本头之前保存的检查点仍然可以读取
+	legacy := `{"documents":3,"paths":[{"path":"$","count":3,"types":{"object":3}}]}`
+	restored := NewAggregator()
+	if err := json.Unmarshal([]byte(legacy), restored); err != nil {
+		t.Fatalf("Unmarshal(legacy) error = %v", err)
+	}
+	if restored.Documents() != 3 || restored.Stats()[0].Types["object"] != 3 {
+		t.Errorf("restored = %d documents, %+v", restored.Documents(), restored.Stats())
+	}
+
+	newer := `{"format":"gojson.aggregator","version":99,"data":{}}`
+	err := json.Unmarshal([]byte(newer), restored)
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrUnsupportedVersion {
+		t.Errorf("Unmarshal(newer) error = %v, want UNSUPPORTED_VERSION", err)
+	}
+	if restored.Documents() != 3 {
+		t.Errorf("failed Unmarshal() modified the aggregator")
+	}
+}
+
+func TestAggregatorTruncated(t *testing.T) {
+	if err := NewAggregator().ConsumeReader(strings.NewReader(`{"a":[1,2`)); err == nil {
+		t.Error("截断的输入应该返回错误")
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	h, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{100, 10000, 200000} {
+		for i := 0; i < n; i++ {
+			h.Add(fmt.Sprintf("value-%d", i))
+		}
+		got := float64(h.Count())
+		if got < float64(n)*0.95 || got > float64(n)*1.05 {
+			t.Errorf("Count() = %v, want about %d", got, n)
+		}
+	}
+
+	if _, err := NewHyperLogLog(20); err == nil {
+		t.Error("无效精度应该返回错误")
+	}
+	other, _ := NewHyperLogLog(10)
+	if err := h.Merge(other); err == nil {
+		t.Error("合并精度不同的HyperLogLog应该返回错误")
+	}
+}
@@ -0,0 +1,275 @@
+package stream
+
+import (
+	"bytes"
+	"fmt"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/internal/jsonstr"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ParseValueAtPath 只解析data中路径path处的值，适用于从很大的文档中取出一个字段。
+// 路径使用流式处理的子集但不能包含通配符：$、.name、['name']和非负整数索引[n]。
+// 目标之前的部分逐字节跳过，只检查字符串和括号是否配对，不构建任何值，目标之后的部分不检查；
+// 目标值使用parser完整解析。对象中有重复的键时与ParseBytesToValue相同，使用最后一个。
+// 路径不存在时返回ErrPathNotFound错误，路径上的值类型不对时返回ErrInvalidType错误
+func ParseValueAtPath(data []byte, path string) (types.JSONValue, error) {
+	steps, err := parseStreamPath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if step.wildcard {
+			return nil, jsonerrors.ErrInvalidPathWithDetails(path, "路径不能包含通配符")
+		}
+	}
+
+	data, err = parser.ToUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &pathScanner{data: data}
+	start := s.skipSpace(0)
+	if start == len(data) {
+		return nil, jsonerrors.NewJSONError(ErrEmptyInput, "输入为空")
+	}
+	for i, step := range steps {
+		var found bool
+		if step.isIndex {
+			start, found, err = s.element(start, step.index)
+		} else {
+			start, found, err = s.member(start, step.key)
+		}
+		if err != nil {
+			if jsonErr, ok := err.(*jsonerrors.JSONError); ok && jsonErr.Code == jsonerrors.ErrInvalidType {
+				return nil, jsonErr.WithPath(formatSteps(steps[:i]))
+			}
+			return nil, err
+		}
+		if !found {
+			return nil, jsonerrors.ErrPathNotFoundWithDetails(path)
+		}
+	}
+
+	end, err := s.skipValue(start)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseBytesToValue(data[start:end])
+}
+
+// formatSteps 返回路径前缀的字符串表示
+func formatSteps(steps []pathStep) string {
+	path := "$"
+	for _, step := range steps {
+		if step.isIndex {
+			path = types.ChildPath(path, types.IndexSegment(step.index))
+		} else {
+			path = types.ChildPath(path, types.KeySegment(step.key))
+		}
+	}
+	return path
+}
+
+// pathScanner 在JSON文本中定位值而不解码它们
+type pathScanner struct {
+	data []byte
+}
+
+// member 返回对象中键为key的成员值的起始位置，start是对象的起始位置
+func (s *pathScanner) member(start int, key string) (int, bool, error) {
+	if s.data[start] != '{' {
+		return 0, false, jsonerrors.ErrInvalidTypeWithDetails("object", s.typeAt(start))
+	}
+
+	found, at := false, 0
+	pos := s.skipSpace(start + 1)
+	if pos < len(s.data) && s.data[pos] == '}' {
+		return 0, false, nil
+	}
+	for {
+		if pos >= len(s.data) || s.data[pos] != '"' {
+			return 0, false, s.syntaxError(pos, "应该是属性名")
+		}
+		keyEnd, err := s.skipString(pos)
+		if err != nil {
+			return 0, false, err
+		}
+		match, err := keyEquals(s.data[pos:keyEnd], key)
+		if err != nil {
+			return 0, false, err
+		}
+		pos = s.skipSpace(keyEnd)
+		if pos >= len(s.data) || s.data[pos] != ':' {
+			return 0, false, s.syntaxError(pos, "应该是:")
+		}
+		pos = s.skipSpace(pos + 1)
+		if pos >= len(s.data) {
+			return 0, false, s.syntaxError(pos, "应该是值")
+		}
+		if match {
+			found, at = true, pos
+		}
+
+		end, err := s.skipValue(pos)
+		if err != nil {
+			return 0, false, err
+		}
+		pos = s.skipSpace(end)
+		switch {
+		case pos < len(s.data) && s.data[pos] == ',':
+			pos = s.skipSpace(pos + 1)
+		case pos < len(s.data) && s.data[pos] == '}':
+			return at, found, nil
+		default:
+			return 0, false, s.syntaxError(pos, "应该是,或}")
+		}
+	}
+}
+
+// element 返回数组中第index个元素的起始位置，start是数组的起始位置
+func (s *pathScanner) element(start, index int) (int, bool, error) {
+	if s.data[start] != '[' {
+		return 0, false, jsonerrors.ErrInvalidTypeWithDetails("array", s.typeAt(start))
+	}
+
+	pos := s.skipSpace(start + 1)
+	if pos < len(s.data) && s.data[pos] == ']' {
+		return 0, false, nil
+	}
+	for i := 0; ; i++ {
+		if pos >= len(s.data) {
+			return 0, false, s.syntaxError(pos, "应该是值")
+		}
+		if i == index {
+			return pos, true, nil
+		}
+
+		end, err := s.skipValue(pos)
+		if err != nil {
+			return 0, false, err
+		}
+		pos = s.skipSpace(end)
+		switch {
+		case pos < len(s.data) && s.data[pos] == ',':
+			pos = s.skipSpace(pos + 1)
+		case pos < len(s.data) && s.data[pos] == ']':
+			return 0, false, nil
+		default:
+			return 0, false, s.syntaxError(pos, "应该是,或]")
+		}
+	}
+}
+
+// skipValue 返回从start开始的值之后的位置，容器只检查字符串和括号是否配对
+func (s *pathScanner) skipValue(start int) (int, error) {
+	data := s.data
+	switch data[start] {
+	case '"':
+		return s.skipString(start)
+	case '{', '[':
+		var buf [32]byte
+		stack := buf[:0]
+		for pos := start; pos < len(data); {
+			switch c := data[pos]; c {
+			case '"':
+				end, err := s.skipString(pos)
+				if err != nil {
+					return 0, err
+				}
+				pos = end
+				continue
+			case '{', '[':
+				stack = append(stack, c+2) // '{'+2 == '}'，'['+2 == ']'
+			case '}', ']':
+				if len(stack) == 0 || stack[len(stack)-1] != c {
+					return 0, s.syntaxError(pos, fmt.Sprintf("不应该出现%c", c))
+				}
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					return pos + 1, nil
+				}
+			}
+			pos++
+		}
+		return 0, jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+	case '}', ']', ',', ':':
+		return 0, s.syntaxError(start, "应该是值")
+	}
+
+	// 数字、true、false和null延续到下一个分隔符
+	pos := start
+	for pos < len(data) && !isWhitespace(data[pos]) && !isDelimiter(data[pos]) {
+		pos++
+	}
+	return pos, nil
+}
+
+// isDelimiter 检查c是否是结束数字、true、false和null的分隔符
+func isDelimiter(c byte) bool {
+	return c == ',' || c == ']' || c == '}' || c == ':'
+}
+
+// skipString 返回从start处的双引号开始的字符串之后的位置
+func (s *pathScanner) skipString(start int) (int, error) {
+	data := s.data
+	for pos := start + 1; pos < len(data); pos++ {
+		switch data[pos] {
+		case '\\':
+			pos++
+		case '"':
+			return pos + 1, nil
+		}
+	}
+	return 0, jsonerrors.NewJSONError(ErrInvalidJSON, "字符串没有结束")
+}
+
+// skipSpace 返回从pos开始的第一个非空白字符的位置
+func (s *pathScanner) skipSpace(pos int) int {
+	for pos < len(s.data) && isWhitespace(s.data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// typeAt 根据第一个字符返回从start开始的值的类型
+func (s *pathScanner) typeAt(start int) string {
+	switch c := s.data[start]; {
+	case c == '{':
+		return "object"
+	case c == '[':
+		return "array"
+	case c == '"':
+		return "string"
+	case c == 't' || c == 'f':
+		return "boolean"
+	case c == 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// syntaxError 返回在pos处的语法错误
+func (s *pathScanner) syntaxError(pos int, reason string) error {
+	if pos >= len(s.data) {
+		return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+	}
+	return jsonerrors.ErrInvalidJSONWithDetails(fmt.Sprintf("%s (偏移 %d)", reason, pos))
+}
+
+// keyEquals 检查带引号的属性名raw解码后是否等于key，没有转义时不分配内存
+func keyEquals(raw []byte, key string) (bool, error) {
+	body := raw[1 : len(raw)-1]
+	if bytes.IndexByte(body, '\\') < 0 {
+		return string(body) == key, nil
+	}
+	decoded, err := jsonstr.Unquote(raw)
+	if err != nil {
+		return false, err
+	}
+	return decoded == key, nil
+}
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestParseValueAtPath(t *testing.T) {
+	data := []byte(`{
+		"skip": {"nested": [1, "]}", {"a": "\"{"}], "s": "x\\"},
+		"items": [
+			{"id": 1, "name": "a"},
+			{"id": 2, "name": "b", "tags": ["x", "y"]}
+		],
+		"first name": "张三",
+		"escapedA": true,
+		"dup": 1,
+		"dup": 2,
+		"big": 12345678901234567890
+	}`)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", ""},
+		{"$.items[1].name", `"b"`},
+		{"$.items[1].tags", `["x","y"]`},
+		{"$.items[1].tags[1]", `"y"`},
+		{"$.items[0]", `{"id":1,"name":"a"}`},
+		{"$['first name']", `"张三"`},
+		{"$.escapedA", "true"},
+		{"$.dup", "2"},
+		{"$.skip.s", `"x\\"`},
+	}
+	for _, tt := range tests {
+		value, err := ParseValueAtPath(data, tt.path)
+		if err != nil {
+			t.Fatalf("ParseValueAtPath(%s) error = %v", tt.path, err)
+		}
+		if tt.want != "" && value.String() != tt.want {
+			t.Errorf("ParseValueAtPath(%s) = %s, want %s", tt.path, value.String(), tt.want)
+		}
+	}
+
+	// 与完整解析的结果相同
+	whole, _ := parser.ParseBytesToValue(data)
+	root, err := ParseValueAtPath(data, "$")
+	if err != nil || root.String() != whole.String() {
+		t.Errorf("ParseValueAtPath($) = %v, %v", root, err)
+	}
+	big, _ := ParseValueAtPath(data, "$.big")
+	if wholeObj, _ := whole.AsObject(); big.String() != wholeObj.Get("big").String() {
+		t.Errorf("ParseValueAtPath($.big) = %s, want %s", big.String(), wholeObj.Get("big").String())
+	}
+}
+
+func TestParseValueAtPathErrors(t *testing.T) {
+	data := []byte(`{"a": {"b": [1, 2]}, "s": "x"}`)
+
+	tests := []struct {
+		path string
+		code jsonerrors.ErrorCode
+	}{
+		{"$.missing", jsonerrors.ErrPathNotFound},
+		{"$.a.b[5]", jsonerrors.ErrPathNotFound},
+		{"$.a.b.c", jsonerrors.ErrInvalidType},
+		{"$.s[0]", jsonerrors.ErrInvalidType},
+		{"$.a[*]", jsonerrors.ErrInvalidPath},
+		{"a.b", jsonerrors.ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		_, err := ParseValueAtPath(data, tt.path)
+		if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != tt.code {
+			t.Errorf("ParseValueAtPath(%s) error = %v, want %s", tt.path, err, tt.code)
+		}
+	}
+
+	_, err := ParseValueAtPath(data, "$.a.b.c")
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Path != "$.a.b" {
+		t.Errorf("ParseValueAtPath($.a.b.c) error path = %v, want $.a.b", err)
+	}
+
+	for _, input := range []string{``, `{"a": [1, 2}`, `{"a" 1}`, `{"x": "unterminated`, `{"x": {"y": 1]}, "a": 1}`, `{"a": [1 2, 3]}`} {
+		_, err := ParseValueAtPath([]byte(input), "$.a[1]")
+		if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || (jsonErr.Code != jsonerrors.ErrInvalidJSON && jsonErr.Code != jsonerrors.ErrEmptyInput) {
+			t.Errorf("ParseValueAtPath(%q) error = %v, want INVALID_JSON", input, err)
+		}
+	}
+
+	// 目标值本身是完整解析的
+	if _, err := ParseValueAtPath([]byte(`{"a": [1, tru]}`), "$.a[1]"); err == nil {
+		t.Errorf("ParseValueAtPath() 应该拒绝无效的目标值")
+	}
+}
+
+func BenchmarkParseValueAtPath(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"items":[`)
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`{"id":1,"name":"item","tags":["a","b","c"],"price":12.5}`)
+	}
+	sb.WriteString(`],"total":10000}`)
+	data := []byte(sb.String())
+
+	b.Run("AtPath", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseValueAtPath(data, "$.total"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ParseBytesToValue(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
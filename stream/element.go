@@ -0,0 +1,362 @@
+package stream
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// pathStep 是流式路径中的一段，key为空且index为-1时匹配任意成员
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseStreamPath 解析流式处理支持的JSON Path子集：
+// $、.name、['name']、[n]、.*和[*]
+func parseStreamPath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, jsonerrors.ErrInvalidPathWithDetails(path, "路径必须以$开头")
+	}
+
+	var steps []pathStep
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			steps = append(steps, pathStep{wildcard: true})
+			rest = rest[2:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, jsonerrors.ErrInvalidPathWithDetails(path, "属性名不能为空")
+			}
+			steps = append(steps, pathStep{key: name})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "[*]"):
+			steps = append(steps, pathStep{wildcard: true})
+			rest = rest[3:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, "[\""):
+			quote := rest[1]
+			end := strings.IndexByte(rest[2:], quote)
+			if end < 0 || len(rest) < end+4 || rest[end+3] != ']' {
+				return nil, jsonerrors.ErrInvalidPathWithDetails(path, "未闭合的属性名")
+			}
+			steps = append(steps, pathStep{key: rest[2 : end+2]})
+			rest = rest[end+4:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, jsonerrors.ErrInvalidPathWithDetails(path, "未闭合的索引")
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, jsonerrors.ErrInvalidPathWithDetails(path, "流式处理只支持非负整数索引、通配符和属性名")
+			}
+			steps = append(steps, pathStep{index: index, isIndex: true})
+			rest = rest[end+1:]
+		default:
+			return nil, jsonerrors.ErrInvalidPathWithDetails(path, "无效的路径字符")
+		}
+	}
+	return steps, nil
+}
+
+// ValidateStreamPath 检查路径是否在流式处理支持的JSON Path子集中：
+// $、.name、['name']、非负整数索引[n]、.*和[*]
+func ValidateStreamPath(path string) error {
+	_, err := parseStreamPath(path)
+	return err
+}
+
+// elementFrame 记录一层容器中的当前位置
+type elementFrame struct {
+	isArray bool
+	key     string
+	index   int // 当前元素的索引
+	next    int // 下一个元素的索引
+}
+
+// ElementReader 从JSON流中逐个读取与路径匹配的值。
+// 只有匹配的值会被完整构建，其余部分边读边丢弃，
+// 因此可以用固定的内存处理大型文档中的数组元素。
+type ElementReader struct {
+	tokenizer *JSONTokenizer
+	steps     []pathStep
+	stack     []elementFrame
+	path      string
+	segments  []types.PathSegment
+	done      bool
+	strict    bool
+}
+
+// NewElementReader 创建读取与路径匹配的值的读取器，路径例如"$.items[*]"
+func NewElementReader(r io.Reader, path string) (*ElementReader, error) {
+	steps, err := parseStreamPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ElementReader{tokenizer: NewJSONTokenizer(r), steps: steps}, nil
+}
+
+// NewStrictElementReader 与NewElementReader相同，但路径经过的值类型不符时返回ErrInvalidType错误，
+// 例如对数组访问属性、对字符串使用通配符，与jsonpath在JSONValue上查询的行为一致。
+// NewElementReader跳过这样的值
+func NewStrictElementReader(r io.Reader, path string) (*ElementReader, error) {
+	e, err := NewElementReader(r, path)
+	if err != nil {
+		return nil, err
+	}
+	e.strict = true
+	return e, nil
+}
+
+// Next 返回下一个匹配的值，没有更多值时返回io.EOF
+func (e *ElementReader) Next() (types.JSONValue, error) {
+	if e.done {
+		return nil, io.EOF
+	}
+
+	for {
+		token := e.tokenizer.Next()
+		switch token.Type {
+		case TokenEOF:
+			e.done = true
+			if len(e.stack) > 0 {
+				return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+			}
+			return nil, io.EOF
+		case TokenError:
+			e.done = true
+			return nil, token.Error
+		case TokenPropertyName:
+			if len(e.stack) > 0 {
+				e.stack[len(e.stack)-1].key = token.Value.(string)
+			}
+			continue
+		case TokenObjectEnd, TokenArrayEnd:
+			if len(e.stack) == 0 {
+				e.done = true
+				return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "多余的结束符")
+			}
+			e.stack = e.stack[:len(e.stack)-1]
+			continue
+		}
+
+		// 值的开始，先确定它在数组中的索引
+		if len(e.stack) > 0 {
+			top := &e.stack[len(e.stack)-1]
+			if top.isArray {
+				top.index = top.next
+				top.next++
+			}
+		}
+
+		if e.strict {
+			if err := e.checkType(token); err != nil {
+				e.done = true
+				return nil, err
+			}
+		}
+
+		if e.matches() {
+			e.path = e.currentPath()
+			e.segments = currentSegments(e.stack)
+			value, err := e.buildValue(token)
+			if err != nil {
+				e.done = true
+				return nil, err
+			}
+			return value, nil
+		}
+
+		switch token.Type {
+		case TokenObjectStart:
+			e.stack = append(e.stack, elementFrame{})
+		case TokenArrayStart:
+			e.stack = append(e.stack, elementFrame{isArray: true})
+		}
+	}
+}
+
+// Path 返回最近一次Next返回的值的路径
+func (e *ElementReader) Path() string {
+	return e.path
+}
+
+// Segments 返回最近一次Next返回的值的路径中的各段
+func (e *ElementReader) Segments() []types.PathSegment {
+	return e.segments
+}
+
+// matches 检查当前位置是否与路径匹配
+func (e *ElementReader) matches() bool {
+	return matchPath(e.steps, e.stack)
+}
+
+// checkType 检查路径经过的值的类型，值的位置与路径的前缀匹配时，它必须是下一段路径能访问的容器
+func (e *ElementReader) checkType(token JSONToken) error {
+	depth := len(e.stack)
+	if depth >= len(e.steps) || !matchPath(e.steps[:depth], e.stack) {
+		return nil
+	}
+	step := e.steps[depth]
+	switch {
+	case step.wildcard:
+		if token.Type != TokenObjectStart && token.Type != TokenArrayStart {
+			return jsonerrors.ErrInvalidTypeWithDetails("object or array", tokenValueType(token))
+		}
+	case step.isIndex:
+		if token.Type != TokenArrayStart {
+			return jsonerrors.ErrInvalidTypeWithDetails("array", tokenValueType(token))
+		}
+	default:
+		if token.Type != TokenObjectStart {
+			return jsonerrors.ErrInvalidTypeWithDetails("object", tokenValueType(token))
+		}
+	}
+	return nil
+}
+
+// tokenValueType 返回以令牌开始的值的类型名称，与JSONValue.Type相同
+func tokenValueType(token JSONToken) string {
+	switch token.Type {
+	case TokenObjectStart:
+		return "object"
+	case TokenArrayStart:
+		return "array"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBoolean:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// currentPath 返回当前位置的路径
+func (e *ElementReader) currentPath() string {
+	return formatPath(e.stack)
+}
+
+// matchPath 检查容器栈表示的位置是否与路径匹配
+func matchPath(steps []pathStep, stack []elementFrame) bool {
+	if len(stack) != len(steps) {
+		return false
+	}
+	for i, step := range steps {
+		frame := stack[i]
+		switch {
+		case step.wildcard:
+		case step.isIndex:
+			if !frame.isArray || frame.index != step.index {
+				return false
+			}
+		default:
+			if frame.isArray || frame.key != step.key {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// formatPath 返回容器栈表示的位置的路径
+func formatPath(stack []elementFrame) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, frame := range stack {
+		if frame.isArray {
+			sb.WriteString("[" + strconv.Itoa(frame.index) + "]")
+		} else {
+			sb.WriteString("['" + frame.key + "']")
+		}
+	}
+	return sb.String()
+}
+
+// currentSegments 返回容器栈表示的位置中的各段
+func currentSegments(stack []elementFrame) []types.PathSegment {
+	segments := make([]types.PathSegment, len(stack))
+	for i, frame := range stack {
+		if frame.isArray {
+			segments[i] = types.IndexSegment(frame.index)
+		} else {
+			segments[i] = types.KeySegment(frame.key)
+		}
+	}
+	return segments
+}
+
+// buildValue 从当前令牌开始构建完整的值
+func (e *ElementReader) buildValue(token JSONToken) (types.JSONValue, error) {
+	switch token.Type {
+	case TokenObjectStart:
+		obj := types.NewJSONObject()
+		for {
+			next := e.tokenizer.Next()
+			switch next.Type {
+			case TokenObjectEnd:
+				return obj, nil
+			case TokenPropertyName:
+				value, err := e.buildValue(e.tokenizer.Next())
+				if err != nil {
+					return nil, err
+				}
+				obj.Put(next.Value.(string), value)
+			default:
+				return nil, unexpectedToken(next)
+			}
+		}
+	case TokenArrayStart:
+		values := make([]types.JSONValue, 0)
+		for {
+			next := e.tokenizer.Next()
+			if next.Type == TokenArrayEnd {
+				return types.NewJSONArrayFromValuesUnsafe(values), nil
+			}
+			value, err := e.buildValue(next)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	case TokenString:
+		return types.NewJSONString(token.Value.(string)), nil
+	case TokenNumber:
+		n, err := token.Value.(json.Number).Float64()
+		if err != nil {
+			return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "无效的数字").WithCause(err)
+		}
+		return types.NewJSONNumber(n), nil
+	case TokenBoolean:
+		return types.NewJSONBool(token.Value.(bool)), nil
+	case TokenNull:
+		return types.NewJSONNull(), nil
+	default:
+		return nil, unexpectedToken(token)
+	}
+}
+
+// unexpectedToken 创建意外令牌错误
+func unexpectedToken(token JSONToken) error {
+	if token.Type == TokenError {
+		return token.Error
+	}
+	if token.Type == TokenEOF {
+		return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+	}
+	return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的令牌")
+}
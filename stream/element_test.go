@@ -0,0 +1,154 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// readAll 读取所有匹配的值并返回其字符串表示和路径
+func readAll(t *testing.T, input, path string) ([]string, []string) {
+	t.Helper()
+	reader, err := NewElementReader(strings.NewReader(input), path)
+	if err != nil {
+		t.Fatalf("NewElementReader(%q) error = %v", path, err)
+	}
+	var values, paths []string
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			return values, paths
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		values = append(values, value.String())
+		paths = append(paths, reader.Path())
+	}
+}
+
+func TestElementReader(t *testing.T) {
+	input := `{"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]},{"id":3}],"meta":{"total":3}}`
+
+	tests := []struct {
+		path  string
+		want  []string
+		paths []string
+	}{
+		{"$.items[*]", []string{`{"id":1,"tags":["a"]}`, `{"id":2,"tags":[]}`, `{"id":3}`},
+			[]string{"$['items'][0]", "$['items'][1]", "$['items'][2]"}},
+		{"$.items[*].id", []string{"1", "2", "3"}, nil},
+		{"$.items[1]", []string{`{"id":2,"tags":[]}`}, nil},
+		{"$['meta'].total", []string{"3"}, nil},
+		{"$.*", []string{`[{"id":1,"tags":["a"]},{"id":2,"tags":[]},{"id":3}]`, `{"total":3}`}, nil},
+		{"$.missing[*]", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, paths := readAll(t, input, tt.path)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			if tt.paths != nil && !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestElementReaderRoot(t *testing.T) {
+	got, _ := readAll(t, `[1, "two", null]`, "$")
+	if len(got) != 1 || got[0] != `[1,"two",null]` {
+		t.Errorf("values = %v", got)
+	}
+}
+
+func TestStrictElementReader(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+		ok    bool
+	}{
+		{`{"items":[{"id":1},{"name":"x"}]}`, "$.items[*].id", true},
+		{`{"items":[{"id":1},2]}`, "$.items[*].id", false},
+		{`{"items":{"id":1}}`, "$.items[0]", false},
+		{`{"items":"text"}`, "$.items.*", false},
+		{`[1]`, "$.a", false},
+		// 不在路径上的值不检查
+		{`{"other":1,"items":[[1]]}`, "$.items[*][0]", true},
+	}
+	for _, tt := range tests {
+		reader, err := NewStrictElementReader(strings.NewReader(tt.input), tt.path)
+		if err != nil {
+			t.Fatalf("NewStrictElementReader(%q) error = %v", tt.path, err)
+		}
+		for err == nil {
+			_, err = reader.Next()
+		}
+		if tt.ok && err != io.EOF {
+			t.Errorf("%s on %s: error = %v", tt.path, tt.input, err)
+		}
+		var jsonErr *jsonerrors.JSONError
+		if !tt.ok && (!errors.As(err, &jsonErr) || jsonErr.Code != jsonerrors.ErrInvalidType) {
+			t.Errorf("%s on %s: error = %v, want INVALID_TYPE", tt.path, tt.input, err)
+		}
+	}
+}
+
+func TestElementReaderInvalidPath(t *testing.T) {
+	for _, path := range []string{"items", "$..name", "$.items[?(@.id)]", "$.items[-1]", "$['a"} {
+		if _, err := NewElementReader(strings.NewReader("{}"), path); err == nil {
+			t.Errorf("NewElementReader(%q) 应该返回错误", path)
+		}
+	}
+}
+
+func TestElementReaderTruncated(t *testing.T) {
+	reader, err := NewElementReader(strings.NewReader(`{"items":[{"id":1},{"id":`), "$.items[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); err != nil {
+		t.Fatalf("第一个元素应该完整, error = %v", err)
+	}
+	if _, err := reader.Next(); err == nil || err == io.EOF {
+		t.Errorf("截断的输入应该返回错误, got %v", err)
+	}
+}
+
+func TestElementReaderEncodings(t *testing.T) {
+	// UTF-16LE编码并带有BOM的 [{"a":"é"},2]
+	var input []byte
+	for _, r := range "\ufeff" + `[{"a":"é"},2]` {
+		input = append(input, byte(r), byte(r>>8))
+	}
+	reader, err := NewElementReader(bytes.NewReader(input), "$[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for {
+		value, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		values = append(values, value.String())
+	}
+	if want := []string{`{"a":"é"}`, "2"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+
+	values, _ = readAll(t, "\ufeff[1,2]", "$[*]")
+	if !reflect.DeepEqual(values, []string{"1", "2"}) {
+		t.Errorf("UTF-8 BOM: values = %v", values)
+	}
+}
@@ -0,0 +1,359 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Framing 表示消息流中分隔JSON消息的方式
+type Framing int
+
+const (
+	// FramingConcatenated 表示直接连接的JSON文档，文档之间可以有空白
+	FramingConcatenated Framing = iota
+	// FramingNewline 表示每行一个JSON文档（NDJSON/JSON Lines），空行被忽略
+	FramingNewline
+	// FramingUvarint 表示每条消息前是无符号varint编码的长度
+	FramingUvarint
+	// FramingUint32 表示每条消息前是4字节大端序的长度
+	FramingUint32
+)
+
+// framingNames 是分帧方式的名称，用于命令行参数和配置
+var framingNames = []string{"concat", "ndjson", "varint", "uint32"}
+
+// String 返回分帧方式的名称
+func (f Framing) String() string {
+	if f < 0 || int(f) >= len(framingNames) {
+		return fmt.Sprintf("Framing(%d)", int(f))
+	}
+	return framingNames[f]
+}
+
+// ParseFraming 根据名称返回分帧方式，名称为concat、ndjson、varint或uint32
+func ParseFraming(name string) (Framing, error) {
+	for i, n := range framingNames {
+		if n == name {
+			return Framing(i), nil
+		}
+	}
+	return 0, jsonerrors.NewJSONError(jsonerrors.ErrNotSupported, "未知的分帧方式: "+name)
+}
+
+// DefaultMaxMessageSize 是读取消息时默认的最大字节数
+const DefaultMaxMessageSize = 64 << 20
+
+// FramingOptions 定义读取消息的选项
+type FramingOptions struct {
+	// MaxMessageSize 是一条消息的最大字节数，为0时使用DefaultMaxMessageSize。
+	// 直接连接的文档在读取超过上限的输入后就返回错误，不会把整个文档读入内存
+	MaxMessageSize int
+}
+
+// MessageReader 逐条读取消息流中的JSON消息，没有更多消息时返回io.EOF。
+// 返回的字节切片属于调用者，不会被之后的读取覆盖
+type MessageReader interface {
+	ReadMessage() ([]byte, error)
+}
+
+// MessageWriter 逐条写入JSON消息，每条消息用一次Write写入底层的Writer，
+// 因此写入网络连接时不需要额外的缓冲和刷新
+type MessageWriter interface {
+	WriteMessage(data []byte) error
+}
+
+// NewMessageReader 创建按framing分帧读取消息的MessageReader。
+// 文本格式（直接连接和按行）的输入开头的BOM被忽略，UTF-16和UTF-32编码的输入被转换为UTF-8
+func NewMessageReader(r io.Reader, framing Framing, opts FramingOptions) MessageReader {
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = DefaultMaxMessageSize
+	}
+	switch framing {
+	case FramingNewline:
+		return &lineReader{reader: bufio.NewReader(parser.NewUTF8Reader(r)), max: opts.MaxMessageSize}
+	case FramingUvarint, FramingUint32:
+		return &lengthReader{reader: bufio.NewReader(r), framing: framing, max: opts.MaxMessageSize}
+	default:
+		budget := &budgetReader{reader: parser.NewUTF8Reader(r)}
+		return &concatReader{decoder: json.NewDecoder(budget), budget: budget, max: opts.MaxMessageSize}
+	}
+}
+
+// NewMessageWriter 创建按framing分帧写入消息的MessageWriter
+func NewMessageWriter(w io.Writer, framing Framing) MessageWriter {
+	switch framing {
+	case FramingNewline:
+		return &lineWriter{writer: w}
+	case FramingUvarint, FramingUint32:
+		return &lengthWriter{writer: w, framing: framing}
+	default:
+		return &concatWriter{writer: w}
+	}
+}
+
+// WriteValue 把JSON值作为一条消息写入
+func WriteValue(w MessageWriter, value types.JSONValue) error {
+	if value == nil {
+		value = types.NewJSONNull()
+	}
+	return w.WriteMessage([]byte(value.String()))
+}
+
+// MessageSource 把MessageReader读取的每条消息解析为JSON值，实现ValueSource接口
+type MessageSource struct {
+	reader   MessageReader
+	messages int
+}
+
+// NewMessageSource 创建解析消息的数据源
+func NewMessageSource(reader MessageReader) *MessageSource {
+	return &MessageSource{reader: reader}
+}
+
+// Next 返回下一条消息解析后的值，没有更多消息时返回io.EOF
+func (m *MessageSource) Next() (types.JSONValue, error) {
+	data, err := m.reader.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parser.ParseBytesToValue(data)
+	if err != nil {
+		return nil, jsonerrors.NewJSONError(ErrInvalidJSON, fmt.Sprintf("解析第%d条消息失败", m.messages+1)).WithCause(err)
+	}
+	m.messages++
+	return value, nil
+}
+
+// concatReader 读取直接连接的JSON文档
+type concatReader struct {
+	decoder *json.Decoder
+	budget  *budgetReader
+	max     int
+}
+
+func (c *concatReader) ReadMessage() ([]byte, error) {
+	// 文档前的空白之后最多再读取max+1字节（数字需要多读一个字节才能结束），
+	// 加上解码器中已经缓冲的部分，内存不超过两倍的上限
+	c.budget.reset(c.max + 1)
+	var raw json.RawMessage
+	if err := c.decoder.Decode(&raw); err != nil {
+		switch err {
+		case io.EOF:
+			return nil, io.EOF
+		case errBudgetExhausted:
+			return nil, messageTooLarge(c.max)
+		}
+		return nil, jsonerrors.NewJSONError(ErrInvalidJSON, "读取消息失败").WithCause(err)
+	}
+	if len(raw) > c.max {
+		return nil, messageTooLarge(c.max)
+	}
+	return raw, nil
+}
+
+// errBudgetExhausted 表示读取的字节数超过了budgetReader的余量
+var errBudgetExhausted = errors.New("stream: 超过读取余量")
+
+// budgetReader 限制两次重置之间读取的字节数，重置后开头的空白不计入。
+// 余量用完后读取返回errBudgetExhausted
+type budgetReader struct {
+	reader    io.Reader
+	remaining int
+	leading   bool // 是否还在读取开头的空白
+}
+
+// reset 重新设置余量
+func (b *budgetReader) reset(remaining int) {
+	b.remaining = remaining
+	b.leading = true
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, errBudgetExhausted
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.reader.Read(p)
+	counted := n
+	if b.leading {
+		space := len(p[:n]) - len(bytes.TrimLeft(p[:n], " \t\r\n"))
+		b.leading = space == n
+		counted -= space
+	}
+	b.remaining -= counted
+	return n, err
+}
+
+// lineReader 读取每行一个的JSON文档
+type lineReader struct {
+	reader *bufio.Reader
+	max    int
+}
+
+func (l *lineReader) ReadMessage() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := l.reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(bytes.TrimSpace(line)) > l.max {
+			return nil, messageTooLarge(l.max)
+		}
+
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err != nil && err != io.EOF:
+			return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取消息失败").WithCause(err)
+		}
+
+		if message := bytes.TrimSpace(line); len(message) > 0 {
+			return message, nil
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		line = line[:0]
+	}
+}
+
+// lengthReader 读取带有长度前缀的消息
+type lengthReader struct {
+	reader  *bufio.Reader
+	framing Framing
+	max     int
+}
+
+func (l *lengthReader) ReadMessage() ([]byte, error) {
+	var size uint64
+	if l.framing == FramingUvarint {
+		n, err := binary.ReadUvarint(l.reader)
+		if err != nil {
+			return nil, truncated(err)
+		}
+		size = n
+	} else {
+		var prefix [4]byte
+		if _, err := io.ReadFull(l.reader, prefix[:]); err != nil {
+			return nil, truncated(err)
+		}
+		size = uint64(binary.BigEndian.Uint32(prefix[:]))
+	}
+
+	if size > uint64(l.max) {
+		return nil, messageTooLarge(l.max)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(l.reader, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, truncated(err)
+	}
+	return data, nil
+}
+
+// truncated 把读取长度前缀或消息时的错误转换为JSONError，消息之间的io.EOF原样返回
+func truncated(err error) error {
+	switch err {
+	case io.EOF:
+		return io.EOF
+	case io.ErrUnexpectedEOF:
+		return jsonerrors.NewJSONError(ErrInvalidJSON, "消息不完整").WithCause(err)
+	default:
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "读取消息失败").WithCause(err)
+	}
+}
+
+// messageTooLarge 返回消息超过长度上限的错误
+func messageTooLarge(max int) error {
+	return jsonerrors.NewJSONError(jsonerrors.ErrBudgetExceeded, fmt.Sprintf("消息超过%d字节的上限", max))
+}
+
+// writeError 把写入消息时的错误转换为JSONError
+func writeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "写入消息失败").WithCause(err)
+}
+
+// concatWriter 直接连接写入的JSON文档
+type concatWriter struct {
+	writer io.Writer
+	last   byte // 上一条消息的最后一个字节
+}
+
+func (c *concatWriter) WriteMessage(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return jsonerrors.NewJSONError(ErrEmptyInput, "消息为空")
+	}
+	// 两个相邻的数字或字面量之间需要空白，否则会被读作一个值
+	if isScalarByte(c.last) && isScalarByte(data[0]) {
+		data = append([]byte{' '}, data...)
+	}
+	c.last = data[len(data)-1]
+	_, err := c.writer.Write(data)
+	return writeError(err)
+}
+
+// isScalarByte 检查字节是否可以出现在数字或true、false、null的开头或结尾
+func isScalarByte(c byte) bool {
+	return c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// lineWriter 每行写入一个JSON文档
+type lineWriter struct {
+	writer io.Writer
+}
+
+func (l *lineWriter) WriteMessage(data []byte) error {
+	line := make([]byte, 0, len(data)+1)
+	if bytes.ContainsAny(data, "\r\n") {
+		// 格式化过的JSON需要压缩为一行
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return jsonerrors.NewJSONError(ErrInvalidJSON, "压缩消息失败").WithCause(err)
+		}
+		line = append(line, buf.Bytes()...)
+	} else {
+		line = append(line, bytes.TrimSpace(data)...)
+	}
+	if len(line) == 0 {
+		return jsonerrors.NewJSONError(ErrEmptyInput, "消息为空")
+	}
+	_, err := l.writer.Write(append(line, '\n'))
+	return writeError(err)
+}
+
+// lengthWriter 写入带有长度前缀的消息
+type lengthWriter struct {
+	writer  io.Writer
+	framing Framing
+}
+
+func (l *lengthWriter) WriteMessage(data []byte) error {
+	frame := make([]byte, 0, len(data)+binary.MaxVarintLen64)
+	if l.framing == FramingUvarint {
+		frame = binary.AppendUvarint(frame, uint64(len(data)))
+	} else {
+		if uint64(len(data)) > math.MaxUint32 {
+			return jsonerrors.NewJSONError(jsonerrors.ErrBudgetExceeded, "消息超过4字节长度前缀能表示的上限")
+		}
+		frame = binary.BigEndian.AppendUint32(frame, uint32(len(data)))
+	}
+	_, err := l.writer.Write(append(frame, data...))
+	return writeError(err)
+}
@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// readMessages 读取所有消息直到io.EOF
+func readMessages(t *testing.T, reader MessageReader) []string {
+	t.Helper()
+	var messages []string
+	for {
+		data, err := reader.ReadMessage()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v", err)
+		}
+		messages = append(messages, string(data))
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	messages := []string{`{"a":1}`, `1`, `2`, `true`, `"x"`, `[null]`}
+	for _, framing := range []Framing{FramingConcatenated, FramingNewline, FramingUvarint, FramingUint32} {
+		t.Run(framing.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := NewMessageWriter(&buf, framing)
+			for _, m := range messages {
+				if err := writer.WriteMessage([]byte(m)); err != nil {
+					t.Fatalf("WriteMessage(%s) error = %v", m, err)
+				}
+			}
+
+			got := readMessages(t, NewMessageReader(&buf, framing, FramingOptions{}))
+			if strings.Join(got, "|") != strings.Join(messages, "|") {
+				t.Errorf("消息 = %q, 期望 %q", got, messages)
+			}
+		})
+	}
+}
+
+func TestMessageReaderText(t *testing.T) {
+	got := readMessages(t, NewMessageReader(strings.NewReader("\xef\xbb\xbf{\"a\":1}\n\n  [2] \r\n3"), FramingNewline, FramingOptions{}))
+	if strings.Join(got, "|") != `{"a":1}|[2]|3` {
+		t.Errorf("按行读取的消息 = %q", got)
+	}
+
+	got = readMessages(t, NewMessageReader(strings.NewReader(`{"a":1}[2] 3 "x"`), FramingConcatenated, FramingOptions{}))
+	if strings.Join(got, "|") != `{"a":1}|[2]|3|"x"` {
+		t.Errorf("直接连接的消息 = %q", got)
+	}
+
+	var buf bytes.Buffer
+	if err := NewMessageWriter(&buf, FramingNewline).WriteMessage([]byte("{\n  \"a\": 1\n}")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if buf.String() != "{\"a\":1}\n" {
+		t.Errorf("格式化的消息应该压缩为一行, 得到 %q", buf.String())
+	}
+}
+
+func TestMessageReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	NewMessageWriter(&buf, FramingUint32).WriteMessage([]byte(`{"a":1}`))
+	frame := buf.Bytes()
+
+	_, err := NewMessageReader(bytes.NewReader(frame[:len(frame)-2]), FramingUint32, FramingOptions{}).ReadMessage()
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != ErrInvalidJSON {
+		t.Errorf("不完整的消息应该返回ErrInvalidJSON, 得到 %v", err)
+	}
+
+	_, err = NewMessageReader(bytes.NewReader(frame), FramingUint32, FramingOptions{MaxMessageSize: 4}).ReadMessage()
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded {
+		t.Errorf("超过上限的消息应该返回ErrBudgetExceeded, 得到 %v", err)
+	}
+
+	_, err = NewMessageReader(strings.NewReader("[1,2,3,4,5]\n"), FramingNewline, FramingOptions{MaxMessageSize: 4}).ReadMessage()
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded {
+		t.Errorf("超过上限的行应该返回ErrBudgetExceeded, 得到 %v", err)
+	}
+
+	// 直接连接的文档在读完之前就返回错误，之前的文档不受影响
+	reader := NewMessageReader(io.MultiReader(strings.NewReader(`{"a":1} `), strings.NewReader("["+strings.Repeat("1,", 1<<20)+"1]")),
+		FramingConcatenated, FramingOptions{MaxMessageSize: 16})
+	if data, err := reader.ReadMessage(); err != nil || string(data) != `{"a":1}` {
+		t.Errorf("ReadMessage() = %s, %v", data, err)
+	}
+	_, err = reader.ReadMessage()
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded {
+		t.Errorf("超过上限的文档应该返回ErrBudgetExceeded, 得到 %v", err)
+	}
+
+	// 恰好等于上限的文档和其后的文档都可以读取
+	reader = NewMessageReader(strings.NewReader(`[1,2,3,4]`+strings.Repeat("\n", 100)+`[5,6,7,8] 123456789 [9]`),
+		FramingConcatenated, FramingOptions{MaxMessageSize: 9})
+	if got := readMessages(t, reader); strings.Join(got, " ") != "[1,2,3,4] [5,6,7,8] 123456789 [9]" {
+		t.Errorf("messages = %v", got)
+	}
+
+	if _, err := ParseFraming("xml"); err == nil {
+		t.Error("ParseFraming(xml)应该返回错误")
+	}
+	if f, err := ParseFraming("varint"); err != nil || f != FramingUvarint {
+		t.Errorf("ParseFraming(varint) = %v, %v", f, err)
+	}
+}
+
+func TestMessageSource(t *testing.T) {
+	source := NewMessageSource(NewMessageReader(strings.NewReader("1\n{\"a\":2}\n[oops\n"), FramingNewline, FramingOptions{}))
+	for i := 0; i < 2; i++ {
+		if _, err := source.Next(); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+	}
+	_, err := source.Next()
+	if err == nil || !strings.Contains(err.Error(), "第3条消息") {
+		t.Errorf("无效的消息应该返回带有序号的错误, 得到 %v", err)
+	}
+}
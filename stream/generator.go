@@ -1,20 +1,25 @@
 package stream
 
 import (
-	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"strconv"
 	"sync"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/internal/jsonstr"
 )
 
 // JSONGenerator 是JSON流式生成器
 type JSONGenerator struct {
-	writer     *bufio.Writer
+	writer     *generatorBuffer
+	opts       GeneratorOptions
+	values     int
 	depth      int
 	states     []generatorState
 	needComma  bool
+	quoted     []byte // 转义字符串时复用的缓冲区
 	err        error
 	writeMutex sync.Mutex
 }
@@ -31,10 +36,38 @@ const (
 	stateArray
 )
 
+// DefaultFlushBytes 是生成器默认的自动刷新阈值，与bufio.Writer的默认缓冲区大小相同
+const DefaultFlushBytes = 4096
+
+// GeneratorOptions 定义生成器的缓冲和刷新策略
+type GeneratorOptions struct {
+	// FlushBytes 是缓冲的字节数达到多少时自动写入底层的Writer，为0时使用DefaultFlushBytes
+	FlushBytes int
+	// FlushValues 是每写完多少个顶层值或顶层容器中的元素后自动刷新，为0时不按值刷新。
+	// 流式HTTP响应中设置为1可以让客户端及时收到每个元素
+	FlushValues int
+	// MaxBuffered 是缓冲区的字节数上限，为0时不限制。
+	// 底层的Writer返回超时错误时（例如设置了写入截止时间的网络连接），未写出的数据留在缓冲区中，
+	// 之后的写入会超过上限时返回ErrBudgetExceeded错误，而不是无限制地缓冲
+	MaxBuffered int
+}
+
 // NewJSONGenerator 创建一个新的JSON流式生成器
 func NewJSONGenerator(w io.Writer) *JSONGenerator {
+	return NewJSONGeneratorWithOptions(w, GeneratorOptions{})
+}
+
+// NewJSONGeneratorWithOptions 创建按opts缓冲和刷新的JSON流式生成器
+func NewJSONGeneratorWithOptions(w io.Writer, opts GeneratorOptions) *JSONGenerator {
+	if opts.FlushBytes <= 0 {
+		opts.FlushBytes = DefaultFlushBytes
+	}
+	if opts.MaxBuffered > 0 && opts.FlushBytes > opts.MaxBuffered {
+		opts.FlushBytes = opts.MaxBuffered
+	}
 	return &JSONGenerator{
-		writer: bufio.NewWriter(w),
+		writer: &generatorBuffer{sink: w, flushBytes: opts.FlushBytes, maxBuffered: opts.MaxBuffered},
+		opts:   opts,
 		depth:  0,
 		states: make([]generatorState, 0, 10),
 	}
@@ -88,7 +121,7 @@ func (g *JSONGenerator) EndObject() error {
 	g.depth--
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // BeginArray 开始一个新的数组
@@ -139,7 +172,7 @@ func (g *JSONGenerator) EndArray() error {
 	g.depth--
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // WriteProperty 写入一个属性名
@@ -198,7 +231,7 @@ func (g *JSONGenerator) WriteString(value string) error {
 
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // WriteNumber 写入一个数字值
@@ -221,13 +254,12 @@ func (g *JSONGenerator) WriteNumber(value float64) error {
 
 	// 写入数字
 	if _, err := g.writer.WriteString(str); err != nil {
-		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入数字失败").WithCause(err)
-		return g.err
+		return g.fail("写入数字失败", err)
 	}
 
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // WriteBoolean 写入一个布尔值
@@ -254,13 +286,12 @@ func (g *JSONGenerator) WriteBoolean(value bool) error {
 	}
 
 	if _, err := g.writer.WriteString(str); err != nil {
-		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入布尔值失败").WithCause(err)
-		return g.err
+		return g.fail("写入布尔值失败", err)
 	}
 
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // WriteNull 写入一个null值
@@ -280,13 +311,36 @@ func (g *JSONGenerator) WriteNull() error {
 
 	// 写入null
 	if _, err := g.writer.WriteString("null"); err != nil {
-		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入null失败").WithCause(err)
+		return g.fail("写入null失败", err)
+	}
+
+	g.needComma = true
+
+	return g.valueDone()
+}
+
+// WriteRaw 写入一个已经编码好的JSON值，调用方需要保证data是合法的JSON
+func (g *JSONGenerator) WriteRaw(data []byte) error {
+	g.writeMutex.Lock()
+	defer g.writeMutex.Unlock()
+
+	if g.err != nil {
 		return g.err
 	}
 
+	if g.needComma {
+		if err := g.writeComma(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := g.writer.Write(data); err != nil {
+		return g.fail("写入JSON值失败", err)
+	}
+
 	g.needComma = true
 
-	return nil
+	return g.valueDone()
 }
 
 // Flush 刷新缓冲区
@@ -299,18 +353,54 @@ func (g *JSONGenerator) Flush() error {
 	}
 
 	if err := g.writer.Flush(); err != nil {
-		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "刷新缓冲区失败").WithCause(err)
-		return g.err
+		if isTimeout(err) {
+			// 超时的数据留在缓冲区中，可以稍后重试
+			return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "刷新缓冲区超时").WithCause(err)
+		}
+		return g.fail("刷新缓冲区失败", err)
 	}
 
 	return nil
 }
 
+// Buffered 返回缓冲区中还没有写入底层Writer的字节数
+func (g *JSONGenerator) Buffered() int {
+	g.writeMutex.Lock()
+	defer g.writeMutex.Unlock()
+
+	return len(g.writer.buf)
+}
+
+// valueDone 在写完一个值后调用，按FlushValues自动刷新
+func (g *JSONGenerator) valueDone() error {
+	if g.opts.FlushValues <= 0 || len(g.states) > 1 {
+		return nil
+	}
+	g.values++
+	if g.values%g.opts.FlushValues != 0 {
+		return nil
+	}
+	if err := g.writer.Flush(); err != nil && !isTimeout(err) {
+		return g.fail("刷新缓冲区失败", err)
+	}
+	return nil
+}
+
+// fail 记录写入错误，之后的所有操作都返回这个错误。已经是JSONError的错误（例如超过缓冲区上限）原样记录
+func (g *JSONGenerator) fail(message string, err error) error {
+	var jsonErr *jsonerrors.JSONError
+	if errors.As(err, &jsonErr) {
+		g.err = jsonErr
+	} else {
+		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, message).WithCause(err)
+	}
+	return g.err
+}
+
 // 写入一个字节
 func (g *JSONGenerator) writeByte(b byte) error {
 	if err := g.writer.WriteByte(b); err != nil {
-		g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字节失败").WithCause(err)
-		return g.err
+		return g.fail("写入字节失败", err)
 	}
 	return nil
 }
@@ -320,72 +410,70 @@ func (g *JSONGenerator) writeComma() error {
 	return g.writeByte(',')
 }
 
-// 写入字符串（带引号和转义）
+// 写入字符串（带引号和转义），转义规则与utils.QuoteJSONString相同
 func (g *JSONGenerator) writeString(s string) error {
-	// 写入开始引号
-	if err := g.writeByte('"'); err != nil {
-		return err
+	g.quoted = jsonstr.AppendQuote(g.quoted[:0], s, false, false)
+	if _, err := g.writer.Write(g.quoted); err != nil {
+		return g.fail("写入字符串失败", err)
 	}
+	return nil
+}
+
+// generatorBuffer 缓冲生成器的输出，缓冲的字节数达到flushBytes时写入sink。
+// sink返回超时错误时未写出的数据留在缓冲区中，缓冲区不会超过maxBuffered
+type generatorBuffer struct {
+	sink        io.Writer
+	buf         []byte
+	flushBytes  int
+	maxBuffered int
+}
 
-	// 写入字符串内容（需要处理转义）
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		switch c {
-		case '"', '\\', '/':
-			if err := g.writeByte('\\'); err != nil {
-				return err
-			}
-			if err := g.writeByte(c); err != nil {
-				return err
-			}
-		case '\b':
-			if _, err := g.writer.WriteString("\\b"); err != nil {
-				g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-				return g.err
-			}
-		case '\f':
-			if _, err := g.writer.WriteString("\\f"); err != nil {
-				g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-				return g.err
-			}
-		case '\n':
-			if _, err := g.writer.WriteString("\\n"); err != nil {
-				g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-				return g.err
-			}
-		case '\r':
-			if _, err := g.writer.WriteString("\\r"); err != nil {
-				g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-				return g.err
-			}
-		case '\t':
-			if _, err := g.writer.WriteString("\\t"); err != nil {
-				g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-				return g.err
-			}
-		default:
-			if c < 32 {
-				// 控制字符需要使用\uXXXX格式
-				if _, err := g.writer.WriteString("\\u00"); err != nil {
-					g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-					return g.err
-				}
-				if _, err := g.writer.WriteString(strconv.FormatInt(int64(c), 16)); err != nil {
-					g.err = jsonerrors.NewJSONError(ErrInvalidJSON, "写入字符串失败").WithCause(err)
-					return g.err
-				}
-			} else {
-				if err := g.writeByte(c); err != nil {
-					return err
-				}
-			}
+func (b *generatorBuffer) Write(p []byte) (int, error) {
+	if b.maxBuffered > 0 && len(b.buf)+len(p) > b.maxBuffered {
+		if err := b.Flush(); err != nil && !isTimeout(err) {
+			return 0, err
+		}
+		if len(b.buf)+len(p) > b.maxBuffered {
+			return 0, jsonerrors.NewJSONError(jsonerrors.ErrBudgetExceeded,
+				fmt.Sprintf("缓冲的输出超过%d字节的上限，写入端太慢", b.maxBuffered))
 		}
 	}
 
-	// 写入结束引号
-	if err := g.writeByte('"'); err != nil {
-		return err
+	b.buf = append(b.buf, p...)
+	if len(b.buf) >= b.flushBytes {
+		if err := b.Flush(); err != nil && !isTimeout(err) {
+			return len(p), err
+		}
 	}
+	return len(p), nil
+}
+
+func (b *generatorBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
 
+func (b *generatorBuffer) WriteByte(c byte) error {
+	_, err := b.Write([]byte{c})
+	return err
+}
+
+// Flush 把缓冲区中的数据写入sink，出错时未写出的数据留在缓冲区中
+func (b *generatorBuffer) Flush() error {
+	for len(b.buf) > 0 {
+		n, err := b.sink.Write(b.buf)
+		b.buf = b.buf[:copy(b.buf, b.buf[n:])]
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+	}
 	return nil
 }
+
+// isTimeout 检查写入错误是否是超时，超时的写入可以稍后重试
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
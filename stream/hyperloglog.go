@@ -0,0 +1,118 @@
+package stream
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// DefaultHLLPrecision 是HyperLogLog的默认精度，对应4096个寄存器，标准误差约1.6%
+const DefaultHLLPrecision = 12
+
+// HyperLogLog 以固定内存估计集合中不同元素的数量
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog 创建一个新的HyperLogLog，precision的取值范围为4到16
+// 寄存器数量为2^precision，标准误差约为1.04/sqrt(2^precision)
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < 4 || precision > 16 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "HyperLogLog精度必须在4到16之间")
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Add 加入一个元素
+func (h *HyperLogLog) Add(value string) {
+	hash := hashValue(value)
+	index := hash >> (64 - h.precision)
+	// 低位补1保证前导零数量有上限
+	w := hash<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+// Count 返回不同元素数量的估计值
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// 基数较小时使用线性计数修正
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Merge 把另一个HyperLogLog合并进来，两者的精度必须相同
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.precision != other.precision {
+		return jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "无法合并精度不同的HyperLogLog")
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// hllState 是HyperLogLog的序列化格式
+type hllState struct {
+	Precision uint8  `json:"precision"`
+	Registers []byte `json:"registers"`
+}
+
+// MarshalJSON 实现json.Marshaler接口
+func (h *HyperLogLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hllState{Precision: h.precision, Registers: h.registers})
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口
+func (h *HyperLogLog) UnmarshalJSON(data []byte) error {
+	var state hllState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	restored, err := NewHyperLogLog(state.Precision)
+	if err != nil {
+		return err
+	}
+	if len(state.Registers) != len(restored.registers) {
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON, "HyperLogLog寄存器数量与精度不符")
+	}
+	copy(restored.registers, state.Registers)
+	*h = *restored
+	return nil
+}
+
+// hashValue 计算字符串的64位哈希，FNV-1a之后再做一次混合使高位分布均匀
+func hashValue(value string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(value))
+	x := f.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,297 @@
+package stream
+
+import (
+	"encoding/json"
+	"strconv"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// TokenFilter 处理一个令牌，返回替换它的令牌，返回空切片表示丢弃这个令牌。
+// 过滤器可以保存状态，例如丢弃一个值时记住还要丢弃它的所有子令牌
+type TokenFilter func(token JSONToken) ([]JSONToken, error)
+
+// ChainFilters 把多个过滤器连接为一个，前一个过滤器输出的每个令牌依次交给后一个过滤器
+func ChainFilters(filters ...TokenFilter) TokenFilter {
+	return func(token JSONToken) ([]JSONToken, error) {
+		tokens := []JSONToken{token}
+		for _, filter := range filters {
+			var out []JSONToken
+			for _, t := range tokens {
+				result, err := filter(t)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, result...)
+			}
+			if len(out) == 0 {
+				return nil, nil
+			}
+			tokens = out
+		}
+		return tokens, nil
+	}
+}
+
+// Pipeline 把解析器产生的令牌经过过滤器写入生成器，整个文档不会被构建到内存中
+type Pipeline struct {
+	tokenizer *JSONTokenizer
+	generator *JSONGenerator
+	filter    TokenFilter
+	tracker   pathTracker
+	tokens    int64
+}
+
+// NewPipeline 创建连接tokenizer、filters和generator的管道
+func NewPipeline(tokenizer *JSONTokenizer, generator *JSONGenerator, filters ...TokenFilter) *Pipeline {
+	return &Pipeline{
+		tokenizer: tokenizer,
+		generator: generator,
+		filter:    ChainFilters(filters...),
+	}
+}
+
+// Run 处理所有令牌直到输入结束，然后刷新生成器。
+// 交给过滤器的令牌的Path是它在输入文档中的路径，例如"$['items'][0]"
+func (p *Pipeline) Run() error {
+	for {
+		token := p.tokenizer.Next()
+		switch token.Type {
+		case TokenError:
+			return token.Error
+		case TokenEOF:
+			if len(p.tracker.stack) > 0 {
+				return jsonerrors.NewJSONError(ErrInvalidJSON, "意外的输入结束")
+			}
+			return p.generator.Flush()
+		}
+		p.tokens++
+
+		token.Path = formatPath(p.tracker.next(token))
+		out, err := p.filter(token)
+		if err != nil {
+			return err
+		}
+		for _, t := range out {
+			if err := writeToken(p.generator, t); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Tokens 返回已经从输入读取的令牌数量
+func (p *Pipeline) Tokens() int64 {
+	return p.tokens
+}
+
+// writeToken 把令牌写入生成器
+func writeToken(g *JSONGenerator, token JSONToken) error {
+	switch token.Type {
+	case TokenObjectStart:
+		return g.BeginObject()
+	case TokenObjectEnd:
+		return g.EndObject()
+	case TokenArrayStart:
+		return g.BeginArray()
+	case TokenArrayEnd:
+		return g.EndArray()
+	case TokenPropertyName:
+		name, ok := token.Value.(string)
+		if !ok {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "属性名令牌的值必须是字符串")
+		}
+		return g.WriteProperty(name)
+	case TokenString:
+		value, ok := token.Value.(string)
+		if !ok {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "字符串令牌的值必须是字符串")
+		}
+		return g.WriteString(value)
+	case TokenNumber:
+		switch n := token.Value.(type) {
+		case json.Number:
+			// 原样写入，保留输入中数字的精度和写法
+			return g.WriteRaw([]byte(n))
+		case float64:
+			return g.WriteNumber(n)
+		case int:
+			return g.WriteRaw([]byte(strconv.Itoa(n)))
+		default:
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "数字令牌的值必须是json.Number、float64或int")
+		}
+	case TokenBoolean:
+		value, ok := token.Value.(bool)
+		if !ok {
+			return jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "布尔令牌的值必须是bool")
+		}
+		return g.WriteBoolean(value)
+	case TokenNull:
+		return g.WriteNull()
+	default:
+		return unexpectedToken(token)
+	}
+}
+
+// pathTracker 跟踪令牌流中的当前位置
+type pathTracker struct {
+	stack []elementFrame
+}
+
+// next 根据令牌更新当前位置并返回令牌的位置：
+// 值开始的令牌返回该值的位置，属性名返回属性值的位置，结束符返回容器的位置。
+// 返回的切片在处理下一个令牌之前有效
+func (p *pathTracker) next(token JSONToken) []elementFrame {
+	switch token.Type {
+	case TokenPropertyName:
+		if len(p.stack) > 0 {
+			p.stack[len(p.stack)-1].key, _ = token.Value.(string)
+		}
+		return p.stack
+	case TokenObjectEnd, TokenArrayEnd:
+		if len(p.stack) > 0 {
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+		return p.stack
+	case TokenError, TokenEOF:
+		return p.stack
+	}
+
+	if len(p.stack) > 0 {
+		top := &p.stack[len(p.stack)-1]
+		if top.isArray {
+			top.index = top.next
+			top.next++
+		}
+	}
+	position := p.stack
+	switch token.Type {
+	case TokenObjectStart:
+		p.stack = append(p.stack, elementFrame{})
+	case TokenArrayStart:
+		p.stack = append(p.stack, elementFrame{isArray: true})
+	}
+	return position
+}
+
+// subtreeSkipper 跳过一个值的所有令牌
+type subtreeSkipper struct {
+	depth int
+}
+
+// start 开始跳过以token开始的值
+func (s *subtreeSkipper) start(token JSONToken) {
+	if token.Type == TokenObjectStart || token.Type == TokenArrayStart {
+		s.depth = 1
+	}
+}
+
+// skip 检查令牌是否属于正在跳过的值
+func (s *subtreeSkipper) skip(token JSONToken) bool {
+	if s.depth == 0 {
+		return false
+	}
+	switch token.Type {
+	case TokenObjectStart, TokenArrayStart:
+		s.depth++
+	case TokenObjectEnd, TokenArrayEnd:
+		s.depth--
+	}
+	return true
+}
+
+// parseStreamPaths 解析多个流式路径
+func parseStreamPaths(paths []string) ([][]pathStep, error) {
+	parsed := make([][]pathStep, 0, len(paths))
+	for _, path := range paths {
+		steps, err := parseStreamPath(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, steps)
+	}
+	return parsed, nil
+}
+
+// matchAnyPath 检查位置是否与任意一个路径匹配
+func matchAnyPath(paths [][]pathStep, position []elementFrame) bool {
+	for _, steps := range paths {
+		if matchPath(steps, position) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameKeys 返回按names重命名属性名的过滤器，names的键是原来的属性名，值是新的属性名，
+// 任意深度的属性都会被重命名
+func RenameKeys(names map[string]string) TokenFilter {
+	return func(token JSONToken) ([]JSONToken, error) {
+		if token.Type == TokenPropertyName {
+			name, _ := token.Value.(string)
+			if renamed, ok := names[name]; ok {
+				token.Value = renamed
+			}
+		}
+		return []JSONToken{token}, nil
+	}
+}
+
+// RedactPaths 返回把与paths匹配的值替换为字符串replacement的过滤器，对象和数组整个被替换。
+// 路径支持的语法与NewElementReader相同，例如"$.users[*].password"
+func RedactPaths(replacement string, paths ...string) (TokenFilter, error) {
+	parsed, err := parseStreamPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracker pathTracker
+	var skipper subtreeSkipper
+	return func(token JSONToken) ([]JSONToken, error) {
+		position := tracker.next(token)
+		if skipper.skip(token) {
+			return nil, nil
+		}
+		switch token.Type {
+		case TokenPropertyName, TokenObjectEnd, TokenArrayEnd:
+			return []JSONToken{token}, nil
+		}
+		if !matchAnyPath(parsed, position) {
+			return []JSONToken{token}, nil
+		}
+		skipper.start(token)
+		return []JSONToken{{Type: TokenString, Value: replacement, Depth: token.Depth, Path: token.Path}}, nil
+	}, nil
+}
+
+// PrunePaths 返回删除与paths匹配的值的过滤器，对象的属性连同属性名一起删除，
+// 数组元素被删除后之后的元素前移。路径支持的语法与NewElementReader相同
+func PrunePaths(paths ...string) (TokenFilter, error) {
+	parsed, err := parseStreamPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracker pathTracker
+	var skipper subtreeSkipper
+	return func(token JSONToken) ([]JSONToken, error) {
+		position := tracker.next(token)
+		if skipper.skip(token) {
+			return nil, nil
+		}
+		switch token.Type {
+		case TokenObjectEnd, TokenArrayEnd:
+			return []JSONToken{token}, nil
+		case TokenPropertyName:
+			if matchAnyPath(parsed, position) {
+				return nil, nil
+			}
+			return []JSONToken{token}, nil
+		}
+		if !matchAnyPath(parsed, position) {
+			return []JSONToken{token}, nil
+		}
+		skipper.start(token)
+		return nil, nil
+	}, nil
+}
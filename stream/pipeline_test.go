@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runPipeline 用过滤器处理input并返回输出
+func runPipeline(t *testing.T, input string, filters ...TokenFilter) string {
+	t.Helper()
+	var buf bytes.Buffer
+	pipeline := NewPipeline(NewJSONTokenizer(strings.NewReader(input)), NewJSONGenerator(&buf), filters...)
+	if err := pipeline.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestPipelineFilters(t *testing.T) {
+	input := `{"user":{"name":"a","password":"x","tokens":[1,2]},"items":[{"id":1,"secret":{"k":1}},{"id":2.50,"secret":null}]}`
+
+	if got := runPipeline(t, input); got != input {
+		t.Errorf("没有过滤器时输出 = %s", got)
+	}
+
+	if got := runPipeline(t, input, RenameKeys(map[string]string{"id": "ID", "user": "owner"})); got !=
+		`{"owner":{"name":"a","password":"x","tokens":[1,2]},"items":[{"ID":1,"secret":{"k":1}},{"ID":2.50,"secret":null}]}` {
+		t.Errorf("重命名后输出 = %s", got)
+	}
+
+	redact, err := RedactPaths("***", "$.user.password", "$.user.tokens", "$.items[*].secret")
+	if err != nil {
+		t.Fatalf("RedactPaths() error = %v", err)
+	}
+	if got := runPipeline(t, input, redact); got !=
+		`{"user":{"name":"a","password":"***","tokens":"***"},"items":[{"id":1,"secret":"***"},{"id":2.50,"secret":"***"}]}` {
+		t.Errorf("脱敏后输出 = %s", got)
+	}
+
+	prune, err := PrunePaths("$.user", "$.items[0]", "$.items[*].secret")
+	if err != nil {
+		t.Fatalf("PrunePaths() error = %v", err)
+	}
+	if got := runPipeline(t, input, prune); got != `{"items":[{"id":2.50}]}` {
+		t.Errorf("删除后输出 = %s", got)
+	}
+
+	if _, err := PrunePaths("items"); err == nil {
+		t.Error("无效的路径应该返回错误")
+	}
+}
+
+func TestPipelineCustomFilter(t *testing.T) {
+	var paths []string
+	double := func(token JSONToken) ([]JSONToken, error) {
+		paths = append(paths, token.Path)
+		if token.Type == TokenString {
+			return []JSONToken{token, token}, nil
+		}
+		return []JSONToken{token}, nil
+	}
+	if got := runPipeline(t, `["a",{"b":[true]}]`, double); got != `["a","a",{"b":[true]}]` {
+		t.Errorf("输出 = %s", got)
+	}
+	want := "$ $[0] $[1] $[1]['b'] $[1]['b'] $[1]['b'][0] $[1]['b'] $[1] $"
+	if strings.Join(paths, " ") != want {
+		t.Errorf("令牌路径 = %q, 期望 %q", strings.Join(paths, " "), want)
+	}
+
+	var buf bytes.Buffer
+	pipeline := NewPipeline(NewJSONTokenizer(strings.NewReader(`{"a":[1,`)), NewJSONGenerator(&buf))
+	if err := pipeline.Run(); err == nil {
+		t.Error("不完整的输入应该返回错误")
+	}
+}
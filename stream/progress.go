@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// Progress 表示流式处理的进度
+type Progress struct {
+	// BytesRead 是已经从输入读取的字节数
+	BytesRead int64
+	// TotalBytes 是输入的总字节数，未知时为0
+	TotalBytes int64
+	// Elements 是已经产生的元素数量
+	Elements int64
+	// Done 表示输入已经处理完毕
+	Done bool
+}
+
+// Fraction 返回已完成的比例，总字节数未知时返回false
+func (p Progress) Fraction() (float64, bool) {
+	if p.TotalBytes <= 0 {
+		return 0, false
+	}
+	if p.BytesRead >= p.TotalBytes {
+		return 1, true
+	}
+	return float64(p.BytesRead) / float64(p.TotalBytes), true
+}
+
+// ProgressFunc 接收进度通知
+type ProgressFunc func(Progress)
+
+// CountingReader 统计从底层Reader读取的字节数，可以在其他goroutine中读取计数
+type CountingReader struct {
+	r io.Reader
+	n int64
+}
+
+// NewCountingReader 创建一个新的CountingReader
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+// Read 实现io.Reader
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// BytesRead 返回已经读取的字节数
+func (c *CountingReader) BytesRead() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+// ProgressSource 包装数据源，每产生一个元素以及数据源结束时调用进度回调。
+// 回调在Next中同步执行，需要限制刷新频率时由回调自己处理。
+type ProgressSource struct {
+	source   ValueSource
+	counter  *CountingReader
+	total    int64
+	fn       ProgressFunc
+	elements int64
+	done     bool
+}
+
+// NewProgressSource 创建报告进度的数据源
+// counter统计输入的字节数，可以为nil；total是输入的总字节数，未知时为0
+func NewProgressSource(source ValueSource, counter *CountingReader, total int64, fn ProgressFunc) *ProgressSource {
+	return &ProgressSource{source: source, counter: counter, total: total, fn: fn}
+}
+
+// Next 返回数据源的下一个值
+func (p *ProgressSource) Next() (types.JSONValue, error) {
+	value, err := p.source.Next()
+	if err == nil {
+		p.elements++
+		p.fn(p.Progress())
+	} else if err == io.EOF && !p.done {
+		p.done = true
+		p.fn(p.Progress())
+	}
+	return value, err
+}
+
+// Finish 在提前停止读取时报告最终的进度，数据源已经结束时不做任何事
+func (p *ProgressSource) Finish() {
+	if !p.done {
+		p.done = true
+		p.fn(p.Progress())
+	}
+}
+
+// Progress 返回当前的进度
+func (p *ProgressSource) Progress() Progress {
+	progress := Progress{TotalBytes: p.total, Elements: p.elements, Done: p.done}
+	if p.counter != nil {
+		progress.BytesRead = p.counter.BytesRead()
+	}
+	return progress
+}
@@ -0,0 +1,43 @@
+package stream
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestProgressSource(t *testing.T) {
+	input := `{"items":[1,2,3]}`
+	counter := NewCountingReader(strings.NewReader(input))
+	reader, err := NewElementReader(counter, "$.items[*]")
+	if err != nil {
+		t.Fatalf("NewElementReader() error = %v", err)
+	}
+
+	var updates []Progress
+	source := NewProgressSource(reader, counter, int64(len(input)), func(p Progress) {
+		updates = append(updates, p)
+	})
+	for {
+		if _, err := source.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+	}
+	source.Next()
+
+	if len(updates) != 4 {
+		t.Fatalf("进度通知次数 = %d, 期望 4", len(updates))
+	}
+	last := updates[3]
+	if !last.Done || last.Elements != 3 || last.BytesRead != int64(len(input)) {
+		t.Errorf("最后的进度 = %+v", last)
+	}
+	if f, ok := last.Fraction(); !ok || f != 1 {
+		t.Errorf("Fraction() = %v, %v", f, ok)
+	}
+	if _, ok := (Progress{BytesRead: 10}).Fraction(); ok {
+		t.Error("总字节数未知时Fraction()应该返回false")
+	}
+}
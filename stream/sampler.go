@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ValueSource 逐个产生JSON值，没有更多值时返回io.EOF
+type ValueSource interface {
+	Next() (types.JSONValue, error)
+}
+
+// SampleOptions 定义采样选项
+type SampleOptions struct {
+	// Rate 是伯努利采样中每个元素被保留的概率，取值范围(0, 1]
+	Rate float64
+	// Size 大于0时使用蓄水池采样，最多保留Size个元素，此时忽略Rate
+	Size int
+	// Seed 是随机数种子，相同的种子和输入总是得到相同的样本
+	Seed int64
+}
+
+// sampledValue 记录蓄水池中的元素及其在数据源中的位置
+type sampledValue struct {
+	position int
+	value    types.JSONValue
+}
+
+// Sampler 从数据源中抽取样本。
+// 伯努利采样逐个判断元素是否保留，不需要额外内存；
+// 蓄水池采样需要读完整个数据源，之后按元素原有的顺序返回样本。
+type Sampler struct {
+	source    ValueSource
+	opts      SampleOptions
+	rand      *rand.Rand
+	reservoir []sampledValue
+	filled    bool
+}
+
+// NewSampler 创建一个新的采样器
+func NewSampler(source ValueSource, opts SampleOptions) (*Sampler, error) {
+	if opts.Size < 0 {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "样本大小不能为负数")
+	}
+	if opts.Size == 0 && (opts.Rate <= 0 || opts.Rate > 1) {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "采样率必须在(0, 1]范围内")
+	}
+	return &Sampler{
+		source: source,
+		opts:   opts,
+		rand:   rand.New(rand.NewSource(opts.Seed)),
+	}, nil
+}
+
+// Next 返回下一个样本，没有更多样本时返回io.EOF
+func (s *Sampler) Next() (types.JSONValue, error) {
+	if s.opts.Size > 0 {
+		return s.nextReservoir()
+	}
+
+	for {
+		value, err := s.source.Next()
+		if err != nil {
+			return nil, err
+		}
+		if s.rand.Float64() < s.opts.Rate {
+			return value, nil
+		}
+	}
+}
+
+// nextReservoir 在第一次调用时填充蓄水池，之后逐个返回其中的元素
+func (s *Sampler) nextReservoir() (types.JSONValue, error) {
+	if !s.filled {
+		if err := s.fill(); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.reservoir) == 0 {
+		return nil, io.EOF
+	}
+	value := s.reservoir[0].value
+	s.reservoir = s.reservoir[1:]
+	return value, nil
+}
+
+// fill 使用算法R读取整个数据源
+func (s *Sampler) fill() error {
+	s.filled = true
+	for position := 0; ; position++ {
+		value, err := s.source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if position < s.opts.Size {
+			s.reservoir = append(s.reservoir, sampledValue{position, value})
+			continue
+		}
+		if j := s.rand.Intn(position + 1); j < s.opts.Size {
+			s.reservoir[j] = sampledValue{position, value}
+		}
+	}
+
+	sort.Slice(s.reservoir, func(i, j int) bool {
+		return s.reservoir[i].position < s.reservoir[j].position
+	})
+	return nil
+}
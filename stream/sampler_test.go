@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// numbersSource 创建包含0到n-1的数据源
+func numbersSource(t *testing.T, n int) ValueSource {
+	t.Helper()
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprint(i)
+	}
+	reader, err := NewElementReader(strings.NewReader("["+strings.Join(parts, ",")+"]"), "$[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+// drain 读取采样器的全部样本
+func drain(t *testing.T, source ValueSource) []float64 {
+	t.Helper()
+	var result []float64
+	for {
+		value, err := source.Next()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		n, _ := value.AsNumber()
+		result = append(result, n)
+	}
+}
+
+func TestSamplerBernoulli(t *testing.T) {
+	sample := func(seed int64) []float64 {
+		s, err := NewSampler(numbersSource(t, 10000), SampleOptions{Rate: 0.1, Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return drain(t, s)
+	}
+
+	first := sample(42)
+	if len(first) < 800 || len(first) > 1200 {
+		t.Errorf("样本数量 = %d, 期望约1000", len(first))
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i] <= first[i-1] {
+			t.Fatalf("样本应保持原有顺序: %v >= %v", first[i-1], first[i])
+		}
+	}
+	if fmt.Sprint(first) != fmt.Sprint(sample(42)) {
+		t.Error("相同种子应该得到相同的样本")
+	}
+	if fmt.Sprint(first) == fmt.Sprint(sample(7)) {
+		t.Error("不同种子应该得到不同的样本")
+	}
+}
+
+func TestSamplerReservoir(t *testing.T) {
+	s, err := NewSampler(numbersSource(t, 1000), SampleOptions{Size: 50, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := drain(t, s)
+	if len(got) != 50 {
+		t.Fatalf("样本数量 = %d, want 50", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("样本应保持原有顺序: %v >= %v", got[i-1], got[i])
+		}
+	}
+	if got[len(got)-1] < 500 {
+		t.Errorf("蓄水池采样应覆盖整个数据源, 最大值 = %v", got[len(got)-1])
+	}
+
+	// 数据源小于样本大小时保留全部元素
+	s, _ = NewSampler(numbersSource(t, 3), SampleOptions{Size: 10})
+	if got := drain(t, s); fmt.Sprint(got) != "[0 1 2]" {
+		t.Errorf("样本 = %v, want [0 1 2]", got)
+	}
+}
+
+func TestSamplerInvalidOptions(t *testing.T) {
+	for _, opts := range []SampleOptions{{}, {Rate: 1.5}, {Rate: -0.1}, {Size: -1}} {
+		if _, err := NewSampler(numbersSource(t, 1), opts); err == nil {
+			t.Errorf("NewSampler(%+v) 应该返回错误", opts)
+		}
+	}
+}
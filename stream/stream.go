@@ -8,6 +8,8 @@ import (
 	"io"
 
 	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/internal/jsonstr"
+	"github.com/UserLeeZJ/gojson/parser"
 )
 
 // 错误代码
@@ -66,7 +68,7 @@ type JSONToken struct {
 // JSONTokenizer 是JSON流式解析器
 type JSONTokenizer struct {
 	reader    *bufio.Reader
-	buffer    bytes.Buffer
+	raw       []byte // 最近一个字符串的原文
 	depth     int
 	path      []string
 	lastToken JSONToken
@@ -74,9 +76,10 @@ type JSONTokenizer struct {
 }
 
 // NewJSONTokenizer 创建一个新的JSON流式解析器
+// 输入开头的BOM被忽略，UTF-16和UTF-32编码的输入被转换为UTF-8
 func NewJSONTokenizer(r io.Reader) *JSONTokenizer {
 	return &JSONTokenizer{
-		reader: bufio.NewReaderSize(r, defaultBufSize),
+		reader: bufio.NewReaderSize(parser.NewUTF8Reader(r), defaultBufSize),
 		depth:  0,
 		path:   make([]string, 0),
 	}
@@ -183,11 +186,9 @@ func (t *JSONTokenizer) readNonWhitespace() (byte, error) {
 	}
 }
 
-// 解析字符串
+// 解析字符串，原文（包括引号）保存在t.raw中
 func (t *JSONTokenizer) parseString() (string, error) {
-	// 直接使用标准库的方式解析JSON字符串
-	var sb bytes.Buffer
-	sb.WriteByte('"') // 添加开始引号
+	t.raw = append(t.raw[:0], '"')
 
 	escaped := false
 	for {
@@ -195,9 +196,7 @@ func (t *JSONTokenizer) parseString() (string, error) {
 		if err != nil {
 			return "", jsonerrors.NewJSONError(ErrInvalidJSON, "解析字符串时遇到EOF")
 		}
-
-		// 添加字符到缓冲区
-		sb.WriteByte(c)
+		t.raw = append(t.raw, c)
 
 		// 处理转义字符
 		if escaped {
@@ -209,16 +208,19 @@ func (t *JSONTokenizer) parseString() (string, error) {
 		}
 	}
 
-	// 使用标准库解析JSON字符串
-	var result string
-	err := json.Unmarshal(sb.Bytes(), &result)
+	result, err := jsonstr.Unquote(t.raw)
 	if err != nil {
 		return "", jsonerrors.NewJSONError(ErrInvalidJSON, "解析字符串失败").WithCause(err)
 	}
-
 	return result, nil
 }
 
+// RawString 返回最近一个字符串或属性名令牌在输入中的原文，包括双引号，转义序列没有解码，
+// 可以用utils.UnquoteJSONString解码。返回的切片在下一次调用Next时失效，需要保留时应复制
+func (t *JSONTokenizer) RawString() []byte {
+	return t.raw
+}
+
 // 解析布尔值
 func (t *JSONTokenizer) parseBoolean(first byte) (bool, error) {
 	if first == 't' {
@@ -3,8 +3,11 @@ package stream
 import (
 	"bytes"
 	"encoding/json"
+	"os"
 	"strings"
 	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
 )
 
 func TestJSONTokenizer(t *testing.T) {
@@ -232,3 +235,124 @@ func TestJSONGenerator(t *testing.T) {
 		})
 	}
 }
+
+// stallWriter 模拟写入很慢的连接，stalled为true时写入超时
+type stallWriter struct {
+	bytes.Buffer
+	stalled bool
+	writes  int
+}
+
+func (w *stallWriter) Write(p []byte) (int, error) {
+	if w.stalled {
+		return 0, os.ErrDeadlineExceeded
+	}
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestJSONGeneratorFlushPolicy(t *testing.T) {
+	sink := &stallWriter{}
+	generator := NewJSONGeneratorWithOptions(sink, GeneratorOptions{FlushValues: 1})
+	generator.BeginArray()
+	generator.BeginObject()
+	generator.WriteProperty("id")
+	generator.WriteNumber(1)
+	if sink.Len() != 0 {
+		t.Errorf("嵌套的值不应该触发刷新, 已写入 %q", sink.String())
+	}
+	generator.EndObject()
+	if sink.String() != `[{"id":1}` {
+		t.Errorf("写完元素后应该自动刷新, 已写入 %q", sink.String())
+	}
+	generator.WriteString("x")
+	if sink.String() != `[{"id":1},"x"` || generator.Buffered() != 0 {
+		t.Errorf("写完元素后应该自动刷新, 已写入 %q", sink.String())
+	}
+
+	sink = &stallWriter{}
+	generator = NewJSONGeneratorWithOptions(sink, GeneratorOptions{FlushBytes: 8})
+	generator.BeginArray()
+	for i := 0; i < 10; i++ {
+		generator.WriteNumber(float64(i))
+	}
+	if sink.writes == 0 || generator.Buffered() >= 8 {
+		t.Errorf("缓冲达到8字节时应该自动刷新, 写入次数 %d, 缓冲 %d", sink.writes, generator.Buffered())
+	}
+}
+
+func TestJSONGeneratorMaxBuffered(t *testing.T) {
+	sink := &stallWriter{stalled: true}
+	generator := NewJSONGeneratorWithOptions(sink, GeneratorOptions{FlushBytes: 4, MaxBuffered: 16})
+	generator.BeginArray()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = generator.WriteString("value")
+	}
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrBudgetExceeded {
+		t.Fatalf("写入端太慢时应该返回ErrBudgetExceeded, 得到 %v", err)
+	}
+	if generator.Buffered() > 16 {
+		t.Errorf("缓冲 %d 字节, 超过上限16", generator.Buffered())
+	}
+
+	// 超时是暂时的，刷新失败后数据留在缓冲区中
+	sink = &stallWriter{stalled: true}
+	generator = NewJSONGeneratorWithOptions(sink, GeneratorOptions{MaxBuffered: 64})
+	generator.WriteString("ok")
+	if err := generator.Flush(); err == nil {
+		t.Fatal("写入超时时Flush()应该返回错误")
+	}
+	sink.stalled = false
+	if err := generator.Flush(); err != nil || sink.String() != `"ok"` {
+		t.Errorf("重试Flush() = %v, 已写入 %q", err, sink.String())
+	}
+}
+
+func TestJSONTokenizerRawString(t *testing.T) {
+	tokenizer := NewJSONTokenizer(strings.NewReader(`{"caf\u00e9": "a\"b\n", "n": 1}`))
+	var raws, values []string
+	for {
+		token := tokenizer.Next()
+		if token.Type == TokenEOF {
+			break
+		}
+		if token.Type == TokenError {
+			t.Fatalf("Next() error = %v", token.Error)
+		}
+		if token.Type == TokenPropertyName || token.Type == TokenString {
+			raws = append(raws, string(tokenizer.RawString()))
+			values = append(values, token.Value.(string))
+		}
+	}
+
+	wantRaws := []string{`"caf\u00e9"`, `"a\"b\n"`, `"n"`}
+	wantValues := []string{"caf\u00e9", "a\"b\n", "n"}
+	if strings.Join(raws, " ") != strings.Join(wantRaws, " ") {
+		t.Errorf("RawString() = %v, want %v", raws, wantRaws)
+	}
+	if strings.Join(values, " ") != strings.Join(wantValues, " ") {
+		t.Errorf("Value = %q, want %q", values, wantValues)
+	}
+}
+
+func TestJSONGeneratorEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewJSONGenerator(&buf)
+	input := "a\"b\\c/\x01\x1f\n\u2028"
+	if err := g.WriteString(input); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+	if err := g.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if want := `"a\"b\\c/\u0001\u001f\n\u2028"`; buf.String() != want {
+		t.Errorf("WriteString() = %s, want %s", buf.String(), want)
+	}
+	var decoded string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded != input {
+		t.Errorf("json.Unmarshal() = %q, %v, want %q", decoded, err, input)
+	}
+}
@@ -0,0 +1,54 @@
+package stream
+
+import (
+	stderrors "errors"
+	"io"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// StopSubscription 由订阅的回调返回，表示不再需要更多的值。Subscribe此时不返回错误
+var StopSubscription = stderrors.New("stop subscription")
+
+// SubscribeStats 是一次订阅的统计信息
+type SubscribeStats struct {
+	// Matches 是交给回调的值的数量
+	Matches int64
+	// BytesRead 是已经从输入读取的字节数，SubscribeSource不统计字节数
+	BytesRead int64
+}
+
+// Subscribe 从r中读取与path匹配的值，每读到一个就调用fn，不等待输入结束。
+// 输入读完、回调返回StopSubscription或者出错时返回，错误是读取或回调的第一个错误
+func Subscribe(r io.Reader, path string, fn func(types.JSONValue) error) (SubscribeStats, error) {
+	counter := NewCountingReader(r)
+	reader, err := NewElementReader(counter, path)
+	if err != nil {
+		return SubscribeStats{}, err
+	}
+	stats, err := SubscribeSource(reader, fn)
+	stats.BytesRead = counter.BytesRead()
+	return stats, err
+}
+
+// SubscribeSource 对数据源产生的每个值调用fn，用于在订阅之前包装采样、进度等数据源
+func SubscribeSource(source ValueSource, fn func(types.JSONValue) error) (SubscribeStats, error) {
+	var stats SubscribeStats
+	for {
+		value, err := source.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Matches++
+		if err := fn(value); err != nil {
+			if err == StopSubscription {
+				return stats, nil
+			}
+			return stats, err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package stream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestSubscribe(t *testing.T) {
+	input := `{"items":[{"id":1},{"id":2},{"id":3}]}`
+
+	var ids []string
+	stats, err := Subscribe(strings.NewReader(input), "$.items[*].id", func(v types.JSONValue) error {
+		ids = append(ids, v.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if strings.Join(ids, ",") != "1,2,3" || stats.Matches != 3 || stats.BytesRead != int64(len(input)) {
+		t.Errorf("ids = %v, stats = %+v", ids, stats)
+	}
+
+	stats, err = Subscribe(strings.NewReader(input), "$.items[*]", func(types.JSONValue) error {
+		return StopSubscription
+	})
+	if err != nil || stats.Matches != 1 {
+		t.Errorf("StopSubscription后 stats = %+v, err = %v", stats, err)
+	}
+
+	failure := errors.New("failure")
+	stats, err = Subscribe(strings.NewReader(input), "$.items[*]", func(types.JSONValue) error {
+		return failure
+	})
+	if err != failure || stats.Matches != 1 {
+		t.Errorf("回调出错时 stats = %+v, err = %v", stats, err)
+	}
+
+	stats, err = Subscribe(strings.NewReader(`{"items":[1,2,`), "$.items[*]", func(types.JSONValue) error {
+		return nil
+	})
+	if err == nil || stats.Matches != 2 {
+		t.Errorf("输入不完整时 stats = %+v, err = %v", stats, err)
+	}
+
+	if _, err := Subscribe(strings.NewReader(input), "items", nil); err == nil {
+		t.Error("无效的路径应该返回错误")
+	}
+}
@@ -0,0 +1,178 @@
+package types
+
+import "github.com/UserLeeZJ/gojson/errors"
+
+// Accessor 沿着属性名和数组索引访问嵌套的值，并记录从根开始的路径。
+// 中间某一步失败后，后续的At和Index不再访问，最终的取值方法返回第一个错误，
+// 错误的Path是失败位置的完整路径，例如"$.a.b[3]"：
+//
+//	n, err := obj.At("a").At("b").Index(3).Number()
+//	// err: INDEX_OUT_OF_RANGE: 索引超出范围: 3 (大小: 2) (path: $.a.b[3])
+//
+// 与JSONObject的GetString等方法不同，取值方法严格检查类型，不在类型之间转换
+type Accessor struct {
+	value JSONValue
+	path  string
+	err   error
+}
+
+// NewAccessor 创建从value开始访问的Accessor，根的路径是"$"
+func NewAccessor(value JSONValue) *Accessor {
+	if value == nil {
+		value = NewJSONNull()
+	}
+	return &Accessor{value: value, path: "$"}
+}
+
+// At 从对象开始访问属性key
+func (o *JSONObject) At(key string) *Accessor {
+	return NewAccessor(o).At(key)
+}
+
+// Index 从数组开始访问第index个元素
+func (a *JSONArray) Index(index int) *Accessor {
+	return NewAccessor(a).Index(index)
+}
+
+// At 访问当前对象的属性key，当前值不是对象或属性不存在时记录错误
+func (c *Accessor) At(key string) *Accessor {
+	next := &Accessor{path: ChildPath(c.path, KeySegment(key)), err: c.err}
+	if next.err != nil {
+		return next
+	}
+	if !c.value.IsObject() {
+		next.err = c.typeError("object")
+		return next
+	}
+	obj, _ := c.value.AsObject()
+	value, ok := obj.lookup(key)
+	if !ok {
+		next.err = errors.ErrPathNotFoundWithDetails(next.path)
+		return next
+	}
+	if value == nil {
+		value = NewJSONNull()
+	}
+	next.value = value
+	return next
+}
+
+// Index 访问当前数组的第index个元素，当前值不是数组或索引超出范围时记录错误
+func (c *Accessor) Index(index int) *Accessor {
+	next := &Accessor{path: ChildPath(c.path, IndexSegment(index)), err: c.err}
+	if next.err != nil {
+		return next
+	}
+	if !c.value.IsArray() {
+		next.err = c.typeError("array")
+		return next
+	}
+	arr, _ := c.value.AsArray()
+	if index < 0 || index >= len(arr.elements) {
+		next.err = errors.ErrIndexOutOfRangeWithDetails(index, len(arr.elements)).WithPath(next.path)
+		return next
+	}
+	next.value = arr.elements[index]
+	if next.value == nil {
+		next.value = NewJSONNull()
+	}
+	return next
+}
+
+// Path 返回当前访问的完整路径，访问失败时同样包含失败之后的部分
+func (c *Accessor) Path() string {
+	return c.path
+}
+
+// Err 返回访问过程中的第一个错误
+func (c *Accessor) Err() error {
+	return c.err
+}
+
+// Exists 检查路径上的每一步是否都成功
+func (c *Accessor) Exists() bool {
+	return c.err == nil
+}
+
+// Value 返回当前的值
+func (c *Accessor) Value() (JSONValue, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.value, nil
+}
+
+// Object 返回当前的对象
+func (c *Accessor) Object() (*JSONObject, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if !c.value.IsObject() {
+		return nil, c.typeError("object")
+	}
+	return c.value.AsObject()
+}
+
+// Array 返回当前的数组
+func (c *Accessor) Array() (*JSONArray, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if !c.value.IsArray() {
+		return nil, c.typeError("array")
+	}
+	return c.value.AsArray()
+}
+
+// Number 返回当前的数字
+func (c *Accessor) Number() (float64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if !c.value.IsNumber() {
+		return 0, c.typeError("number")
+	}
+	return c.value.AsNumber()
+}
+
+// Int 返回当前的数字转换成的整数，小数部分和超出范围的处理方式由CurrentNumericPolicy决定
+func (c *Accessor) Int() (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if !c.value.IsNumber() {
+		return 0, c.typeError("number")
+	}
+	n, err := CurrentNumericPolicy().Int(c.value)
+	if jsonErr, ok := err.(*errors.JSONError); ok {
+		return n, jsonErr.WithPath(c.path)
+	}
+	return n, err
+}
+
+// StringValue 返回当前的字符串，其他类型的值返回错误而不是被转换为字符串
+func (c *Accessor) StringValue() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	if !c.value.IsString() {
+		return "", c.typeError("string")
+	}
+	return c.value.AsString()
+}
+
+// Boolean 返回当前的布尔值
+func (c *Accessor) Boolean() (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	if !c.value.IsBoolean() {
+		return false, c.typeError("boolean")
+	}
+	return c.value.AsBoolean()
+}
+
+// typeError 返回当前值不是expected类型的错误，路径是当前值的路径
+func (c *Accessor) typeError(expected string) error {
+	return errors.ErrInvalidTypeWithDetails(expected, c.value.Type()).WithPath(c.path)
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+func TestAccessor(t *testing.T) {
+	obj := NewJSONObject().
+		Put("a", NewJSONObject().
+			Put("b", NewJSONArray().AddNumber(1).AddString("two").Add(NewJSONObject().PutBoolean("ok", true))).
+			PutNull("n").
+			PutString("first name", "Ada"))
+
+	if n, err := obj.At("a").At("b").Index(0).Number(); err != nil || n != 1 {
+		t.Errorf("Number() = %v, %v", n, err)
+	}
+	if s, err := obj.At("a").At("first name").StringValue(); err != nil || s != "Ada" {
+		t.Errorf("StringValue() = %q, %v", s, err)
+	}
+	ok := obj.At("a").At("b").Index(2).At("ok")
+	if b, err := ok.Boolean(); err != nil || !b || ok.Path() != "$.a.b[2].ok" {
+		t.Errorf("Boolean() = %v, %v at %s", b, err, ok.Path())
+	}
+	if arr, err := obj.At("a").At("b").Array(); err != nil || arr.Size() != 3 {
+		t.Errorf("Array() = %v, %v", arr, err)
+	}
+	if v, err := obj.At("a").At("n").Value(); err != nil || !v.IsNull() {
+		t.Errorf("Value() of null = %v, %v", v, err)
+	}
+
+	tests := []struct {
+		name string
+		get  func() error
+		code errors.ErrorCode
+		path string
+	}{
+		{"missing key", func() error { _, err := obj.At("a").At("missing").At("x").Number(); return err },
+			errors.ErrPathNotFound, "$.a.missing"},
+		{"index out of range", func() error { _, err := obj.At("a").At("b").Index(3).Number(); return err },
+			errors.ErrIndexOutOfRange, "$.a.b[3]"},
+		{"negative index", func() error { _, err := obj.At("a").At("b").Index(-1).Value(); return err },
+			errors.ErrIndexOutOfRange, "$.a.b[-1]"},
+		{"index into object", func() error { _, err := obj.At("a").Index(0).Number(); return err },
+			errors.ErrInvalidType, "$.a"},
+		{"key into array", func() error { _, err := obj.At("a").At("b").At("c").Number(); return err },
+			errors.ErrInvalidType, "$.a.b"},
+		{"wrong final type", func() error { _, err := obj.At("a").At("b").Index(1).Number(); return err },
+			errors.ErrInvalidType, "$.a.b[1]"},
+		{"number is not a string", func() error { _, err := obj.At("a").At("b").Index(0).StringValue(); return err },
+			errors.ErrInvalidType, "$.a.b[0]"},
+		{"null is not an object", func() error { _, err := obj.At("a").At("n").Object(); return err },
+			errors.ErrInvalidType, "$.a.n"},
+		{"quoted key", func() error { _, err := obj.At("a").At("last name").Boolean(); return err },
+			errors.ErrPathNotFound, "$.a['last name']"},
+	}
+	for _, tt := range tests {
+		err := tt.get()
+		jsonErr, ok := err.(*errors.JSONError)
+		if !ok || jsonErr.Code != tt.code || jsonErr.Path != tt.path {
+			t.Errorf("%s: error = %v, want %s at %s", tt.name, err, tt.code, tt.path)
+		}
+	}
+
+	// 失败之后的访问保留第一个错误
+	c := obj.At("x").At("y").Index(1)
+	if c.Exists() || c.Path() != "$.x.y[1]" || c.Err().(*errors.JSONError).Path != "$.x" {
+		t.Errorf("Accessor after failure: exists=%v path=%s err=%v", c.Exists(), c.Path(), c.Err())
+	}
+
+	if n, err := NewJSONArray().AddNumber(5).Index(0).Number(); err != nil || n != 5 {
+		t.Errorf("JSONArray.Index() = %v, %v", n, err)
+	}
+	if _, err := NewAccessor(nil).At("a").Value(); err == nil {
+		t.Errorf("NewAccessor(nil).At() should fail")
+	}
+}
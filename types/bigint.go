@@ -0,0 +1,180 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// JSONBigInt 表示任意精度的整数，用于超出float64精确范围（±2^53）的ID、金额等。
+// JSONBigInt的类型是number，String和MarshalJSON输出精确的十进制表示，
+// AsNumber返回最接近的float64。JSONBigInt不可变，算术方法返回新的值
+type JSONBigInt struct {
+	value *big.Int
+}
+
+// NewJSONBigInt 创建一个新的JSONBigInt，复制value，value为nil时为0
+func NewJSONBigInt(value *big.Int) *JSONBigInt {
+	v := new(big.Int)
+	if value != nil {
+		v.Set(value)
+	}
+	return &JSONBigInt{value: v}
+}
+
+// NewJSONBigIntFromInt64 从int64创建JSONBigInt
+func NewJSONBigIntFromInt64(value int64) *JSONBigInt {
+	return &JSONBigInt{value: big.NewInt(value)}
+}
+
+// ParseJSONBigInt 解析十进制整数字符串，可以带正负号
+func ParseJSONBigInt(s string) (*JSONBigInt, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, errors.NewJSONError(errors.ErrTypeConversion, fmt.Sprintf("无效的整数: %q", s))
+	}
+	return &JSONBigInt{value: v}, nil
+}
+
+// Type 返回JSON值的类型
+func (b *JSONBigInt) Type() string {
+	return "number"
+}
+
+// String 返回整数的十进制表示
+func (b *JSONBigInt) String() string {
+	return b.value.String()
+}
+
+// MarshalJSON 实现json.Marshaler接口，输出精确的十进制表示
+func (b *JSONBigInt) MarshalJSON() ([]byte, error) {
+	return []byte(b.value.String()), nil
+}
+
+// IsNull 检查值是否为null
+func (b *JSONBigInt) IsNull() bool {
+	return false
+}
+
+// IsBoolean 检查值是否为布尔值
+func (b *JSONBigInt) IsBoolean() bool {
+	return false
+}
+
+// IsNumber 检查值是否为数字
+func (b *JSONBigInt) IsNumber() bool {
+	return true
+}
+
+// IsString 检查值是否为字符串
+func (b *JSONBigInt) IsString() bool {
+	return false
+}
+
+// IsArray 检查值是否为数组
+func (b *JSONBigInt) IsArray() bool {
+	return false
+}
+
+// IsObject 检查值是否为对象
+func (b *JSONBigInt) IsObject() bool {
+	return false
+}
+
+// AsBoolean 将值转换为布尔值，非0为true
+func (b *JSONBigInt) AsBoolean() (bool, error) {
+	return b.value.Sign() != 0, nil
+}
+
+// AsNumber 返回最接近的float64，超出精确范围时会丢失精度，
+// 是否返回错误由CurrentNumericPolicy决定
+func (b *JSONBigInt) AsNumber() (float64, error) {
+	return CurrentNumericPolicy().Float64(b)
+}
+
+// AsString 将值转换为字符串
+func (b *JSONBigInt) AsString() (string, error) {
+	return b.String(), nil
+}
+
+// AsArray 将值转换为数组
+func (b *JSONBigInt) AsArray() (*JSONArray, error) {
+	return nil, errors.ErrInvalidTypeWithDetails("array", "number")
+}
+
+// AsObject 将值转换为对象
+func (b *JSONBigInt) AsObject() (*JSONObject, error) {
+	return nil, errors.ErrInvalidTypeWithDetails("object", "number")
+}
+
+// BigInt 返回整数值的副本
+func (b *JSONBigInt) BigInt() *big.Int {
+	return new(big.Int).Set(b.value)
+}
+
+// IsInt64 检查整数是否可以用int64表示
+func (b *JSONBigInt) IsInt64() bool {
+	return b.value.IsInt64()
+}
+
+// Int64 返回整数的int64表示，超出范围时结果未定义，应先用IsInt64检查
+func (b *JSONBigInt) Int64() int64 {
+	return b.value.Int64()
+}
+
+// Sign 返回整数的符号：-1、0或1
+func (b *JSONBigInt) Sign() int {
+	return b.value.Sign()
+}
+
+// Cmp 比较两个整数，b小于、等于、大于other时分别返回-1、0、1
+func (b *JSONBigInt) Cmp(other *JSONBigInt) int {
+	return b.value.Cmp(other.value)
+}
+
+// Add 返回b + other
+func (b *JSONBigInt) Add(other *JSONBigInt) *JSONBigInt {
+	return &JSONBigInt{value: new(big.Int).Add(b.value, other.value)}
+}
+
+// Sub 返回b - other
+func (b *JSONBigInt) Sub(other *JSONBigInt) *JSONBigInt {
+	return &JSONBigInt{value: new(big.Int).Sub(b.value, other.value)}
+}
+
+// Mul 返回b * other
+func (b *JSONBigInt) Mul(other *JSONBigInt) *JSONBigInt {
+	return &JSONBigInt{value: new(big.Int).Mul(b.value, other.value)}
+}
+
+// Neg 返回-b
+func (b *JSONBigInt) Neg() *JSONBigInt {
+	return &JSONBigInt{value: new(big.Int).Neg(b.value)}
+}
+
+// Quo 返回b / other向零截断的商，other为0时返回错误
+func (b *JSONBigInt) Quo(other *JSONBigInt) (*JSONBigInt, error) {
+	if other.value.Sign() == 0 {
+		return nil, errors.NewJSONError(errors.ErrOperationFailed, "除数为0")
+	}
+	return &JSONBigInt{value: new(big.Int).Quo(b.value, other.value)}, nil
+}
+
+// Rem 返回b / other向零截断的余数，符号与b相同，other为0时返回错误
+func (b *JSONBigInt) Rem(other *JSONBigInt) (*JSONBigInt, error) {
+	if other.value.Sign() == 0 {
+		return nil, errors.NewJSONError(errors.ErrOperationFailed, "除数为0")
+	}
+	return &JSONBigInt{value: new(big.Int).Rem(b.value, other.value)}, nil
+}
+
+// Decimal 将整数转换为小数位数为0的JSONDecimal
+func (b *JSONBigInt) Decimal() *JSONDecimal {
+	return &JSONDecimal{unscaled: new(big.Int).Set(b.value)}
+}
+
+// rat 返回整数的精确有理数表示
+func (b *JSONBigInt) rat() *big.Rat {
+	return new(big.Rat).SetInt(b.value)
+}
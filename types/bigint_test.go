@@ -0,0 +1,56 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestJSONBigInt(t *testing.T) {
+	b, err := ParseJSONBigInt("-12345678901234567890123")
+	if err != nil {
+		t.Fatalf("ParseJSONBigInt() error = %v", err)
+	}
+	if b.String() != "-12345678901234567890123" || b.Type() != "number" || !b.IsNumber() {
+		t.Errorf("ParseJSONBigInt() = %v (%s)", b, b.Type())
+	}
+	if data, _ := b.MarshalJSON(); string(data) != "-12345678901234567890123" {
+		t.Errorf("MarshalJSON() = %s", data)
+	}
+	if _, err := ParseJSONBigInt("1.5"); err == nil {
+		t.Error("ParseJSONBigInt(1.5) 应该返回错误")
+	}
+
+	max := NewJSONBigIntFromInt64(1 << 62)
+	sum := max.Add(max).Add(NewJSONBigIntFromInt64(1))
+	if sum.String() != "9223372036854775809" || sum.IsInt64() {
+		t.Errorf("Add() = %v", sum)
+	}
+	if got := sum.Sub(max).Mul(NewJSONBigIntFromInt64(2)).Neg(); got.String() != "-9223372036854775810" {
+		t.Errorf("Sub().Mul().Neg() = %v", got)
+	}
+	if q, _ := sum.Quo(NewJSONBigIntFromInt64(10)); q.String() != "922337203685477580" {
+		t.Errorf("Quo() = %v", q)
+	}
+	if r, _ := sum.Rem(NewJSONBigIntFromInt64(10)); r.Int64() != 9 {
+		t.Errorf("Rem() = %v", r)
+	}
+	if _, err := sum.Quo(NewJSONBigIntFromInt64(0)); err == nil {
+		t.Error("Quo(0) 应该返回错误")
+	}
+
+	// 构造函数复制参数，修改原来的big.Int不影响JSONBigInt
+	src := big.NewInt(7)
+	v := NewJSONBigInt(src)
+	src.SetInt64(8)
+	if v.Int64() != 7 || v.BigInt().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("NewJSONBigInt() 没有复制参数: %v", v)
+	}
+
+	obj := NewJSONObject().Put("id", sum)
+	if obj.String() != `{"id":9223372036854775809}` {
+		t.Errorf("包含JSONBigInt的对象 = %v", obj.String())
+	}
+	if got, err := FromInterface(big.NewInt(42)); err != nil || got.String() != "42" || !IsExactNumber(got) {
+		t.Errorf("FromInterface(*big.Int) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,141 @@
+package types
+
+import "github.com/UserLeeZJ/gojson/errors"
+
+// ErrMissing 是可选链的值不存在时Chain.Get返回的错误，可以用errors.Is判断
+var ErrMissing = errors.NewJSONError(errors.ErrPathNotFound, "可选链的值不存在")
+
+// Chain 是类似JavaScript可选链（a?.b?.[0]）的访问器。
+// 属性不存在、索引超出范围、在null或类型不匹配的值上继续访问时，链变为缺失状态并一直传递下去，
+// 中间的步骤从不返回错误，只在最后的取值方法中报告一次：
+//
+//	name := doc.Q("user").Q("profile").Q("name").StringOr("匿名")
+//	first, ok := doc.Q("items").Idx(0).Object()
+//
+// 与Accessor相比，Chain不记录路径，适合缺失是正常情况、只需要默认值的场景。
+// Chain是值类型，零值表示缺失
+type Chain struct {
+	value JSONValue // nil表示缺失
+}
+
+// Maybe 从任意JSON值开始可选链，value为nil时链是缺失的
+func Maybe(value JSONValue) Chain {
+	return Chain{value: value}
+}
+
+// Q 从对象开始可选链并访问属性key
+func (o *JSONObject) Q(key string) Chain {
+	return Maybe(o).Q(key)
+}
+
+// Idx 从数组开始可选链并访问第index个元素
+func (a *JSONArray) Idx(index int) Chain {
+	return Maybe(a).Idx(index)
+}
+
+// Q 访问属性key，当前值不是对象或属性不存在时返回缺失的链
+func (c Chain) Q(key string) Chain {
+	if c.value == nil || !c.value.IsObject() {
+		return Chain{}
+	}
+	obj, _ := c.value.AsObject()
+	value, ok := obj.lookup(key)
+	if !ok {
+		return Chain{}
+	}
+	if value == nil {
+		value = NewJSONNull()
+	}
+	return Chain{value: value}
+}
+
+// Idx 访问第index个元素，负数从末尾开始计数（-1是最后一个元素）。
+// 当前值不是数组或索引超出范围时返回缺失的链
+func (c Chain) Idx(index int) Chain {
+	if c.value == nil || !c.value.IsArray() {
+		return Chain{}
+	}
+	arr, _ := c.value.AsArray()
+	if index < 0 {
+		index += len(arr.elements)
+	}
+	if index < 0 || index >= len(arr.elements) {
+		return Chain{}
+	}
+	value := arr.elements[index]
+	if value == nil {
+		value = NewJSONNull()
+	}
+	return Chain{value: value}
+}
+
+// Missing 检查链是否缺失。存在的null值不是缺失
+func (c Chain) Missing() bool {
+	return c.value == nil
+}
+
+// Get 返回链的值，链缺失时返回ErrMissing
+func (c Chain) Get() (JSONValue, error) {
+	if c.value == nil {
+		return nil, ErrMissing
+	}
+	return c.value, nil
+}
+
+// Value 返回链的值，链缺失时返回nil
+func (c Chain) Value() JSONValue {
+	return c.value
+}
+
+// Object 返回链的对象，链缺失或值不是对象时第二个返回值为false
+func (c Chain) Object() (*JSONObject, bool) {
+	if c.value == nil || !c.value.IsObject() {
+		return nil, false
+	}
+	obj, _ := c.value.AsObject()
+	return obj, true
+}
+
+// Array 返回链的数组，链缺失或值不是数组时第二个返回值为false
+func (c Chain) Array() (*JSONArray, bool) {
+	if c.value == nil || !c.value.IsArray() {
+		return nil, false
+	}
+	arr, _ := c.value.AsArray()
+	return arr, true
+}
+
+// NumberOr 返回链的数字，链缺失或值不是数字时返回def
+func (c Chain) NumberOr(def float64) float64 {
+	if c.value == nil || !c.value.IsNumber() {
+		return def
+	}
+	n, _ := c.value.AsNumber()
+	return n
+}
+
+// StringOr 返回链的字符串，链缺失或值不是字符串时返回def
+func (c Chain) StringOr(def string) string {
+	if c.value == nil || !c.value.IsString() {
+		return def
+	}
+	s, _ := c.value.AsString()
+	return s
+}
+
+// BooleanOr 返回链的布尔值，链缺失或值不是布尔值时返回def
+func (c Chain) BooleanOr(def bool) bool {
+	if c.value == nil || !c.value.IsBoolean() {
+		return def
+	}
+	b, _ := c.value.AsBoolean()
+	return b
+}
+
+// Or 返回链的值，链缺失或值为null时返回def，类似JavaScript的??运算符
+func (c Chain) Or(def JSONValue) JSONValue {
+	if c.value == nil || c.value.IsNull() {
+		return def
+	}
+	return c.value
+}
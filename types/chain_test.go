@@ -0,0 +1,73 @@
+package types
+
+import (
+	goerrors "errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	doc := NewJSONObject().
+		Put("user", NewJSONObject().
+			Put("profile", NewJSONObject().PutString("name", "Ada").PutNull("nickname")).
+			Put("tags", NewJSONArray().AddString("a").AddString("b").AddNumber(3)).
+			PutBoolean("admin", true))
+
+	if got := doc.Q("user").Q("profile").Q("name").StringOr("?"); got != "Ada" {
+		t.Errorf("StringOr() = %q, want Ada", got)
+	}
+	if got := doc.Q("user").Q("tags").Idx(-1).NumberOr(0); got != 3 {
+		t.Errorf("Idx(-1).NumberOr() = %v, want 3", got)
+	}
+	if got := doc.Q("user").Q("admin").BooleanOr(false); !got {
+		t.Errorf("BooleanOr() = false, want true")
+	}
+	if obj, ok := doc.Q("user").Q("profile").Object(); !ok || obj.Size() != 2 {
+		t.Errorf("Object() = %v, %v", obj, ok)
+	}
+	if arr, ok := doc.Q("user").Q("tags").Array(); !ok || arr.Size() != 3 {
+		t.Errorf("Array() = %v, %v", arr, ok)
+	}
+
+	// 存在的null不是缺失，但Or把它视为没有值
+	nickname := doc.Q("user").Q("profile").Q("nickname")
+	if nickname.Missing() || !nickname.Value().IsNull() {
+		t.Errorf("nickname should be a present null")
+	}
+	if got := nickname.Or(NewJSONString("none")).String(); got != `"none"` {
+		t.Errorf("Or() = %s", got)
+	}
+
+	// 缺失一直传递到最后的取值方法
+	missing := []Chain{
+		doc.Q("nobody").Q("profile").Q("name"),
+		doc.Q("user").Q("profile").Q("nickname").Q("first"),
+		doc.Q("user").Q("tags").Idx(3),
+		doc.Q("user").Q("tags").Idx(-4),
+		doc.Q("user").Idx(0).Q("x"),
+		doc.Q("user").Q("admin").Q("x"),
+		Maybe(nil).Q("a"),
+		Chain{}.Idx(0),
+	}
+	for i, c := range missing {
+		if !c.Missing() || c.Value() != nil {
+			t.Errorf("chain %d should be missing, got %v", i, c.Value())
+		}
+		if _, err := c.Get(); !goerrors.Is(err, ErrMissing) {
+			t.Errorf("chain %d: Get() error = %v, want ErrMissing", i, err)
+		}
+		if c.StringOr("d") != "d" || c.NumberOr(-1) != -1 || c.BooleanOr(true) != true {
+			t.Errorf("chain %d: defaults not returned", i)
+		}
+		if _, ok := c.Object(); ok {
+			t.Errorf("chain %d: Object() ok = true", i)
+		}
+	}
+
+	// 类型不匹配的取值返回默认值
+	if got := doc.Q("user").Q("tags").Idx(0).NumberOr(7); got != 7 {
+		t.Errorf("NumberOr() of a string = %v, want 7", got)
+	}
+	if v, err := NewJSONArray().AddNumber(1).Idx(0).Get(); err != nil || v.String() != "1" {
+		t.Errorf("JSONArray.Idx().Get() = %v, %v", v, err)
+	}
+}
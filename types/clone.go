@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// DeepCopyValue 深度复制JSON值，容器包含它自身时返回循环引用错误。
+// 同一个容器在不相交的位置出现多次不是循环，每处都会复制为独立的值。
+// 复制的对象保留忽略大小写的设置
+func DeepCopyValue(value JSONValue) (JSONValue, error) {
+	return deepCopy(value, "$", make(map[JSONValue]bool))
+}
+
+// deepCopy 递归复制值，ancestors记录从根到当前位置的所有容器
+func deepCopy(value JSONValue, path string, ancestors map[JSONValue]bool) (JSONValue, error) {
+	if value == nil || value.IsNull() {
+		return NewJSONNull(), nil
+	}
+
+	switch {
+	case value.IsObject():
+		if ancestors[value] {
+			return nil, errors.NewJSONError(errors.ErrCircularReference, "对象包含对自身的引用").WithPath(path)
+		}
+		ancestors[value] = true
+		defer delete(ancestors, value)
+
+		obj, _ := value.AsObject()
+		result := NewJSONObject().SetCaseInsensitive(obj.CaseInsensitive())
+		for _, key := range obj.Keys() {
+			child, err := deepCopy(obj.Get(key), ChildPath(path, KeySegment(key)), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			result.Put(key, child)
+		}
+		return result, nil
+	case value.IsArray():
+		if ancestors[value] {
+			return nil, errors.NewJSONError(errors.ErrCircularReference, "数组包含对自身的引用").WithPath(path)
+		}
+		ancestors[value] = true
+		defer delete(ancestors, value)
+
+		arr, _ := value.AsArray()
+		elements := make([]JSONValue, arr.Size())
+		for i := range elements {
+			child, err := deepCopy(arr.Get(i), ChildPath(path, IndexSegment(i)), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = child
+		}
+		return NewJSONArrayFromValuesUnsafe(elements), nil
+	case value.IsString():
+		str, _ := value.AsString()
+		return NewJSONString(str), nil
+	case IsExactNumber(value):
+		// JSONBigInt和JSONDecimal不可变，直接共享以保留精度
+		return value, nil
+	case value.IsNumber():
+		num, _ := value.AsNumber()
+		return NewJSONNumber(num), nil
+	case value.IsBoolean():
+		b, _ := value.AsBoolean()
+		return NewJSONBool(b), nil
+	default:
+		return nil, errors.NewJSONError(errors.ErrNotSupported,
+			fmt.Sprintf("不支持的JSON值类型: %s", value.Type())).WithPath(path)
+	}
+}
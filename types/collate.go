@@ -0,0 +1,114 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// KeyOrder 定义对象键的排序规则，返回a是否应排在b之前。为nil时按字节序排序
+type KeyOrder func(a, b string) bool
+
+// CaseInsensitiveOrder 忽略大小写比较键，只有大小写不同的键按字节序排列，保证结果确定
+func CaseInsensitiveOrder(a, b string) bool {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	if la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+// NaturalOrder 按自然顺序比较键：连续的数字按数值比较，因此"item2"排在"item10"之前。
+// 数值相同时前导零少的排在前面，其他部分按字节序比较
+func NaturalOrder(a, b string) bool {
+	if c := naturalCompare(a, b); c != 0 {
+		return c < 0
+	}
+	return a < b
+}
+
+// Collate 把三路比较函数转换为KeyOrder，比较结果为0的键按字节序排列。
+// 需要按特定语言排序时可以使用golang.org/x/text/collate：
+//
+//	order := types.Collate(collate.New(language.German).CompareString)
+func Collate(compare func(a, b string) int) KeyOrder {
+	return func(a, b string) bool {
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		return a < b
+	}
+}
+
+// SortStrings 按order原地排序keys，order为nil时按字节序排序
+func SortStrings(keys []string, order KeyOrder) {
+	if order == nil {
+		sort.Strings(keys)
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return order(keys[i], keys[j])
+	})
+}
+
+// naturalCompare 逐段比较a和b，数字段按数值比较，返回-1、0或1
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			if c := compareDigits(a[si:i], b[sj:j]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return 0
+}
+
+// compareDigits 按数值比较两个数字串，数值相同时前导零少的较小
+func compareDigits(a, b string) int {
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	switch {
+	case len(ta) != len(tb):
+		if len(ta) < len(tb) {
+			return -1
+		}
+		return 1
+	case ta != tb:
+		if ta < tb {
+			return -1
+		}
+		return 1
+	case len(a) != len(b):
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// isDigit 检查字节是否是ASCII数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
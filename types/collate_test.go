@@ -0,0 +1,57 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyOrders(t *testing.T) {
+	keys := []string{"item10", "Item2", "item2", "item02", "item1", "b", "A", "a", "item", "x9y", "x10y", "x9z"}
+	tests := []struct {
+		name  string
+		order KeyOrder
+		want  []string
+	}{
+		{"byte", nil, []string{"A", "Item2", "a", "b", "item", "item02", "item1", "item10", "item2", "x10y", "x9y", "x9z"}},
+		{"case-insensitive", CaseInsensitiveOrder, []string{"A", "a", "b", "item", "item02", "item1", "item10", "Item2", "item2", "x10y", "x9y", "x9z"}},
+		{"natural", NaturalOrder, []string{"A", "Item2", "a", "b", "item", "item1", "item2", "item02", "item10", "x9y", "x9z", "x10y"}},
+		{"collate", Collate(func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}), []string{"A", "a", "b", "item", "item02", "item1", "item10", "Item2", "item2", "x10y", "x9y", "x9z"}},
+	}
+	for _, tt := range tests {
+		got := append([]string(nil), keys...)
+		SortStrings(got, tt.order)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SortStrings() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalOrderConsistency(t *testing.T) {
+	keys := []string{"", "0", "00", "1", "01", "a0", "a00", "a1b", "a01b", "a1", "1a", "10", "9", "99999999999999999999", "100000000000000000000"}
+	for _, a := range keys {
+		if NaturalOrder(a, a) {
+			t.Errorf("NaturalOrder(%q, %q) = true", a, a)
+		}
+		for _, b := range keys {
+			if a != b && NaturalOrder(a, b) == NaturalOrder(b, a) {
+				t.Errorf("NaturalOrder(%q, %q) is not antisymmetric", a, b)
+			}
+		}
+	}
+	if !NaturalOrder("99999999999999999999", "100000000000000000000") {
+		t.Errorf("long numbers should compare by value")
+	}
+}
+
+func TestSortedKeysBy(t *testing.T) {
+	obj := NewJSONObject().PutNumber("f10", 1).PutNumber("f2", 2).PutNumber("F1", 3)
+	if got := obj.SortedKeysBy(NaturalOrder); !reflect.DeepEqual(got, []string{"F1", "f2", "f10"}) {
+		t.Errorf("SortedKeysBy(NaturalOrder) = %v", got)
+	}
+	if got := obj.SortedKeysBy(nil); !reflect.DeepEqual(got, obj.SortedKeys()) {
+		t.Errorf("SortedKeysBy(nil) = %v, want %v", got, obj.SortedKeys())
+	}
+}
@@ -0,0 +1,333 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// maxDecimalExponent 是ParseJSONDecimal接受的最大指数绝对值，防止"1e999999999"分配巨大的整数
+const maxDecimalExponent = 100000
+
+// JSONDecimal 表示定点小数，值为unscaled × 10^-scale，用于金额等不能有二进制舍入误差的数据。
+// JSONDecimal的类型是number，String和MarshalJSON按小数位数输出，保留末尾的0（"1.50"）。
+// JSONDecimal不可变，算术方法返回新的值；舍入一律采用四舍五入（远离0）
+type JSONDecimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// NewJSONDecimal 创建值为unscaled × 10^-scale的JSONDecimal，复制unscaled，
+// 例如NewJSONDecimal(big.NewInt(12345), 2)表示123.45。scale为负数时转换为整数
+func NewJSONDecimal(unscaled *big.Int, scale int) *JSONDecimal {
+	v := new(big.Int)
+	if unscaled != nil {
+		v.Set(unscaled)
+	}
+	return newDecimal(v, scale)
+}
+
+// NewJSONDecimalFromInt64 创建值为value × 10^-scale的JSONDecimal
+func NewJSONDecimalFromInt64(value int64, scale int) *JSONDecimal {
+	return newDecimal(big.NewInt(value), scale)
+}
+
+// NewJSONDecimalFromFloat 按float64的最短十进制表示创建JSONDecimal，0.1得到精确的0.1。
+// value为NaN或无穷大时返回错误
+func NewJSONDecimalFromFloat(value float64) (*JSONDecimal, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, errors.NewJSONError(errors.ErrTypeConversion,
+			"无法转换为小数: "+strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	return ParseJSONDecimal(strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// ParseJSONDecimal 解析十进制数字字符串，接受JSON数字语法以及前导的+号，
+// 例如"-12.50"、"1e3"、"2.5E-2"。小数位数由字符串决定，"1.50"的小数位数为2
+func ParseJSONDecimal(s string) (*JSONDecimal, error) {
+	invalid := func() error {
+		return errors.NewJSONError(errors.ErrTypeConversion, fmt.Sprintf("无效的小数: %q", s))
+	}
+
+	str := s
+	negative := false
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	exponent := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(str[i+1:])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return nil, invalid()
+		}
+		exponent = exp
+		str = str[:i]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+		if fracPart == "" {
+			return nil, invalid()
+		}
+	}
+	digits := intPart + fracPart
+	if intPart == "" || !isDigits(digits) {
+		return nil, invalid()
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+	return newDecimal(unscaled, len(fracPart)-exponent), nil
+}
+
+// isDigits 检查s是否只包含ASCII数字
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newDecimal 使用unscaled创建JSONDecimal，不复制unscaled，负的scale转换为整数
+func newDecimal(unscaled *big.Int, scale int) *JSONDecimal {
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return &JSONDecimal{unscaled: unscaled, scale: scale}
+}
+
+// pow10 返回10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Type 返回JSON值的类型
+func (d *JSONDecimal) Type() string {
+	return "number"
+}
+
+// String 返回小数的十进制表示，不使用科学计数法
+func (d *JSONDecimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// MarshalJSON 实现json.Marshaler接口，输出精确的十进制表示
+func (d *JSONDecimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// IsNull 检查值是否为null
+func (d *JSONDecimal) IsNull() bool {
+	return false
+}
+
+// IsBoolean 检查值是否为布尔值
+func (d *JSONDecimal) IsBoolean() bool {
+	return false
+}
+
+// IsNumber 检查值是否为数字
+func (d *JSONDecimal) IsNumber() bool {
+	return true
+}
+
+// IsString 检查值是否为字符串
+func (d *JSONDecimal) IsString() bool {
+	return false
+}
+
+// IsArray 检查值是否为数组
+func (d *JSONDecimal) IsArray() bool {
+	return false
+}
+
+// IsObject 检查值是否为对象
+func (d *JSONDecimal) IsObject() bool {
+	return false
+}
+
+// AsBoolean 将值转换为布尔值，非0为true
+func (d *JSONDecimal) AsBoolean() (bool, error) {
+	return d.unscaled.Sign() != 0, nil
+}
+
+// AsNumber 返回最接近的float64，是否在丢失精度时返回错误由CurrentNumericPolicy决定
+func (d *JSONDecimal) AsNumber() (float64, error) {
+	return CurrentNumericPolicy().Float64(d)
+}
+
+// AsString 将值转换为字符串
+func (d *JSONDecimal) AsString() (string, error) {
+	return d.String(), nil
+}
+
+// AsArray 将值转换为数组
+func (d *JSONDecimal) AsArray() (*JSONArray, error) {
+	return nil, errors.ErrInvalidTypeWithDetails("array", "number")
+}
+
+// AsObject 将值转换为对象
+func (d *JSONDecimal) AsObject() (*JSONObject, error) {
+	return nil, errors.ErrInvalidTypeWithDetails("object", "number")
+}
+
+// Unscaled 返回去掉小数点后的整数的副本
+func (d *JSONDecimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale 返回小数位数
+func (d *JSONDecimal) Scale() int {
+	return d.scale
+}
+
+// Sign 返回小数的符号：-1、0或1
+func (d *JSONDecimal) Sign() int {
+	return d.unscaled.Sign()
+}
+
+// IsInteger 检查小数是否没有非0的小数部分
+func (d *JSONDecimal) IsInteger() bool {
+	if d.scale == 0 {
+		return true
+	}
+	return new(big.Int).Rem(d.unscaled, pow10(d.scale)).Sign() == 0
+}
+
+// BigInt 返回向零截断后的整数部分
+func (d *JSONDecimal) BigInt() *JSONBigInt {
+	if d.scale == 0 {
+		return NewJSONBigInt(d.unscaled)
+	}
+	return &JSONBigInt{value: new(big.Int).Quo(d.unscaled, pow10(d.scale))}
+}
+
+// Cmp 按数值比较两个小数，不考虑小数位数，1.5与1.50相等
+func (d *JSONDecimal) Cmp(other *JSONDecimal) int {
+	a, b := align(d, other)
+	return a.Cmp(b)
+}
+
+// Add 返回d + other，小数位数取两者中较大的
+func (d *JSONDecimal) Add(other *JSONDecimal) *JSONDecimal {
+	a, b := align(d, other)
+	return &JSONDecimal{unscaled: a.Add(a, b), scale: maxInt(d.scale, other.scale)}
+}
+
+// Sub 返回d - other，小数位数取两者中较大的
+func (d *JSONDecimal) Sub(other *JSONDecimal) *JSONDecimal {
+	a, b := align(d, other)
+	return &JSONDecimal{unscaled: a.Sub(a, b), scale: maxInt(d.scale, other.scale)}
+}
+
+// Mul 返回d * other，小数位数为两者之和
+func (d *JSONDecimal) Mul(other *JSONDecimal) *JSONDecimal {
+	return &JSONDecimal{unscaled: new(big.Int).Mul(d.unscaled, other.unscaled), scale: d.scale + other.scale}
+}
+
+// Div 返回d / other四舍五入到places位小数的结果，other为0时返回错误
+func (d *JSONDecimal) Div(other *JSONDecimal, places int) (*JSONDecimal, error) {
+	if other.unscaled.Sign() == 0 {
+		return nil, errors.NewJSONError(errors.ErrOperationFailed, "除数为0")
+	}
+	return roundRat(new(big.Rat).Quo(d.rat(), other.rat()), places), nil
+}
+
+// Neg 返回-d
+func (d *JSONDecimal) Neg() *JSONDecimal {
+	return &JSONDecimal{unscaled: new(big.Int).Neg(d.unscaled), scale: d.scale}
+}
+
+// Abs 返回d的绝对值
+func (d *JSONDecimal) Abs() *JSONDecimal {
+	return &JSONDecimal{unscaled: new(big.Int).Abs(d.unscaled), scale: d.scale}
+}
+
+// Round 四舍五入到places位小数，places大于当前的小数位数时在末尾补0，小于0时按0处理
+func (d *JSONDecimal) Round(places int) *JSONDecimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return d.rescale(places)
+	}
+	return roundRat(d.rat(), places)
+}
+
+// Truncate 向零截断到places位小数，places不小于当前的小数位数时返回d，小于0时按0处理
+func (d *JSONDecimal) Truncate(places int) *JSONDecimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return d
+	}
+	return &JSONDecimal{unscaled: new(big.Int).Quo(d.unscaled, pow10(d.scale-places)), scale: places}
+}
+
+// rescale 把小数位数增加到scale，scale不能小于当前的小数位数
+func (d *JSONDecimal) rescale(scale int) *JSONDecimal {
+	if scale == d.scale {
+		return d
+	}
+	return &JSONDecimal{unscaled: new(big.Int).Mul(d.unscaled, pow10(scale-d.scale)), scale: scale}
+}
+
+// rat 返回小数的精确有理数表示
+func (d *JSONDecimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.unscaled, pow10(d.scale))
+}
+
+// align 返回两个小数按相同的小数位数表示时的整数，结果是新分配的，可以直接修改
+func align(a, b *JSONDecimal) (*big.Int, *big.Int) {
+	scale := maxInt(a.scale, b.scale)
+	return a.rescale(scale).Unscaled(), b.rescale(scale).Unscaled()
+}
+
+// roundRat 把r四舍五入（远离0）到places位小数
+func roundRat(r *big.Rat, places int) *JSONDecimal {
+	if places < 0 {
+		places = 0
+	}
+	num := new(big.Int).Mul(r.Num(), pow10(places))
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	// |rem| * 2 >= denom 时远离0进位
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return &JSONDecimal{unscaled: quo, scale: places}
+}
+
+// maxInt 返回两个整数中较大的
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestParseJSONDecimal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		scale int
+	}{
+		{"0", "0", 0},
+		{"1.50", "1.50", 2},
+		{"-0.005", "-0.005", 3},
+		{"+12", "12", 0},
+		{"1e3", "1000", 0},
+		{"2.5E-2", "0.025", 3},
+		{"123.456e1", "1234.56", 2},
+	}
+	for _, tt := range tests {
+		d, err := ParseJSONDecimal(tt.input)
+		if err != nil {
+			t.Errorf("ParseJSONDecimal(%q) error = %v", tt.input, err)
+			continue
+		}
+		if d.String() != tt.want || d.Scale() != tt.scale {
+			t.Errorf("ParseJSONDecimal(%q) = %v (scale %d), want %v (scale %d)", tt.input, d, d.Scale(), tt.want, tt.scale)
+		}
+	}
+
+	for _, input := range []string{"", "-", ".5", "1.", "1e", "abc", "1.2.3", "1e999999999"} {
+		if _, err := ParseJSONDecimal(input); err == nil {
+			t.Errorf("ParseJSONDecimal(%q) 应该返回错误", input)
+		}
+	}
+}
+
+func TestJSONDecimalArithmetic(t *testing.T) {
+	a, _ := ParseJSONDecimal("0.1")
+	b, _ := ParseJSONDecimal("0.20")
+	if got := a.Add(b); got.String() != "0.30" {
+		t.Errorf("0.1 + 0.20 = %v, want 0.30", got)
+	}
+	if got := a.Sub(b); got.String() != "-0.10" {
+		t.Errorf("0.1 - 0.20 = %v, want -0.10", got)
+	}
+	if got := a.Mul(b); got.String() != "0.020" {
+		t.Errorf("0.1 * 0.20 = %v, want 0.020", got)
+	}
+	if a.Add(b).Cmp(NewJSONDecimalFromInt64(3, 1)) != 0 {
+		t.Error("0.30 应该等于 0.3")
+	}
+
+	price := NewJSONDecimalFromInt64(1000, 2)
+	if got, _ := price.Div(NewJSONDecimalFromInt64(3, 0), 2); got.String() != "3.33" {
+		t.Errorf("10.00 / 3 = %v, want 3.33", got)
+	}
+	if got, _ := price.Neg().Div(NewJSONDecimalFromInt64(6, 0), 2); got.String() != "-1.67" {
+		t.Errorf("-10.00 / 6 = %v, want -1.67", got)
+	}
+	if _, err := price.Div(NewJSONDecimalFromInt64(0, 2), 2); err == nil {
+		t.Error("Div(0) 应该返回错误")
+	}
+
+	half, _ := ParseJSONDecimal("-2.345")
+	if got := half.Round(2); got.String() != "-2.35" {
+		t.Errorf("Round(2) = %v, want -2.35", got)
+	}
+	if got := half.Round(5); got.String() != "-2.34500" {
+		t.Errorf("Round(5) = %v, want -2.34500", got)
+	}
+	if got := half.Truncate(1); got.String() != "-2.3" {
+		t.Errorf("Truncate(1) = %v, want -2.3", got)
+	}
+	if got := half.Abs().BigInt(); got.String() != "2" {
+		t.Errorf("BigInt() = %v, want 2", got)
+	}
+	if half.IsInteger() || !NewJSONDecimalFromInt64(500, 2).IsInteger() {
+		t.Error("IsInteger() 结果错误")
+	}
+	if f, _ := half.AsNumber(); f != -2.345 {
+		t.Errorf("AsNumber() = %v", f)
+	}
+
+	if d, err := NewJSONDecimalFromFloat(0.1); err != nil || d.String() != "0.1" {
+		t.Errorf("NewJSONDecimalFromFloat(0.1) = %v, %v", d, err)
+	}
+	arr := NewJSONArray().Add(NewJSONDecimalFromInt64(150, 2))
+	if arr.String() != "[1.50]" {
+		t.Errorf("包含JSONDecimal的数组 = %v", arr.String())
+	}
+}
@@ -0,0 +1,243 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// DefaultStreamingThreshold 是对象和数组的String、MarshalJSON改用流式编码的默认节点数
+const DefaultStreamingThreshold = 4096
+
+// streamingThreshold 是当前的流式编码阈值，负数表示禁用
+var streamingThreshold int64 = DefaultStreamingThreshold
+
+// SetStreamingThreshold 设置流式编码的阈值并返回原来的阈值。
+// 节点数（包括容器本身和所有嵌套的值）达到n的对象和数组在String和MarshalJSON中
+// 直接写入池化的缓冲区，而不是先转换为interface{}树再交给encoding/json，
+// 大型文档的峰值内存大约减半。两种方式的输出完全相同。
+// n为0时总是使用流式编码，为负数时不使用流式编码。
+// 包含MultiValueObject的值总是使用流式编码，因为interface{}树无法保留重复的键
+func SetStreamingThreshold(n int) int {
+	return int(atomic.SwapInt64(&streamingThreshold, int64(n)))
+}
+
+// StreamingThreshold 返回当前的流式编码阈值
+func StreamingThreshold() int {
+	return int(atomic.LoadInt64(&streamingThreshold))
+}
+
+// useStreaming 判断是否用流式编码序列化v
+func useStreaming(v JSONValue) bool {
+	remaining := atomic.LoadInt64(&streamingThreshold)
+	if remaining < 0 {
+		// 禁用时仍然需要检查是否包含MultiValueObject
+		remaining = math.MaxInt64
+	}
+	return !fewerNodes(v, &remaining)
+}
+
+// fewerNodes 在v的节点数少于remaining时返回true，节点数足够或遇到MultiValueObject时提前停止遍历
+func fewerNodes(v JSONValue, remaining *int64) bool {
+	*remaining--
+	if *remaining <= 0 {
+		return false
+	}
+	switch val := v.(type) {
+	case *MultiValueObject:
+		return false
+	case *JSONObject:
+		for _, child := range val.properties {
+			if child != nil && !fewerNodes(child, remaining) {
+				return false
+			}
+		}
+	case *JSONArray:
+		for _, child := range val.elements {
+			if child != nil && !fewerNodes(child, remaining) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 缓冲区池的参数，与fast包相同
+const (
+	encodeBufSize    = 4096
+	maxEncodeBufSize = 1024 * 1024
+)
+
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, encodeBufSize))
+	},
+}
+
+// encodeValue 用池化的缓冲区编码v，把结果交给emit。emit返回后缓冲区被回收，不能保留它的内容
+func encodeValue(v JSONValue, emit func(data []byte) error) error {
+	buf := encodeBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		// 容量过大的缓冲区不放回池中，让GC回收
+		if buf.Cap() <= maxEncodeBufSize {
+			encodeBufferPool.Put(buf)
+		}
+	}()
+	if err := writeValue(buf, v); err != nil {
+		return err
+	}
+	return emit(buf.Bytes())
+}
+
+// marshalStreaming 用流式编码序列化v
+func marshalStreaming(v JSONValue) ([]byte, error) {
+	var result []byte
+	err := encodeValue(v, func(data []byte) error {
+		result = append([]byte(nil), data...)
+		return nil
+	})
+	return result, err
+}
+
+// stringStreaming 用流式编码返回v的字符串表示
+func stringStreaming(v JSONValue) (string, error) {
+	var result string
+	err := encodeValue(v, func(data []byte) error {
+		result = string(data)
+		return nil
+	})
+	return result, err
+}
+
+// WriteJSON 把v的紧凑JSON表示写入w，不论大小都使用流式编码。
+// 输出与MarshalJSON相同：对象的键按字典序排列，字符串按encoding/json的规则转义
+func WriteJSON(w io.Writer, v JSONValue) error {
+	return encodeValue(v, func(data []byte) error {
+		if _, err := w.Write(data); err != nil {
+			return errors.NewJSONError(errors.ErrOperationFailed, "写入JSON失败").WithCause(err)
+		}
+		return nil
+	})
+}
+
+// writeValue 按ValueToInterface加json.Marshal的规则把v写入buf
+func writeValue(buf *bytes.Buffer, v JSONValue) error {
+	if v == nil || v.IsNull() {
+		buf.WriteString("null")
+		return nil
+	}
+	if m, ok := v.(*MultiValueObject); ok {
+		return m.write(buf)
+	}
+	switch v.Type() {
+	case "boolean":
+		b, _ := v.AsBoolean()
+		buf.WriteString(strconv.FormatBool(b))
+	case "number":
+		if IsExactNumber(v) {
+			buf.WriteString(v.String())
+			return nil
+		}
+		n, _ := v.AsNumber()
+		return writeNumber(buf, n)
+	case "string":
+		s, _ := v.AsString()
+		writeString(buf, s)
+	case "array":
+		arr, _ := v.AsArray()
+		buf.WriteByte('[')
+		for i, element := range arr.elements {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeValue(buf, element); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case "object":
+		obj, _ := v.AsObject()
+		keys := make([]string, 0, len(obj.properties))
+		for key := range obj.properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, key)
+			buf.WriteByte(':')
+			if err := writeValue(buf, obj.properties[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+// writeNumber 按encoding/json的格式写入数字：指数很大或很小时使用科学计数法
+func writeNumber(buf *bytes.Buffer, n float64) error {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return errors.NewJSONError(errors.ErrInvalidType, "无法序列化数字"+strconv.FormatFloat(n, 'g', -1, 64))
+	}
+	format := byte('f')
+	if abs := math.Abs(n); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	var scratch [64]byte
+	b := strconv.AppendFloat(scratch[:0], n, format, -1, 64)
+	if format == 'e' {
+		// 把e-09写作e-9
+		if l := len(b); l >= 4 && b[l-4] == 'e' && b[l-3] == '-' && b[l-2] == '0' {
+			b[l-2] = b[l-1]
+			b = b[:l-1]
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// writeString 写入带引号的字符串。只包含不需要转义的字符时直接写入，
+// 否则交给encoding/json，保证转义规则（包括HTML字符）与MarshalJSON相同
+func writeString(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c < 0x20 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+				writeEscapedString(buf, s)
+				return
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' {
+			writeEscapedString(buf, s)
+			return
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+	buf.WriteString(s)
+	buf.WriteByte('"')
+}
+
+// writeEscapedString 用encoding/json写入需要转义的字符串
+func writeEscapedString(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// withStreamingThreshold 在测试期间设置流式编码阈值
+func withStreamingThreshold(t *testing.T, n int) {
+	old := SetStreamingThreshold(n)
+	t.Cleanup(func() { SetStreamingThreshold(old) })
+}
+
+// randomValue 生成包含各种需要特殊处理的字符串和数字的随机值
+func randomValue(rng *rand.Rand, depth int) JSONValue {
+	strs := []string{"", "plain", "中文", "<a href=\"x\">&</a>", "tab\there", "line\nbreak", "\x01\x1f",
+		"back\\slash", "sep\u2028\u2029", "bad\xffutf8", "emoji 😀", "\x7f"}
+	nums := []float64{0, -0.0, 1, -1.5, 0.1, 1e20, 1e21, 123456789012, 1e-6, 1e-7, 5e-324, math.MaxFloat64, -2.5e-9}
+	kind := rng.Intn(7)
+	if depth <= 0 {
+		kind %= 5
+	}
+	switch kind {
+	case 0:
+		return NewJSONNull()
+	case 1:
+		return NewJSONBool(rng.Intn(2) == 0)
+	case 2:
+		return NewJSONNumber(nums[rng.Intn(len(nums))])
+	case 3, 4:
+		return NewJSONString(strs[rng.Intn(len(strs))])
+	case 5:
+		arr := NewJSONArray()
+		for i := rng.Intn(5); i > 0; i-- {
+			arr.Add(randomValue(rng, depth-1))
+		}
+		return arr
+	default:
+		obj := NewJSONObject()
+		for i := rng.Intn(5); i > 0; i-- {
+			obj.Put(strs[rng.Intn(len(strs))]+string(rune('a'+rng.Intn(3))), randomValue(rng, depth-1))
+		}
+		return obj
+	}
+}
+
+func TestStreamingMatchesInterface(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		value := randomValue(rng, 4)
+		want, wantErr := marshalViaInterface(value)
+		got, err := marshalStreaming(value)
+		if (err != nil) != (wantErr != nil) || string(got) != string(want) {
+			t.Fatalf("marshalStreaming() = %s, %v, want %s, %v", got, err, want, wantErr)
+		}
+	}
+}
+
+// marshalViaInterface 用转换为interface{}树的方式序列化容器，其他值直接调用MarshalJSON
+func marshalViaInterface(v JSONValue) ([]byte, error) {
+	switch val := v.(type) {
+	case *JSONObject:
+		return val.marshalInterface()
+	case *JSONArray:
+		return val.marshalInterface()
+	}
+	return v.MarshalJSON()
+}
+
+func TestStreamingThreshold(t *testing.T) {
+	obj := NewJSONObject().
+		PutString("name", "<b>").
+		Put("items", NewJSONArrayFromValues([]JSONValue{NewJSONNumber(1e21), NewJSONNull(), NewJSONBool(true)})).
+		Put("nested", NewJSONObject().PutNumber("z", 0.5).PutNumber("a", -1))
+	want := `{"items":[1e+21,null,true],"name":"\u003cb\u003e","nested":{"a":-1,"z":0.5}}`
+
+	// obj共有9个节点
+	for _, threshold := range []int{-1, 0, 9, 10, DefaultStreamingThreshold} {
+		withStreamingThreshold(t, threshold)
+		if got := obj.String(); got != want {
+			t.Errorf("threshold %d: String() = %s, want %s", threshold, got, want)
+		}
+		if got, err := obj.MarshalJSON(); err != nil || string(got) != want {
+			t.Errorf("threshold %d: MarshalJSON() = %s, %v", threshold, got, err)
+		}
+	}
+
+	withStreamingThreshold(t, 9)
+	if !useStreaming(obj) {
+		t.Errorf("useStreaming() = false for a value at the threshold")
+	}
+	withStreamingThreshold(t, 10)
+	if useStreaming(obj) {
+		t.Errorf("useStreaming() = true for a value below the threshold")
+	}
+	if got := StreamingThreshold(); got != 10 {
+		t.Errorf("StreamingThreshold() = %d, want 10", got)
+	}
+}
+
+func TestStreamingErrors(t *testing.T) {
+	withStreamingThreshold(t, 0)
+	arr := NewJSONArray().Add(NewJSONNumber(math.NaN()))
+	if _, err := arr.MarshalJSON(); err == nil {
+		t.Errorf("MarshalJSON() of NaN should fail")
+	}
+	if arr.String() != "[]" {
+		t.Errorf("String() = %s, want []", arr.String())
+	}
+	if NewJSONObject().Put("n", NewJSONNumber(math.Inf(1))).String() != "{}" {
+		t.Errorf("String() of +Inf should return {}")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	arr := NewJSONArray().AddString("a").Add(NewJSONObject().PutBoolean("ok", true))
+	if err := WriteJSON(&buf, arr); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if buf.String() != arr.String() {
+		t.Errorf("WriteJSON() = %s, want %s", buf.String(), arr.String())
+	}
+
+	// 超过池化缓冲区上限的值同样可以正确写入
+	big := NewJSONArray()
+	for i := 0; i < 20000; i++ {
+		big.AddString(strings.Repeat("x", 100))
+	}
+	buf.Reset()
+	if err := WriteJSON(&buf, big); err != nil || buf.Len() != 20000*103+1 {
+		t.Errorf("WriteJSON() wrote %d bytes, %v", buf.Len(), err)
+	}
+}
@@ -0,0 +1,280 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// indexWatch 记录被索引的容器的修改次数，同一个Index观察的容器共享一个indexWatch
+type indexWatch struct {
+	mods uint64
+}
+
+// watchMu 保护容器的watch字段，多个Index可能同时在同一个文档上建立
+var watchMu sync.Mutex
+
+// touch 记录一次修改，没有被索引的容器的watch为nil
+func (w *indexWatch) touch() {
+	if w != nil {
+		atomic.AddUint64(&w.mods, 1)
+	}
+}
+
+// watchStamp 是构建索引时某个indexWatch的修改次数
+type watchStamp struct {
+	watch *indexWatch
+	mods  uint64
+}
+
+// indexSpec 是一个被索引的路径，例如"$.users[*].id"的集合为"users"，键为"id"
+type indexSpec struct {
+	collection     string
+	key            string
+	collectionPath []string
+	keyPath        []string
+}
+
+// indexName 是LookupByKey中集合和键的组合
+type indexName struct {
+	collection string
+	key        string
+}
+
+// Index 是JSON文档上的哈希索引，为反复查询的静态文档提供O(1)的按键查找。
+//
+// 索引的路径形如"$.users[*].id"：集合"users"是一个数组，按数组元素的"id"建立索引；
+// 集合和键都可以是用点号分隔的多级属性名，例如"$.data.users[*].profile.email"。
+// 通过Put、Add、Set、Remove等方法修改被索引的对象和数组后，索引在下一次查找时自动重建；
+// 直接修改JSONArray.Values返回的切片无法被察觉，之后需要调用Rebuild。
+// Index可以被多个goroutine同时使用
+type Index struct {
+	mu      sync.RWMutex
+	root    JSONValue
+	specs   []indexSpec
+	watch   *indexWatch
+	stamps  []watchStamp
+	entries map[indexName]map[string][]JSONValue
+}
+
+// NewIndex 在root上为paths建立索引，路径例如"$.users[*].id"
+func NewIndex(root JSONValue, paths ...string) (*Index, error) {
+	specs := make([]indexSpec, 0, len(paths))
+	for _, path := range paths {
+		spec, err := parseIndexPath(path)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	index := &Index{root: root, specs: specs, watch: &indexWatch{}}
+	index.build()
+	return index, nil
+}
+
+// parseIndexPath 解析"$.collection[*].key"形式的索引路径
+func parseIndexPath(path string) (indexSpec, error) {
+	if !strings.HasPrefix(path, "$") {
+		return indexSpec{}, errors.ErrInvalidPathWithDetails(path, "路径必须以$开头")
+	}
+	star := strings.Index(path, "[*]")
+	if star < 0 || strings.Contains(path[star+3:], "[") {
+		return indexSpec{}, errors.ErrInvalidPathWithDetails(path, "索引路径必须形如$.collection[*].key")
+	}
+
+	collection := strings.TrimPrefix(path[1:star], ".")
+	key := strings.TrimPrefix(path[star+3:], ".")
+	if key == "" || strings.Contains(collection, "[") || (collection == "" && path[1:star] != "") {
+		return indexSpec{}, errors.ErrInvalidPathWithDetails(path, "索引路径必须形如$.collection[*].key")
+	}
+
+	spec := indexSpec{collection: collection, key: key, keyPath: strings.Split(key, ".")}
+	if collection != "" {
+		spec.collectionPath = strings.Split(collection, ".")
+	}
+	for _, names := range [][]string{spec.collectionPath, spec.keyPath} {
+		for _, name := range names {
+			if name == "" {
+				return indexSpec{}, errors.ErrInvalidPathWithDetails(path, "属性名不能为空")
+			}
+		}
+	}
+	return spec, nil
+}
+
+// LookupByKey 返回集合中键的值为value的第一个元素，例如LookupByKey("users", "id", "42")。
+// 数字、布尔值和null按它们的JSON写法比较，因此"42"同时匹配数字42和字符串"42"；
+// JSONBigInt和JSONDecimal按精确的十进制表示比较，小数不带末尾的0
+func (ix *Index) LookupByKey(collection, key, value string) (JSONValue, bool) {
+	matches := ix.lookup(collection, key, value)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
+
+// LookupAllByKey 按文档中的顺序返回集合中键的值为value的所有元素
+func (ix *Index) LookupAllByKey(collection, key, value string) []JSONValue {
+	matches := ix.lookup(collection, key, value)
+	if len(matches) == 0 {
+		return nil
+	}
+	return append([]JSONValue(nil), matches...)
+}
+
+// Valid 检查索引建立之后被索引的对象和数组是否没有被修改
+func (ix *Index) Valid() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return !ix.stale()
+}
+
+// Rebuild 重新建立索引
+func (ix *Index) Rebuild() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.build()
+}
+
+// lookup 查找匹配的元素，索引失效时先重建
+func (ix *Index) lookup(collection, key, value string) []JSONValue {
+	name := indexName{collection: collection, key: key}
+
+	ix.mu.RLock()
+	if !ix.stale() {
+		matches := ix.entries[name][value]
+		ix.mu.RUnlock()
+		return matches
+	}
+	ix.mu.RUnlock()
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.stale() {
+		ix.build()
+	}
+	return ix.entries[name][value]
+}
+
+// stale 检查被索引的容器是否被修改过，调用方需要持有锁
+func (ix *Index) stale() bool {
+	for _, stamp := range ix.stamps {
+		if atomic.LoadUint64(&stamp.watch.mods) != stamp.mods {
+			return true
+		}
+	}
+	return false
+}
+
+// build 遍历文档建立索引，调用方需要持有写锁
+func (ix *Index) build() {
+	ix.entries = make(map[indexName]map[string][]JSONValue, len(ix.specs))
+	watches := map[*indexWatch]bool{ix.watch: true}
+
+	// 让遍历到的容器在修改时通知这个索引；已经被其他索引观察的容器，记下它的watch
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	attach := func(value JSONValue) {
+		var watch **indexWatch
+		switch v := value.(type) {
+		case *JSONObject:
+			watch = &v.watch
+		case *JSONArray:
+			watch = &v.watch
+		default:
+			return
+		}
+		if *watch == nil {
+			*watch = ix.watch
+		}
+		watches[*watch] = true
+	}
+
+	for _, spec := range ix.specs {
+		name := indexName{collection: spec.collection, key: spec.key}
+		entries := ix.entries[name]
+		if entries == nil {
+			entries = make(map[string][]JSONValue)
+			ix.entries[name] = entries
+		}
+
+		collection, ok := walkIndexPath(ix.root, spec.collectionPath, attach)
+		if !ok {
+			continue
+		}
+		arr, ok := collection.(*JSONArray)
+		if !ok {
+			continue
+		}
+		for _, element := range arr.elements {
+			value, ok := walkIndexPath(element, spec.keyPath, attach)
+			if !ok {
+				continue
+			}
+			if k, ok := indexKey(value); ok {
+				entries[k] = append(entries[k], element)
+			}
+		}
+	}
+
+	ix.stamps = ix.stamps[:0]
+	for watch := range watches {
+		ix.stamps = append(ix.stamps, watchStamp{watch: watch, mods: atomic.LoadUint64(&watch.mods)})
+	}
+}
+
+// walkIndexPath 沿着属性名从value向下查找，对经过的每个容器调用attach
+func walkIndexPath(value JSONValue, names []string, attach func(JSONValue)) (JSONValue, bool) {
+	for _, name := range names {
+		attach(value)
+		obj, ok := value.(*JSONObject)
+		if !ok {
+			return nil, false
+		}
+		value, ok = obj.lookup(name)
+		if !ok {
+			return nil, false
+		}
+	}
+	attach(value)
+	return value, true
+}
+
+// indexKey 返回标量值在索引中的键，对象和数组不能作为键
+func indexKey(value JSONValue) (string, bool) {
+	switch {
+	case value.IsString():
+		s, _ := value.AsString()
+		return s, true
+	case value.IsNumber():
+		return numberKey(value), true
+	case value.IsBoolean():
+		b, _ := value.AsBoolean()
+		return strconv.FormatBool(b), true
+	case value.IsNull():
+		return "null", true
+	}
+	return "", false
+}
+
+// numberKey 返回数字在索引中的键。JSONBigInt和JSONDecimal使用精确的十进制表示，
+// 超过2^53的整数不会因为转换为float64而与相邻的整数相同；
+// 小数去掉末尾的0，使42.50与浮点数42.5使用同一个键
+func numberKey(value JSONValue) string {
+	switch n := value.(type) {
+	case *JSONBigInt:
+		return n.String()
+	case *JSONDecimal:
+		s := n.String()
+		if strings.Contains(s, ".") {
+			s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+		return s
+	}
+	f, _ := value.AsNumber()
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"sync"
+	"testing"
+)
+
+func newIndexedDocument() *JSONObject {
+	users := NewJSONArray()
+	users.Add(NewJSONObject().PutNumber("id", 42).PutString("name", "alice"))
+	users.Add(NewJSONObject().PutString("id", "7").PutString("name", "bob"))
+	users.Add(NewJSONObject().PutNumber("id", 42).PutString("name", "carol"))
+	users.Add(NewJSONObject().PutString("name", "nobody"))
+	profile := NewJSONObject().PutString("email", "dave@example.com")
+	users.Add(NewJSONObject().PutNumber("id", 8).Put("profile", profile))
+	return NewJSONObject().Put("data", NewJSONObject().Put("users", users))
+}
+
+func TestIndexLookup(t *testing.T) {
+	doc := newIndexedDocument()
+	index, err := NewIndex(doc, "$.data.users[*].id", "$.data.users[*].profile.email")
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+
+	user, ok := index.LookupByKey("data.users", "id", "42")
+	if name, _ := user.(*JSONObject).GetString("name"); !ok || name != "alice" {
+		t.Errorf("LookupByKey(42) = %v, %v", user, ok)
+	}
+	if all := index.LookupAllByKey("data.users", "id", "42"); len(all) != 2 {
+		t.Errorf("LookupAllByKey(42) 返回 %d 个元素, 期望 2", len(all))
+	}
+	if _, ok := index.LookupByKey("data.users", "id", "7"); !ok {
+		t.Error("字符串键\"7\"应该能找到")
+	}
+	if user, ok := index.LookupByKey("data.users", "profile.email", "dave@example.com"); !ok || user.String() == "" {
+		t.Error("多级键应该能找到")
+	}
+	if _, ok := index.LookupByKey("data.users", "id", "99"); ok {
+		t.Error("不存在的键不应该找到")
+	}
+	if _, ok := index.LookupByKey("users", "id", "42"); ok {
+		t.Error("没有索引的集合不应该找到")
+	}
+
+	for _, path := range []string{"users[*].id", "$.users.id", "$.users[*]", "$.users[*].a[0]", "$..users[*].id"} {
+		if _, err := NewIndex(doc, path); err == nil {
+			t.Errorf("NewIndex(%s) 应该返回错误", path)
+		}
+	}
+}
+
+func TestIndexInvalidation(t *testing.T) {
+	doc := newIndexedDocument()
+	index, err := NewIndex(doc, "$.data.users[*].id")
+	if err != nil {
+		t.Fatalf("NewIndex() error = %v", err)
+	}
+	other, _ := NewIndex(doc, "$.data.users[*].name")
+
+	users, _ := doc.Get("data").(*JSONObject).GetArray("users")
+	users.Add(NewJSONObject().PutNumber("id", 100))
+	if index.Valid() || other.Valid() {
+		t.Error("修改数组后索引应该失效")
+	}
+	if _, ok := index.LookupByKey("data.users", "id", "100"); !ok {
+		t.Error("失效的索引应该在查找时重建")
+	}
+	if !index.Valid() {
+		t.Error("重建后索引应该有效")
+	}
+
+	// 修改元素的键
+	bob, _ := users.GetObject(1)
+	bob.PutNumber("id", 9)
+	if _, ok := index.LookupByKey("data.users", "id", "9"); !ok {
+		t.Error("修改元素的键后应该能找到新的值")
+	}
+	if _, ok := index.LookupByKey("data.users", "id", "7"); ok {
+		t.Error("修改元素的键后不应该找到旧的值")
+	}
+	if _, ok := other.LookupByKey("data.users", "name", "bob"); !ok {
+		t.Error("其他索引也应该重建")
+	}
+
+	// 替换整个集合
+	doc.Get("data").(*JSONObject).Put("users", NewJSONArray())
+	if _, ok := index.LookupByKey("data.users", "id", "42"); ok {
+		t.Error("替换集合后不应该找到旧的元素")
+	}
+
+	// 不相关的对象不影响索引
+	NewJSONObject().PutString("a", "b")
+	if !index.Valid() {
+		t.Error("修改不相关的对象不应该使索引失效")
+	}
+}
+
+func TestIndexConcurrentLookup(t *testing.T) {
+	doc := newIndexedDocument()
+	index, _ := NewIndex(doc, "$.data.users[*].id")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, ok := index.LookupByKey("data.users", "id", "42"); !ok {
+					t.Error("并发查找失败")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIndexConcurrentBuild(t *testing.T) {
+	doc := newIndexedDocument()
+
+	// 多个索引同时在同一个文档上建立
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			index, err := NewIndex(doc, "$.data.users[*].id")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, ok := index.LookupByKey("data.users", "id", "42"); !ok {
+				t.Error("并发建立的索引查找失败")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIndexExactNumbers(t *testing.T) {
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	big2, _ := ParseJSONBigInt("9007199254740992")
+	price, _ := ParseJSONDecimal("42.50")
+	items := NewJSONArray().
+		Add(NewJSONObject().Put("id", big1).PutString("name", "a")).
+		Add(NewJSONObject().Put("id", big2).PutString("name", "b")).
+		Add(NewJSONObject().Put("id", price).PutString("name", "c")).
+		Add(NewJSONObject().PutNumber("id", 42.5).PutString("name", "d"))
+	index, err := NewIndex(NewJSONObject().PutArray("items", items), "$.items[*].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"9007199254740993": {"a"},
+		"9007199254740992": {"b"},
+		"42.5":             {"c", "d"},
+	}
+	for key, want := range tests {
+		matches := index.LookupAllByKey("items", "id", key)
+		if len(matches) != len(want) {
+			t.Errorf("LookupAllByKey(%s) = %v, want %v", key, matches, want)
+			continue
+		}
+		for i, match := range matches {
+			obj, _ := match.AsObject()
+			if name, _ := obj.GetString("name"); name != want[i] {
+				t.Errorf("LookupAllByKey(%s)[%d] = %s, want %s", key, i, name, want[i])
+			}
+		}
+	}
+}
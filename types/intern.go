@@ -0,0 +1,320 @@
+package types
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// InternStats 是InternPool的统计信息
+type InternStats struct {
+	// Nodes 是Intern处理过的节点数量
+	Nodes int
+	// Shared 是复用了池中已有内容的节点数量
+	Shared int
+	// Unique 是池中不同内容的数量
+	Unique int
+	// BytesSaved 是因为复用而不再需要的内存的估计字节数
+	BytesSaved int64
+}
+
+// InternPool 在多个文档之间共享内容相同的部分，适合在内存中保存大量相似文档的场景。
+// 相同的字符串、数字、布尔值和null共享同一个值；只包含标量的对象和数组按内容的哈希共享内部存储；
+// 包含容器的对象按键的顺序共享键的存储。
+//
+// 共享对使用者是透明的：每个文档仍然有自己的对象和数组，修改共享了存储的容器时，
+// 它先复制自己的存储（写时复制，与Snapshot相同），不影响其他文档。
+// 共享了存储的数组的Values返回元素的副本，每次调用都会复制。
+//
+// InternPool可以被多个goroutine同时使用，但Intern一个文档时不能有其他goroutine同时修改它
+type InternPool struct {
+	mu      sync.Mutex
+	scalars map[scalarKey]JSONValue
+	objects map[uint64][]*frozenObject // 只包含标量的对象
+	shapes  map[uint64][]*frozenObject // 键的顺序，只使用keys和index
+	arrays  map[uint64][][]JSONValue   // 只包含标量的数组
+	stats   InternStats
+}
+
+// scalarKey 是标量值在池中的键，数字使用位表示，使NaN也能被共享
+type scalarKey struct {
+	kind byte
+	s    string
+	bits uint64
+}
+
+// NewInternPool 创建一个空的InternPool
+func NewInternPool() *InternPool {
+	p := &InternPool{}
+	p.Reset()
+	return p
+}
+
+// Reset 清空池中的内容和统计信息，已经被Intern的文档不受影响
+func (p *InternPool) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scalars = make(map[scalarKey]JSONValue)
+	p.objects = make(map[uint64][]*frozenObject)
+	p.shapes = make(map[uint64][]*frozenObject)
+	p.arrays = make(map[uint64][][]JSONValue)
+	p.stats = InternStats{}
+}
+
+// Stats 返回池的统计信息
+func (p *InternPool) Stats() InternStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// Intern 让value与池中内容相同的部分共享内存并返回共享后的值。
+// 对象和数组在原地被修改并原样返回，标量返回池中相同的值
+func (p *InternPool) Intern(value JSONValue) JSONValue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.intern(value)
+}
+
+// intern 处理value及其所有子值，调用者持有锁
+func (p *InternPool) intern(value JSONValue) JSONValue {
+	if value == nil {
+		value = NewJSONNull()
+	}
+	p.stats.Nodes++
+	switch val := value.(type) {
+	case *JSONObject:
+		if val != nil {
+			p.internObject(val)
+		}
+		return val
+	case *JSONArray:
+		if val != nil {
+			p.internArray(val)
+		}
+		return val
+	}
+	return p.internScalar(value)
+}
+
+// internScalar 返回池中与value相同的标量
+func (p *InternPool) internScalar(value JSONValue) JSONValue {
+	var key scalarKey
+	size := int64(8)
+	switch value.Type() {
+	case "null":
+		key.kind = 'n'
+		size = 0
+	case "boolean":
+		b, _ := value.AsBoolean()
+		key.kind = 'b'
+		if b {
+			key.bits = 1
+		}
+	case "number":
+		// 精确数字按类型和十进制表示区分，不能与float64相同的JSONNumber共享
+		if IsExactNumber(value) {
+			key.kind = 'd'
+			if _, ok := value.(*JSONBigInt); ok {
+				key.kind = 'i'
+			}
+			key.s = value.String()
+			size = 16 + int64(len(key.s))
+			break
+		}
+		n, _ := value.AsNumber()
+		key.kind = 'f'
+		key.bits = math.Float64bits(n)
+	case "string":
+		key.kind = 's'
+		key.s, _ = value.AsString()
+		size = 16 + int64(len(key.s))
+	default:
+		return value
+	}
+	if shared, ok := p.scalars[key]; ok {
+		if shared != value {
+			p.stats.Shared++
+			p.stats.BytesSaved += size
+		}
+		return shared
+	}
+	p.scalars[key] = value
+	p.stats.Unique++
+	return value
+}
+
+// internObject 共享对象的子值，再共享对象自己的存储
+func (p *InternPool) internObject(o *JSONObject) {
+	o.compact()
+	leaf := true
+	for _, key := range o.keys {
+		child := o.properties[key]
+		shared := p.intern(child)
+		if shared != child {
+			o.unshare()
+			o.properties[key] = shared
+			o.watch.touch()
+		}
+		if shared.IsObject() || shared.IsArray() {
+			leaf = false
+		}
+	}
+
+	if len(o.keys) == 0 {
+		return
+	}
+	if leaf {
+		h := hashKeys(o.keys, o.properties)
+		for _, f := range p.objects[h] {
+			if !sameObject(f, o) {
+				continue
+			}
+			if !o.sharesStorage(f) {
+				o.properties, o.keys, o.index, o.holes = f.properties, f.keys, f.index, 0
+				o.shared = true
+				o.watch.touch()
+				p.stats.Shared++
+				p.stats.BytesSaved += objectSize(o.keys)
+			}
+			return
+		}
+		p.objects[h] = append(p.objects[h], &frozenObject{properties: o.properties, keys: o.keys, index: o.index})
+		o.shared = true
+		p.stats.Unique++
+		return
+	}
+
+	// 子值中有容器时properties不能共享，只共享键的顺序
+	h := hashKeys(o.keys, nil)
+	for _, f := range p.shapes[h] {
+		if !sameKeys(f.keys, o.keys) {
+			continue
+		}
+		if &f.keys[0] != &o.keys[0] {
+			o.keys, o.index = f.keys, f.index
+			o.shared = true
+			p.stats.Shared++
+			p.stats.BytesSaved += shapeSize(o.keys)
+		}
+		return
+	}
+	p.shapes[h] = append(p.shapes[h], &frozenObject{keys: o.keys, index: o.index})
+	o.shared = true
+	p.stats.Unique++
+}
+
+// internArray 共享数组的元素，只包含标量的数组再共享自己的存储
+func (p *InternPool) internArray(a *JSONArray) {
+	leaf := true
+	for i, element := range a.elements {
+		shared := p.intern(element)
+		if shared != element {
+			a.unshare()
+			a.elements[i] = shared
+			a.watch.touch()
+		}
+		if shared.IsObject() || shared.IsArray() {
+			leaf = false
+		}
+	}
+	if !leaf || len(a.elements) == 0 {
+		return
+	}
+
+	h := hashElements(a.elements)
+	for _, elements := range p.arrays[h] {
+		if !sameElements(elements, a.elements) {
+			continue
+		}
+		if &elements[0] != &a.elements[0] {
+			a.elements = elements
+			a.shared = true
+			a.watch.touch()
+			p.stats.Shared++
+			p.stats.BytesSaved += 24 + 16*int64(len(elements))
+		}
+		return
+	}
+	p.arrays[h] = append(p.arrays[h], a.elements)
+	a.shared = true
+	p.stats.Unique++
+}
+
+// sharesStorage 检查非空对象是否已经使用f的存储
+func (o *JSONObject) sharesStorage(f *frozenObject) bool {
+	return &o.keys[0] == &f.keys[0]
+}
+
+// hashKeys 计算键的顺序的哈希，properties不为nil时还包括每个键对应的标量
+func hashKeys(keys []string, properties map[string]JSONValue) uint64 {
+	h := fnv.New64a()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		if properties != nil {
+			h.Write([]byte(properties[key].String()))
+			h.Write([]byte{0})
+		}
+	}
+	return h.Sum64()
+}
+
+// hashElements 计算标量元素的哈希
+func hashElements(elements []JSONValue) uint64 {
+	h := fnv.New64a()
+	for _, element := range elements {
+		h.Write([]byte(element.String()))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// sameKeys 检查两个键的顺序是否相同
+func sameKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameObject 检查只包含标量的对象是否与f相同。标量已经被共享，因此可以比较指针
+func sameObject(f *frozenObject, o *JSONObject) bool {
+	if !sameKeys(f.keys, o.keys) {
+		return false
+	}
+	for _, key := range o.keys {
+		if f.properties[key] != o.properties[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameElements 检查两个只包含共享标量的数组是否相同
+func sameElements(a, b []JSONValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shapeSize 估计键的顺序占用的字节数：keys切片和index映射
+func shapeSize(keys []string) int64 {
+	return 72 + 40*int64(len(keys))
+}
+
+// objectSize 估计对象的存储占用的字节数：keys、index和properties
+func objectSize(keys []string) int64 {
+	return shapeSize(keys) + 48 + 32*int64(len(keys))
+}
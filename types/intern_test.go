@@ -0,0 +1,118 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func internDoc(id int) *JSONObject {
+	return NewJSONObject().
+		PutNumber("id", float64(id)).
+		PutString("status", "active").
+		Put("tags", NewJSONArray().AddString("go").AddString("json")).
+		Put("address", NewJSONObject().PutString("city", "Beijing").PutString("country", "CN")).
+		Put("orders", NewJSONArray().Add(NewJSONObject().PutString("sku", "A1").PutNumber("qty", 1)))
+}
+
+func TestInternSharesStorage(t *testing.T) {
+	pool := NewInternPool()
+	a := pool.Intern(internDoc(1)).(*JSONObject)
+	b := pool.Intern(internDoc(2)).(*JSONObject)
+
+	if a.Get("status") != b.Get("status") {
+		t.Errorf("identical strings are not shared")
+	}
+	aAddr, _ := a.GetObject("address")
+	bAddr, _ := b.GetObject("address")
+	if aAddr == bAddr || &aAddr.keys[0] != &bAddr.keys[0] {
+		t.Errorf("identical objects should be distinct but share storage")
+	}
+	aTags, _ := a.GetArray("tags")
+	bTags, _ := b.GetArray("tags")
+	if &aTags.elements[0] != &bTags.elements[0] {
+		t.Errorf("identical arrays do not share storage")
+	}
+	if &a.keys[0] != &b.keys[0] {
+		t.Errorf("objects with the same keys do not share the key order")
+	}
+	if a.Get("id") == b.Get("id") {
+		t.Errorf("different numbers are shared")
+	}
+
+	stats := pool.Stats()
+	if stats.Nodes != 26 || stats.Shared == 0 || stats.BytesSaved <= 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	// 再次Intern同一个文档不再增加共享
+	pool.Intern(b)
+	if again := pool.Stats(); again.Shared != stats.Shared || again.BytesSaved != stats.BytesSaved {
+		t.Errorf("Stats() after re-interning = %+v, want %+v", again, stats)
+	}
+}
+
+func TestInternCopyOnWrite(t *testing.T) {
+	pool := NewInternPool()
+	a := pool.Intern(internDoc(1)).(*JSONObject)
+	b := pool.Intern(internDoc(1)).(*JSONObject)
+	want := b.String()
+
+	addr, _ := a.GetObject("address")
+	addr.PutString("city", "Shanghai").Remove("country")
+	tags, _ := a.GetArray("tags")
+	tags.SetString(0, "rust").AddString("x")
+	a.PutBoolean("vip", true).Remove("status")
+
+	if b.String() != want {
+		t.Errorf("b = %s, want %s", b.String(), want)
+	}
+	if got := a.String(); got != `{"address":{"city":"Shanghai"},"id":1,"orders":[{"qty":1,"sku":"A1"}],"tags":["rust","json","x"],"vip":true}` {
+		t.Errorf("a = %s", got)
+	}
+
+	// 池中的内容不受修改影响，之后的文档仍然共享原来的存储
+	c := pool.Intern(internDoc(1)).(*JSONObject)
+	if c.String() != want {
+		t.Errorf("c = %s, want %s", c.String(), want)
+	}
+}
+
+func TestInternScalars(t *testing.T) {
+	pool := NewInternPool()
+	nan := pool.Intern(NewJSONNumber(math.NaN()))
+	if pool.Intern(NewJSONNumber(math.NaN())) != nan {
+		t.Errorf("NaN is not shared")
+	}
+	zero := pool.Intern(NewJSONNumber(0))
+	if pool.Intern(NewJSONNumber(math.Copysign(0, -1))) == zero {
+		t.Errorf("-0 and 0 should not be shared")
+	}
+	if pool.Intern(NewJSONBool(true)) == pool.Intern(NewJSONBool(false)) {
+		t.Errorf("true and false are shared")
+	}
+	if !pool.Intern(nil).IsNull() {
+		t.Errorf("Intern(nil) should return null")
+	}
+
+	pool.Reset()
+	if stats := pool.Stats(); stats != (InternStats{}) {
+		t.Errorf("Stats() after Reset = %+v", stats)
+	}
+	if pool.Intern(NewJSONNumber(math.NaN())) == nan {
+		t.Errorf("Reset did not clear the pool")
+	}
+}
+
+func TestInternSavesMemory(t *testing.T) {
+	pool := NewInternPool()
+	for i := 0; i < 1000; i++ {
+		pool.Intern(internDoc(i % 10))
+	}
+	stats := pool.Stats()
+	if stats.Unique > 30 || stats.Shared < 1000*10 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	if stats.BytesSaved < 1000*500 {
+		t.Errorf("BytesSaved = %d, want at least %d", stats.BytesSaved, 1000*500)
+	}
+}
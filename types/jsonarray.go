@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	
 	"github.com/UserLeeZJ/gojson/errors"
@@ -10,6 +11,8 @@ import (
 // JSONArray 表示JSON中的数组
 type JSONArray struct {
 	elements []JSONValue
+	watch    *indexWatch // 索引了这个数组的Index，修改时通知它失效
+	shared   bool        // elements被Snapshot引用，修改前需要复制
 }
 
 // NewJSONArray 创建一个新的空JSONArray
@@ -20,7 +23,21 @@ func NewJSONArray() *JSONArray {
 }
 
 // NewJSONArrayFromValues 从JSONValue切片创建一个新的JSONArray
+// 会复制传入的切片，之后修改values不会影响数组
 func NewJSONArrayFromValues(values []JSONValue) *JSONArray {
+	elements := make([]JSONValue, len(values))
+	copy(elements, values)
+	return &JSONArray{
+		elements: elements,
+	}
+}
+
+// NewJSONArrayFromValuesUnsafe 直接使用传入的切片创建JSONArray，不做复制
+// 调用方之后不应再修改values，否则会影响数组内容
+func NewJSONArrayFromValuesUnsafe(values []JSONValue) *JSONArray {
+	if values == nil {
+		values = make([]JSONValue, 0)
+	}
 	return &JSONArray{
 		elements: values,
 	}
@@ -31,17 +48,32 @@ func (a *JSONArray) Type() string {
 	return "array"
 }
 
-// String 返回JSON值的字符串表示
+// String 返回JSON值的字符串表示，大型数组使用流式编码，见SetStreamingThreshold
 func (a *JSONArray) String() string {
-	bytes, err := a.MarshalJSON()
+	if useStreaming(a) {
+		s, err := stringStreaming(a)
+		if err != nil {
+			return "[]"
+		}
+		return s
+	}
+	bytes, err := a.marshalInterface()
 	if err != nil {
 		return "[]"
 	}
 	return string(bytes)
 }
 
-// MarshalJSON 实现json.Marshaler接口
+// MarshalJSON 实现json.Marshaler接口，大型数组使用流式编码，见SetStreamingThreshold
 func (a *JSONArray) MarshalJSON() ([]byte, error) {
+	if useStreaming(a) {
+		return marshalStreaming(a)
+	}
+	return a.marshalInterface()
+}
+
+// marshalInterface 把数组转换为切片后交给encoding/json序列化
+func (a *JSONArray) marshalInterface() ([]byte, error) {
 	values := make([]interface{}, len(a.elements))
 	for i, v := range a.elements {
 		if v == nil {
@@ -113,6 +145,20 @@ func (a *JSONArray) Size() int {
 	return len(a.elements)
 }
 
+// Values 返回数组底层的元素切片
+// 通常返回的是实时的底层切片而不是副本：修改其中的元素会直接修改数组，
+// 数组之后的Add、Remove等操作也可能使其失效，需要副本时请使用NewJSONArrayFromValues(a.Values())。
+// 数组的元素被Snapshot或InternPool共享时返回副本，以免修改绕过写时复制，
+// 此时每次调用的开销与数组大小成正比，修改副本不会影响数组
+func (a *JSONArray) Values() []JSONValue {
+	if a.shared {
+		values := make([]JSONValue, len(a.elements))
+		copy(values, a.elements)
+		return values
+	}
+	return a.elements
+}
+
 // Get 获取指定索引的元素
 func (a *JSONArray) Get(index int) JSONValue {
 	if index < 0 || index >= len(a.elements) {
@@ -139,6 +185,15 @@ func (a *JSONArray) GetNumber(index int) (float64, error) {
 	return value.AsNumber()
 }
 
+// GetInt 获取指定索引的整数，小数部分和超出范围的处理方式由CurrentNumericPolicy决定
+func (a *JSONArray) GetInt(index int) (int, error) {
+	value := a.Get(index)
+	if value.IsNull() {
+		return 0, errors.ErrIndexOutOfRangeWithDetails(index, a.Size())
+	}
+	return CurrentNumericPolicy().Int(value)
+}
+
 // GetString 获取指定索引的字符串
 func (a *JSONArray) GetString(index int) (string, error) {
 	value := a.Get(index)
@@ -168,7 +223,9 @@ func (a *JSONArray) GetObject(index int) (*JSONObject, error) {
 
 // Add 添加一个元素到数组末尾
 func (a *JSONArray) Add(value JSONValue) *JSONArray {
+	a.unshare()
 	a.elements = append(a.elements, value)
+	a.watch.touch()
 	return a
 }
 
@@ -194,11 +251,13 @@ func (a *JSONArray) AddNull() *JSONArray {
 
 // Set 设置指定索引的元素
 func (a *JSONArray) Set(index int, value JSONValue) *JSONArray {
+	a.unshare()
 	// 如果索引超出范围，自动扩展数组
 	for len(a.elements) <= index {
 		a.elements = append(a.elements, NewJSONNull())
 	}
 	a.elements[index] = value
+	a.watch.touch()
 	return a
 }
 
@@ -227,10 +286,21 @@ func (a *JSONArray) Remove(index int) *JSONArray {
 	if index < 0 || index >= len(a.elements) {
 		return a
 	}
+	a.unshare()
 	a.elements = append(a.elements[:index], a.elements[index+1:]...)
+	a.watch.touch()
 	return a
 }
 
+// unshare 在数组被Snapshot引用时复制elements，之后的修改不影响快照
+func (a *JSONArray) unshare() {
+	if !a.shared {
+		return
+	}
+	a.elements = append(make([]JSONValue, 0, len(a.elements)+1), a.elements...)
+	a.shared = false
+}
+
 // ToArray 将JSONArray转换为Go切片
 func (a *JSONArray) ToArray() []interface{} {
 	result := make([]interface{}, len(a.elements))
@@ -311,3 +381,54 @@ func (a *JSONArray) Slice(start, end int) *JSONArray {
 	}
 	return result
 }
+
+// Clone 克隆当前JSONArray
+// 只复制元素切片，元素中的对象和数组与原数组共享
+func (a *JSONArray) Clone() *JSONArray {
+	return NewJSONArrayFromValues(a.elements)
+}
+
+// DeepClone 深度克隆当前JSONArray，修改克隆的结果不会影响原数组。
+// 数组包含它自身时会panic，不能确定输入是否安全时使用DeepCopyValue
+func (a *JSONArray) DeepClone() *JSONArray {
+	result, err := DeepCopyValue(a)
+	if err != nil {
+		panic(err)
+	}
+	arr, _ := result.AsArray()
+	return arr
+}
+
+// ToStringSlice 将JSONArray转换为字符串切片，所有元素都必须是字符串
+func (a *JSONArray) ToStringSlice() ([]string, error) {
+	result := make([]string, len(a.elements))
+	for i, v := range a.elements {
+		if v == nil || !v.IsString() {
+			return nil, elementTypeError(i, "字符串", v)
+		}
+		result[i], _ = v.AsString()
+	}
+	return result, nil
+}
+
+// ToFloatSlice 将JSONArray转换为浮点数切片，所有元素都必须是数字
+func (a *JSONArray) ToFloatSlice() ([]float64, error) {
+	result := make([]float64, len(a.elements))
+	for i, v := range a.elements {
+		if v == nil || !v.IsNumber() {
+			return nil, elementTypeError(i, "数字", v)
+		}
+		result[i], _ = v.AsNumber()
+	}
+	return result, nil
+}
+
+// elementTypeError 创建数组元素类型不符的错误
+func elementTypeError(index int, expected string, v JSONValue) *errors.JSONError {
+	actual := "null"
+	if v != nil {
+		actual = v.Type()
+	}
+	return errors.NewJSONError(errors.ErrTypeConversion,
+		fmt.Sprintf("元素不是%s，实际类型为%s", expected, actual)).WithPath(fmt.Sprintf("[%d]", index))
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -207,3 +208,109 @@ func TestNewJSONArrayFromValues(t *testing.T) {
 		t.Errorf("arr.GetString(2) = %v, %v, want %v, nil", val, err, "hello")
 	}
 }
+
+func TestJSONArrayValuesAliasing(t *testing.T) {
+	values := []JSONValue{NewJSONNumber(1), NewJSONNumber(2)}
+
+	// 默认构造函数会复制输入切片
+	arr := NewJSONArrayFromValues(values)
+	values[0] = NewJSONString("changed")
+	if val, _ := arr.GetNumber(0); val != 1 {
+		t.Errorf("NewJSONArrayFromValues() should copy input, got %v", arr.Get(0))
+	}
+
+	// Unsafe构造函数共享输入切片
+	unsafeArr := NewJSONArrayFromValuesUnsafe(values)
+	values[1] = NewJSONString("shared")
+	if !unsafeArr.Get(1).IsString() {
+		t.Errorf("NewJSONArrayFromValuesUnsafe() should share input, got %v", unsafeArr.Get(1))
+	}
+	if NewJSONArrayFromValuesUnsafe(nil).String() != "[]" {
+		t.Errorf("NewJSONArrayFromValuesUnsafe(nil) should be an empty array")
+	}
+
+	// Values返回实时的底层切片
+	live := arr.Values()
+	if len(live) != 2 {
+		t.Fatalf("arr.Values() length = %v, want 2", len(live))
+	}
+	live[1] = NewJSONBool(true)
+	if !arr.Get(1).IsBoolean() {
+		t.Errorf("arr.Values() should return the live backing slice")
+	}
+}
+
+func TestJSONArrayClone(t *testing.T) {
+	inner := NewJSONObject()
+	inner.PutNumber("n", 1)
+	arr := NewJSONArray()
+	arr.AddString("a")
+	arr.Add(inner)
+
+	// 浅克隆复制元素切片，但共享元素
+	clone := arr.Clone()
+	clone.AddString("b")
+	if arr.Size() != 2 {
+		t.Errorf("arr.Size() after clone.Add = %v, want 2", arr.Size())
+	}
+	obj, _ := clone.GetObject(1)
+	obj.PutNumber("n", 2)
+	if n, _ := inner.GetNumber("n"); n != 2 {
+		t.Errorf("Clone() should share elements, got n = %v", n)
+	}
+
+	// 深克隆不共享任何容器
+	deep := arr.DeepClone()
+	obj, _ = deep.GetObject(1)
+	obj.PutNumber("n", 3)
+	if n, _ := inner.GetNumber("n"); n != 2 {
+		t.Errorf("DeepClone() should not share elements, got n = %v", n)
+	}
+	if deep.String() == arr.String() {
+		t.Errorf("DeepClone() result should be independent, got %v", deep.String())
+	}
+
+	// 深克隆保留对象忽略大小写的设置
+	folded := NewJSONObject().SetCaseInsensitive(true).PutNumber("ID", 1)
+	deep = NewJSONArray().Add(folded).DeepClone()
+	if obj, _ = deep.GetObject(0); !obj.CaseInsensitive() || !obj.Has("id") {
+		t.Errorf("DeepClone() should keep the case-insensitive flag")
+	}
+
+	// 数组包含自身时panic
+	self := NewJSONArray()
+	self.Add(NewJSONObject().PutArray("items", self))
+	if _, err := DeepCopyValue(self); err == nil || !strings.Contains(err.Error(), "$[0].items") {
+		t.Errorf("DeepCopyValue() with a cycle error = %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DeepClone() with a cycle should panic")
+		}
+	}()
+	self.DeepClone()
+}
+
+func TestJSONArrayToSlices(t *testing.T) {
+	strs, err := NewJSONArray().AddString("a").AddString("b").ToStringSlice()
+	if err != nil || len(strs) != 2 || strs[0] != "a" || strs[1] != "b" {
+		t.Errorf("ToStringSlice() = %v, %v, want [a b], nil", strs, err)
+	}
+	if _, err := NewJSONArray().AddString("a").AddNumber(1).ToStringSlice(); err == nil {
+		t.Errorf("ToStringSlice() should fail for a number element")
+	}
+
+	nums, err := NewJSONArray().AddNumber(1.5).AddNumber(2).ToFloatSlice()
+	if err != nil || len(nums) != 2 || nums[0] != 1.5 || nums[1] != 2 {
+		t.Errorf("ToFloatSlice() = %v, %v, want [1.5 2], nil", nums, err)
+	}
+	_, err = NewJSONArray().AddNumber(1).AddNull().ToFloatSlice()
+	if err == nil || !strings.Contains(err.Error(), "[1]") {
+		t.Errorf("ToFloatSlice() error = %v, want error at [1]", err)
+	}
+
+	empty, err := NewJSONArray().ToStringSlice()
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("ToStringSlice() on empty array = %v, %v, want [], nil", empty, err)
+	}
+}
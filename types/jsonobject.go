@@ -3,14 +3,26 @@ package types
 import (
 	"encoding/json"
 	"sort"
+	"strings"
 	
 	"github.com/UserLeeZJ/gojson/errors"
 )
 
 // JSONObject 表示JSON中的对象
+//
+// 对象保持键的插入顺序，也可以按位置访问和修改属性。各操作的复杂度：
+//   - Get、Has、Put：O(1)
+//   - Remove：均摊O(1)，被移除的键在keys中留下空位，空位过多时压缩
+//   - GetAt、RemoveAt：有空位时O(n)，否则O(1)
+//   - IndexOf、InsertAt、Keys、SortedKeys、ForEach：O(n)
 type JSONObject struct {
 	properties map[string]JSONValue
-	keys       []string // 保持键的顺序
+	keys       []string          // 保持键的顺序，可能包含已移除键留下的空位
+	index      map[string]int    // 键在keys中的位置，keys[i]是空位当且仅当index[keys[i]] != i
+	holes      int               // keys中空位的数量
+	watch      *indexWatch       // 索引了这个对象的Index，修改时通知它失效
+	shared     bool              // properties、keys、index和fold被Snapshot引用，修改前需要复制
+	fold       map[string]string // 忽略大小写时键的小写形式到原始键的映射，区分大小写时为nil
 }
 
 // NewJSONObject 创建一个新的空JSONObject
@@ -18,6 +30,7 @@ func NewJSONObject() *JSONObject {
 	return &JSONObject{
 		properties: make(map[string]JSONValue),
 		keys:       make([]string, 0),
+		index:      make(map[string]int),
 	}
 }
 
@@ -26,17 +39,32 @@ func (o *JSONObject) Type() string {
 	return "object"
 }
 
-// String 返回JSON值的字符串表示
+// String 返回JSON值的字符串表示，大型对象使用流式编码，见SetStreamingThreshold
 func (o *JSONObject) String() string {
-	bytes, err := o.MarshalJSON()
+	if useStreaming(o) {
+		s, err := stringStreaming(o)
+		if err != nil {
+			return "{}"
+		}
+		return s
+	}
+	bytes, err := o.marshalInterface()
 	if err != nil {
 		return "{}"
 	}
 	return string(bytes)
 }
 
-// MarshalJSON 实现json.Marshaler接口
+// MarshalJSON 实现json.Marshaler接口，大型对象使用流式编码，见SetStreamingThreshold
 func (o *JSONObject) MarshalJSON() ([]byte, error) {
+	if useStreaming(o) {
+		return marshalStreaming(o)
+	}
+	return o.marshalInterface()
+}
+
+// marshalInterface 把对象转换为map后交给encoding/json序列化
+func (o *JSONObject) marshalInterface() ([]byte, error) {
 	m := make(map[string]any)
 	for k, v := range o.properties {
 		if v == nil {
@@ -108,33 +136,117 @@ func (o *JSONObject) Size() int {
 	return len(o.properties)
 }
 
-// Keys 返回对象的所有键
+// Keys 按插入顺序返回对象的所有键
+// 返回的是副本，修改它不会影响对象
 func (o *JSONObject) Keys() []string {
-	return o.keys
+	keys := make([]string, 0, len(o.properties))
+	for i, key := range o.keys {
+		if o.live(i, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
-// SortedKeys 返回对象的所有键（已排序）
+// SortedKeys 返回对象的所有键（按字节序排序）
 func (o *JSONObject) SortedKeys() []string {
-	keys := make([]string, len(o.keys))
-	copy(keys, o.keys)
+	keys := o.Keys()
 	sort.Strings(keys)
 	return keys
 }
 
+// SortedKeysBy 返回按order排序的所有键，order为nil时与SortedKeys相同
+func (o *JSONObject) SortedKeysBy(order KeyOrder) []string {
+	keys := o.Keys()
+	SortStrings(keys, order)
+	return keys
+}
+
 // Has 检查对象是否包含指定键
 func (o *JSONObject) Has(key string) bool {
-	_, ok := o.properties[key]
+	_, ok := o.resolve(key)
 	return ok
 }
 
 // Get 获取指定键的值
 func (o *JSONObject) Get(key string) JSONValue {
-	if value, ok := o.properties[key]; ok {
+	if value, ok := o.lookup(key); ok {
 		return value
 	}
 	return NewJSONNull()
 }
 
+// SetCaseInsensitive 设置按键访问时是否忽略大小写，用于处理把同一个字段时而写作"ID"、时而写作"id"的数据。
+// 忽略大小写时，Get、Has、Put、Remove等方法把只有大小写不同的键视为同一个键，输出保留第一次写入时的写法：
+//
+//	obj.SetCaseInsensitive(true).PutNumber("ID", 1)
+//	obj.GetNumber("id")    // 1
+//	obj.PutNumber("Id", 2) // 更新"ID"的值
+//	obj.String()           // {"ID":2}
+//
+// 开启时已有的只有大小写不同的键被合并为一个，保留插入顺序中第一个键的位置和写法，值取最后一个键的值。
+// 设置只影响这个对象，不影响嵌套的对象
+func (o *JSONObject) SetCaseInsensitive(enabled bool) *JSONObject {
+	if !enabled {
+		o.fold = nil
+		return o
+	}
+	if o.fold != nil {
+		return o
+	}
+
+	keys := o.Keys()
+	o.fold = make(map[string]string, len(keys))
+	for _, key := range keys {
+		folded := foldKey(key)
+		actual, ok := o.fold[folded]
+		if !ok {
+			o.fold[folded] = key
+			continue
+		}
+		value := o.properties[key]
+		o.Remove(key)
+		o.Put(actual, value)
+	}
+	return o
+}
+
+// CaseInsensitive 检查按键访问时是否忽略大小写
+func (o *JSONObject) CaseInsensitive() bool {
+	return o.fold != nil
+}
+
+// resolve 返回key在对象中的原始写法，忽略大小写时精确匹配优先
+func (o *JSONObject) resolve(key string) (string, bool) {
+	if _, ok := o.index[key]; ok {
+		return key, true
+	}
+	if o.fold != nil {
+		if actual, ok := o.fold[foldKey(key)]; ok {
+			return actual, true
+		}
+	}
+	return key, false
+}
+
+// lookup 返回key对应的值，忽略大小写时按resolve查找
+func (o *JSONObject) lookup(key string) (JSONValue, bool) {
+	if value, ok := o.properties[key]; ok {
+		return value, true
+	}
+	if o.fold != nil {
+		if actual, ok := o.fold[foldKey(key)]; ok {
+			return o.properties[actual], true
+		}
+	}
+	return nil, false
+}
+
+// foldKey 返回忽略大小写比较时使用的键
+func foldKey(key string) string {
+	return strings.ToLower(key)
+}
+
 // GetBoolean 获取指定键的布尔值
 func (o *JSONObject) GetBoolean(key string) (bool, error) {
 	value := o.Get(key)
@@ -153,6 +265,15 @@ func (o *JSONObject) GetNumber(key string) (float64, error) {
 	return value.AsNumber()
 }
 
+// GetInt 获取指定键的整数，小数部分和超出范围的处理方式由CurrentNumericPolicy决定
+func (o *JSONObject) GetInt(key string) (int, error) {
+	value := o.Get(key)
+	if value.IsNull() {
+		return 0, errors.ErrPathNotFoundWithDetails(key)
+	}
+	return CurrentNumericPolicy().Int(value)
+}
+
 // GetString 获取指定键的字符串
 func (o *JSONObject) GetString(key string) (string, error) {
 	value := o.Get(key)
@@ -182,10 +303,17 @@ func (o *JSONObject) GetObject(key string) (*JSONObject, error) {
 
 // Put 设置指定键的值
 func (o *JSONObject) Put(key string, value JSONValue) *JSONObject {
-	if !o.Has(key) {
+	o.unshare()
+	key, ok := o.resolve(key)
+	if !ok {
+		o.index[key] = len(o.keys)
 		o.keys = append(o.keys, key)
+		if o.fold != nil {
+			o.fold[foldKey(key)] = key
+		}
 	}
 	o.properties[key] = value
+	o.watch.touch()
 	return o
 }
 
@@ -221,23 +349,168 @@ func (o *JSONObject) PutArray(key string, value *JSONArray) *JSONObject {
 
 // Remove 移除指定键
 func (o *JSONObject) Remove(key string) *JSONObject {
-	if !o.Has(key) {
+	key, ok := o.resolve(key)
+	if !ok {
 		return o
 	}
 
+	o.unshare()
 	delete(o.properties, key)
+	delete(o.index, key)
+	if o.fold != nil && o.fold[foldKey(key)] == key {
+		delete(o.fold, foldKey(key))
+	}
+	o.watch.touch()
+
+	// 在keys中留下空位，空位超过一半时压缩
+	o.holes++
+	if o.holes > len(o.keys)/2 {
+		o.compact()
+	}
 
-	// 从keys中移除
-	for i, k := range o.keys {
-		if k == key {
-			o.keys = append(o.keys[:i], o.keys[i+1:]...)
-			break
+	return o
+}
+
+// GetAt 返回插入顺序中第i个属性的键和值，i超出范围时返回空字符串和nil
+func (o *JSONObject) GetAt(i int) (string, JSONValue) {
+	if i < 0 || i >= len(o.properties) {
+		return "", nil
+	}
+	if o.holes == 0 {
+		key := o.keys[i]
+		return key, o.properties[key]
+	}
+	for j, key := range o.keys {
+		if !o.live(j, key) {
+			continue
 		}
+		if i == 0 {
+			return key, o.properties[key]
+		}
+		i--
+	}
+	return "", nil
+}
+
+// IndexOf 返回键在插入顺序中的位置，键不存在时返回-1
+func (o *JSONObject) IndexOf(key string) int {
+	key, ok := o.resolve(key)
+	if !ok {
+		return -1
+	}
+	pos := o.index[key]
+	if o.holes == 0 {
+		return pos
+	}
+	// 减去前面的空位
+	n := 0
+	for i, k := range o.keys[:pos] {
+		if o.live(i, k) {
+			n++
+		}
+	}
+	return n
+}
+
+// InsertAt 把属性插入到插入顺序中的第i个位置，原来位于i及之后的属性依次后移。
+// 键已经存在时先把它从原来的位置移除，因此也可以用来移动属性。
+// i小于0时插入到开头，大于属性数量时追加到末尾
+func (o *JSONObject) InsertAt(i int, key string, value JSONValue) *JSONObject {
+	o.Remove(key)
+	o.compact()
+	o.unshare()
+	if i < 0 {
+		i = 0
+	}
+	if i > len(o.keys) {
+		i = len(o.keys)
+	}
+
+	o.keys = append(o.keys, "")
+	copy(o.keys[i+1:], o.keys[i:])
+	o.keys[i] = key
+	for j := i; j < len(o.keys); j++ {
+		o.index[o.keys[j]] = j
+	}
+	if o.fold != nil {
+		o.fold[foldKey(key)] = key
 	}
+	o.properties[key] = value
+	o.watch.touch()
+	return o
+}
 
+// RemoveAt 移除插入顺序中第i个属性，i超出范围时不做任何事
+func (o *JSONObject) RemoveAt(i int) *JSONObject {
+	if i >= 0 && i < len(o.properties) {
+		key, _ := o.GetAt(i)
+		o.Remove(key)
+	}
 	return o
 }
 
+// live 检查keys[i]是否是有效的键而不是已移除键留下的空位
+func (o *JSONObject) live(i int, key string) bool {
+	pos, ok := o.index[key]
+	return ok && pos == i
+}
+
+// compact 移除keys中的空位并更新索引，只在修改对象的方法中调用，
+// 只读的方法跳过空位，因此可以在多个goroutine中同时读取对象
+func (o *JSONObject) compact() {
+	if o.holes == 0 {
+		return
+	}
+	if o.shared {
+		// unshare复制时已经去掉了空位
+		o.unshare()
+		return
+	}
+
+	n := 0
+	for i, key := range o.keys {
+		if o.live(i, key) {
+			o.keys[n] = key
+			o.index[key] = n
+			n++
+		}
+	}
+	// 清除尾部的引用，便于回收
+	for i := n; i < len(o.keys); i++ {
+		o.keys[i] = ""
+	}
+	o.keys = o.keys[:n]
+	o.holes = 0
+}
+
+// unshare 在对象被Snapshot引用时复制properties、keys、index和fold，之后的修改不影响快照。
+// 复制时顺便去掉keys中的空位
+func (o *JSONObject) unshare() {
+	if !o.shared {
+		return
+	}
+	properties := make(map[string]JSONValue, len(o.properties))
+	keys := make([]string, 0, len(o.properties))
+	index := make(map[string]int, len(o.properties))
+	for i, key := range o.keys {
+		if o.live(i, key) {
+			index[key] = len(keys)
+			keys = append(keys, key)
+			properties[key] = o.properties[key]
+		}
+	}
+	o.properties, o.keys, o.index = properties, keys, index
+	if o.fold != nil {
+		fold := make(map[string]string, len(o.fold))
+		for folded, key := range o.fold {
+			fold[folded] = key
+		}
+		o.fold = fold
+	}
+	o.holes = 0
+	o.shared = false
+}
+
 // ToMap 将JSONObject转换为Go map
 func (o *JSONObject) ToMap() map[string]any {
 	result := make(map[string]any)
@@ -249,7 +522,7 @@ func (o *JSONObject) ToMap() map[string]any {
 
 // ForEach 对对象中的每个属性执行函数
 func (o *JSONObject) ForEach(fn func(key string, value JSONValue)) {
-	for _, key := range o.keys {
+	for _, key := range o.Keys() {
 		fn(key, o.properties[key])
 	}
 }
@@ -269,7 +542,7 @@ func (o *JSONObject) Merge(other *JSONObject) *JSONObject {
 
 // Clone 克隆当前JSONObject
 func (o *JSONObject) Clone() *JSONObject {
-	clone := NewJSONObject()
+	clone := NewJSONObject().SetCaseInsensitive(o.CaseInsensitive())
 	o.ForEach(func(key string, value JSONValue) {
 		clone.Put(key, value)
 	})
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -216,3 +219,191 @@ func TestJSONObject(t *testing.T) {
 		t.Errorf("After modifying clone, obj.Has(\"cloneOnly\") = %v, want %v", obj.Has("cloneOnly"), false)
 	}
 }
+
+func TestJSONObjectKeyOrderAfterRemove(t *testing.T) {
+	obj := NewJSONObject()
+	for i := 0; i < 10; i++ {
+		obj.PutNumber(fmt.Sprintf("k%d", i), float64(i))
+	}
+
+	// 移除大部分键以触发压缩
+	for i := 0; i < 10; i += 2 {
+		obj.Remove(fmt.Sprintf("k%d", i))
+	}
+	obj.Remove("k3")
+	obj.PutNumber("k0", 0)
+	obj.PutNumber("k5", 50)
+
+	want := []string{"k1", "k5", "k7", "k9", "k0"}
+	got := obj.Keys()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if obj.Size() != len(want) {
+		t.Errorf("Size() = %d, want %d", obj.Size(), len(want))
+	}
+
+	// 修改返回的键不影响对象
+	got[0] = "changed"
+	if obj.Keys()[0] != "k1" {
+		t.Errorf("Keys() returned slice aliases internal state")
+	}
+
+	var visited []string
+	obj.ForEach(func(key string, value JSONValue) {
+		visited = append(visited, key)
+	})
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("ForEach() visited %v, want %v", visited, want)
+	}
+}
+
+func TestJSONObjectConcurrentRead(t *testing.T) {
+	obj := NewJSONObject()
+	for i := 0; i < 10; i++ {
+		obj.PutNumber(fmt.Sprintf("k%d", i), float64(i))
+	}
+	// 留下不足以触发压缩的空位，读取时不应该修改对象
+	obj.Remove("k1").Remove("k4")
+	want := []string{"k0", "k2", "k3", "k5", "k6", "k7", "k8", "k9"}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for i := 0; i < 50; i++ {
+				if got := obj.Keys(); !reflect.DeepEqual(got, want) {
+					t.Errorf("Keys() = %v, want %v", got, want)
+					return
+				}
+				if key, _ := obj.GetAt(3); key != "k5" || obj.IndexOf("k9") != 7 {
+					t.Errorf("GetAt(3) = %q, IndexOf(k9) = %d", key, obj.IndexOf("k9"))
+					return
+				}
+				n := 0
+				obj.ForEach(func(string, JSONValue) { n++ })
+				if n != len(want) || len(obj.SortedKeys()) != len(want) || obj.String() == "{}" {
+					t.Errorf("ForEach() visited %d properties", n)
+					return
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestJSONObjectPositional(t *testing.T) {
+	obj := NewJSONObject().PutNumber("a", 1).PutNumber("b", 2).PutNumber("c", 3)
+
+	if key, value := obj.GetAt(1); key != "b" || value.String() != "2" {
+		t.Errorf("GetAt(1) = %q, %v", key, value)
+	}
+	for _, i := range []int{-1, 3} {
+		if key, value := obj.GetAt(i); key != "" || value != nil {
+			t.Errorf("GetAt(%d) = %q, %v, want nothing", i, key, value)
+		}
+	}
+
+	obj.InsertAt(0, "first", NewJSONString("x")).
+		InsertAt(2, "mid", NewJSONNull()).
+		InsertAt(100, "last", NewJSONBool(true))
+	want := []string{"first", "a", "mid", "b", "c", "last"}
+	if got := obj.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() after InsertAt = %v, want %v", got, want)
+	}
+
+	// 插入已有的键会移动它并替换值
+	obj.InsertAt(-5, "c", NewJSONNumber(30))
+	want = []string{"c", "first", "a", "mid", "b", "last"}
+	if got := obj.Keys(); !reflect.DeepEqual(got, want) || obj.Size() != 6 {
+		t.Errorf("Keys() after moving c = %v, want %v", got, want)
+	}
+	if n, _ := obj.GetNumber("c"); n != 30 {
+		t.Errorf("GetNumber(c) = %v, want 30", n)
+	}
+
+	// 按位置移除后空位不影响位置
+	obj.RemoveAt(1).RemoveAt(2).RemoveAt(10).RemoveAt(-1)
+	want = []string{"c", "a", "b", "last"}
+	for i, key := range want {
+		if got, _ := obj.GetAt(i); got != key {
+			t.Errorf("GetAt(%d) = %q, want %q", i, got, key)
+		}
+		if obj.IndexOf(key) != i {
+			t.Errorf("IndexOf(%q) = %d, want %d", key, obj.IndexOf(key), i)
+		}
+	}
+	if obj.IndexOf("first") != -1 || obj.Has("mid") {
+		t.Errorf("removed keys are still present: %v", obj.Keys())
+	}
+
+	obj.Remove("a").PutNumber("z", 26)
+	if key, _ := obj.GetAt(3); key != "z" || obj.IndexOf("b") != 1 {
+		t.Errorf("Keys() after Remove and Put = %v", obj.Keys())
+	}
+}
+
+func TestJSONObjectCaseInsensitive(t *testing.T) {
+	obj := NewJSONObject().SetCaseInsensitive(true)
+	obj.PutNumber("ID", 1).PutString("name", "a")
+	if !obj.CaseInsensitive() {
+		t.Fatalf("CaseInsensitive() = false")
+	}
+	if n, err := obj.GetNumber("id"); err != nil || n != 1 {
+		t.Errorf("GetNumber(id) = %v, %v", n, err)
+	}
+	obj.PutNumber("Id", 2)
+	if obj.Size() != 2 || obj.String() != `{"ID":2,"name":"a"}` {
+		t.Errorf("after Put(Id) = %s", obj.String())
+	}
+	if !obj.Has("NAME") || obj.IndexOf("iD") != 0 {
+		t.Errorf("Has(NAME) = %v, IndexOf(iD) = %d", obj.Has("NAME"), obj.IndexOf("iD"))
+	}
+	if obj.At("Name").Exists() == false || obj.Q("NAME").StringOr("") != "a" {
+		t.Errorf("At/Q should ignore case")
+	}
+
+	// 快照和克隆保留设置
+	snap := obj.Snapshot()
+	clone := obj.Clone()
+	obj.Remove("id")
+	if obj.Has("ID") || obj.Size() != 1 {
+		t.Errorf("Remove(id) left %s", obj.String())
+	}
+	if !snap.Has("id") || !clone.Has("id") || !clone.CaseInsensitive() {
+		t.Errorf("snapshot or clone lost the key")
+	}
+	if v := snap.Value().(*JSONObject); !v.CaseInsensitive() || !v.Has("Id") {
+		t.Errorf("Snapshot.Value() = %s", v.String())
+	}
+
+	// 重新加入时使用新的写法
+	obj.PutNumber("id", 3)
+	if got := obj.Keys(); !reflect.DeepEqual(got, []string{"name", "id"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	obj.InsertAt(0, "NAME", NewJSONString("b"))
+	if got := obj.Keys(); !reflect.DeepEqual(got, []string{"NAME", "id"}) || obj.Size() != 2 {
+		t.Errorf("Keys() after InsertAt = %v", got)
+	}
+
+	obj.SetCaseInsensitive(false)
+	if obj.Has("name") || !obj.Has("NAME") {
+		t.Errorf("keys should be case-sensitive after SetCaseInsensitive(false)")
+	}
+}
+
+func TestJSONObjectCaseInsensitiveMerge(t *testing.T) {
+	obj := NewJSONObject().PutNumber("id", 1).PutNumber("x", 0).PutNumber("ID", 2).PutNumber("Id", 3)
+	obj.SetCaseInsensitive(true)
+	if obj.String() != `{"id":3,"x":0}` {
+		t.Errorf("SetCaseInsensitive(true) = %s", obj.String())
+	}
+	if got := obj.Keys(); !reflect.DeepEqual(got, []string{"id", "x"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+}
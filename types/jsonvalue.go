@@ -2,7 +2,16 @@
 package types
 
 import (
+	"bytes"
+	"encoding"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/UserLeeZJ/gojson/errors"
 )
 
 // JSONValue 是所有JSON值类型的通用接口
@@ -52,6 +61,7 @@ type JSONValue interface {
 }
 
 // ValueToInterface 将JSONValue转换为Go原生类型
+// 数字转换为float64，JSONBigInt和JSONDecimal转换为json.Number以保留精度
 func ValueToInterface(v JSONValue) interface{} {
 	if v == nil || v.IsNull() {
 		return nil
@@ -62,6 +72,9 @@ func ValueToInterface(v JSONValue) interface{} {
 		val, _ := v.AsBoolean()
 		return val
 	case "number":
+		if IsExactNumber(v) {
+			return json.Number(v.String())
+		}
 		val, _ := v.AsNumber()
 		return val
 	case "string":
@@ -86,15 +99,158 @@ func ValueToInterface(v JSONValue) interface{} {
 	}
 }
 
+// InterfaceOptions 表示ValueToInterfaceOpts的转换选项
+type InterfaceOptions struct {
+	// UseNumber 为true时数字转换为json.Number，而不是float64
+	UseNumber bool
+	// OrderedMaps 为true时对象转换为OrderedMap，保留键的插入顺序
+	OrderedMaps bool
+}
+
+// KeyValue 表示OrderedMap中的一个键值对
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedMap 是保持键顺序的对象表示
+type OrderedMap []KeyValue
+
+// Get 获取指定键的值
+func (m OrderedMap) Get(key string) (interface{}, bool) {
+	for _, kv := range m {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys 按顺序返回所有键
+func (m OrderedMap) Keys() []string {
+	keys := make([]string, len(m))
+	for i, kv := range m {
+		keys[i] = kv.Key
+	}
+	return keys
+}
+
+// MarshalJSON 实现json.Marshaler接口，按键的顺序输出
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ValueToInterfaceOpts 按选项将JSONValue转换为Go原生类型
+func ValueToInterfaceOpts(v JSONValue, opts InterfaceOptions) interface{} {
+	if v == nil || v.IsNull() {
+		return nil
+	}
+
+	switch v.Type() {
+	case "boolean":
+		val, _ := v.AsBoolean()
+		return val
+	case "number":
+		if opts.UseNumber || IsExactNumber(v) {
+			return json.Number(v.String())
+		}
+		val, _ := v.AsNumber()
+		return val
+	case "string":
+		val, _ := v.AsString()
+		return val
+	case "array":
+		arr, _ := v.AsArray()
+		result := make([]interface{}, arr.Size())
+		for i := 0; i < arr.Size(); i++ {
+			result[i] = ValueToInterfaceOpts(arr.Get(i), opts)
+		}
+		return result
+	case "object":
+		obj, _ := v.AsObject()
+		if opts.OrderedMaps {
+			result := make(OrderedMap, 0, obj.Size())
+			for _, key := range obj.Keys() {
+				result = append(result, KeyValue{Key: key, Value: ValueToInterfaceOpts(obj.Get(key), opts)})
+			}
+			return result
+		}
+		result := make(map[string]interface{})
+		for _, key := range obj.Keys() {
+			result[key] = ValueToInterfaceOpts(obj.Get(key), opts)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
 // FromGoValue 将Go原生类型转换为JSONValue
+// 等价于FromInterface
 func FromGoValue(v interface{}) (JSONValue, error) {
+	return FromInterface(v)
+}
+
+// FromInterface 将Go原生类型转换为JSONValue
+// 支持所有整数和浮点数宽度、json.Number、json.RawMessage以及嵌套的map和切片，
+// big.Int转换为JSONBigInt，float64不能精确表示的json.Number转换为JSONBigInt或JSONDecimal，键是整数或实现了encoding.TextMarshaler的map直接转换为按键排序的对象，
+// 其他类型通过encoding/json往返转换
+// 通道、函数、复数等不能序列化的值返回ErrUnsupportedType错误，路径是该值在v中的位置
+func FromInterface(v interface{}) (JSONValue, error) {
+	return FromInterfaceOpts(v, FromInterfaceOptions{})
+}
+
+// FromInterfaceOptions 表示FromInterfaceOpts的转换选项
+type FromInterfaceOptions struct {
+	// Float64Numbers 为true时json.Number总是转换为JSONNumber，超出float64范围时返回错误，
+	// 结果与encoding/json解码为float64相同。解析器使用这个选项，使不同的解码器得到相同的数字类型
+	Float64Numbers bool
+}
+
+// FromInterfaceOpts 按选项将Go原生类型转换为JSONValue，其他方面与FromInterface相同
+func FromInterfaceOpts(v interface{}, opts FromInterfaceOptions) (JSONValue, error) {
+	value, err := fromInterface(v, opts)
+	if err != nil {
+		if uerr := errors.UnsupportedTypeError(v, err); uerr != nil {
+			return nil, uerr
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// fromInterface 实现FromInterface，嵌套的值递归调用fromInterface，错误的路径由FromInterface统一计算
+func fromInterface(v interface{}, opts FromInterfaceOptions) (JSONValue, error) {
 	if v == nil {
 		return NewJSONNull(), nil
 	}
 
 	switch val := v.(type) {
+	case JSONValue:
+		return val, nil
 	case bool:
 		return NewJSONBool(val), nil
+	case string:
+		return NewJSONString(val), nil
 	case float64:
 		return NewJSONNumber(val), nil
 	case float32:
@@ -119,12 +275,37 @@ func FromGoValue(v interface{}) (JSONValue, error) {
 		return NewJSONNumber(float64(val)), nil
 	case uint64:
 		return NewJSONNumber(float64(val)), nil
-	case string:
-		return NewJSONString(val), nil
+	case *big.Int:
+		return NewJSONBigInt(val), nil
+	case big.Int:
+		return NewJSONBigInt(&val), nil
+	case json.Number:
+		// float64不能精确表示的数字转换为JSONBigInt或JSONDecimal，与ValueToInterface往返不丢失精度
+		var n JSONValue
+		var err error
+		if opts.Float64Numbers {
+			var f float64
+			if f, err = val.Float64(); err == nil {
+				n = NewJSONNumber(f)
+			}
+		} else {
+			n, err = ParseNumber(val.String())
+		}
+		if err != nil {
+			return nil, errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("无效的数字: %s", val.String())).WithCause(err)
+		}
+		return n, nil
+	case json.RawMessage:
+		return fromRawJSON(val, opts)
+	case Obj:
+		return fromInterface(map[string]interface{}(val), opts)
+	case Arr:
+		return fromInterface([]interface{}(val), opts)
 	case []interface{}:
-		arr := NewJSONArray()
+		arr := &JSONArray{elements: make([]JSONValue, 0, len(val))}
 		for _, item := range val {
-			itemValue, err := FromGoValue(item)
+			itemValue, err := fromInterface(item, opts)
 			if err != nil {
 				return nil, err
 			}
@@ -132,9 +313,16 @@ func FromGoValue(v interface{}) (JSONValue, error) {
 		}
 		return arr, nil
 	case map[string]interface{}:
+		// 对键排序，保证转换结果的键顺序是确定的
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
 		obj := NewJSONObject()
-		for key, item := range val {
-			itemValue, err := FromGoValue(item)
+		for _, key := range keys {
+			itemValue, err := fromInterface(val[key], opts)
 			if err != nil {
 				return nil, err
 			}
@@ -142,15 +330,105 @@ func FromGoValue(v interface{}) (JSONValue, error) {
 		}
 		return obj, nil
 	default:
+		// 键是整数或实现了encoding.TextMarshaler的map直接转换，值不经过encoding/json
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Map && isDirectMap(val, rv.Type()) {
+			return fromMap(rv, opts)
+		}
 		// 尝试使用json.Marshal和json.Unmarshal进行转换
 		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("无法转换类型 %T", val)).WithCause(err)
+		}
+		return fromRawJSON(data, opts)
+	}
+}
+
+// textMarshalerType 是encoding.TextMarshaler的反射类型
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// isDirectMap 检查map是否可以由fromMap转换：map本身没有自定义的序列化方法，
+// 键是字符串、整数或实现了encoding.TextMarshaler
+func isDirectMap(v interface{}, t reflect.Type) bool {
+	switch v.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return false
+	}
+	key := t.Key()
+	switch key.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return key.Implements(textMarshalerType)
+}
+
+// mapKeyString 按encoding/json的规则把map的键转换为字符串：
+// 字符串类型的键直接使用，其次是encoding.TextMarshaler，最后是整数
+func mapKeyString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if key.Kind() == reflect.Pointer && key.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("无法转换map的键 %v", key.Interface())).WithCause(err)
+		}
+		return string(text), nil
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", errors.NewJSONError(errors.ErrNotSupported, "不支持的map键类型: "+key.Type().String())
+}
+
+// fromMap 把map转换为JSONObject，键按转换后的字符串排序，nil map转换为null
+func fromMap(rv reflect.Value, opts FromInterfaceOptions) (JSONValue, error) {
+	if rv.IsNil() {
+		return NewJSONNull(), nil
+	}
+
+	values := make(map[string]reflect.Value, rv.Len())
+	keys := make([]string, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := mapKeyString(iter.Key())
 		if err != nil {
 			return nil, err
 		}
-		var result interface{}
-		if err := json.Unmarshal(data, &result); err != nil {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	obj := NewJSONObject()
+	for _, key := range keys {
+		itemValue, err := fromInterface(values[key].Interface(), opts)
+		if err != nil {
 			return nil, err
 		}
-		return FromGoValue(result)
+		obj.Put(key, itemValue)
+	}
+	return obj, nil
+}
+
+// fromRawJSON 将原始JSON字节转换为JSONValue，保留数字精度直到转换为JSONNumber
+func fromRawJSON(data []byte, opts FromInterfaceOptions) (JSONValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, errors.NewJSONError(errors.ErrInvalidJSON, "解析JSON失败").WithCause(err)
 	}
+	return FromInterfaceOpts(raw, opts)
 }
@@ -1,7 +1,12 @@
 package types
 
 import (
+	"encoding/json"
+	stderrors "errors"
+	"strconv"
 	"testing"
+
+	"github.com/UserLeeZJ/gojson/errors"
 )
 
 func TestJSONNull(t *testing.T) {
@@ -315,3 +320,224 @@ func TestValueToInterface(t *testing.T) {
 		})
 	}
 }
+
+func TestValueToInterfaceOpts(t *testing.T) {
+	obj := NewJSONObject()
+	obj.PutString("z", "last")
+	obj.PutNumber("a", 12345678901234)
+	inner := NewJSONObject()
+	inner.PutNumber("y", 1.5)
+	inner.PutNumber("b", 2)
+	obj.PutObject("m", inner)
+
+	// 测试UseNumber
+	got := ValueToInterfaceOpts(obj, InterfaceOptions{UseNumber: true})
+	m, ok := got.(map[string]interface{})
+	if !ok {
+		t.Fatalf("ValueToInterfaceOpts() type = %T, want map[string]interface{}", got)
+	}
+	if n, ok := m["a"].(json.Number); !ok || n.String() != "12345678901234" {
+		t.Errorf("ValueToInterfaceOpts() a = %v, want json.Number 12345678901234", m["a"])
+	}
+
+	// 测试OrderedMaps
+	got = ValueToInterfaceOpts(obj, InterfaceOptions{OrderedMaps: true})
+	om, ok := got.(OrderedMap)
+	if !ok {
+		t.Fatalf("ValueToInterfaceOpts() type = %T, want OrderedMap", got)
+	}
+	if keys := om.Keys(); len(keys) != 3 || keys[0] != "z" || keys[1] != "a" || keys[2] != "m" {
+		t.Errorf("OrderedMap.Keys() = %v, want [z a m]", keys)
+	}
+	if v, ok := om.Get("z"); !ok || v != "last" {
+		t.Errorf("OrderedMap.Get(z) = %v, want last", v)
+	}
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"z":"last","a":12345678901234,"m":{"y":1.5,"b":2}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal(OrderedMap) = %s, want %s", data, want)
+	}
+
+	// 测试null
+	if ValueToInterfaceOpts(NewJSONNull(), InterfaceOptions{UseNumber: true}) != nil {
+		t.Errorf("ValueToInterfaceOpts(null) should return nil")
+	}
+}
+
+func TestFromInterface(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string // 期望的字符串表示
+	}{
+		{name: "nil", value: nil, want: "null"},
+		{name: "int8", value: int8(-8), want: "-8"},
+		{name: "uint64", value: uint64(64), want: "64"},
+		{name: "float32", value: float32(0.5), want: "0.5"},
+		{name: "json.Number", value: json.Number("12.5"), want: "12.5"},
+		{name: "json.RawMessage", value: json.RawMessage(`{"b":[1,true],"a":null}`), want: `{"a":null,"b":[1,true]}`},
+		{name: "嵌套", value: map[string]interface{}{"list": []interface{}{int64(1), "x"}}, want: `{"list":[1,"x"]}`},
+		{name: "JSONValue", value: NewJSONString("s"), want: `"s"`},
+		{name: "结构体", value: struct {
+			Name string `json:"name"`
+		}{Name: "n"}, want: `{"name":"n"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromInterface(tt.value)
+			if err != nil {
+				t.Fatalf("FromInterface() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("FromInterface() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+
+	// 键顺序应当确定
+	obj, _ := FromInterface(map[string]interface{}{"c": 1, "a": 2, "b": 3})
+	o, _ := obj.AsObject()
+	if keys := o.Keys(); keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("FromInterface() keys = %v, want [a b c]", keys)
+	}
+
+	// 不支持的类型
+	if _, err := FromInterface(complex(1, 2)); err == nil {
+		t.Errorf("FromInterface(complex) should return error")
+	}
+	if _, err := FromInterface(json.Number("abc")); err == nil {
+		t.Errorf("FromInterface(invalid json.Number) should return error")
+	}
+}
+
+func TestFromInterfaceExactNumberRoundTrip(t *testing.T) {
+	bigInt, _ := ParseJSONBigInt("9007199254740993")
+	decimal, _ := ParseJSONDecimal("0.10000000000000000001")
+	for _, value := range []JSONValue{bigInt, decimal} {
+		got, err := FromInterface(ValueToInterface(value))
+		if err != nil {
+			t.Fatalf("FromInterface(ValueToInterface(%s)) error = %v", value, err)
+		}
+		if !IsExactNumber(got) || got.String() != value.String() || !Equal(got, value) {
+			t.Errorf("FromInterface(ValueToInterface(%s)) = %T %s", value, got, got)
+		}
+	}
+
+	// float64能精确表示的数字仍然是JSONNumber
+	for _, n := range []json.Number{"0.1", "42", "-1.5e3"} {
+		got, _ := FromInterface(n)
+		if _, ok := got.(*JSONNumber); !ok {
+			t.Errorf("FromInterface(%s) = %T, want *JSONNumber", n, got)
+		}
+	}
+}
+
+// point 是实现了encoding.TextMarshaler的map键
+type point struct{ x, y int }
+
+func (p point) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(p.x) + "," + strconv.Itoa(p.y)), nil
+}
+
+func TestFromInterfaceMapKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{"整数键", map[int]string{10: "b", 2: "a", -1: "c"}, `{"-1":"c","10":"b","2":"a"}`},
+		{"无符号整数键", map[uint8]bool{7: true}, `{"7":true}`},
+		{"TextMarshaler键", map[point]int{{2, 1}: 2, {1, 2}: 1}, `{"1,2":1,"2,1":2}`},
+		{"字符串类型的键", map[CustomKey][]int{"b": {1}, "a": nil}, `{"a":null,"b":[1]}`},
+		{"nil map", map[int]int(nil), `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromInterface(tt.input)
+			if err != nil {
+				t.Fatalf("FromInterface() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("FromInterface() = %v, want %v", got, tt.want)
+			}
+			// 输出与encoding/json相同
+			if want, _ := json.Marshal(tt.input); string(want) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %v", want, tt.want)
+			}
+		})
+	}
+
+	// 对象的键按转换后的字符串排序
+	obj, _ := FromInterface(map[int]int{3: 0, 1: 0, 2: 0})
+	if keys := obj.(*JSONObject).Keys(); keys[0] != "1" || keys[2] != "3" {
+		t.Errorf("Keys() = %v, want sorted", keys)
+	}
+	if _, err := FromInterface(map[[2]int]int{{1, 2}: 1}); err == nil {
+		t.Error("数组键应该返回错误")
+	}
+}
+
+// CustomKey 是底层类型为字符串的map键
+type CustomKey string
+
+// unsupportedInner 和unsupportedOuter 用于测试不能序列化的结构体字段的路径
+type unsupportedInner struct {
+	Callback func()    `json:"on-change"`
+	Skipped  chan int  `json:"-"`
+	Optional chan bool `json:"optional,omitempty"`
+}
+
+type unsupportedOuter struct {
+	Name  string
+	Items []unsupportedInner `json:"items"`
+}
+
+func TestFromInterfaceUnsupportedType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		wantType string
+		wantPath string
+	}{
+		{"通道", make(chan int), "chan int", "$"},
+		{"复数", complex64(1), "complex64", "$"},
+		{"结构体字段", unsupportedOuter{Items: []unsupportedInner{{}, {}}}, "func()", `$.items[0]["on-change"]`},
+		{"嵌套的map", map[string]interface{}{"b": 1, "a": []interface{}{true, map[string]interface{}{"fn": func() {}}}}, "func()", "$.a[1].fn"},
+		{"指针", &unsupportedOuter{Items: []unsupportedInner{{}}}, "func()", `$.items[0]["on-change"]`},
+		{"整数键的map", map[int]interface{}{3: complex(1, 1)}, "complex128", `$["3"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromInterface(tt.input)
+			var jsonErr *errors.JSONError
+			if !stderrors.As(err, &jsonErr) || jsonErr.Code != errors.ErrUnsupportedType {
+				t.Fatalf("FromInterface() error = %v, want %s", err, errors.ErrUnsupportedType)
+			}
+			if jsonErr.Message != "不能序列化为JSON的类型: "+tt.wantType || jsonErr.Path != tt.wantPath {
+				t.Errorf("FromInterface() error = %v, want 类型 %s 路径 %s", err, tt.wantType, tt.wantPath)
+			}
+			var typeErr *json.UnsupportedTypeError
+			if !stderrors.As(err, &typeErr) {
+				t.Errorf("FromInterface() error = %v, 原始错误应该是json.UnsupportedTypeError", err)
+			}
+		})
+	}
+
+	// 标签为"-"的字段不会被报告；omitempty的空字段是否可以序列化取决于encoding/json的版本，
+	// 不能序列化时报告这个字段
+	type clean struct {
+		Skipped  chan int  `json:"-"`
+		Optional chan bool `json:"optional,omitempty"`
+		Value    int
+	}
+	if _, err := FromInterface(clean{Skipped: make(chan int)}); err != nil {
+		var jsonErr *errors.JSONError
+		if !stderrors.As(err, &jsonErr) || jsonErr.Path != "$.optional" {
+			t.Errorf("FromInterface() error = %v, want 路径 $.optional", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+// Obj 是用于以字面量形式构造JSONObject的映射类型。
+// 值可以是任意FromInterface支持的类型，包括嵌套的Obj和Arr，例如：
+//
+//	types.Obj{"name": "a", "tags": types.Arr{1, 2}}.MustObject()
+//
+// 由于Go映射无序，生成的对象按键排序。
+type Obj map[string]interface{}
+
+// Arr 是用于以字面量形式构造JSONArray的切片类型。
+type Arr []interface{}
+
+// Object 将Obj转换为JSONObject。
+func (o Obj) Object() (*JSONObject, error) {
+	value, err := FromInterface(map[string]interface{}(o))
+	if err != nil {
+		return nil, err
+	}
+	return value.AsObject()
+}
+
+// MustObject 将Obj转换为JSONObject，转换失败时panic。
+func (o Obj) MustObject() *JSONObject {
+	obj, err := o.Object()
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// Array 将Arr转换为JSONArray。
+func (a Arr) Array() (*JSONArray, error) {
+	value, err := FromInterface([]interface{}(a))
+	if err != nil {
+		return nil, err
+	}
+	return value.AsArray()
+}
+
+// MustArray 将Arr转换为JSONArray，转换失败时panic。
+func (a Arr) MustArray() *JSONArray {
+	arr, err := a.Array()
+	if err != nil {
+		panic(err)
+	}
+	return arr
+}
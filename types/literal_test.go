@@ -0,0 +1,33 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestObjArr(t *testing.T) {
+	obj := Obj{
+		"name": "a",
+		"tags": Arr{1, 2},
+		"meta": Obj{"ok": true, "none": nil},
+	}.MustObject()
+
+	want := `{"meta":{"none":null,"ok":true},"name":"a","tags":[1,2]}`
+	if obj.String() != want {
+		t.Errorf("Obj.MustObject() = %v, want %v", obj.String(), want)
+	}
+	if keys := obj.Keys(); keys[0] != "meta" || keys[1] != "name" || keys[2] != "tags" {
+		t.Errorf("Obj.MustObject() keys = %v, want sorted", keys)
+	}
+
+	arr, err := Arr{"x", Obj{"k": 1.5}, Arr{}}.Array()
+	if err != nil {
+		t.Fatalf("Arr.Array() error = %v", err)
+	}
+	if arr.String() != `["x",{"k":1.5},[]]` {
+		t.Errorf("Arr.Array() = %v", arr.String())
+	}
+
+	if _, err := (Obj{"bad": complex(1, 2)}).Object(); err == nil {
+		t.Errorf("Obj.Object() should return error for unsupported value")
+	}
+}
@@ -0,0 +1,322 @@
+package types
+
+import (
+	"bytes"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// MultiValueObject 是允许重复键的对象，按文档顺序保存每一个键值对。
+// 有些系统有意输出重复的键（例如把同名的多个查询参数写成多个属性），
+// JSONObject只能保留每个键的最后一个值，MultiValueObject保留全部的值，序列化时按原顺序输出：
+//
+//	obj := types.NewMultiValueObject().AddString("tag", "a").AddString("tag", "b")
+//	obj.GetAll("tag") // ["a", "b"]
+//	obj.String()      // {"tag":"a","tag":"b"}
+//
+// MultiValueObject实现了JSONValue，可以嵌套在数组和其他MultiValueObject中；
+// AsObject返回ToObject的结果，因此只接受JSONObject的代码看到的是每个键取最后一个值的对象
+type MultiValueObject struct {
+	entries []multiEntry
+}
+
+// multiEntry 是MultiValueObject中的一个键值对
+type multiEntry struct {
+	key   string
+	value JSONValue
+}
+
+// NewMultiValueObject 创建一个新的空MultiValueObject
+func NewMultiValueObject() *MultiValueObject {
+	return &MultiValueObject{}
+}
+
+// MultiValueObjectFrom 把对象转换为MultiValueObject，嵌套的对象同样被转换，键保持插入顺序
+func MultiValueObjectFrom(obj *JSONObject) *MultiValueObject {
+	m := NewMultiValueObject()
+	obj.ForEach(func(key string, value JSONValue) {
+		m.Add(key, toMultiValue(value))
+	})
+	return m
+}
+
+// toMultiValue 把value中的所有JSONObject转换为MultiValueObject
+func toMultiValue(value JSONValue) JSONValue {
+	switch val := value.(type) {
+	case *JSONObject:
+		return MultiValueObjectFrom(val)
+	case *JSONArray:
+		values := make([]JSONValue, len(val.elements))
+		for i, element := range val.elements {
+			values[i] = toMultiValue(element)
+		}
+		return NewJSONArrayFromValuesUnsafe(values)
+	}
+	return value
+}
+
+// Type 返回JSON值的类型
+func (m *MultiValueObject) Type() string {
+	return "object"
+}
+
+// String 返回按文档顺序输出所有键值对的JSON字符串
+func (m *MultiValueObject) String() string {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// MarshalJSON 实现json.Marshaler接口，按文档顺序输出所有键值对，包括重复的键
+func (m *MultiValueObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write 按文档顺序写入所有键值对
+func (m *MultiValueObject) write(buf *bytes.Buffer) error {
+	buf.WriteByte('{')
+	for i, entry := range m.entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, entry.key)
+		buf.WriteByte(':')
+		if err := writeValue(buf, entry.value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// IsNull 检查值是否为null
+func (m *MultiValueObject) IsNull() bool {
+	return false
+}
+
+// IsBoolean 检查值是否为布尔值
+func (m *MultiValueObject) IsBoolean() bool {
+	return false
+}
+
+// IsNumber 检查值是否为数字
+func (m *MultiValueObject) IsNumber() bool {
+	return false
+}
+
+// IsString 检查值是否为字符串
+func (m *MultiValueObject) IsString() bool {
+	return false
+}
+
+// IsArray 检查值是否为数组
+func (m *MultiValueObject) IsArray() bool {
+	return false
+}
+
+// IsObject 检查值是否为对象
+func (m *MultiValueObject) IsObject() bool {
+	return true
+}
+
+// AsBoolean 将值转换为布尔值
+func (m *MultiValueObject) AsBoolean() (bool, error) {
+	return false, errors.ErrInvalidTypeWithDetails("boolean", "object")
+}
+
+// AsNumber 将值转换为数字
+func (m *MultiValueObject) AsNumber() (float64, error) {
+	return 0, errors.ErrInvalidTypeWithDetails("number", "object")
+}
+
+// AsString 将值转换为字符串
+func (m *MultiValueObject) AsString() (string, error) {
+	return m.String(), nil
+}
+
+// AsArray 将值转换为数组
+func (m *MultiValueObject) AsArray() (*JSONArray, error) {
+	return nil, errors.ErrInvalidTypeWithDetails("array", "object")
+}
+
+// AsObject 将值转换为对象，重复的键取最后一个值，见ToObject
+func (m *MultiValueObject) AsObject() (*JSONObject, error) {
+	return m.ToObject(), nil
+}
+
+// Size 返回键值对的数量，重复的键分别计数
+func (m *MultiValueObject) Size() int {
+	return len(m.entries)
+}
+
+// Keys 按第一次出现的顺序返回所有不同的键
+func (m *MultiValueObject) Keys() []string {
+	seen := make(map[string]bool, len(m.entries))
+	keys := make([]string, 0, len(m.entries))
+	for _, entry := range m.entries {
+		if !seen[entry.key] {
+			seen[entry.key] = true
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Has 检查是否包含指定键
+func (m *MultiValueObject) Has(key string) bool {
+	return m.Count(key) > 0
+}
+
+// Count 返回指定键出现的次数
+func (m *MultiValueObject) Count(key string) int {
+	n := 0
+	for _, entry := range m.entries {
+		if entry.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+// Get 获取指定键的最后一个值，与encoding/json处理重复键的方式相同。键不存在时返回null
+func (m *MultiValueObject) Get(key string) JSONValue {
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if m.entries[i].key == key {
+			return m.entries[i].value
+		}
+	}
+	return NewJSONNull()
+}
+
+// GetFirst 获取指定键的第一个值，键不存在时返回null
+func (m *MultiValueObject) GetFirst(key string) JSONValue {
+	for _, entry := range m.entries {
+		if entry.key == key {
+			return entry.value
+		}
+	}
+	return NewJSONNull()
+}
+
+// GetAll 按文档顺序返回指定键的所有值，键不存在时返回nil
+func (m *MultiValueObject) GetAll(key string) []JSONValue {
+	var values []JSONValue
+	for _, entry := range m.entries {
+		if entry.key == key {
+			values = append(values, entry.value)
+		}
+	}
+	return values
+}
+
+// Add 在末尾追加一个键值对，已有的同名键保持不变
+func (m *MultiValueObject) Add(key string, value JSONValue) *MultiValueObject {
+	if value == nil {
+		value = NewJSONNull()
+	}
+	m.entries = append(m.entries, multiEntry{key: key, value: value})
+	return m
+}
+
+// AddString 追加一个字符串值
+func (m *MultiValueObject) AddString(key, value string) *MultiValueObject {
+	return m.Add(key, NewJSONString(value))
+}
+
+// AddNumber 追加一个数字值
+func (m *MultiValueObject) AddNumber(key string, value float64) *MultiValueObject {
+	return m.Add(key, NewJSONNumber(value))
+}
+
+// AddBoolean 追加一个布尔值
+func (m *MultiValueObject) AddBoolean(key string, value bool) *MultiValueObject {
+	return m.Add(key, NewJSONBool(value))
+}
+
+// Put 把指定键设置为唯一的值：替换第一次出现的值并移除其余的重复项，键不存在时追加到末尾
+func (m *MultiValueObject) Put(key string, value JSONValue) *MultiValueObject {
+	if value == nil {
+		value = NewJSONNull()
+	}
+	found := false
+	entries := m.entries[:0]
+	for _, entry := range m.entries {
+		if entry.key == key {
+			if found {
+				continue
+			}
+			found = true
+			entry.value = value
+		}
+		entries = append(entries, entry)
+	}
+	m.entries = entries
+	if !found {
+		m.entries = append(m.entries, multiEntry{key: key, value: value})
+	}
+	return m
+}
+
+// Remove 移除指定键的所有值
+func (m *MultiValueObject) Remove(key string) *MultiValueObject {
+	entries := m.entries[:0]
+	for _, entry := range m.entries {
+		if entry.key != key {
+			entries = append(entries, entry)
+		}
+	}
+	m.entries = entries
+	return m
+}
+
+// ForEach 按文档顺序遍历所有键值对，重复的键被访问多次
+func (m *MultiValueObject) ForEach(fn func(key string, value JSONValue)) {
+	for _, entry := range m.entries {
+		fn(entry.key, entry.value)
+	}
+}
+
+// ToObject 转换为JSONObject，重复的键取最后一个值，位置取第一次出现的位置。
+// 嵌套的MultiValueObject同样被转换
+func (m *MultiValueObject) ToObject() *JSONObject {
+	obj := NewJSONObject()
+	for _, entry := range m.entries {
+		obj.Put(entry.key, fromMultiValue(entry.value))
+	}
+	return obj
+}
+
+// ToObjectFunc 转换为JSONObject，每个键的所有值交给merge合并为一个值，
+// 例如把重复的值合并为数组。只出现一次的键同样调用merge。嵌套的MultiValueObject按ToObject转换
+func (m *MultiValueObject) ToObjectFunc(merge func(key string, values []JSONValue) JSONValue) *JSONObject {
+	obj := NewJSONObject()
+	for _, key := range m.Keys() {
+		values := m.GetAll(key)
+		for i, value := range values {
+			values[i] = fromMultiValue(value)
+		}
+		obj.Put(key, merge(key, values))
+	}
+	return obj
+}
+
+// fromMultiValue 把value中的所有MultiValueObject转换为JSONObject
+func fromMultiValue(value JSONValue) JSONValue {
+	switch val := value.(type) {
+	case *MultiValueObject:
+		return val.ToObject()
+	case *JSONArray:
+		values := make([]JSONValue, len(val.elements))
+		for i, element := range val.elements {
+			values[i] = fromMultiValue(element)
+		}
+		return NewJSONArrayFromValuesUnsafe(values)
+	}
+	return value
+}
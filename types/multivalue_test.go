@@ -0,0 +1,82 @@
+package types
+
+import "testing"
+
+func TestMultiValueObject(t *testing.T) {
+	m := NewMultiValueObject().
+		AddString("tag", "a").
+		AddNumber("id", 1).
+		AddString("tag", "b").
+		Add("nested", NewMultiValueObject().AddBoolean("x", true).AddBoolean("x", false))
+
+	if got := m.String(); got != `{"tag":"a","id":1,"tag":"b","nested":{"x":true,"x":false}}` {
+		t.Errorf("String() = %s", got)
+	}
+	if m.Size() != 4 || m.Count("tag") != 2 || !m.Has("id") || m.Has("missing") {
+		t.Errorf("Size() = %d, Count(tag) = %d", m.Size(), m.Count("tag"))
+	}
+	if got := m.Keys(); len(got) != 3 || got[0] != "tag" || got[1] != "id" || got[2] != "nested" {
+		t.Errorf("Keys() = %v", got)
+	}
+	if s, _ := m.Get("tag").AsString(); s != "b" {
+		t.Errorf("Get(tag) = %s", m.Get("tag"))
+	}
+	if s, _ := m.GetFirst("tag").AsString(); s != "a" {
+		t.Errorf("GetFirst(tag) = %s", m.GetFirst("tag"))
+	}
+	if all := m.GetAll("tag"); len(all) != 2 || !m.Get("missing").IsNull() || m.GetAll("missing") != nil {
+		t.Errorf("GetAll(tag) = %v", all)
+	}
+
+	// 嵌套在数组中的MultiValueObject同样保留重复的键
+	arr := NewJSONArray().Add(m)
+	if got := arr.String(); got != "["+m.String()+"]" {
+		t.Errorf("array String() = %s", got)
+	}
+
+	m.Put("tag", NewJSONString("c"))
+	if got := m.String(); got != `{"tag":"c","id":1,"nested":{"x":true,"x":false}}` {
+		t.Errorf("after Put String() = %s", got)
+	}
+	m.Put("new", nil).Remove("id")
+	if got := m.String(); got != `{"tag":"c","nested":{"x":true,"x":false},"new":null}` {
+		t.Errorf("after Remove String() = %s", got)
+	}
+}
+
+func TestMultiValueObjectConversion(t *testing.T) {
+	m := NewMultiValueObject().
+		AddNumber("b", 1).
+		AddNumber("a", 2).
+		AddNumber("b", 3).
+		Add("list", NewJSONArray().Add(NewMultiValueObject().AddString("k", "x").AddString("k", "y")))
+
+	obj := m.ToObject()
+	if got := obj.String(); got != `{"a":2,"b":3,"list":[{"k":"y"}]}` {
+		t.Errorf("ToObject() = %s", got)
+	}
+	if got := obj.Keys(); got[0] != "b" || got[1] != "a" {
+		t.Errorf("ToObject().Keys() = %v", got)
+	}
+	if asObj, err := m.AsObject(); err != nil || asObj.String() != obj.String() {
+		t.Errorf("AsObject() = %v, %v", asObj, err)
+	}
+
+	merged := m.ToObjectFunc(func(key string, values []JSONValue) JSONValue {
+		if len(values) == 1 {
+			return values[0]
+		}
+		return NewJSONArrayFromValues(values)
+	})
+	if got := merged.String(); got != `{"a":2,"b":[1,3],"list":[{"k":"y"}]}` {
+		t.Errorf("ToObjectFunc() = %s", got)
+	}
+
+	back := MultiValueObjectFrom(obj)
+	if got := back.String(); got != `{"b":3,"a":2,"list":[{"k":"y"}]}` {
+		t.Errorf("MultiValueObjectFrom() = %s", got)
+	}
+	if _, ok := back.Get("list").(*JSONArray).Get(0).(*MultiValueObject); !ok {
+		t.Errorf("nested objects should be converted")
+	}
+}
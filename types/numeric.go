@@ -0,0 +1,241 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// exactNumber 由精确表示数字的值类型实现，即JSONBigInt和JSONDecimal
+type exactNumber interface {
+	JSONValue
+	rat() *big.Rat
+}
+
+// IsExactNumber 检查v是否为JSONBigInt或JSONDecimal
+func IsExactNumber(v JSONValue) bool {
+	_, ok := v.(exactNumber)
+	return ok
+}
+
+// numberRat 返回数字值的精确有理数表示。
+// 其他数字类型按float64的最短十进制表示转换，因此0.1与解析"0.1"得到的JSONDecimal相等
+func numberRat(v JSONValue) (*big.Rat, error) {
+	if v == nil || !v.IsNumber() {
+		return nil, errors.ErrInvalidTypeWithDetails("number", typeName(v))
+	}
+	if exact, ok := v.(exactNumber); ok {
+		return exact.rat(), nil
+	}
+	f, _ := v.AsNumber()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.NewJSONError(errors.ErrTypeConversion,
+			"无法精确表示数字: "+strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r, nil
+}
+
+// CompareNumbers 精确比较两个数字值，a小于、等于、大于b时分别返回-1、0、1。
+// JSONBigInt和JSONDecimal不会先转换为float64，可以区分超出float64精度的差异
+func CompareNumbers(a, b JSONValue) (int, error) {
+	ra, err := numberRat(a)
+	if err != nil {
+		return 0, err
+	}
+	rb, err := numberRat(b)
+	if err != nil {
+		return 0, err
+	}
+	return ra.Cmp(rb), nil
+}
+
+// NumbersEqual 检查两个数字值是否相等。两者都不是JSONBigInt或JSONDecimal时比较float64，
+// 否则精确比较；任一值不是数字时返回false
+func NumbersEqual(a, b JSONValue) bool {
+	if !IsExactNumber(a) && !IsExactNumber(b) {
+		if a == nil || b == nil || !a.IsNumber() || !b.IsNumber() {
+			return false
+		}
+		x, _ := a.AsNumber()
+		y, _ := b.AsNumber()
+		return x == y
+	}
+	cmp, err := CompareNumbers(a, b)
+	return err == nil && cmp == 0
+}
+
+// Equal 深度比较两个JSON值。数字用NumbersEqual比较，JSONBigInt和JSONDecimal不会先转换为float64；
+// 对象不考虑键的顺序；nil与null相等
+func Equal(a, b JSONValue) bool {
+	if a == nil || b == nil {
+		return (a == nil || a.IsNull()) && (b == nil || b.IsNull())
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Type() {
+	case "null":
+		return true
+	case "boolean":
+		x, _ := a.AsBoolean()
+		y, _ := b.AsBoolean()
+		return x == y
+	case "number":
+		return NumbersEqual(a, b)
+	case "string":
+		x, _ := a.AsString()
+		y, _ := b.AsString()
+		return x == y
+	case "array":
+		x, _ := a.AsArray()
+		y, _ := b.AsArray()
+		if x.Size() != y.Size() {
+			return false
+		}
+		for i := 0; i < x.Size(); i++ {
+			if !Equal(x.Get(i), y.Get(i)) {
+				return false
+			}
+		}
+		return true
+	case "object":
+		x, _ := a.AsObject()
+		y, _ := b.AsObject()
+		if x.Size() != y.Size() {
+			return false
+		}
+		for _, key := range x.Keys() {
+			if !y.Has(key) || !Equal(x.Get(key), y.Get(key)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ParseNumber 解析十进制数字文本。文本的值与它的float64的最短十进制表示相同时返回JSONNumber，
+// 例如"0.1"和"42"；否则整数返回JSONBigInt，带小数点或指数的数字返回JSONDecimal，不丢失精度
+func ParseNumber(s string) (JSONValue, error) {
+	d, err := ParseJSONDecimal(s)
+	if err != nil {
+		return nil, err
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if r, err := numberRat(NewJSONNumber(f)); err == nil && r.Cmp(d.rat()) == 0 {
+			return NewJSONNumber(f), nil
+		}
+	}
+	if !strings.ContainsAny(s, ".eE") {
+		return ParseJSONBigInt(s)
+	}
+	return d, nil
+}
+
+// ToDecimal 将数字值转换为JSONDecimal，JSONNumber按最短十进制表示转换
+func ToDecimal(v JSONValue) (*JSONDecimal, error) {
+	switch val := v.(type) {
+	case *JSONDecimal:
+		return val, nil
+	case *JSONBigInt:
+		return val.Decimal(), nil
+	}
+	if v == nil || !v.IsNumber() {
+		return nil, errors.ErrInvalidTypeWithDetails("number", typeName(v))
+	}
+	f, _ := v.AsNumber()
+	return NewJSONDecimalFromFloat(f)
+}
+
+// ToBigInt 将数字值转换为JSONBigInt，值有非0的小数部分时返回错误
+func ToBigInt(v JSONValue) (*JSONBigInt, error) {
+	if b, ok := v.(*JSONBigInt); ok {
+		return b, nil
+	}
+	d, err := ToDecimal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsInteger() {
+		return nil, errors.NewJSONError(errors.ErrTypeConversion, "数字不是整数: "+d.String())
+	}
+	return d.BigInt(), nil
+}
+
+// typeName 返回值的类型名，nil为null
+func typeName(v JSONValue) string {
+	if v == nil {
+		return "null"
+	}
+	return v.Type()
+}
+
+// AddNumbers 返回a + b，结果类型见arithmetic
+func AddNumbers(a, b JSONValue) (JSONValue, error) {
+	return arithmetic(a, b,
+		func(x, y float64) float64 { return x + y },
+		(*JSONBigInt).Add,
+		(*JSONDecimal).Add)
+}
+
+// SubNumbers 返回a - b，结果类型见arithmetic
+func SubNumbers(a, b JSONValue) (JSONValue, error) {
+	return arithmetic(a, b,
+		func(x, y float64) float64 { return x - y },
+		(*JSONBigInt).Sub,
+		(*JSONDecimal).Sub)
+}
+
+// MulNumbers 返回a * b，结果类型见arithmetic
+func MulNumbers(a, b JSONValue) (JSONValue, error) {
+	return arithmetic(a, b,
+		func(x, y float64) float64 { return x * y },
+		(*JSONBigInt).Mul,
+		(*JSONDecimal).Mul)
+}
+
+// arithmetic 按操作数的类型选择运算：
+// 都不是JSONBigInt或JSONDecimal时用float64计算，结果为JSONNumber；
+// 有JSONDecimal时结果为JSONDecimal；有JSONBigInt并且另一个操作数是整数时结果为JSONBigInt，
+// 否则为JSONDecimal
+func arithmetic(a, b JSONValue,
+	floatOp func(x, y float64) float64,
+	intOp func(x, y *JSONBigInt) *JSONBigInt,
+	decimalOp func(x, y *JSONDecimal) *JSONDecimal) (JSONValue, error) {
+	if a == nil || !a.IsNumber() {
+		return nil, errors.ErrInvalidTypeWithDetails("number", typeName(a))
+	}
+	if b == nil || !b.IsNumber() {
+		return nil, errors.ErrInvalidTypeWithDetails("number", typeName(b))
+	}
+
+	if !IsExactNumber(a) && !IsExactNumber(b) {
+		x, _ := a.AsNumber()
+		y, _ := b.AsNumber()
+		return NewJSONNumber(floatOp(x, y)), nil
+	}
+
+	_, aDecimal := a.(*JSONDecimal)
+	_, bDecimal := b.(*JSONDecimal)
+	if !aDecimal && !bDecimal {
+		x, errA := ToBigInt(a)
+		y, errB := ToBigInt(b)
+		if errA == nil && errB == nil {
+			return intOp(x, y), nil
+		}
+	}
+
+	x, err := ToDecimal(a)
+	if err != nil {
+		return nil, err
+	}
+	y, err := ToDecimal(b)
+	if err != nil {
+		return nil, err
+	}
+	return decimalOp(x, y), nil
+}
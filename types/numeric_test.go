@@ -0,0 +1,121 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCompareNumbers(t *testing.T) {
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	big2, _ := ParseJSONBigInt("9007199254740992")
+	if NumbersEqual(big1, big2) {
+		t.Error("超出float64精度的两个整数不应该相等")
+	}
+	if !NumbersEqual(big2, NewJSONNumber(9007199254740992)) {
+		t.Error("相同值的JSONBigInt和JSONNumber应该相等")
+	}
+	if cmp, _ := CompareNumbers(big1, big2); cmp != 1 {
+		t.Errorf("CompareNumbers() = %d, want 1", cmp)
+	}
+
+	tenth, _ := ParseJSONDecimal("0.10")
+	if !NumbersEqual(tenth, NewJSONNumber(0.1)) {
+		t.Error("0.10 应该等于JSONNumber 0.1")
+	}
+	if NumbersEqual(tenth, NewJSONString("0.1")) {
+		t.Error("数字不应该等于字符串")
+	}
+	if _, err := CompareNumbers(tenth, NewJSONNull()); err == nil {
+		t.Error("CompareNumbers(null) 应该返回错误")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	big2, _ := ParseJSONBigInt("9007199254740992")
+	tests := []struct {
+		a, b JSONValue
+		want bool
+	}{
+		{big1, big2, false},
+		{big2, NewJSONNumber(9007199254740992), true},
+		{big1, NewJSONNumber(9007199254740992), false},
+		{NewJSONArray().Add(big1), NewJSONArray().Add(big2), false},
+		{NewJSONObject().Put("a", big1).Put("b", NewJSONNull()), NewJSONObject().Put("b", NewJSONNull()).Put("a", big1), true},
+		{NewJSONNumber(1), NewJSONString("1"), false},
+		{nil, NewJSONNull(), true},
+		{nil, NewJSONBool(false), false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // 期望的类型
+	}{
+		{"42", "*types.JSONNumber"},
+		{"0.1", "*types.JSONNumber"},
+		{"-2.5e-3", "*types.JSONNumber"},
+		{"9007199254740993", "*types.JSONBigInt"},
+		{"-123456789012345678901234567890", "*types.JSONBigInt"},
+		{"0.10000000000000000001", "*types.JSONDecimal"},
+		{"9007199254740993.0", "*types.JSONDecimal"},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.input)
+		if err != nil {
+			t.Fatalf("ParseNumber(%s) error = %v", tt.input, err)
+		}
+		if typ := fmt.Sprintf("%T", got); typ != tt.want {
+			t.Errorf("ParseNumber(%s) = %s, want %s", tt.input, typ, tt.want)
+		}
+		want, _ := ParseJSONDecimal(tt.input)
+		if !NumbersEqual(got, want) {
+			t.Errorf("ParseNumber(%s) = %s, 值不相等", tt.input, got)
+		}
+	}
+	if _, err := ParseNumber("1x"); err == nil {
+		t.Error("ParseNumber(1x) 应该返回错误")
+	}
+}
+
+func TestArithmeticHelpers(t *testing.T) {
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	tenth, _ := ParseJSONDecimal("0.10")
+
+	tests := []struct {
+		name     string
+		op       func(a, b JSONValue) (JSONValue, error)
+		a, b     JSONValue
+		want     string
+		wantType string
+	}{
+		{"float", AddNumbers, NewJSONNumber(1.5), NewJSONNumber(2), "3.5", "*types.JSONNumber"},
+		{"bigint+float integer", AddNumbers, big1, NewJSONNumber(2), "9007199254740995", "*types.JSONBigInt"},
+		{"bigint*float fraction", MulNumbers, big1, NewJSONNumber(0.5), "4503599627370496.5", "*types.JSONDecimal"},
+		{"decimal-bigint", SubNumbers, tenth, NewJSONBigIntFromInt64(1), "-0.90", "*types.JSONDecimal"},
+		{"decimal+float", AddNumbers, tenth, NewJSONNumber(0.2), "0.30", "*types.JSONDecimal"},
+	}
+	for _, tt := range tests {
+		got, err := tt.op(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if got.String() != tt.want || fmt.Sprintf("%T", got) != tt.wantType {
+			t.Errorf("%s: = %v (%s), want %v (%s)", tt.name, got, fmt.Sprintf("%T", got), tt.want, tt.wantType)
+		}
+	}
+
+	if _, err := AddNumbers(tenth, NewJSONString("1")); err == nil {
+		t.Error("AddNumbers(string) 应该返回错误")
+	}
+	if _, err := ToBigInt(tenth); err == nil {
+		t.Error("ToBigInt(0.10) 应该返回错误")
+	}
+}
@@ -0,0 +1,254 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// FractionMode 决定转换为整数时如何处理小数部分
+type FractionMode int
+
+const (
+	// FractionTruncate 向零截断小数部分
+	FractionTruncate FractionMode = iota
+	// FractionRound 四舍五入（远离0），与JSONDecimal.Round相同
+	FractionRound
+	// FractionError 有非0的小数部分时返回错误
+	FractionError
+)
+
+// OverflowMode 决定转换结果超出目标类型的范围时如何处理
+type OverflowMode int
+
+const (
+	// OverflowClamp 取目标类型的边界值
+	OverflowClamp OverflowMode = iota
+	// OverflowError 返回错误
+	OverflowError
+)
+
+// NumericPolicy 决定数字值转换为Go的整数和float64的方式。
+// AsNumber（JSONBigInt和JSONDecimal）、GetInt、Accessor.Int以及generic包的转换都使用
+// 通过SetNumericPolicy设置的全局策略，也可以直接调用策略的方法按其他规则转换单个值。
+// 非数字值先经过AsNumber转换，字符串"12"可以转换为12
+type NumericPolicy struct {
+	// Fraction 决定转换为整数时如何处理小数部分
+	Fraction FractionMode
+	// Overflow 决定超出整数范围或float64范围时如何处理
+	Overflow OverflowMode
+	// ErrorOnPrecisionLoss 为true时，JSONBigInt和JSONDecimal转换为float64后
+	// 不能还原为原来的十进制值时返回错误，例如超出2^53的整数
+	ErrorOnPrecisionLoss bool
+}
+
+// DefaultNumericPolicy 返回默认的策略：截断小数部分，超出范围时取边界值，允许丢失精度
+func DefaultNumericPolicy() NumericPolicy {
+	return NumericPolicy{Fraction: FractionTruncate, Overflow: OverflowClamp}
+}
+
+// StrictNumericPolicy 返回严格的策略：小数部分、超出范围和丢失精度都返回错误
+func StrictNumericPolicy() NumericPolicy {
+	return NumericPolicy{Fraction: FractionError, Overflow: OverflowError, ErrorOnPrecisionLoss: true}
+}
+
+// RoundNumericPolicy 返回四舍五入的策略：超出范围时取边界值，允许丢失精度
+func RoundNumericPolicy() NumericPolicy {
+	return NumericPolicy{Fraction: FractionRound, Overflow: OverflowClamp}
+}
+
+// numericPolicy 是当前的全局策略
+var numericPolicy atomic.Value
+
+func init() {
+	numericPolicy.Store(DefaultNumericPolicy())
+}
+
+// SetNumericPolicy 设置全局的数字转换策略并返回原来的策略，通常在程序启动时调用一次
+func SetNumericPolicy(p NumericPolicy) NumericPolicy {
+	return numericPolicy.Swap(p).(NumericPolicy)
+}
+
+// CurrentNumericPolicy 返回当前的全局数字转换策略
+func CurrentNumericPolicy() NumericPolicy {
+	return numericPolicy.Load().(NumericPolicy)
+}
+
+// Float64 把值转换为float64。JSONBigInt和JSONDecimal返回最接近的float64，
+// 丢失精度或超出float64范围时按策略返回错误；返回错误时仍然返回最接近的值
+func (p NumericPolicy) Float64(v JSONValue) (float64, error) {
+	exact, ok := v.(exactNumber)
+	if !ok {
+		if v == nil {
+			return 0, errors.ErrInvalidTypeWithDetails("number", "null")
+		}
+		return v.AsNumber()
+	}
+
+	r := exact.rat()
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) {
+		if p.Overflow == OverflowError || p.ErrorOnPrecisionLoss {
+			return f, numberRangeError(exact.String(), "float64")
+		}
+		return math.Copysign(math.MaxFloat64, f), nil
+	}
+	if p.ErrorOnPrecisionLoss {
+		back, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if back.Cmp(r) != 0 {
+			return f, errors.NewJSONError(errors.ErrTypeConversion,
+				fmt.Sprintf("数字 %s 转换为float64时丢失精度", exact.String()))
+		}
+	}
+	return f, nil
+}
+
+// Int 把值转换为int
+func (p NumericPolicy) Int(v JSONValue) (int, error) {
+	n, err := p.IntBits(v, strconv.IntSize)
+	return int(n), err
+}
+
+// Int64 把值转换为int64
+func (p NumericPolicy) Int64(v JSONValue) (int64, error) {
+	return p.IntBits(v, 64)
+}
+
+// Uint64 把值转换为uint64
+func (p NumericPolicy) Uint64(v JSONValue) (uint64, error) {
+	return p.UintBits(v, 64)
+}
+
+// IntBits 把值转换为bits位（8、16、32或64）有符号整数能表示的值。
+// 超出范围时返回边界值，策略为OverflowError时同时返回错误
+func (p NumericPolicy) IntBits(v JSONValue, bits int) (int64, error) {
+	min := int64(-1) << (bits - 1)
+	max := int64(math.MaxInt64) >> (64 - bits)
+	target := "int" + strconv.Itoa(bits)
+
+	if IsExactNumber(v) {
+		n, err := p.exactInteger(v.(exactNumber))
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case n.Cmp(big.NewInt(min)) < 0:
+			return min, p.overflow(n.String(), target)
+		case n.Cmp(big.NewInt(max)) > 0:
+			return max, p.overflow(n.String(), target)
+		}
+		return n.Int64(), nil
+	}
+
+	f, err := p.floatInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case f < float64(min):
+		return min, p.overflow(formatFloat(f), target)
+	case f >= -float64(min):
+		return max, p.overflow(formatFloat(f), target)
+	}
+	return int64(f), nil
+}
+
+// UintBits 把值转换为bits位（8、16、32或64）无符号整数能表示的值，负数按超出范围处理。
+// 超出范围时返回边界值，策略为OverflowError时同时返回错误
+func (p NumericPolicy) UintBits(v JSONValue, bits int) (uint64, error) {
+	max := uint64(math.MaxUint64) >> (64 - bits)
+	target := "uint" + strconv.Itoa(bits)
+
+	if IsExactNumber(v) {
+		n, err := p.exactInteger(v.(exactNumber))
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case n.Sign() < 0:
+			return 0, p.overflow(n.String(), target)
+		case n.Cmp(new(big.Int).SetUint64(max)) > 0:
+			return max, p.overflow(n.String(), target)
+		}
+		return n.Uint64(), nil
+	}
+
+	f, err := p.floatInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case f < 0:
+		return 0, p.overflow(formatFloat(f), target)
+	case f >= math.Ldexp(1, bits):
+		return max, p.overflow(formatFloat(f), target)
+	}
+	return uint64(f), nil
+}
+
+// exactInteger 按策略处理JSONBigInt或JSONDecimal的小数部分
+func (p NumericPolicy) exactInteger(v exactNumber) (*big.Int, error) {
+	d, err := ToDecimal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsInteger() {
+		switch p.Fraction {
+		case FractionError:
+			return nil, fractionError(d.String())
+		case FractionRound:
+			d = d.Round(0)
+		}
+	}
+	return d.BigInt().value, nil
+}
+
+// floatInteger 把其他值转换为float64并按策略处理小数部分，NaN和无穷大返回错误
+func (p NumericPolicy) floatInteger(v JSONValue) (float64, error) {
+	if v == nil {
+		return 0, errors.ErrInvalidTypeWithDetails("number", "null")
+	}
+	f, err := v.AsNumber()
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.NewJSONError(errors.ErrTypeConversion, "无法转换为整数: "+formatFloat(f))
+	}
+	if f != math.Trunc(f) {
+		switch p.Fraction {
+		case FractionError:
+			return 0, fractionError(formatFloat(f))
+		case FractionRound:
+			return math.Round(f), nil
+		}
+	}
+	return math.Trunc(f), nil
+}
+
+// overflow 按策略返回超出范围的错误，OverflowClamp时返回nil
+func (p NumericPolicy) overflow(value, target string) error {
+	if p.Overflow == OverflowClamp {
+		return nil
+	}
+	return numberRangeError(value, target)
+}
+
+// numberRangeError 创建数字超出目标类型范围的错误
+func numberRangeError(value, target string) error {
+	return errors.NewJSONError(errors.ErrTypeConversion, fmt.Sprintf("数字 %s 超出%s的范围", value, target))
+}
+
+// fractionError 创建数字有小数部分的错误
+func fractionError(value string) error {
+	return errors.NewJSONError(errors.ErrTypeConversion, fmt.Sprintf("数字 %s 不是整数", value))
+}
+
+// formatFloat 按最短表示格式化float64
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumericPolicyInt(t *testing.T) {
+	huge, _ := ParseJSONBigInt("100000000000000000000")
+	half, _ := ParseJSONDecimal("-2.5")
+
+	tests := []struct {
+		name    string
+		policy  NumericPolicy
+		value   JSONValue
+		bits    int
+		want    int64
+		wantErr bool
+	}{
+		{"截断", DefaultNumericPolicy(), NewJSONNumber(2.7), 64, 2, false},
+		{"截断负数", DefaultNumericPolicy(), NewJSONNumber(-2.7), 64, -2, false},
+		{"四舍五入", RoundNumericPolicy(), NewJSONNumber(2.5), 64, 3, false},
+		{"四舍五入小数", RoundNumericPolicy(), half, 64, -3, false},
+		{"严格拒绝小数", StrictNumericPolicy(), NewJSONNumber(2.5), 64, 0, true},
+		{"严格接受整数", StrictNumericPolicy(), NewJSONNumber(2), 64, 2, false},
+		{"取边界值", DefaultNumericPolicy(), NewJSONNumber(300), 8, 127, false},
+		{"取下边界值", DefaultNumericPolicy(), NewJSONNumber(-300), 8, -128, false},
+		{"大整数取边界值", DefaultNumericPolicy(), huge, 64, math.MaxInt64, false},
+		{"严格拒绝溢出", StrictNumericPolicy(), NewJSONNumber(300), 8, 127, true},
+		{"int64上界", StrictNumericPolicy(), NewJSONNumber(math.Ldexp(1, 63)), 64, math.MaxInt64, true},
+		{"字符串", DefaultNumericPolicy(), NewJSONString("12"), 64, 12, false},
+		{"NaN", DefaultNumericPolicy(), NewJSONNumber(math.NaN()), 64, 0, true},
+		{"对象", DefaultNumericPolicy(), NewJSONObject(), 64, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.IntBits(tt.value, tt.bits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IntBits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("IntBits() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if n, err := DefaultNumericPolicy().UintBits(NewJSONNumber(-1), 64); n != 0 || err != nil {
+		t.Errorf("UintBits(-1) = %d, %v", n, err)
+	}
+	if _, err := StrictNumericPolicy().Uint64(NewJSONNumber(-1)); err == nil {
+		t.Error("严格策略下Uint64(-1)应该返回错误")
+	}
+	if n, err := DefaultNumericPolicy().UintBits(huge, 16); n != math.MaxUint16 || err != nil {
+		t.Errorf("UintBits(huge, 16) = %d, %v", n, err)
+	}
+}
+
+func TestNumericPolicyFloat64(t *testing.T) {
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	tenth, _ := ParseJSONDecimal("0.10")
+
+	if f, err := DefaultNumericPolicy().Float64(big1); err != nil || f != 9007199254740992 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+	if f, err := StrictNumericPolicy().Float64(big1); err == nil || f != 9007199254740992 {
+		t.Errorf("严格策略下Float64() = %v, %v, 期望返回最接近的值和错误", f, err)
+	}
+	if f, err := StrictNumericPolicy().Float64(tenth); err != nil || f != 0.1 {
+		t.Errorf("0.10可以精确还原, Float64() = %v, %v", f, err)
+	}
+	if f, err := StrictNumericPolicy().Float64(NewJSONNumber(1.5)); err != nil || f != 1.5 {
+		t.Errorf("Float64(JSONNumber) = %v, %v", f, err)
+	}
+}
+
+func TestSetNumericPolicy(t *testing.T) {
+	old := SetNumericPolicy(StrictNumericPolicy())
+	defer SetNumericPolicy(old)
+
+	big1, _ := ParseJSONBigInt("9007199254740993")
+	obj := NewJSONObject().PutNumber("n", 2.5).Put("id", big1)
+	if _, err := obj.GetInt("n"); err == nil {
+		t.Error("严格策略下GetInt(2.5)应该返回错误")
+	}
+	if _, err := big1.AsNumber(); err == nil {
+		t.Error("严格策略下AsNumber()应该在丢失精度时返回错误")
+	}
+	if _, err := obj.At("n").Int(); err == nil {
+		t.Error("严格策略下Accessor.Int()应该返回错误")
+	}
+
+	SetNumericPolicy(RoundNumericPolicy())
+	if n, err := obj.GetInt("n"); err != nil || n != 3 {
+		t.Errorf("GetInt() = %d, %v, want 3", n, err)
+	}
+	arr := NewJSONArray().AddNumber(-1.5)
+	if n, err := arr.GetInt(0); err != nil || n != -2 {
+		t.Errorf("JSONArray.GetInt() = %d, %v, want -2", n, err)
+	}
+	if _, err := arr.GetInt(3); err == nil {
+		t.Error("GetInt()越界应该返回错误")
+	}
+}
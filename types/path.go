@@ -0,0 +1,291 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// PathSegment 是路径中的一段，是对象的键或数组的索引
+type PathSegment struct {
+	Key     string // 对象键，IsIndex为false时有效
+	Index   int    // 数组索引，IsIndex为true时有效
+	IsIndex bool   // 是否为数组索引
+}
+
+// KeySegment 返回对象键的路径段
+func KeySegment(key string) PathSegment {
+	return PathSegment{Key: key}
+}
+
+// IndexSegment 返回数组索引的路径段
+func IndexSegment(index int) PathSegment {
+	return PathSegment{Index: index, IsIndex: true}
+}
+
+// String 返回段的字符串表示，即键本身或索引的十进制表示，与JSON Pointer中未转义的段相同
+func (s PathSegment) String() string {
+	if s.IsIndex {
+		return strconv.Itoa(s.Index)
+	}
+	return s.Key
+}
+
+// same 检查两个段是否指向同一个位置。JSON Pointer不区分键和索引，
+// 因此键"0"和索引0被视为相同的段
+func (s PathSegment) same(other PathSegment) bool {
+	if s.IsIndex == other.IsIndex {
+		return s == other
+	}
+	return s.String() == other.String()
+}
+
+// Path 是从根开始的结构化路径，根是空路径，Depth就是段的数量。
+// Path可以格式化为JSON Path（$.a['b c'][0]）或JSON Pointer（/a/b c/0），也可以从两者解析，
+// 是jsonpath、patch、diff、stream等包在字符串路径之间转换时共用的表示。
+// Path的方法从不修改接收者，返回的新路径不与接收者共享存储
+type Path []PathSegment
+
+// ParsePath 解析不含通配符和过滤器的JSON Path：$、.name、['name']、["name"]和[n]。
+// 引号中的\\和与引号相同的\'或\"是转义，其他反斜杠保持原样
+func ParsePath(path string) (Path, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.ErrInvalidPathWithDetails(path, "路径必须以$开头")
+	}
+
+	p := Path{}
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, errors.ErrInvalidPathWithDetails(path, "属性名不能为空")
+			}
+			if name == "*" {
+				return nil, errors.ErrInvalidPathWithDetails(path, "路径不能包含通配符")
+			}
+			p = append(p, KeySegment(name))
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, "[\""):
+			name, n, ok := unquotePathKey(rest[1:])
+			if !ok || len(rest) < n+2 || rest[n+1] != ']' {
+				return nil, errors.ErrInvalidPathWithDetails(path, "未闭合的属性名")
+			}
+			p = append(p, KeySegment(name))
+			rest = rest[n+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.ErrInvalidPathWithDetails(path, "未闭合的索引")
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, errors.ErrInvalidPathWithDetails(path, "索引必须是非负整数")
+			}
+			p = append(p, IndexSegment(index))
+			rest = rest[end+1:]
+		default:
+			return nil, errors.ErrInvalidPathWithDetails(path, "无效的路径字符")
+		}
+	}
+	return p, nil
+}
+
+// ParsePointer 解析JSON Pointer（RFC 6901），根是空字符串。
+// JSON Pointer不区分键和索引，所有段都被解析为键
+func ParsePointer(pointer string) (Path, error) {
+	if pointer == "" {
+		return Path{}, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errors.ErrInvalidPathWithDetails(pointer, "JSON Pointer必须以/开头")
+	}
+	parts := strings.Split(pointer[1:], "/")
+	p := make(Path, len(parts))
+	for i, part := range parts {
+		if strings.Contains(part, "~") {
+			part = strings.ReplaceAll(part, "~1", "/")
+			part = strings.ReplaceAll(part, "~0", "~")
+		}
+		p[i] = KeySegment(part)
+	}
+	return p, nil
+}
+
+// Depth 返回路径的深度，根为0
+func (p Path) Depth() int {
+	return len(p)
+}
+
+// Join 返回在路径后追加segments的新路径
+func (p Path) Join(segments ...PathSegment) Path {
+	joined := make(Path, len(p), len(p)+len(segments))
+	copy(joined, p)
+	return append(joined, segments...)
+}
+
+// Key 返回对象键key对应的子路径
+func (p Path) Key(key string) Path {
+	return p.Join(KeySegment(key))
+}
+
+// Index 返回数组索引index对应的子路径
+func (p Path) Index(index int) Path {
+	return p.Join(IndexSegment(index))
+}
+
+// Parent 返回父路径，根的父路径是根
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return Path{}
+	}
+	return p[:len(p)-1].Join()
+}
+
+// Base 返回路径的最后一段，根没有最后一段，第二个返回值为false
+func (p Path) Base() (PathSegment, bool) {
+	if len(p) == 0 {
+		return PathSegment{}, false
+	}
+	return p[len(p)-1], true
+}
+
+// IsAncestorOf 检查p是否是other的祖先，路径不是自己的祖先
+func (p Path) IsAncestorOf(other Path) bool {
+	return len(p) < len(other) && other[:len(p)].Equal(p)
+}
+
+// Equal 检查两个路径是否指向同一个位置
+func (p Path) Equal(other Path) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if !p[i].same(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回路径的JSON Path表示，可以被ParsePath和jsonpath包解析。
+// 标识符形式的键使用点号，其他键使用方括号
+func (p Path) String() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, segment := range p {
+		writePathSegment(&sb, segment)
+	}
+	return sb.String()
+}
+
+// Pointer 返回路径的JSON Pointer表示，根是空字符串
+func (p Path) Pointer() string {
+	var sb strings.Builder
+	for _, segment := range p {
+		sb.WriteByte('/')
+		s := segment.String()
+		if strings.ContainsAny(s, "~/") {
+			s = strings.ReplaceAll(s, "~", "~0")
+			s = strings.ReplaceAll(s, "/", "~1")
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// Segments 返回每一段的字符串表示
+func (p Path) Segments() []string {
+	segments := make([]string, len(p))
+	for i, segment := range p {
+		segments[i] = segment.String()
+	}
+	return segments
+}
+
+// ChildPath 返回在JSON Path字符串path后追加segment的路径，格式与Path.String相同。
+// 遍历文档时逐层构建路径字符串可以使用它，而不必为每个节点保存Path
+func ChildPath(path string, segment PathSegment) string {
+	var sb strings.Builder
+	sb.Grow(len(path) + len(segment.Key) + 4)
+	sb.WriteString(path)
+	writePathSegment(&sb, segment)
+	return sb.String()
+}
+
+// writePathSegment 写入段的JSON Path表示
+func writePathSegment(sb *strings.Builder, segment PathSegment) {
+	if segment.IsIndex {
+		sb.WriteByte('[')
+		sb.WriteString(strconv.Itoa(segment.Index))
+		sb.WriteByte(']')
+		return
+	}
+	if isPathIdentifier(segment.Key) {
+		sb.WriteByte('.')
+		sb.WriteString(segment.Key)
+		return
+	}
+
+	// 优先使用单引号，键中只有单引号时使用双引号，两种引号都有时转义
+	key := segment.Key
+	quote := byte('\'')
+	if strings.IndexByte(key, '\'') >= 0 && strings.IndexByte(key, '"') < 0 {
+		quote = '"'
+	}
+	sb.WriteByte('[')
+	sb.WriteByte(quote)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == quote:
+			sb.WriteByte('\\')
+		case c == '\\' && (i == len(key)-1 || key[i+1] == '\\' || key[i+1] == quote):
+			// 只转义会被误认为转义的反斜杠，其他反斜杠保持原样
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	sb.WriteByte(quote)
+	sb.WriteByte(']')
+}
+
+// unquotePathKey 解析以引号开始的属性名，返回属性名和包括两个引号在内消耗的字节数
+func unquotePathKey(s string) (string, int, bool) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return sb.String(), i + 1, true
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == quote):
+			i++
+			c = s[i]
+		}
+		sb.WriteByte(c)
+	}
+	return "", 0, false
+}
+
+// isPathIdentifier 检查键是否可以用点号表示：字母或下划线开头，由字母、数字和下划线组成，
+// 与jsonpath包接受的点号属性名相同
+func isPathIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    Path
+		pointer string
+	}{
+		{"$", Path{}, ""},
+		{"$.a.b", Path{KeySegment("a"), KeySegment("b")}, "/a/b"},
+		{"$.users[0]['first name']", Path{KeySegment("users"), IndexSegment(0), KeySegment("first name")}, "/users/0/first name"},
+		{`$["a/b"]['m~n']`, Path{KeySegment("a/b"), KeySegment("m~n")}, "/a~1b/m~0n"},
+		{`$['it\'s']["say \"hi\""]`, Path{KeySegment("it's"), KeySegment(`say "hi"`)}, "/it's/say \"hi\""},
+		{`$['a\b']`, Path{KeySegment(`a\b`)}, `/a\b`},
+		{"$['']", Path{KeySegment("")}, "/"},
+	}
+	for _, tt := range tests {
+		got, err := ParsePath(tt.path)
+		if err != nil {
+			t.Errorf("ParsePath(%q) error = %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+		if got.Pointer() != tt.pointer {
+			t.Errorf("ParsePath(%q).Pointer() = %q, want %q", tt.path, got.Pointer(), tt.pointer)
+		}
+	}
+
+	for _, path := range []string{"", "a.b", "$.", "$.a.*", "$[*]", "$[-1]", "$[1", "$['a", "$['a'", "$x"} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q) should fail", path)
+		}
+	}
+}
+
+func TestPathStringRoundTrip(t *testing.T) {
+	keys := []string{"name", "_x1", "1st", "a b", "a.b", "", "中文", "it's", `say "hi"`, `both ' and "`,
+		`back\slash`, `trailing\`, `\'`, `\\`, "a$", "$", "]", "*"}
+	for _, key := range keys {
+		p := Path{KeySegment(key), IndexSegment(3)}
+		s := p.String()
+		got, err := ParsePath(s)
+		if err != nil || !reflect.DeepEqual(got, p) {
+			t.Errorf("ParsePath(%q) = %v, %v, want %v", s, got, err, p)
+		}
+		if child := ChildPath(ChildPath("$", KeySegment(key)), IndexSegment(3)); child != s {
+			t.Errorf("ChildPath() = %q, want %q", child, s)
+		}
+	}
+	if got := (Path{KeySegment("a"), KeySegment("b c"), IndexSegment(2)}).String(); got != "$.a['b c'][2]" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	got, err := ParsePointer("/a~1b/m~0n/0/~01")
+	if err != nil {
+		t.Fatalf("ParsePointer() error = %v", err)
+	}
+	if want := []string{"a/b", "m~n", "0", "~1"}; !reflect.DeepEqual(got.Segments(), want) {
+		t.Errorf("Segments() = %v, want %v", got.Segments(), want)
+	}
+	if got.Pointer() != "/a~1b/m~0n/0/~01" {
+		t.Errorf("Pointer() = %q", got.Pointer())
+	}
+	if root, err := ParsePointer(""); err != nil || root.Depth() != 0 {
+		t.Errorf("ParsePointer(\"\") = %v, %v", root, err)
+	}
+	if _, err := ParsePointer("a"); err == nil {
+		t.Errorf("ParsePointer(\"a\") should fail")
+	}
+}
+
+func TestPathOperations(t *testing.T) {
+	root := Path{}
+	users := root.Key("users")
+	user := users.Index(1)
+	name := user.Join(KeySegment("name"))
+
+	if name.Depth() != 3 || name.String() != "$.users[1].name" {
+		t.Errorf("name = %s (depth %d)", name, name.Depth())
+	}
+	if !name.Parent().Equal(user) || !root.Parent().Equal(root) {
+		t.Errorf("Parent() = %s", name.Parent())
+	}
+	if base, ok := name.Base(); !ok || base != KeySegment("name") {
+		t.Errorf("Base() = %v, %v", base, ok)
+	}
+	if _, ok := root.Base(); ok {
+		t.Errorf("root.Base() should not exist")
+	}
+
+	if !root.IsAncestorOf(name) || !users.IsAncestorOf(name) || name.IsAncestorOf(name) || name.IsAncestorOf(users) {
+		t.Errorf("IsAncestorOf() is wrong")
+	}
+	if users.IsAncestorOf(root.Key("users2").Index(0)) {
+		t.Errorf("IsAncestorOf() should compare whole segments")
+	}
+
+	// 从JSON Pointer解析的键与索引相同
+	pointer, _ := ParsePointer("/users/1")
+	if !pointer.Equal(user) || !pointer.IsAncestorOf(name) {
+		t.Errorf("pointer %v should equal %v", pointer, user)
+	}
+
+	// 派生的路径不共享存储
+	a := user.Key("a")
+	b := user.Key("b")
+	if base, _ := a.Base(); base.Key != "a" {
+		t.Errorf("Key() shares storage: %s %s", a, b)
+	}
+}
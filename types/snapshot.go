@@ -0,0 +1,291 @@
+package types
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Snapshot 是对象或数组在某一时刻的只读视图。
+// 创建快照的开销与文档中的容器数量成正比：它遍历整个文档，在两个映射中为每个对象和数组
+// 记录一项当前的内部状态（对象另外分配一个frozenObject），但不复制属性表、元素切片和标量值；
+// 之后原文档的每个容器在第一次被修改时复制自己的内部状态（写时复制，开销与该容器的大小成正比），
+// 因此持有快照的读者看到的内容始终不变，可以在写者继续修改原文档的同时从其他goroutine读取：
+//
+//	snap := doc.Snapshot()
+//	go func() { fmt.Println(snap.Get("user").Get("name")) }()
+//	doc.PutString("status", "updated") // 不影响snap
+//
+// 创建快照时不能有其他goroutine同时修改文档。被快照引用的数组的Values返回元素的副本，
+// 因此通过它修改元素不会影响快照，也不会修改数组。
+// Snapshot是值类型，零值表示null
+type Snapshot struct {
+	state *snapshotState
+	value JSONValue
+}
+
+// snapshotState 保存一次快照中每个容器被冻结的内部状态
+type snapshotState struct {
+	objects map[*JSONObject]*frozenObject
+	arrays  map[*JSONArray][]JSONValue
+}
+
+// frozenObject 是对象被冻结的内部状态。keys中可能有空位，
+// 只有index[keys[i]] == i的位置才是有效的键
+type frozenObject struct {
+	properties map[string]JSONValue
+	keys       []string
+	index      map[string]int
+	fold       map[string]string
+}
+
+// lookup 按JSONObject.lookup的规则查找被冻结的属性
+func (f *frozenObject) lookup(key string) (JSONValue, bool) {
+	if value, ok := f.properties[key]; ok {
+		return value, true
+	}
+	if f.fold != nil {
+		if actual, ok := f.fold[foldKey(key)]; ok {
+			return f.properties[actual], true
+		}
+	}
+	return nil, false
+}
+
+// Snapshot 返回对象当前内容的只读快照
+func (o *JSONObject) Snapshot() Snapshot {
+	return newSnapshot(o)
+}
+
+// Snapshot 返回数组当前内容的只读快照
+func (a *JSONArray) Snapshot() Snapshot {
+	return newSnapshot(a)
+}
+
+// newSnapshot 冻结value中的所有容器并创建快照，需要遍历整个文档
+func newSnapshot(value JSONValue) Snapshot {
+	state := &snapshotState{
+		objects: make(map[*JSONObject]*frozenObject),
+		arrays:  make(map[*JSONArray][]JSONValue),
+	}
+	state.freeze(value)
+	return Snapshot{state: state, value: value}
+}
+
+// freeze 记录v及其嵌套容器的内部状态并把它们标记为共享，已经记录过的容器不再访问
+func (s *snapshotState) freeze(v JSONValue) {
+	switch val := v.(type) {
+	case *JSONObject:
+		if val == nil || s.objects[val] != nil {
+			return
+		}
+		s.objects[val] = &frozenObject{properties: val.properties, keys: val.keys, index: val.index, fold: val.fold}
+		val.shared = true
+		for _, child := range val.properties {
+			s.freeze(child)
+		}
+	case *JSONArray:
+		if val == nil {
+			return
+		}
+		if _, ok := s.arrays[val]; ok {
+			return
+		}
+		s.arrays[val] = val.elements
+		val.shared = true
+		for _, child := range val.elements {
+			s.freeze(child)
+		}
+	}
+}
+
+// child 返回快照中的子值，nil被视为null
+func (s Snapshot) child(v JSONValue) Snapshot {
+	if v == nil {
+		v = NewJSONNull()
+	}
+	return Snapshot{state: s.state, value: v}
+}
+
+// object 返回快照对应的被冻结的对象，不是对象时返回nil
+func (s Snapshot) object() *frozenObject {
+	if obj, ok := s.value.(*JSONObject); ok && obj != nil {
+		return s.state.objects[obj]
+	}
+	return nil
+}
+
+// array 返回快照对应的被冻结的数组元素，不是数组时第二个返回值为false
+func (s Snapshot) array() ([]JSONValue, bool) {
+	if arr, ok := s.value.(*JSONArray); ok && arr != nil {
+		elements, ok := s.state.arrays[arr]
+		return elements, ok
+	}
+	return nil, false
+}
+
+// Type 返回快照中值的类型
+func (s Snapshot) Type() string {
+	if s.value == nil {
+		return "null"
+	}
+	return s.value.Type()
+}
+
+// IsObject 检查快照中的值是否是对象
+func (s Snapshot) IsObject() bool {
+	return s.object() != nil
+}
+
+// IsArray 检查快照中的值是否是数组
+func (s Snapshot) IsArray() bool {
+	_, ok := s.array()
+	return ok
+}
+
+// Get 返回对象属性key的快照，不是对象或属性不存在时返回null的快照
+func (s Snapshot) Get(key string) Snapshot {
+	obj := s.object()
+	if obj == nil {
+		return s.child(nil)
+	}
+	value, _ := obj.lookup(key)
+	return s.child(value)
+}
+
+// Has 检查对象是否包含属性key
+func (s Snapshot) Has(key string) bool {
+	obj := s.object()
+	if obj == nil {
+		return false
+	}
+	_, ok := obj.lookup(key)
+	return ok
+}
+
+// Keys 按插入顺序返回对象的所有键，不是对象时返回nil
+func (s Snapshot) Keys() []string {
+	obj := s.object()
+	if obj == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(obj.properties))
+	for i, key := range obj.keys {
+		if pos, ok := obj.index[key]; ok && pos == i {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Index 返回数组第i个元素的快照，不是数组或i超出范围时返回null的快照
+func (s Snapshot) Index(i int) Snapshot {
+	elements, _ := s.array()
+	if i < 0 || i >= len(elements) {
+		return s.child(nil)
+	}
+	return s.child(elements[i])
+}
+
+// Size 返回对象的属性数量或数组的元素数量，其他值返回0
+func (s Snapshot) Size() int {
+	if obj := s.object(); obj != nil {
+		return len(obj.properties)
+	}
+	elements, _ := s.array()
+	return len(elements)
+}
+
+// ForEach 按插入顺序遍历对象的属性，或按顺序遍历数组的元素（key为空字符串）
+func (s Snapshot) ForEach(fn func(key string, value Snapshot)) {
+	if obj := s.object(); obj != nil {
+		for _, key := range s.Keys() {
+			fn(key, s.child(obj.properties[key]))
+		}
+		return
+	}
+	elements, _ := s.array()
+	for _, element := range elements {
+		fn("", s.child(element))
+	}
+}
+
+// Value 返回快照内容的可修改副本。标量值不可变，直接返回；对象和数组被深拷贝
+func (s Snapshot) Value() JSONValue {
+	if s.value == nil {
+		return NewJSONNull()
+	}
+	if obj := s.object(); obj != nil {
+		result := NewJSONObject().SetCaseInsensitive(obj.fold != nil)
+		s.ForEach(func(key string, value Snapshot) {
+			result.Put(key, value.Value())
+		})
+		return result
+	}
+	if elements, ok := s.array(); ok {
+		values := make([]JSONValue, len(elements))
+		for i, element := range elements {
+			values[i] = s.child(element).Value()
+		}
+		return NewJSONArrayFromValuesUnsafe(values)
+	}
+	return s.value
+}
+
+// String 返回快照内容的JSON字符串表示，与原文档在创建快照时的String相同
+func (s Snapshot) String() string {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		if s.IsArray() {
+			return "[]"
+		}
+		return "{}"
+	}
+	return string(data)
+}
+
+// MarshalJSON 实现json.Marshaler接口，输出与JSONObject、JSONArray的MarshalJSON相同
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write 按writeValue的规则写入快照的内容
+func (s Snapshot) write(buf *bytes.Buffer) error {
+	if obj := s.object(); obj != nil {
+		keys := make([]string, 0, len(obj.properties))
+		for key := range obj.properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, key)
+			buf.WriteByte(':')
+			if err := s.child(obj.properties[key]).write(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	if elements, ok := s.array(); ok {
+		buf.WriteByte('[')
+		for i, element := range elements {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := s.child(element).write(buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	return writeValue(buf, s.value)
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func snapshotDoc() *JSONObject {
+	return NewJSONObject().
+		PutString("name", "gojson").
+		Put("tags", NewJSONArray().AddString("a").AddString("b").AddString("c")).
+		Put("owner", NewJSONObject().PutString("login", "lee").PutNumber("id", 7))
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	doc := snapshotDoc()
+	want := doc.String()
+	snap := doc.Snapshot()
+
+	doc.PutString("name", "changed").PutBoolean("extra", true).Remove("owner")
+	tags, _ := doc.GetArray("tags")
+	tags.SetString(0, "x").AddString("d").Remove(1)
+	owner, _ := snap.Get("owner").Value().AsObject()
+	owner.PutString("login", "copy")
+
+	if got := snap.String(); got != want {
+		t.Errorf("Snapshot.String() = %s, want %s", got, want)
+	}
+	if got := snap.Keys(); !reflect.DeepEqual(got, []string{"name", "tags", "owner"}) {
+		t.Errorf("Snapshot.Keys() = %v", got)
+	}
+	if snap.Has("extra") || !snap.Has("owner") || snap.Size() != 3 {
+		t.Errorf("Snapshot sees the changes: Has(extra)=%v Has(owner)=%v Size()=%d", snap.Has("extra"), snap.Has("owner"), snap.Size())
+	}
+	if got := snap.Get("tags").Index(0).Value(); got.String() != `"a"` {
+		t.Errorf("Snapshot tags[0] = %s, want \"a\"", got.String())
+	}
+	if doc.String() != `{"extra":true,"name":"changed","tags":["x","c","d"]}` {
+		t.Errorf("doc = %s", doc.String())
+	}
+}
+
+func TestSnapshotNestedWrite(t *testing.T) {
+	doc := snapshotDoc()
+	snap := doc.Snapshot()
+	owner, _ := doc.GetObject("owner")
+	owner.PutNumber("id", 8).InsertAt(0, "first", NewJSONNull())
+
+	if got := snap.Get("owner").String(); got != `{"id":7,"login":"lee"}` {
+		t.Errorf("Snapshot owner = %s", got)
+	}
+	if got := owner.Keys(); !reflect.DeepEqual(got, []string{"first", "login", "id"}) {
+		t.Errorf("owner.Keys() = %v", got)
+	}
+}
+
+func TestSnapshotArrayValues(t *testing.T) {
+	doc := snapshotDoc()
+	tags, _ := doc.GetArray("tags")
+	snap := doc.Snapshot()
+
+	// 被快照引用的数组返回副本，修改副本不影响快照和数组
+	values := tags.Values()
+	values[0] = NewJSONString("x")
+	if got := snap.Get("tags").Index(0).String(); got != `"a"` {
+		t.Errorf("Snapshot tags[0] = %s, want \"a\"", got)
+	}
+	if got := tags.Get(0).String(); got != `"a"` {
+		t.Errorf("tags[0] = %s, want \"a\"", got)
+	}
+
+	// 写时复制之后Values重新返回实时的底层切片
+	tags.SetString(1, "y")
+	tags.Values()[0] = NewJSONString("z")
+	if got := tags.String(); got != `["z","y","c"]` {
+		t.Errorf("tags = %s", got)
+	}
+	if got := snap.Get("tags").String(); got != `["a","b","c"]` {
+		t.Errorf("Snapshot tags = %s", got)
+	}
+}
+
+func TestSnapshotHoles(t *testing.T) {
+	doc := NewJSONObject()
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		doc.PutNumber(key, 1)
+	}
+	doc.Remove("b")
+	snap := doc.Snapshot()
+
+	// 之后的删除和Keys中的压缩不能改写快照中的keys
+	doc.Remove("a").Remove("c").Remove("d")
+	if got := doc.Keys(); !reflect.DeepEqual(got, []string{"e"}) {
+		t.Errorf("doc.Keys() = %v", got)
+	}
+	if got := snap.Keys(); !reflect.DeepEqual(got, []string{"a", "c", "d", "e"}) {
+		t.Errorf("Snapshot.Keys() = %v", got)
+	}
+	var visited []string
+	snap.ForEach(func(key string, value Snapshot) { visited = append(visited, key) })
+	if !reflect.DeepEqual(visited, []string{"a", "c", "d", "e"}) {
+		t.Errorf("Snapshot.ForEach() visited %v", visited)
+	}
+}
+
+func TestSnapshotMissing(t *testing.T) {
+	snap := snapshotDoc().Snapshot()
+	for name, s := range map[string]Snapshot{
+		"missing key":   snap.Get("none"),
+		"index on obj":  snap.Index(0),
+		"out of range":  snap.Get("tags").Index(3),
+		"key on scalar": snap.Get("name").Get("x"),
+		"zero value":    {},
+	} {
+		if s.Type() != "null" || s.Size() != 0 || s.Keys() != nil || !s.Value().IsNull() {
+			t.Errorf("%s: got %s (%s)", name, s.String(), s.Type())
+		}
+	}
+	if !snap.IsObject() || !snap.Get("tags").IsArray() || snap.Get("tags").Size() != 3 {
+		t.Errorf("Snapshot types are wrong")
+	}
+}
+
+func TestSnapshotCycle(t *testing.T) {
+	arr := NewJSONArray()
+	obj := NewJSONObject().Put("self", arr)
+	arr.Add(obj)
+	snap := obj.Snapshot()
+	arr.AddNumber(1)
+	if got := snap.Get("self").Size(); got != 1 {
+		t.Errorf("Snapshot self.Size() = %d, want 1", got)
+	}
+}
+
+func TestSnapshotConcurrentRead(t *testing.T) {
+	doc := snapshotDoc()
+	snap := doc.Snapshot()
+	want := snap.String()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if got := snap.String(); got != want {
+					t.Errorf("Snapshot.String() = %s, want %s", got, want)
+					return
+				}
+			}
+		}()
+	}
+	tags, _ := doc.GetArray("tags")
+	for j := 0; j < 200; j++ {
+		doc.PutNumber("counter", float64(j))
+		tags.AddNumber(float64(j))
+	}
+	wg.Wait()
+}
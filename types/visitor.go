@@ -0,0 +1,133 @@
+package types
+
+import (
+	stderrors "errors"
+
+	"github.com/UserLeeZJ/gojson/errors"
+)
+
+// Visitor 访问JSON值树中的每个节点。
+// 对象按键的顺序依次调用VisitKey和对应值的访问方法，
+// 数组按索引顺序访问元素。任一方法返回错误时遍历立即停止，
+// VisitObjectStart或VisitArrayStart返回SkipChildren时跳过该容器的内容。
+type Visitor interface {
+	VisitNull() error
+	VisitBool(value bool) error
+	VisitNumber(value float64) error
+	VisitString(value string) error
+	VisitObjectStart(size int) error
+	VisitKey(key string) error
+	VisitObjectEnd() error
+	VisitArrayStart(size int) error
+	VisitArrayEnd() error
+}
+
+// SkipChildren 由VisitObjectStart或VisitArrayStart返回，表示跳过容器的内容。
+// 被跳过的容器不会调用对应的End方法。
+var SkipChildren = stderrors.New("skip children")
+
+// BaseVisitor 是所有方法都不做任何事的Visitor，可以嵌入以只实现需要的方法。
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitNull() error                { return nil }
+func (BaseVisitor) VisitBool(value bool) error      { return nil }
+func (BaseVisitor) VisitNumber(value float64) error { return nil }
+func (BaseVisitor) VisitString(value string) error  { return nil }
+func (BaseVisitor) VisitObjectStart(size int) error { return nil }
+func (BaseVisitor) VisitKey(key string) error       { return nil }
+func (BaseVisitor) VisitObjectEnd() error           { return nil }
+func (BaseVisitor) VisitArrayStart(size int) error  { return nil }
+func (BaseVisitor) VisitArrayEnd() error            { return nil }
+
+// Accept 使用visitor遍历value。nil值按null处理。
+func Accept(value JSONValue, visitor Visitor) error {
+	switch v := value.(type) {
+	case nil:
+		return visitor.VisitNull()
+	case *JSONNull:
+		return v.Accept(visitor)
+	case *JSONBool:
+		return v.Accept(visitor)
+	case *JSONNumber:
+		return v.Accept(visitor)
+	case *JSONBigInt:
+		return v.Accept(visitor)
+	case *JSONDecimal:
+		return v.Accept(visitor)
+	case *JSONString:
+		return v.Accept(visitor)
+	case *JSONObject:
+		return v.Accept(visitor)
+	case *JSONArray:
+		return v.Accept(visitor)
+	default:
+		return errors.NewJSONError(errors.ErrNotSupported, "不支持的JSON值类型: "+value.Type())
+	}
+}
+
+// Accept 使用visitor访问null值。
+func (n *JSONNull) Accept(visitor Visitor) error {
+	return visitor.VisitNull()
+}
+
+// Accept 使用visitor访问布尔值。
+func (b *JSONBool) Accept(visitor Visitor) error {
+	return visitor.VisitBool(b.value)
+}
+
+// Accept 使用visitor访问数字值。
+func (n *JSONNumber) Accept(visitor Visitor) error {
+	return visitor.VisitNumber(n.value)
+}
+
+// Accept 使用visitor访问任意精度整数，传给VisitNumber的是最接近的float64。
+func (b *JSONBigInt) Accept(visitor Visitor) error {
+	f, _ := b.AsNumber()
+	return visitor.VisitNumber(f)
+}
+
+// Accept 使用visitor访问定点小数，传给VisitNumber的是最接近的float64。
+func (d *JSONDecimal) Accept(visitor Visitor) error {
+	f, _ := d.AsNumber()
+	return visitor.VisitNumber(f)
+}
+
+// Accept 使用visitor访问字符串值。
+func (s *JSONString) Accept(visitor Visitor) error {
+	return visitor.VisitString(s.value)
+}
+
+// Accept 使用visitor按键的顺序遍历对象。
+func (o *JSONObject) Accept(visitor Visitor) error {
+	if err := visitor.VisitObjectStart(o.Size()); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	for _, key := range o.Keys() {
+		if err := visitor.VisitKey(key); err != nil {
+			return err
+		}
+		if err := Accept(o.properties[key], visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitObjectEnd()
+}
+
+// Accept 使用visitor按索引顺序遍历数组。
+func (a *JSONArray) Accept(visitor Visitor) error {
+	if err := visitor.VisitArrayStart(len(a.elements)); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	for _, element := range a.elements {
+		if err := Accept(element, visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitArrayEnd()
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingVisitor 记录每次访问的事件
+type recordingVisitor struct {
+	events []string
+	skip   string
+}
+
+func (r *recordingVisitor) record(format string, args ...interface{}) {
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingVisitor) VisitNull() error                { r.record("null"); return nil }
+func (r *recordingVisitor) VisitBool(value bool) error      { r.record("bool:%v", value); return nil }
+func (r *recordingVisitor) VisitNumber(value float64) error { r.record("number:%v", value); return nil }
+func (r *recordingVisitor) VisitString(value string) error  { r.record("string:%s", value); return nil }
+func (r *recordingVisitor) VisitKey(key string) error       { r.record("key:%s", key); return nil }
+func (r *recordingVisitor) VisitObjectEnd() error           { r.record("}"); return nil }
+func (r *recordingVisitor) VisitArrayEnd() error            { r.record("]"); return nil }
+
+func (r *recordingVisitor) VisitObjectStart(size int) error {
+	r.record("{%d", size)
+	if r.skip == "object" {
+		return SkipChildren
+	}
+	return nil
+}
+
+func (r *recordingVisitor) VisitArrayStart(size int) error {
+	r.record("[%d", size)
+	if r.skip == "array" {
+		return SkipChildren
+	}
+	return nil
+}
+
+// stringCounter 只统计字符串节点
+type stringCounter struct {
+	BaseVisitor
+	count int
+}
+
+func (c *stringCounter) VisitString(value string) error {
+	c.count++
+	return nil
+}
+
+func TestAccept(t *testing.T) {
+	obj := NewJSONObject()
+	obj.PutString("name", "John")
+	obj.PutArray("tags", NewJSONArray().AddString("a").AddNumber(1).AddNull())
+	obj.PutBoolean("active", true)
+
+	tests := []struct {
+		skip string
+		want string
+	}{
+		{"", "{3 key:name string:John key:tags [3 string:a number:1 null ] key:active bool:true }"},
+		{"array", "{3 key:name string:John key:tags [3 key:active bool:true }"},
+		{"object", "{3"},
+	}
+	for _, tt := range tests {
+		r := &recordingVisitor{skip: tt.skip}
+		if err := Accept(obj, r); err != nil {
+			t.Fatalf("Accept() error = %v", err)
+		}
+		if got := strings.Join(r.events, " "); got != tt.want {
+			t.Errorf("Accept() skip=%q events = %s, want %s", tt.skip, got, tt.want)
+		}
+	}
+
+	counter := &stringCounter{}
+	if err := obj.Accept(counter); err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	if counter.count != 2 {
+		t.Errorf("string count = %d, want 2", counter.count)
+	}
+}
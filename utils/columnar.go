@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ToColumnar 把对象数组转换为列式布局：每个字段对应一个等长的数组
+// 字段按第一次出现的顺序排列，元素缺少的字段用null填充，
+// 例如[{"a":1},{"a":2,"b":3}]转换为{"a":[1,2],"b":[null,3]}
+// 数组中有不是对象的元素时返回错误
+func ToColumnar(arr *types.JSONArray) (*types.JSONObject, error) {
+	rows := arr.Values()
+	result := types.NewJSONObject()
+	columns := make(map[string][]types.JSONValue)
+
+	for i, row := range rows {
+		if row == nil || !row.IsObject() {
+			actual := "null"
+			if row != nil {
+				actual = row.Type()
+			}
+			return nil, jsonerrors.ErrInvalidTypeWithDetails("object", actual).WithPath("$[" + strconv.Itoa(i) + "]")
+		}
+		obj, _ := row.AsObject()
+		for _, key := range obj.Keys() {
+			column, ok := columns[key]
+			if !ok {
+				column = make([]types.JSONValue, len(rows))
+				columns[key] = column
+				result.Put(key, nil)
+			}
+			column[i] = obj.Get(key)
+		}
+	}
+
+	for key, column := range columns {
+		for i, value := range column {
+			if value == nil {
+				column[i] = types.NewJSONNull()
+			}
+		}
+		result.Put(key, types.NewJSONArrayFromValuesUnsafe(column))
+	}
+	return result, nil
+}
+
+// FromColumnar 把列式布局转换回对象数组，是ToColumnar的逆操作
+// 行数取最长的列，较短的列缺少的值用null填充；
+// 由于缺失和null无法区分，转换结果中每个元素都包含所有字段
+// 有不是数组的列时返回错误
+func FromColumnar(obj *types.JSONObject) (*types.JSONArray, error) {
+	keys := obj.Keys()
+	columns := make([][]types.JSONValue, len(keys))
+	rows := 0
+	for i, key := range keys {
+		value := obj.Get(key)
+		if value == nil || !value.IsArray() {
+			actual := "null"
+			if value != nil {
+				actual = value.Type()
+			}
+			return nil, jsonerrors.ErrInvalidTypeWithDetails("array", actual).WithPath(fmt.Sprintf("$['%s']", key))
+		}
+		column, _ := value.AsArray()
+		columns[i] = column.Values()
+		if len(columns[i]) > rows {
+			rows = len(columns[i])
+		}
+	}
+
+	result := make([]types.JSONValue, rows)
+	for r := range result {
+		record := types.NewJSONObject()
+		for i, key := range keys {
+			if r < len(columns[i]) && columns[i][r] != nil {
+				record.Put(key, columns[i][r])
+			} else {
+				record.Put(key, types.NewJSONNull())
+			}
+		}
+		result[r] = record
+	}
+	return types.NewJSONArrayFromValuesUnsafe(result), nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestToColumnar(t *testing.T) {
+	value, err := parser.ParseToValue(`[{"b": 1, "a": "x"}, {"a": "y"}, {"c": true, "b": 3}]`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	arr, _ := value.AsArray()
+
+	columnar, err := ToColumnar(arr)
+	if err != nil {
+		t.Fatalf("ToColumnar() error = %v", err)
+	}
+	want := `{"a":["x","y",null],"b":[1,null,3],"c":[null,null,true]}`
+	if columnar.String() != want {
+		t.Errorf("ToColumnar() = %v, want %v", columnar.String(), want)
+	}
+
+	back, err := FromColumnar(columnar)
+	if err != nil {
+		t.Fatalf("FromColumnar() error = %v", err)
+	}
+	want = `[{"a":"x","b":1,"c":null},{"a":"y","b":null,"c":null},{"a":null,"b":3,"c":true}]`
+	if back.String() != want {
+		t.Errorf("FromColumnar() = %v, want %v", back.String(), want)
+	}
+}
+
+func TestColumnarKeyOrder(t *testing.T) {
+	value, _ := parser.ParseToValue(`[{"z": 1}, {"y": 2, "z": 3}]`)
+	arr, _ := value.AsArray()
+	columnar, err := ToColumnar(arr)
+	if err != nil {
+		t.Fatalf("ToColumnar() error = %v", err)
+	}
+	if got := columnar.Keys(); !reflect.DeepEqual(got, []string{"z", "y"}) {
+		t.Errorf("ToColumnar() keys = %v, want [z y]", got)
+	}
+}
+
+func TestColumnarErrors(t *testing.T) {
+	value, _ := parser.ParseToValue(`[{"a": 1}, 2]`)
+	arr, _ := value.AsArray()
+	if _, err := ToColumnar(arr); err == nil {
+		t.Error("非对象元素应该返回错误")
+	}
+
+	value, _ = parser.ParseToValue(`{"a": [1, 2], "b": [3]}`)
+	obj, _ := value.AsObject()
+	back, err := FromColumnar(obj)
+	if err != nil {
+		t.Fatalf("FromColumnar() error = %v", err)
+	}
+	if want := `[{"a":1,"b":3},{"a":2,"b":null}]`; back.String() != want {
+		t.Errorf("FromColumnar() = %v, want %v", back.String(), want)
+	}
+
+	value, _ = parser.ParseToValue(`{"a": [1], "b": 2}`)
+	obj, _ = value.AsObject()
+	if _, err := FromColumnar(obj); err == nil {
+		t.Error("非数组的列应该返回错误")
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"hash/fnv"
+
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// DedupKeep 表示去重时保留重复元素中的哪一个
+type DedupKeep int
+
+const (
+	// KeepFirst 保留第一次出现的元素
+	KeepFirst DedupKeep = iota
+	// KeepLast 保留最后一次出现的元素
+	KeepLast
+)
+
+// Deduper 根据元素内容的哈希判断元素是否重复，只保存哈希而不保存元素本身
+// byPath为空时比较整个元素，否则比较元素中JSON Path所选的值；
+// 比较前会规范化对象键的顺序，因此键顺序不同但内容相同的对象视为重复
+type Deduper struct {
+	path *jsonpath.JSONPath
+	seen map[[16]byte]struct{}
+}
+
+// NewDeduper 创建一个新的Deduper，byPath以元素为根，例如"$.id"
+func NewDeduper(byPath string) (*Deduper, error) {
+	d := &Deduper{seen: make(map[[16]byte]struct{})}
+	if byPath != "" {
+		jp, err := jsonpath.ParseJSONPath(byPath)
+		if err != nil {
+			return nil, err
+		}
+		d.path = jp
+	}
+	return d, nil
+}
+
+// Key 返回元素的去重键，路径没有匹配值时ok为false
+func (d *Deduper) Key(value types.JSONValue) (key [16]byte, ok bool) {
+	if d.path != nil {
+		if value = sortKey(d.path, value); value == nil {
+			return key, false
+		}
+	}
+	normalized := Normalize(value, NormalizeOptions{SortObjectKeys: true})
+	h := fnv.New128a()
+	h.Write([]byte(normalized.String()))
+	h.Sum(key[:0])
+	return key, true
+}
+
+// IsDuplicate 检查元素是否与之前检查过的元素重复，并记录该元素
+// 路径没有匹配值的元素总是视为不重复
+func (d *Deduper) IsDuplicate(value types.JSONValue) bool {
+	key, ok := d.Key(value)
+	if !ok {
+		return false
+	}
+	if _, exists := d.seen[key]; exists {
+		return true
+	}
+	d.seen[key] = struct{}{}
+	return false
+}
+
+// DedupArray 返回去除重复元素后的新数组，重复的元素只保留第一次出现的那个
+// byPath为空时按整个元素去重，否则按元素中JSON Path所选的值去重
+func DedupArray(arr *types.JSONArray, byPath string) (*types.JSONArray, error) {
+	return DedupArrayKeep(arr, byPath, KeepFirst)
+}
+
+// DedupArrayKeep 返回去除重复元素后的新数组，keep指定保留重复元素中的哪一个
+// 结果中的元素保持它们在原数组中的相对顺序，原数组不会被修改
+func DedupArrayKeep(arr *types.JSONArray, byPath string, keep DedupKeep) (*types.JSONArray, error) {
+	d, err := NewDeduper(byPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.JSONValue, 0, arr.Size())
+	if keep == KeepFirst {
+		for _, value := range arr.Values() {
+			if !d.IsDuplicate(value) {
+				result = append(result, value)
+			}
+		}
+		return types.NewJSONArrayFromValuesUnsafe(result), nil
+	}
+
+	// 先记录每个键最后出现的位置，再按位置输出
+	last := make(map[[16]byte]int)
+	for i, value := range arr.Values() {
+		if key, ok := d.Key(value); ok {
+			last[key] = i
+		}
+	}
+	for i, value := range arr.Values() {
+		if key, ok := d.Key(value); !ok || last[key] == i {
+			result = append(result, value)
+		}
+	}
+	return types.NewJSONArrayFromValuesUnsafe(result), nil
+}
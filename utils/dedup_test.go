@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestDedupArray(t *testing.T) {
+	value, err := parser.ParseToValue(`[
+		{"id": 1, "v": "a"},
+		{"id": 2, "v": "b"},
+		{"v": "a", "id": 1},
+		{"id": 1, "v": "c"},
+		{"v": "x"},
+		{"v": "x"}
+	]`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	arr, _ := value.AsArray()
+	original := arr.String()
+
+	tests := []struct {
+		name   string
+		byPath string
+		keep   DedupKeep
+		want   string
+	}{
+		{"整个元素保留第一个", "", KeepFirst,
+			`[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":1,"v":"c"},{"v":"x"}]`},
+		{"整个元素保留最后一个", "", KeepLast,
+			`[{"id":2,"v":"b"},{"id":1,"v":"a"},{"id":1,"v":"c"},{"v":"x"}]`},
+		{"按键保留第一个", "$.id", KeepFirst,
+			`[{"id":1,"v":"a"},{"id":2,"v":"b"},{"v":"x"},{"v":"x"}]`},
+		{"按键保留最后一个", "$.id", KeepLast,
+			`[{"id":2,"v":"b"},{"id":1,"v":"c"},{"v":"x"},{"v":"x"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DedupArrayKeep(arr, tt.byPath, tt.keep)
+			if err != nil {
+				t.Fatalf("DedupArrayKeep() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("DedupArrayKeep() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+
+	if arr.String() != original {
+		t.Error("DedupArrayKeep() 不应修改原数组")
+	}
+	if _, err := DedupArray(arr, "id"); err == nil {
+		t.Error("无效路径应该返回错误")
+	}
+}
@@ -10,6 +10,16 @@ import (
 
 // FormatJSON 格式化JSON字符串。
 func FormatJSON(jsonStr string, indent string, sortKeys bool) (string, error) {
+	options := PrettyOptions{
+		Indent:     indent,
+		SortKeys:   sortKeys,
+		EscapeHTML: false,
+	}
+	return FormatJSONWithOptions(jsonStr, options)
+}
+
+// FormatJSONWithOptions 按美化选项格式化JSON字符串。
+func FormatJSONWithOptions(jsonStr string, options PrettyOptions) (string, error) {
 	// 解析JSON
 	jsonValue, err := parser.ParseToValue(jsonStr)
 	if err != nil {
@@ -17,11 +27,6 @@ func FormatJSON(jsonStr string, indent string, sortKeys bool) (string, error) {
 	}
 
 	// 使用PrettyPrint格式化
-	options := PrettyOptions{
-		Indent:     indent,
-		SortKeys:   sortKeys,
-		EscapeHTML: false,
-	}
 	return PrettyPrint(jsonValue, options)
 }
 
@@ -37,7 +42,7 @@ func CompactJSON(jsonStr string) (string, error) {
 	return CompressJSON(jsonValue)
 }
 
-// SortJSONKeys 对JSON对象的键进行排序。
+// SortJSONKeys 递归地对JSON中所有对象的键进行排序，包括数组中的对象。
 func SortJSONKeys(jsonStr string) (string, error) {
 	// 解析JSON
 	jsonValue, err := parser.ParseToValue(jsonStr)
@@ -45,11 +50,6 @@ func SortJSONKeys(jsonStr string) (string, error) {
 		return "", err
 	}
 
-	// 如果不是对象，直接返回
-	if !jsonValue.IsObject() {
-		return jsonValue.String(), nil
-	}
-
 	// 使用sortMapKeys排序键
 	native := types.ValueToInterface(jsonValue)
 	sorted := sortMapKeys(native)
@@ -63,12 +63,49 @@ func SortJSONKeys(jsonStr string) (string, error) {
 	return string(bytes), nil
 }
 
+// SortKeys 返回JSON值的副本，其中所有对象的键按字典序插入，
+// 副本的String方法因此按排序后的顺序输出。
+func SortKeys(value types.JSONValue) types.JSONValue {
+	return SortKeysBy(value, nil)
+}
+
+// SortKeysBy 返回JSON值的副本，其中所有对象的键按order的顺序插入，order为nil时与SortKeys相同。
+// 副本的Keys、ForEach等按插入顺序访问的方法因此按order的顺序返回键
+func SortKeysBy(value types.JSONValue, order types.KeyOrder) types.JSONValue {
+	switch {
+	case value == nil:
+		return types.NewJSONNull()
+	case value.IsObject():
+		obj, _ := value.AsObject()
+		result := types.NewJSONObject()
+		for _, key := range obj.SortedKeysBy(order) {
+			result.Put(key, SortKeysBy(obj.Get(key), order))
+		}
+		return result
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		result := types.NewJSONArray()
+		for i := 0; i < arr.Size(); i++ {
+			result.Add(SortKeysBy(arr.Get(i), order))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
 // ValidateJSON 验证JSON字符串是否有效。
 func ValidateJSON(jsonStr string) error {
 	_, err := parser.ParseToValue(jsonStr)
 	return err
 }
 
+// ValidateJSONBytes 验证JSON字节是否有效。
+func ValidateJSONBytes(data []byte) error {
+	_, err := parser.ParseBytesToValue(data)
+	return err
+}
+
 // MergeJSON 合并两个JSON对象。
 func MergeJSON(target, source string) (string, error) {
 	// 解析目标JSON
@@ -83,25 +120,36 @@ func MergeJSON(target, source string) (string, error) {
 		return "", err
 	}
 
+	// 合并对象
+	result, err := MergeValues(targetValue, sourceValue)
+	if err != nil {
+		return "", err
+	}
+
+	// 转换为字符串
+	return result.String(), nil
+}
+
+// MergeValues 深度合并两个JSON对象，源对象的值覆盖目标对象中同名的值，
+// 两边都是对象的值递归合并。输入的值不会被修改，但结果会与输入共享没有合并的子值，
+// 需要独立修改结果时先使用DeepCopy。
+func MergeValues(target, source types.JSONValue) (types.JSONValue, error) {
 	// 如果目标不是对象，返回错误
-	if !targetValue.IsObject() {
-		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "目标JSON不是对象")
+	if target == nil || !target.IsObject() {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "目标JSON不是对象")
 	}
 
 	// 如果源不是对象，返回错误
-	if !sourceValue.IsObject() {
-		return "", jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "源JSON不是对象")
+	if source == nil || !source.IsObject() {
+		return nil, jsonerrors.NewJSONError(jsonerrors.ErrInvalidType, "源JSON不是对象")
 	}
 
 	// 获取对象
-	targetObj, _ := targetValue.AsObject()
-	sourceObj, _ := sourceValue.AsObject()
+	targetObj, _ := target.AsObject()
+	sourceObj, _ := source.AsObject()
 
 	// 合并对象
-	result := mergeObjects(targetObj, sourceObj)
-
-	// 转换为字符串
-	return result.String(), nil
+	return mergeObjects(targetObj, sourceObj), nil
 }
 
 // mergeObjects 合并两个JSONObject
@@ -137,36 +185,17 @@ func mergeObjects(target, source *types.JSONObject) *types.JSONObject {
 }
 
 // DeepCopy 深度复制JSON值。
+// 值中存在循环引用时会panic，不能确定输入是否安全时使用DeepCopyChecked。
 func DeepCopy(value types.JSONValue) types.JSONValue {
-	if value == nil || value.IsNull() {
-		return types.NewJSONNull()
+	result, err := DeepCopyChecked(value)
+	if err != nil {
+		panic(err)
 	}
+	return result
+}
 
-	switch {
-	case value.IsObject():
-		obj, _ := value.AsObject()
-		result := types.NewJSONObject()
-		for _, key := range obj.Keys() {
-			result.Put(key, DeepCopy(obj.Get(key)))
-		}
-		return result
-	case value.IsArray():
-		arr, _ := value.AsArray()
-		result := types.NewJSONArray()
-		for i := 0; i < arr.Size(); i++ {
-			result.Add(DeepCopy(arr.Get(i)))
-		}
-		return result
-	case value.IsString():
-		str, _ := value.AsString()
-		return types.NewJSONString(str)
-	case value.IsNumber():
-		num, _ := value.AsNumber()
-		return types.NewJSONNumber(num)
-	case value.IsBoolean():
-		b, _ := value.AsBoolean()
-		return types.NewJSONBool(b)
-	default:
-		return types.NewJSONNull()
-	}
+// DeepCopyChecked 深度复制JSON值，容器包含它自身时返回循环引用错误。
+// 同一个容器在不相交的位置出现多次不是循环，每处都会复制为独立的值。
+func DeepCopyChecked(value types.JSONValue) (types.JSONValue, error) {
+	return types.DeepCopyValue(value)
 }
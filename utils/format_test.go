@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestFormatJSON(t *testing.T) {
+	got, err := FormatJSON(`{"b":1,"a":[1,2]}`, "\t", true)
+	if err != nil {
+		t.Fatalf("FormatJSON() error = %v", err)
+	}
+	want := "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t],\n\t\"b\": 1\n}"
+	if got != want {
+		t.Errorf("FormatJSON() = %q, want %q", got, want)
+	}
+
+	got, err = FormatJSONWithOptions(`{"html":"<b>"}`, PrettyOptions{Indent: " ", EscapeHTML: true})
+	if err != nil || !strings.Contains(got, `\u003cb\u003e`) {
+		t.Errorf("FormatJSONWithOptions() with EscapeHTML = %q, %v", got, err)
+	}
+
+	if _, err := FormatJSON(`{`, "  ", false); err == nil {
+		t.Errorf("FormatJSON() with invalid JSON should fail")
+	}
+}
+
+func TestCompactJSON(t *testing.T) {
+	got, err := CompactJSON("{\n  \"a\": [1, 2],\n  \"b\": null\n}")
+	if err != nil || got != `{"a":[1,2],"b":null}` {
+		t.Errorf("CompactJSON() = %q, %v", got, err)
+	}
+	if _, err := CompactJSON(`[1,`); err == nil {
+		t.Errorf("CompactJSON() with invalid JSON should fail")
+	}
+}
+
+func TestSortJSONKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"b":1,"a":{"d":2,"c":3}}`, `{"a":{"c":3,"d":2},"b":1}`},
+		{`[{"b":1,"a":2},3]`, `[{"a":2,"b":1},3]`},
+		{`"text"`, `"text"`},
+	}
+	for _, tt := range tests {
+		got, err := SortJSONKeys(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("SortJSONKeys(%s) = %s, %v, want %s", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	obj := types.NewJSONObject()
+	obj.PutNumber("b", 1)
+	inner := types.NewJSONObject()
+	inner.PutNumber("z", 1)
+	inner.PutNumber("y", 2)
+	arr := types.NewJSONArray()
+	arr.Add(inner)
+	obj.PutArray("a", arr)
+
+	sorted := SortKeys(obj)
+	if got := sorted.String(); got != `{"a":[{"y":2,"z":1}],"b":1}` {
+		t.Errorf("SortKeys().String() = %s", got)
+	}
+	if keys := obj.Keys(); keys[0] != "b" {
+		t.Errorf("SortKeys() should not modify the input, keys = %v", keys)
+	}
+}
+
+func TestSortKeysBy(t *testing.T) {
+	obj := types.NewJSONObject().PutNumber("item10", 1).PutNumber("Item2", 2).
+		Put("nested", types.NewJSONArray().Add(types.NewJSONObject().PutNumber("b", 1).PutNumber("A", 2)))
+
+	sorted, _ := SortKeysBy(obj, types.NaturalOrder).AsObject()
+	if keys := sorted.Keys(); strings.Join(keys, ",") != "Item2,item10,nested" {
+		t.Errorf("SortKeysBy(NaturalOrder) keys = %v", keys)
+	}
+
+	pretty, err := PrettyPrint(obj, PrettyOptions{SortKeys: true, KeyOrder: types.CaseInsensitiveOrder})
+	if err != nil {
+		t.Fatalf("PrettyPrint() error = %v", err)
+	}
+	if want := `{"item10":1,"Item2":2,"nested":[{"A":2,"b":1}]}`; pretty != want {
+		t.Errorf("PrettyPrint() = %s, want %s", pretty, want)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	if err := ValidateJSON(`{"a":[1,true,null]}`); err != nil {
+		t.Errorf("ValidateJSON() error = %v", err)
+	}
+	if err := ValidateJSON(`{"a":}`); err == nil {
+		t.Errorf("ValidateJSON() with invalid JSON should fail")
+	}
+	if err := ValidateJSONBytes([]byte(`[1,2]`)); err != nil {
+		t.Errorf("ValidateJSONBytes() error = %v", err)
+	}
+	if err := ValidateJSONBytes([]byte(``)); err == nil {
+		t.Errorf("ValidateJSONBytes() with empty input should fail")
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	got, err := MergeJSON(`{"a":1,"b":{"c":1,"d":2}}`, `{"b":{"d":3,"e":4},"f":5}`)
+	if err != nil {
+		t.Fatalf("MergeJSON() error = %v", err)
+	}
+	want, _ := parser.ParseToValue(`{"a":1,"b":{"c":1,"d":3,"e":4},"f":5}`)
+	gotValue, _ := parser.ParseToValue(got)
+	if CompareValues(gotValue, want) != 0 {
+		t.Errorf("MergeJSON() = %s, want %s", got, want.String())
+	}
+
+	if _, err := MergeJSON(`[1]`, `{}`); err == nil {
+		t.Errorf("MergeJSON() with an array target should fail")
+	}
+	if _, err := MergeJSON(`{}`, `1`); err == nil {
+		t.Errorf("MergeJSON() with a number source should fail")
+	}
+
+	target, _ := parser.ParseToValue(`{"a":{"b":1}}`)
+	source, _ := parser.ParseToValue(`{"a":{"c":2}}`)
+	merged, err := MergeValues(target, source)
+	if err != nil {
+		t.Fatalf("MergeValues() error = %v", err)
+	}
+	if target.String() != `{"a":{"b":1}}` {
+		t.Errorf("MergeValues() should not modify the target, got %s", target.String())
+	}
+	if n, err := merged.(*types.JSONObject).GetObject("a"); err != nil || n.Size() != 2 {
+		t.Errorf("MergeValues() = %s", merged.String())
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	original, _ := parser.ParseToValue(`{"a":[1,{"b":"c"}],"d":true,"e":null}`)
+	copied := DeepCopy(original)
+	if CompareValues(original, copied) != 0 {
+		t.Fatalf("DeepCopy() = %s, want %s", copied.String(), original.String())
+	}
+
+	obj, _ := copied.AsObject()
+	arr, _ := obj.GetArray("a")
+	inner, _ := arr.GetObject(1)
+	inner.PutString("b", "changed")
+	if !strings.Contains(original.String(), `"c"`) {
+		t.Errorf("DeepCopy() result shares values with the original: %s", original.String())
+	}
+	if !DeepCopy(nil).IsNull() {
+		t.Errorf("DeepCopy(nil) should return null")
+	}
+}
+
+func TestDeepCopyChecked(t *testing.T) {
+	// 同一个对象出现在两个位置不是循环
+	shared := types.NewJSONObject()
+	shared.PutNumber("n", 1)
+	root := types.NewJSONObject()
+	root.PutObject("a", shared)
+	root.PutObject("b", shared)
+	copied, err := DeepCopyChecked(root)
+	if err != nil {
+		t.Fatalf("DeepCopyChecked() with a shared object error = %v", err)
+	}
+	copiedObj, _ := copied.AsObject()
+	a, _ := copiedObj.GetObject("a")
+	b, _ := copiedObj.GetObject("b")
+	a.PutNumber("n", 2)
+	if n, _ := b.GetNumber("n"); n != 1 {
+		t.Errorf("shared values should be copied independently, got n = %v", n)
+	}
+
+	// 对象包含自身
+	cyclic := types.NewJSONObject()
+	items := types.NewJSONArray()
+	items.Add(cyclic)
+	cyclic.PutArray("items", items)
+	_, err = DeepCopyChecked(cyclic)
+	if err == nil || !strings.Contains(err.Error(), "CIRCULAR_REFERENCE") || !strings.Contains(err.Error(), "$.items[0]") {
+		t.Errorf("DeepCopyChecked() with a cycle error = %v", err)
+	}
+
+	// 数组包含自身
+	self := types.NewJSONArray()
+	self.Add(self)
+	if _, err := DeepCopyChecked(self); err == nil {
+		t.Errorf("DeepCopyChecked() with a self-containing array should fail")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DeepCopy() with a cycle should panic")
+		}
+	}()
+	DeepCopy(self)
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// JoinKind 表示连接方式
+type JoinKind int
+
+const (
+	// InnerJoin 只输出两侧都能匹配的记录
+	InnerJoin JoinKind = iota
+	// LeftJoin 输出所有左侧记录，没有匹配的右侧记录时保持左侧记录不变
+	LeftJoin
+)
+
+// Joiner 以右侧数据集建立索引，然后逐个连接左侧记录
+// 适用于左侧数据集很大、需要流式处理的场景
+type Joiner struct {
+	left  *Deduper
+	right map[[16]byte][]*types.JSONObject
+	kind  JoinKind
+}
+
+// NewJoiner 为右侧数据集建立索引，leftKey和rightKey是以元素为根的JSON Path，例如"$.userId"
+// 右侧中不是对象或缺少连接键的元素会被忽略
+func NewJoiner(right *types.JSONArray, leftKey, rightKey string, kind JoinKind) (*Joiner, error) {
+	left, err := NewDeduper(leftKey)
+	if err != nil {
+		return nil, err
+	}
+	rightDeduper, err := NewDeduper(rightKey)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Joiner{left: left, right: make(map[[16]byte][]*types.JSONObject), kind: kind}
+	for _, value := range right.Values() {
+		obj, err := value.AsObject()
+		if err != nil {
+			continue
+		}
+		if key, ok := rightDeduper.Key(value); ok {
+			j.right[key] = append(j.right[key], obj)
+		}
+	}
+	return j, nil
+}
+
+// Join 返回左侧记录与所有匹配的右侧记录合并后的结果，每个匹配产生一条记录
+// 合并时右侧记录中左侧没有的字段被加入结果，同名字段保留左侧的值
+// 没有匹配时，内连接返回空切片，左连接返回只包含左侧记录的切片
+func (j *Joiner) Join(left types.JSONValue) []types.JSONValue {
+	var matches []*types.JSONObject
+	obj, err := left.AsObject()
+	if err == nil {
+		if key, ok := j.left.Key(left); ok {
+			matches = j.right[key]
+		}
+	}
+
+	if len(matches) == 0 {
+		if j.kind == LeftJoin {
+			return []types.JSONValue{left}
+		}
+		return nil
+	}
+
+	result := make([]types.JSONValue, len(matches))
+	for i, match := range matches {
+		result[i] = mergeRecords(obj, match)
+	}
+	return result
+}
+
+// Join 按连接键连接两个数组，返回合并后的记录
+// 结果按左侧记录的顺序排列，同一左侧记录的多个匹配按右侧记录的顺序排列
+func Join(left, right *types.JSONArray, leftKey, rightKey string, kind JoinKind) (*types.JSONArray, error) {
+	j, err := NewJoiner(right, leftKey, rightKey, kind)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]types.JSONValue, 0, left.Size())
+	for _, value := range left.Values() {
+		result = append(result, j.Join(value)...)
+	}
+	return types.NewJSONArrayFromValuesUnsafe(result), nil
+}
+
+// mergeRecords 返回包含左侧所有字段和右侧独有字段的新对象
+func mergeRecords(left, right *types.JSONObject) *types.JSONObject {
+	result := types.NewJSONObject()
+	for _, key := range left.Keys() {
+		result.Put(key, left.Get(key))
+	}
+	for _, key := range right.Keys() {
+		if !left.Has(key) {
+			result.Put(key, right.Get(key))
+		}
+	}
+	return result
+}
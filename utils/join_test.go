@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestJoin(t *testing.T) {
+	parseArray := func(s string) *types.JSONArray {
+		value, err := parser.ParseToValue(s)
+		if err != nil {
+			t.Fatalf("ParseToValue() error = %v", err)
+		}
+		arr, _ := value.AsArray()
+		return arr
+	}
+
+	orders := parseArray(`[
+		{"id": 1, "userId": "u1", "amount": 10},
+		{"id": 2, "userId": "u2", "amount": 20},
+		{"id": 3, "userId": "u9", "amount": 30},
+		{"id": 4, "amount": 40}
+	]`)
+	users := parseArray(`[
+		{"uid": "u1", "name": "Alice", "id": 100},
+		{"uid": "u2", "name": "Bob"},
+		{"uid": "u2", "name": "Bobby"},
+		"ignored"
+	]`)
+
+	tests := []struct {
+		name string
+		kind JoinKind
+		want string
+	}{
+		{"内连接", InnerJoin,
+			`[{"amount":10,"id":1,"name":"Alice","uid":"u1","userId":"u1"},` +
+				`{"amount":20,"id":2,"name":"Bob","uid":"u2","userId":"u2"},` +
+				`{"amount":20,"id":2,"name":"Bobby","uid":"u2","userId":"u2"}]`},
+		{"左连接", LeftJoin,
+			`[{"amount":10,"id":1,"name":"Alice","uid":"u1","userId":"u1"},` +
+				`{"amount":20,"id":2,"name":"Bob","uid":"u2","userId":"u2"},` +
+				`{"amount":20,"id":2,"name":"Bobby","uid":"u2","userId":"u2"},` +
+				`{"amount":30,"id":3,"userId":"u9"},` +
+				`{"amount":40,"id":4}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Join(orders, users, "$.userId", "$.uid", tt.kind)
+			if err != nil {
+				t.Fatalf("Join() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Join() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+
+	first, _ := orders.GetObject(0)
+	if first.Has("name") {
+		t.Error("Join() 不应修改左侧记录")
+	}
+	if _, err := Join(orders, users, "userId", "$.uid", InnerJoin); err == nil {
+		t.Error("无效路径应该返回错误")
+	}
+}
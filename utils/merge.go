@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"fmt"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// MergeStrategy 决定两边的值冲突时使用哪个值
+type MergeStrategy int
+
+const (
+	// MergePreferSource 使用源JSON中的值
+	MergePreferSource MergeStrategy = iota
+	// MergePreferTarget 保留目标JSON中的值
+	MergePreferTarget
+	// MergeFailOnConflict 遇到冲突时返回错误
+	MergeFailOnConflict
+)
+
+// ArrayMergeMode 决定两边都是数组时如何合并
+type ArrayMergeMode int
+
+const (
+	// ArrayReplace 把两个不同的数组视为冲突，按MergeStrategy选择其中一个
+	ArrayReplace ArrayMergeMode = iota
+	// ArrayConcat 把源数组的元素追加到目标数组之后
+	ArrayConcat
+	// ArrayUnion 追加源数组中目标数组没有的元素
+	ArrayUnion
+	// ArrayByIndex 按索引逐个合并元素，较长数组多出的元素直接保留
+	ArrayByIndex
+)
+
+// MergeOptions 表示合并JSON的选项
+type MergeOptions struct {
+	Strategy    MergeStrategy  // 冲突时使用的值
+	Arrays      ArrayMergeMode // 数组的合并方式
+	NullDeletes bool           // 源JSON中的null删除目标中的键，与RFC 7396 JSON Merge Patch相同
+}
+
+// DefaultMergeOptions 返回默认的合并选项，与MergeJSON的行为相同
+func DefaultMergeOptions() *MergeOptions {
+	return &MergeOptions{
+		Strategy:    MergePreferSource,
+		Arrays:      ArrayReplace,
+		NullDeletes: false,
+	}
+}
+
+// Conflict 表示两边在同一路径上有不同且无法递归合并的值
+type Conflict struct {
+	Path   string          // 冲突位置的JSON Path
+	Target types.JSONValue // 目标JSON中的值
+	Source types.JSONValue // 源JSON中的值
+}
+
+// String 返回冲突的描述
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %s <> %s", c.Path, c.Target.String(), c.Source.String())
+}
+
+// MergeJSONWithOptions 按选项深度合并两个JSON字符串，返回合并结果和所有冲突。
+// 根节点可以是任意类型，两边都是对象或数组时按选项递归合并。
+// 使用MergeFailOnConflict时，存在冲突会返回错误以及所有冲突
+func MergeJSONWithOptions(target, source string, options *MergeOptions) (string, []Conflict, error) {
+	targetValue, err := parser.ParseToValue(target)
+	if err != nil {
+		return "", nil, err
+	}
+	sourceValue, err := parser.ParseToValue(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	result, conflicts, err := MergeValuesWithOptions(targetValue, sourceValue, options)
+	if err != nil {
+		return "", conflicts, err
+	}
+	return result.String(), conflicts, nil
+}
+
+// MergeValuesWithOptions 按选项深度合并两个JSON值，options为nil时使用默认选项。
+// 输入的值不会被修改，结果中的值都是副本
+func MergeValuesWithOptions(target, source types.JSONValue, options *MergeOptions) (types.JSONValue, []Conflict, error) {
+	if options == nil {
+		options = DefaultMergeOptions()
+	}
+	if target == nil {
+		target = types.NewJSONNull()
+	}
+	if source == nil {
+		source = types.NewJSONNull()
+	}
+
+	m := &merger{options: options}
+	result := m.merge(target, source, "$")
+	if m.err != nil {
+		return nil, m.conflicts, m.err
+	}
+	if len(m.conflicts) > 0 && options.Strategy == MergeFailOnConflict {
+		return nil, m.conflicts, jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed,
+			fmt.Sprintf("合并时发现%d处冲突", len(m.conflicts))).WithPath(m.conflicts[0].Path)
+	}
+	return result, m.conflicts, nil
+}
+
+// merger 保存一次合并的选项、发现的冲突和复制值时的第一个错误
+type merger struct {
+	options   *MergeOptions
+	conflicts []Conflict
+	err       error
+}
+
+// copy 复制合并结果中的值，值包含循环引用时记录错误并返回null
+func (m *merger) copy(value types.JSONValue) types.JSONValue {
+	copied, err := DeepCopyChecked(value)
+	if err != nil {
+		if m.err == nil {
+			m.err = err
+		}
+		return types.NewJSONNull()
+	}
+	return copied
+}
+
+// merge 合并同一路径上的两个值
+func (m *merger) merge(target, source types.JSONValue, path string) types.JSONValue {
+	switch {
+	case target.IsObject() && source.IsObject():
+		targetObj, _ := target.AsObject()
+		sourceObj, _ := source.AsObject()
+		return m.mergeObjects(targetObj, sourceObj, path)
+	case target.IsArray() && source.IsArray() && m.options.Arrays != ArrayReplace:
+		targetArr, _ := target.AsArray()
+		sourceArr, _ := source.AsArray()
+		return m.mergeArrays(targetArr, sourceArr, path)
+	case CompareValues(target, source) == 0:
+		return m.copy(target)
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{Path: path, Target: target, Source: source})
+	if m.options.Strategy == MergePreferTarget {
+		return m.copy(target)
+	}
+	return m.copy(source)
+}
+
+// mergeObjects 合并两个对象，目标中的键保持原有顺序，源中新增的键追加在后面
+func (m *merger) mergeObjects(target, source *types.JSONObject, path string) *types.JSONObject {
+	result := types.NewJSONObject()
+	for _, key := range target.Keys() {
+		childPath := types.ChildPath(path, types.KeySegment(key))
+		if !source.Has(key) {
+			result.Put(key, m.copy(target.Get(key)))
+			continue
+		}
+		sourceValue := source.Get(key)
+		if sourceValue.IsNull() && m.options.NullDeletes {
+			continue
+		}
+		result.Put(key, m.merge(target.Get(key), sourceValue, childPath))
+	}
+	for _, key := range source.Keys() {
+		if target.Has(key) {
+			continue
+		}
+		sourceValue := source.Get(key)
+		if sourceValue.IsNull() && m.options.NullDeletes {
+			continue
+		}
+		result.Put(key, m.copy(sourceValue))
+	}
+	return result
+}
+
+// mergeArrays 按数组合并方式合并两个数组
+func (m *merger) mergeArrays(target, source *types.JSONArray, path string) *types.JSONArray {
+	result := types.NewJSONArray()
+	switch m.options.Arrays {
+	case ArrayConcat:
+		for _, value := range target.Values() {
+			result.Add(m.copy(value))
+		}
+		for _, value := range source.Values() {
+			result.Add(m.copy(value))
+		}
+	case ArrayUnion:
+		for _, value := range target.Values() {
+			result.Add(m.copy(value))
+		}
+		for _, value := range source.Values() {
+			if !containsValue(result, value) {
+				result.Add(m.copy(value))
+			}
+		}
+	case ArrayByIndex:
+		for i := 0; i < target.Size() || i < source.Size(); i++ {
+			switch {
+			case i >= source.Size():
+				result.Add(m.copy(target.Get(i)))
+			case i >= target.Size():
+				result.Add(m.copy(source.Get(i)))
+			default:
+				result.Add(m.merge(target.Get(i), source.Get(i), types.ChildPath(path, types.IndexSegment(i))))
+			}
+		}
+	}
+	return result
+}
+
+// containsValue 检查数组中是否有与value相等的元素
+func containsValue(arr *types.JSONArray, value types.JSONValue) bool {
+	for _, element := range arr.Values() {
+		if CompareValues(element, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"testing"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestMergeJSONWithOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		source    string
+		options   *MergeOptions
+		want      string
+		conflicts []string
+	}{
+		{
+			name:      "默认选项",
+			target:    `{"a":1,"b":{"c":2,"d":3},"e":[1,2]}`,
+			source:    `{"a":1,"b":{"c":4},"e":[3],"f":true}`,
+			want:      `{"a":1,"b":{"c":4,"d":3},"e":[3],"f":true}`,
+			conflicts: []string{"$.b.c", "$.e"},
+		},
+		{
+			name:      "保留目标",
+			target:    `{"a":1,"b":{"c":2}}`,
+			source:    `{"a":2,"b":{"c":3,"d":4}}`,
+			options:   &MergeOptions{Strategy: MergePreferTarget},
+			want:      `{"a":1,"b":{"c":2,"d":4}}`,
+			conflicts: []string{"$.a", "$.b.c"},
+		},
+		{
+			name:    "连接数组",
+			target:  `{"tags":["a","b"]}`,
+			source:  `{"tags":["b","c"]}`,
+			options: &MergeOptions{Arrays: ArrayConcat},
+			want:    `{"tags":["a","b","b","c"]}`,
+		},
+		{
+			name:    "数组并集",
+			target:  `{"tags":["a","b"]}`,
+			source:  `{"tags":["b","c","c"]}`,
+			options: &MergeOptions{Arrays: ArrayUnion},
+			want:    `{"tags":["a","b","c"]}`,
+		},
+		{
+			name:      "按索引合并数组",
+			target:    `[{"a":1},2,3]`,
+			source:    `[{"b":2},5]`,
+			options:   &MergeOptions{Arrays: ArrayByIndex},
+			want:      `[{"a":1,"b":2},5,3]`,
+			conflicts: []string{"$[1]"},
+		},
+		{
+			name:    "null删除键",
+			target:  `{"a":1,"b":2}`,
+			source:  `{"a":null,"c":null}`,
+			options: &MergeOptions{NullDeletes: true},
+			want:    `{"b":2}`,
+		},
+		{
+			name:      "特殊字符的键",
+			target:    `{"a b":1}`,
+			source:    `{"a b":2}`,
+			want:      `{"a b":2}`,
+			conflicts: []string{"$['a b']"},
+		},
+		{
+			name:      "根节点类型不同",
+			target:    `{"a":1}`,
+			source:    `[1]`,
+			want:      `[1]`,
+			conflicts: []string{"$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts, err := MergeJSONWithOptions(tt.target, tt.source, tt.options)
+			if err != nil {
+				t.Fatalf("MergeJSONWithOptions() error = %v", err)
+			}
+			if !jsonEqual(t, got, tt.want) {
+				t.Errorf("MergeJSONWithOptions() = %s, want %s", got, tt.want)
+			}
+			if len(conflicts) != len(tt.conflicts) {
+				t.Fatalf("MergeJSONWithOptions() conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+			for i, c := range conflicts {
+				if c.Path != tt.conflicts[i] {
+					t.Errorf("conflicts[%d].Path = %s, want %s", i, c.Path, tt.conflicts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeFailOnConflict(t *testing.T) {
+	options := &MergeOptions{Strategy: MergeFailOnConflict}
+	_, conflicts, err := MergeJSONWithOptions(`{"a":1,"b":2}`, `{"a":2,"b":3}`, options)
+	if err == nil {
+		t.Fatalf("MergeJSONWithOptions() should fail on conflict")
+	}
+	if len(conflicts) != 2 || conflicts[0].Path != "$.a" || conflicts[0].Target.String() != "1" || conflicts[0].Source.String() != "2" {
+		t.Errorf("MergeJSONWithOptions() conflicts = %v", conflicts)
+	}
+
+	got, conflicts, err := MergeJSONWithOptions(`{"a":1}`, `{"b":2}`, options)
+	if err != nil || len(conflicts) != 0 || !jsonEqual(t, got, `{"a":1,"b":2}`) {
+		t.Errorf("MergeJSONWithOptions() without conflicts = %s, %v, %v", got, conflicts, err)
+	}
+}
+
+func TestMergeValuesWithOptionsCopies(t *testing.T) {
+	target, _ := parser.ParseToValue(`{"a":{"x":1}}`)
+	source, _ := parser.ParseToValue(`{"b":{"y":2}}`)
+
+	result, _, err := MergeValuesWithOptions(target, source, nil)
+	if err != nil {
+		t.Fatalf("MergeValuesWithOptions() error = %v", err)
+	}
+	obj, _ := result.AsObject()
+	a, _ := obj.Get("a").AsObject()
+	a.PutNumber("x", 10)
+	b, _ := obj.Get("b").AsObject()
+	b.PutNumber("y", 20)
+
+	if target.String() != `{"a":{"x":1}}` || source.String() != `{"b":{"y":2}}` {
+		t.Errorf("MergeValuesWithOptions() modified its inputs: %s, %s", target.String(), source.String())
+	}
+}
+
+func TestMergeValuesWithOptionsCycle(t *testing.T) {
+	target, _ := parser.ParseToValue(`{"a":1}`)
+	cyclic := types.NewJSONObject()
+	cyclic.Put("self", cyclic)
+	source := types.NewJSONObject().Put("b", cyclic)
+
+	_, _, err := MergeValuesWithOptions(target, source, nil)
+	if jsonErr, ok := err.(*jsonerrors.JSONError); !ok || jsonErr.Code != jsonerrors.ErrCircularReference {
+		t.Errorf("MergeValuesWithOptions() error = %v, want ErrCircularReference", err)
+	}
+}
+
+// jsonEqual 比较两个JSON字符串表示的值是否相等
+func jsonEqual(t *testing.T, a, b string) bool {
+	t.Helper()
+	av, err := parser.ParseToValue(a)
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", a, err)
+	}
+	bv, err := parser.ParseToValue(b)
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", b, err)
+	}
+	return CompareValues(av, bv) == 0
+}
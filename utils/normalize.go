@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// ArrayOrder 定义规范化时数组元素的排序规则，返回a是否应排在b之前
+type ArrayOrder func(a, b types.JSONValue) bool
+
+// ByHash 按元素规范化JSON表示的FNV-1a哈希排序数组，哈希相同时按JSON表示排序
+func ByHash(a, b types.JSONValue) bool {
+	as, bs := a.String(), b.String()
+	ah, bh := hashString(as), hashString(bs)
+	if ah != bh {
+		return ah < bh
+	}
+	return as < bs
+}
+
+// ByKey 返回按对象元素中指定键的值排序数组的规则，键值按CompareValues比较：
+// 数字按数值、字符串按解码后的内容比较，类型不同时按类型排序。
+// 缺少该键或不是对象的元素排在最后，键值相同时按整个元素的JSON表示排序
+func ByKey(key string) ArrayOrder {
+	return func(a, b types.JSONValue) bool {
+		av, aok := keyValue(a, key)
+		bv, bok := keyValue(b, key)
+		if aok != bok {
+			return aok
+		}
+		if aok {
+			if c := CompareValues(av, bv); c != 0 {
+				return c < 0
+			}
+		}
+		return a.String() < b.String()
+	}
+}
+
+// NormalizeOptions 表示规范化选项
+type NormalizeOptions struct {
+	// SortObjectKeys 表示是否按字典序排列对象的键
+	SortObjectKeys bool
+	// SortArrays 是数组元素的排序规则，为nil时保持原有顺序
+	SortArrays ArrayOrder
+	// TrimStrings 表示是否去除字符串值首尾的空白
+	TrimStrings bool
+	// LowercaseKeys 表示是否将对象的键转换为小写，转换后重复的键以后出现的为准
+	LowercaseKeys bool
+}
+
+// Normalize 返回JSON值的规范化副本，原值不会被修改
+// 规范化后的文档适合用于快照比较和去重
+func Normalize(value types.JSONValue, options NormalizeOptions) types.JSONValue {
+	if value == nil || value.IsNull() {
+		return types.NewJSONNull()
+	}
+
+	switch {
+	case value.IsObject():
+		obj, _ := value.AsObject()
+		keys := obj.Keys()
+		if options.SortObjectKeys {
+			keys = obj.SortedKeys()
+		}
+		result := types.NewJSONObject()
+		for _, key := range keys {
+			newKey := key
+			if options.LowercaseKeys {
+				newKey = strings.ToLower(key)
+			}
+			result.Put(newKey, Normalize(obj.Get(key), options))
+		}
+		if options.SortObjectKeys && options.LowercaseKeys {
+			// 小写后的键顺序可能变化，重新排序
+			sorted := types.NewJSONObject()
+			for _, key := range result.SortedKeys() {
+				sorted.Put(key, result.Get(key))
+			}
+			result = sorted
+		}
+		return result
+	case value.IsArray():
+		arr, _ := value.AsArray()
+		elements := make([]types.JSONValue, arr.Size())
+		for i := 0; i < arr.Size(); i++ {
+			elements[i] = Normalize(arr.Get(i), options)
+		}
+		if options.SortArrays != nil {
+			sort.SliceStable(elements, func(i, j int) bool {
+				return options.SortArrays(elements[i], elements[j])
+			})
+		}
+		return types.NewJSONArrayFromValuesUnsafe(elements)
+	case value.IsString():
+		str, _ := value.AsString()
+		if options.TrimStrings {
+			str = strings.TrimSpace(str)
+		}
+		return types.NewJSONString(str)
+	default:
+		return DeepCopy(value)
+	}
+}
+
+// hashString 计算字符串的FNV-1a哈希
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// keyValue 获取对象元素中指定键的值
+func keyValue(value types.JSONValue, key string) (types.JSONValue, bool) {
+	if !value.IsObject() {
+		return nil, false
+	}
+	obj, _ := value.AsObject()
+	if !obj.Has(key) {
+		return nil, false
+	}
+	return obj.Get(key), true
+}
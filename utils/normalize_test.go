@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+func TestNormalize(t *testing.T) {
+	value, err := parser.ParseToValue(`{
+		"Name": "  John  ",
+		"Items": [{"id": 3, "v": "c"}, {"id": 1, "v": "a"}, {"v": "x"}, {"id": 2, "v": "b"}],
+		"Tags": ["b", "a", "c"]
+	}`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	original := value.String()
+
+	got := Normalize(value, NormalizeOptions{
+		SortObjectKeys: true,
+		SortArrays:     ByKey("id"),
+		TrimStrings:    true,
+		LowercaseKeys:  true,
+	})
+	want := `{"items":[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":3,"v":"c"},{"v":"x"}],"name":"John","tags":["a","b","c"]}`
+	if got.String() != want {
+		t.Errorf("Normalize() = %v, want %v", got.String(), want)
+	}
+	obj, _ := got.AsObject()
+	if keys := obj.Keys(); keys[0] != "items" || keys[1] != "name" || keys[2] != "tags" {
+		t.Errorf("Normalize() keys = %v, want sorted", keys)
+	}
+
+	// 原值不应被修改
+	if value.String() != original {
+		t.Errorf("Normalize() modified the input value")
+	}
+
+	// 不同顺序的数组按哈希排序后应当相同
+	a, _ := parser.ParseToValue(`[{"x":1},"s",2,[true]]`)
+	b, _ := parser.ParseToValue(`[[true],2,{"x":1},"s"]`)
+	na := Normalize(a, NormalizeOptions{SortArrays: ByHash})
+	nb := Normalize(b, NormalizeOptions{SortArrays: ByHash})
+	if na.String() != nb.String() {
+		t.Errorf("Normalize(ByHash) = %v and %v, want equal", na.String(), nb.String())
+	}
+
+	// 不设置选项时只做深度复制
+	plain := Normalize(value, NormalizeOptions{})
+	if plain.String() != original {
+		t.Errorf("Normalize() with zero options = %v, want %v", plain.String(), original)
+	}
+}
+
+func TestNormalizeByKeyValues(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// 多位数字按数值排序，而不是按JSON文本
+		{`[{"id":10},{"id":9},{"id":2},{"id":1.5}]`, `[{"id":1.5},{"id":2},{"id":9},{"id":10}]`},
+		// 字符串按解码后的内容排序，转义的\u0041就是A
+		{`[{"id":"b"},{"id":"B"},{"id":"\u0041"}]`, `[{"id":"A"},{"id":"B"},{"id":"b"}]`},
+		// 类型不同时按类型排序，缺少键的元素排在最后
+		{`[{"id":"1"},{},{"id":2},{"id":null},{"id":true}]`, `[{"id":null},{"id":true},{"id":2},{"id":"1"},{}]`},
+	}
+	for _, tt := range tests {
+		value, err := parser.ParseToValue(tt.input)
+		if err != nil {
+			t.Fatalf("ParseToValue(%s) error = %v", tt.input, err)
+		}
+		if got := Normalize(value, NormalizeOptions{SortArrays: ByKey("id")}); got.String() != tt.want {
+			t.Errorf("Normalize(%s, ByKey) = %v, want %v", tt.input, got.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"github.com/UserLeeZJ/gojson/internal/jsonstr"
+)
+
+// QuoteOptions 定义QuoteJSONString的转义选项
+type QuoteOptions struct {
+	// EscapeHTML 表示是否把<、>和&转义为\u003c、\u003e和\u0026，使结果可以嵌入HTML
+	EscapeHTML bool
+	// ASCII 表示是否把所有非ASCII字符转义为\uXXXX，超出基本多文种平面的字符使用代理对
+	ASCII bool
+}
+
+// QuoteJSONString 返回s的JSON字符串字面量，包括双引号。
+// 总是转义双引号、反斜杠、控制字符以及U+2028和U+2029，无效的UTF-8字节转义为\ufffd。
+// 与stream.JSONGenerator写出的字符串相同
+func QuoteJSONString(s string, opts QuoteOptions) string {
+	return string(AppendQuotedJSONString(make([]byte, 0, len(s)+2), s, opts))
+}
+
+// AppendQuotedJSONString 把s的JSON字符串字面量追加到dst后返回，dst容量足够时不分配内存
+func AppendQuotedJSONString(dst []byte, s string, opts QuoteOptions) []byte {
+	return jsonstr.AppendQuote(dst, s, opts.EscapeHTML, opts.ASCII)
+}
+
+// UnquoteJSONString 解码包括双引号的JSON字符串字面量，例如stream.JSONTokenizer.RawString的结果。
+// 未转义的控制字符和无效的转义返回错误，不成对的UTF-16代理项和无效的UTF-8字节替换为U+FFFD，
+// 与encoding/json相同。没有转义的字符串只分配一次
+func UnquoteJSONString(data []byte) (string, error) {
+	return jsonstr.Unquote(data)
+}
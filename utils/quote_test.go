@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuoteJSONString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		opts QuoteOptions
+		want string
+	}{
+		{"普通字符串", "hello 世界", QuoteOptions{}, `"hello 世界"`},
+		{"引号和反斜杠", `a"b\c/d`, QuoteOptions{}, `"a\"b\\c/d"`},
+		{"控制字符", "\b\f\n\r\t\x00\x1f", QuoteOptions{}, `"\b\f\n\r\t\u0000\u001f"`},
+		{"行分隔符", "a\u2028b\u2029", QuoteOptions{}, `"a\u2028b\u2029"`},
+		{"无效的UTF-8", "a\xffb", QuoteOptions{}, `"a\ufffdb"`},
+		{"不转义HTML", "<a&b>", QuoteOptions{}, `"<a&b>"`},
+		{"转义HTML", "<a&b>", QuoteOptions{EscapeHTML: true}, `"\u003ca\u0026b\u003e"`},
+		{"只使用ASCII", "\u00e9\u4e16\U0001F600", QuoteOptions{ASCII: true}, `"\u00e9\u4e16\ud83d\ude00"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := QuoteJSONString(tt.s, tt.opts)
+			if got != tt.want {
+				t.Errorf("QuoteJSONString(%q) = %s, want %s", tt.s, got, tt.want)
+			}
+
+			// 结果是有效的JSON字符串，解码后与原字符串相同（无效的UTF-8除外）
+			var decoded string
+			if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", got, err)
+			}
+			if back, err := UnquoteJSONString([]byte(got)); err != nil || back != decoded {
+				t.Errorf("UnquoteJSONString(%s) = %q, %v, want %q", got, back, err, decoded)
+			}
+		})
+	}
+
+	dst := []byte("x=")
+	if got := string(AppendQuotedJSONString(dst, "a\nb", QuoteOptions{})); got != `x="a\nb"` {
+		t.Errorf("AppendQuotedJSONString() = %s", got)
+	}
+}
+
+func TestUnquoteJSONString(t *testing.T) {
+	// 与encoding/json的结果比较
+	inputs := []string{
+		`""`,
+		`"plain"`,
+		`"caf\u00e9"`,
+		`"\"\\\/\b\f\n\r\t"`,
+		`"\ud83d\ude00"`,
+		`"\ud83d"`,
+		`"\ud83dx"`,
+		`"\ude00\ud83d"`,
+		`"\ud83d\u0041"`,
+		"\"a\xffb\"",
+		"\"\xc0\xaf\"",
+		"\"世界\"",
+	}
+	for _, input := range inputs {
+		var want string
+		if err := json.Unmarshal([]byte(input), &want); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", input, err)
+		}
+		got, err := UnquoteJSONString([]byte(input))
+		if err != nil || got != want {
+			t.Errorf("UnquoteJSONString(%s) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{``, `"`, `abc`, `"abc`, `"a\"`, `"a"b"`, "\"a\nb\"", `"\x"`, `"\u12"`, `"\u12zz"`} {
+		if got, err := UnquoteJSONString([]byte(input)); err == nil {
+			t.Errorf("UnquoteJSONString(%q) = %q, want error", input, got)
+		}
+	}
+}
+
+func TestQuoteAllocations(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(100, func() {
+		buf = AppendQuotedJSONString(buf[:0], "hello \"world\"\n", QuoteOptions{})
+	}); n != 0 {
+		t.Errorf("AppendQuotedJSONString() 分配了 %v 次", n)
+	}
+
+	data := []byte(`"hello world"`)
+	if n := testing.AllocsPerRun(100, func() {
+		_, _ = UnquoteJSONString(data)
+	}); n > 1 {
+		t.Errorf("UnquoteJSONString() 分配了 %v 次", n)
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/parser"
+)
+
+// ReindentJSON 只调整JSON字符串中每一行的缩进，保留键的顺序、数字和字符串的原始写法以及原有的换行，
+// 用于格式化手工编辑的文件并使版本控制中的差异最小。
+// 每行的缩进由该行开始处的嵌套深度决定，以右括号开头的行与对应的左括号所在的行对齐；
+// 行尾的空白和文档首尾的空白会被删除，空行和CRLF换行保持不变
+func ReindentJSON(jsonStr string, indent string) (string, error) {
+	if _, err := parser.ParseToValue(jsonStr); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(jsonStr))
+	depth := 0
+	lines := strings.Split(strings.Trim(jsonStr, jsonWhitespace), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		crlf := strings.HasSuffix(line, "\r")
+		// 合法的JSON字符串中不能出现未转义的换行符，所以行首和行尾的空白都在字符串之外
+		line = strings.Trim(line, jsonWhitespace)
+
+		if line != "" {
+			sb.WriteString(strings.Repeat(indent, lineLevel(line, depth)))
+			sb.WriteString(line)
+			depth += depthChange(line)
+		}
+		if crlf {
+			sb.WriteByte('\r')
+		}
+	}
+	return sb.String(), nil
+}
+
+// jsonWhitespace 是JSON语法允许的空白字符
+const jsonWhitespace = " \t\r\n"
+
+// lineLevel 返回一行的缩进级别，行首每个右括号使级别减一
+func lineLevel(line string, depth int) int {
+	level := depth
+	for i := 0; i < len(line) && level > 0; i++ {
+		switch line[i] {
+		case '}', ']':
+			level--
+		case ' ', '\t', ',':
+		default:
+			return level
+		}
+	}
+	return level
+}
+
+// depthChange 返回一行中左括号与右括号数量之差，忽略字符串中的括号
+func depthChange(line string) int {
+	change := 0
+	inString := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			change++
+		case c == '}' || c == ']':
+			change--
+		}
+	}
+	return change
+}
@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestReindentJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "调整缩进",
+			input: "{\n\"b\": 1,\n      \"a\": {\n  \"y\": 1.50,\n\"x\": 1e3\n    }\n}",
+			want:  "{\n  \"b\": 1,\n  \"a\": {\n    \"y\": 1.50,\n    \"x\": 1e3\n  }\n}",
+		},
+		{
+			name:  "保留行内的数组和字符串",
+			input: "{\n\t\"tags\": [1, 2,   3],\n\t\"s\": \"caf\\u00e9 {[\\\"\"\n}",
+			want:  "{\n  \"tags\": [1, 2,   3],\n  \"s\": \"caf\\u00e9 {[\\\"\"\n}",
+		},
+		{
+			name:  "多个右括号",
+			input: "[\n[\n{\n\"a\": 1\n}]\n, [\n]\n]",
+			want:  "[\n  [\n    {\n      \"a\": 1\n  }]\n  , [\n  ]\n]",
+		},
+		{
+			name:  "空行和行尾空白",
+			input: "\n\n{  \n\n   \"a\": 1   \n}\n\n",
+			want:  "{\n\n  \"a\": 1\n}",
+		},
+		{
+			name:  "CRLF换行",
+			input: "{\r\n\"a\": [\r\n1\r\n]\r\n}\r\n",
+			want:  "{\r\n  \"a\": [\r\n    1\r\n  ]\r\n}",
+		},
+		{
+			name:  "单行",
+			input: ` {"b":1, "a":2} `,
+			want:  `{"b":1, "a":2}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReindentJSON(tt.input, "  ")
+			if err != nil {
+				t.Fatalf("ReindentJSON() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReindentJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReindentJSON("{\n\"a\": 1,\n", "  "); err == nil {
+		t.Errorf("ReindentJSON() with invalid JSON should fail")
+	}
+}
@@ -0,0 +1,292 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	jsonerrors "github.com/UserLeeZJ/gojson/errors"
+)
+
+// FixKind 表示Repair修复的问题类型
+type FixKind int
+
+const (
+	// FixTrailingComma 表示对象或数组末尾多余的逗号，修复时删除
+	FixTrailingComma FixKind = iota
+	// FixSingleQuotes 表示单引号字符串，修复时改为双引号字符串
+	FixSingleQuotes
+	// FixUnquotedKey 表示没有引号的键，修复时加上双引号
+	FixUnquotedKey
+	// FixUnterminatedString 表示输入在字符串中结束，修复时补上引号
+	FixUnterminatedString
+	// FixMissingClose 表示输入结束时没有关闭的对象或数组，修复时补上右括号
+	FixMissingClose
+	// FixNonFinite 表示NaN、Infinity和-Infinity，修复时替换为null
+	FixNonFinite
+)
+
+// String 返回问题类型的描述
+func (k FixKind) String() string {
+	switch k {
+	case FixTrailingComma:
+		return "删除多余的逗号"
+	case FixSingleQuotes:
+		return "单引号改为双引号"
+	case FixUnquotedKey:
+		return "给键加上引号"
+	case FixUnterminatedString:
+		return "补上字符串的结束引号"
+	case FixMissingClose:
+		return "补上缺少的右括号"
+	default:
+		return "非有限数字替换为null"
+	}
+}
+
+// Fix 描述Repair所做的一处修复
+type Fix struct {
+	Kind   FixKind // 问题类型
+	Offset int     // 问题在原始输入中的字节偏移
+	Line   int     // 问题所在的行，从1开始
+	Column int     // 问题所在的列，从1开始，按字符计算
+	Text   string  // 被修复的原始内容，补充内容的修复为空
+}
+
+// String 返回修复的描述
+func (f Fix) String() string {
+	if f.Text == "" {
+		return fmt.Sprintf("%d:%d: %s", f.Line, f.Column, f.Kind)
+	}
+	return fmt.Sprintf("%d:%d: %s %s", f.Line, f.Column, f.Kind, f.Text)
+}
+
+// Repair 修复几乎合法的JSON中常见的问题：末尾多余的逗号、单引号字符串、没有引号的键、
+// 输入结束时没有关闭的字符串、对象和数组，以及NaN和Infinity字面量。
+// 返回修复后的JSON和每一处修复；除了修复的地方，输入中的其他内容包括空白都原样保留。
+// 修复后仍然不是合法JSON时返回错误以及已经做的修复
+func Repair(s string) (string, []Fix, error) {
+	r := &repairer{input: s, lastComma: -1}
+	if err := r.run(); err != nil {
+		return "", r.positions(), err
+	}
+	output := r.out.String()
+	if err := ValidateJSON(output); err != nil {
+		return "", r.positions(), err
+	}
+	return output, r.positions(), nil
+}
+
+// repairFrame 是一个尚未关闭的对象或数组
+type repairFrame struct {
+	open      byte // '{' 或 '['
+	expectKey bool // 对象中下一个值是否是键
+}
+
+// repairer 保存一次修复的状态
+type repairer struct {
+	input     string
+	out       strings.Builder
+	stack     []repairFrame
+	fixes     []Fix
+	lastComma int // 最近的逗号在out中的位置，之后出现其他内容时为-1
+	commaAt   int // 最近的逗号在输入中的位置
+}
+
+// run 逐个处理输入中的记号
+func (r *repairer) run() error {
+	s := r.input
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			r.out.WriteByte(c)
+			i++
+			continue
+		case c == ',':
+			r.lastComma, r.commaAt = r.out.Len(), i
+			r.out.WriteByte(c)
+			if top := r.top(); top != nil && top.open == '{' {
+				top.expectKey = true
+			}
+			i++
+			continue
+		case c == '}' || c == ']':
+			if err := r.close(c, i); err != nil {
+				return err
+			}
+			i++
+		case c == '{' || c == '[':
+			r.stack = append(r.stack, repairFrame{open: c, expectKey: c == '{'})
+			r.out.WriteByte(c)
+			i++
+		case c == ':':
+			if top := r.top(); top != nil && top.open == '{' {
+				top.expectKey = false
+			}
+			r.out.WriteByte(c)
+			i++
+		case c == '"' || c == '\'':
+			i = r.copyString(i)
+		case isWordByte(c):
+			i = r.word(i)
+		default:
+			// 其他字符原样保留，由最后的校验报告错误
+			r.out.WriteByte(c)
+			i++
+		}
+		r.lastComma = -1
+	}
+
+	r.dropTrailingComma()
+	for len(r.stack) > 0 {
+		r.fix(FixMissingClose, len(s), "")
+		r.out.WriteByte(closer(r.stack[len(r.stack)-1].open))
+		r.stack = r.stack[:len(r.stack)-1]
+	}
+	return nil
+}
+
+// top 返回最内层尚未关闭的对象或数组
+func (r *repairer) top() *repairFrame {
+	if len(r.stack) == 0 {
+		return nil
+	}
+	return &r.stack[len(r.stack)-1]
+}
+
+// close 处理右括号
+func (r *repairer) close(c byte, i int) error {
+	top := r.top()
+	if top == nil || closer(top.open) != c {
+		line, column := lineColumn(r.input, i)
+		return jsonerrors.NewJSONError(jsonerrors.ErrInvalidJSON,
+			fmt.Sprintf("第%d行第%d列的 %c 没有对应的左括号", line, column, c))
+	}
+	r.dropTrailingComma()
+	r.stack = r.stack[:len(r.stack)-1]
+	r.out.WriteByte(c)
+	return nil
+}
+
+// dropTrailingComma 删除右括号或输入末尾之前多余的逗号，逗号之后的空白保留
+func (r *repairer) dropTrailingComma() {
+	if r.lastComma < 0 {
+		return
+	}
+	out := r.out.String()
+	r.out.Reset()
+	r.out.WriteString(out[:r.lastComma])
+	r.out.WriteString(out[r.lastComma+1:])
+	r.fix(FixTrailingComma, r.commaAt, ",")
+	r.lastComma = -1
+}
+
+// copyString 复制从i开始的字符串，单引号字符串转换为双引号字符串，返回字符串之后的位置
+func (r *repairer) copyString(i int) int {
+	s := r.input
+	quote := s[i]
+	r.setKeyDone()
+	if quote == '\'' {
+		r.fix(FixSingleQuotes, i, "")
+	}
+	r.out.WriteByte('"')
+
+	for j := i + 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == quote:
+			r.out.WriteByte('"')
+			if quote == '\'' {
+				r.fixes[len(r.fixes)-1].Text = s[i : j+1]
+			}
+			return j + 1
+		case c == '\\' && j+1 < len(s):
+			if quote == '\'' && s[j+1] == '\'' {
+				// 双引号字符串中不需要转义单引号
+				r.out.WriteByte('\'')
+			} else {
+				r.out.WriteString(s[j : j+2])
+			}
+			j++
+		case c == '"':
+			// 单引号字符串中的双引号需要转义
+			r.out.WriteString(`\"`)
+		case c == '\\':
+			// 输入末尾的反斜杠会转义补上的引号，因此把它本身转义
+			r.out.WriteString(`\\`)
+		default:
+			r.out.WriteByte(c)
+		}
+	}
+
+	r.fix(FixUnterminatedString, len(s), "")
+	r.out.WriteByte('"')
+	return len(s)
+}
+
+// word 处理从i开始的字面量、数字或没有引号的键，返回之后的位置
+func (r *repairer) word(i int) int {
+	s := r.input
+	j := i
+	for j < len(s) && isWordByte(s[j]) {
+		j++
+	}
+	word := s[i:j]
+
+	if top := r.top(); top != nil && top.open == '{' && top.expectKey {
+		r.fix(FixUnquotedKey, i, word)
+		r.out.WriteString(`"` + word + `"`)
+		top.expectKey = false
+		return j
+	}
+	switch word {
+	case "NaN", "Infinity", "+Infinity", "-Infinity":
+		r.fix(FixNonFinite, i, word)
+		r.out.WriteString("null")
+	default:
+		r.out.WriteString(word)
+	}
+	return j
+}
+
+// setKeyDone 在对象中读到键之后标记下一个值不是键
+func (r *repairer) setKeyDone() {
+	if top := r.top(); top != nil && top.open == '{' {
+		top.expectKey = false
+	}
+}
+
+// fix 记录一处修复，行列号在最后统一计算
+func (r *repairer) fix(kind FixKind, offset int, text string) {
+	r.fixes = append(r.fixes, Fix{Kind: kind, Offset: offset, Text: text})
+}
+
+// positions 计算所有修复的行列号并按输入中的位置返回
+func (r *repairer) positions() []Fix {
+	for i := range r.fixes {
+		r.fixes[i].Line, r.fixes[i].Column = lineColumn(r.input, r.fixes[i].Offset)
+	}
+	return r.fixes
+}
+
+// closer 返回左括号对应的右括号
+func closer(open byte) byte {
+	if open == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+// isWordByte 检查字符是否可以出现在字面量、数字或没有引号的键中
+func isWordByte(c byte) bool {
+	return isValidIdentifierPart(c) || c == '-' || c == '+' || c == '.' || c >= utf8.RuneSelf
+}
+
+// lineColumn 把字节偏移转换为从1开始的行号和按字符计算的列号
+func lineColumn(s string, offset int) (int, int) {
+	before := s[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
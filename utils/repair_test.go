@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		fixes []FixKind
+	}{
+		{"合法的JSON", `{"a": [1, 2]}`, `{"a": [1, 2]}`, nil},
+		{"多余的逗号", "{\"a\": [1, 2,],\n}", "{\"a\": [1, 2]\n}", []FixKind{FixTrailingComma, FixTrailingComma}},
+		{"单引号", `{'a': 'it\'s "x"'}`, `{"a": "it's \"x\""}`, []FixKind{FixSingleQuotes, FixSingleQuotes}},
+		{"没有引号的键", `{name: "x", $id_2: 1}`, `{"name": "x", "$id_2": 1}`, []FixKind{FixUnquotedKey, FixUnquotedKey}},
+		{"缺少右括号", `{"a": [1, {"b": 2`, `{"a": [1, {"b": 2}]}`, []FixKind{FixMissingClose, FixMissingClose, FixMissingClose}},
+		{"末尾的逗号和缺少的括号", `[1, 2, `, `[1, 2 ]`, []FixKind{FixTrailingComma, FixMissingClose}},
+		{"未结束的字符串", `["abc`, `["abc"]`, []FixKind{FixUnterminatedString, FixMissingClose}},
+		{"末尾的反斜杠", `["a\`, `["a\\"]`, []FixKind{FixUnterminatedString, FixMissingClose}},
+		{"NaN和Infinity", `[NaN, Infinity, -Infinity, 1.5e3, true, null]`, `[null, null, null, 1.5e3, true, null]`, []FixKind{FixNonFinite, FixNonFinite, FixNonFinite}},
+		{"值中的字面量不是键", `{"a": true, b: false}`, `{"a": true, "b": false}`, []FixKind{FixUnquotedKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes, err := Repair(tt.input)
+			if err != nil {
+				t.Fatalf("Repair(%s) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Repair(%s) = %s, want %s", tt.input, got, tt.want)
+			}
+			var kinds []FixKind
+			for _, f := range fixes {
+				kinds = append(kinds, f.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.fixes) {
+				t.Errorf("Repair(%s) fixes = %v, want %v", tt.input, fixes, tt.fixes)
+			}
+		})
+	}
+}
+
+func TestRepairPositions(t *testing.T) {
+	_, fixes, err := Repair("{\n  名字: 'x',\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Fix{
+		{Kind: FixUnquotedKey, Offset: 4, Line: 2, Column: 3, Text: "名字"},
+		{Kind: FixSingleQuotes, Offset: 12, Line: 2, Column: 7, Text: "'x'"},
+		{Kind: FixTrailingComma, Offset: 15, Line: 2, Column: 10, Text: ","},
+	}
+	if !reflect.DeepEqual(fixes, want) {
+		t.Errorf("Repair() fixes = %+v, want %+v", fixes, want)
+	}
+	if got := fixes[1].String(); got != "2:7: 单引号改为双引号 'x'" {
+		t.Errorf("Fix.String() = %s", got)
+	}
+}
+
+func TestRepairErrors(t *testing.T) {
+	for _, input := range []string{`[1}`, `{"a" 1}`, `[undefined]`, ``} {
+		if got, _, err := Repair(input); err == nil {
+			t.Errorf("Repair(%s) = %s, should fail", input, got)
+		}
+	}
+}
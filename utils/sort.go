@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/UserLeeZJ/gojson/jsonpath"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+// SortOrder 表示排序方向
+type SortOrder int
+
+const (
+	// Ascending 表示升序
+	Ascending SortOrder = iota
+	// Descending 表示降序
+	Descending
+)
+
+// CompareValues 比较两个JSON值，a小于、等于、大于b时分别返回-1、0、1
+// 不同类型按 null < boolean < number < string < array < object 排序，
+// 同为数组或对象时按JSON表示比较
+func CompareValues(a, b types.JSONValue) int {
+	ar, br := typeRank(a), typeRank(b)
+	if ar != br {
+		return compareInts(ar, br)
+	}
+
+	switch {
+	case a == nil || a.IsNull():
+		return 0
+	case a.IsBoolean():
+		ab, _ := a.AsBoolean()
+		bb, _ := b.AsBoolean()
+		if ab == bb {
+			return 0
+		}
+		if !ab {
+			return -1
+		}
+		return 1
+	case a.IsNumber():
+		an, _ := a.AsNumber()
+		bn, _ := b.AsNumber()
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case a.IsString():
+		as, _ := a.AsString()
+		bs, _ := b.AsString()
+		return strings.Compare(as, bs)
+	default:
+		return strings.Compare(a.String(), b.String())
+	}
+}
+
+// ByPath 返回按元素中JSON Path所选值排序数组的规则，路径以元素为根，例如"$.createdAt"
+// 路径没有匹配值的元素无论升序还是降序都排在最后，比较结果相同的元素保持原有顺序
+func ByPath(path string, order SortOrder) (ArrayOrder, error) {
+	jp, err := jsonpath.ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b types.JSONValue) bool {
+		return lessKeys(sortKey(jp, a), sortKey(jp, b), order)
+	}, nil
+}
+
+// SortArrayBy 返回按元素中JSON Path所选值稳定排序后的新数组，原数组不会被修改
+func SortArrayBy(arr *types.JSONArray, path string, order SortOrder) (*types.JSONArray, error) {
+	jp, err := jsonpath.ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预先计算每个元素的排序键，避免比较时重复查询
+	elements := arr.Values()
+	keys := make([]types.JSONValue, len(elements))
+	for i, element := range elements {
+		keys[i] = sortKey(jp, element)
+	}
+	indexes := make([]int, len(elements))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return lessKeys(keys[indexes[i]], keys[indexes[j]], order)
+	})
+
+	result := make([]types.JSONValue, len(elements))
+	for i, index := range indexes {
+		result[i] = elements[index]
+	}
+	return types.NewJSONArrayFromValuesUnsafe(result), nil
+}
+
+// sortKey 返回元素的排序键，路径没有匹配值时返回nil
+func sortKey(jp *jsonpath.JSONPath, value types.JSONValue) types.JSONValue {
+	results, err := jp.Query(value)
+	if err != nil || len(results) == 0 {
+		return nil
+	}
+	if results[0] == nil {
+		return types.NewJSONNull()
+	}
+	return results[0]
+}
+
+// lessKeys 比较两个排序键，nil表示缺失并总是排在最后
+func lessKeys(a, b types.JSONValue, order SortOrder) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	c := CompareValues(a, b)
+	if order == Descending {
+		return c > 0
+	}
+	return c < 0
+}
+
+// typeRank 返回类型在排序中的位置
+func typeRank(value types.JSONValue) int {
+	switch {
+	case value == nil || value.IsNull():
+		return 0
+	case value.IsBoolean():
+		return 1
+	case value.IsNumber():
+		return 2
+	case value.IsString():
+		return 3
+	case value.IsArray():
+		return 4
+	default:
+		return 5
+	}
+}
+
+// compareInts 比较两个整数
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
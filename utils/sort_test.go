@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/UserLeeZJ/gojson/parser"
+	"github.com/UserLeeZJ/gojson/types"
+)
+
+func TestSortArrayBy(t *testing.T) {
+	value, err := parser.ParseToValue(`[
+		{"id": "c", "createdAt": "2024-03-01"},
+		{"id": "a", "createdAt": "2024-01-01"},
+		{"id": "x"},
+		{"id": "b", "createdAt": "2024-01-01"},
+		{"id": "d", "createdAt": "2024-02-01"}
+	]`)
+	if err != nil {
+		t.Fatalf("ParseToValue() error = %v", err)
+	}
+	arr, _ := value.AsArray()
+	original := arr.String()
+
+	ids := func(arr *types.JSONArray) string {
+		result := ""
+		for _, v := range arr.Values() {
+			obj, _ := v.AsObject()
+			id, _ := obj.GetString("id")
+			result += id
+		}
+		return result
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  string
+	}{
+		{Ascending, "abdcx"},
+		{Descending, "cdabx"},
+	}
+	for _, tt := range tests {
+		got, err := SortArrayBy(arr, "$.createdAt", tt.order)
+		if err != nil {
+			t.Fatalf("SortArrayBy() error = %v", err)
+		}
+		if ids(got) != tt.want {
+			t.Errorf("SortArrayBy(order=%d) = %s, want %s", tt.order, ids(got), tt.want)
+		}
+	}
+
+	if arr.String() != original {
+		t.Error("SortArrayBy() 不应修改原数组")
+	}
+
+	if _, err := SortArrayBy(arr, "createdAt", Ascending); err == nil {
+		t.Error("无效路径应该返回错误")
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	ordered := []string{`null`, `false`, `true`, `-1`, `2.5`, `10`, `""`, `"a"`, `"b"`, `[1]`, `{"a":1}`}
+	values := make([]types.JSONValue, len(ordered))
+	for i, s := range ordered {
+		values[i], _ = parser.ParseToValue(s)
+	}
+	for i := range values {
+		for j := range values {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := CompareValues(values[i], values[j]); got != want {
+				t.Errorf("CompareValues(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
@@ -19,6 +19,9 @@ type PrettyOptions struct {
 	Indent string
 	// SortKeys 表示是否对对象的键进行排序
 	SortKeys bool
+	// KeyOrder 是SortKeys为true时键的排序规则，为nil时按字节序排序。
+	// 可以使用types.CaseInsensitiveOrder、types.NaturalOrder或由types.Collate创建的规则
+	KeyOrder types.KeyOrder
 	// EscapeHTML 表示是否转义HTML字符
 	EscapeHTML bool
 }
@@ -39,7 +42,16 @@ func PrettyPrint(value types.JSONValue, options PrettyOptions) (string, error) {
 	}
 
 	// 转换为Go原生类型
-	native := types.ValueToInterface(value)
+	var native interface{}
+	switch {
+	case options.SortKeys && options.KeyOrder != nil:
+		// map总是按字节序输出，自定义顺序需要使用OrderedMap
+		native = types.ValueToInterfaceOpts(SortKeysBy(value, options.KeyOrder), types.InterfaceOptions{OrderedMaps: true})
+	case options.SortKeys:
+		native = sortMapKeys(types.ValueToInterface(value))
+	default:
+		native = types.ValueToInterface(value)
+	}
 
 	// 创建编码器
 	var buf bytes.Buffer
@@ -47,11 +59,6 @@ func PrettyPrint(value types.JSONValue, options PrettyOptions) (string, error) {
 	encoder.SetIndent("", options.Indent)
 	encoder.SetEscapeHTML(options.EscapeHTML)
 
-	// 如果需要排序键
-	if options.SortKeys {
-		native = sortMapKeys(native)
-	}
-
 	// 编码
 	if err := encoder.Encode(native); err != nil {
 		return "", jsonerrors.NewJSONError(jsonerrors.ErrOperationFailed, "格式化JSON失败").WithCause(err)
@@ -102,38 +109,14 @@ func extractPathsRecursive(value types.JSONValue, currentPath string, paths *[]s
 		sort.Strings(keys) // 排序键以确保结果一致
 
 		for _, key := range keys {
-			// 如果键包含特殊字符，使用['key']语法
-			childPath := currentPath
-			if needsQuotes(key) {
-				childPath += "['" + key + "']"
-			} else {
-				childPath += "." + key
-			}
-			extractPathsRecursive(obj.Get(key), childPath, paths)
+			extractPathsRecursive(obj.Get(key), types.ChildPath(currentPath, types.KeySegment(key)), paths)
 		}
 	} else if value.IsArray() {
 		arr, _ := value.AsArray()
 		for i := 0; i < arr.Size(); i++ {
-			childPath := fmt.Sprintf("%s[%d]", currentPath, i)
-			extractPathsRecursive(arr.Get(i), childPath, paths)
-		}
-	}
-}
-
-// needsQuotes 检查键是否需要引号
-func needsQuotes(key string) bool {
-	if key == "" {
-		return true
-	}
-	if !isValidIdentifierStart(key[0]) {
-		return true
-	}
-	for i := 1; i < len(key); i++ {
-		if !isValidIdentifierPart(key[i]) {
-			return true
+			extractPathsRecursive(arr.Get(i), types.ChildPath(currentPath, types.IndexSegment(i)), paths)
 		}
 	}
-	return false
 }
 
 // isValidIdentifierStart 检查字符是否是有效的标识符开始